@@ -3,55 +3,81 @@ package api
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
+	"github.com/danielmoisa/neobank/audit"
 	db "github.com/danielmoisa/neobank/db/sqlc"
-	"github.com/danielmoisa/neobank/tokens"
 	"github.com/labstack/echo/v4"
 )
 
 type createAccountRequest struct {
 	Currency string `json:"currency" validate:"required"`
+	// SavingsProductID opens a savings account under the product instead of
+	// a checking account.
+	SavingsProductID int64 `json:"savings_product_id" validate:"omitempty,min=1"`
 }
 
 // createAccount godoc
 // @Summary Create an account
-// @Description Create a new account with the specified owner and currency.
+// @Description Create a new account with the specified owner and currency. Users who haven't verified their identity can only have one account. With a savings product, a savings account earning the interest of the product is opened instead of a checking account; users may have one of each per currency.
 // @Tags Accounts
 // @Accept json
 // @Produce json
 // @Param request body createAccountRequest true "Request body for creating an account"
 // @Success 201 {object} db.Account
-// @Failure 400 {object} ErrorResponse "Bad Request"
-// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "KYC Required"
+// @Failure 404 {object} Problem "Savings Product Not Found"
+// @Failure 409 {object} Problem "Account Already Exists"
+// @Failure 422 {object} Problem "Currency Mismatch"
+// @Failure 500 {object} Problem "Internal Server Error"
 // @Router /accounts [post]
 func (server *Server) createAccount(ctx echo.Context) error {
 	req := new(createAccountRequest)
 	if err := ctx.Bind(req); err != nil {
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return err
 	}
 
 	if err := ctx.Validate(req); err != nil {
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return err
 	}
 
-	authPayload := ctx.Get(authorizationPayloadKey).(*tokens.Payload)
+	user := authUser(ctx)
+	if err := db.CheckKYCAccountLimit(ctx.Request().Context(), server.store, user); err != nil {
+		return kycProblem(err)
+	}
+
+	if req.SavingsProductID != 0 {
+		return server.createSavingsAccount(ctx, req, user)
+	}
 
 	account, err := server.store.CreateAccount(ctx.Request().Context(), db.CreateAccountParams{
-		Owner:    authPayload.Username,
+		Owner:    user.Username,
 		Currency: req.Currency,
 		Balance:  0,
 	})
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		if isUniqueViolation(err) {
+			return newProblem(http.StatusConflict, CodeAccountAlreadyExists, "a checking account in this currency already exists")
+		}
+		return err
 	}
 
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionAccountCreate,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceAccount,
+		ResourceID:   strconv.FormatInt(account.ID, 10),
+		Metadata:     map[string]interface{}{"currency": account.Currency},
+	})
+
 	return ctx.JSON(http.StatusCreated, account)
 }
 
 type getAccountRequest struct {
-	ID int64 `uri:"id" validate:"required"`
+	ID int64 `param:"id" validate:"required,min=1"`
 }
 
 // getAccount godoc
@@ -62,59 +88,86 @@ type getAccountRequest struct {
 // @Produce json
 // @Param id path int true "Account ID"
 // @Success 200 {object} db.Account
-// @Failure 400 {object} ErrorResponse "Bad Request"
-// @Failure 404 {object} ErrorResponse "Account Not Found"
-// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 404 {object} Problem "Account Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
 // @Router /accounts/{id} [get]
 func (server *Server) getAccount(ctx echo.Context) error {
+	account, err := server.authorizedAccount(ctx, db.AccountActionView)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, account)
+}
+
+// authorizedAccount loads the account in the path and makes sure the
+// authenticated user may take action on it.
+func (server *Server) authorizedAccount(ctx echo.Context, action string) (db.Account, error) {
 	req := new(getAccountRequest)
 
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID"})
+		return db.Account{}, newProblem(http.StatusBadRequest, CodeInvalidRequest, "invalid account id")
 	}
 
 	req.ID = id
 
 	if err := ctx.Validate(req); err != nil {
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return db.Account{}, err
 	}
 
 	account, err := server.store.GetAccount(ctx.Request().Context(), req.ID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "Account not found"})
+		if errors.Is(err, sql.ErrNoRows) {
+			return account, newProblem(http.StatusNotFound, CodeAccountNotFound, "account not found")
 		}
-
-		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+		return account, err
 	}
 
-	authPayload := ctx.Get(authorizationPayloadKey).(*tokens.Payload)
-	if account.Owner != authPayload.Username {
-		err := errors.New("account doesn't belongs to auth user")
-		return ctx.JSON(http.StatusUnauthorized, ErrorResponse{Error: err.Error()})
+	if _, err := server.authorizeAccount(ctx, account, action); err != nil {
+		return account, err
 	}
 
-	return ctx.JSON(http.StatusOK, account)
+	return account, nil
+}
+
+// authorizeAccount makes sure the authenticated user may take action on
+// account, returning their access to it.
+func (server *Server) authorizeAccount(ctx echo.Context, account db.Account, action string) (db.AccountAccess, error) {
+	access, err := db.AuthorizeAccount(ctx.Request().Context(), server.store, account, authUser(ctx).Username, action)
+	return access, accountAccessError(account, access, err)
+}
+
+// accountAccessError maps the errors of AuthorizeAccount to problems.
+func accountAccessError(account db.Account, access db.AccountAccess, err error) error {
+	switch {
+	case errors.Is(err, db.ErrNotAccountMember):
+		return newProblem(http.StatusUnauthorized, CodeAccountNotOwned, "account doesn't belong to the authenticated user")
+	case errors.Is(err, db.ErrAccountActionDenied):
+		detail := fmt.Sprintf("the %s role on account [%d] doesn't allow this", access.Role, account.ID)
+		return newProblem(http.StatusForbidden, CodeAccountActionForbidden, detail)
+	}
+	return err
 }
 
 type listAccountRequest struct {
-	PageID   int32 `form:"page_id" validate:"required,min=1"`
-	PageSize int32 `form:"page_size" validate:"required,min=5,max=10"`
+	PageID   int32 `query:"page_id" validate:"required,min=1"`
+	PageSize int32 `query:"page_size" validate:"required,min=5,max=10"`
 }
 
 // listAccounts godoc
 // @Summary List accounts
-// @Description Get a list of accounts with pagination.
+// @Description Get the accounts of the authenticated user and those shared with them, with their pockets, with pagination.
 // @Tags Accounts
 // @Accept json
 // @Produce json
 // @Param page_id query int true "Page ID for pagination"
 // @Param page_size query int true "Number of accounts per page (min: 5, max: 10)"
 // @Success 200 {array} db.Account
-// @Failure 400 {object} ErrorResponse "Bad Request"
-// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 500 {object} Problem "Internal Server Error"
 // @Router /accounts [get]
 func (server *Server) listAccounts(ctx echo.Context) error {
 	req := new(listAccountRequest)
@@ -124,31 +177,78 @@ func (server *Server) listAccounts(ctx echo.Context) error {
 
 	pageID, err := strconv.ParseInt(pageIDStr, 10, 32)
 	if err != nil {
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid page_id"})
+		return newProblem(http.StatusBadRequest, CodeInvalidRequest, "invalid page_id")
 	}
 
 	pageSize, err := strconv.ParseInt(pageSizeStr, 10, 32)
 	if err != nil {
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid page_size"})
+		return newProblem(http.StatusBadRequest, CodeInvalidRequest, "invalid page_size")
 	}
 
 	req.PageID = int32(pageID)
 	req.PageSize = int32(pageSize)
 
 	if err := ctx.Validate(req); err != nil {
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return err
 	}
 
-	authPayload := ctx.Get(authorizationPayloadKey).(*tokens.Payload)
-
 	accounts, err := server.store.ListAccounts(ctx.Request().Context(), db.ListAccountsParams{
-		Owner:  authPayload.Username,
+		Owner:  authUser(ctx).Username,
 		Limit:  req.PageSize,
 		Offset: (req.PageID - 1) * req.PageSize,
 	})
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+		return err
 	}
 
+	// the query finds the accounts the user owns or has a role on, and every
+	// role may view an account
 	return ctx.JSON(http.StatusOK, accounts)
 }
+
+type listEntriesRequest struct {
+	PageID   int32 `query:"page_id" validate:"required,min=1"`
+	PageSize int32 `query:"page_size" validate:"required,min=5,max=50"`
+}
+
+// listAccountEntries godoc
+// @Summary List account entries
+// @Description Get the ledger entries of an account of the authenticated user, oldest first, with pagination.
+// @Tags Accounts
+// @Produce json
+// @Param id path int true "Account ID"
+// @Param page_id query int true "Page ID for pagination"
+// @Param page_size query int true "Number of entries per page (min: 5, max: 50)"
+// @Success 200 {array} db.Entry
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Insufficient Scope"
+// @Failure 404 {object} Problem "Account Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /accounts/{id}/entries [get]
+func (server *Server) listAccountEntries(ctx echo.Context) error {
+	account, err := server.authorizedAccount(ctx, db.AccountActionView)
+	if err != nil {
+		return err
+	}
+
+	req := new(listEntriesRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	entries, err := server.store.ListEntries(ctx.Request().Context(), db.ListEntriesParams{
+		AccountID: account.ID,
+		Limit:     req.PageSize,
+		Offset:    (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, entries)
+}
@@ -0,0 +1,341 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/events"
+	"github.com/labstack/echo/v4"
+)
+
+type proposeAdjustmentRequest struct {
+	AccountID  int64  `json:"account_id" validate:"required,min=1"`
+	Amount     int64  `json:"amount" validate:"required"`
+	ReasonCode string `json:"reason_code" validate:"required,oneof=correction fee_refund chargeback goodwill write_off"`
+	Note       string `json:"note" validate:"required"`
+	Evidence   string `json:"evidence" validate:"required"`
+}
+
+type adjustmentResponse struct {
+	ID         int64      `json:"id"`
+	AccountID  int64      `json:"account_id"`
+	Amount     int64      `json:"amount"`
+	ReasonCode string     `json:"reason_code"`
+	Note       string     `json:"note"`
+	Evidence   string     `json:"evidence"`
+	Status     string     `json:"status"`
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	ReviewedBy string     `json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	ReviewNote string     `json:"review_note,omitempty"`
+	EntryID    int64      `json:"entry_id,omitempty"`
+}
+
+func newAdjustmentResponse(adjustment db.Adjustment) adjustmentResponse {
+	res := adjustmentResponse{
+		ID:         adjustment.ID,
+		AccountID:  adjustment.AccountID,
+		Amount:     adjustment.Amount,
+		ReasonCode: adjustment.ReasonCode,
+		Note:       adjustment.Note,
+		Evidence:   adjustment.Evidence,
+		Status:     adjustment.Status,
+		CreatedBy:  adjustment.CreatedBy,
+		CreatedAt:  adjustment.CreatedAt,
+		ExpiresAt:  adjustment.ExpiresAt,
+		ReviewedBy: adjustment.ReviewedBy.String,
+		ReviewNote: adjustment.ReviewNote,
+		EntryID:    adjustment.EntryID.Int64,
+	}
+	if adjustment.ReviewedAt.Valid {
+		res.ReviewedAt = &adjustment.ReviewedAt.Time
+	}
+	return res
+}
+
+type approveAdjustmentResponse struct {
+	Adjustment adjustmentResponse `json:"adjustment"`
+	Account    db.Account         `json:"account"`
+	Entry      db.Entry           `json:"entry"`
+}
+
+// proposeAdjustment godoc
+// @Summary Propose a balance adjustment
+// @Description Propose a manual credit (positive amount) or debit (negative amount). Nothing is booked until a different admin approves it. Admins only.
+// @Tags Adjustments
+// @Accept json
+// @Produce json
+// @Param request body proposeAdjustmentRequest true "Request body for proposing an adjustment"
+// @Success 201 {object} adjustmentResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Admin Required"
+// @Failure 404 {object} Problem "Account Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /adjustments [post]
+func (server *Server) proposeAdjustment(ctx echo.Context) error {
+	req := new(proposeAdjustmentRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	if _, err := server.store.GetAccount(ctx.Request().Context(), req.AccountID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return newProblem(http.StatusNotFound, CodeAccountNotFound, fmt.Sprintf("account [%d] not found", req.AccountID))
+		}
+		return err
+	}
+
+	username := authUser(ctx).Username
+
+	args := db.ProposeAdjustmentParams{
+		AccountID:  req.AccountID,
+		Amount:     req.Amount,
+		ReasonCode: req.ReasonCode,
+		Note:       req.Note,
+		Evidence:   req.Evidence,
+		CreatedBy:  username,
+	}
+	if server.config.AdjustmentTTL > 0 {
+		args.ExpiresAt = time.Now().Add(server.config.AdjustmentTTL)
+	}
+
+	adjustment, err := server.store.ProposeAdjustment(ctx.Request().Context(), args)
+	if err != nil {
+		return err
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionAdjustmentPropose,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceAdjustment,
+		ResourceID:   strconv.FormatInt(adjustment.ID, 10),
+		Metadata: map[string]interface{}{
+			"account_id":  adjustment.AccountID,
+			"amount":      adjustment.Amount,
+			"reason_code": adjustment.ReasonCode,
+		},
+	})
+
+	return ctx.JSON(http.StatusCreated, newAdjustmentResponse(adjustment))
+}
+
+type listAdjustmentsRequest struct {
+	Status   string `query:"status" validate:"required,oneof=pending approved rejected expired"`
+	PageID   int32  `query:"page_id" validate:"required,min=1"`
+	PageSize int32  `query:"page_size" validate:"required,min=5,max=50"`
+}
+
+// listAdjustments godoc
+// @Summary List adjustments
+// @Description List adjustments by status, oldest first. Pending adjustments past their expiry are marked expired first. Admins only.
+// @Tags Adjustments
+// @Accept json
+// @Produce json
+// @Param status query string true "Status" Enums(pending, approved, rejected, expired)
+// @Param page_id query int true "Page ID for pagination"
+// @Param page_size query int true "Number of adjustments per page (min: 5, max: 50)"
+// @Success 200 {array} adjustmentResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Admin Required"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /adjustments [get]
+func (server *Server) listAdjustments(ctx echo.Context) error {
+	req := new(listAdjustmentsRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	if err := server.expireAdjustments(ctx); err != nil {
+		return err
+	}
+
+	adjustments, err := server.store.ListAdjustments(ctx.Request().Context(), db.ListAdjustmentsParams{
+		Status: req.Status,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	res := make([]adjustmentResponse, len(adjustments))
+	for i, adjustment := range adjustments {
+		res[i] = newAdjustmentResponse(adjustment)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// expireAdjustments closes the pending adjustments nobody reviewed in time,
+// so the review queue only shows what can still be approved.
+func (server *Server) expireAdjustments(ctx echo.Context) error {
+	expired, err := server.store.ExpireAdjustments(ctx.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	for _, adjustment := range expired {
+		server.auditAdjustmentExpiry(ctx, adjustment.ID)
+	}
+	return nil
+}
+
+func (server *Server) auditAdjustmentExpiry(ctx echo.Context, id int64) {
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Actor:        db.SystemUsername,
+		Action:       audit.ActionAdjustmentExpire,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceAdjustment,
+		ResourceID:   strconv.FormatInt(id, 10),
+	})
+}
+
+type approveAdjustmentRequest struct {
+	ID int64 `param:"id" validate:"required,min=1"`
+}
+
+type rejectAdjustmentRequest struct {
+	ID   int64  `param:"id" validate:"required,min=1"`
+	Note string `json:"note" validate:"required"`
+}
+
+// approveAdjustment godoc
+// @Summary Approve an adjustment
+// @Description Book a pending adjustment against the suspense account of its currency. The approver must not be the admin who proposed it. Admins only.
+// @Tags Adjustments
+// @Accept json
+// @Produce json
+// @Param id path int true "Adjustment ID"
+// @Success 200 {object} approveAdjustmentResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Admin Required or Self Review"
+// @Failure 404 {object} Problem "Adjustment Not Found"
+// @Failure 409 {object} Problem "Adjustment Not Pending or Expired"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /adjustments/{id}/approve [post]
+func (server *Server) approveAdjustment(ctx echo.Context) error {
+	req := new(approveAdjustmentRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	username := authUser(ctx).Username
+
+	result, err := server.store.ApproveAdjustmentTx(ctx.Request().Context(), db.ReviewAdjustmentTxParams{
+		AdjustmentID: req.ID,
+		ReviewedBy:   username,
+	})
+	if err != nil {
+		return server.reviewProblem(ctx, audit.ActionAdjustmentApprove, req.ID, err)
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionAdjustmentApprove,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceAdjustment,
+		ResourceID:   strconv.FormatInt(req.ID, 10),
+		Metadata: map[string]interface{}{
+			"account_id":        result.Account.ID,
+			"amount":            result.Adjustment.Amount,
+			"entry_id":          result.Entry.ID,
+			"suspense_entry_id": result.SuspenseEntry.ID,
+		},
+	})
+
+	events.PublishAll(ctx.Request().Context(), server.broker, events.AdjustmentEvents(result))
+
+	return ctx.JSON(http.StatusOK, approveAdjustmentResponse{
+		Adjustment: newAdjustmentResponse(result.Adjustment),
+		Account:    result.Account,
+		Entry:      result.Entry,
+	})
+}
+
+// rejectAdjustment godoc
+// @Summary Reject an adjustment
+// @Description Close a pending adjustment without booking it. The reviewer must not be the admin who proposed it. Admins only.
+// @Tags Adjustments
+// @Accept json
+// @Produce json
+// @Param id path int true "Adjustment ID"
+// @Param request body rejectAdjustmentRequest true "Why the adjustment is rejected"
+// @Success 200 {object} adjustmentResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Admin Required or Self Review"
+// @Failure 404 {object} Problem "Adjustment Not Found"
+// @Failure 409 {object} Problem "Adjustment Not Pending"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /adjustments/{id}/reject [post]
+func (server *Server) rejectAdjustment(ctx echo.Context) error {
+	req := new(rejectAdjustmentRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	username := authUser(ctx).Username
+
+	adjustment, err := server.store.RejectAdjustmentTx(ctx.Request().Context(), db.ReviewAdjustmentTxParams{
+		AdjustmentID: req.ID,
+		ReviewedBy:   username,
+		Note:         req.Note,
+	})
+	if err != nil {
+		return server.reviewProblem(ctx, audit.ActionAdjustmentReject, req.ID, err)
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionAdjustmentReject,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceAdjustment,
+		ResourceID:   strconv.FormatInt(req.ID, 10),
+	})
+
+	return ctx.JSON(http.StatusOK, newAdjustmentResponse(adjustment))
+}
+
+// reviewProblem maps the errors of approving or rejecting an adjustment.
+// Attempts to review one's own adjustment are audited.
+func (server *Server) reviewProblem(ctx echo.Context, action string, id int64, err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return newProblem(http.StatusNotFound, CodeAdjustmentNotFound, fmt.Sprintf("adjustment [%d] not found", id))
+	case errors.Is(err, db.ErrSelfReview):
+		audit.Record(ctx.Request().Context(), server.store, audit.Event{
+			Action:       action,
+			Outcome:      audit.OutcomeFailure,
+			ResourceType: audit.ResourceAdjustment,
+			ResourceID:   strconv.FormatInt(id, 10),
+			Metadata:     map[string]interface{}{"reason": "self_review"},
+		})
+		return newProblem(http.StatusForbidden, CodeSelfReview, err.Error())
+	case errors.Is(err, db.ErrAdjustmentExpired):
+		server.auditAdjustmentExpiry(ctx, id)
+		return newProblem(http.StatusConflict, CodeAdjustmentExpired, err.Error())
+	case errors.Is(err, db.ErrAdjustmentNotPending):
+		return newProblem(http.StatusConflict, CodeAdjustmentNotPending, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,476 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func randomAdmin(t *testing.T) db.User {
+	admin, _ := randomUser(t)
+	admin.Role = db.RoleAdmin
+	return admin
+}
+
+func randomPendingAdjustment(proposer string, accountID int64) db.Adjustment {
+	return db.Adjustment{
+		ID:         utils.RandomInt(1, 1000),
+		AccountID:  accountID,
+		Amount:     -utils.RandomInt(1, 100),
+		ReasonCode: db.ReasonFeeRefund,
+		Note:       utils.RandomString(10),
+		Evidence:   utils.RandomString(10),
+		Status:     db.AdjustmentPending,
+		CreatedBy:  proposer,
+		CreatedAt:  time.Now(),
+		ExpiresAt:  time.Now().Add(time.Hour),
+	}
+}
+
+func TestProposeAdjustmentAPI(t *testing.T) {
+	maker := randomAdmin(t)
+	customer, _ := randomUser(t)
+	account := randomAccount(customer.Username)
+	adjustment := randomPendingAdjustment(maker.Username, account.ID)
+
+	body := map[string]interface{}{
+		"account_id":  account.ID,
+		"amount":      adjustment.Amount,
+		"reason_code": adjustment.ReasonCode,
+		"note":        adjustment.Note,
+		"evidence":    adjustment.Evidence,
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		body          map[string]interface{}
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: maker.Username,
+			body:     body,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, maker)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					ProposeAdjustment(gomock.Any(), gomock.Cond(func(x any) bool {
+						arg, ok := x.(db.ProposeAdjustmentParams)
+						return ok && arg.AccountID == account.ID && arg.Amount == adjustment.Amount && arg.CreatedBy == maker.Username
+					})).
+					Times(1).
+					Return(adjustment, nil)
+				expectAuditEvent(store, audit.ActionAdjustmentPropose, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var res adjustmentResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, adjustment.ID, res.ID)
+				require.Equal(t, db.AdjustmentPending, res.Status)
+				require.Nil(t, res.ReviewedAt)
+			},
+		},
+		{
+			name:     "NotAdmin",
+			username: customer.Username,
+			body:     body,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, customer)
+				store.EXPECT().
+					ProposeAdjustment(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeAdminRequired)
+			},
+		},
+		{
+			name:     "InvalidReasonCode",
+			username: maker.Username,
+			body: map[string]interface{}{
+				"account_id":  account.ID,
+				"amount":      10,
+				"reason_code": "because",
+				"note":        "note",
+				"evidence":    "ticket",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, maker)
+				store.EXPECT().
+					ProposeAdjustment(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name:     "MissingEvidence",
+			username: maker.Username,
+			body: map[string]interface{}{
+				"account_id":  account.ID,
+				"amount":      10,
+				"reason_code": db.ReasonGoodwill,
+				"note":        "note",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, maker)
+				store.EXPECT().
+					ProposeAdjustment(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name:     "AccountNotFound",
+			username: maker.Username,
+			body:     body,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, maker)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().
+					ProposeAdjustment(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, CodeAccountNotFound)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/adjustments", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestApproveAdjustmentAPI(t *testing.T) {
+	maker := randomAdmin(t)
+	checker := randomAdmin(t)
+	customer, _ := randomUser(t)
+	account := randomAccount(customer.Username)
+	adjustment := randomPendingAdjustment(maker.Username, account.ID)
+
+	approved := adjustment
+	approved.Status = db.AdjustmentApproved
+	approved.ReviewedBy = sql.NullString{String: checker.Username, Valid: true}
+	approved.ReviewedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	result := db.ApproveAdjustmentTxResult{
+		Adjustment: approved,
+		Account:    account,
+		Entry:      db.Entry{ID: 1, AccountID: account.ID, Amount: adjustment.Amount},
+	}
+
+	testCases := []struct {
+		name          string
+		reviewer      db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			reviewer: checker,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, checker)
+				store.EXPECT().
+					ApproveAdjustmentTx(gomock.Any(), gomock.Eq(db.ReviewAdjustmentTxParams{
+						AdjustmentID: adjustment.ID,
+						ReviewedBy:   checker.Username,
+					})).
+					Times(1).
+					Return(result, nil)
+				expectAuditEvent(store, audit.ActionAdjustmentApprove, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res approveAdjustmentResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, db.AdjustmentApproved, res.Adjustment.Status)
+				require.Equal(t, checker.Username, res.Adjustment.ReviewedBy)
+				require.Equal(t, result.Entry.ID, res.Entry.ID)
+			},
+		},
+		{
+			name:     "SelfReview",
+			reviewer: maker,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, maker)
+				store.EXPECT().
+					ApproveAdjustmentTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ApproveAdjustmentTxResult{}, db.ErrSelfReview)
+				expectAuditEvent(store, audit.ActionAdjustmentApprove, audit.OutcomeFailure)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeSelfReview)
+			},
+		},
+		{
+			name:     "Expired",
+			reviewer: checker,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, checker)
+				store.EXPECT().
+					ApproveAdjustmentTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ApproveAdjustmentTxResult{}, db.ErrAdjustmentExpired)
+				expectAuditEvent(store, audit.ActionAdjustmentExpire, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, CodeAdjustmentExpired)
+			},
+		},
+		{
+			name:     "AlreadyReviewed",
+			reviewer: checker,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, checker)
+				store.EXPECT().
+					ApproveAdjustmentTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ApproveAdjustmentTxResult{}, db.ErrAdjustmentNotPending)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, CodeAdjustmentNotPending)
+			},
+		},
+		{
+			name:     "NotFound",
+			reviewer: checker,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, checker)
+				store.EXPECT().
+					ApproveAdjustmentTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ApproveAdjustmentTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, CodeAdjustmentNotFound)
+			},
+		},
+		{
+			name:     "DisabledAdmin",
+			reviewer: checker,
+			buildStubs: func(store *mockdb.MockStore) {
+				disabled := checker
+				disabled.Disabled = true
+				expectUser(store, disabled)
+				store.EXPECT().
+					ApproveAdjustmentTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeUserDisabled)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/adjustments/%d/approve", adjustment.ID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.reviewer.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRejectAdjustmentAPI(t *testing.T) {
+	maker := randomAdmin(t)
+	checker := randomAdmin(t)
+	adjustment := randomPendingAdjustment(maker.Username, 1)
+
+	rejected := adjustment
+	rejected.Status = db.AdjustmentRejected
+	rejected.ReviewedBy = sql.NullString{String: checker.Username, Valid: true}
+	rejected.ReviewNote = "duplicate"
+
+	testCases := []struct {
+		name          string
+		body          map[string]interface{}
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: map[string]interface{}{"note": "duplicate"},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, checker)
+				store.EXPECT().
+					RejectAdjustmentTx(gomock.Any(), gomock.Eq(db.ReviewAdjustmentTxParams{
+						AdjustmentID: adjustment.ID,
+						ReviewedBy:   checker.Username,
+						Note:         "duplicate",
+					})).
+					Times(1).
+					Return(rejected, nil)
+				expectAuditEvent(store, audit.ActionAdjustmentReject, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res adjustmentResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, db.AdjustmentRejected, res.Status)
+				require.Equal(t, "duplicate", res.ReviewNote)
+			},
+		},
+		{
+			name: "MissingNote",
+			body: map[string]interface{}{},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, checker)
+				store.EXPECT().
+					RejectAdjustmentTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/adjustments/%d/reject", adjustment.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, checker.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListAdjustmentsAPI(t *testing.T) {
+	admin := randomAdmin(t)
+
+	expired := randomPendingAdjustment(utils.RandomOwner(), 1)
+	expired.Status = db.AdjustmentExpired
+	pending := []db.Adjustment{
+		randomPendingAdjustment(utils.RandomOwner(), 1),
+		randomPendingAdjustment(utils.RandomOwner(), 2),
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	expectUser(store, admin)
+	store.EXPECT().
+		ExpireAdjustments(gomock.Any()).
+		Times(1).
+		Return([]db.Adjustment{expired}, nil)
+	expectAuditEvent(store, audit.ActionAdjustmentExpire, audit.OutcomeSuccess)
+	store.EXPECT().
+		ListAdjustments(gomock.Any(), gomock.Eq(db.ListAdjustmentsParams{
+			Status: db.AdjustmentPending,
+			Limit:  5,
+			Offset: 0,
+		})).
+		Times(1).
+		Return(pending, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/adjustments?status=pending&page_id=1&page_size=5", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var res []adjustmentResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+	require.Len(t, res, 2)
+	require.Equal(t, pending[0].ID, res[0].ID)
+}
@@ -0,0 +1,491 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/monitoring"
+	"github.com/labstack/echo/v4"
+)
+
+type amlAlertResponse struct {
+	ID         int64           `json:"id"`
+	Scenario   string          `json:"scenario"`
+	Username   string          `json:"username"`
+	AccountID  int64           `json:"account_id"`
+	Summary    string          `json:"summary"`
+	Evidence   json.RawMessage `json:"evidence" swaggertype:"object"`
+	Status     string          `json:"status"`
+	AssignedTo string          `json:"assigned_to,omitempty"`
+	Resolution string          `json:"resolution,omitempty"`
+	ResolvedBy string          `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time      `json:"resolved_at,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+func newAMLAlertResponse(alert db.AMLAlert) amlAlertResponse {
+	res := amlAlertResponse{
+		ID:         alert.ID,
+		Scenario:   alert.Scenario,
+		Username:   alert.Username,
+		AccountID:  alert.AccountID,
+		Summary:    alert.Summary,
+		Evidence:   alert.Evidence,
+		Status:     alert.Status,
+		AssignedTo: alert.AssignedTo.String,
+		Resolution: alert.Resolution,
+		ResolvedBy: alert.ResolvedBy.String,
+		CreatedAt:  alert.CreatedAt,
+		UpdatedAt:  alert.UpdatedAt,
+	}
+	if alert.ResolvedAt.Valid {
+		res.ResolvedAt = &alert.ResolvedAt.Time
+	}
+	return res
+}
+
+type listAMLAlertsRequest struct {
+	Status     string `query:"status" validate:"required,oneof=open closed escalated"`
+	AssignedTo string `query:"assigned_to" validate:"omitempty,alphanum"`
+	PageID     int32  `query:"page_id" validate:"required,min=1"`
+	PageSize   int32  `query:"page_size" validate:"required,min=5,max=50"`
+}
+
+// listAMLAlerts godoc
+// @Summary List AML alerts
+// @Description List the alerts of the transaction monitoring jobs by status, oldest first, optionally only those assigned to an analyst. Support staff only.
+// @Tags AML
+// @Produce json
+// @Param status query string true "Status" Enums(open, closed, escalated)
+// @Param assigned_to query string false "Username of the assigned analyst"
+// @Param page_id query int true "Page ID for pagination"
+// @Param page_size query int true "Number of alerts per page (min: 5, max: 50)"
+// @Success 200 {array} amlAlertResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Support Required"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /aml/alerts [get]
+func (server *Server) listAMLAlerts(ctx echo.Context) error {
+	req := new(listAMLAlertsRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	alerts, err := server.store.ListAMLAlerts(ctx.Request().Context(), db.ListAMLAlertsParams{
+		Status:     req.Status,
+		AssignedTo: sql.NullString{String: req.AssignedTo, Valid: req.AssignedTo != ""},
+		Limit:      req.PageSize,
+		Offset:     (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	res := make([]amlAlertResponse, len(alerts))
+	for i, alert := range alerts {
+		res[i] = newAMLAlertResponse(alert)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+type getAMLAlertRequest struct {
+	ID int64 `param:"id" validate:"required,min=1"`
+}
+
+type amlAlertDetailResponse struct {
+	Alert    amlAlertResponse     `json:"alert"`
+	Comments []db.AMLAlertComment `json:"comments"`
+}
+
+// getAMLAlert godoc
+// @Summary Get an AML alert
+// @Description Get an alert with the comments of the analysts. Support staff only.
+// @Tags AML
+// @Produce json
+// @Param id path int true "Alert ID"
+// @Success 200 {object} amlAlertDetailResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Support Required"
+// @Failure 404 {object} Problem "AML Alert Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /aml/alerts/{id} [get]
+func (server *Server) getAMLAlert(ctx echo.Context) error {
+	req := new(getAMLAlertRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	alert, err := server.findAMLAlert(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+
+	comments, err := server.store.ListAMLAlertComments(ctx.Request().Context(), alert.ID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, amlAlertDetailResponse{
+		Alert:    newAMLAlertResponse(alert),
+		Comments: comments,
+	})
+}
+
+func (server *Server) findAMLAlert(ctx echo.Context, id int64) (db.AMLAlert, error) {
+	alert, err := server.store.GetAMLAlert(ctx.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return alert, newProblem(http.StatusNotFound, CodeAMLAlertNotFound, fmt.Sprintf("AML alert [%d] not found", id))
+		}
+		return alert, err
+	}
+	return alert, nil
+}
+
+// alertNotOpen explains why an update of an open alert matched no row: the
+// alert doesn't exist or it is no longer open.
+func (server *Server) alertNotOpen(ctx echo.Context, id int64) error {
+	alert, err := server.findAMLAlert(ctx, id)
+	if err != nil {
+		return err
+	}
+	return newProblem(http.StatusConflict, CodeAMLAlertNotOpen, fmt.Sprintf("AML alert [%d] is %s", id, alert.Status))
+}
+
+type assignAMLAlertRequest struct {
+	ID       int64  `param:"id" validate:"required,min=1"`
+	Assignee string `json:"assignee" validate:"omitempty,alphanum"`
+}
+
+// assignAMLAlert godoc
+// @Summary Assign an AML alert
+// @Description Assign an open alert to an analyst, by default the caller. The assignee must be support staff. Support staff only.
+// @Tags AML
+// @Accept json
+// @Produce json
+// @Param id path int true "Alert ID"
+// @Param request body assignAMLAlertRequest false "Analyst to assign"
+// @Success 200 {object} amlAlertResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Support Required"
+// @Failure 404 {object} Problem "AML Alert Not Found"
+// @Failure 409 {object} Problem "AML Alert Not Open"
+// @Failure 422 {object} Problem "Assignee Not Staff"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /aml/alerts/{id}/assign [post]
+func (server *Server) assignAMLAlert(ctx echo.Context) error {
+	req := new(assignAMLAlertRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	assignee := authUser(ctx)
+	if req.Assignee != "" && req.Assignee != assignee.Username {
+		user, err := server.store.GetUser(ctx.Request().Context(), req.Assignee)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if err != nil || !isStaff(user) {
+			return newProblem(http.StatusUnprocessableEntity, CodeAssigneeNotStaff, fmt.Sprintf("%s is not support staff", req.Assignee))
+		}
+		assignee = user
+	}
+
+	alert, err := server.store.AssignAMLAlert(ctx.Request().Context(), db.AssignAMLAlertParams{
+		ID:         req.ID,
+		AssignedTo: sql.NullString{String: assignee.Username, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return server.alertNotOpen(ctx, req.ID)
+		}
+		return err
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionAMLAssign,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceAMLAlert,
+		ResourceID:   strconv.FormatInt(alert.ID, 10),
+		Metadata:     map[string]interface{}{"assignee": assignee.Username},
+	})
+
+	return ctx.JSON(http.StatusOK, newAMLAlertResponse(alert))
+}
+
+type commentAMLAlertRequest struct {
+	ID   int64  `param:"id" validate:"required,min=1"`
+	Body string `json:"body" validate:"required,max=4000"`
+}
+
+// commentAMLAlert godoc
+// @Summary Comment on an AML alert
+// @Description Add a note of the investigation to an alert, in any status. Support staff only.
+// @Tags AML
+// @Accept json
+// @Produce json
+// @Param id path int true "Alert ID"
+// @Param request body commentAMLAlertRequest true "Comment"
+// @Success 201 {object} db.AMLAlertComment
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Support Required"
+// @Failure 404 {object} Problem "AML Alert Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /aml/alerts/{id}/comments [post]
+func (server *Server) commentAMLAlert(ctx echo.Context) error {
+	req := new(commentAMLAlertRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	if _, err := server.findAMLAlert(ctx, req.ID); err != nil {
+		return err
+	}
+
+	comment, err := server.store.CreateAMLAlertComment(ctx.Request().Context(), db.CreateAMLAlertCommentParams{
+		AlertID: req.ID,
+		Author:  authUser(ctx).Username,
+		Body:    req.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionAMLComment,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceAMLAlert,
+		ResourceID:   strconv.FormatInt(req.ID, 10),
+		Metadata:     map[string]interface{}{"comment_id": comment.ID},
+	})
+
+	return ctx.JSON(http.StatusCreated, comment)
+}
+
+type resolveAMLAlertRequest struct {
+	ID         int64  `param:"id" validate:"required,min=1"`
+	Resolution string `json:"resolution" validate:"required,max=4000"`
+}
+
+// closeAMLAlert godoc
+// @Summary Close an AML alert
+// @Description Close an open alert as explained activity. The same payments and entries don't raise it again. Support staff only.
+// @Tags AML
+// @Accept json
+// @Produce json
+// @Param id path int true "Alert ID"
+// @Param request body resolveAMLAlertRequest true "Why the activity is not suspicious"
+// @Success 200 {object} amlAlertResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Support Required"
+// @Failure 404 {object} Problem "AML Alert Not Found"
+// @Failure 409 {object} Problem "AML Alert Not Open"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /aml/alerts/{id}/close [post]
+func (server *Server) closeAMLAlert(ctx echo.Context) error {
+	return server.resolveAMLAlert(ctx, db.AMLAlertClosed)
+}
+
+// escalateAMLAlert godoc
+// @Summary Escalate an AML alert
+// @Description Escalate an open alert for a suspicious activity report. The resolution is the narrative of the report. Support staff only.
+// @Tags AML
+// @Accept json
+// @Produce json
+// @Param id path int true "Alert ID"
+// @Param request body resolveAMLAlertRequest true "Narrative of the suspicious activity"
+// @Success 200 {object} amlAlertResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Support Required"
+// @Failure 404 {object} Problem "AML Alert Not Found"
+// @Failure 409 {object} Problem "AML Alert Not Open"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /aml/alerts/{id}/escalate [post]
+func (server *Server) escalateAMLAlert(ctx echo.Context) error {
+	return server.resolveAMLAlert(ctx, db.AMLAlertEscalated)
+}
+
+func (server *Server) resolveAMLAlert(ctx echo.Context, status string) error {
+	req := new(resolveAMLAlertRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	action := audit.ActionAMLClose
+	if status == db.AMLAlertEscalated {
+		action = audit.ActionAMLEscalate
+	}
+
+	alert, err := server.store.ResolveAMLAlert(ctx.Request().Context(), db.ResolveAMLAlertParams{
+		ID:         req.ID,
+		Status:     status,
+		Resolution: req.Resolution,
+		ResolvedBy: sql.NullString{String: authUser(ctx).Username, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return server.alertNotOpen(ctx, req.ID)
+		}
+		return err
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       action,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceAMLAlert,
+		ResourceID:   strconv.FormatInt(alert.ID, 10),
+		Metadata: map[string]interface{}{
+			"scenario":   alert.Scenario,
+			"username":   alert.Username,
+			"account_id": alert.AccountID,
+		},
+	})
+
+	return ctx.JSON(http.StatusOK, newAMLAlertResponse(alert))
+}
+
+// exportAMLAlertSAR godoc
+// @Summary Export a suspicious activity report
+// @Description Download the suspicious activity report of an escalated alert: the subject, the account, every payment and entry behind the alert, the narrative and the comments. Support staff only.
+// @Tags AML
+// @Produce json
+// @Param id path int true "Alert ID"
+// @Success 200 {object} monitoring.SAR
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Support Required"
+// @Failure 404 {object} Problem "AML Alert Not Found"
+// @Failure 409 {object} Problem "AML Alert Not Escalated"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /aml/alerts/{id}/sar [get]
+func (server *Server) exportAMLAlertSAR(ctx echo.Context) error {
+	req := new(getAMLAlertRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	alert, err := server.findAMLAlert(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+
+	user := authUser(ctx)
+	report, err := monitoring.BuildSAR(ctx.Request().Context(), server.store, alert, user.Username, time.Now())
+	if err != nil {
+		if errors.Is(err, monitoring.ErrAlertNotEscalated) {
+			return newProblem(http.StatusConflict, CodeAMLAlertNotEscalated, err.Error())
+		}
+		return err
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionAMLSARExport,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceAMLAlert,
+		ResourceID:   strconv.FormatInt(alert.ID, 10),
+		Metadata:     map[string]interface{}{"report_id": report.ReportID},
+	})
+
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", report.ReportID+".json"))
+	return ctx.JSON(http.StatusOK, report)
+}
+
+type amlJobRunResponse struct {
+	ID          int64      `json:"id"`
+	Scenario    string     `json:"scenario"`
+	WindowStart time.Time  `json:"window_start"`
+	WindowEnd   time.Time  `json:"window_end"`
+	Alerts      int32      `json:"alerts"`
+	Error       string     `json:"error,omitempty"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
+
+func newAMLJobRunResponse(run db.AMLJobRun) amlJobRunResponse {
+	res := amlJobRunResponse{
+		ID:          run.ID,
+		Scenario:    run.Scenario,
+		WindowStart: run.WindowStart,
+		WindowEnd:   run.WindowEnd,
+		Alerts:      run.Alerts,
+		Error:       run.Error,
+		StartedAt:   run.StartedAt,
+	}
+	if run.FinishedAt.Valid {
+		res.FinishedAt = &run.FinishedAt.Time
+	}
+	return res
+}
+
+type listAMLJobRunsRequest struct {
+	PageID   int32 `query:"page_id" validate:"required,min=1"`
+	PageSize int32 `query:"page_size" validate:"required,min=5,max=50"`
+}
+
+// listAMLJobRuns godoc
+// @Summary List AML job runs
+// @Description List the runs of the transaction monitoring scenarios, latest first, with the window they covered, the alerts they raised and their error. Support staff only.
+// @Tags AML
+// @Produce json
+// @Param page_id query int true "Page ID for pagination"
+// @Param page_size query int true "Number of runs per page (min: 5, max: 50)"
+// @Success 200 {array} amlJobRunResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Support Required"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /aml/jobs [get]
+func (server *Server) listAMLJobRuns(ctx echo.Context) error {
+	req := new(listAMLJobRunsRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	runs, err := server.store.ListAMLJobRuns(ctx.Request().Context(), db.ListAMLJobRunsParams{
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	res := make([]amlJobRunResponse, len(runs))
+	for i, run := range runs {
+		res[i] = newAMLJobRunResponse(run)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,478 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/monitoring"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func randomAMLAlert(user db.User, status string) db.AMLAlert {
+	return db.AMLAlert{
+		ID:        utils.RandomInt(1, 1000),
+		Scenario:  monitoring.ScenarioStructuring,
+		Username:  user.Username,
+		AccountID: utils.RandomInt(1, 1000),
+		Summary:   "3 payments just under 1000000 EUR, 2900000 in total",
+		Evidence:  json.RawMessage(`{"window":{"start":"2024-03-01T00:00:00Z","end":"2024-03-08T00:00:00Z"},"payment_ids":[1,2,3],"entry_ids":[],"facts":{"payments":3}}`),
+		Status:    status,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+		UpdatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func TestListAMLAlertsAPI(t *testing.T) {
+	support := randomSupport(t)
+	customer, _ := randomUser(t)
+	alerts := []db.AMLAlert{randomAMLAlert(customer, db.AMLAlertOpen), randomAMLAlert(customer, db.AMLAlertOpen)}
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			user:  support,
+			query: "status=open&page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().
+					ListAMLAlerts(gomock.Any(), gomock.Eq(db.ListAMLAlertsParams{
+						Status: db.AMLAlertOpen,
+						Limit:  5,
+						Offset: 0,
+					})).
+					Times(1).
+					Return(alerts, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res []amlAlertResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Len(t, res, 2)
+				require.Equal(t, alerts[0].ID, res[0].ID)
+				require.JSONEq(t, string(alerts[0].Evidence), string(res[0].Evidence))
+			},
+		},
+		{
+			name:  "AssignedTo",
+			user:  support,
+			query: "status=open&assigned_to=" + support.Username + "&page_id=2&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().
+					ListAMLAlerts(gomock.Any(), gomock.Eq(db.ListAMLAlertsParams{
+						Status:     db.AMLAlertOpen,
+						AssignedTo: sql.NullString{String: support.Username, Valid: true},
+						Limit:      5,
+						Offset:     5,
+					})).
+					Times(1).
+					Return([]db.AMLAlert{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidStatus",
+			user:  support,
+			query: "status=pending&page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().ListAMLAlerts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name:  "Customer",
+			user:  customer,
+			query: "status=open&page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, customer)
+				store.EXPECT().ListAMLAlerts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeSupportRequired)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/aml/alerts?"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAMLAlertCaseAPI(t *testing.T) {
+	support := randomSupport(t)
+	colleague := randomSupport(t)
+	customer, _ := randomUser(t)
+	alert := randomAMLAlert(customer, db.AMLAlertOpen)
+	path := "/aml/alerts/" + strconv.FormatInt(alert.ID, 10)
+
+	resolved := func(status, resolution string) db.AMLAlert {
+		resolved := alert
+		resolved.Status = status
+		resolved.Resolution = resolution
+		resolved.ResolvedBy = sql.NullString{String: support.Username, Valid: true}
+		resolved.ResolvedAt = sql.NullTime{Time: time.Now().UTC(), Valid: true}
+		return resolved
+	}
+
+	testCases := []struct {
+		name          string
+		method        string
+		path          string
+		body          echo.Map
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Get",
+			method: http.MethodGet,
+			path:   path,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().GetAMLAlert(gomock.Any(), gomock.Eq(alert.ID)).Times(1).Return(alert, nil)
+				store.EXPECT().
+					ListAMLAlertComments(gomock.Any(), gomock.Eq(alert.ID)).
+					Times(1).
+					Return([]db.AMLAlertComment{{ID: 1, AlertID: alert.ID, Author: support.Username, Body: "looking"}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res amlAlertDetailResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, alert.ID, res.Alert.ID)
+				require.Len(t, res.Comments, 1)
+			},
+		},
+		{
+			name:   "GetNotFound",
+			method: http.MethodGet,
+			path:   path,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().GetAMLAlert(gomock.Any(), gomock.Any()).Times(1).Return(db.AMLAlert{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, CodeAMLAlertNotFound)
+			},
+		},
+		{
+			name:   "AssignSelf",
+			method: http.MethodPost,
+			path:   path + "/assign",
+			body:   echo.Map{},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				assigned := alert
+				assigned.AssignedTo = sql.NullString{String: support.Username, Valid: true}
+				store.EXPECT().
+					AssignAMLAlert(gomock.Any(), gomock.Eq(db.AssignAMLAlertParams{
+						ID:         alert.ID,
+						AssignedTo: sql.NullString{String: support.Username, Valid: true},
+					})).
+					Times(1).
+					Return(assigned, nil)
+				expectAuditEvent(store, audit.ActionAMLAssign, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res amlAlertResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, support.Username, res.AssignedTo)
+			},
+		},
+		{
+			name:   "AssignColleague",
+			method: http.MethodPost,
+			path:   path + "/assign",
+			body:   echo.Map{"assignee": colleague.Username},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				expectUser(store, colleague)
+				assigned := alert
+				assigned.AssignedTo = sql.NullString{String: colleague.Username, Valid: true}
+				store.EXPECT().
+					AssignAMLAlert(gomock.Any(), gomock.Eq(db.AssignAMLAlertParams{
+						ID:         alert.ID,
+						AssignedTo: sql.NullString{String: colleague.Username, Valid: true},
+					})).
+					Times(1).
+					Return(assigned, nil)
+				expectAuditEvent(store, audit.ActionAMLAssign, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "AssignCustomer",
+			method: http.MethodPost,
+			path:   path + "/assign",
+			body:   echo.Map{"assignee": customer.Username},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				expectUser(store, customer)
+				store.EXPECT().AssignAMLAlert(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, CodeAssigneeNotStaff)
+			},
+		},
+		{
+			name:   "AssignClosed",
+			method: http.MethodPost,
+			path:   path + "/assign",
+			body:   echo.Map{},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().AssignAMLAlert(gomock.Any(), gomock.Any()).Times(1).Return(db.AMLAlert{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetAMLAlert(gomock.Any(), gomock.Eq(alert.ID)).
+					Times(1).
+					Return(resolved(db.AMLAlertClosed, "salary"), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, CodeAMLAlertNotOpen)
+			},
+		},
+		{
+			name:   "Comment",
+			method: http.MethodPost,
+			path:   path + "/comments",
+			body:   echo.Map{"body": "asked the customer for invoices"},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().GetAMLAlert(gomock.Any(), gomock.Eq(alert.ID)).Times(1).Return(alert, nil)
+				store.EXPECT().
+					CreateAMLAlertComment(gomock.Any(), gomock.Eq(db.CreateAMLAlertCommentParams{
+						AlertID: alert.ID,
+						Author:  support.Username,
+						Body:    "asked the customer for invoices",
+					})).
+					Times(1).
+					Return(db.AMLAlertComment{ID: 1, AlertID: alert.ID, Author: support.Username, Body: "asked the customer for invoices"}, nil)
+				expectAuditEvent(store, audit.ActionAMLComment, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:   "CommentEmpty",
+			method: http.MethodPost,
+			path:   path + "/comments",
+			body:   echo.Map{"body": ""},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().CreateAMLAlertComment(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name:   "Close",
+			method: http.MethodPost,
+			path:   path + "/close",
+			body:   echo.Map{"resolution": "monthly rent split between flatmates"},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().
+					ResolveAMLAlert(gomock.Any(), gomock.Eq(db.ResolveAMLAlertParams{
+						ID:         alert.ID,
+						Status:     db.AMLAlertClosed,
+						Resolution: "monthly rent split between flatmates",
+						ResolvedBy: sql.NullString{String: support.Username, Valid: true},
+					})).
+					Times(1).
+					Return(resolved(db.AMLAlertClosed, "monthly rent split between flatmates"), nil)
+				expectAuditEvent(store, audit.ActionAMLClose, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res amlAlertResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, db.AMLAlertClosed, res.Status)
+				require.Equal(t, support.Username, res.ResolvedBy)
+				require.NotNil(t, res.ResolvedAt)
+			},
+		},
+		{
+			name:   "Escalate",
+			method: http.MethodPost,
+			path:   path + "/escalate",
+			body:   echo.Map{"resolution": "no business reason for the payments"},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().
+					ResolveAMLAlert(gomock.Any(), gomock.Cond(func(x any) bool {
+						arg, ok := x.(db.ResolveAMLAlertParams)
+						return ok && arg.Status == db.AMLAlertEscalated
+					})).
+					Times(1).
+					Return(resolved(db.AMLAlertEscalated, "no business reason for the payments"), nil)
+				expectAuditEvent(store, audit.ActionAMLEscalate, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "EscalateNotFound",
+			method: http.MethodPost,
+			path:   path + "/escalate",
+			body:   echo.Map{"resolution": "no business reason for the payments"},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().ResolveAMLAlert(gomock.Any(), gomock.Any()).Times(1).Return(db.AMLAlert{}, sql.ErrNoRows)
+				store.EXPECT().GetAMLAlert(gomock.Any(), gomock.Any()).Times(1).Return(db.AMLAlert{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, CodeAMLAlertNotFound)
+			},
+		},
+		{
+			name:   "ExportSAR",
+			method: http.MethodGet,
+			path:   path + "/sar",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().
+					GetAMLAlert(gomock.Any(), gomock.Eq(alert.ID)).
+					Times(1).
+					Return(resolved(db.AMLAlertEscalated, "no business reason for the payments"), nil)
+				expectUser(store, customer)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(alert.AccountID)).Times(1).Return(randomAccount(customer.Username), nil)
+				store.EXPECT().GetKYCProfile(gomock.Any(), gomock.Eq(customer.Username)).Times(1).Return(randomKYCProfile(customer.Username), nil)
+				store.EXPECT().
+					ListPaymentsByID(gomock.Any(), gomock.Eq([]int64{1, 2, 3})).
+					Times(1).
+					Return([]db.Payment{{ID: 1}, {ID: 2}, {ID: 3}}, nil)
+				store.EXPECT().ListEntriesByID(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListAMLAlertComments(gomock.Any(), gomock.Eq(alert.ID)).Times(1).Return([]db.AMLAlertComment{}, nil)
+				expectAuditEvent(store, audit.ActionAMLSARExport, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Header().Get(echo.HeaderContentDisposition), "attachment")
+
+				var report monitoring.SAR
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+				require.Equal(t, "SAR-"+strconv.FormatInt(alert.ID, 10), report.ReportID)
+				require.Equal(t, support.Username, report.GeneratedBy)
+				require.Equal(t, customer.Username, report.Subject.Username)
+				require.Len(t, report.Activity.Transactions, 3)
+				require.Equal(t, "no business reason for the payments", report.Narrative)
+			},
+		},
+		{
+			name:   "ExportSARNotEscalated",
+			method: http.MethodGet,
+			path:   path + "/sar",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().GetAMLAlert(gomock.Any(), gomock.Eq(alert.ID)).Times(1).Return(alert, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, CodeAMLAlertNotEscalated)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var body bytes.Buffer
+			if tc.body != nil {
+				require.NoError(t, json.NewEncoder(&body).Encode(tc.body))
+			}
+			request, err := http.NewRequest(tc.method, tc.path, &body)
+			require.NoError(t, err)
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, support.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListAMLJobRunsAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	support := randomSupport(t)
+	expectUser(store, support)
+	store.EXPECT().
+		ListAMLJobRuns(gomock.Any(), gomock.Eq(db.ListAMLJobRunsParams{Limit: 10, Offset: 0})).
+		Times(1).
+		Return([]db.AMLJobRun{{ID: 1, Scenario: monitoring.ScenarioDormant, Alerts: 2}}, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/aml/jobs?page_id=1&page_size=10", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, support.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var res []db.AMLJobRun
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+	require.Len(t, res, 1)
+	require.Equal(t, int32(2), res[0].Alerts)
+}
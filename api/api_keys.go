@@ -0,0 +1,226 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/labstack/echo/v4"
+)
+
+// Scopes an API key can be granted. Each route that accepts API keys names
+// the scopes it needs.
+const (
+	ScopeAccountsRead      = "accounts:read"
+	ScopeAccountsWrite     = "accounts:write"
+	ScopePaymentsWrite     = "payments:write"
+	ScopeNotificationsRead = "notifications:read"
+)
+
+type createAPIKeyRequest struct {
+	Name       string     `json:"name" validate:"required,max=100"`
+	Scopes     []string   `json:"scopes" validate:"required,min=1,dive,oneof=accounts:read accounts:write payments:write notifications:read"`
+	AllowedIPs []string   `json:"allowed_ips" validate:"omitempty,dive,cidr|ip"`
+	ExpiresAt  *time.Time `json:"expires_at" validate:"omitempty,gt"`
+}
+
+type apiKeyResponse struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	AllowedIPs []string   `json:"allowed_ips"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func newAPIKeyResponse(apiKey db.APIKey) apiKeyResponse {
+	res := apiKeyResponse{
+		ID:         apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     apiKey.Scopes,
+		AllowedIPs: apiKey.AllowedIPs,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+	if apiKey.ExpiresAt.Valid {
+		res.ExpiresAt = &apiKey.ExpiresAt.Time
+	}
+	if apiKey.LastUsedAt.Valid {
+		res.LastUsedAt = &apiKey.LastUsedAt.Time
+	}
+	if apiKey.RevokedAt.Valid {
+		res.RevokedAt = &apiKey.RevokedAt.Time
+	}
+	return res
+}
+
+type createAPIKeyResponse struct {
+	APIKey apiKeyResponse `json:"api_key"`
+	// Key is only returned once; only its hash is stored.
+	Key string `json:"key"`
+}
+
+// createAPIKey godoc
+// @Summary Create an API key
+// @Description Create a key for a backend to call the API as the authenticated user, sent like an access token as `Authorization: Bearer <key>`. The key is only shown in this response. It is limited to its scopes (accounts:read, accounts:write, payments:write, notifications:read), to the allowed IPs or CIDRs if any are given, and stops working at expires_at. API keys can't create other keys.
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Param request body createAPIKeyRequest true "Request body for creating an API key"
+// @Success 201 {object} createAPIKeyResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Insufficient Scope"
+// @Failure 429 {object} Problem "Rate Limited"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /api-keys [post]
+func (server *Server) createAPIKey(ctx echo.Context) error {
+	req := new(createAPIKeyRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	key, prefix, err := db.NewAPIKey()
+	if err != nil {
+		return err
+	}
+
+	args := db.CreateAPIKeyParams{
+		Username:   authUser(ctx).Username,
+		Name:       req.Name,
+		Prefix:     prefix,
+		KeyHash:    db.HashAPIKey(key),
+		Scopes:     req.Scopes,
+		AllowedIPs: make([]string, len(req.AllowedIPs)),
+	}
+	for i, ip := range req.AllowedIPs {
+		args.AllowedIPs[i] = normalizeCIDR(ip)
+	}
+	if req.ExpiresAt != nil {
+		args.ExpiresAt = sql.NullTime{Time: *req.ExpiresAt, Valid: true}
+	}
+
+	apiKey, err := server.store.CreateAPIKey(ctx.Request().Context(), args)
+	if err != nil {
+		return err
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionAPIKeyCreate,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceAPIKey,
+		ResourceID:   strconv.FormatInt(apiKey.ID, 10),
+		Metadata: map[string]interface{}{
+			"prefix":      apiKey.Prefix,
+			"scopes":      apiKey.Scopes,
+			"allowed_ips": apiKey.AllowedIPs,
+		},
+	})
+
+	return ctx.JSON(http.StatusCreated, createAPIKeyResponse{
+		APIKey: newAPIKeyResponse(apiKey),
+		Key:    key,
+	})
+}
+
+// normalizeCIDR turns a single address into a CIDR of one address, so the
+// allowlist only holds CIDRs. ip was validated as an IP or a CIDR.
+func normalizeCIDR(ip string) string {
+	if _, network, err := net.ParseCIDR(ip); err == nil {
+		return network.String()
+	}
+
+	addr := net.ParseIP(ip)
+	if addr.To4() != nil {
+		return addr.String() + "/32"
+	}
+	return addr.String() + "/128"
+}
+
+// listAPIKeys godoc
+// @Summary List API keys
+// @Description List the API keys of the authenticated user, including revoked ones. The keys themselves can't be shown again; the prefix tells them apart.
+// @Tags API Keys
+// @Produce json
+// @Success 200 {array} apiKeyResponse
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Insufficient Scope"
+// @Failure 429 {object} Problem "Rate Limited"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /api-keys [get]
+func (server *Server) listAPIKeys(ctx echo.Context) error {
+	apiKeys, err := server.store.ListAPIKeys(ctx.Request().Context(), authUser(ctx).Username)
+	if err != nil {
+		return err
+	}
+
+	res := make([]apiKeyResponse, len(apiKeys))
+	for i, apiKey := range apiKeys {
+		res[i] = newAPIKeyResponse(apiKey)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+type revokeAPIKeyRequest struct {
+	ID int64 `param:"id" validate:"required,min=1"`
+}
+
+// revokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Revoke an API key of the authenticated user. Requests made with it fail from then on.
+// @Tags API Keys
+// @Produce json
+// @Param id path int true "API key ID"
+// @Success 200 {object} apiKeyResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Insufficient Scope"
+// @Failure 404 {object} Problem "API Key Not Found"
+// @Failure 429 {object} Problem "Rate Limited"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /api-keys/{id} [delete]
+func (server *Server) revokeAPIKey(ctx echo.Context) error {
+	req := new(revokeAPIKeyRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	apiKey, err := server.store.RevokeAPIKey(ctx.Request().Context(), db.RevokeAPIKeyParams{
+		ID:       req.ID,
+		Username: authUser(ctx).Username,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// someone else's key or one that was already revoked
+			return newProblem(http.StatusNotFound, CodeAPIKeyNotFound, fmt.Sprintf("active API key [%d] not found", req.ID))
+		}
+		return err
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionAPIKeyRevoke,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceAPIKey,
+		ResourceID:   strconv.FormatInt(apiKey.ID, 10),
+		Metadata:     map[string]interface{}{"prefix": apiKey.Prefix},
+	})
+
+	return ctx.JSON(http.StatusOK, newAPIKeyResponse(apiKey))
+}
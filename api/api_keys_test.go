@@ -0,0 +1,313 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateAPIKeyAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	expiresAt := time.Now().Add(30 * 24 * time.Hour).UTC().Truncate(time.Second)
+
+	testCases := []struct {
+		name          string
+		body          map[string]interface{}
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: map[string]interface{}{
+				"name":        "payroll",
+				"scopes":      []string{ScopeAccountsRead, ScopePaymentsWrite},
+				"allowed_ips": []string{"192.0.2.10", "10.1.2.3/8", "2001:db8::1"},
+				"expires_at":  expiresAt,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().
+					CreateAPIKey(gomock.Any(), gomock.Cond(func(x any) bool {
+						arg, ok := x.(db.CreateAPIKeyParams)
+						return ok &&
+							arg.Username == user.Username &&
+							arg.Name == "payroll" &&
+							strings.HasPrefix(arg.Prefix, db.APIKeyPrefix) &&
+							len(arg.KeyHash) == 64 &&
+							fmt.Sprint(arg.Scopes) == fmt.Sprint([]string{ScopeAccountsRead, ScopePaymentsWrite}) &&
+							fmt.Sprint(arg.AllowedIPs) == fmt.Sprint([]string{"192.0.2.10/32", "10.0.0.0/8", "2001:db8::1/128"}) &&
+							arg.ExpiresAt.Valid && arg.ExpiresAt.Time.Equal(expiresAt)
+					})).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateAPIKeyParams) (db.APIKey, error) {
+						return db.APIKey{
+							ID:         1,
+							Username:   arg.Username,
+							Name:       arg.Name,
+							Prefix:     arg.Prefix,
+							KeyHash:    arg.KeyHash,
+							Scopes:     arg.Scopes,
+							AllowedIPs: arg.AllowedIPs,
+							ExpiresAt:  arg.ExpiresAt,
+							CreatedAt:  time.Now(),
+						}, nil
+					})
+				expectAuditEvent(store, audit.ActionAPIKeyCreate, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				require.NotContains(t, recorder.Body.String(), "key_hash")
+
+				var got createAPIKeyResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.True(t, strings.HasPrefix(got.Key, got.APIKey.Prefix+"_"))
+				require.Equal(t, "payroll", got.APIKey.Name)
+				require.NotNil(t, got.APIKey.ExpiresAt)
+				require.Nil(t, got.APIKey.RevokedAt)
+			},
+		},
+		{
+			name: "UnknownScope",
+			body: map[string]interface{}{
+				"name":   "payroll",
+				"scopes": []string{"adjustments:write"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name: "NoScopes",
+			body: map[string]interface{}{
+				"name":   "payroll",
+				"scopes": []string{},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidIP",
+			body: map[string]interface{}{
+				"name":        "payroll",
+				"scopes":      []string{ScopeAccountsRead},
+				"allowed_ips": []string{"localhost"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name: "ExpiryInThePast",
+			body: map[string]interface{}{
+				"name":       "payroll",
+				"scopes":     []string{ScopeAccountsRead},
+				"expires_at": time.Now().Add(-time.Hour),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/api-keys", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateAPIKeyWithAPIKey(t *testing.T) {
+	user, _ := randomUser(t)
+	key, apiKey := randomAPIKey(t, user, ScopeAccountsRead, ScopeAccountsWrite, ScopePaymentsWrite, ScopeNotificationsRead)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// a leaked key must not be able to mint more keys
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAPIKeyByHash(gomock.Any(), gomock.Eq(apiKey.KeyHash)).Times(1).Return(apiKey, nil)
+	expectUser(store, user)
+	store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	body := `{"name":"more","scopes":["accounts:read"]}`
+	request := httptest.NewRequest(http.MethodPost, "/api-keys", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(authorizationHeaderKey, authorizationTypeBearer+" "+key)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+	requireProblemCode(t, recorder, CodeInsufficientScope)
+}
+
+func TestListAPIKeysAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	_, active := randomAPIKey(t, user, ScopeAccountsRead)
+	active.LastUsedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	_, revoked := randomAPIKey(t, user, ScopePaymentsWrite)
+	revoked.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	expectUser(store, user)
+	store.EXPECT().
+		ListAPIKeys(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return([]db.APIKey{active, revoked}, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api-keys", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NotContains(t, recorder.Body.String(), active.KeyHash)
+
+	var got []apiKeyResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+	require.Len(t, got, 2)
+	require.Equal(t, active.Prefix, got[0].Prefix)
+	require.NotNil(t, got[0].LastUsedAt)
+	require.Nil(t, got[0].RevokedAt)
+	require.NotNil(t, got[1].RevokedAt)
+}
+
+func TestRevokeAPIKeyAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	_, apiKey := randomAPIKey(t, user, ScopeAccountsRead)
+
+	testCases := []struct {
+		name          string
+		id            int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			id:   apiKey.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				revoked := apiKey
+				revoked.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+				store.EXPECT().
+					RevokeAPIKey(gomock.Any(), gomock.Eq(db.RevokeAPIKeyParams{ID: apiKey.ID, Username: user.Username})).
+					Times(1).
+					Return(revoked, nil)
+				expectAuditEvent(store, audit.ActionAPIKeyRevoke, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got apiKeyResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.NotNil(t, got.RevokedAt)
+			},
+		},
+		{
+			name: "NotFound",
+			id:   apiKey.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RevokeAPIKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.APIKey{}, sql.ErrNoRows)
+				store.EXPECT().CreateAuditEvent(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, CodeAPIKeyNotFound)
+			},
+		},
+		{
+			name: "InvalidID",
+			id:   0,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RevokeAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectUser(store, user)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/api-keys/%d", tc.id), nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
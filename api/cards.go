@@ -0,0 +1,442 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	"github.com/danielmoisa/neobank/cards"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/labstack/echo/v4"
+)
+
+// panAttempts is how many PANs are tried when issuing a card before giving
+// up on collisions with the cards already issued.
+const panAttempts = 3
+
+type cardResponse struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	// MaskedPAN only shows the BIN and the last four digits.
+	MaskedPAN        string    `json:"masked_pan" example:"400000******1234"`
+	Last4            string    `json:"last4"`
+	ExpiryMonth      int32     `json:"expiry_month"`
+	ExpiryYear       int32     `json:"expiry_year"`
+	Frozen           bool      `json:"frozen"`
+	TransactionLimit int64     `json:"transaction_limit"`
+	DailyLimit       int64     `json:"daily_limit"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+func newCardResponse(card db.Card) cardResponse {
+	return cardResponse{
+		ID:               card.ID,
+		AccountID:        card.AccountID,
+		MaskedPAN:        cards.Mask(card.PAN),
+		Last4:            card.Last4,
+		ExpiryMonth:      card.ExpiryMonth,
+		ExpiryYear:       card.ExpiryYear,
+		Frozen:           card.Frozen,
+		TransactionLimit: card.TransactionLimit,
+		DailyLimit:       card.DailyLimit,
+		CreatedAt:        card.CreatedAt,
+	}
+}
+
+type createCardRequest struct {
+	AccountID int64 `json:"account_id" validate:"required,min=1"`
+	// TransactionLimit and DailyLimit default to 1000.00 and 2500.00.
+	TransactionLimit int64 `json:"transaction_limit" validate:"omitempty,min=1"`
+	DailyLimit       int64 `json:"daily_limit" validate:"omitempty,min=1"`
+}
+
+type createCardResponse struct {
+	Card cardResponse `json:"card"`
+	// PAN and CVV are only ever shown here.
+	PAN string `json:"pan"`
+	CVV string `json:"cvv"`
+}
+
+// createCard godoc
+// @Summary Issue a virtual card
+// @Description Issue a virtual debit card spending from a checking account of the authenticated user. The full card number and CVV are only returned in this response. Authorizations above the per-transaction limit, or taking the card above its daily limit, are declined. Only verified customers may get cards.
+// @Tags Cards
+// @Accept json
+// @Produce json
+// @Param request body createCardRequest true "Card"
+// @Success 201 {object} createCardResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "KYC Required or Account Action Forbidden"
+// @Failure 404 {object} Problem "Account Not Found"
+// @Failure 422 {object} Problem "Card Account Invalid or Card Limits Invalid"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /cards [post]
+func (server *Server) createCard(ctx echo.Context) error {
+	req := new(createCardRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	if req.TransactionLimit == 0 {
+		req.TransactionLimit = cards.DefaultTransactionLimit
+	}
+	if req.DailyLimit == 0 {
+		req.DailyLimit = max(cards.DefaultDailyLimit, req.TransactionLimit)
+	}
+	if req.TransactionLimit > req.DailyLimit {
+		return newProblem(http.StatusUnprocessableEntity, CodeCardLimitsInvalid, "the transaction limit must not be more than the daily limit")
+	}
+
+	user := authUser(ctx)
+	if user.KYCStatus != db.KYCVerified {
+		return newProblem(http.StatusForbidden, CodeKYCRequired, "cards are only issued to verified customers")
+	}
+
+	account, err := server.store.GetAccount(ctx.Request().Context(), req.AccountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return newProblem(http.StatusNotFound, CodeAccountNotFound, "account not found")
+		}
+		return err
+	}
+	if _, err := server.authorizeAccount(ctx, account, db.AccountActionManage); err != nil {
+		return err
+	}
+	if account.Type != db.AccountChecking {
+		return newProblem(http.StatusUnprocessableEntity, CodeCardAccountInvalid, "cards are only issued on checking accounts")
+	}
+
+	var card db.Card
+	var details cards.Details
+	for attempt := 1; ; attempt++ {
+		details, err = cards.NewDetails(server.cardBIN, time.Now())
+		if err != nil {
+			return err
+		}
+
+		card, err = server.store.CreateCard(ctx.Request().Context(), db.CreateCardParams{
+			AccountID:        account.ID,
+			Owner:            user.Username,
+			PAN:              details.PAN,
+			Last4:            details.PAN[len(details.PAN)-4:],
+			ExpiryMonth:      details.ExpiryMonth,
+			ExpiryYear:       details.ExpiryYear,
+			HashedCVV:        details.HashedCVV,
+			TransactionLimit: req.TransactionLimit,
+			DailyLimit:       req.DailyLimit,
+		})
+		// another card got the same number
+		if isUniqueViolation(err) && attempt < panAttempts {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		break
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionCardIssue,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceCard,
+		ResourceID:   strconv.FormatInt(card.ID, 10),
+		Metadata: map[string]interface{}{
+			"account_id":        account.ID,
+			"last4":             card.Last4,
+			"transaction_limit": card.TransactionLimit,
+			"daily_limit":       card.DailyLimit,
+		},
+	})
+
+	return ctx.JSON(http.StatusCreated, createCardResponse{
+		Card: newCardResponse(card),
+		PAN:  details.PAN,
+		CVV:  details.CVV,
+	})
+}
+
+// listCards godoc
+// @Summary List cards
+// @Description Get the cards of the authenticated user, oldest first.
+// @Tags Cards
+// @Produce json
+// @Success 200 {array} cardResponse
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /cards [get]
+func (server *Server) listCards(ctx echo.Context) error {
+	list, err := server.store.ListCards(ctx.Request().Context(), authUser(ctx).Username)
+	if err != nil {
+		return err
+	}
+
+	res := make([]cardResponse, len(list))
+	for i, card := range list {
+		res[i] = newCardResponse(card)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// visibleCard loads the card of the id path parameter, if it was issued to
+// the authenticated user or they are staff.
+func (server *Server) visibleCard(ctx echo.Context) (db.Card, error) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil || id < 1 {
+		return db.Card{}, newProblem(http.StatusBadRequest, CodeInvalidRequest, "invalid card id")
+	}
+
+	notFound := newProblem(http.StatusNotFound, CodeCardNotFound, fmt.Sprintf("card [%d] not found", id))
+	card, err := server.store.GetCard(ctx.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return card, notFound
+		}
+		return card, err
+	}
+
+	user := authUser(ctx)
+	if card.Owner != user.Username && !isStaff(user) {
+		return card, notFound
+	}
+	return card, nil
+}
+
+// getCard godoc
+// @Summary Get a card
+// @Description Get a card of the authenticated user. The card number is masked.
+// @Tags Cards
+// @Produce json
+// @Param id path int true "Card ID"
+// @Success 200 {object} cardResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 404 {object} Problem "Card Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /cards/{id} [get]
+func (server *Server) getCard(ctx echo.Context) error {
+	card, err := server.visibleCard(ctx)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, newCardResponse(card))
+}
+
+type updateCardRequest struct {
+	// Frozen cards have every authorization declined.
+	Frozen           *bool  `json:"frozen"`
+	TransactionLimit *int64 `json:"transaction_limit" validate:"omitempty,min=1"`
+	DailyLimit       *int64 `json:"daily_limit" validate:"omitempty,min=1"`
+}
+
+// updateCard godoc
+// @Summary Update a card
+// @Description Freeze or unfreeze a card of the authenticated user, or change its limits. Fields left out are kept. Holds already placed are not affected.
+// @Tags Cards
+// @Accept json
+// @Produce json
+// @Param id path int true "Card ID"
+// @Param request body updateCardRequest true "Card changes"
+// @Success 200 {object} cardResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 404 {object} Problem "Card Not Found"
+// @Failure 422 {object} Problem "Card Limits Invalid"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /cards/{id} [patch]
+func (server *Server) updateCard(ctx echo.Context) error {
+	card, err := server.visibleCard(ctx)
+	if err != nil {
+		return err
+	}
+	// staff may look at cards, only cardholders change them
+	if card.Owner != authUser(ctx).Username {
+		return newProblem(http.StatusNotFound, CodeCardNotFound, fmt.Sprintf("card [%d] not found", card.ID))
+	}
+
+	req := new(updateCardRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	params := db.UpdateCardParams{
+		Frozen:           card.Frozen,
+		TransactionLimit: card.TransactionLimit,
+		DailyLimit:       card.DailyLimit,
+		ID:               card.ID,
+	}
+	if req.Frozen != nil {
+		params.Frozen = *req.Frozen
+	}
+	if req.TransactionLimit != nil {
+		params.TransactionLimit = *req.TransactionLimit
+	}
+	if req.DailyLimit != nil {
+		params.DailyLimit = *req.DailyLimit
+	}
+	if params.TransactionLimit > params.DailyLimit {
+		return newProblem(http.StatusUnprocessableEntity, CodeCardLimitsInvalid, "the transaction limit must not be more than the daily limit")
+	}
+
+	updated, err := server.store.UpdateCard(ctx.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionCardUpdate,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceCard,
+		ResourceID:   strconv.FormatInt(card.ID, 10),
+		Metadata: map[string]interface{}{
+			"frozen":            updated.Frozen,
+			"transaction_limit": updated.TransactionLimit,
+			"daily_limit":       updated.DailyLimit,
+		},
+	})
+
+	return ctx.JSON(http.StatusOK, newCardResponse(updated))
+}
+
+type cardAuthorizationResponse struct {
+	ID       int64  `json:"id"`
+	STAN     string `json:"stan"`
+	RRN      string `json:"rrn"`
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	Merchant string `json:"merchant"`
+	MCC      string `json:"mcc"`
+	Status   string `json:"status"`
+	// ResponseCode is the ISO 8583 response code the switch answered with:
+	// 00 when approved.
+	ResponseCode   string `json:"response_code"`
+	AuthCode       string `json:"auth_code,omitempty"`
+	CapturedAmount int64  `json:"captured_amount"`
+	// EntryID is the entry that debited the account on capture.
+	EntryID    *int64     `json:"entry_id,omitempty"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	CapturedAt *time.Time `json:"captured_at,omitempty"`
+	ReversedAt *time.Time `json:"reversed_at,omitempty"`
+}
+
+func newCardAuthorizationResponse(authorization db.CardAuthorization) cardAuthorizationResponse {
+	res := cardAuthorizationResponse{
+		ID:             authorization.ID,
+		STAN:           authorization.STAN,
+		RRN:            authorization.RRN,
+		Amount:         authorization.Amount,
+		Currency:       authorization.Currency,
+		Merchant:       authorization.Merchant,
+		MCC:            authorization.MCC,
+		Status:         authorization.Status,
+		ResponseCode:   authorization.ResponseCode,
+		AuthCode:       authorization.AuthCode,
+		CapturedAmount: authorization.CapturedAmount,
+		ExpiresAt:      authorization.ExpiresAt,
+		CreatedAt:      authorization.CreatedAt,
+	}
+	if authorization.EntryID.Valid {
+		res.EntryID = &authorization.EntryID.Int64
+	}
+	if authorization.CapturedAt.Valid {
+		res.CapturedAt = &authorization.CapturedAt.Time
+	}
+	if authorization.ReversedAt.Valid {
+		res.ReversedAt = &authorization.ReversedAt.Time
+	}
+	return res
+}
+
+type listCardAuthorizationsRequest struct {
+	PageID   int32 `query:"page_id" validate:"required,min=1"`
+	PageSize int32 `query:"page_size" validate:"required,min=5,max=50"`
+}
+
+// listCardAuthorizations godoc
+// @Summary List card authorizations
+// @Description Get the authorizations of a card of the authenticated user, latest first, with pagination: approved ones holding their amount on the account, declined ones with their response code, and those captured or reversed since.
+// @Tags Cards
+// @Produce json
+// @Param id path int true "Card ID"
+// @Param page_id query int true "Page ID for pagination"
+// @Param page_size query int true "Number of authorizations per page (min: 5, max: 50)"
+// @Success 200 {array} cardAuthorizationResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 404 {object} Problem "Card Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /cards/{id}/authorizations [get]
+func (server *Server) listCardAuthorizations(ctx echo.Context) error {
+	card, err := server.visibleCard(ctx)
+	if err != nil {
+		return err
+	}
+
+	req := new(listCardAuthorizationsRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	list, err := server.store.ListCardAuthorizations(ctx.Request().Context(), db.ListCardAuthorizationsParams{
+		CardID: card.ID,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	res := make([]cardAuthorizationResponse, len(list))
+	for i, authorization := range list {
+		res[i] = newCardAuthorizationResponse(authorization)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// simulateCardMessage godoc
+// @Summary Send a message to the card switch
+// @Description Play the card network against the local authorization switch. Send 0100 to authorize a payment and hold its amount on the account, 0220 to capture an approved authorization into entries, and 0400 or 0420 to reverse one, found by the card number (field 2) and RRN (field 37). Amounts (field 4) are 12 digits in minor units and currencies (field 49) ISO 4217 numeric codes. The response carries the response code in field 39, 00 when approved. Only admins may use the simulator.
+// @Tags Cards
+// @Accept json
+// @Produce json
+// @Param request body cards.Message true "ISO 8583 message"
+// @Success 200 {object} cards.Message
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Admin Required"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /cards/switch [post]
+func (server *Server) simulateCardMessage(ctx echo.Context) error {
+	req := new(cards.Message)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	res, err := server.cardSwitch.Handle(ctx.Request().Context(), *req)
+	if err != nil {
+		if errors.Is(err, cards.ErrInvalidMTI) {
+			return newProblem(http.StatusBadRequest, CodeInvalidRequest, err.Error())
+		}
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,550 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	"github.com/danielmoisa/neobank/cards"
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/labstack/echo/v4"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func randomCard(owner string, accountID int64) db.Card {
+	return db.Card{
+		ID:               utils.RandomInt(1, 1000),
+		AccountID:        accountID,
+		Owner:            owner,
+		PAN:              "4000001234567899",
+		Last4:            "7899",
+		ExpiryMonth:      5,
+		ExpiryYear:       2028,
+		HashedCVV:        utils.RandomString(60),
+		TransactionLimit: 50_000,
+		DailyLimit:       80_000,
+		CreatedAt:        time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func TestCreateCardAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	unverified, _ := randomUser(t)
+	unverified.KYCStatus = db.KYCPending
+	account := randomAccount(user.Username)
+	account.Type = db.AccountChecking
+
+	// cardFor answers CreateCard with the card it was asked to create
+	cardFor := func(id int64) func(ctx context.Context, arg db.CreateCardParams) (db.Card, error) {
+		return func(_ context.Context, arg db.CreateCardParams) (db.Card, error) {
+			return db.Card{
+				ID:               id,
+				AccountID:        arg.AccountID,
+				Owner:            arg.Owner,
+				PAN:              arg.PAN,
+				Last4:            arg.Last4,
+				ExpiryMonth:      arg.ExpiryMonth,
+				ExpiryYear:       arg.ExpiryYear,
+				HashedCVV:        arg.HashedCVV,
+				TransactionLimit: arg.TransactionLimit,
+				DailyLimit:       arg.DailyLimit,
+			}, nil
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		body          echo.Map
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			user: user,
+			body: echo.Map{"account_id": account.ID, "transaction_limit": 20_000, "daily_limit": 50_000},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					CreateCard(gomock.Any(), gomock.Cond(func(x any) bool {
+						arg := x.(db.CreateCardParams)
+						return arg.AccountID == account.ID && arg.Owner == user.Username &&
+							strings.HasPrefix(arg.PAN, cards.DefaultBIN) && cards.LuhnValid(arg.PAN) &&
+							arg.Last4 == arg.PAN[12:] && arg.HashedCVV != "" &&
+							arg.TransactionLimit == 20_000 && arg.DailyLimit == 50_000
+					})).
+					Times(1).
+					DoAndReturn(cardFor(1))
+				expectAuditEvent(store, audit.ActionCardIssue, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var res createCardResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Len(t, res.PAN, cards.PANLength)
+				require.Len(t, res.CVV, 3)
+				require.Equal(t, cards.Mask(res.PAN), res.Card.MaskedPAN)
+				require.Equal(t, int64(20_000), res.Card.TransactionLimit)
+				require.NotContains(t, recorder.Body.String(), "hashed_cvv")
+			},
+		},
+		{
+			name: "DefaultLimits",
+			user: user,
+			body: echo.Map{"account_id": account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					CreateCard(gomock.Any(), gomock.Cond(func(x any) bool {
+						arg := x.(db.CreateCardParams)
+						return arg.TransactionLimit == cards.DefaultTransactionLimit && arg.DailyLimit == cards.DefaultDailyLimit
+					})).
+					Times(1).
+					DoAndReturn(cardFor(1))
+				expectAuditEvent(store, audit.ActionCardIssue, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "PANCollision",
+			user: user,
+			body: echo.Map{"account_id": account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				gomock.InOrder(
+					store.EXPECT().
+						CreateCard(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(db.Card{}, &pq.Error{Code: pqUniqueViolation}),
+					store.EXPECT().
+						CreateCard(gomock.Any(), gomock.Any()).
+						Times(1).
+						DoAndReturn(cardFor(2)),
+				)
+				expectAuditEvent(store, audit.ActionCardIssue, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "TransactionLimitOverDailyLimit",
+			user: user,
+			body: echo.Map{"account_id": account.ID, "transaction_limit": 60_000, "daily_limit": 50_000},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().CreateCard(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, CodeCardLimitsInvalid)
+			},
+		},
+		{
+			name: "NegativeLimit",
+			user: user,
+			body: echo.Map{"account_id": account.ID, "transaction_limit": -1},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().CreateCard(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name: "KYCRequired",
+			user: unverified,
+			body: echo.Map{"account_id": account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, unverified)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateCard(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeKYCRequired)
+			},
+		},
+		{
+			name: "AccountNotOwned",
+			user: user,
+			body: echo.Map{"account_id": account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				other := randomAccount("someone_else")
+				other.ID = account.ID
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(other, nil)
+				expectNoMembership(store)
+				store.EXPECT().CreateCard(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireProblemCode(t, recorder, CodeAccountNotOwned)
+			},
+		},
+		{
+			name: "SavingsAccount",
+			user: user,
+			body: echo.Map{"account_id": account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				savings := account
+				savings.Type = db.AccountSavings
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(savings, nil)
+				store.EXPECT().CreateCard(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, CodeCardAccountInvalid)
+			},
+		},
+		{
+			name: "AccountNotFound",
+			user: user,
+			body: echo.Map{"account_id": account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().CreateCard(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, CodeAccountNotFound)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/cards", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListCardsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	list := []db.Card{randomCard(user.Username, 1), randomCard(user.Username, 2)}
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	expectUser(store, user)
+	store.EXPECT().
+		ListCards(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(list, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/cards", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var res []cardResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+	require.Len(t, res, 2)
+	require.Equal(t, "400000******7899", res[0].MaskedPAN)
+	require.NotContains(t, recorder.Body.String(), list[0].PAN)
+	require.NotContains(t, recorder.Body.String(), list[0].HashedCVV)
+}
+
+func TestUpdateCardAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	other, _ := randomUser(t)
+	support := randomSupport(t)
+	card := randomCard(user.Username, utils.RandomInt(1, 1000))
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		body          echo.Map
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Freeze",
+			user: user,
+			body: echo.Map{"frozen": true},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().GetCard(gomock.Any(), gomock.Eq(card.ID)).Times(1).Return(card, nil)
+				frozen := card
+				frozen.Frozen = true
+				store.EXPECT().
+					UpdateCard(gomock.Any(), gomock.Eq(db.UpdateCardParams{
+						Frozen:           true,
+						TransactionLimit: card.TransactionLimit,
+						DailyLimit:       card.DailyLimit,
+						ID:               card.ID,
+					})).
+					Times(1).
+					Return(frozen, nil)
+				expectAuditEvent(store, audit.ActionCardUpdate, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res cardResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.True(t, res.Frozen)
+			},
+		},
+		{
+			name: "Limits",
+			user: user,
+			body: echo.Map{"transaction_limit": 10_000, "daily_limit": 20_000},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().GetCard(gomock.Any(), gomock.Eq(card.ID)).Times(1).Return(card, nil)
+				store.EXPECT().
+					UpdateCard(gomock.Any(), gomock.Eq(db.UpdateCardParams{
+						Frozen:           card.Frozen,
+						TransactionLimit: 10_000,
+						DailyLimit:       20_000,
+						ID:               card.ID,
+					})).
+					Times(1).
+					Return(card, nil)
+				expectAuditEvent(store, audit.ActionCardUpdate, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "DailyLimitBelowTransactionLimit",
+			user: user,
+			body: echo.Map{"daily_limit": card.TransactionLimit - 1},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().GetCard(gomock.Any(), gomock.Eq(card.ID)).Times(1).Return(card, nil)
+				store.EXPECT().UpdateCard(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, CodeCardLimitsInvalid)
+			},
+		},
+		{
+			name: "OtherUser",
+			user: other,
+			body: echo.Map{"frozen": true},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, other)
+				store.EXPECT().GetCard(gomock.Any(), gomock.Eq(card.ID)).Times(1).Return(card, nil)
+				store.EXPECT().UpdateCard(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, CodeCardNotFound)
+			},
+		},
+		{
+			name: "Support",
+			user: support,
+			body: echo.Map{"frozen": true},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().GetCard(gomock.Any(), gomock.Eq(card.ID)).Times(1).Return(card, nil)
+				store.EXPECT().UpdateCard(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, CodeCardNotFound)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/cards/%d", card.ID)
+			request, err := http.NewRequest(http.MethodPatch, url, bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListCardAuthorizationsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	card := randomCard(user.Username, utils.RandomInt(1, 1000))
+	list := []db.CardAuthorization{
+		{ID: 2, CardID: card.ID, AccountID: card.AccountID, STAN: "000124", RRN: "406912345679", Amount: 1_000, Currency: "EUR", Status: db.CardAuthDeclined, ResponseCode: cards.RespInsufficientFunds},
+		{ID: 1, CardID: card.ID, AccountID: card.AccountID, STAN: "000123", RRN: "406912345678", Amount: 2_000, Currency: "EUR", Status: db.CardAuthCaptured, ResponseCode: cards.RespApproved, AuthCode: "123456", CapturedAmount: 1_800, EntryID: sql.NullInt64{Int64: 9, Valid: true}},
+	}
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	expectUser(store, user)
+	store.EXPECT().GetCard(gomock.Any(), gomock.Eq(card.ID)).Times(1).Return(card, nil)
+	store.EXPECT().
+		ListCardAuthorizations(gomock.Any(), gomock.Eq(db.ListCardAuthorizationsParams{
+			CardID: card.ID,
+			Limit:  5,
+			Offset: 0,
+		})).
+		Times(1).
+		Return(list, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	url := fmt.Sprintf("/cards/%d/authorizations?page_id=1&page_size=5", card.ID)
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var res []cardAuthorizationResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+	require.Len(t, res, 2)
+	require.Equal(t, cards.RespInsufficientFunds, res[0].ResponseCode)
+	require.Nil(t, res[0].EntryID)
+	require.Equal(t, int64(1_800), res[1].CapturedAmount)
+	require.Equal(t, int64(9), *res[1].EntryID)
+}
+
+func TestSimulateCardMessageAPI(t *testing.T) {
+	admin := randomAdmin(t)
+	customer, _ := randomUser(t)
+
+	request := cards.Message{
+		MTI: cards.MTIReversalRequest,
+		Fields: map[int]string{
+			cards.FieldPAN: "4000001234567899",
+			cards.FieldRRN: "406912345678",
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		body          cards.Message
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "UnknownCard",
+			user: admin,
+			body: request,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, admin)
+				store.EXPECT().
+					GetCardByPAN(gomock.Any(), gomock.Eq("4000001234567899")).
+					Times(1).
+					Return(db.Card{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res cards.Message
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, cards.MTIReversalResponse, res.MTI)
+				require.Equal(t, cards.RespInvalidCard, res.Fields[cards.FieldResponseCode])
+				require.Equal(t, "406912345678", res.Fields[cards.FieldRRN])
+			},
+		},
+		{
+			name: "InvalidMTI",
+			user: admin,
+			body: cards.Message{MTI: "0800"},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, admin)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeInvalidRequest)
+			},
+		},
+		{
+			name: "Customer",
+			user: customer,
+			body: request,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, customer)
+				store.EXPECT().GetCardByPAN(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeAdminRequired)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/cards/switch", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
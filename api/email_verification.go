@@ -0,0 +1,121 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/mail"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultEmailVerificationTTL = 24 * time.Hour
+	defaultPasswordResetTTL     = time.Hour
+)
+
+type verifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// verifyEmail godoc
+// @Summary Verify an email address
+// @Description Confirm the email address of a user with the token from the verification email. Each token works once.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param request body verifyEmailRequest true "Request body for verifying an email address"
+// @Success 200 {object} userResponse
+// @Failure 400 {object} Problem "Bad Request or Invalid Token"
+// @Failure 429 {object} Problem "Rate Limited"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /users/verify-email [post]
+func (server *Server) verifyEmail(ctx echo.Context) error {
+	req := new(verifyEmailRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	user, err := server.store.VerifyEmailTx(ctx.Request().Context(), req.Token)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidUserToken) {
+			return newProblem(http.StatusBadRequest, CodeInvalidToken, err.Error())
+		}
+		return err
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Actor:        user.Username,
+		Action:       audit.ActionUserVerifyEmail,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceUser,
+		ResourceID:   user.Username,
+		Metadata:     map[string]interface{}{"email": user.Email},
+	})
+
+	return ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// resendVerificationEmail godoc
+// @Summary Resend the verification email
+// @Description Send a new verification email to the authenticated user. Links from earlier emails stop working.
+// @Tags Users
+// @Produce json
+// @Success 202
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 409 {object} Problem "Email Already Verified"
+// @Failure 429 {object} Problem "Rate Limited"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /users/verify-email/resend [post]
+func (server *Server) resendVerificationEmail(ctx echo.Context) error {
+	user := authUser(ctx)
+	if !user.EmailVerifiedAt.IsZero() {
+		return newProblem(http.StatusConflict, CodeEmailAlreadyVerified, "email is already verified")
+	}
+
+	if err := server.sendVerificationEmail(ctx, user); err != nil {
+		return err
+	}
+
+	return ctx.NoContent(http.StatusAccepted)
+}
+
+// sendVerificationEmail issues a verification token for the current email of
+// user and mails the link to it.
+func (server *Server) sendVerificationEmail(ctx echo.Context, user db.User) error {
+	ttl := server.config.EmailVerifyTTL
+	if ttl <= 0 {
+		ttl = defaultEmailVerificationTTL
+	}
+
+	result, err := server.store.IssueUserTokenTx(ctx.Request().Context(), db.IssueUserTokenParams{
+		Username: user.Username,
+		Purpose:  db.TokenEmailVerification,
+		Email:    user.Email,
+		TTL:      ttl,
+	})
+	if err != nil {
+		return err
+	}
+
+	link := mail.Link(server.config.AppBaseURL, "/verify-email", result.Token)
+	return server.mailer.Send(ctx.Request().Context(), mail.VerificationEmail(user.Email, link))
+}
+
+// sendVerificationEmailAfterSignUp doesn't fail the sign up when the email
+// can't be sent: the user can ask for another one.
+func (server *Server) sendVerificationEmailAfterSignUp(ctx echo.Context, user db.User) {
+	if err := server.sendVerificationEmail(ctx, user); err != nil {
+		slog.ErrorContext(ctx.Request().Context(), "cannot send verification email",
+			slog.String("username", user.Username),
+			slog.Any("error", err),
+		)
+	}
+}
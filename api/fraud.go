@@ -0,0 +1,347 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/events"
+	"github.com/danielmoisa/neobank/fraud"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/labstack/echo/v4"
+)
+
+// assessPayment runs the fraud rules on a payment and records the decision.
+// A challenged payment goes through when the request carries the password of
+// the payer; a blocked one is held for review and comes back with the
+// pending status. A wrong password gets the same answer as a missing one.
+func (server *Server) assessPayment(ctx echo.Context, payment fraud.Payment, password string) (db.FraudDecision, error) {
+	decision, err := server.fraud.Assess(ctx.Request().Context(), server.store, payment)
+	if err != nil {
+		return db.FraudDecision{}, err
+	}
+
+	confirmed := false
+	if decision.Outcome == db.FraudChallenge && password != "" {
+		confirmed, err = server.confirmPassword(ctx, payment.User, password)
+		if err != nil {
+			return db.FraudDecision{}, err
+		}
+	}
+
+	record, err := fraud.Record(ctx.Request().Context(), server.store, payment, decision, decision.Status(confirmed))
+	if err != nil {
+		return db.FraudDecision{}, err
+	}
+
+	switch record.Status {
+	case db.FraudChallenged:
+		server.auditHeldPayment(ctx, record, "fraud_challenge")
+		return record, newProblem(http.StatusForbidden, CodeFraudChallenge, "confirm the payment by sending it again with your password")
+	case db.FraudPending:
+		server.auditHeldPayment(ctx, record, "fraud_block")
+	}
+	return record, nil
+}
+
+// confirmPassword checks the password sent to confirm a challenged payment.
+// A wrong one counts towards the lockout like a failed login, and a locked
+// user can't confirm at all, so an access token isn't enough to guess the
+// password.
+func (server *Server) confirmPassword(ctx echo.Context, user db.User, password string) (bool, error) {
+	if user.LockedUntil.After(time.Now()) {
+		return false, nil
+	}
+	if utils.CheckPassword(user.HashedPassword, password) == nil {
+		return true, nil
+	}
+	return false, server.recordLoginFailure(ctx, user.Username)
+}
+
+func (server *Server) auditHeldPayment(ctx echo.Context, decision db.FraudDecision, reason string) {
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionPaymentCreate,
+		Outcome:      audit.OutcomeFailure,
+		ResourceType: audit.ResourcePayment,
+		Metadata: map[string]interface{}{
+			"reason":            reason,
+			"fraud_decision_id": decision.ID,
+			"from_account_id":   decision.FromAccountID,
+			"to_account_id":     decision.ToAccountID,
+			"amount":            decision.Amount,
+		},
+	})
+}
+
+// linkPayment records which payment a decision let through. The payment is
+// already made, so a failure is only logged.
+func (server *Server) linkPayment(ctx echo.Context, decision db.FraudDecision, payment db.Payment) {
+	_, err := server.store.SetFraudDecisionPayment(ctx.Request().Context(), db.SetFraudDecisionPaymentParams{
+		ID:        decision.ID,
+		PaymentID: sql.NullInt64{Int64: payment.ID, Valid: true},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx.Request().Context(), "cannot link payment to fraud decision",
+			slog.Int64("fraud_decision_id", decision.ID),
+			slog.Int64("payment_id", payment.ID),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// heldPaymentResponse answers a payment the fraud rules held for review.
+// The payer isn't told why.
+type heldPaymentResponse struct {
+	FraudDecisionID int64  `json:"fraud_decision_id"`
+	Status          string `json:"status"`
+}
+
+type fraudDecisionResponse struct {
+	ID            int64           `json:"id"`
+	Username      string          `json:"username"`
+	FromAccountID int64           `json:"from_account_id"`
+	ToAccountID   int64           `json:"to_account_id"`
+	Amount        int64           `json:"amount"`
+	Score         int32           `json:"score"`
+	Outcome       string          `json:"outcome"`
+	Status        string          `json:"status"`
+	Trace         json.RawMessage `json:"trace" swaggertype:"array,object"`
+	Features      json.RawMessage `json:"features" swaggertype:"object"`
+	PaymentID     *int64          `json:"payment_id,omitempty"`
+	ReviewedBy    string          `json:"reviewed_by,omitempty"`
+	ReviewedAt    *time.Time      `json:"reviewed_at,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+func newFraudDecisionResponse(decision db.FraudDecision) fraudDecisionResponse {
+	res := fraudDecisionResponse{
+		ID:            decision.ID,
+		Username:      decision.Username,
+		FromAccountID: decision.FromAccountID,
+		ToAccountID:   decision.ToAccountID,
+		Amount:        decision.Amount,
+		Score:         decision.Score,
+		Outcome:       decision.Outcome,
+		Status:        decision.Status,
+		Trace:         decision.Trace,
+		Features:      decision.Features,
+		ReviewedBy:    decision.ReviewedBy.String,
+		CreatedAt:     decision.CreatedAt,
+	}
+	if decision.PaymentID.Valid {
+		res.PaymentID = &decision.PaymentID.Int64
+	}
+	if decision.ReviewedAt.Valid {
+		res.ReviewedAt = &decision.ReviewedAt.Time
+	}
+	return res
+}
+
+type listFraudDecisionsRequest struct {
+	Status   string `query:"status" validate:"required,oneof=passed challenged pending approved rejected"`
+	PageID   int32  `query:"page_id" validate:"required,min=1"`
+	PageSize int32  `query:"page_size" validate:"required,min=5,max=50"`
+}
+
+// listFraudDecisions godoc
+// @Summary List fraud decisions
+// @Description List the decisions of the fraud rules by status, oldest first, with the rules that matched. Pending decisions are the payments waiting for review. Support staff only.
+// @Tags Fraud
+// @Produce json
+// @Param status query string true "Status" Enums(passed, challenged, pending, approved, rejected)
+// @Param page_id query int true "Page ID for pagination"
+// @Param page_size query int true "Number of decisions per page (min: 5, max: 50)"
+// @Success 200 {array} fraudDecisionResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Support Required"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /fraud/decisions [get]
+func (server *Server) listFraudDecisions(ctx echo.Context) error {
+	req := new(listFraudDecisionsRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	decisions, err := server.store.ListFraudDecisions(ctx.Request().Context(), db.ListFraudDecisionsParams{
+		Status: req.Status,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	res := make([]fraudDecisionResponse, len(decisions))
+	for i, decision := range decisions {
+		res[i] = newFraudDecisionResponse(decision)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+type getFraudDecisionRequest struct {
+	ID int64 `param:"id" validate:"required,min=1"`
+}
+
+// getFraudDecision godoc
+// @Summary Get a fraud decision
+// @Description Support staff only.
+// @Tags Fraud
+// @Produce json
+// @Param id path int true "Decision ID"
+// @Success 200 {object} fraudDecisionResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Support Required"
+// @Failure 404 {object} Problem "Fraud Decision Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /fraud/decisions/{id} [get]
+func (server *Server) getFraudDecision(ctx echo.Context) error {
+	req := new(getFraudDecisionRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	decision, err := server.store.GetFraudDecision(ctx.Request().Context(), req.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return newProblem(http.StatusNotFound, CodeFraudDecisionNotFound, fmt.Sprintf("fraud decision [%d] not found", req.ID))
+		}
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, newFraudDecisionResponse(decision))
+}
+
+type reviewFraudDecisionResponse struct {
+	Decision fraudDecisionResponse `json:"decision"`
+	Payment  *db.PaymentTxResult   `json:"payment,omitempty"`
+}
+
+// approveFraudDecision godoc
+// @Summary Approve a held payment
+// @Description Make a payment the fraud rules held for review, as it was requested, if the payer may still make it. Support staff only, and not for their own payments.
+// @Tags Fraud
+// @Produce json
+// @Param id path int true "Decision ID"
+// @Success 200 {object} reviewFraudDecisionResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Support Required or Self Review"
+// @Failure 404 {object} Problem "Fraud Decision Not Found"
+// @Failure 409 {object} Problem "Fraud Decision Not Pending"
+// @Failure 422 {object} Problem "Account Frozen, Payer Not Allowed, Over a Limit or Screening Hold"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /fraud/decisions/{id}/approve [post]
+func (server *Server) approveFraudDecision(ctx echo.Context) error {
+	return server.reviewFraudDecision(ctx, true)
+}
+
+// rejectFraudDecision godoc
+// @Summary Reject a held payment
+// @Description Drop a payment the fraud rules held for review. Support staff only, and not for their own payments.
+// @Tags Fraud
+// @Produce json
+// @Param id path int true "Decision ID"
+// @Success 200 {object} reviewFraudDecisionResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Support Required or Self Review"
+// @Failure 404 {object} Problem "Fraud Decision Not Found"
+// @Failure 409 {object} Problem "Fraud Decision Not Pending"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /fraud/decisions/{id}/reject [post]
+func (server *Server) rejectFraudDecision(ctx echo.Context) error {
+	return server.reviewFraudDecision(ctx, false)
+}
+
+func (server *Server) reviewFraudDecision(ctx echo.Context, approve bool) error {
+	req := new(getFraudDecisionRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	action := audit.ActionFraudReject
+	if approve {
+		action = audit.ActionFraudApprove
+	}
+
+	result, err := server.store.ReviewFraudDecisionTx(ctx.Request().Context(), db.ReviewFraudDecisionTxParams{
+		ID:         req.ID,
+		ReviewedBy: authUser(ctx).Username,
+		Approve:    approve,
+	})
+	if err != nil {
+		// the checks of a new payment are run again on approval; the payment
+		// is no longer one that can be made, whoever reviews it
+		var limitErr *db.KYCLimitError
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return newProblem(http.StatusNotFound, CodeFraudDecisionNotFound, fmt.Sprintf("fraud decision [%d] not found", req.ID))
+		case errors.Is(err, db.ErrFraudSelfReview):
+			audit.Record(ctx.Request().Context(), server.store, audit.Event{
+				Action:       action,
+				Outcome:      audit.OutcomeFailure,
+				ResourceType: audit.ResourceFraudDecision,
+				ResourceID:   strconv.FormatInt(req.ID, 10),
+				Metadata:     map[string]interface{}{"reason": "self_review"},
+			})
+			return newProblem(http.StatusForbidden, CodeSelfReview, err.Error())
+		case errors.Is(err, db.ErrFraudDecisionNotPending):
+			return newProblem(http.StatusConflict, CodeFraudDecisionNotPending, err.Error())
+		case errors.Is(err, db.ErrAccountFrozen):
+			return newProblem(http.StatusUnprocessableEntity, CodeAccountFrozen, err.Error())
+		case errors.Is(err, db.ErrNotAccountMember), errors.Is(err, db.ErrAccountActionDenied):
+			return newProblem(http.StatusUnprocessableEntity, CodeAccountActionForbidden, "the payer may no longer pay from the account")
+		case errors.Is(err, db.ErrPaymentLimitExceeded):
+			return newProblem(http.StatusUnprocessableEntity, CodePaymentLimitExceeded, err.Error())
+		case errors.As(err, &limitErr):
+			return newProblem(http.StatusUnprocessableEntity, CodeKYCRequired, err.Error())
+		case errors.Is(err, db.ErrScreeningHold):
+			return newProblem(http.StatusUnprocessableEntity, CodeScreeningHold, err.Error())
+		}
+		return err
+	}
+
+	metadata := map[string]interface{}{
+		"username": result.Decision.Username,
+		"amount":   result.Decision.Amount,
+	}
+	if payment := result.Payment; payment != nil {
+		metadata["payment_id"] = payment.Payment.ID
+		server.metrics.ObservePayment(payment.FromAccount.Currency, payment.Payment.Amount)
+		server.publishPayment(ctx, *payment)
+	}
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       action,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceFraudDecision,
+		ResourceID:   strconv.FormatInt(req.ID, 10),
+		Metadata:     metadata,
+	})
+
+	return ctx.JSON(http.StatusOK, reviewFraudDecisionResponse{
+		Decision: newFraudDecisionResponse(result.Decision),
+		Payment:  result.Payment,
+	})
+}
+
+// publishPayment tells the subscribers of both accounts about a payment.
+func (server *Server) publishPayment(ctx echo.Context, payment db.PaymentTxResult) {
+	events.PublishAll(ctx.Request().Context(), server.broker, events.PaymentEvents(payment))
+}
@@ -0,0 +1,558 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/fraud"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// testFraudRules challenge payments from 1000 and block them from 10000.
+var testFraudRules = []fraud.Rule{
+	{Name: "large", Expression: "amount >= 1000", Score: 60},
+	{Name: "huge", Expression: "amount >= 10000", Score: 60},
+}
+
+func randomFraudDecision(user db.User, outcome, status string) db.FraudDecision {
+	return db.FraudDecision{
+		ID:            utils.RandomInt(1, 1000),
+		Username:      user.Username,
+		FromAccountID: utils.RandomInt(1, 1000),
+		ToAccountID:   utils.RandomInt(1, 1000),
+		Amount:        utils.RandomMoney(),
+		Score:         60,
+		Outcome:       outcome,
+		Status:        status,
+		Trace:         json.RawMessage(`[{"rule":"large","expression":"amount >= 1000","score":60,"matched":true}]`),
+		Features:      json.RawMessage(`{"amount":1000}`),
+		CreatedAt:     time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+// expectFraudDecision expects the fraud rules to assess one payment and
+// record a decision with the given outcome and status.
+func expectFraudDecision(store *mockdb.MockStore, user db.User, outcome, status string) db.FraudDecision {
+	decision := randomFraudDecision(user, outcome, status)
+	store.EXPECT().
+		GetPaymentHistory(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.GetPaymentHistoryRow{PayeePayments: 1}, nil)
+	store.EXPECT().
+		CreateFraudDecision(gomock.Any(), gomock.Cond(func(x any) bool {
+			arg, ok := x.(db.CreateFraudDecisionParams)
+			return ok && arg.Username == user.Username && arg.Outcome == outcome && arg.Status == status
+		})).
+		Times(1).
+		Return(decision, nil)
+	return decision
+}
+
+// requireChallengeDetail checks the answer to a challenged payment, which is
+// the same whether a password was sent or not.
+func requireChallengeDetail(t *testing.T, recorder *httptest.ResponseRecorder) {
+	var problem Problem
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+	require.Equal(t, CodeFraudChallenge, problem.Code)
+	require.Equal(t, "confirm the payment by sending it again with your password", problem.Detail)
+}
+
+func TestAssessPaymentAPI(t *testing.T) {
+	user, password := randomUser(t)
+	recipient, _ := randomUser(t)
+	fromAccount := randomAccount(user.Username)
+	toAccount := randomAccount(recipient.Username)
+	toAccount.ID = fromAccount.ID + 1
+	toAccount.Currency = fromAccount.Currency
+
+	paymentBody := func(amount int64, password string) string {
+		body := fmt.Sprintf(`{"from_account_id":%d,"to_account_id":%d,"amount":%d,"currency":%q`,
+			fromAccount.ID, toAccount.ID, amount, fromAccount.Currency)
+		if password != "" {
+			body += fmt.Sprintf(`,"password":%q`, password)
+		}
+		return body + "}"
+	}
+	expectPayment := func(store *mockdb.MockStore, payer db.User) {
+		expectUser(store, payer)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+		expectUser(store, recipient)
+		store.EXPECT().CountBlockingScreeningHits(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+	}
+	expectPaymentTx := func(store *mockdb.MockStore, decision db.FraudDecision) {
+		payment := db.Payment{ID: utils.RandomInt(1, 1000)}
+		store.EXPECT().
+			PaymentTx(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(db.PaymentTxResult{Payment: payment}, nil)
+		store.EXPECT().
+			SetFraudDecisionPayment(gomock.Any(), gomock.Eq(db.SetFraudDecisionPaymentParams{
+				ID:        decision.ID,
+				PaymentID: sql.NullInt64{Int64: payment.ID, Valid: true},
+			})).
+			Times(1).
+			Return(decision, nil)
+		expectAuditEvent(store, audit.ActionPaymentCreate, audit.OutcomeSuccess)
+	}
+
+	testCases := []struct {
+		name          string
+		body          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Allow",
+			body: paymentBody(10, ""),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectPayment(store, user)
+				decision := expectFraudDecision(store, user, db.FraudAllow, db.FraudPassed)
+				expectPaymentTx(store, decision)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "Challenge",
+			body: paymentBody(1000, ""),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectPayment(store, user)
+				expectFraudDecision(store, user, db.FraudChallenge, db.FraudChallenged)
+				expectAuditEvent(store, audit.ActionPaymentCreate, audit.OutcomeFailure)
+				store.EXPECT().PaymentTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireChallengeDetail(t, recorder)
+			},
+		},
+		{
+			name: "ChallengeConfirmed",
+			body: paymentBody(1000, password),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectPayment(store, user)
+				decision := expectFraudDecision(store, user, db.FraudChallenge, db.FraudPassed)
+				expectPaymentTx(store, decision)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "ChallengeWrongPassword",
+			body: paymentBody(1000, "wrong password"),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectPayment(store, user)
+				store.EXPECT().
+					LoginFailureTx(gomock.Any(), gomock.Eq(db.LoginFailureTxParams{Username: user.Username})).
+					Times(1).
+					Return(db.LoginFailureTxResult{User: user}, nil)
+				expectFraudDecision(store, user, db.FraudChallenge, db.FraudChallenged)
+				expectAuditEvent(store, audit.ActionPaymentCreate, audit.OutcomeFailure)
+				store.EXPECT().PaymentTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				// answered like a challenge without a password
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeFraudChallenge)
+				requireChallengeDetail(t, recorder)
+			},
+		},
+		{
+			name: "ChallengeWrongPasswordLocksUser",
+			body: paymentBody(1000, "wrong password"),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectPayment(store, user)
+				locked := user
+				locked.Lockouts = 1
+				locked.LockedUntil = time.Now().Add(time.Minute)
+				store.EXPECT().
+					LoginFailureTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.LoginFailureTxResult{User: locked, Locked: true}, nil)
+				expectAuditEvent(store, audit.ActionUserLock, audit.OutcomeSuccess)
+				expectFraudDecision(store, user, db.FraudChallenge, db.FraudChallenged)
+				expectAuditEvent(store, audit.ActionPaymentCreate, audit.OutcomeFailure)
+				store.EXPECT().PaymentTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireChallengeDetail(t, recorder)
+			},
+		},
+		{
+			name: "ChallengeUserLocked",
+			body: paymentBody(1000, password),
+			buildStubs: func(store *mockdb.MockStore) {
+				// even the right password doesn't confirm during a lockout
+				locked := user
+				locked.LockedUntil = time.Now().Add(time.Minute)
+				expectPayment(store, locked)
+				store.EXPECT().LoginFailureTx(gomock.Any(), gomock.Any()).Times(0)
+				expectFraudDecision(store, user, db.FraudChallenge, db.FraudChallenged)
+				expectAuditEvent(store, audit.ActionPaymentCreate, audit.OutcomeFailure)
+				store.EXPECT().PaymentTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireChallengeDetail(t, recorder)
+			},
+		},
+		{
+			name: "Block",
+			body: paymentBody(10000, password),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectPayment(store, user)
+				expectFraudDecision(store, user, db.FraudBlock, db.FraudPending)
+				expectAuditEvent(store, audit.ActionPaymentCreate, audit.OutcomeFailure)
+				store.EXPECT().PaymentTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var res heldPaymentResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.NotZero(t, res.FraudDecisionID)
+				require.Equal(t, db.FraudPending, res.Status)
+			},
+		},
+		{
+			name: "HistoryError",
+			body: paymentBody(10, ""),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectPayment(store, user)
+				store.EXPECT().
+					GetPaymentHistory(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetPaymentHistoryRow{}, sql.ErrConnDone)
+				store.EXPECT().CreateFraudDecision(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().PaymentTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			var err error
+			server.fraud, err = fraud.NewEngine(testFraudRules, 0, 0)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/payments", strings.NewReader(tc.body))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListFraudDecisionsAPI(t *testing.T) {
+	support := randomSupport(t)
+	customer, _ := randomUser(t)
+	decision := randomFraudDecision(customer, db.FraudBlock, db.FraudPending)
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			user:  support,
+			query: "status=pending&page_id=2&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().
+					ListFraudDecisions(gomock.Any(), gomock.Eq(db.ListFraudDecisionsParams{Status: db.FraudPending, Limit: 5, Offset: 5})).
+					Times(1).
+					Return([]db.FraudDecision{decision}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res []fraudDecisionResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Len(t, res, 1)
+				require.Equal(t, decision.ID, res[0].ID)
+				require.JSONEq(t, string(decision.Trace), string(res[0].Trace))
+				require.Nil(t, res[0].PaymentID)
+			},
+		},
+		{
+			name:  "InvalidStatus",
+			user:  support,
+			query: "status=open&page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().ListFraudDecisions(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name:  "Customer",
+			user:  customer,
+			query: "status=pending&page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, customer)
+				store.EXPECT().ListFraudDecisions(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeSupportRequired)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/fraud/decisions?"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestReviewFraudDecisionAPI(t *testing.T) {
+	support := randomSupport(t)
+	customer, _ := randomUser(t)
+	decision := randomFraudDecision(customer, db.FraudBlock, db.FraudPending)
+	path := "/fraud/decisions/" + strconv.FormatInt(decision.ID, 10)
+
+	reviewed := func(status string) db.FraudDecision {
+		reviewed := decision
+		reviewed.Status = status
+		reviewed.ReviewedBy = sql.NullString{String: support.Username, Valid: true}
+		reviewed.ReviewedAt = sql.NullTime{Time: time.Now().UTC(), Valid: true}
+		return reviewed
+	}
+	payment := db.PaymentTxResult{
+		Payment:     db.Payment{ID: utils.RandomInt(1, 1000), FromAccountID: decision.FromAccountID, ToAccountID: decision.ToAccountID, Amount: decision.Amount},
+		FromAccount: db.Account{ID: decision.FromAccountID, Currency: "EUR"},
+		ToAccount:   db.Account{ID: decision.ToAccountID, Currency: "EUR"},
+	}
+
+	testCases := []struct {
+		name          string
+		path          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Approve",
+			path: path + "/approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				approved := reviewed(db.FraudApproved)
+				approved.PaymentID = sql.NullInt64{Int64: payment.Payment.ID, Valid: true}
+				store.EXPECT().
+					ReviewFraudDecisionTx(gomock.Any(), gomock.Eq(db.ReviewFraudDecisionTxParams{
+						ID:         decision.ID,
+						ReviewedBy: support.Username,
+						Approve:    true,
+					})).
+					Times(1).
+					Return(db.ReviewFraudDecisionTxResult{Decision: approved, Payment: &payment}, nil)
+				expectAuditEvent(store, audit.ActionFraudApprove, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res reviewFraudDecisionResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, db.FraudApproved, res.Decision.Status)
+				require.Equal(t, support.Username, res.Decision.ReviewedBy)
+				require.NotNil(t, res.Decision.PaymentID)
+				require.NotNil(t, res.Payment)
+				require.Equal(t, payment.Payment.ID, res.Payment.Payment.ID)
+			},
+		},
+		{
+			name: "Reject",
+			path: path + "/reject",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().
+					ReviewFraudDecisionTx(gomock.Any(), gomock.Eq(db.ReviewFraudDecisionTxParams{
+						ID:         decision.ID,
+						ReviewedBy: support.Username,
+					})).
+					Times(1).
+					Return(db.ReviewFraudDecisionTxResult{Decision: reviewed(db.FraudRejected)}, nil)
+				expectAuditEvent(store, audit.ActionFraudReject, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res reviewFraudDecisionResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, db.FraudRejected, res.Decision.Status)
+				require.Nil(t, res.Decision.PaymentID)
+				require.Nil(t, res.Payment)
+			},
+		},
+		{
+			name: "SelfReview",
+			path: path + "/approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().
+					ReviewFraudDecisionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReviewFraudDecisionTxResult{}, db.ErrFraudSelfReview)
+				expectAuditEvent(store, audit.ActionFraudApprove, audit.OutcomeFailure)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeSelfReview)
+			},
+		},
+		{
+			name: "NotPending",
+			path: path + "/reject",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().
+					ReviewFraudDecisionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReviewFraudDecisionTxResult{}, db.ErrFraudDecisionNotPending)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, CodeFraudDecisionNotPending)
+			},
+		},
+		{
+			name: "AccountFrozen",
+			path: path + "/approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().
+					ReviewFraudDecisionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReviewFraudDecisionTxResult{}, db.ErrAccountFrozen)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, CodeAccountFrozen)
+			},
+		},
+		{
+			name: "PayerNotAllowed",
+			path: path + "/approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().
+					ReviewFraudDecisionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReviewFraudDecisionTxResult{}, db.ErrNotAccountMember)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, CodeAccountActionForbidden)
+			},
+		},
+		{
+			name: "OverKYCLimit",
+			path: path + "/approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().
+					ReviewFraudDecisionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReviewFraudDecisionTxResult{}, &db.KYCLimitError{Limit: "daily payment", Max: db.BasicKYCLimits.DailyPayments})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, CodeKYCRequired)
+			},
+		},
+		{
+			name: "ScreeningHold",
+			path: path + "/approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().
+					ReviewFraudDecisionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReviewFraudDecisionTxResult{}, db.ErrScreeningHold)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, CodeScreeningHold)
+			},
+		},
+		{
+			name: "NotFound",
+			path: path + "/approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().
+					ReviewFraudDecisionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReviewFraudDecisionTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, CodeFraudDecisionNotFound)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, tc.path, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, support.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
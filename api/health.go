@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/danielmoisa/neobank/db/migrations"
+	"github.com/labstack/echo/v4"
+)
+
+// readinessCheckTimeout bounds each readiness check, so a hung database makes
+// the probe fail instead of time out.
+const readinessCheckTimeout = 2 * time.Second
+
+const (
+	healthStatusOK          = "ok"
+	healthStatusUnavailable = "unavailable"
+)
+
+type healthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// healthz godoc
+// @Summary Liveness probe
+// @Description Reports that the process is up. It does not touch the database.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} healthResponse
+// @Router /healthz [get]
+func (server *Server) healthz(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, healthResponse{Status: healthStatusOK})
+}
+
+// readyz godoc
+// @Summary Readiness probe
+// @Description Reports whether the server can take traffic: the database answers and its schema is migrated to the version this build expects. A failing check only reports unavailable; the reason is logged.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} healthResponse
+// @Failure 503 {object} healthResponse
+// @Router /readyz [get]
+func (server *Server) readyz(ctx echo.Context) error {
+	checks := []struct {
+		name  string
+		check func(context.Context) error
+	}{
+		{name: "database", check: server.store.Ping},
+		{name: "migrations", check: server.checkMigrations},
+	}
+
+	res := healthResponse{Status: healthStatusOK, Checks: map[string]string{}}
+	for _, c := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx.Request().Context(), readinessCheckTimeout)
+		err := c.check(checkCtx)
+		cancel()
+
+		if err != nil {
+			// the probe is public, so the reason goes to the logs only
+			slog.WarnContext(ctx.Request().Context(), "readiness check failed", slog.String("check", c.name), slog.Any("error", err))
+			res.Status = healthStatusUnavailable
+			res.Checks[c.name] = healthStatusUnavailable
+			continue
+		}
+		res.Checks[c.name] = healthStatusOK
+	}
+
+	if res.Status != healthStatusOK {
+		return ctx.JSON(http.StatusServiceUnavailable, res)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// checkMigrations fails while migrations are pending or a migration failed
+// halfway. A schema newer than the build is fine: it happens during a rolling
+// deploy, and migrations are expected to stay backwards compatible.
+func (server *Server) checkMigrations(ctx context.Context) error {
+	want, err := migrations.LatestVersion()
+	if err != nil {
+		return err
+	}
+
+	version, dirty, err := server.store.SchemaVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot read schema version: %w", err)
+	}
+	if dirty {
+		return fmt.Errorf("migration %d is dirty", version)
+	}
+	if version < want {
+		return fmt.Errorf("schema is at version %d, want %d", version, want)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/danielmoisa/neobank/tokens"
+	"github.com/labstack/echo/v4"
+)
+
+// jwks godoc
+// @Summary Token verification keys
+// @Description Publish the public keys access tokens are verified with, as a JSON Web Key Set. Tokens name their key by ID, so keys being rotated in or out are listed alongside the signing key. Not found when tokens are encrypted with a symmetric key.
+// @Tags Tokens
+// @Produce json
+// @Success 200 {object} tokens.JWKS
+// @Failure 404 {object} Problem "Not Found"
+// @Router /.well-known/jwks.json [get]
+func (server *Server) jwks(ctx echo.Context) error {
+	keySet, ok := server.tokenMaker.(tokens.KeySet)
+	if !ok {
+		return newProblem(http.StatusNotFound, CodeNotFound, "tokens are not signed with a public key")
+	}
+
+	// verifiers may cache the keys for a while; rotations keep the old
+	// key listed until its tokens expire anyway
+	ctx.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return ctx.JSON(http.StatusOK, keySet.JWKS())
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/danielmoisa/neobank/blob"
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	"github.com/danielmoisa/neobank/events"
+	"github.com/danielmoisa/neobank/fraud"
+	"github.com/danielmoisa/neobank/mail"
+	"github.com/danielmoisa/neobank/metrics"
+	"github.com/danielmoisa/neobank/ratelimit"
+	"github.com/danielmoisa/neobank/screening"
+	"github.com/danielmoisa/neobank/tokens"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// newSigningTestServer creates a server whose tokens are signed with a new
+// Ed25519 key.
+func newSigningTestServer(t *testing.T, store *mockdb.MockStore, tokenMaker string) *Server {
+	key, err := tokens.GenerateKey(tokens.KeyTypeEd25519)
+	require.NoError(t, err)
+	private, err := tokens.EncodePrivateKey(key)
+	require.NoError(t, err)
+
+	keyFile := filepath.Join(t.TempDir(), "token_key")
+	require.NoError(t, os.WriteFile(keyFile, private, 0o600))
+
+	config := utils.Config{
+		TokenMaker:          tokenMaker,
+		TokenPrivateKeyFile: keyFile,
+		AccessTokenDuration: time.Minute,
+	}
+
+	fraudEngine, err := fraud.NewEngine(nil, 0, 0)
+	require.NoError(t, err)
+
+	server, err := NewServer(config, store, events.NewMemoryBroker(), metrics.New(), ratelimit.NewMemoryLimiter(), mail.NewMemoryMailer(), blob.NewMemoryStorage(), screening.NewScreener(0, nil), fraudEngine)
+	require.NoError(t, err)
+	return server
+}
+
+func TestJWKSAPI(t *testing.T) {
+	for _, tokenMaker := range []string{tokens.MakerPasetoV4Public, tokens.MakerJWTEdDSA} {
+		t.Run(tokenMaker, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newSigningTestServer(t, store, tokenMaker)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+			server.router.ServeHTTP(recorder, request)
+
+			require.Equal(t, http.StatusOK, recorder.Code)
+			require.NotEmpty(t, recorder.Header().Get("Cache-Control"))
+
+			var keySet tokens.JWKS
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &keySet))
+			require.Len(t, keySet.Keys, 1)
+			require.Equal(t, "OKP", keySet.Keys[0].KeyType)
+			require.NotEmpty(t, keySet.Keys[0].KeyID)
+
+			// the server accepts the tokens it signs
+			user, _ := randomUser(t)
+			expectUser(store, user)
+
+			recorder = httptest.NewRecorder()
+			request = httptest.NewRequest(http.MethodGet, "/users/me", nil)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Code)
+		})
+	}
+}
+
+func TestJWKSAPISymmetricKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(ctrl))
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	server.router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+	requireProblemCode(t, recorder, CodeNotFound)
+}
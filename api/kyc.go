@@ -0,0 +1,602 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	"github.com/danielmoisa/neobank/blob"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// maxKYCDocumentSize is the largest document users may upload, in bytes.
+	maxKYCDocumentSize = 10 << 20
+	// minKYCAge is how old, in years, users must be to verify their identity.
+	minKYCAge = 18
+)
+
+// kycContentTypes are the kinds of files accepted as documents: scans and
+// photos. The type is sniffed from the content, the one the client claims is
+// ignored.
+var kycContentTypes = []string{"image/jpeg", "image/png", "application/pdf"}
+
+type kycProfileResponse struct {
+	LegalName       string     `json:"legal_name"`
+	DateOfBirth     string     `json:"date_of_birth"`
+	Nationality     string     `json:"nationality"`
+	AddressLine     string     `json:"address_line"`
+	City            string     `json:"city"`
+	PostalCode      string     `json:"postal_code"`
+	Country         string     `json:"country"`
+	SubmittedAt     *time.Time `json:"submitted_at,omitempty"`
+	ReviewedAt      *time.Time `json:"reviewed_at,omitempty"`
+	RejectionReason string     `json:"rejection_reason,omitempty"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+func newKYCProfileResponse(profile db.KYCProfile) *kycProfileResponse {
+	res := &kycProfileResponse{
+		LegalName:       profile.LegalName,
+		DateOfBirth:     profile.DateOfBirth.Format(time.DateOnly),
+		Nationality:     profile.Nationality,
+		AddressLine:     profile.AddressLine,
+		City:            profile.City,
+		PostalCode:      profile.PostalCode,
+		Country:         profile.Country,
+		RejectionReason: profile.RejectionReason.String,
+		UpdatedAt:       profile.UpdatedAt,
+	}
+	if profile.SubmittedAt.Valid {
+		res.SubmittedAt = &profile.SubmittedAt.Time
+	}
+	if profile.ReviewedAt.Valid {
+		res.ReviewedAt = &profile.ReviewedAt.Time
+	}
+	return res
+}
+
+// kycDocumentResponse leaves out where the document is stored.
+type kycDocumentResponse struct {
+	ID          int64     `json:"id"`
+	Kind        string    `json:"kind"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func newKYCDocumentResponse(document db.KYCDocument) kycDocumentResponse {
+	return kycDocumentResponse{
+		ID:          document.ID,
+		Kind:        document.Kind,
+		ContentType: document.ContentType,
+		Size:        document.Size,
+		SHA256:      document.SHA256,
+		CreatedAt:   document.CreatedAt,
+	}
+}
+
+type kycResponse struct {
+	Username  string                `json:"username"`
+	Status    string                `json:"status"`
+	Limits    db.KYCLimits          `json:"limits"`
+	Profile   *kycProfileResponse   `json:"profile,omitempty"`
+	Documents []kycDocumentResponse `json:"documents"`
+}
+
+// kycState loads the KYC profile and documents of user. A user who hasn't
+// saved a profile yet has none.
+func (server *Server) kycState(ctx echo.Context, user db.User) (kycResponse, error) {
+	res := kycResponse{
+		Username:  user.Username,
+		Status:    user.KYCStatus,
+		Limits:    db.KYCLimitsFor(user.KYCStatus),
+		Documents: []kycDocumentResponse{},
+	}
+
+	profile, err := server.store.GetKYCProfile(ctx.Request().Context(), user.Username)
+	switch {
+	case err == nil:
+		res.Profile = newKYCProfileResponse(profile)
+	case !errors.Is(err, sql.ErrNoRows):
+		return res, err
+	}
+
+	documents, err := server.store.ListKYCDocuments(ctx.Request().Context(), user.Username)
+	if err != nil {
+		return res, err
+	}
+	for _, document := range documents {
+		res.Documents = append(res.Documents, newKYCDocumentResponse(document))
+	}
+	return res, nil
+}
+
+// getKYC godoc
+// @Summary Get the identity verification of the current user
+// @Description Returns the KYC status, the limits that apply until the user is verified, the profile and the uploaded documents.
+// @Tags KYC
+// @Produce json
+// @Success 200 {object} kycResponse
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /kyc [get]
+func (server *Server) getKYC(ctx echo.Context) error {
+	res, err := server.kycState(ctx, authUser(ctx))
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+type saveKYCProfileRequest struct {
+	LegalName   string `json:"legal_name" validate:"required,max=200"`
+	DateOfBirth string `json:"date_of_birth" validate:"required,datetime=2006-01-02"`
+	Nationality string `json:"nationality" validate:"required,iso3166_1_alpha2"`
+	AddressLine string `json:"address_line" validate:"required,max=200"`
+	City        string `json:"city" validate:"required,max=100"`
+	PostalCode  string `json:"postal_code" validate:"required,max=20"`
+	Country     string `json:"country" validate:"required,iso3166_1_alpha2"`
+}
+
+// saveKYCProfile godoc
+// @Summary Save the identity profile of the current user
+// @Description Creates or replaces the legal name, date of birth, nationality and address of the user. Users must be adults. The profile can't change while it is under review or once verified.
+// @Tags KYC
+// @Accept json
+// @Produce json
+// @Param request body saveKYCProfileRequest true "Identity profile"
+// @Success 200 {object} kycProfileResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 409 {object} Problem "KYC Not Editable"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /kyc/profile [put]
+func (server *Server) saveKYCProfile(ctx echo.Context) error {
+	req := new(saveKYCProfileRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	dateOfBirth, _ := time.Parse(time.DateOnly, req.DateOfBirth)
+	if dateOfBirth.AddDate(minKYCAge, 0, 0).After(time.Now()) {
+		return newProblem(http.StatusBadRequest, CodeValidationFailed, fmt.Sprintf("you must be at least %d years old", minKYCAge))
+	}
+
+	username := authUser(ctx).Username
+
+	profile, err := server.store.SaveKYCProfileTx(ctx.Request().Context(), db.UpsertKYCProfileParams{
+		Username:    username,
+		LegalName:   req.LegalName,
+		DateOfBirth: dateOfBirth,
+		Nationality: req.Nationality,
+		AddressLine: req.AddressLine,
+		City:        req.City,
+		PostalCode:  req.PostalCode,
+		Country:     req.Country,
+	})
+	if err != nil {
+		return kycProblem(err)
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionKYCProfileUpdate,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceKYC,
+		ResourceID:   username,
+	})
+
+	return ctx.JSON(http.StatusOK, newKYCProfileResponse(profile))
+}
+
+// uploadKYCDocument godoc
+// @Summary Upload an identity document
+// @Description Uploads a JPEG, PNG or PDF scan of up to 10 MiB. Documents can't be added while the verification is under review or once verified.
+// @Tags KYC
+// @Accept multipart/form-data
+// @Produce json
+// @Param kind formData string true "Kind of document" Enums(passport, national_id, driving_licence, proof_of_address)
+// @Param file formData file true "The document"
+// @Success 201 {object} kycDocumentResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 409 {object} Problem "KYC Not Editable"
+// @Failure 413 {object} Problem "Document Too Large"
+// @Failure 415 {object} Problem "Unsupported Media Type"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /kyc/documents [post]
+func (server *Server) uploadKYCDocument(ctx echo.Context) error {
+	kind := ctx.FormValue("kind")
+	if !slices.Contains(db.IdentityDocuments, kind) && kind != db.DocumentProofOfAddress {
+		return newProblem(http.StatusBadRequest, CodeValidationFailed, "kind must be one of [passport national_id driving_licence proof_of_address]")
+	}
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		return newProblem(http.StatusBadRequest, CodeInvalidRequest, "a file is required")
+	}
+	if header.Size > maxKYCDocumentSize {
+		return newProblem(http.StatusRequestEntityTooLarge, CodeInvalidRequest, fmt.Sprintf("documents may be at most %d bytes", maxKYCDocumentSize))
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxKYCDocumentSize+1))
+	if err != nil {
+		return err
+	}
+	if len(data) > maxKYCDocumentSize {
+		return newProblem(http.StatusRequestEntityTooLarge, CodeInvalidRequest, fmt.Sprintf("documents may be at most %d bytes", maxKYCDocumentSize))
+	}
+
+	contentType := http.DetectContentType(data)
+	if !slices.Contains(kycContentTypes, contentType) {
+		return newProblem(http.StatusUnsupportedMediaType, CodeUnsupportedMedia, "documents must be JPEG, PNG or PDF files")
+	}
+
+	user := authUser(ctx)
+	if !db.KYCEditable(user.KYCStatus) {
+		return kycProblem(db.ErrKYCNotEditable)
+	}
+
+	sum := sha256.Sum256(data)
+	key := "kyc/" + user.Username + "/" + uuid.NewString()
+	if err := server.blobs.Put(ctx.Request().Context(), key, bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("cannot store document: %w", err)
+	}
+
+	document, err := server.store.AddKYCDocumentTx(ctx.Request().Context(), db.CreateKYCDocumentParams{
+		Username:    user.Username,
+		Kind:        kind,
+		StorageKey:  key,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		SHA256:      hex.EncodeToString(sum[:]),
+	})
+	if err != nil {
+		// the status may have changed since it was checked above
+		if err := server.blobs.Delete(ctx.Request().Context(), key); err != nil {
+			slog.ErrorContext(ctx.Request().Context(), "cannot delete orphaned document", slog.String("key", key), slog.Any("error", err))
+		}
+		return kycProblem(err)
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionKYCDocumentUpload,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceKYCDocument,
+		ResourceID:   strconv.FormatInt(document.ID, 10),
+		Metadata: map[string]interface{}{
+			"kind": document.Kind,
+			"size": document.Size,
+		},
+	})
+
+	return ctx.JSON(http.StatusCreated, newKYCDocumentResponse(document))
+}
+
+type getKYCDocumentRequest struct {
+	ID int64 `param:"id" validate:"required,min=1"`
+}
+
+// getKYCDocument godoc
+// @Summary Download an identity document
+// @Description Returns the content of a document. Users can only download their own documents, support staff can download any.
+// @Tags KYC
+// @Produce octet-stream
+// @Param id path int true "Document ID"
+// @Success 200 {file} file
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 404 {object} Problem "Document Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /kyc/documents/{id} [get]
+func (server *Server) getKYCDocument(ctx echo.Context) error {
+	req := new(getKYCDocumentRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	notFound := newProblem(http.StatusNotFound, CodeKYCDocumentNotFound, fmt.Sprintf("document [%d] not found", req.ID))
+
+	document, err := server.store.GetKYCDocument(ctx.Request().Context(), req.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return notFound
+		}
+		return err
+	}
+
+	// other users' documents are reported as missing so their IDs can't be probed
+	user := authUser(ctx)
+	if document.Username != user.Username && !isStaff(user) {
+		return notFound
+	}
+
+	r, err := server.blobs.Get(ctx.Request().Context(), document.StorageKey)
+	if err != nil {
+		if errors.Is(err, blob.ErrNotFound) {
+			return notFound
+		}
+		return err
+	}
+	defer r.Close()
+
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("%s-%d", document.Kind, document.ID)))
+	ctx.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	return ctx.Stream(http.StatusOK, document.ContentType, r)
+}
+
+// submitKYC godoc
+// @Summary Submit the identity verification for review
+// @Description Puts the profile and documents of the user in the review queue of support staff. Needs a profile and at least one passport, national ID or driving licence.
+// @Tags KYC
+// @Produce json
+// @Success 200 {object} kycResponse
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 409 {object} Problem "KYC Not Editable"
+// @Failure 422 {object} Problem "KYC Incomplete"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /kyc/submit [post]
+func (server *Server) submitKYC(ctx echo.Context) error {
+	username := authUser(ctx).Username
+
+	result, err := server.store.SubmitKYCTx(ctx.Request().Context(), username)
+	if err != nil {
+		return kycProblem(err)
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionKYCSubmit,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceKYC,
+		ResourceID:   username,
+	})
+
+	res, err := server.kycState(ctx, result.User)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+type listKYCReviewsRequest struct {
+	PageID   int32 `query:"page_id" validate:"required,min=1"`
+	PageSize int32 `query:"page_size" validate:"required,min=5,max=50"`
+}
+
+type kycReviewResponse struct {
+	Username string              `json:"username"`
+	Profile  *kycProfileResponse `json:"profile"`
+}
+
+// listKYCReviews godoc
+// @Summary List identity verifications waiting for review
+// @Description Lists the pending submissions, the oldest first. Support staff only.
+// @Tags KYC
+// @Produce json
+// @Param page_id query int true "Page ID for pagination"
+// @Param page_size query int true "Number of submissions per page (min: 5, max: 50)"
+// @Success 200 {array} kycReviewResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Support Required"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /kyc/reviews [get]
+func (server *Server) listKYCReviews(ctx echo.Context) error {
+	req := new(listKYCReviewsRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	profiles, err := server.store.ListKYCReviewQueue(ctx.Request().Context(), db.ListKYCReviewQueueParams{
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	res := make([]kycReviewResponse, len(profiles))
+	for i, profile := range profiles {
+		res[i] = kycReviewResponse{Username: profile.Username, Profile: newKYCProfileResponse(profile)}
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+type kycUsernameRequest struct {
+	Username string `param:"username" validate:"required,alphanum"`
+}
+
+// getKYCReview godoc
+// @Summary Get the identity verification of a user
+// @Description Returns the KYC status, profile and documents of any user. Support staff only.
+// @Tags KYC
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} kycResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Support Required"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /kyc/reviews/{username} [get]
+func (server *Server) getKYCReview(ctx echo.Context) error {
+	req := new(kycUsernameRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	user, err := server.store.GetUser(ctx.Request().Context(), req.Username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return newProblem(http.StatusNotFound, CodeNotFound, fmt.Sprintf("user %s not found", req.Username))
+		}
+		return err
+	}
+
+	res, err := server.kycState(ctx, user)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+type rejectKYCRequest struct {
+	Username string `param:"username" validate:"required,alphanum"`
+	Reason   string `json:"reason" validate:"required,max=500"`
+}
+
+// approveKYC godoc
+// @Summary Approve an identity verification
+// @Description Verifies a pending submission, which lifts the limits of the user. Staff can't review their own submission. Support staff only.
+// @Tags KYC
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} kycResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Support Required or Self Review"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 409 {object} Problem "KYC Not Pending"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /kyc/reviews/{username}/approve [post]
+func (server *Server) approveKYC(ctx echo.Context) error {
+	req := new(kycUsernameRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	return server.reviewKYC(ctx, db.ReviewKYCTxParams{
+		Username:   req.Username,
+		ReviewedBy: authUser(ctx).Username,
+		Approve:    true,
+	})
+}
+
+// rejectKYC godoc
+// @Summary Reject an identity verification
+// @Description Rejects a pending submission. The reason is shown to the user, who can fix their profile or documents and submit again. Support staff only.
+// @Tags KYC
+// @Accept json
+// @Produce json
+// @Param username path string true "Username"
+// @Param request body rejectKYCRequest true "Why the submission is rejected"
+// @Success 200 {object} kycResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Support Required or Self Review"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 409 {object} Problem "KYC Not Pending"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /kyc/reviews/{username}/reject [post]
+func (server *Server) rejectKYC(ctx echo.Context) error {
+	req := new(rejectKYCRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	return server.reviewKYC(ctx, db.ReviewKYCTxParams{
+		Username:   req.Username,
+		ReviewedBy: authUser(ctx).Username,
+		Reason:     req.Reason,
+	})
+}
+
+func (server *Server) reviewKYC(ctx echo.Context, args db.ReviewKYCTxParams) error {
+	action := audit.ActionKYCReject
+	if args.Approve {
+		action = audit.ActionKYCApprove
+	}
+
+	result, err := server.store.ReviewKYCTx(ctx.Request().Context(), args)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return newProblem(http.StatusNotFound, CodeNotFound, fmt.Sprintf("user %s not found", args.Username))
+		case errors.Is(err, db.ErrKYCSelfReview):
+			audit.Record(ctx.Request().Context(), server.store, audit.Event{
+				Action:       action,
+				Outcome:      audit.OutcomeFailure,
+				ResourceType: audit.ResourceKYC,
+				ResourceID:   args.Username,
+				Metadata:     map[string]interface{}{"reason": "self_review"},
+			})
+		}
+		return kycProblem(err)
+	}
+
+	event := audit.Event{
+		Action:       action,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceKYC,
+		ResourceID:   args.Username,
+	}
+	if !args.Approve {
+		event.Metadata = map[string]interface{}{"reason": args.Reason}
+	}
+	audit.Record(ctx.Request().Context(), server.store, event)
+
+	res, err := server.kycState(ctx, result.User)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// kycProblem maps the errors of the KYC workflow and of the limits of the
+// KYC tiers.
+func kycProblem(err error) error {
+	var limitErr *db.KYCLimitError
+	switch {
+	case errors.As(err, &limitErr):
+		return newProblem(http.StatusForbidden, CodeKYCRequired, err.Error())
+	case errors.Is(err, db.ErrKYCNotEditable):
+		return newProblem(http.StatusConflict, CodeKYCNotEditable, err.Error())
+	case errors.Is(err, db.ErrKYCIncomplete):
+		return newProblem(http.StatusUnprocessableEntity, CodeKYCIncomplete, err.Error())
+	case errors.Is(err, db.ErrKYCNotPending):
+		return newProblem(http.StatusConflict, CodeKYCNotPending, err.Error())
+	case errors.Is(err, db.ErrKYCSelfReview):
+		return newProblem(http.StatusForbidden, CodeSelfReview, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,857 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	"github.com/danielmoisa/neobank/blob"
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// pngHeader is enough of a PNG file for its content type to be sniffed.
+const pngHeader = "\x89PNG\r\n\x1a\n"
+
+func randomUnverifiedUser(t *testing.T) db.User {
+	user, _ := randomUser(t)
+	user.KYCStatus = db.KYCUnverified
+	return user
+}
+
+func randomSupport(t *testing.T) db.User {
+	user, _ := randomUser(t)
+	user.Role = db.RoleSupport
+	return user
+}
+
+func randomKYCProfile(username string) db.KYCProfile {
+	return db.KYCProfile{
+		Username:    username,
+		LegalName:   utils.RandomOwner(),
+		DateOfBirth: time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC),
+		Nationality: "RO",
+		AddressLine: "1 Main Street",
+		City:        "Bucharest",
+		PostalCode:  "010011",
+		Country:     "RO",
+		SubmittedAt: sql.NullTime{Time: time.Now(), Valid: true},
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+}
+
+func randomKYCDocument(username string, kind string) db.KYCDocument {
+	return db.KYCDocument{
+		ID:          utils.RandomInt(1, 1000),
+		Username:    username,
+		Kind:        kind,
+		StorageKey:  "kyc/" + username + "/" + utils.RandomString(8),
+		ContentType: "image/png",
+		Size:        utils.RandomInt(100, 1000),
+		SHA256:      strings.Repeat("a", 64),
+		CreatedAt:   time.Now(),
+	}
+}
+
+// expectKYCState expects the profile and documents of username to be loaded.
+func expectKYCState(store *mockdb.MockStore, username string, profile db.KYCProfile, documents ...db.KYCDocument) {
+	store.EXPECT().
+		GetKYCProfile(gomock.Any(), gomock.Eq(username)).
+		Times(1).
+		Return(profile, nil)
+	store.EXPECT().
+		ListKYCDocuments(gomock.Any(), gomock.Eq(username)).
+		Times(1).
+		Return(documents, nil)
+}
+
+func TestGetKYCAPI(t *testing.T) {
+	user := randomUnverifiedUser(t)
+	document := randomKYCDocument(user.Username, db.DocumentPassport)
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	expectUser(store, user)
+	store.EXPECT().
+		GetKYCProfile(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(db.KYCProfile{}, sql.ErrNoRows)
+	store.EXPECT().
+		ListKYCDocuments(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return([]db.KYCDocument{document}, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/kyc", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var res kycResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+	require.Equal(t, db.KYCUnverified, res.Status)
+	require.Equal(t, db.BasicKYCLimits, res.Limits)
+	require.Nil(t, res.Profile)
+	require.Len(t, res.Documents, 1)
+	require.Equal(t, document.ID, res.Documents[0].ID)
+	require.NotContains(t, recorder.Body.String(), document.StorageKey)
+}
+
+func TestSaveKYCProfileAPI(t *testing.T) {
+	user := randomUnverifiedUser(t)
+	profile := randomKYCProfile(user.Username)
+
+	validBody := map[string]interface{}{
+		"legal_name":    profile.LegalName,
+		"date_of_birth": "1990-05-17",
+		"nationality":   profile.Nationality,
+		"address_line":  profile.AddressLine,
+		"city":          profile.City,
+		"postal_code":   profile.PostalCode,
+		"country":       profile.Country,
+	}
+	withField := func(name string, value string) map[string]interface{} {
+		body := map[string]interface{}{}
+		for k, v := range validBody {
+			body[k] = v
+		}
+		body[name] = value
+		return body
+	}
+
+	testCases := []struct {
+		name          string
+		body          map[string]interface{}
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: validBody,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().
+					SaveKYCProfileTx(gomock.Any(), gomock.Eq(db.UpsertKYCProfileParams{
+						Username:    user.Username,
+						LegalName:   profile.LegalName,
+						DateOfBirth: profile.DateOfBirth,
+						Nationality: profile.Nationality,
+						AddressLine: profile.AddressLine,
+						City:        profile.City,
+						PostalCode:  profile.PostalCode,
+						Country:     profile.Country,
+					})).
+					Times(1).
+					Return(profile, nil)
+				expectAuditEvent(store, audit.ActionKYCProfileUpdate, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res kycProfileResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, "1990-05-17", res.DateOfBirth)
+				require.Equal(t, profile.LegalName, res.LegalName)
+			},
+		},
+		{
+			name: "InvalidCountry",
+			body: withField("country", "XX"),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().SaveKYCProfileTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name: "InvalidDate",
+			body: withField("date_of_birth", "17/05/1990"),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().SaveKYCProfileTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name: "Minor",
+			body: withField("date_of_birth", time.Now().AddDate(-minKYCAge, 0, 1).Format(time.DateOnly)),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().SaveKYCProfileTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name: "NotEditable",
+			body: validBody,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().
+					SaveKYCProfileTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.KYCProfile{}, db.ErrKYCNotEditable)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, CodeKYCNotEditable)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPut, "/kyc/profile", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func newKYCUploadRequest(t *testing.T, kind string, content []byte) *http.Request {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	require.NoError(t, writer.WriteField("kind", kind))
+	part, err := writer.CreateFormFile("file", "scan")
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	request, err := http.NewRequest(http.MethodPost, "/kyc/documents", body)
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	return request
+}
+
+func TestUploadKYCDocumentAPI(t *testing.T) {
+	user := randomUnverifiedUser(t)
+	png := []byte(pngHeader + utils.RandomString(100))
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		kind          string
+		content       []byte
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, blobs *blob.MemoryStorage)
+	}{
+		{
+			name:    "OK",
+			user:    user,
+			kind:    db.DocumentPassport,
+			content: png,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().
+					AddKYCDocumentTx(gomock.Any(), gomock.Cond(func(x any) bool {
+						arg, ok := x.(db.CreateKYCDocumentParams)
+						return ok && arg.Username == user.Username && arg.Kind == db.DocumentPassport &&
+							arg.ContentType == "image/png" && arg.Size == int64(len(png)) && len(arg.SHA256) == 64 &&
+							strings.HasPrefix(arg.StorageKey, "kyc/"+user.Username+"/")
+					})).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateKYCDocumentParams) (db.KYCDocument, error) {
+						return db.KYCDocument{ID: 1, Username: arg.Username, Kind: arg.Kind, StorageKey: arg.StorageKey, ContentType: arg.ContentType, Size: arg.Size, SHA256: arg.SHA256}, nil
+					})
+				expectAuditEvent(store, audit.ActionKYCDocumentUpload, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, blobs *blob.MemoryStorage) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				keys := blobs.Keys()
+				require.Len(t, keys, 1)
+				require.NotContains(t, recorder.Body.String(), keys[0])
+
+				r, err := blobs.Get(context.Background(), keys[0])
+				require.NoError(t, err)
+				data, err := io.ReadAll(r)
+				require.NoError(t, err)
+				require.Equal(t, png, data)
+			},
+		},
+		{
+			name:    "UnsupportedType",
+			user:    user,
+			kind:    db.DocumentPassport,
+			content: []byte("just some text"),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().AddKYCDocumentTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, blobs *blob.MemoryStorage) {
+				require.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+				requireProblemCode(t, recorder, CodeUnsupportedMedia)
+				require.Empty(t, blobs.Keys())
+			},
+		},
+		{
+			name:    "InvalidKind",
+			user:    user,
+			kind:    "selfie",
+			content: png,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().AddKYCDocumentTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, blobs *blob.MemoryStorage) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name: "Verified",
+			user: func() db.User {
+				verified := user
+				verified.KYCStatus = db.KYCVerified
+				return verified
+			}(),
+			kind:    db.DocumentPassport,
+			content: png,
+			buildStubs: func(store *mockdb.MockStore) {
+				verified := user
+				verified.KYCStatus = db.KYCVerified
+				expectUser(store, verified)
+				store.EXPECT().AddKYCDocumentTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, blobs *blob.MemoryStorage) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, CodeKYCNotEditable)
+				require.Empty(t, blobs.Keys())
+			},
+		},
+		{
+			name:    "SubmittedMeanwhile",
+			user:    user,
+			kind:    db.DocumentProofOfAddress,
+			content: png,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().
+					AddKYCDocumentTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.KYCDocument{}, db.ErrKYCNotEditable)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, blobs *blob.MemoryStorage) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, CodeKYCNotEditable)
+				require.Empty(t, blobs.Keys())
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request := newKYCUploadRequest(t, tc.kind, tc.content)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, server.blobs.(*blob.MemoryStorage))
+		})
+	}
+}
+
+func TestGetKYCDocumentAPI(t *testing.T) {
+	owner := randomUnverifiedUser(t)
+	other, _ := randomUser(t)
+	support := randomSupport(t)
+	document := randomKYCDocument(owner.Username, db.DocumentPassport)
+	content := pngHeader + utils.RandomString(20)
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Owner",
+			user: owner,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "image/png", recorder.Header().Get("Content-Type"))
+				require.Equal(t, content, recorder.Body.String())
+			},
+		},
+		{
+			name: "Support",
+			user: support,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, content, recorder.Body.String())
+			},
+		},
+		{
+			name: "OtherUser",
+			user: other,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, CodeKYCDocumentNotFound)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			expectUser(store, tc.user)
+			store.EXPECT().
+				GetKYCDocument(gomock.Any(), gomock.Eq(document.ID)).
+				Times(1).
+				Return(document, nil)
+
+			server := newTestServer(t, store)
+			require.NoError(t, server.blobs.Put(context.Background(), document.StorageKey, strings.NewReader(content)))
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/kyc/documents/"+strconv.FormatInt(document.ID, 10), nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestSubmitKYCAPI(t *testing.T) {
+	user := randomUnverifiedUser(t)
+	profile := randomKYCProfile(user.Username)
+	document := randomKYCDocument(user.Username, db.DocumentNationalID)
+
+	pending := user
+	pending.KYCStatus = db.KYCPending
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().
+					SubmitKYCTx(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.KYCTxResult{User: pending, Profile: profile}, nil)
+				expectAuditEvent(store, audit.ActionKYCSubmit, audit.OutcomeSuccess)
+				expectKYCState(store, user.Username, profile, document)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res kycResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, db.KYCPending, res.Status)
+				require.NotNil(t, res.Profile)
+				require.NotNil(t, res.Profile.SubmittedAt)
+			},
+		},
+		{
+			name: "Incomplete",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().
+					SubmitKYCTx(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.KYCTxResult{}, db.ErrKYCIncomplete)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, CodeKYCIncomplete)
+			},
+		},
+		{
+			name: "AlreadyPending",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().
+					SubmitKYCTx(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.KYCTxResult{}, db.ErrKYCNotEditable)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, CodeKYCNotEditable)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/kyc/submit", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListKYCReviewsAPI(t *testing.T) {
+	support := randomSupport(t)
+	admin := randomAdmin(t)
+	customer, _ := randomUser(t)
+	profile := randomKYCProfile(utils.RandomOwner())
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Support",
+			user: support,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().
+					ListKYCReviewQueue(gomock.Any(), gomock.Eq(db.ListKYCReviewQueueParams{Limit: 5, Offset: 5})).
+					Times(1).
+					Return([]db.KYCProfile{profile}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res []kycReviewResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Len(t, res, 1)
+				require.Equal(t, profile.Username, res[0].Username)
+			},
+		},
+		{
+			name: "Admin",
+			user: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, admin)
+				store.EXPECT().
+					ListKYCReviewQueue(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.KYCProfile{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Customer",
+			user: customer,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, customer)
+				store.EXPECT().ListKYCReviewQueue(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeSupportRequired)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/kyc/reviews?page_id=2&page_size=5", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestReviewKYCAPI(t *testing.T) {
+	support := randomSupport(t)
+	applicant := randomUnverifiedUser(t)
+	profile := randomKYCProfile(applicant.Username)
+
+	verified := applicant
+	verified.KYCStatus = db.KYCVerified
+	rejected := applicant
+	rejected.KYCStatus = db.KYCRejected
+
+	testCases := []struct {
+		name          string
+		path          string
+		body          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Approve",
+			path: "/kyc/reviews/" + applicant.Username + "/approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().
+					ReviewKYCTx(gomock.Any(), gomock.Eq(db.ReviewKYCTxParams{
+						Username:   applicant.Username,
+						ReviewedBy: support.Username,
+						Approve:    true,
+					})).
+					Times(1).
+					Return(db.KYCTxResult{User: verified, Profile: profile}, nil)
+				expectAuditEvent(store, audit.ActionKYCApprove, audit.OutcomeSuccess)
+				expectKYCState(store, applicant.Username, profile)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res kycResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, db.KYCVerified, res.Status)
+				require.Equal(t, db.KYCLimits{}, res.Limits)
+			},
+		},
+		{
+			name: "Reject",
+			path: "/kyc/reviews/" + applicant.Username + "/reject",
+			body: `{"reason":"the passport photo is blurry"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().
+					ReviewKYCTx(gomock.Any(), gomock.Eq(db.ReviewKYCTxParams{
+						Username:   applicant.Username,
+						ReviewedBy: support.Username,
+						Reason:     "the passport photo is blurry",
+					})).
+					Times(1).
+					Return(db.KYCTxResult{User: rejected, Profile: profile}, nil)
+				expectAuditEvent(store, audit.ActionKYCReject, audit.OutcomeSuccess)
+				expectKYCState(store, applicant.Username, profile)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "RejectWithoutReason",
+			path: "/kyc/reviews/" + applicant.Username + "/reject",
+			body: `{}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().ReviewKYCTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name: "SelfReview",
+			path: "/kyc/reviews/" + support.Username + "/approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().
+					ReviewKYCTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.KYCTxResult{}, db.ErrKYCSelfReview)
+				expectAuditEvent(store, audit.ActionKYCApprove, audit.OutcomeFailure)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeSelfReview)
+			},
+		},
+		{
+			name: "NotPending",
+			path: "/kyc/reviews/" + applicant.Username + "/approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().
+					ReviewKYCTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.KYCTxResult{}, db.ErrKYCNotPending)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, CodeKYCNotPending)
+			},
+		},
+		{
+			name: "UserNotFound",
+			path: "/kyc/reviews/" + applicant.Username + "/approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().
+					ReviewKYCTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.KYCTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+			require.NoError(t, err)
+			if tc.body != "" {
+				request.Header.Set("Content-Type", "application/json")
+			}
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, support.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestKYCLimitsAPI(t *testing.T) {
+	user := randomUnverifiedUser(t)
+	account := randomAccount(user.Username)
+
+	testCases := []struct {
+		name          string
+		method        string
+		path          string
+		body          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "SecondAccount",
+			method: http.MethodPost,
+			path:   "/accounts",
+			body:   `{"currency":"EUR"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().
+					CountAccounts(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(int64(1), nil)
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeKYCRequired)
+			},
+		},
+		{
+			name:   "LargePayment",
+			method: http.MethodPost,
+			path:   "/payments",
+			body:   `{"from_account_id":` + strconv.FormatInt(account.ID, 10) + `,"to_account_id":99999,"amount":10001,"currency":"` + account.Currency + `"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetOutgoingPaymentTotal(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().PaymentTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeKYCRequired)
+			},
+		},
+		{
+			name:   "DailyPayments",
+			method: http.MethodPost,
+			path:   "/payments",
+			body:   `{"from_account_id":` + strconv.FormatInt(account.ID, 10) + `,"to_account_id":99999,"amount":5000,"currency":"` + account.Currency + `"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetOutgoingPaymentTotal(gomock.Any(), gomock.Cond(func(x any) bool {
+						arg, ok := x.(db.GetOutgoingPaymentTotalParams)
+						return ok && arg.InitiatedBy == user.Username && arg.Currency == account.Currency
+					})).
+					Times(1).
+					Return(int64(21_000), nil)
+				store.EXPECT().PaymentTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeKYCRequired)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
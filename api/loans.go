@@ -0,0 +1,576 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/events"
+	"github.com/danielmoisa/neobank/loans"
+	"github.com/labstack/echo/v4"
+)
+
+type createLoanProductRequest struct {
+	Name     string `json:"name" validate:"required,max=100"`
+	Currency string `json:"currency" validate:"required,oneof=USD EUR CAD"`
+	// RateBps is the annual rate in basis points: 1200 is 12%.
+	RateBps      int32  `json:"rate_bps" validate:"min=0,max=10000"`
+	Method       string `json:"method" validate:"required,oneof=annuity linear"`
+	MinPrincipal int64  `json:"min_principal" validate:"required,min=1"`
+	MaxPrincipal int64  `json:"max_principal" validate:"required,min=1"`
+	MinTerm      int32  `json:"min_term" validate:"required,min=1,max=600"`
+	MaxTerm      int32  `json:"max_term" validate:"required,min=1,max=600"`
+	// LateFee is charged once on an installment still unpaid GraceDays after
+	// it was due.
+	LateFee   int64 `json:"late_fee" validate:"min=0"`
+	GraceDays int32 `json:"grace_days" validate:"min=0,max=90"`
+}
+
+// createLoanProduct godoc
+// @Summary Create a loan product
+// @Description Define the terms loans are offered on: an annual rate in basis points, how installments are amortized (annuity: equal installments, linear: equal principal), the principal and term in months borrowers may choose from, and the late fee charged on installments still unpaid after the grace days. Only admins may create products.
+// @Tags Loans
+// @Accept json
+// @Produce json
+// @Param request body createLoanProductRequest true "Loan product"
+// @Success 201 {object} db.LoanProduct
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Admin Required"
+// @Failure 409 {object} Problem "Conflict"
+// @Failure 422 {object} Problem "Loan Terms Invalid"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /loans/products [post]
+func (server *Server) createLoanProduct(ctx echo.Context) error {
+	req := new(createLoanProductRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	if req.MaxPrincipal < req.MinPrincipal || req.MaxTerm < req.MinTerm {
+		return newProblem(http.StatusUnprocessableEntity, CodeLoanTermsInvalid, "the maximum principal and term must not be less than the minimum")
+	}
+
+	product, err := server.store.CreateLoanProduct(ctx.Request().Context(), db.CreateLoanProductParams{
+		Name:         req.Name,
+		Currency:     req.Currency,
+		RateBps:      req.RateBps,
+		Method:       req.Method,
+		MinPrincipal: req.MinPrincipal,
+		MaxPrincipal: req.MaxPrincipal,
+		MinTerm:      req.MinTerm,
+		MaxTerm:      req.MaxTerm,
+		LateFee:      req.LateFee,
+		GraceDays:    req.GraceDays,
+		CreatedBy:    authUser(ctx).Username,
+	})
+	if err != nil {
+		return err
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionLoanProductCreate,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceLoanProduct,
+		ResourceID:   strconv.FormatInt(product.ID, 10),
+		Metadata: map[string]interface{}{
+			"name":     product.Name,
+			"currency": product.Currency,
+			"rate_bps": product.RateBps,
+			"method":   product.Method,
+		},
+	})
+
+	return ctx.JSON(http.StatusCreated, product)
+}
+
+// listLoanProducts godoc
+// @Summary List loan products
+// @Description List the products loans can be taken out under.
+// @Tags Loans
+// @Produce json
+// @Success 200 {array} db.LoanProduct
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /loans/products [get]
+func (server *Server) listLoanProducts(ctx echo.Context) error {
+	products, err := server.store.ListLoanProducts(ctx.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, products)
+}
+
+type loanResponse struct {
+	ID                   int64  `json:"id"`
+	ProductID            int64  `json:"product_id"`
+	AccountID            int64  `json:"account_id"`
+	Borrower             string `json:"borrower"`
+	Principal            int64  `json:"principal"`
+	RateBps              int32  `json:"rate_bps"`
+	TermMonths           int32  `json:"term_months"`
+	Method               string `json:"method"`
+	LateFee              int64  `json:"late_fee"`
+	GraceDays            int32  `json:"grace_days"`
+	OutstandingPrincipal int64  `json:"outstanding_principal"`
+	Status               string `json:"status"`
+	// DisbursedOn is the day the principal was paid into the account, as
+	// YYYY-MM-DD.
+	DisbursedOn         string     `json:"disbursed_on"`
+	DisbursementEntryID *int64     `json:"disbursement_entry_id,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	ClosedAt            *time.Time `json:"closed_at,omitempty"`
+}
+
+func newLoanResponse(loan db.Loan) loanResponse {
+	res := loanResponse{
+		ID:                   loan.ID,
+		ProductID:            loan.ProductID,
+		AccountID:            loan.AccountID,
+		Borrower:             loan.Borrower,
+		Principal:            loan.Principal,
+		RateBps:              loan.RateBps,
+		TermMonths:           loan.TermMonths,
+		Method:               loan.Method,
+		LateFee:              loan.LateFee,
+		GraceDays:            loan.GraceDays,
+		OutstandingPrincipal: loan.OutstandingPrincipal,
+		Status:               loan.Status,
+		DisbursedOn:          loan.DisbursedOn.Format(dateFormat),
+		CreatedAt:            loan.CreatedAt,
+	}
+	if loan.DisbursementEntryID.Valid {
+		res.DisbursementEntryID = &loan.DisbursementEntryID.Int64
+	}
+	if loan.ClosedAt.Valid {
+		res.ClosedAt = &loan.ClosedAt.Time
+	}
+	return res
+}
+
+type loanInstallmentResponse struct {
+	ID     int64 `json:"id"`
+	Period int32 `json:"period"`
+	// DueDate is the day the installment is collected, as YYYY-MM-DD.
+	DueDate       string     `json:"due_date"`
+	Principal     int64      `json:"principal"`
+	Interest      int64      `json:"interest"`
+	Fee           int64      `json:"fee"`
+	PaidPrincipal int64      `json:"paid_principal"`
+	PaidInterest  int64      `json:"paid_interest"`
+	PaidFee       int64      `json:"paid_fee"`
+	Status        string     `json:"status"`
+	PaidAt        *time.Time `json:"paid_at,omitempty"`
+}
+
+func newLoanInstallmentResponses(installments []db.LoanInstallment) []loanInstallmentResponse {
+	res := make([]loanInstallmentResponse, len(installments))
+	for i, installment := range installments {
+		res[i] = loanInstallmentResponse{
+			ID:            installment.ID,
+			Period:        installment.Period,
+			DueDate:       installment.DueDate.Format(dateFormat),
+			Principal:     installment.Principal,
+			Interest:      installment.Interest,
+			Fee:           installment.Fee,
+			PaidPrincipal: installment.PaidPrincipal,
+			PaidInterest:  installment.PaidInterest,
+			PaidFee:       installment.PaidFee,
+			Status:        installment.Status,
+		}
+		if installment.PaidAt.Valid {
+			res[i].PaidAt = &installment.PaidAt.Time
+		}
+	}
+	return res
+}
+
+type loanRepaymentResponse struct {
+	ID int64 `json:"id"`
+	// InstallmentID is the installment collected, unless the repayment was
+	// early.
+	InstallmentID *int64    `json:"installment_id,omitempty"`
+	Kind          string    `json:"kind"`
+	Amount        int64     `json:"amount"`
+	Principal     int64     `json:"principal"`
+	Interest      int64     `json:"interest"`
+	Fee           int64     `json:"fee"`
+	EntryID       int64     `json:"entry_id"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func newLoanRepaymentResponse(repayment db.LoanRepayment) loanRepaymentResponse {
+	res := loanRepaymentResponse{
+		ID:        repayment.ID,
+		Kind:      repayment.Kind,
+		Amount:    repayment.Amount,
+		Principal: repayment.Principal,
+		Interest:  repayment.Interest,
+		Fee:       repayment.Fee,
+		EntryID:   repayment.EntryID,
+		CreatedAt: repayment.CreatedAt,
+	}
+	if repayment.InstallmentID.Valid {
+		res.InstallmentID = &repayment.InstallmentID.Int64
+	}
+	return res
+}
+
+type loanArrearsResponse struct {
+	// Installments is how many installments past their due date aren't paid
+	// and Amount what is owed on them, late fees included.
+	Installments int64 `json:"installments"`
+	Amount       int64 `json:"amount"`
+}
+
+type loanDetailResponse struct {
+	Loan         loanResponse              `json:"loan"`
+	Installments []loanInstallmentResponse `json:"installments"`
+	Repayments   []loanRepaymentResponse   `json:"repayments"`
+	Arrears      loanArrearsResponse       `json:"arrears"`
+}
+
+type createLoanRequest struct {
+	AccountID  int64 `json:"account_id" validate:"required,min=1"`
+	ProductID  int64 `json:"product_id" validate:"required,min=1"`
+	Principal  int64 `json:"principal" validate:"required,min=1"`
+	TermMonths int32 `json:"term_months" validate:"required,min=1"`
+}
+
+// createLoan godoc
+// @Summary Take out a loan
+// @Description Borrow under a loan product: the principal is paid into a checking account of the authenticated user in the currency of the product, and repaid in monthly installments collected from the same account on their due dates. Only verified customers may borrow, not while an installment of any of their loans is past due, and what they owe under a product may not go over its maximum principal.
+// @Tags Loans
+// @Accept json
+// @Produce json
+// @Param request body createLoanRequest true "Loan"
+// @Success 201 {object} loanDetailResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "KYC Required or Account Action Forbidden"
+// @Failure 404 {object} Problem "Account or Loan Product Not Found"
+// @Failure 409 {object} Problem "Loan In Arrears"
+// @Failure 422 {object} Problem "Loan Terms Invalid, Loan Limit Exceeded, Currency Mismatch or Account Frozen"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /loans [post]
+func (server *Server) createLoan(ctx echo.Context) error {
+	req := new(createLoanRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	user := authUser(ctx)
+	if user.KYCStatus != db.KYCVerified {
+		return newProblem(http.StatusForbidden, CodeKYCRequired, "loans are only offered to verified customers")
+	}
+
+	product, err := server.store.GetLoanProduct(ctx.Request().Context(), req.ProductID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return newProblem(http.StatusNotFound, CodeLoanProductNotFound, "loan product not found")
+		}
+		return err
+	}
+
+	account, err := server.store.GetAccount(ctx.Request().Context(), req.AccountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return newProblem(http.StatusNotFound, CodeAccountNotFound, "account not found")
+		}
+		return err
+	}
+	if _, err := server.authorizeAccount(ctx, account, db.AccountActionManage); err != nil {
+		return err
+	}
+
+	switch {
+	case account.Type != db.AccountChecking:
+		return newProblem(http.StatusUnprocessableEntity, CodeLoanTermsInvalid, "loans are paid into checking accounts")
+	case account.Currency != product.Currency:
+		return newProblem(http.StatusUnprocessableEntity, CodeCurrencyMismatch, "the loan product is in "+product.Currency)
+	case req.Principal < product.MinPrincipal || req.Principal > product.MaxPrincipal:
+		return newProblem(http.StatusUnprocessableEntity, CodeLoanTermsInvalid,
+			fmt.Sprintf("the principal must be between %d and %d", product.MinPrincipal, product.MaxPrincipal))
+	case req.TermMonths < product.MinTerm || req.TermMonths > product.MaxTerm:
+		return newProblem(http.StatusUnprocessableEntity, CodeLoanTermsInvalid,
+			fmt.Sprintf("the term must be between %d and %d months", product.MinTerm, product.MaxTerm))
+	}
+
+	result, err := server.store.OriginateLoanTx(ctx.Request().Context(), db.OriginateLoanTxParams{
+		Loan: db.CreateLoanParams{
+			ProductID:   product.ID,
+			AccountID:   account.ID,
+			Borrower:    user.Username,
+			Principal:   req.Principal,
+			RateBps:     product.RateBps,
+			TermMonths:  req.TermMonths,
+			Method:      product.Method,
+			LateFee:     product.LateFee,
+			GraceDays:   product.GraceDays,
+			DisbursedOn: today(),
+		},
+		Schedule: loans.LoanSchedule,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrAccountFrozen):
+			return newProblem(http.StatusUnprocessableEntity, CodeAccountFrozen, err.Error())
+		case errors.Is(err, db.ErrBorrowerInArrears):
+			return newProblem(http.StatusConflict, CodeLoanInArrears, err.Error())
+		case errors.Is(err, db.ErrLoanLimitExceeded):
+			detail := fmt.Sprintf("you may owe up to %d under this product, the new loan included", product.MaxPrincipal)
+			return newProblem(http.StatusUnprocessableEntity, CodeLoanLimitExceeded, detail)
+		}
+		return err
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionLoanOriginate,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceLoan,
+		ResourceID:   strconv.FormatInt(result.Loan.ID, 10),
+		Metadata: map[string]interface{}{
+			"product_id":  product.ID,
+			"account_id":  account.ID,
+			"principal":   result.Loan.Principal,
+			"term_months": result.Loan.TermMonths,
+			"entry_id":    result.Entry.ID,
+		},
+	})
+
+	events.PublishAll(ctx.Request().Context(), server.broker, events.AccountEvents(result.Account, result.Entry))
+
+	return ctx.JSON(http.StatusCreated, loanDetailResponse{
+		Loan:         newLoanResponse(result.Loan),
+		Installments: newLoanInstallmentResponses(result.Installments),
+		Repayments:   []loanRepaymentResponse{},
+	})
+}
+
+type listLoansRequest struct {
+	PageID   int32 `query:"page_id" validate:"required,min=1"`
+	PageSize int32 `query:"page_size" validate:"required,min=5,max=50"`
+}
+
+// listLoans godoc
+// @Summary List loans
+// @Description Get the loans of the authenticated user, latest first, with pagination.
+// @Tags Loans
+// @Produce json
+// @Param page_id query int true "Page ID for pagination"
+// @Param page_size query int true "Number of loans per page (min: 5, max: 50)"
+// @Success 200 {array} loanResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /loans [get]
+func (server *Server) listLoans(ctx echo.Context) error {
+	req := new(listLoansRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	list, err := server.store.ListLoans(ctx.Request().Context(), db.ListLoansParams{
+		Borrower: authUser(ctx).Username,
+		Limit:    req.PageSize,
+		Offset:   (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	res := make([]loanResponse, len(list))
+	for i, loan := range list {
+		res[i] = newLoanResponse(loan)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// visibleLoan loads the loan of the id path parameter, if the authenticated
+// user borrowed it or is staff.
+func (server *Server) visibleLoan(ctx echo.Context) (db.Loan, error) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil || id < 1 {
+		return db.Loan{}, newProblem(http.StatusBadRequest, CodeInvalidRequest, "invalid loan id")
+	}
+
+	notFound := newProblem(http.StatusNotFound, CodeLoanNotFound, fmt.Sprintf("loan [%d] not found", id))
+	loan, err := server.store.GetLoan(ctx.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return loan, notFound
+		}
+		return loan, err
+	}
+
+	user := authUser(ctx)
+	if loan.Borrower != user.Username && !isStaff(user) {
+		return loan, notFound
+	}
+	return loan, nil
+}
+
+// getLoan godoc
+// @Summary Get a loan
+// @Description Get a loan of the authenticated user with its schedule, the repayments made and the arrears: what is owed on installments past their due date. Installments cancelled by early repayments are listed too.
+// @Tags Loans
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Success 200 {object} loanDetailResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 404 {object} Problem "Loan Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /loans/{id} [get]
+func (server *Server) getLoan(ctx echo.Context) error {
+	loan, err := server.visibleLoan(ctx)
+	if err != nil {
+		return err
+	}
+
+	installments, err := server.store.ListLoanInstallments(ctx.Request().Context(), loan.ID)
+	if err != nil {
+		return err
+	}
+
+	repayments, err := server.store.ListLoanRepayments(ctx.Request().Context(), loan.ID)
+	if err != nil {
+		return err
+	}
+
+	arrears, err := server.store.GetLoanArrears(ctx.Request().Context(), db.GetLoanArrearsParams{
+		LoanID:    loan.ID,
+		DueBefore: today(),
+	})
+	if err != nil {
+		return err
+	}
+
+	res := loanDetailResponse{
+		Loan:         newLoanResponse(loan),
+		Installments: newLoanInstallmentResponses(installments),
+		Repayments:   make([]loanRepaymentResponse, len(repayments)),
+		Arrears: loanArrearsResponse{
+			Installments: arrears.Installments,
+			Amount:       arrears.Amount,
+		},
+	}
+	for i, repayment := range repayments {
+		res.Repayments[i] = newLoanRepaymentResponse(repayment)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+type repayLoanRequest struct {
+	Amount int64 `json:"amount" validate:"required,min=1"`
+}
+
+type repayLoanResponse struct {
+	Loan      loanResponse          `json:"loan"`
+	Repayment loanRepaymentResponse `json:"repayment"`
+	// Installments is the new schedule of what is left, empty once the loan
+	// is paid off.
+	Installments []loanInstallmentResponse `json:"installments"`
+}
+
+// repayLoan godoc
+// @Summary Repay a loan early
+// @Description Repay part or all of the outstanding principal of a loan of the authenticated user from the account it was paid into. The installments not due yet are recalculated on the principal left, over the same periods. Installments due must be paid first.
+// @Tags Loans
+// @Accept json
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Param request body repayLoanRequest true "Repayment"
+// @Success 200 {object} repayLoanResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 404 {object} Problem "Loan Not Found"
+// @Failure 409 {object} Problem "Loan Closed or Loan In Arrears"
+// @Failure 422 {object} Problem "Repayment Too Large, Insufficient Funds or Account Frozen"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /loans/{id}/repay [post]
+func (server *Server) repayLoan(ctx echo.Context) error {
+	loan, err := server.visibleLoan(ctx)
+	if err != nil {
+		return err
+	}
+	// staff may look at loans, only borrowers repay them
+	if loan.Borrower != authUser(ctx).Username {
+		return newProblem(http.StatusNotFound, CodeLoanNotFound, fmt.Sprintf("loan [%d] not found", loan.ID))
+	}
+
+	req := new(repayLoanRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	result, err := server.store.RepayLoanEarlyTx(ctx.Request().Context(), db.RepayLoanEarlyTxParams{
+		LoanID:   loan.ID,
+		Amount:   req.Amount,
+		Today:    today(),
+		Schedule: loans.LoanSchedule,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrLoanClosed):
+			return newProblem(http.StatusConflict, CodeLoanClosed, err.Error())
+		case errors.Is(err, db.ErrLoanInArrears):
+			return newProblem(http.StatusConflict, CodeLoanInArrears, err.Error())
+		case errors.Is(err, db.ErrRepaymentTooLarge):
+			return newProblem(http.StatusUnprocessableEntity, CodeRepaymentTooLarge, err.Error())
+		case errors.Is(err, db.ErrInsufficientFunds):
+			return newProblem(http.StatusUnprocessableEntity, CodeInsufficientFunds, err.Error())
+		case errors.Is(err, db.ErrAccountFrozen):
+			return newProblem(http.StatusUnprocessableEntity, CodeAccountFrozen, err.Error())
+		}
+		return err
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionLoanRepay,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceLoan,
+		ResourceID:   strconv.FormatInt(loan.ID, 10),
+		Metadata: map[string]interface{}{
+			"amount":                req.Amount,
+			"outstanding_principal": result.Loan.OutstandingPrincipal,
+			"entry_id":              result.Repayment.EntryID,
+		},
+	})
+
+	events.PublishAll(ctx.Request().Context(), server.broker, events.AccountEvents(result.Account, result.Entry))
+
+	return ctx.JSON(http.StatusOK, repayLoanResponse{
+		Loan:         newLoanResponse(result.Loan),
+		Repayment:    newLoanRepaymentResponse(result.Repayment),
+		Installments: newLoanInstallmentResponses(result.Installments),
+	})
+}
+
+// today is the current day in UTC, the calendar loans are scheduled on.
+func today() time.Time {
+	year, month, day := time.Now().UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,846 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func randomLoanProduct(createdBy string) db.LoanProduct {
+	return db.LoanProduct{
+		ID:           utils.RandomInt(1, 1000),
+		Name:         "Loan " + utils.RandomString(6),
+		Currency:     "EUR",
+		RateBps:      1200,
+		Method:       db.LoanAnnuity,
+		MinPrincipal: 10_000,
+		MaxPrincipal: 5_000_000,
+		MinTerm:      3,
+		MaxTerm:      60,
+		LateFee:      500,
+		GraceDays:    5,
+		CreatedBy:    createdBy,
+		CreatedAt:    time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func randomLoan(borrower string, accountID int64) db.Loan {
+	return db.Loan{
+		ID:                   utils.RandomInt(1, 1000),
+		ProductID:            utils.RandomInt(1, 1000),
+		AccountID:            accountID,
+		Borrower:             borrower,
+		Principal:            1_000_000,
+		RateBps:              1200,
+		TermMonths:           12,
+		Method:               db.LoanAnnuity,
+		LateFee:              500,
+		GraceDays:            5,
+		OutstandingPrincipal: 921_151,
+		Status:               db.LoanActive,
+		DisbursedOn:          time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC),
+		DisbursementEntryID:  sql.NullInt64{Int64: utils.RandomInt(1, 1000), Valid: true},
+		CreatedAt:            time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func TestCreateLoanProductAPI(t *testing.T) {
+	admin := randomAdmin(t)
+	customer, _ := randomUser(t)
+	product := randomLoanProduct(admin.Username)
+
+	body := func(change func(body echo.Map)) echo.Map {
+		body := echo.Map{
+			"name":          product.Name,
+			"currency":      product.Currency,
+			"rate_bps":      product.RateBps,
+			"method":        product.Method,
+			"min_principal": product.MinPrincipal,
+			"max_principal": product.MaxPrincipal,
+			"min_term":      product.MinTerm,
+			"max_term":      product.MaxTerm,
+			"late_fee":      product.LateFee,
+			"grace_days":    product.GraceDays,
+		}
+		if change != nil {
+			change(body)
+		}
+		return body
+	}
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		body          echo.Map
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			user: admin,
+			body: body(nil),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, admin)
+				store.EXPECT().
+					CreateLoanProduct(gomock.Any(), gomock.Eq(db.CreateLoanProductParams{
+						Name:         product.Name,
+						Currency:     product.Currency,
+						RateBps:      product.RateBps,
+						Method:       product.Method,
+						MinPrincipal: product.MinPrincipal,
+						MaxPrincipal: product.MaxPrincipal,
+						MinTerm:      product.MinTerm,
+						MaxTerm:      product.MaxTerm,
+						LateFee:      product.LateFee,
+						GraceDays:    product.GraceDays,
+						CreatedBy:    admin.Username,
+					})).
+					Times(1).
+					Return(product, nil)
+				expectAuditEvent(store, audit.ActionLoanProductCreate, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var res db.LoanProduct
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, product, res)
+			},
+		},
+		{
+			name: "InvalidMethod",
+			user: admin,
+			body: body(func(body echo.Map) { body["method"] = "balloon" }),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, admin)
+				store.EXPECT().CreateLoanProduct(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name: "TermTooLong",
+			user: admin,
+			body: body(func(body echo.Map) { body["max_term"] = 601 }),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, admin)
+				store.EXPECT().CreateLoanProduct(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name: "MaxBelowMin",
+			user: admin,
+			body: body(func(body echo.Map) { body["max_principal"] = product.MinPrincipal - 1 }),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, admin)
+				store.EXPECT().CreateLoanProduct(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, CodeLoanTermsInvalid)
+			},
+		},
+		{
+			name: "Customer",
+			user: customer,
+			body: body(nil),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, customer)
+				store.EXPECT().CreateLoanProduct(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeAdminRequired)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/loans/products", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListLoanProductsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	products := []db.LoanProduct{randomLoanProduct("admin"), randomLoanProduct("admin")}
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	expectUser(store, user)
+	store.EXPECT().
+		ListLoanProducts(gomock.Any()).
+		Times(1).
+		Return(products, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/loans/products", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var res []db.LoanProduct
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+	require.Equal(t, products, res)
+}
+
+func TestCreateLoanAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	unverified, _ := randomUser(t)
+	unverified.KYCStatus = db.KYCPending
+	other, _ := randomUser(t)
+
+	product := randomLoanProduct("admin")
+	account := randomAccount(user.Username)
+	account.Currency = product.Currency
+	account.Type = db.AccountChecking
+	savings := account
+	savings.Type = db.AccountSavings
+	dollars := account
+	dollars.Currency = "USD"
+	otherAccount := randomAccount(other.Username)
+
+	loan := randomLoan(user.Username, account.ID)
+	loan.ProductID = product.ID
+	loan.OutstandingPrincipal = loan.Principal
+	installments := []db.LoanInstallment{
+		{ID: 1, LoanID: loan.ID, Period: 1, DueDate: time.Date(2024, time.February, 15, 0, 0, 0, 0, time.UTC), Principal: 78_849, Interest: 10_000, Status: db.InstallmentScheduled},
+		{ID: 2, LoanID: loan.ID, Period: 2, DueDate: time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC), Principal: 79_637, Interest: 9_212, Status: db.InstallmentScheduled},
+	}
+
+	body := echo.Map{
+		"account_id":  account.ID,
+		"product_id":  product.ID,
+		"principal":   loan.Principal,
+		"term_months": loan.TermMonths,
+	}
+	withBody := func(key string, value any) echo.Map {
+		changed := echo.Map{}
+		for k, v := range body {
+			changed[k] = v
+		}
+		changed[key] = value
+		return changed
+	}
+	expectProductAndAccount := func(store *mockdb.MockStore, account db.Account) {
+		store.EXPECT().
+			GetLoanProduct(gomock.Any(), gomock.Eq(product.ID)).
+			Times(1).
+			Return(product, nil)
+		store.EXPECT().
+			GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+			Times(1).
+			Return(account, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		body          echo.Map
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			user: user,
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				expectProductAndAccount(store, account)
+				store.EXPECT().
+					OriginateLoanTx(gomock.Any(), gomock.Cond(func(x any) bool {
+						arg, ok := x.(db.OriginateLoanTxParams)
+						return ok && arg.Schedule != nil &&
+							arg.Loan.ProductID == product.ID &&
+							arg.Loan.AccountID == account.ID &&
+							arg.Loan.Borrower == user.Username &&
+							arg.Loan.Principal == loan.Principal &&
+							arg.Loan.TermMonths == loan.TermMonths &&
+							arg.Loan.RateBps == product.RateBps &&
+							arg.Loan.Method == product.Method &&
+							arg.Loan.LateFee == product.LateFee &&
+							arg.Loan.GraceDays == product.GraceDays &&
+							arg.Loan.DisbursedOn.Equal(today())
+					})).
+					Times(1).
+					Return(db.OriginateLoanTxResult{Loan: loan, Installments: installments}, nil)
+				expectAuditEvent(store, audit.ActionLoanOriginate, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var res loanDetailResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, loan.ID, res.Loan.ID)
+				require.Equal(t, "2024-01-15", res.Loan.DisbursedOn)
+				require.Equal(t, loan.DisbursementEntryID.Int64, *res.Loan.DisbursementEntryID)
+				require.Len(t, res.Installments, 2)
+				require.Equal(t, "2024-02-15", res.Installments[0].DueDate)
+				require.Equal(t, int64(78_849), res.Installments[0].Principal)
+				require.Empty(t, res.Repayments)
+			},
+		},
+		{
+			name: "KYCRequired",
+			user: unverified,
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, unverified)
+				store.EXPECT().GetLoanProduct(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().OriginateLoanTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeKYCRequired)
+			},
+		},
+		{
+			name: "ProductNotFound",
+			user: user,
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().
+					GetLoanProduct(gomock.Any(), gomock.Eq(product.ID)).
+					Times(1).
+					Return(db.LoanProduct{}, sql.ErrNoRows)
+				store.EXPECT().OriginateLoanTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, CodeLoanProductNotFound)
+			},
+		},
+		{
+			name: "AccountNotOwned",
+			user: user,
+			body: withBody("account_id", otherAccount.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				expectProductAndAccount(store, otherAccount)
+				expectNoMembership(store)
+				store.EXPECT().OriginateLoanTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireProblemCode(t, recorder, CodeAccountNotOwned)
+			},
+		},
+		{
+			name: "SavingsAccount",
+			user: user,
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				expectProductAndAccount(store, savings)
+				store.EXPECT().OriginateLoanTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, CodeLoanTermsInvalid)
+			},
+		},
+		{
+			name: "CurrencyMismatch",
+			user: user,
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				expectProductAndAccount(store, dollars)
+				store.EXPECT().OriginateLoanTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, CodeCurrencyMismatch)
+			},
+		},
+		{
+			name: "PrincipalTooLarge",
+			user: user,
+			body: withBody("principal", product.MaxPrincipal+1),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				expectProductAndAccount(store, account)
+				store.EXPECT().OriginateLoanTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, CodeLoanTermsInvalid)
+			},
+		},
+		{
+			name: "TermTooShort",
+			user: user,
+			body: withBody("term_months", product.MinTerm-1),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				expectProductAndAccount(store, account)
+				store.EXPECT().OriginateLoanTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, CodeLoanTermsInvalid)
+			},
+		},
+		{
+			name: "AccountFrozen",
+			user: user,
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				expectProductAndAccount(store, account)
+				store.EXPECT().
+					OriginateLoanTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OriginateLoanTxResult{}, db.ErrAccountFrozen)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, CodeAccountFrozen)
+			},
+		},
+		{
+			name: "InArrears",
+			user: user,
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				expectProductAndAccount(store, account)
+				store.EXPECT().
+					OriginateLoanTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OriginateLoanTxResult{}, db.ErrBorrowerInArrears)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, CodeLoanInArrears)
+			},
+		},
+		{
+			name: "LimitExceeded",
+			user: user,
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				expectProductAndAccount(store, account)
+				store.EXPECT().
+					OriginateLoanTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OriginateLoanTxResult{}, db.ErrLoanLimitExceeded)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, CodeLoanLimitExceeded)
+			},
+		},
+		{
+			name: "InvalidPrincipal",
+			user: user,
+			body: withBody("principal", 0),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().GetLoanProduct(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/loans", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListLoansAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	list := []db.Loan{randomLoan(user.Username, 1), randomLoan(user.Username, 2)}
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	expectUser(store, user)
+	store.EXPECT().
+		ListLoans(gomock.Any(), gomock.Eq(db.ListLoansParams{
+			Borrower: user.Username,
+			Limit:    5,
+			Offset:   5,
+		})).
+		Times(1).
+		Return(list, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/loans?page_id=2&page_size=5", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var res []loanResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+	require.Len(t, res, 2)
+	require.Equal(t, list[1].ID, res[1].ID)
+	require.Equal(t, list[1].OutstandingPrincipal, res[1].OutstandingPrincipal)
+}
+
+func TestGetLoanAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	other, _ := randomUser(t)
+	support := randomSupport(t)
+	loan := randomLoan(user.Username, utils.RandomInt(1, 1000))
+
+	installments := []db.LoanInstallment{
+		{ID: 1, LoanID: loan.ID, Period: 1, DueDate: time.Date(2024, time.February, 15, 0, 0, 0, 0, time.UTC), Principal: 78_849, Interest: 10_000, PaidPrincipal: 78_849, PaidInterest: 10_000, Status: db.InstallmentPaid, PaidAt: sql.NullTime{Time: time.Now().UTC().Truncate(time.Second), Valid: true}},
+		{ID: 2, LoanID: loan.ID, Period: 2, DueDate: time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC), Principal: 79_637, Interest: 9_212, Fee: 500, Status: db.InstallmentOverdue},
+	}
+	repayments := []db.LoanRepayment{
+		{ID: 1, LoanID: loan.ID, InstallmentID: sql.NullInt64{Int64: 1, Valid: true}, Kind: db.RepaymentScheduled, Amount: 88_849, Principal: 78_849, Interest: 10_000, EntryID: 7},
+	}
+
+	expectDetail := func(store *mockdb.MockStore) {
+		store.EXPECT().
+			ListLoanInstallments(gomock.Any(), gomock.Eq(loan.ID)).
+			Times(1).
+			Return(installments, nil)
+		store.EXPECT().
+			ListLoanRepayments(gomock.Any(), gomock.Eq(loan.ID)).
+			Times(1).
+			Return(repayments, nil)
+		store.EXPECT().
+			GetLoanArrears(gomock.Any(), gomock.Eq(db.GetLoanArrearsParams{LoanID: loan.ID, DueBefore: today()})).
+			Times(1).
+			Return(db.GetLoanArrearsRow{Installments: 1, Amount: 89_349}, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		loanID        int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			user:   user,
+			loanID: loan.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().
+					GetLoan(gomock.Any(), gomock.Eq(loan.ID)).
+					Times(1).
+					Return(loan, nil)
+				expectDetail(store)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res loanDetailResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, loan.ID, res.Loan.ID)
+				require.Len(t, res.Installments, 2)
+				require.NotNil(t, res.Installments[0].PaidAt)
+				require.Equal(t, db.InstallmentOverdue, res.Installments[1].Status)
+				require.Equal(t, int64(500), res.Installments[1].Fee)
+				require.Nil(t, res.Installments[1].PaidAt)
+				require.Len(t, res.Repayments, 1)
+				require.Equal(t, int64(1), *res.Repayments[0].InstallmentID)
+				require.Equal(t, loanArrearsResponse{Installments: 1, Amount: 89_349}, res.Arrears)
+			},
+		},
+		{
+			name:   "Support",
+			user:   support,
+			loanID: loan.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().
+					GetLoan(gomock.Any(), gomock.Eq(loan.ID)).
+					Times(1).
+					Return(loan, nil)
+				expectDetail(store)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "OtherUser",
+			user:   other,
+			loanID: loan.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, other)
+				store.EXPECT().
+					GetLoan(gomock.Any(), gomock.Eq(loan.ID)).
+					Times(1).
+					Return(loan, nil)
+				store.EXPECT().ListLoanInstallments(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, CodeLoanNotFound)
+			},
+		},
+		{
+			name:   "NotFound",
+			user:   user,
+			loanID: loan.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().
+					GetLoan(gomock.Any(), gomock.Eq(loan.ID)).
+					Times(1).
+					Return(db.Loan{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, CodeLoanNotFound)
+			},
+		},
+		{
+			name:   "InvalidID",
+			user:   user,
+			loanID: 0,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().GetLoan(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeInvalidRequest)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/loans/%d", tc.loanID), nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRepayLoanAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	support := randomSupport(t)
+	loan := randomLoan(user.Username, utils.RandomInt(1, 1000))
+
+	repaid := loan
+	repaid.OutstandingPrincipal -= 100_000
+	repayment := db.LoanRepayment{ID: 3, LoanID: loan.ID, Kind: db.RepaymentEarly, Amount: 100_000, Principal: 100_000, EntryID: 9}
+	installments := []db.LoanInstallment{
+		{ID: 20, LoanID: loan.ID, Period: 2, DueDate: time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC), Principal: 70_000, Interest: 8_212, Status: db.InstallmentScheduled},
+	}
+
+	expectLoan := func(store *mockdb.MockStore) {
+		store.EXPECT().
+			GetLoan(gomock.Any(), gomock.Eq(loan.ID)).
+			Times(1).
+			Return(loan, nil)
+	}
+	testCases := []struct {
+		name          string
+		user          db.User
+		body          echo.Map
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			user: user,
+			body: echo.Map{"amount": 100_000},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				expectLoan(store)
+				store.EXPECT().
+					RepayLoanEarlyTx(gomock.Any(), gomock.Cond(func(x any) bool {
+						arg, ok := x.(db.RepayLoanEarlyTxParams)
+						return ok && arg.Schedule != nil &&
+							arg.LoanID == loan.ID &&
+							arg.Amount == 100_000 &&
+							arg.Today.Equal(today())
+					})).
+					Times(1).
+					Return(db.RepayLoanEarlyTxResult{Loan: repaid, Repayment: repayment, Installments: installments}, nil)
+				expectAuditEvent(store, audit.ActionLoanRepay, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res repayLoanResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, repaid.OutstandingPrincipal, res.Loan.OutstandingPrincipal)
+				require.Equal(t, db.RepaymentEarly, res.Repayment.Kind)
+				require.Nil(t, res.Repayment.InstallmentID)
+				require.Len(t, res.Installments, 1)
+				require.Equal(t, int64(70_000), res.Installments[0].Principal)
+			},
+		},
+		{
+			name: "InvalidAmount",
+			user: user,
+			body: echo.Map{"amount": 0},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				expectLoan(store)
+				store.EXPECT().RepayLoanEarlyTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name: "Support",
+			user: support,
+			body: echo.Map{"amount": 100_000},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				expectLoan(store)
+				store.EXPECT().RepayLoanEarlyTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, CodeLoanNotFound)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/loans/%d/repay", loan.ID), bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRepayLoanAPIErrors(t *testing.T) {
+	user, _ := randomUser(t)
+	loan := randomLoan(user.Username, utils.RandomInt(1, 1000))
+
+	testCases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{err: db.ErrLoanClosed, status: http.StatusConflict, code: CodeLoanClosed},
+		{err: db.ErrLoanInArrears, status: http.StatusConflict, code: CodeLoanInArrears},
+		{err: db.ErrRepaymentTooLarge, status: http.StatusUnprocessableEntity, code: CodeRepaymentTooLarge},
+		{err: db.ErrInsufficientFunds, status: http.StatusUnprocessableEntity, code: CodeInsufficientFunds},
+		{err: db.ErrAccountFrozen, status: http.StatusUnprocessableEntity, code: CodeAccountFrozen},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.code, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			expectUser(store, user)
+			store.EXPECT().
+				GetLoan(gomock.Any(), gomock.Eq(loan.ID)).
+				Times(1).
+				Return(loan, nil)
+			store.EXPECT().
+				RepayLoanEarlyTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.RepayLoanEarlyTxResult{}, tc.err)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/loans/%d/repay", loan.ID), bytes.NewReader([]byte(`{"amount":100000}`)))
+			require.NoError(t, err)
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.status, recorder.Code)
+			requireProblemCode(t, recorder, tc.code)
+		})
+	}
+}
@@ -5,7 +5,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/danielmoisa/neobank/blob"
 	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/events"
+	"github.com/danielmoisa/neobank/fraud"
+	"github.com/danielmoisa/neobank/mail"
+	"github.com/danielmoisa/neobank/metrics"
+	"github.com/danielmoisa/neobank/ratelimit"
+	"github.com/danielmoisa/neobank/screening"
 	"github.com/danielmoisa/neobank/utils"
 	"github.com/stretchr/testify/require"
 )
@@ -16,7 +23,10 @@ func newTestServer(t *testing.T, store db.Store) *Server {
 		AccessTokenDuration: time.Minute,
 	}
 
-	server, err := NewServer(config, store)
+	fraudEngine, err := fraud.NewEngine(nil, 0, 0)
+	require.NoError(t, err)
+
+	server, err := NewServer(config, store, events.NewMemoryBroker(), metrics.New(), ratelimit.NewMemoryLimiter(), mail.NewMemoryMailer(), blob.NewMemoryStorage(), screening.NewScreener(0, nil), fraudEngine)
 	require.NoError(t, err)
 
 	return server
@@ -0,0 +1,468 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/labstack/echo/v4"
+)
+
+// accountInvitationTTL is how long an invitee has to accept an invitation.
+const accountInvitationTTL = 7 * 24 * time.Hour
+
+type accountMemberResponse struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	// PaymentLimit is the most a payer may send in a day, 0 for the other
+	// roles.
+	PaymentLimit int64 `json:"payment_limit"`
+	// InvitedBy is empty for the owner.
+	InvitedBy string    `json:"invited_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type accountInvitationResponse struct {
+	ID           int64      `json:"id"`
+	AccountID    int64      `json:"account_id"`
+	Invitee      string     `json:"invitee"`
+	Role         string     `json:"role"`
+	PaymentLimit int64      `json:"payment_limit"`
+	InvitedBy    string     `json:"invited_by"`
+	Status       string     `json:"status"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	RespondedAt  *time.Time `json:"responded_at,omitempty"`
+}
+
+func newAccountInvitationResponse(invitation db.AccountInvitation) accountInvitationResponse {
+	res := accountInvitationResponse{
+		ID:           invitation.ID,
+		AccountID:    invitation.AccountID,
+		Invitee:      invitation.Invitee,
+		Role:         invitation.Role,
+		PaymentLimit: invitation.PaymentLimit,
+		InvitedBy:    invitation.InvitedBy,
+		Status:       invitation.Status,
+		ExpiresAt:    invitation.ExpiresAt,
+		CreatedAt:    invitation.CreatedAt,
+	}
+	if invitation.RespondedAt.Valid {
+		res.RespondedAt = &invitation.RespondedAt.Time
+	}
+	return res
+}
+
+// listAccountMembers godoc
+// @Summary List account members
+// @Description Get the users of an account the authenticated user has a role on: its owner first, then the members who accepted an invitation.
+// @Tags Accounts
+// @Produce json
+// @Param id path int true "Account ID"
+// @Success 200 {array} accountMemberResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized or Account Not Owned"
+// @Failure 403 {object} Problem "Insufficient Scope"
+// @Failure 404 {object} Problem "Account Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /accounts/{id}/members [get]
+func (server *Server) listAccountMembers(ctx echo.Context) error {
+	account, err := server.authorizedAccount(ctx, db.AccountActionView)
+	if err != nil {
+		return err
+	}
+
+	members, err := server.store.ListAccountMembers(ctx.Request().Context(), account.ID)
+	if err != nil {
+		return err
+	}
+
+	res := make([]accountMemberResponse, 0, len(members)+1)
+	res = append(res, accountMemberResponse{
+		Username:  account.Owner,
+		Role:      db.AccountRoleOwner,
+		CreatedAt: account.CreatedAt,
+	})
+	for _, member := range members {
+		res = append(res, accountMemberResponse{
+			Username:     member.Username,
+			Role:         member.Role,
+			PaymentLimit: member.PaymentLimit,
+			InvitedBy:    member.InvitedBy,
+			CreatedAt:    member.CreatedAt,
+		})
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+type inviteAccountMemberRequest struct {
+	Username     string `json:"username" validate:"required,alphanum"`
+	Role         string `json:"role" validate:"required,oneof=co_owner viewer payer"`
+	PaymentLimit int64  `json:"payment_limit" validate:"min=0"`
+}
+
+// inviteAccountMember godoc
+// @Summary Invite a user to an account
+// @Description Invite a user to share a checking or savings account of the authenticated user. Co-owners may view the account, pay from it and manage its pockets, cards and loans; viewers may only view it; payers may view it and send payments of up to their payment limit, which only they have. The invitee has 7 days to accept; accepting replaces the role they had on the account.
+// @Tags Accounts
+// @Accept json
+// @Produce json
+// @Param id path int true "Account ID"
+// @Param request body inviteAccountMemberRequest true "Invitation"
+// @Success 201 {object} accountInvitationResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized or Account Not Owned"
+// @Failure 403 {object} Problem "Insufficient Scope or Account Action Forbidden"
+// @Failure 404 {object} Problem "Account Not Found or User Not Found"
+// @Failure 409 {object} Problem "Invitation Exists"
+// @Failure 422 {object} Problem "Invitation Invalid or Account Not Shareable"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /accounts/{id}/invitations [post]
+func (server *Server) inviteAccountMember(ctx echo.Context) error {
+	account, err := server.authorizedAccount(ctx, db.AccountActionShare)
+	if err != nil {
+		return err
+	}
+
+	req := new(inviteAccountMemberRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	if req.Role == db.AccountRolePayer && req.PaymentLimit == 0 {
+		return newProblem(http.StatusUnprocessableEntity, CodeInvitationInvalid, "payers need a payment limit")
+	}
+	if req.Role != db.AccountRolePayer && req.PaymentLimit > 0 {
+		return newProblem(http.StatusUnprocessableEntity, CodeInvitationInvalid, "only payers have a payment limit")
+	}
+
+	if account.Type != db.AccountChecking && account.Type != db.AccountSavings {
+		return newProblem(http.StatusUnprocessableEntity, CodeAccountNotShareable, "only checking and savings accounts can be shared")
+	}
+
+	if req.Username == account.Owner {
+		return newProblem(http.StatusUnprocessableEntity, CodeInvitationInvalid, "the owner of an account can't be invited to it")
+	}
+
+	if _, err := server.store.GetUser(ctx.Request().Context(), req.Username); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return newProblem(http.StatusNotFound, CodeUserNotFound, fmt.Sprintf("user %q not found", req.Username))
+		}
+		return err
+	}
+
+	invitation, err := server.store.InviteAccountMemberTx(ctx.Request().Context(), db.CreateAccountInvitationParams{
+		AccountID:    account.ID,
+		Invitee:      req.Username,
+		Role:         req.Role,
+		PaymentLimit: req.PaymentLimit,
+		InvitedBy:    authUser(ctx).Username,
+		ExpiresAt:    time.Now().Add(accountInvitationTTL),
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			detail := fmt.Sprintf("user %q already has a pending invitation to account [%d]", req.Username, account.ID)
+			return newProblem(http.StatusConflict, CodeInvitationExists, detail)
+		}
+		return err
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionAccountInvite,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceAccountInvitation,
+		ResourceID:   strconv.FormatInt(invitation.ID, 10),
+		Metadata: map[string]interface{}{
+			"account_id":    account.ID,
+			"invitee":       invitation.Invitee,
+			"role":          invitation.Role,
+			"payment_limit": invitation.PaymentLimit,
+		},
+	})
+
+	return ctx.JSON(http.StatusCreated, newAccountInvitationResponse(invitation))
+}
+
+// listAccountInvitations godoc
+// @Summary List account invitations
+// @Description Get the pending invitations to an account of the authenticated user, expired ones included.
+// @Tags Accounts
+// @Produce json
+// @Param id path int true "Account ID"
+// @Success 200 {array} accountInvitationResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized or Account Not Owned"
+// @Failure 403 {object} Problem "Insufficient Scope or Account Action Forbidden"
+// @Failure 404 {object} Problem "Account Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /accounts/{id}/invitations [get]
+func (server *Server) listAccountInvitations(ctx echo.Context) error {
+	account, err := server.authorizedAccount(ctx, db.AccountActionShare)
+	if err != nil {
+		return err
+	}
+
+	invitations, err := server.store.ListAccountInvitations(ctx.Request().Context(), account.ID)
+	if err != nil {
+		return err
+	}
+
+	res := make([]accountInvitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		res[i] = newAccountInvitationResponse(invitation)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// removeAccountMember godoc
+// @Summary Remove an account member
+// @Description Remove a member from an account of the authenticated user, or leave an account shared with them.
+// @Tags Accounts
+// @Param id path int true "Account ID"
+// @Param username path string true "Username of the member"
+// @Success 204
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized or Account Not Owned"
+// @Failure 403 {object} Problem "Insufficient Scope or Account Action Forbidden"
+// @Failure 404 {object} Problem "Account Not Found or Account Member Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /accounts/{id}/members/{username} [delete]
+func (server *Server) removeAccountMember(ctx echo.Context) error {
+	username := ctx.Param("username")
+
+	// members may always leave an account
+	action := db.AccountActionShare
+	if username == authUser(ctx).Username {
+		action = db.AccountActionView
+	}
+
+	account, err := server.authorizedAccount(ctx, action)
+	if err != nil {
+		return err
+	}
+
+	member, err := server.store.DeleteAccountMember(ctx.Request().Context(), db.DeleteAccountMemberParams{
+		AccountID: account.ID,
+		Username:  username,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			detail := fmt.Sprintf("user %q isn't a member of account [%d]", username, account.ID)
+			return newProblem(http.StatusNotFound, CodeAccountMemberNotFound, detail)
+		}
+		return err
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionAccountMemberRemove,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceAccount,
+		ResourceID:   strconv.FormatInt(account.ID, 10),
+		Metadata: map[string]interface{}{
+			"username": member.Username,
+			"role":     member.Role,
+		},
+	})
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// listUserInvitations godoc
+// @Summary List my invitations
+// @Description Get the invitations to accounts the authenticated user may still accept.
+// @Tags Accounts
+// @Produce json
+// @Success 200 {array} accountInvitationResponse
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Insufficient Scope"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /invitations [get]
+func (server *Server) listUserInvitations(ctx echo.Context) error {
+	invitations, err := server.store.ListUserInvitations(ctx.Request().Context(), db.ListUserInvitationsParams{
+		Invitee: authUser(ctx).Username,
+		Now:     time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	res := make([]accountInvitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		res[i] = newAccountInvitationResponse(invitation)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// invitation loads the invitation of the id path parameter. Invitations of
+// other users are not found unless the authenticated user may share their
+// account.
+func (server *Server) invitation(ctx echo.Context, sharing bool) (db.AccountInvitation, error) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil || id < 1 {
+		return db.AccountInvitation{}, newProblem(http.StatusBadRequest, CodeInvalidRequest, "invalid invitation id")
+	}
+
+	notFound := newProblem(http.StatusNotFound, CodeInvitationNotFound, fmt.Sprintf("invitation [%d] not found", id))
+	invitation, err := server.store.GetAccountInvitation(ctx.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return invitation, notFound
+		}
+		return invitation, err
+	}
+
+	if !sharing {
+		if invitation.Invitee != authUser(ctx).Username {
+			return invitation, notFound
+		}
+		return invitation, nil
+	}
+
+	account, err := server.store.GetAccount(ctx.Request().Context(), invitation.AccountID)
+	if err != nil {
+		return invitation, err
+	}
+	access, err := db.AuthorizeAccount(ctx.Request().Context(), server.store, account, authUser(ctx).Username, db.AccountActionShare)
+	if errors.Is(err, db.ErrNotAccountMember) {
+		return invitation, notFound
+	}
+	if err != nil {
+		return invitation, accountAccessError(account, access, err)
+	}
+	return invitation, nil
+}
+
+// acceptAccountInvitation godoc
+// @Summary Accept an invitation
+// @Description Accept an invitation of the authenticated user to an account, becoming a member of it with the role of the invitation.
+// @Tags Accounts
+// @Produce json
+// @Param id path int true "Invitation ID"
+// @Success 200 {object} accountInvitationResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Insufficient Scope"
+// @Failure 404 {object} Problem "Invitation Not Found"
+// @Failure 409 {object} Problem "Invitation Not Pending or Invitation Expired"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /invitations/{id}/accept [post]
+func (server *Server) acceptAccountInvitation(ctx echo.Context) error {
+	invitation, err := server.invitation(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	result, err := server.store.AcceptAccountInvitationTx(ctx.Request().Context(), db.AcceptAccountInvitationTxParams{
+		InvitationID: invitation.ID,
+		Now:          time.Now(),
+	})
+	if err != nil {
+		return invitationError(invitation, err)
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionAccountInvitationAccept,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceAccountInvitation,
+		ResourceID:   strconv.FormatInt(invitation.ID, 10),
+		Metadata: map[string]interface{}{
+			"account_id": result.Member.AccountID,
+			"role":       result.Member.Role,
+		},
+	})
+
+	return ctx.JSON(http.StatusOK, newAccountInvitationResponse(result.Invitation))
+}
+
+// declineAccountInvitation godoc
+// @Summary Decline an invitation
+// @Description Decline an invitation of the authenticated user to an account.
+// @Tags Accounts
+// @Produce json
+// @Param id path int true "Invitation ID"
+// @Success 200 {object} accountInvitationResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Insufficient Scope"
+// @Failure 404 {object} Problem "Invitation Not Found"
+// @Failure 409 {object} Problem "Invitation Not Pending"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /invitations/{id}/decline [post]
+func (server *Server) declineAccountInvitation(ctx echo.Context) error {
+	invitation, err := server.invitation(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	return server.respondAccountInvitation(ctx, invitation, db.InvitationDeclined, audit.ActionAccountInvitationDecline)
+}
+
+// revokeAccountInvitation godoc
+// @Summary Revoke an invitation
+// @Description Revoke a pending invitation to an account of the authenticated user.
+// @Tags Accounts
+// @Produce json
+// @Param id path int true "Invitation ID"
+// @Success 200 {object} accountInvitationResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Insufficient Scope or Account Action Forbidden"
+// @Failure 404 {object} Problem "Invitation Not Found"
+// @Failure 409 {object} Problem "Invitation Not Pending"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /invitations/{id} [delete]
+func (server *Server) revokeAccountInvitation(ctx echo.Context) error {
+	invitation, err := server.invitation(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	return server.respondAccountInvitation(ctx, invitation, db.InvitationRevoked, audit.ActionAccountInvitationRevoke)
+}
+
+// respondAccountInvitation closes a pending invitation with status.
+func (server *Server) respondAccountInvitation(ctx echo.Context, invitation db.AccountInvitation, status string, action string) error {
+	responded, err := server.store.RespondAccountInvitation(ctx.Request().Context(), db.RespondAccountInvitationParams{
+		Status: status,
+		ID:     invitation.ID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = db.ErrInvitationNotPending
+		}
+		return invitationError(invitation, err)
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       action,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceAccountInvitation,
+		ResourceID:   strconv.FormatInt(invitation.ID, 10),
+		Metadata: map[string]interface{}{
+			"account_id": responded.AccountID,
+			"invitee":    responded.Invitee,
+		},
+	})
+
+	return ctx.JSON(http.StatusOK, newAccountInvitationResponse(responded))
+}
+
+// invitationError maps the errors of responding to an invitation.
+func invitationError(invitation db.AccountInvitation, err error) error {
+	switch {
+	case errors.Is(err, db.ErrInvitationNotPending):
+		return newProblem(http.StatusConflict, CodeInvitationNotPending, fmt.Sprintf("invitation [%d] isn't pending anymore", invitation.ID))
+	case errors.Is(err, db.ErrInvitationExpired):
+		return newProblem(http.StatusConflict, CodeInvitationExpired, fmt.Sprintf("invitation [%d] expired", invitation.ID))
+	}
+	return err
+}
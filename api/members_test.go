@@ -0,0 +1,763 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/labstack/echo/v4"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// expectNoMembership expects the user to be looked up among the members of
+// an account they don't own, and not found.
+func expectNoMembership(store *mockdb.MockStore) {
+	store.EXPECT().
+		GetAccountMember(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.AccountMember{}, sql.ErrNoRows)
+}
+
+// expectMembership expects username to be found among the members of
+// account with role.
+func expectMembership(store *mockdb.MockStore, account db.Account, username string, role string, paymentLimit int64) {
+	store.EXPECT().
+		GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account.ID, Username: username})).
+		Times(1).
+		Return(db.AccountMember{
+			AccountID:    account.ID,
+			Username:     username,
+			Role:         role,
+			PaymentLimit: paymentLimit,
+			InvitedBy:    account.Owner,
+		}, nil)
+}
+
+func randomInvitation(account db.Account, invitee string, role string) db.AccountInvitation {
+	return db.AccountInvitation{
+		ID:        account.ID + 10,
+		AccountID: account.ID,
+		Invitee:   invitee,
+		Role:      role,
+		InvitedBy: account.Owner,
+		Status:    db.InvitationPending,
+		ExpiresAt: time.Now().Add(accountInvitationTTL).UTC().Truncate(time.Second),
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func TestGetAccountAsMemberAPI(t *testing.T) {
+	owner, _ := randomUser(t)
+	member, _ := randomUser(t)
+	account := randomAccount(owner.Username)
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	expectUser(store, member)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	expectMembership(store, account, member.Username, db.AccountRoleViewer, 0)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/accounts/%d", account.ID), nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, member.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	requireBodyMatchAccount(t, recorder.Body, account)
+}
+
+func TestListAccountMembersAPI(t *testing.T) {
+	owner, _ := randomUser(t)
+	member, _ := randomUser(t)
+	account := randomAccount(owner.Username)
+	account.CreatedAt = time.Now().UTC().Truncate(time.Second)
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	expectUser(store, member)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	expectMembership(store, account, member.Username, db.AccountRolePayer, 5_000)
+	store.EXPECT().
+		ListAccountMembers(gomock.Any(), gomock.Eq(account.ID)).
+		Times(1).
+		Return([]db.AccountMember{{
+			AccountID:    account.ID,
+			Username:     member.Username,
+			Role:         db.AccountRolePayer,
+			PaymentLimit: 5_000,
+			InvitedBy:    owner.Username,
+		}}, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/accounts/%d/members", account.ID), nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, member.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var res []accountMemberResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+	require.Len(t, res, 2)
+	require.Equal(t, owner.Username, res[0].Username)
+	require.Equal(t, db.AccountRoleOwner, res[0].Role)
+	require.Empty(t, res[0].InvitedBy)
+	require.Equal(t, member.Username, res[1].Username)
+	require.Equal(t, int64(5_000), res[1].PaymentLimit)
+}
+
+func TestInviteAccountMemberAPI(t *testing.T) {
+	owner, _ := randomUser(t)
+	invitee, _ := randomUser(t)
+	account := randomAccount(owner.Username)
+	account.Type = db.AccountChecking
+	invitation := randomInvitation(account, invitee.Username, db.AccountRolePayer)
+	invitation.PaymentLimit = 5_000
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		body          echo.Map
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			user: owner,
+			body: echo.Map{"username": invitee.Username, "role": "payer", "payment_limit": 5_000},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, owner)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(invitee.Username)).Times(1).Return(invitee, nil)
+				store.EXPECT().
+					InviteAccountMemberTx(gomock.Any(), gomock.Cond(func(x any) bool {
+						arg, ok := x.(db.CreateAccountInvitationParams)
+						return ok && arg.AccountID == account.ID &&
+							arg.Invitee == invitee.Username &&
+							arg.Role == db.AccountRolePayer &&
+							arg.PaymentLimit == 5_000 &&
+							arg.InvitedBy == owner.Username &&
+							arg.ExpiresAt.After(time.Now().Add(accountInvitationTTL-time.Minute))
+					})).
+					Times(1).
+					Return(invitation, nil)
+				expectAuditEvent(store, audit.ActionAccountInvite, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var res accountInvitationResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, invitation.ID, res.ID)
+				require.Equal(t, db.InvitationPending, res.Status)
+				require.Nil(t, res.RespondedAt)
+			},
+		},
+		{
+			name: "CoOwnerCannotShare",
+			user: invitee,
+			body: echo.Map{"username": owner.Username, "role": "viewer"},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, invitee)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				expectMembership(store, account, invitee.Username, db.AccountRoleCoOwner, 0)
+				store.EXPECT().InviteAccountMemberTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeAccountActionForbidden)
+			},
+		},
+		{
+			name: "PayerWithoutLimit",
+			user: owner,
+			body: echo.Map{"username": invitee.Username, "role": "payer"},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, owner)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().InviteAccountMemberTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, CodeInvitationInvalid)
+			},
+		},
+		{
+			name: "ViewerWithLimit",
+			user: owner,
+			body: echo.Map{"username": invitee.Username, "role": "viewer", "payment_limit": 100},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, owner)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().InviteAccountMemberTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, CodeInvitationInvalid)
+			},
+		},
+		{
+			name: "InvalidRole",
+			user: owner,
+			body: echo.Map{"username": invitee.Username, "role": "owner"},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, owner)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().InviteAccountMemberTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "LoanAccount",
+			user: owner,
+			body: echo.Map{"username": invitee.Username, "role": "viewer"},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, owner)
+				loans := account
+				loans.Type = db.AccountLoans
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(loans, nil)
+				store.EXPECT().InviteAccountMemberTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, CodeAccountNotShareable)
+			},
+		},
+		{
+			name: "InviteOwner",
+			user: owner,
+			body: echo.Map{"username": owner.Username, "role": "viewer"},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, owner)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().InviteAccountMemberTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, CodeInvitationInvalid)
+			},
+		},
+		{
+			name: "UserNotFound",
+			user: owner,
+			body: echo.Map{"username": invitee.Username, "role": "viewer"},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, owner)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(invitee.Username)).Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().InviteAccountMemberTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, CodeUserNotFound)
+			},
+		},
+		{
+			name: "AlreadyInvited",
+			user: owner,
+			body: echo.Map{"username": invitee.Username, "role": "viewer"},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, owner)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(invitee.Username)).Times(1).Return(invitee, nil)
+				store.EXPECT().
+					InviteAccountMemberTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AccountInvitation{}, &pq.Error{Code: pqUniqueViolation})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, CodeInvitationExists)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/invitations", account.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAcceptAccountInvitationAPI(t *testing.T) {
+	owner, _ := randomUser(t)
+	invitee, _ := randomUser(t)
+	account := randomAccount(owner.Username)
+	invitation := randomInvitation(account, invitee.Username, db.AccountRoleCoOwner)
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			user: invitee,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, invitee)
+				store.EXPECT().GetAccountInvitation(gomock.Any(), gomock.Eq(invitation.ID)).Times(1).Return(invitation, nil)
+
+				accepted := invitation
+				accepted.Status = db.InvitationAccepted
+				accepted.RespondedAt = sql.NullTime{Time: time.Now(), Valid: true}
+				store.EXPECT().
+					AcceptAccountInvitationTx(gomock.Any(), gomock.Cond(func(x any) bool {
+						arg, ok := x.(db.AcceptAccountInvitationTxParams)
+						return ok && arg.InvitationID == invitation.ID && !arg.Now.IsZero()
+					})).
+					Times(1).
+					Return(db.AcceptAccountInvitationTxResult{
+						Invitation: accepted,
+						Member: db.AccountMember{
+							AccountID: account.ID,
+							Username:  invitee.Username,
+							Role:      db.AccountRoleCoOwner,
+						},
+					}, nil)
+				expectAuditEvent(store, audit.ActionAccountInvitationAccept, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res accountInvitationResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, db.InvitationAccepted, res.Status)
+				require.NotNil(t, res.RespondedAt)
+			},
+		},
+		{
+			name: "OtherUser",
+			user: owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, owner)
+				store.EXPECT().GetAccountInvitation(gomock.Any(), gomock.Eq(invitation.ID)).Times(1).Return(invitation, nil)
+				store.EXPECT().AcceptAccountInvitationTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, CodeInvitationNotFound)
+			},
+		},
+		{
+			name: "NotFound",
+			user: invitee,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, invitee)
+				store.EXPECT().
+					GetAccountInvitation(gomock.Any(), gomock.Eq(invitation.ID)).
+					Times(1).
+					Return(db.AccountInvitation{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, CodeInvitationNotFound)
+			},
+		},
+		{
+			name: "NotPending",
+			user: invitee,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, invitee)
+				store.EXPECT().GetAccountInvitation(gomock.Any(), gomock.Eq(invitation.ID)).Times(1).Return(invitation, nil)
+				store.EXPECT().
+					AcceptAccountInvitationTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AcceptAccountInvitationTxResult{}, db.ErrInvitationNotPending)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, CodeInvitationNotPending)
+			},
+		},
+		{
+			name: "Expired",
+			user: invitee,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, invitee)
+				store.EXPECT().GetAccountInvitation(gomock.Any(), gomock.Eq(invitation.ID)).Times(1).Return(invitation, nil)
+				store.EXPECT().
+					AcceptAccountInvitationTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AcceptAccountInvitationTxResult{}, db.ErrInvitationExpired)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, CodeInvitationExpired)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/invitations/%d/accept", invitation.ID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDeclineAccountInvitationAPI(t *testing.T) {
+	owner, _ := randomUser(t)
+	invitee, _ := randomUser(t)
+	account := randomAccount(owner.Username)
+	invitation := randomInvitation(account, invitee.Username, db.AccountRoleViewer)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				declined := invitation
+				declined.Status = db.InvitationDeclined
+				store.EXPECT().
+					RespondAccountInvitation(gomock.Any(), gomock.Eq(db.RespondAccountInvitationParams{
+						Status: db.InvitationDeclined,
+						ID:     invitation.ID,
+					})).
+					Times(1).
+					Return(declined, nil)
+				expectAuditEvent(store, audit.ActionAccountInvitationDecline, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res accountInvitationResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, db.InvitationDeclined, res.Status)
+			},
+		},
+		{
+			name: "NotPending",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RespondAccountInvitation(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AccountInvitation{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, CodeInvitationNotPending)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			expectUser(store, invitee)
+			store.EXPECT().GetAccountInvitation(gomock.Any(), gomock.Eq(invitation.ID)).Times(1).Return(invitation, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/invitations/%d/decline", invitation.ID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, invitee.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRevokeAccountInvitationAPI(t *testing.T) {
+	owner, _ := randomUser(t)
+	invitee, _ := randomUser(t)
+	account := randomAccount(owner.Username)
+	invitation := randomInvitation(account, invitee.Username, db.AccountRoleViewer)
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			user: owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				revoked := invitation
+				revoked.Status = db.InvitationRevoked
+				store.EXPECT().
+					RespondAccountInvitation(gomock.Any(), gomock.Eq(db.RespondAccountInvitationParams{
+						Status: db.InvitationRevoked,
+						ID:     invitation.ID,
+					})).
+					Times(1).
+					Return(revoked, nil)
+				expectAuditEvent(store, audit.ActionAccountInvitationRevoke, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Invitee",
+			user: invitee,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectNoMembership(store)
+				store.EXPECT().RespondAccountInvitation(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, CodeInvitationNotFound)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			expectUser(store, tc.user)
+			store.EXPECT().GetAccountInvitation(gomock.Any(), gomock.Eq(invitation.ID)).Times(1).Return(invitation, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/invitations/%d", invitation.ID)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRemoveAccountMemberAPI(t *testing.T) {
+	owner, _ := randomUser(t)
+	member, _ := randomUser(t)
+	viewer, _ := randomUser(t)
+	account := randomAccount(owner.Username)
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Owner",
+			user:     owner,
+			username: member.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteAccountMember(gomock.Any(), gomock.Eq(db.DeleteAccountMemberParams{AccountID: account.ID, Username: member.Username})).
+					Times(1).
+					Return(db.AccountMember{AccountID: account.ID, Username: member.Username, Role: db.AccountRoleCoOwner}, nil)
+				expectAuditEvent(store, audit.ActionAccountMemberRemove, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:     "Leave",
+			user:     viewer,
+			username: viewer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectMembership(store, account, viewer.Username, db.AccountRoleViewer, 0)
+				store.EXPECT().
+					DeleteAccountMember(gomock.Any(), gomock.Eq(db.DeleteAccountMemberParams{AccountID: account.ID, Username: viewer.Username})).
+					Times(1).
+					Return(db.AccountMember{AccountID: account.ID, Username: viewer.Username, Role: db.AccountRoleViewer}, nil)
+				expectAuditEvent(store, audit.ActionAccountMemberRemove, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:     "RemoveOtherMember",
+			user:     viewer,
+			username: member.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectMembership(store, account, viewer.Username, db.AccountRoleViewer, 0)
+				store.EXPECT().DeleteAccountMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeAccountActionForbidden)
+			},
+		},
+		{
+			name:     "NotAMember",
+			user:     owner,
+			username: member.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AccountMember{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, CodeAccountMemberNotFound)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			expectUser(store, tc.user)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/members/%s", account.ID, tc.username)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreatePaymentAsMemberAPI(t *testing.T) {
+	owner, _ := randomUser(t)
+	member, _ := randomUser(t)
+	account := randomAccount(owner.Username)
+	account.Type = db.AccountChecking
+
+	testCases := []struct {
+		name          string
+		role          string
+		paymentLimit  int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Viewer",
+			role: db.AccountRoleViewer,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeAccountActionForbidden)
+			},
+		},
+		{
+			name:         "PayerOverLimit",
+			role:         db.AccountRolePayer,
+			paymentLimit: 50,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodePaymentLimitExceeded)
+			},
+		},
+		{
+			name:         "PayerOverDailyLimit",
+			role:         db.AccountRolePayer,
+			paymentLimit: 500,
+			buildStubs: func(store *mockdb.MockStore) {
+				// what the payer sent from the account today counts, not
+				// what the owner sent
+				store.EXPECT().
+					GetAccountPaymentTotal(gomock.Any(), gomock.Cond(func(x any) bool {
+						arg, ok := x.(db.GetAccountPaymentTotalParams)
+						return ok && arg.FromAccountID == account.ID && arg.InitiatedBy == member.Username
+					})).
+					Times(1).
+					Return(int64(450), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodePaymentLimitExceeded)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			expectUser(store, member)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			expectMembership(store, account, member.Username, tc.role, tc.paymentLimit)
+			if tc.buildStubs != nil {
+				tc.buildStubs(store)
+			}
+			store.EXPECT().PaymentTx(gomock.Any(), gomock.Any()).Times(0)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(echo.Map{
+				"from_account_id": account.ID,
+				"to_account_id":   account.ID + 1,
+				"amount":          100,
+				"currency":        account.Currency,
+			})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/payments", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, member.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
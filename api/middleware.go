@@ -1,52 +1,448 @@
 package api
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
+	"log/slog"
+	"math"
+	"net"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/logging"
+	"github.com/danielmoisa/neobank/metrics"
+	"github.com/danielmoisa/neobank/ratelimit"
 	"github.com/danielmoisa/neobank/tokens"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
 	authorizationHeaderKey  = "authorization"
 	authorizationTypeBearer = "bearer"
 	authorizationPayloadKey = "authorization_payload"
+	authorizationUserKey    = "authorization_user"
+	authorizationAPIKeyKey  = "authorization_api_key"
 )
 
-// AuthMiddleware creates an Echo middleware for authorization
-func authMiddleware(tokenMaker tokens.Maker) echo.MiddlewareFunc {
+// authMiddleware checks the access token or API key and loads its user on
+// every request, so that disabling the user or changing their password takes
+// effect at once: tokens issued before password_changed_at are revoked.
+//
+// API keys and the access tokens of third-party apps are only accepted by
+// routes that name the scopes they need, and only when they hold all of them.
+// Access tokens of a logged in user aren't limited by scopes.
+func authMiddleware(tokenMaker tokens.Maker, store db.Store, scopes ...string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(ctx echo.Context) error {
 			authorizationHeader := ctx.Request().Header.Get(authorizationHeaderKey)
 
 			if len(authorizationHeader) == 0 {
-				err := errors.New("authorization header is not provided")
-				return ctx.JSON(http.StatusUnauthorized, ErrorResponse{Error: err.Error()})
+				return newProblem(http.StatusUnauthorized, CodeUnauthorized, "authorization header is not provided")
 			}
 
 			fields := strings.Fields(authorizationHeader)
 			if len(fields) < 2 {
-				err := errors.New("invalid authorization header format")
-				return ctx.JSON(http.StatusUnauthorized, ErrorResponse{Error: err.Error()})
+				return newProblem(http.StatusUnauthorized, CodeUnauthorized, "invalid authorization header format")
 			}
 
 			authorizationType := strings.ToLower(fields[0])
 			if authorizationType != authorizationTypeBearer {
-				err := fmt.Errorf("unsupported authorization type %s", authorizationType)
-				return ctx.JSON(http.StatusUnauthorized, ErrorResponse{Error: err.Error()})
+				detail := fmt.Sprintf("unsupported authorization type %s", authorizationType)
+				return newProblem(http.StatusUnauthorized, CodeUnauthorized, detail)
+			}
+
+			if db.IsAPIKey(fields[1]) {
+				return authenticateAPIKey(ctx, store, fields[1], scopes, next)
 			}
 
 			accessToken := fields[1]
 			payload, err := tokenMaker.VerifyToken(accessToken)
 			if err != nil {
-				return ctx.JSON(http.StatusUnauthorized, ErrorResponse{Error: err.Error()})
+				return newProblem(http.StatusUnauthorized, CodeUnauthorized, err.Error())
+			}
+
+			user, err := store.GetUser(ctx.Request().Context(), payload.Username)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return newProblem(http.StatusUnauthorized, CodeUnauthorized, "token has been revoked")
+				}
+				return err
+			}
+
+			if user.Disabled {
+				return newProblem(http.StatusForbidden, CodeUserDisabled, "user is disabled")
+			}
+			if payload.IssuedAt.Before(user.PasswordChangedAt) {
+				return newProblem(http.StatusUnauthorized, CodeUnauthorized, "token has been revoked")
+			}
+			if payload.ClientID != "" {
+				if err := authenticateOAuthClient(ctx, store, payload, scopes); err != nil {
+					return err
+				}
 			}
 
 			ctx.Set(authorizationPayloadKey, payload)
+			ctx.Set(authorizationUserKey, user)
+			ctx.SetRequest(ctx.Request().WithContext(logging.WithUsername(ctx.Request().Context(), payload.Username)))
+			return next(ctx)
+		}
+	}
+}
+
+// authenticateAPIKey is the part of authMiddleware for API keys. Keys don't
+// expire with the password of their user, but stop working when the user is
+// disabled.
+func authenticateAPIKey(ctx echo.Context, store db.Store, key string, scopes []string, next echo.HandlerFunc) error {
+	reqCtx := ctx.Request().Context()
+
+	apiKey, err := store.GetAPIKeyByHash(reqCtx, db.HashAPIKey(key))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return newProblem(http.StatusUnauthorized, CodeUnauthorized, "API key is invalid")
+		}
+		return err
+	}
+	if apiKey.RevokedAt.Valid {
+		return newProblem(http.StatusUnauthorized, CodeUnauthorized, "API key has been revoked")
+	}
+	if apiKey.ExpiresAt.Valid && !apiKey.ExpiresAt.Time.After(time.Now()) {
+		return newProblem(http.StatusUnauthorized, CodeUnauthorized, "API key has expired")
+	}
+	// RealIP goes through the IP extractor of the server, so a client can't
+	// claim an allowed address with X-Forwarded-For
+	if !ipAllowed(apiKey.AllowedIPs, ctx.RealIP()) {
+		return newProblem(http.StatusForbidden, CodeIPNotAllowed, "API key may not be used from this address")
+	}
+
+	user, err := store.GetUser(reqCtx, apiKey.Username)
+	if err != nil {
+		return err
+	}
+	if user.Disabled {
+		return newProblem(http.StatusForbidden, CodeUserDisabled, "user is disabled")
+	}
+
+	if err := requireScopes("API key", apiKey.Scopes, scopes); err != nil {
+		return err
+	}
+
+	if err := store.TouchAPIKey(reqCtx, apiKey.ID); err != nil {
+		slog.ErrorContext(reqCtx, "cannot record API key use", slog.Int64("api_key_id", apiKey.ID), slog.Any("error", err))
+	}
+
+	ctx.Set(authorizationAPIKeyKey, apiKey)
+	ctx.Set(authorizationUserKey, user)
+	ctx.SetRequest(ctx.Request().WithContext(logging.WithUsername(reqCtx, user.Username)))
+	return next(ctx)
+}
+
+// requireScopes makes sure a credential limited to the granted scopes may
+// call a route that needs the required ones. Routes that don't name their
+// scopes are off limits to such credentials.
+func requireScopes(credential string, granted, required []string) error {
+	if len(required) == 0 {
+		return newProblem(http.StatusForbidden, CodeInsufficientScope, fmt.Sprintf("%s can't be used for this request", credential))
+	}
+	for _, scope := range required {
+		if !slices.Contains(granted, scope) {
+			return newProblem(http.StatusForbidden, CodeInsufficientScope, fmt.Sprintf("%s lacks the %s scope", credential, scope))
+		}
+	}
+	return nil
+}
+
+// authenticateOAuthClient checks the consent an access token of a third-party
+// app was issued for. Revoking the consent ends its tokens at once.
+func authenticateOAuthClient(ctx echo.Context, store db.Store, payload *tokens.Payload, scopes []string) error {
+	consent, err := store.GetOAuthConsent(ctx.Request().Context(), payload.ConsentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return newProblem(http.StatusUnauthorized, CodeUnauthorized, "token has been revoked")
+		}
+		return err
+	}
+	if consent.Username != payload.Username || consent.ClientID != payload.ClientID || !consent.Active() {
+		return newProblem(http.StatusUnauthorized, CodeUnauthorized, "token has been revoked")
+	}
+
+	return requireScopes("app token", payload.Scopes, scopes)
+}
+
+// ipAllowed reports whether ip is within one of the allowed CIDRs. An empty
+// allowlist allows every address.
+func ipAllowed(allowed []string, ip string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, cidr := range allowed {
+		_, network, err := net.ParseCIDR(cidr)
+		if err == nil && network.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// authUser returns the user loaded by authMiddleware.
+func authUser(ctx echo.Context) db.User {
+	return ctx.Get(authorizationUserKey).(db.User)
+}
+
+// adminMiddleware lets only admins through. It must run after authMiddleware,
+// which loads the user, and with it the role, on every request.
+func adminMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if authUser(ctx).Role != db.RoleAdmin {
+				return newProblem(http.StatusForbidden, CodeAdminRequired, "only admins may do this")
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// isStaff reports whether user is support staff or an admin.
+func isStaff(user db.User) bool {
+	return user.Role == db.RoleSupport || user.Role == db.RoleAdmin
+}
+
+// supportMiddleware lets only support staff and admins through. Like
+// adminMiddleware it must run after authMiddleware.
+func supportMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if !isStaff(authUser(ctx)) {
+				return newProblem(http.StatusForbidden, CodeSupportRequired, "only support staff may do this")
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// ipExtractor finds the address of the client of a request. Without trusted
+// proxies it is the address the request came from; X-Forwarded-For is only
+// read when the request came through one of the trusted proxies, given as
+// CIDRs, since clients set it to anything they like.
+func ipExtractor(trustedProxies []string) (echo.IPExtractor, error) {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, cidr := range trustedProxies {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, echo.TrustIPRange(network))
+	}
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
+
+// rateLimitKey picks the bucket of a request within a route.
+type rateLimitKey func(ctx echo.Context) string
+
+func byClientIP(ctx echo.Context) string {
+	return "ip:" + ctx.RealIP()
+}
+
+// byUser must run after authMiddleware.
+func byUser(ctx echo.Context) string {
+	return "user:" + authUser(ctx).Username
+}
+
+// rateLimitMiddleware refuses requests with 429 once the bucket of the caller
+// for route is empty. Buckets of different routes are independent, so name
+// routes that should share a limit the same. When the limiter fails the
+// request is let through: an outage of the limiter should not take the API
+// down with it.
+func rateLimitMiddleware(limiter ratelimit.Limiter, m *metrics.Metrics, route string, policy ratelimit.Policy, key rateLimitKey) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			reqCtx := ctx.Request().Context()
+
+			decision, err := limiter.Allow(reqCtx, route+":"+key(ctx), policy)
+			if err != nil {
+				slog.ErrorContext(reqCtx, "cannot check rate limit", slog.String("route", route), slog.Any("error", err))
+				return next(ctx)
+			}
+
+			ctx.Response().Header().Set("X-RateLimit-Limit", strconv.Itoa(policy.Burst))
+			ctx.Response().Header().Set("X-RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+			if !decision.Allowed {
+				m.IncRateLimited(route)
+				setRetryAfter(ctx, decision.RetryAfter)
+				return newProblem(http.StatusTooManyRequests, CodeRateLimited, "too many requests, retry later")
+			}
+
 			return next(ctx)
 		}
 	}
 }
+
+// setRetryAfter tells the client how many whole seconds to wait.
+func setRetryAfter(ctx echo.Context, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	ctx.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(seconds))
+}
+
+const maxRequestIDLength = 128
+
+// requestContextMiddleware propagates X-Request-ID, generating one when the
+// client didn't send a usable value, and stores it with the client IP in the
+// request context for logging and auditing.
+func requestContextMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			requestID := ctx.Request().Header.Get(echo.HeaderXRequestID)
+			if !validRequestID(requestID) {
+				requestID = uuid.NewString()
+			}
+			ctx.Response().Header().Set(echo.HeaderXRequestID, requestID)
+
+			reqCtx := logging.WithRequestID(ctx.Request().Context(), requestID)
+			reqCtx = logging.WithClientIP(reqCtx, ctx.RealIP())
+			ctx.SetRequest(ctx.Request().WithContext(reqCtx))
+
+			return next(ctx)
+		}
+	}
+}
+
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range requestID {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// accessLogMiddleware writes one structured log line per request.
+func accessLogMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			start := time.Now()
+			if err := next(ctx); err != nil {
+				ctx.Error(err)
+			}
+
+			req := ctx.Request()
+			res := ctx.Response()
+
+			level := slog.LevelInfo
+			switch {
+			case res.Status >= http.StatusInternalServerError:
+				level = slog.LevelError
+			case res.Status >= http.StatusBadRequest:
+				level = slog.LevelWarn
+			}
+
+			slog.LogAttrs(req.Context(), level, "http request",
+				slog.String("method", req.Method),
+				slog.String("path", req.URL.Path),
+				slog.String("route", ctx.Path()),
+				slog.Int("status", res.Status),
+				slog.Duration("latency", time.Since(start)),
+				slog.Int64("bytes_out", res.Size),
+				slog.String("remote_ip", ctx.RealIP()),
+				slog.String("user_agent", req.UserAgent()),
+			)
+			return nil
+		}
+	}
+}
+
+// metricsMiddleware records the latency and status of every request by route
+// template, so that path parameters don't create new series.
+func metricsMiddleware(m *metrics.Metrics) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			start := time.Now()
+			if err := next(ctx); err != nil {
+				ctx.Error(err)
+			}
+
+			route := ctx.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+
+			m.ObserveHTTPRequest(ctx.Request().Method, route, ctx.Response().Status, time.Since(start))
+			return nil
+		}
+	}
+}
+
+// tracingMiddleware starts a server span for every request, continuing the
+// trace from the W3C traceparent header when the caller sent one. Handlers get
+// the span through the request context, so store and SQL spans nest under it.
+func tracingMiddleware(provider trace.TracerProvider, propagator propagation.TextMapPropagator) echo.MiddlewareFunc {
+	tracer := provider.Tracer("github.com/danielmoisa/neobank/api")
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			req := ctx.Request()
+			route := ctx.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+
+			spanCtx := propagator.Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+			spanCtx, span := tracer.Start(spanCtx, req.Method+" "+route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(req.Method),
+					semconv.HTTPRoute(route),
+					semconv.URLPath(req.URL.Path),
+					attribute.String("request_id", logging.RequestID(req.Context())),
+				),
+			)
+			defer span.End()
+
+			ctx.SetRequest(req.WithContext(spanCtx))
+			propagator.Inject(spanCtx, propagation.HeaderCarrier(ctx.Response().Header()))
+
+			if err := next(ctx); err != nil {
+				span.RecordError(err)
+				ctx.Error(err)
+			}
+
+			status := ctx.Response().Status
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+			return nil
+		}
+	}
+}
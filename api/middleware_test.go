@@ -1,16 +1,31 @@
 package api
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/logging"
+	"github.com/danielmoisa/neobank/metrics"
+	"github.com/danielmoisa/neobank/ratelimit"
 	"github.com/danielmoisa/neobank/tokens"
 	"github.com/danielmoisa/neobank/utils"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/mock/gomock"
 )
 
 func addAuthorization(
@@ -28,18 +43,31 @@ func addAuthorization(
 	request.Header.Set(authorizationHeaderKey, authorizationHeader)
 }
 
+// expectUser lets authMiddleware load user for a request made with their
+// token.
+func expectUser(store *mockdb.MockStore, user db.User) {
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(user, nil)
+}
+
 func TestAuthMiddleware(t *testing.T) {
-	username := utils.RandomOwner()
+	user, _ := randomUser(t)
 
 	testCases := []struct {
 		name          string
 		setupAuth     func(t *testing.T, request *http.Request, tokenMaker tokens.Maker)
+		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
 			name: "OK",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -49,6 +77,9 @@ func TestAuthMiddleware(t *testing.T) {
 			name: "NoAuthorization",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
 			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
@@ -56,7 +87,10 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name: "UnsupportedAuthorization",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
-				addAuthorization(t, request, tokenMaker, "unsupported", username, time.Minute)
+				addAuthorization(t, request, tokenMaker, "unsupported", user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -65,7 +99,10 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name: "InvalidAuthorizationFormat",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
-				addAuthorization(t, request, tokenMaker, "", username, time.Minute)
+				addAuthorization(t, request, tokenMaker, "", user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -74,7 +111,54 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name: "ExpiredToken",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, -time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, -time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "UserNotFound",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "UserDisabled",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				disabled := user
+				disabled.Disabled = true
+				expectUser(store, disabled)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeUserDisabled)
+			},
+		},
+		{
+			name: "PasswordChanged",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker tokens.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				changed := user
+				changed.PasswordChangedAt = time.Now().Add(time.Second)
+				expectUser(store, changed)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -86,14 +170,20 @@ func TestAuthMiddleware(t *testing.T) {
 		tc := testCases[i]
 
 		t.Run(tc.name, func(t *testing.T) {
-			server := newTestServer(t, nil)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
 			authPath := "/auth"
 			server.router.GET(
 				authPath,
 				func(ctx echo.Context) error {
 					return ctx.JSON(http.StatusOK, map[string]interface{}{})
 				},
-				authMiddleware(server.tokenMaker),
+				authMiddleware(server.tokenMaker, store),
 			)
 
 			recorder := httptest.NewRecorder()
@@ -106,3 +196,634 @@ func TestAuthMiddleware(t *testing.T) {
 		})
 	}
 }
+
+// randomAPIKey returns a key of user and the row stored for it.
+func randomAPIKey(t *testing.T, user db.User, scopes ...string) (string, db.APIKey) {
+	key, prefix, err := db.NewAPIKey()
+	require.NoError(t, err)
+
+	return key, db.APIKey{
+		ID:         utils.RandomInt(1, 1000),
+		Username:   user.Username,
+		Name:       utils.RandomString(6),
+		Prefix:     prefix,
+		KeyHash:    db.HashAPIKey(key),
+		Scopes:     scopes,
+		AllowedIPs: []string{},
+		CreatedAt:  time.Now(),
+	}
+}
+
+// expectAPIKey lets authMiddleware accept apiKey for user.
+func expectAPIKey(store *mockdb.MockStore, apiKey db.APIKey, user db.User) {
+	store.EXPECT().
+		GetAPIKeyByHash(gomock.Any(), gomock.Eq(apiKey.KeyHash)).
+		Times(1).
+		Return(apiKey, nil)
+	expectUser(store, user)
+	store.EXPECT().
+		TouchAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).
+		Times(1).
+		Return(nil)
+}
+
+func TestAuthMiddlewareAPIKey(t *testing.T) {
+	user, _ := randomUser(t)
+	key, apiKey := randomAPIKey(t, user, ScopeAccountsRead)
+
+	testCases := []struct {
+		name          string
+		path          string
+		forwardedFor  string
+		apiKey        func() db.APIKey
+		buildStubs    func(store *mockdb.MockStore, apiKey db.APIKey)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			path:   "/scoped",
+			apiKey: func() db.APIKey { return apiKey },
+			buildStubs: func(store *mockdb.MockStore, apiKey db.APIKey) {
+				expectAPIKey(store, apiKey, user)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "AllowedIP",
+			path: "/scoped",
+			apiKey: func() db.APIKey {
+				allowed := apiKey
+				allowed.AllowedIPs = []string{"10.0.0.0/8", "192.0.2.0/24"}
+				return allowed
+			},
+			buildStubs: func(store *mockdb.MockStore, apiKey db.APIKey) {
+				expectAPIKey(store, apiKey, user)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "TouchFails",
+			path:   "/scoped",
+			apiKey: func() db.APIKey { return apiKey },
+			buildStubs: func(store *mockdb.MockStore, apiKey db.APIKey) {
+				store.EXPECT().GetAPIKeyByHash(gomock.Any(), gomock.Any()).Times(1).Return(apiKey, nil)
+				expectUser(store, user)
+				store.EXPECT().TouchAPIKey(gomock.Any(), gomock.Any()).Times(1).Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "UnknownKey",
+			path:   "/scoped",
+			apiKey: func() db.APIKey { return apiKey },
+			buildStubs: func(store *mockdb.MockStore, apiKey db.APIKey) {
+				store.EXPECT().GetAPIKeyByHash(gomock.Any(), gomock.Any()).Times(1).Return(db.APIKey{}, sql.ErrNoRows)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Revoked",
+			path: "/scoped",
+			apiKey: func() db.APIKey {
+				revoked := apiKey
+				revoked.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+				return revoked
+			},
+			buildStubs: func(store *mockdb.MockStore, apiKey db.APIKey) {
+				store.EXPECT().GetAPIKeyByHash(gomock.Any(), gomock.Any()).Times(1).Return(apiKey, nil)
+				store.EXPECT().TouchAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Expired",
+			path: "/scoped",
+			apiKey: func() db.APIKey {
+				expired := apiKey
+				expired.ExpiresAt = sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true}
+				return expired
+			},
+			buildStubs: func(store *mockdb.MockStore, apiKey db.APIKey) {
+				store.EXPECT().GetAPIKeyByHash(gomock.Any(), gomock.Any()).Times(1).Return(apiKey, nil)
+				store.EXPECT().TouchAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "IPNotAllowed",
+			path: "/scoped",
+			apiKey: func() db.APIKey {
+				allowed := apiKey
+				allowed.AllowedIPs = []string{"10.0.0.0/8"}
+				return allowed
+			},
+			buildStubs: func(store *mockdb.MockStore, apiKey db.APIKey) {
+				store.EXPECT().GetAPIKeyByHash(gomock.Any(), gomock.Any()).Times(1).Return(apiKey, nil)
+				store.EXPECT().TouchAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeIPNotAllowed)
+			},
+		},
+		{
+			name:         "SpoofedForwardedFor",
+			path:         "/scoped",
+			forwardedFor: "10.1.2.3",
+			apiKey: func() db.APIKey {
+				allowed := apiKey
+				allowed.AllowedIPs = []string{"10.0.0.0/8"}
+				return allowed
+			},
+			buildStubs: func(store *mockdb.MockStore, apiKey db.APIKey) {
+				store.EXPECT().GetAPIKeyByHash(gomock.Any(), gomock.Any()).Times(1).Return(apiKey, nil)
+				store.EXPECT().TouchAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeIPNotAllowed)
+			},
+		},
+		{
+			name: "MissingScope",
+			path: "/scoped",
+			apiKey: func() db.APIKey {
+				other := apiKey
+				other.Scopes = []string{ScopePaymentsWrite}
+				return other
+			},
+			buildStubs: func(store *mockdb.MockStore, apiKey db.APIKey) {
+				store.EXPECT().GetAPIKeyByHash(gomock.Any(), gomock.Any()).Times(1).Return(apiKey, nil)
+				expectUser(store, user)
+				store.EXPECT().TouchAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeInsufficientScope)
+			},
+		},
+		{
+			name:   "RouteWithoutScopes",
+			path:   "/unscoped",
+			apiKey: func() db.APIKey { return apiKey },
+			buildStubs: func(store *mockdb.MockStore, apiKey db.APIKey) {
+				store.EXPECT().GetAPIKeyByHash(gomock.Any(), gomock.Any()).Times(1).Return(apiKey, nil)
+				expectUser(store, user)
+				store.EXPECT().TouchAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeInsufficientScope)
+			},
+		},
+		{
+			name:   "UserDisabled",
+			path:   "/scoped",
+			apiKey: func() db.APIKey { return apiKey },
+			buildStubs: func(store *mockdb.MockStore, apiKey db.APIKey) {
+				store.EXPECT().GetAPIKeyByHash(gomock.Any(), gomock.Any()).Times(1).Return(apiKey, nil)
+				disabled := user
+				disabled.Disabled = true
+				expectUser(store, disabled)
+				store.EXPECT().TouchAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeUserDisabled)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store, tc.apiKey())
+
+			server := newTestServer(t, store)
+			ok := func(ctx echo.Context) error {
+				return ctx.JSON(http.StatusOK, map[string]interface{}{})
+			}
+			server.router.GET("/scoped", ok, authMiddleware(server.tokenMaker, store, ScopeAccountsRead))
+			server.router.GET("/unscoped", ok, authMiddleware(server.tokenMaker, store))
+
+			recorder := httptest.NewRecorder()
+			// httptest requests come from 192.0.2.1
+			request := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			request.Header.Set(authorizationHeaderKey, authorizationTypeBearer+" "+key)
+			if tc.forwardedFor != "" {
+				request.Header.Set(echo.HeaderXForwardedFor, tc.forwardedFor)
+				request.Header.Set(echo.HeaderXRealIP, tc.forwardedFor)
+			}
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// addOAuthAuthorization authorizes request with an access token issued to
+// the client of consent.
+func addOAuthAuthorization(t *testing.T, request *http.Request, tokenMaker tokens.Maker, consent db.OAuthConsent) {
+	payload, err := tokens.NewPayload(consent.Username, time.Minute)
+	require.NoError(t, err)
+	payload.ClientID = consent.ClientID
+	payload.ConsentID = consent.ID
+	payload.Scopes = consent.Scopes
+
+	token, err := tokenMaker.CreatePayloadToken(payload)
+	require.NoError(t, err)
+	request.Header.Set(authorizationHeaderKey, authorizationTypeBearer+" "+token)
+}
+
+func randomOAuthConsent(user db.User, scopes ...string) db.OAuthConsent {
+	return db.OAuthConsent{
+		ID:        utils.RandomInt(1, 1000),
+		Username:  user.Username,
+		ClientID:  "client_" + utils.RandomString(24),
+		Scopes:    scopes,
+		ExpiresAt: time.Now().Add(time.Hour),
+		CreatedAt: time.Now(),
+	}
+}
+
+func TestAuthMiddlewareOAuth(t *testing.T) {
+	user, _ := randomUser(t)
+	consent := randomOAuthConsent(user, ScopeAccountsRead)
+
+	testCases := []struct {
+		name          string
+		path          string
+		consent       func() db.OAuthConsent
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "OK",
+			path:    "/scoped",
+			consent: func() db.OAuthConsent { return consent },
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Revoked",
+			path: "/scoped",
+			consent: func() db.OAuthConsent {
+				revoked := consent
+				revoked.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+				return revoked
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Expired",
+			path: "/scoped",
+			consent: func() db.OAuthConsent {
+				expired := consent
+				expired.ExpiresAt = time.Now().Add(-time.Minute)
+				return expired
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "OtherClient",
+			path: "/scoped",
+			consent: func() db.OAuthConsent {
+				other := consent
+				other.ClientID = "client_other"
+				return other
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:    "RouteWithoutScopes",
+			path:    "/unscoped",
+			consent: func() db.OAuthConsent { return consent },
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeInsufficientScope)
+			},
+		},
+		{
+			name:    "MissingScope",
+			path:    "/payments",
+			consent: func() db.OAuthConsent { return consent },
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeInsufficientScope)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectUser(store, user)
+			store.EXPECT().
+				GetOAuthConsent(gomock.Any(), gomock.Eq(consent.ID)).
+				Times(1).
+				Return(tc.consent(), nil)
+
+			server := newTestServer(t, store)
+			ok := func(ctx echo.Context) error {
+				return ctx.JSON(http.StatusOK, map[string]interface{}{})
+			}
+			server.router.GET("/scoped", ok, authMiddleware(server.tokenMaker, store, ScopeAccountsRead))
+			server.router.GET("/payments", ok, authMiddleware(server.tokenMaker, store, ScopePaymentsWrite))
+			server.router.GET("/unscoped", ok, authMiddleware(server.tokenMaker, store))
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			addOAuthAuthorization(t, request, server.tokenMaker, consent)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(context.Context, string, ratelimit.Policy) (ratelimit.Decision, error) {
+	return ratelimit.Decision{}, errors.New("limiter is down")
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	policy := ratelimit.Policy{Rate: 0.001, Burst: 2}
+
+	testCases := []struct {
+		name    string
+		limiter ratelimit.Limiter
+		check   func(t *testing.T, recorders []*httptest.ResponseRecorder, m *metrics.Metrics)
+	}{
+		{
+			name:    "LimitedPerClientIP",
+			limiter: ratelimit.NewMemoryLimiter(),
+			check: func(t *testing.T, recorders []*httptest.ResponseRecorder, m *metrics.Metrics) {
+				require.Equal(t, http.StatusOK, recorders[0].Code)
+				require.Equal(t, "1", recorders[0].Header().Get("X-RateLimit-Remaining"))
+				require.Equal(t, http.StatusOK, recorders[1].Code)
+
+				require.Equal(t, http.StatusTooManyRequests, recorders[2].Code)
+				requireProblemCode(t, recorders[2], CodeRateLimited)
+				require.Equal(t, "1000", recorders[2].Header().Get(echo.HeaderRetryAfter))
+
+				metricsRecorder := httptest.NewRecorder()
+				m.Handler().ServeHTTP(metricsRecorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+				require.Contains(t, metricsRecorder.Body.String(), `neobank_http_rate_limited_total{route="test"} 1`)
+
+				// another client has its own bucket
+				require.Equal(t, http.StatusOK, recorders[3].Code)
+			},
+		},
+		{
+			name:    "LimiterDown",
+			limiter: failingLimiter{},
+			check: func(t *testing.T, recorders []*httptest.ResponseRecorder, m *metrics.Metrics) {
+				for _, recorder := range recorders {
+					require.Equal(t, http.StatusOK, recorder.Code)
+				}
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			m := metrics.New()
+			e := echo.New()
+			e.HTTPErrorHandler = errorHandler
+			e.GET("/limited", func(ctx echo.Context) error {
+				return ctx.NoContent(http.StatusOK)
+			}, rateLimitMiddleware(tc.limiter, m, "test", policy, byClientIP))
+
+			var recorders []*httptest.ResponseRecorder
+			for _, remoteAddr := range []string{"192.0.2.1:1000", "192.0.2.1:2000", "192.0.2.1:3000", "192.0.2.2:1000"} {
+				request := httptest.NewRequest(http.MethodGet, "/limited", nil)
+				request.RemoteAddr = remoteAddr
+				recorder := httptest.NewRecorder()
+				e.ServeHTTP(recorder, request)
+				recorders = append(recorders, recorder)
+			}
+
+			tc.check(t, recorders, m)
+		})
+	}
+}
+
+func TestRateLimitForwardedFor(t *testing.T) {
+	policy := ratelimit.Policy{Rate: 0.001, Burst: 2}
+
+	testCases := []struct {
+		name           string
+		trustedProxies []string
+		requests       [][2]string
+		check          func(t *testing.T, recorders []*httptest.ResponseRecorder)
+	}{
+		{
+			name: "SpoofedHeaderIgnored",
+			requests: [][2]string{
+				{"192.0.2.1:1000", "198.51.100.1"},
+				{"192.0.2.1:1000", "198.51.100.2"},
+				{"192.0.2.1:1000", "198.51.100.3"},
+			},
+			check: func(t *testing.T, recorders []*httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorders[0].Code)
+				require.Equal(t, http.StatusOK, recorders[1].Code)
+				// a new X-Forwarded-For doesn't get a new bucket
+				require.Equal(t, http.StatusTooManyRequests, recorders[2].Code)
+			},
+		},
+		{
+			name:           "TrustedProxy",
+			trustedProxies: []string{"10.0.0.0/8"},
+			requests: [][2]string{
+				{"10.0.0.1:1000", "198.51.100.1"},
+				{"10.0.0.1:1000", "198.51.100.1"},
+				{"10.0.0.1:1000", "198.51.100.1"},
+				{"10.0.0.1:1000", "198.51.100.2"},
+				{"192.0.2.1:1000", "198.51.100.2"},
+			},
+			check: func(t *testing.T, recorders []*httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorders[1].Code)
+				require.Equal(t, http.StatusTooManyRequests, recorders[2].Code)
+				// clients behind the proxy have their own buckets
+				require.Equal(t, http.StatusOK, recorders[3].Code)
+				// but the header is only read from the proxy
+				require.Equal(t, http.StatusOK, recorders[4].Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			extractIP, err := ipExtractor(tc.trustedProxies)
+			require.NoError(t, err)
+
+			e := echo.New()
+			e.IPExtractor = extractIP
+			e.HTTPErrorHandler = errorHandler
+			e.GET("/limited", func(ctx echo.Context) error {
+				return ctx.NoContent(http.StatusOK)
+			}, rateLimitMiddleware(ratelimit.NewMemoryLimiter(), metrics.New(), "test", policy, byClientIP))
+
+			var recorders []*httptest.ResponseRecorder
+			for _, req := range tc.requests {
+				request := httptest.NewRequest(http.MethodGet, "/limited", nil)
+				request.RemoteAddr = req[0]
+				request.Header.Set(echo.HeaderXForwardedFor, req[1])
+				request.Header.Set(echo.HeaderXRealIP, req[1])
+				recorder := httptest.NewRecorder()
+				e.ServeHTTP(recorder, request)
+				recorders = append(recorders, recorder)
+			}
+
+			tc.check(t, recorders)
+		})
+	}
+}
+
+func TestIPExtractorInvalidProxy(t *testing.T) {
+	_, err := ipExtractor([]string{"not-a-cidr"})
+	require.Error(t, err)
+}
+
+func TestRequestContextMiddleware(t *testing.T) {
+	testCases := []struct {
+		name      string
+		requestID string
+		check     func(t *testing.T, responseID string, contextID string)
+	}{
+		{
+			name:      "Propagated",
+			requestID: "client-request-1",
+			check: func(t *testing.T, responseID string, contextID string) {
+				require.Equal(t, "client-request-1", responseID)
+				require.Equal(t, responseID, contextID)
+			},
+		},
+		{
+			name:      "Generated",
+			requestID: "",
+			check: func(t *testing.T, responseID string, contextID string) {
+				require.NotEmpty(t, responseID)
+				require.Equal(t, responseID, contextID)
+			},
+		},
+		{
+			name:      "TooLong",
+			requestID: utils.RandomString(maxRequestIDLength + 1),
+			check: func(t *testing.T, responseID string, contextID string) {
+				require.Len(t, responseID, 36)
+				require.Equal(t, responseID, contextID)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil)
+
+			var contextID string
+			server.router.GET("/request-id", func(ctx echo.Context) error {
+				contextID = logging.RequestID(ctx.Request().Context())
+				return ctx.NoContent(http.StatusOK)
+			})
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/request-id", nil)
+			require.NoError(t, err)
+			if tc.requestID != "" {
+				request.Header.Set(echo.HeaderXRequestID, tc.requestID)
+			}
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Code)
+			tc.check(t, recorder.Header().Get(echo.HeaderXRequestID), contextID)
+		})
+	}
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	request := httptest.NewRequest(http.MethodGet, "/accounts/42", nil)
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	request = httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	recorder = httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	body := recorder.Body.String()
+	require.Contains(t, body, `neobank_http_request_duration_seconds_count{method="GET",route="/accounts/:id",status="401"} 1`)
+	require.Contains(t, body, "go_goroutines")
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	e := echo.New()
+	e.HTTPErrorHandler = errorHandler
+	e.Use(tracingMiddleware(provider, propagation.TraceContext{}))
+
+	var handlerSpan trace.SpanContext
+	e.GET("/accounts/:id", func(ctx echo.Context) error {
+		handlerSpan = trace.SpanContextFromContext(ctx.Request().Context())
+		return errors.New("boom")
+	})
+
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	request := httptest.NewRequest(http.MethodGet, "/accounts/42", nil)
+	request.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	response := httptest.NewRecorder()
+	e.ServeHTTP(response, request)
+	require.Equal(t, http.StatusInternalServerError, response.Code)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	require.Equal(t, "GET /accounts/:id", span.Name())
+	require.Equal(t, trace.SpanKindServer, span.SpanKind())
+	require.Equal(t, traceID, span.SpanContext().TraceID().String())
+	require.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	require.True(t, span.Parent().IsRemote())
+	require.Equal(t, span.SpanContext().SpanID(), handlerSpan.SpanID())
+	require.Equal(t, codes.Error, span.Status().Code)
+	require.Contains(t, span.Attributes(), attribute.Int("http.response.status_code", http.StatusInternalServerError))
+	require.Contains(t, response.Header().Get("traceparent"), traceID)
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/labstack/echo/v4"
+)
+
+type listNotificationsRequest struct {
+	PageID   int32 `query:"page_id" validate:"required,min=1"`
+	PageSize int32 `query:"page_size" validate:"required,min=5,max=50"`
+}
+
+type notificationResponse struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload" swaggertype:"object"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+func newNotificationResponse(notification db.Notification) notificationResponse {
+	return notificationResponse{
+		ID:        notification.ID,
+		Type:      notification.Type,
+		Payload:   notification.Payload,
+		CreatedAt: notification.CreatedAt,
+	}
+}
+
+// listNotifications godoc
+// @Summary List notifications
+// @Description List the notifications of the authenticated user, newest first, e.g. that the account was locked after too many failed logins.
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param page_id query int true "Page ID for pagination"
+// @Param page_size query int true "Number of notifications per page (min: 5, max: 50)"
+// @Success 200 {array} notificationResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 429 {object} Problem "Rate Limited"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /notifications [get]
+func (server *Server) listNotifications(ctx echo.Context) error {
+	req := new(listNotificationsRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	username := authUser(ctx).Username
+
+	notifications, err := server.store.ListNotifications(ctx.Request().Context(), db.ListNotificationsParams{
+		Username: username,
+		Limit:    req.PageSize,
+		Offset:   (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	res := make([]notificationResponse, len(notifications))
+	for i, notification := range notifications {
+		res[i] = newNotificationResponse(notification)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,581 @@
+package api
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/tokens"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultOAuthConsentTTL = 90 * 24 * time.Hour
+	defaultOAuthRefreshTTL = 30 * 24 * time.Hour
+	// oauthCodeTTL is short: the client redeems the code right after the
+	// user is sent back to it.
+	oauthCodeTTL = 5 * time.Minute
+)
+
+// Grant types and errors of the token endpoint (RFC 6749 section 5.2).
+const (
+	grantTypeAuthorizationCode = "authorization_code"
+	grantTypeRefreshToken      = "refresh_token"
+
+	oauthErrInvalidRequest       = "invalid_request"
+	oauthErrInvalidClient        = "invalid_client"
+	oauthErrInvalidGrant         = "invalid_grant"
+	oauthErrUnsupportedGrantType = "unsupported_grant_type"
+)
+
+type createOAuthClientRequest struct {
+	Name         string   `json:"name" validate:"required,max=100"`
+	RedirectURIs []string `json:"redirect_uris" validate:"required,min=1,dive,url"`
+	Scopes       []string `json:"scopes" validate:"required,min=1,dive,oneof=accounts:read payments:write"`
+	// Public clients, like mobile and single page apps, can't keep a secret
+	// and rely on PKCE alone.
+	Public bool `json:"public"`
+}
+
+type oauthClientResponse struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	Scopes       []string  `json:"scopes"`
+	Public       bool      `json:"public"`
+	CreatedBy    string    `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func newOAuthClientResponse(client db.OAuthClient) oauthClientResponse {
+	return oauthClientResponse{
+		ID:           client.ID,
+		Name:         client.Name,
+		RedirectURIs: client.RedirectURIs,
+		Scopes:       client.Scopes,
+		Public:       !client.SecretHash.Valid,
+		CreatedBy:    client.CreatedBy,
+		CreatedAt:    client.CreatedAt,
+	}
+}
+
+type createOAuthClientResponse struct {
+	Client oauthClientResponse `json:"client"`
+	// ClientSecret is only returned once, and only for confidential clients.
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// createOAuthClient godoc
+// @Summary Register an OAuth client
+// @Description Register a third-party app that may ask users for access to their accounts (accounts:read) and to initiate payments (payments:write). Confidential clients get a secret that is only shown in this response; public clients don't get one. Only admins may register clients.
+// @Tags OAuth
+// @Accept json
+// @Produce json
+// @Param request body createOAuthClientRequest true "Request body for registering a client"
+// @Success 201 {object} createOAuthClientResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Admin Required"
+// @Failure 429 {object} Problem "Rate Limited"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /oauth/clients [post]
+func (server *Server) createOAuthClient(ctx echo.Context) error {
+	req := new(createOAuthClientRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	id, err := db.NewOAuthClientID()
+	if err != nil {
+		return err
+	}
+
+	args := db.CreateOAuthClientParams{
+		ID:           id,
+		Name:         req.Name,
+		RedirectURIs: req.RedirectURIs,
+		Scopes:       req.Scopes,
+		CreatedBy:    authUser(ctx).Username,
+	}
+
+	var secret string
+	if !req.Public {
+		secret, err = db.NewOAuthClientSecret()
+		if err != nil {
+			return err
+		}
+		args.SecretHash = sql.NullString{String: db.HashUserToken(secret), Valid: true}
+	}
+
+	client, err := server.store.CreateOAuthClient(ctx.Request().Context(), args)
+	if err != nil {
+		return err
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionOAuthClientCreate,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceOAuthClient,
+		ResourceID:   client.ID,
+		Metadata: map[string]interface{}{
+			"name":          client.Name,
+			"redirect_uris": client.RedirectURIs,
+			"scopes":        client.Scopes,
+		},
+	})
+
+	return ctx.JSON(http.StatusCreated, createOAuthClientResponse{
+		Client:       newOAuthClientResponse(client),
+		ClientSecret: secret,
+	})
+}
+
+// listOAuthClients godoc
+// @Summary List OAuth clients
+// @Description List the registered third-party apps. Only admins may list clients.
+// @Tags OAuth
+// @Produce json
+// @Success 200 {array} oauthClientResponse
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Admin Required"
+// @Failure 429 {object} Problem "Rate Limited"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /oauth/clients [get]
+func (server *Server) listOAuthClients(ctx echo.Context) error {
+	clients, err := server.store.ListOAuthClients(ctx.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	res := make([]oauthClientResponse, len(clients))
+	for i, client := range clients {
+		res[i] = newOAuthClientResponse(client)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// oauthAuthorizeRequest is the authorization request of RFC 6749 section
+// 4.1.1 with the PKCE challenge of RFC 7636, which every client must send.
+type oauthAuthorizeRequest struct {
+	ResponseType        string `query:"response_type" json:"response_type" validate:"required,eq=code"`
+	ClientID            string `query:"client_id" json:"client_id" validate:"required"`
+	RedirectURI         string `query:"redirect_uri" json:"redirect_uri" validate:"required"`
+	Scope               string `query:"scope" json:"scope" validate:"required"`
+	State               string `query:"state" json:"state" validate:"max=500"`
+	CodeChallenge       string `query:"code_challenge" json:"code_challenge" validate:"required,len=43"`
+	CodeChallengeMethod string `query:"code_challenge_method" json:"code_challenge_method" validate:"required,eq=S256"`
+}
+
+type oauthAuthorizeResponse struct {
+	ClientID    string   `json:"client_id"`
+	ClientName  string   `json:"client_name"`
+	RedirectURI string   `json:"redirect_uri"`
+	Scopes      []string `json:"scopes"`
+}
+
+// checkAuthorizeRequest makes sure the client asks for a redirect URI it
+// registered and for scopes it may ask for. The user isn't sent back to the
+// client when this fails: the redirect URI can't be trusted.
+func (server *Server) checkAuthorizeRequest(ctx echo.Context, req *oauthAuthorizeRequest) (db.OAuthClient, []string, error) {
+	client, err := server.store.GetOAuthClient(ctx.Request().Context(), req.ClientID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return client, nil, newProblem(http.StatusBadRequest, CodeInvalidRequest, "unknown client_id")
+		}
+		return client, nil, err
+	}
+
+	if !slices.Contains(client.RedirectURIs, req.RedirectURI) {
+		return client, nil, newProblem(http.StatusBadRequest, CodeInvalidRequest, "redirect_uri is not registered for the client")
+	}
+
+	var scopes []string
+	for _, scope := range strings.Fields(req.Scope) {
+		if !slices.Contains(client.Scopes, scope) {
+			return client, nil, newProblem(http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("the client may not ask for the %s scope", scope))
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return client, nil, newProblem(http.StatusBadRequest, CodeInvalidRequest, "scope is empty")
+	}
+
+	return client, scopes, nil
+}
+
+// getOAuthAuthorization godoc
+// @Summary Show an authorization request
+// @Description Check the authorization request a third-party app sent the user to and return what the consent screen shows: the app and the scopes it asks for. PKCE with S256 is required.
+// @Tags OAuth
+// @Produce json
+// @Param response_type query string true "Must be code"
+// @Param client_id query string true "Client ID"
+// @Param redirect_uri query string true "One of the redirect URIs of the client"
+// @Param scope query string true "Space separated scopes"
+// @Param state query string false "Opaque value sent back to the client"
+// @Param code_challenge query string true "PKCE code challenge"
+// @Param code_challenge_method query string true "Must be S256"
+// @Success 200 {object} oauthAuthorizeResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Insufficient Scope"
+// @Failure 429 {object} Problem "Rate Limited"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /oauth/authorize [get]
+func (server *Server) getOAuthAuthorization(ctx echo.Context) error {
+	req := new(oauthAuthorizeRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	client, scopes, err := server.checkAuthorizeRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, oauthAuthorizeResponse{
+		ClientID:    client.ID,
+		ClientName:  client.Name,
+		RedirectURI: req.RedirectURI,
+		Scopes:      scopes,
+	})
+}
+
+type decideOAuthAuthorizationRequest struct {
+	oauthAuthorizeRequest
+	Approve *bool `json:"approve" validate:"required"`
+}
+
+type decideOAuthAuthorizationResponse struct {
+	// RedirectTo sends the user back to the client with the authorization
+	// code, or with the access_denied error.
+	RedirectTo string `json:"redirect_to"`
+}
+
+// decideOAuthAuthorization godoc
+// @Summary Approve or deny an authorization request
+// @Description Record the decision of the user on the consent screen. Approving replaces an earlier consent for the same app and returns where to send the user with an authorization code, which the app redeems at /oauth/token within 5 minutes.
+// @Tags OAuth
+// @Accept json
+// @Produce json
+// @Param request body decideOAuthAuthorizationRequest true "The authorization request and the decision"
+// @Success 200 {object} decideOAuthAuthorizationResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Insufficient Scope"
+// @Failure 429 {object} Problem "Rate Limited"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /oauth/authorize [post]
+func (server *Server) decideOAuthAuthorization(ctx echo.Context) error {
+	req := new(decideOAuthAuthorizationRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	client, scopes, err := server.checkAuthorizeRequest(ctx, &req.oauthAuthorizeRequest)
+	if err != nil {
+		return err
+	}
+
+	redirect, err := url.Parse(req.RedirectURI)
+	if err != nil {
+		return newProblem(http.StatusBadRequest, CodeInvalidRequest, "invalid redirect_uri")
+	}
+	query := redirect.Query()
+	if req.State != "" {
+		query.Set("state", req.State)
+	}
+
+	if !*req.Approve {
+		query.Set("error", "access_denied")
+		redirect.RawQuery = query.Encode()
+		return ctx.JSON(http.StatusOK, decideOAuthAuthorizationResponse{RedirectTo: redirect.String()})
+	}
+
+	consentTTL := server.config.OAuthConsentTTL
+	if consentTTL <= 0 {
+		consentTTL = defaultOAuthConsentTTL
+	}
+
+	result, err := server.store.AuthorizeOAuthClientTx(ctx.Request().Context(), db.AuthorizeOAuthClientTxParams{
+		Username:      authUser(ctx).Username,
+		ClientID:      client.ID,
+		Scopes:        scopes,
+		RedirectURI:   req.RedirectURI,
+		CodeChallenge: req.CodeChallenge,
+		ConsentTTL:    consentTTL,
+		CodeTTL:       oauthCodeTTL,
+	})
+	if err != nil {
+		return err
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionOAuthConsentGrant,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceOAuthConsent,
+		ResourceID:   strconv.FormatInt(result.Consent.ID, 10),
+		Metadata: map[string]interface{}{
+			"client_id": client.ID,
+			"scopes":    result.Consent.Scopes,
+		},
+	})
+
+	query.Set("code", result.Code)
+	redirect.RawQuery = query.Encode()
+	return ctx.JSON(http.StatusOK, decideOAuthAuthorizationResponse{RedirectTo: redirect.String()})
+}
+
+// oauthTokenResponse is the successful response of RFC 6749 section 5.1.
+type oauthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"`
+}
+
+// oauthErrorResponse is the error response of RFC 6749 section 5.2. The
+// token endpoint answers clients, which expect it rather than a problem.
+type oauthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+func oauthError(ctx echo.Context, status int, code string, description string) error {
+	if code == oauthErrInvalidClient && status == http.StatusUnauthorized {
+		ctx.Response().Header().Set(echo.HeaderWWWAuthenticate, `Basic realm="oauth"`)
+	}
+	return ctx.JSON(status, oauthErrorResponse{Error: code, ErrorDescription: description})
+}
+
+// createOAuthToken godoc
+// @Summary Issue OAuth tokens
+// @Description Token endpoint of RFC 6749 for third-party apps. Redeem an authorization code with grant_type=authorization_code, redirect_uri and the PKCE code_verifier, or rotate a refresh token with grant_type=refresh_token. Confidential clients authenticate with HTTP Basic or client_id and client_secret in the form; public clients send their client_id. A code or refresh token used twice revokes the consent. Access tokens are limited to the consented scopes.
+// @Tags OAuth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "authorization_code or refresh_token"
+// @Param code formData string false "Authorization code"
+// @Param redirect_uri formData string false "Redirect URI of the authorization request"
+// @Param code_verifier formData string false "PKCE code verifier"
+// @Param refresh_token formData string false "Refresh token"
+// @Param client_id formData string false "Client ID, unless sent with HTTP Basic"
+// @Param client_secret formData string false "Client secret, unless sent with HTTP Basic"
+// @Success 200 {object} oauthTokenResponse
+// @Failure 400 {object} oauthErrorResponse "Invalid Request or Grant"
+// @Failure 401 {object} oauthErrorResponse "Invalid Client"
+// @Failure 429 {object} Problem "Rate Limited"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /oauth/token [post]
+func (server *Server) createOAuthToken(ctx echo.Context) error {
+	ctx.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	ctx.Response().Header().Set("Pragma", "no-cache")
+
+	clientID, clientSecret, basic := ctx.Request().BasicAuth()
+	if !basic {
+		clientID = ctx.FormValue("client_id")
+		clientSecret = ctx.FormValue("client_secret")
+	}
+	if clientID == "" {
+		return oauthError(ctx, http.StatusUnauthorized, oauthErrInvalidClient, "client authentication is missing")
+	}
+
+	client, err := server.store.GetOAuthClient(ctx.Request().Context(), clientID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return oauthError(ctx, http.StatusUnauthorized, oauthErrInvalidClient, "unknown client")
+		}
+		return err
+	}
+	if client.SecretHash.Valid &&
+		subtle.ConstantTimeCompare([]byte(db.HashUserToken(clientSecret)), []byte(client.SecretHash.String)) != 1 {
+		return oauthError(ctx, http.StatusUnauthorized, oauthErrInvalidClient, "client authentication failed")
+	}
+
+	refreshTTL := server.config.OAuthRefreshTTL
+	if refreshTTL <= 0 {
+		refreshTTL = defaultOAuthRefreshTTL
+	}
+
+	var result db.OAuthGrantTxResult
+	switch grantType := ctx.FormValue("grant_type"); grantType {
+	case grantTypeAuthorizationCode:
+		code := ctx.FormValue("code")
+		redirectURI := ctx.FormValue("redirect_uri")
+		verifier := ctx.FormValue("code_verifier")
+		if code == "" || redirectURI == "" || verifier == "" {
+			return oauthError(ctx, http.StatusBadRequest, oauthErrInvalidRequest, "code, redirect_uri and code_verifier are required")
+		}
+
+		result, err = server.store.ExchangeOAuthCodeTx(ctx.Request().Context(), db.ExchangeOAuthCodeTxParams{
+			Code:         code,
+			ClientID:     client.ID,
+			RedirectURI:  redirectURI,
+			CodeVerifier: verifier,
+			RefreshTTL:   refreshTTL,
+		})
+	case grantTypeRefreshToken:
+		refreshToken := ctx.FormValue("refresh_token")
+		if refreshToken == "" {
+			return oauthError(ctx, http.StatusBadRequest, oauthErrInvalidRequest, "refresh_token is required")
+		}
+
+		result, err = server.store.RefreshOAuthTokenTx(ctx.Request().Context(), db.RefreshOAuthTokenTxParams{
+			RefreshToken: refreshToken,
+			ClientID:     client.ID,
+			RefreshTTL:   refreshTTL,
+		})
+	case "":
+		return oauthError(ctx, http.StatusBadRequest, oauthErrInvalidRequest, "grant_type is required")
+	default:
+		return oauthError(ctx, http.StatusBadRequest, oauthErrUnsupportedGrantType, fmt.Sprintf("unsupported grant_type %s", grantType))
+	}
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidGrant) {
+			return oauthError(ctx, http.StatusBadRequest, oauthErrInvalidGrant, err.Error())
+		}
+		return err
+	}
+
+	payload, err := tokens.NewPayload(result.Consent.Username, server.config.AccessTokenDuration)
+	if err != nil {
+		return err
+	}
+	payload.ClientID = client.ID
+	payload.ConsentID = result.Consent.ID
+	payload.Scopes = result.Consent.Scopes
+
+	accessToken, err := server.tokenMaker.CreatePayloadToken(payload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, oauthTokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(server.config.AccessTokenDuration.Seconds()),
+		RefreshToken: result.RefreshToken,
+		Scope:        strings.Join(result.Consent.Scopes, " "),
+	})
+}
+
+type oauthConsentResponse struct {
+	ID         int64     `json:"id"`
+	ClientID   string    `json:"client_id"`
+	ClientName string    `json:"client_name,omitempty"`
+	Scopes     []string  `json:"scopes"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// listOAuthConsents godoc
+// @Summary List app consents
+// @Description List the third-party apps the authenticated user has given access to, with the scopes they were granted. Revoked and expired consents aren't listed.
+// @Tags OAuth
+// @Produce json
+// @Success 200 {array} oauthConsentResponse
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Insufficient Scope"
+// @Failure 429 {object} Problem "Rate Limited"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /oauth/consents [get]
+func (server *Server) listOAuthConsents(ctx echo.Context) error {
+	consents, err := server.store.ListOAuthConsents(ctx.Request().Context(), authUser(ctx).Username)
+	if err != nil {
+		return err
+	}
+
+	res := make([]oauthConsentResponse, len(consents))
+	for i, consent := range consents {
+		res[i] = oauthConsentResponse{
+			ID:         consent.ID,
+			ClientID:   consent.ClientID,
+			ClientName: consent.ClientName,
+			Scopes:     consent.Scopes,
+			ExpiresAt:  consent.ExpiresAt,
+			CreatedAt:  consent.CreatedAt,
+		}
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+type revokeOAuthConsentRequest struct {
+	ID int64 `param:"id" validate:"required,min=1"`
+}
+
+// revokeOAuthConsent godoc
+// @Summary Revoke an app consent
+// @Description Take away the access of a third-party app. Its access and refresh tokens stop working at once.
+// @Tags OAuth
+// @Produce json
+// @Param id path int true "Consent ID"
+// @Success 200 {object} oauthConsentResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Insufficient Scope"
+// @Failure 404 {object} Problem "Consent Not Found"
+// @Failure 429 {object} Problem "Rate Limited"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /oauth/consents/{id} [delete]
+func (server *Server) revokeOAuthConsent(ctx echo.Context) error {
+	req := new(revokeOAuthConsentRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	consent, err := server.store.RevokeOAuthConsent(ctx.Request().Context(), db.RevokeOAuthConsentParams{
+		ID:       req.ID,
+		Username: authUser(ctx).Username,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// someone else's consent or one that was already revoked
+			return newProblem(http.StatusNotFound, CodeOAuthConsentNotFound, fmt.Sprintf("active consent [%d] not found", req.ID))
+		}
+		return err
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionOAuthConsentRevoke,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceOAuthConsent,
+		ResourceID:   strconv.FormatInt(consent.ID, 10),
+		Metadata:     map[string]interface{}{"client_id": consent.ClientID},
+	})
+
+	return ctx.JSON(http.StatusOK, oauthConsentResponse{
+		ID:        consent.ID,
+		ClientID:  consent.ClientID,
+		Scopes:    consent.Scopes,
+		ExpiresAt: consent.ExpiresAt,
+		CreatedAt: consent.CreatedAt,
+	})
+}
@@ -0,0 +1,816 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const testRedirectURI = "https://app.example.com/callback"
+
+// randomOAuthClient returns a client and its secret, which is empty for a
+// public client.
+func randomOAuthClient(t *testing.T, admin db.User, public bool) (db.OAuthClient, string) {
+	id, err := db.NewOAuthClientID()
+	require.NoError(t, err)
+
+	client := db.OAuthClient{
+		ID:           id,
+		Name:         utils.RandomOwner(),
+		RedirectURIs: []string{testRedirectURI},
+		Scopes:       []string{ScopeAccountsRead, ScopePaymentsWrite},
+		CreatedBy:    admin.Username,
+		CreatedAt:    time.Now(),
+	}
+	if public {
+		return client, ""
+	}
+
+	secret, err := db.NewOAuthClientSecret()
+	require.NoError(t, err)
+	client.SecretHash = sql.NullString{String: db.HashUserToken(secret), Valid: true}
+	return client, secret
+}
+
+func TestCreateOAuthClientAPI(t *testing.T) {
+	admin := randomAdmin(t)
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		body          map[string]interface{}
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Confidential",
+			user: admin,
+			body: map[string]interface{}{
+				"name":          "Budget App",
+				"redirect_uris": []string{testRedirectURI},
+				"scopes":        []string{ScopeAccountsRead},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateOAuthClient(gomock.Any(), gomock.Cond(func(x any) bool {
+						arg, ok := x.(db.CreateOAuthClientParams)
+						return ok &&
+							strings.HasPrefix(arg.ID, "client_") &&
+							arg.Name == "Budget App" &&
+							arg.SecretHash.Valid &&
+							arg.CreatedBy == admin.Username
+					})).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateOAuthClientParams) (db.OAuthClient, error) {
+						return db.OAuthClient{
+							ID:           arg.ID,
+							Name:         arg.Name,
+							SecretHash:   arg.SecretHash,
+							RedirectURIs: arg.RedirectURIs,
+							Scopes:       arg.Scopes,
+							CreatedBy:    arg.CreatedBy,
+							CreatedAt:    time.Now(),
+						}, nil
+					})
+				expectAuditEvent(store, audit.ActionOAuthClientCreate, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				require.NotContains(t, recorder.Body.String(), "secret_hash")
+
+				var got createOAuthClientResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.NotEmpty(t, got.ClientSecret)
+				require.False(t, got.Client.Public)
+				require.Equal(t, []string{testRedirectURI}, got.Client.RedirectURIs)
+			},
+		},
+		{
+			name: "Public",
+			user: admin,
+			body: map[string]interface{}{
+				"name":          "Mobile App",
+				"redirect_uris": []string{"https://mobile.example.com/cb"},
+				"scopes":        []string{ScopeAccountsRead, ScopePaymentsWrite},
+				"public":        true,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateOAuthClient(gomock.Any(), gomock.Cond(func(x any) bool {
+						arg, ok := x.(db.CreateOAuthClientParams)
+						return ok && !arg.SecretHash.Valid
+					})).
+					Times(1).
+					Return(db.OAuthClient{ID: "client_1", Name: "Mobile App"}, nil)
+				expectAuditEvent(store, audit.ActionOAuthClientCreate, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var got createOAuthClientResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Empty(t, got.ClientSecret)
+				require.True(t, got.Client.Public)
+			},
+		},
+		{
+			name: "ScopeNotOffered",
+			user: admin,
+			body: map[string]interface{}{
+				"name":          "Greedy App",
+				"redirect_uris": []string{testRedirectURI},
+				"scopes":        []string{ScopeAccountsWrite},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateOAuthClient(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name: "InvalidRedirectURI",
+			user: admin,
+			body: map[string]interface{}{
+				"name":          "Budget App",
+				"redirect_uris": []string{"not a url"},
+				"scopes":        []string{ScopeAccountsRead},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateOAuthClient(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotAdmin",
+			user: user,
+			body: map[string]interface{}{
+				"name":          "Budget App",
+				"redirect_uris": []string{testRedirectURI},
+				"scopes":        []string{ScopeAccountsRead},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateOAuthClient(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeAdminRequired)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectUser(store, tc.user)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/oauth/clients", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// authorizeQuery is a valid authorization request of client.
+func authorizeQuery(client db.OAuthClient, verifier string) url.Values {
+	return url.Values{
+		"response_type":         {"code"},
+		"client_id":             {client.ID},
+		"redirect_uri":          {testRedirectURI},
+		"scope":                 {ScopeAccountsRead},
+		"state":                 {"xyz"},
+		"code_challenge":        {db.OAuthCodeChallenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+}
+
+func TestGetOAuthAuthorizationAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	client, _ := randomOAuthClient(t, randomAdmin(t), false)
+	valid := authorizeQuery(client, utils.RandomString(43))
+
+	with := func(key, value string) url.Values {
+		query := url.Values{}
+		for k, v := range valid {
+			query[k] = v
+		}
+		query.Set(key, value)
+		return query
+	}
+
+	testCases := []struct {
+		name          string
+		query         url.Values
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: with("scope", "accounts:read payments:write accounts:read"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), gomock.Eq(client.ID)).Times(1).Return(client, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got oauthAuthorizeResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, client.Name, got.ClientName)
+				require.Equal(t, []string{ScopeAccountsRead, ScopePaymentsWrite}, got.Scopes)
+			},
+		},
+		{
+			name:  "UnknownClient",
+			query: valid,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), gomock.Any()).Times(1).Return(db.OAuthClient{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeInvalidRequest)
+			},
+		},
+		{
+			name:  "UnregisteredRedirectURI",
+			query: with("redirect_uri", "https://evil.example.com/callback"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), gomock.Any()).Times(1).Return(client, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeInvalidRequest)
+			},
+		},
+		{
+			name:  "ScopeNotAllowed",
+			query: with("scope", ScopeAccountsWrite),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), gomock.Any()).Times(1).Return(client, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeInvalidRequest)
+			},
+		},
+		{
+			name:  "PlainChallenge",
+			query: with("code_challenge_method", "plain"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name:  "NoChallenge",
+			query: with("code_challenge", ""),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectUser(store, user)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/oauth/authorize?"+tc.query.Encode(), nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDecideOAuthAuthorizationAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	client, _ := randomOAuthClient(t, randomAdmin(t), false)
+	verifier := utils.RandomString(43)
+	consent := randomOAuthConsent(user, ScopeAccountsRead)
+	consent.ClientID = client.ID
+
+	body := func(approve bool) map[string]interface{} {
+		body := map[string]interface{}{"approve": approve}
+		for key, value := range authorizeQuery(client, verifier) {
+			body[key] = value[0]
+		}
+		return body
+	}
+
+	testCases := []struct {
+		name          string
+		body          map[string]interface{}
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Approve",
+			body: body(true),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), gomock.Eq(client.ID)).Times(1).Return(client, nil)
+				store.EXPECT().
+					AuthorizeOAuthClientTx(gomock.Any(), gomock.Eq(db.AuthorizeOAuthClientTxParams{
+						Username:      user.Username,
+						ClientID:      client.ID,
+						Scopes:        []string{ScopeAccountsRead},
+						RedirectURI:   testRedirectURI,
+						CodeChallenge: db.OAuthCodeChallenge(verifier),
+						ConsentTTL:    defaultOAuthConsentTTL,
+						CodeTTL:       oauthCodeTTL,
+					})).
+					Times(1).
+					Return(db.AuthorizeOAuthClientTxResult{Consent: consent, Code: "the-code"}, nil)
+				expectAuditEvent(store, audit.ActionOAuthConsentGrant, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got decideOAuthAuthorizationResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				redirect, err := url.Parse(got.RedirectTo)
+				require.NoError(t, err)
+				require.Equal(t, "app.example.com", redirect.Host)
+				require.Equal(t, "the-code", redirect.Query().Get("code"))
+				require.Equal(t, "xyz", redirect.Query().Get("state"))
+			},
+		},
+		{
+			name: "Deny",
+			body: body(false),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), gomock.Eq(client.ID)).Times(1).Return(client, nil)
+				store.EXPECT().AuthorizeOAuthClientTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got decideOAuthAuthorizationResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				redirect, err := url.Parse(got.RedirectTo)
+				require.NoError(t, err)
+				require.Equal(t, "access_denied", redirect.Query().Get("error"))
+				require.Equal(t, "xyz", redirect.Query().Get("state"))
+				require.Empty(t, redirect.Query().Get("code"))
+			},
+		},
+		{
+			name: "NoDecision",
+			body: func() map[string]interface{} {
+				body := body(true)
+				delete(body, "approve")
+				return body
+			}(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectUser(store, user)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/oauth/authorize", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestOAuthAuthorizeWithAppToken(t *testing.T) {
+	user, _ := randomUser(t)
+	consent := randomOAuthConsent(user, ScopeAccountsRead, ScopePaymentsWrite)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	expectUser(store, user)
+	store.EXPECT().GetOAuthConsent(gomock.Any(), gomock.Eq(consent.ID)).Times(1).Return(consent, nil)
+	store.EXPECT().GetOAuthClient(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/oauth/consents", nil)
+	require.NoError(t, err)
+
+	addOAuthAuthorization(t, request, server.tokenMaker, consent)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+	requireProblemCode(t, recorder, CodeInsufficientScope)
+}
+
+func TestCreateOAuthTokenAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	admin := randomAdmin(t)
+	client, secret := randomOAuthClient(t, admin, false)
+	publicClient, _ := randomOAuthClient(t, admin, true)
+	consent := randomOAuthConsent(user, ScopeAccountsRead, ScopePaymentsWrite)
+	consent.ClientID = client.ID
+
+	codeForm := func(client db.OAuthClient, secret string) url.Values {
+		form := url.Values{
+			"grant_type":    {grantTypeAuthorizationCode},
+			"code":          {"the-code"},
+			"redirect_uri":  {testRedirectURI},
+			"code_verifier": {"the-verifier"},
+			"client_id":     {client.ID},
+		}
+		if secret != "" {
+			form.Set("client_secret", secret)
+		}
+		return form
+	}
+
+	testCases := []struct {
+		name          string
+		form          url.Values
+		basicAuth     bool
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "AuthorizationCode",
+			form: codeForm(client, secret),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), gomock.Eq(client.ID)).Times(1).Return(client, nil)
+				store.EXPECT().
+					ExchangeOAuthCodeTx(gomock.Any(), gomock.Eq(db.ExchangeOAuthCodeTxParams{
+						Code:         "the-code",
+						ClientID:     client.ID,
+						RedirectURI:  testRedirectURI,
+						CodeVerifier: "the-verifier",
+						RefreshTTL:   defaultOAuthRefreshTTL,
+					})).
+					Times(1).
+					Return(db.OAuthGrantTxResult{Consent: consent, RefreshToken: "the-refresh-token"}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "no-store", recorder.Header().Get(echo.HeaderCacheControl))
+
+				var got oauthTokenResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, "Bearer", got.TokenType)
+				require.Equal(t, "the-refresh-token", got.RefreshToken)
+				require.Equal(t, "accounts:read payments:write", got.Scope)
+				require.EqualValues(t, 60, got.ExpiresIn)
+				require.NotEmpty(t, got.AccessToken)
+			},
+		},
+		{
+			name:      "BasicAuth",
+			form:      codeForm(db.OAuthClient{}, ""),
+			basicAuth: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), gomock.Eq(client.ID)).Times(1).Return(client, nil)
+				store.EXPECT().
+					ExchangeOAuthCodeTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OAuthGrantTxResult{Consent: consent, RefreshToken: "the-refresh-token"}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "PublicClient",
+			form: codeForm(publicClient, ""),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), gomock.Eq(publicClient.ID)).Times(1).Return(publicClient, nil)
+				store.EXPECT().
+					ExchangeOAuthCodeTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OAuthGrantTxResult{Consent: consent, RefreshToken: "the-refresh-token"}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "RefreshToken",
+			form: url.Values{
+				"grant_type":    {grantTypeRefreshToken},
+				"refresh_token": {"the-refresh-token"},
+				"client_id":     {client.ID},
+				"client_secret": {secret},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), gomock.Eq(client.ID)).Times(1).Return(client, nil)
+				store.EXPECT().
+					RefreshOAuthTokenTx(gomock.Any(), gomock.Eq(db.RefreshOAuthTokenTxParams{
+						RefreshToken: "the-refresh-token",
+						ClientID:     client.ID,
+						RefreshTTL:   defaultOAuthRefreshTTL,
+					})).
+					Times(1).
+					Return(db.OAuthGrantTxResult{Consent: consent, RefreshToken: "the-next-token"}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got oauthTokenResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, "the-next-token", got.RefreshToken)
+			},
+		},
+		{
+			name: "WrongSecret",
+			form: codeForm(client, "wrong"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), gomock.Any()).Times(1).Return(client, nil)
+				store.EXPECT().ExchangeOAuthCodeTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireOAuthError(t, recorder, http.StatusUnauthorized, oauthErrInvalidClient)
+			},
+		},
+		{
+			name: "MissingSecret",
+			form: codeForm(client, ""),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), gomock.Any()).Times(1).Return(client, nil)
+				store.EXPECT().ExchangeOAuthCodeTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireOAuthError(t, recorder, http.StatusUnauthorized, oauthErrInvalidClient)
+			},
+		},
+		{
+			name: "UnknownClient",
+			form: codeForm(client, secret),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), gomock.Any()).Times(1).Return(db.OAuthClient{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireOAuthError(t, recorder, http.StatusUnauthorized, oauthErrInvalidClient)
+			},
+		},
+		{
+			name: "InvalidGrant",
+			form: codeForm(client, secret),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), gomock.Any()).Times(1).Return(client, nil)
+				store.EXPECT().
+					ExchangeOAuthCodeTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OAuthGrantTxResult{}, db.ErrInvalidGrant)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, oauthErrInvalidGrant)
+			},
+		},
+		{
+			name: "MissingVerifier",
+			form: func() url.Values {
+				form := codeForm(client, secret)
+				form.Del("code_verifier")
+				return form
+			}(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), gomock.Any()).Times(1).Return(client, nil)
+				store.EXPECT().ExchangeOAuthCodeTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, oauthErrInvalidRequest)
+			},
+		},
+		{
+			name: "UnsupportedGrantType",
+			form: url.Values{
+				"grant_type":    {"password"},
+				"client_id":     {client.ID},
+				"client_secret": {secret},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), gomock.Any()).Times(1).Return(client, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, oauthErrUnsupportedGrantType)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(tc.form.Encode()))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tc.basicAuth {
+				request.SetBasicAuth(client.ID, secret)
+			}
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func requireOAuthError(t *testing.T, recorder *httptest.ResponseRecorder, status int, code string) {
+	require.Equal(t, status, recorder.Code)
+
+	var got oauthErrorResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+	require.Equal(t, code, got.Error)
+}
+
+func TestListOAuthConsentsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	consent := randomOAuthConsent(user, ScopeAccountsRead)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	expectUser(store, user)
+	store.EXPECT().
+		ListOAuthConsents(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return([]db.ListOAuthConsentsRow{{
+			ID:         consent.ID,
+			Username:   consent.Username,
+			ClientID:   consent.ClientID,
+			Scopes:     consent.Scopes,
+			ExpiresAt:  consent.ExpiresAt,
+			CreatedAt:  consent.CreatedAt,
+			ClientName: "Budget App",
+		}}, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/oauth/consents", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var got []oauthConsentResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+	require.Len(t, got, 1)
+	require.Equal(t, "Budget App", got[0].ClientName)
+	require.Equal(t, consent.Scopes, got[0].Scopes)
+}
+
+func TestRevokeOAuthConsentAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	consent := randomOAuthConsent(user, ScopeAccountsRead)
+
+	testCases := []struct {
+		name          string
+		id            int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			id:   consent.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				revoked := consent
+				revoked.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+				store.EXPECT().
+					RevokeOAuthConsent(gomock.Any(), gomock.Eq(db.RevokeOAuthConsentParams{ID: consent.ID, Username: user.Username})).
+					Times(1).
+					Return(revoked, nil)
+				expectAuditEvent(store, audit.ActionOAuthConsentRevoke, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			id:   consent.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RevokeOAuthConsent(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OAuthConsent{}, sql.ErrNoRows)
+				store.EXPECT().CreateAuditEvent(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, CodeOAuthConsentNotFound)
+			},
+		},
+		{
+			name: "InvalidID",
+			id:   0,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RevokeOAuthConsent(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectUser(store, user)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/oauth/consents/%d", tc.id), nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
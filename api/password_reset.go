@@ -0,0 +1,135 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/danielmoisa/neobank/audit"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/mail"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type forgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// forgotPassword godoc
+// @Summary Request a password reset
+// @Description Email a password reset link to the user with this address. The response is the same whether or not such a user exists.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param request body forgotPasswordRequest true "Request body for requesting a password reset"
+// @Success 202
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 429 {object} Problem "Rate Limited"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /users/password/forgot [post]
+func (server *Server) forgotPassword(ctx echo.Context) error {
+	req := new(forgotPasswordRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	user, err := server.store.GetUserByEmail(ctx.Request().Context(), req.Email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ctx.NoContent(http.StatusAccepted)
+		}
+		return err
+	}
+	if user.Disabled {
+		return ctx.NoContent(http.StatusAccepted)
+	}
+
+	ttl := server.config.PasswordResetTTL
+	if ttl <= 0 {
+		ttl = defaultPasswordResetTTL
+	}
+
+	result, err := server.store.IssueUserTokenTx(ctx.Request().Context(), db.IssueUserTokenParams{
+		Username: user.Username,
+		Purpose:  db.TokenPasswordReset,
+		Email:    user.Email,
+		TTL:      ttl,
+	})
+	if err != nil {
+		return err
+	}
+
+	link := mail.Link(server.config.AppBaseURL, "/reset-password", result.Token)
+	if err := server.mailer.Send(ctx.Request().Context(), mail.PasswordResetEmail(user.Email, link)); err != nil {
+		return err
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Actor:        user.Username,
+		Action:       audit.ActionUserPasswordResetRequest,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceUser,
+		ResourceID:   user.Username,
+	})
+
+	return ctx.NoContent(http.StatusAccepted)
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=6"`
+}
+
+// resetPassword godoc
+// @Summary Reset a password
+// @Description Set a new password with the token from the password reset email. Each token works once. Access tokens issued before, OAuth consents and API keys are revoked and a lockout ends.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param request body resetPasswordRequest true "Request body for resetting a password"
+// @Success 204
+// @Failure 400 {object} Problem "Bad Request or Invalid Token"
+// @Failure 429 {object} Problem "Rate Limited"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /users/password/reset [post]
+func (server *Server) resetPassword(ctx echo.Context) error {
+	req := new(resetPasswordRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	hash, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return err
+	}
+
+	user, err := server.store.ResetPasswordTx(ctx.Request().Context(), db.ResetPasswordTxParams{
+		Token:          req.Token,
+		HashedPassword: hash,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidUserToken) {
+			return newProblem(http.StatusBadRequest, CodeInvalidToken, err.Error())
+		}
+		return err
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Actor:        user.Username,
+		Action:       audit.ActionUserPasswordReset,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceUser,
+		ResourceID:   user.Username,
+	})
+
+	return ctx.NoContent(http.StatusNoContent)
+}
@@ -5,92 +5,159 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 
+	"github.com/danielmoisa/neobank/audit"
 	db "github.com/danielmoisa/neobank/db/sqlc"
-	"github.com/danielmoisa/neobank/tokens"
+	"github.com/danielmoisa/neobank/fraud"
 	"github.com/labstack/echo/v4"
 )
 
 type paymentRequest struct {
-	FromAccountID int64  `json:"from_account_id" validation:"required,min=1"`
-	ToAccountID   int64  `json:"to_account_id" validation:"required,min=1"`
-	Amount        int64  `json:"amount" validation:"required,gt=0"`
-	Currency      string `json:"currency" validation:"required,oneof=USD EUR CAD"`
+	FromAccountID int64  `json:"from_account_id" validate:"required,min=1"`
+	ToAccountID   int64  `json:"to_account_id" validate:"required,min=1"`
+	Amount        int64  `json:"amount" validate:"required,gt=0"`
+	Currency      string `json:"currency" validate:"required,oneof=USD EUR CAD"`
+	// Password confirms a payment the fraud rules challenged.
+	Password string `json:"password,omitempty"`
 }
 
 // createPayment godoc
 // @Summary Create a payment
-// @Description Transfer funds between two accounts.
+// @Description Transfer funds between two accounts. The payer must own the account paid from, or be a co-owner or a payer of it; payers may only send up to their payment limit at a time. Users who haven't verified their email or their identity can only send small amounts. Payments between users with an unreviewed sanctions screening hit are held. The fraud rules may ask for the password of the payer (403 fraud_challenge), to be sent with the payment again, or hold the payment for review (202). Pockets don't take part in payments, but a payment from an account with a round-up pocket sweeps its spare change into the pocket.
 // @Tags Payments
 // @Accept json
 // @Produce json
 // @Param request body paymentRequest true "Request body for creating a payment"
 // @Success 201 {object} db.Payment
-// @Failure 400 {object} ErrorResponse "Bad Request"
-// @Failure 404 {object} ErrorResponse "Account Not Found"
-// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Success 202 {object} heldPaymentResponse "Held for review"
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Account Not Owned"
+// @Failure 403 {object} Problem "Email Not Verified, KYC Required, Screening Hold, Fraud Challenge, Account Action Forbidden or Payment Limit Exceeded"
+// @Failure 404 {object} Problem "Account Not Found"
+// @Failure 422 {object} Problem "Account Frozen or Pocket Account"
+// @Failure 429 {object} Problem "Rate Limited"
+// @Failure 500 {object} Problem "Internal Server Error"
 // @Router /payments [post]
 func (server *Server) createPayment(ctx echo.Context) error {
 	req := new(paymentRequest)
 
 	if err := ctx.Bind(req); err != nil {
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return err
 	}
 
 	if err := ctx.Validate(req); err != nil {
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return err
 	}
 
-	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
+	fromAccount, err := server.validAccount(ctx, req.FromAccountID, req.Currency)
+	if err != nil {
+		return err
+	}
+
+	access, err := server.authorizeAccount(ctx, fromAccount, db.AccountActionPay)
+	if err != nil {
+		return err
+	}
+	err = access.CheckPayment(req.Amount)
+	if err == nil {
+		err = db.CheckPayerAllowance(ctx.Request().Context(), server.store, access, fromAccount, authUser(ctx).Username, req.Amount)
+	}
+	switch {
+	case errors.Is(err, db.ErrPaymentAmountInvalid):
+		return newProblem(http.StatusBadRequest, CodeValidationFailed, "the amount must be positive")
+	case errors.Is(err, db.ErrPaymentLimitExceeded):
+		detail := fmt.Sprintf("payers of account [%d] may send up to %d a day", fromAccount.ID, access.PaymentLimit)
+		return newProblem(http.StatusForbidden, CodePaymentLimitExceeded, detail)
+	case err != nil:
+		return err
+	}
 
-	if !valid {
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid account"})
+	if limit := server.config.UnverifiedPaymentLimit(); authUser(ctx).EmailVerifiedAt.IsZero() && req.Amount > limit {
+		detail := fmt.Sprintf("verify your email address to send more than %d", limit)
+		return newProblem(http.StatusForbidden, CodeEmailNotVerified, detail)
 	}
 
-	authPayload := ctx.Get(authorizationPayloadKey).(*tokens.Payload)
-	if fromAccount.Owner != authPayload.Username {
-		err := errors.New("account doesn't belongs to auth user")
-		return ctx.JSON(http.StatusUnauthorized, ErrorResponse{Error: err.Error()})
+	if err := db.CheckKYCPaymentLimit(ctx.Request().Context(), server.store, authUser(ctx), req.Currency, req.Amount); err != nil {
+		return kycProblem(err)
 	}
 
-	_, valid = server.validAccount(ctx, req.ToAccountID, req.Currency)
+	toAccount, err := server.validAccount(ctx, req.ToAccountID, req.Currency)
+	if err != nil {
+		return err
+	}
 
-	if !valid {
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid account"})
+	if err := server.screenPayment(ctx, authUser(ctx), toAccount.Owner); err != nil {
+		return err
+	}
+
+	decision, err := server.assessPayment(ctx, fraud.Payment{
+		User:        authUser(ctx),
+		FromAccount: fromAccount,
+		ToAccount:   toAccount,
+		Amount:      req.Amount,
+	}, req.Password)
+	if err != nil {
+		return err
+	}
+	if decision.Status == db.FraudPending {
+		return ctx.JSON(http.StatusAccepted, heldPaymentResponse{FraudDecisionID: decision.ID, Status: decision.Status})
 	}
 
 	payment, err := server.store.PaymentTx(ctx.Request().Context(), db.PaymentTxParams{
 		FromAccountID: req.FromAccountID,
 		ToAccountID:   req.ToAccountID,
 		Amount:        req.Amount,
+		InitiatedBy:   authUser(ctx).Username,
 	})
-
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
-
+		return err
 	}
 
+	server.linkPayment(ctx, decision, payment.Payment)
+	server.metrics.ObservePayment(req.Currency, req.Amount)
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionPaymentCreate,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourcePayment,
+		ResourceID:   strconv.FormatInt(payment.Payment.ID, 10),
+		Metadata: map[string]interface{}{
+			"from_account_id": req.FromAccountID,
+			"to_account_id":   req.ToAccountID,
+			"amount":          req.Amount,
+			"currency":        req.Currency,
+		},
+	})
+
+	server.publishPayment(ctx, payment)
+
 	return ctx.JSON(http.StatusCreated, payment)
 
 }
 
-func (server *Server) validAccount(ctx echo.Context, accountID int64, currency string) (db.Account, bool) {
+func (server *Server) validAccount(ctx echo.Context, accountID int64, currency string) (db.Account, error) {
 	account, err := server.store.GetAccount(ctx.Request().Context(), accountID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
-			return account, false
+		if errors.Is(err, sql.ErrNoRows) {
+			return account, newProblem(http.StatusNotFound, CodeAccountNotFound, fmt.Sprintf("account [%d] not found", accountID))
 		}
-
-		ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
-		return account, false
+		return account, err
 	}
 
 	if account.Currency != currency {
-		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", account.ID, account.Currency, currency)
-		ctx.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
-		return account, false
+		detail := fmt.Sprintf("account [%d] currency mismatch: %s vs %s", account.ID, account.Currency, currency)
+		return account, newProblem(http.StatusBadRequest, CodeCurrencyMismatch, detail)
+	}
+
+	if account.Frozen {
+		return account, newProblem(http.StatusUnprocessableEntity, CodeAccountFrozen, fmt.Sprintf("account [%d] is frozen", account.ID))
+	}
+
+	if account.Type == db.AccountPocket {
+		detail := fmt.Sprintf("account [%d] is a pocket, move money in and out of it with POST /pockets/moves", account.ID)
+		return account, newProblem(http.StatusUnprocessableEntity, CodePocketAccount, detail)
 	}
 
-	return account, true
+	return account, nil
 }
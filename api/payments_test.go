@@ -0,0 +1,84 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreatePaymentValidationAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	testCases := []struct {
+		name  string
+		body  echo.Map
+		rules map[string]string
+	}{
+		{
+			name: "ZeroAmount",
+			body: echo.Map{"from_account_id": account.ID, "to_account_id": account.ID + 1, "amount": 0, "currency": "USD"},
+			// 0 is the zero value of the amount, so it counts as missing
+			rules: map[string]string{"amount": "required"},
+		},
+		{
+			name:  "NegativeAmount",
+			body:  echo.Map{"from_account_id": account.ID, "to_account_id": account.ID + 1, "amount": -500, "currency": "USD"},
+			rules: map[string]string{"amount": "gt"},
+		},
+		{
+			name:  "InvalidCurrency",
+			body:  echo.Map{"from_account_id": account.ID, "to_account_id": account.ID + 1, "amount": 500, "currency": "XYZ"},
+			rules: map[string]string{"currency": "oneof"},
+		},
+		{
+			name: "MissingAccounts",
+			body: echo.Map{"amount": 500, "currency": "USD"},
+			rules: map[string]string{
+				"from_account_id": "required",
+				"to_account_id":   "required",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			expectUser(store, user)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			store.EXPECT().PaymentTx(gomock.Any(), gomock.Any()).Times(0)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/payments", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusBadRequest, recorder.Code)
+			requireProblemCode(t, recorder, CodeValidationFailed)
+
+			var problem Problem
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+			rules := make(map[string]string)
+			for _, fieldErr := range problem.Errors {
+				rules[fieldErr.Field] = fieldErr.Rule
+			}
+			require.Equal(t, tc.rules, rules)
+		})
+	}
+}
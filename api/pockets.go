@@ -0,0 +1,543 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/events"
+	"github.com/labstack/echo/v4"
+)
+
+type pocketResponse struct {
+	// AccountID is the pocket's own account, ParentID the account it is set
+	// aside in.
+	AccountID int64  `json:"account_id"`
+	ParentID  int64  `json:"parent_id"`
+	Name      string `json:"name"`
+	Balance   int64  `json:"balance"`
+	Currency  string `json:"currency"`
+	// GoalAmount is 0 for pockets without a goal. GoalProgress is the
+	// balance as a percentage of the goal, up to 100.
+	GoalAmount   int64 `json:"goal_amount"`
+	GoalProgress int64 `json:"goal_progress"`
+	// TargetDate is when the goal should be reached, as YYYY-MM-DD.
+	TargetDate *string `json:"target_date,omitempty"`
+	// RoundUpTo is what payments out of the parent account are rounded up
+	// to, sweeping the spare change into the pocket; 0 when it doesn't.
+	RoundUpTo int64     `json:"round_up_to"`
+	Frozen    bool      `json:"frozen"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newPocketResponse(pocket db.Pocket, account db.Account) pocketResponse {
+	res := pocketResponse{
+		AccountID:  pocket.AccountID,
+		ParentID:   pocket.ParentID,
+		Name:       pocket.Name,
+		Balance:    account.Balance,
+		Currency:   account.Currency,
+		GoalAmount: pocket.GoalAmount,
+		RoundUpTo:  pocket.RoundUpTo,
+		Frozen:     account.Frozen,
+		CreatedAt:  pocket.CreatedAt,
+	}
+	if pocket.GoalAmount > 0 {
+		res.GoalProgress = min(100, max(0, account.Balance)*100/pocket.GoalAmount)
+	}
+	if pocket.TargetDate.Valid {
+		date := pocket.TargetDate.Time.Format(dateFormat)
+		res.TargetDate = &date
+	}
+	return res
+}
+
+type createPocketRequest struct {
+	Name       string `json:"name" validate:"required,max=50"`
+	GoalAmount int64  `json:"goal_amount" validate:"min=0"`
+	TargetDate string `json:"target_date" validate:"omitempty,datetime=2006-01-02"`
+	RoundUpTo  int64  `json:"round_up_to" validate:"min=0,max=100000"`
+}
+
+// createPocket godoc
+// @Summary Create a pocket
+// @Description Set money aside in a pocket inside a checking account of the authenticated user. A pocket is an account of its own with the owner and currency of its parent, optionally with a goal amount and a target date. With round_up_to set, every payment out of the parent is rounded up to the next multiple of it and the spare change swept into the pocket; only one pocket of an account rounds up.
+// @Tags Pockets
+// @Accept json
+// @Produce json
+// @Param id path int true "Parent account ID"
+// @Param request body createPocketRequest true "Pocket"
+// @Success 201 {object} pocketResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Insufficient Scope or Account Action Forbidden"
+// @Failure 404 {object} Problem "Account Not Found"
+// @Failure 409 {object} Problem "Pocket Exists or Round-Up Pocket Exists"
+// @Failure 422 {object} Problem "Pocket Parent Invalid"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /accounts/{id}/pockets [post]
+func (server *Server) createPocket(ctx echo.Context) error {
+	parent, err := server.authorizedAccount(ctx, db.AccountActionManage)
+	if err != nil {
+		return err
+	}
+
+	req := new(createPocketRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	targetDate, err := pocketTargetDate(req.TargetDate)
+	if err != nil {
+		return err
+	}
+
+	if parent.Type != db.AccountChecking {
+		return newProblem(http.StatusUnprocessableEntity, CodePocketParentInvalid, "pockets are only set aside in checking accounts")
+	}
+
+	if req.RoundUpTo > 0 {
+		if err := server.checkRoundUpPocket(ctx, parent.ID, 0); err != nil {
+			return err
+		}
+	}
+
+	result, err := server.store.CreatePocketTx(ctx.Request().Context(), db.CreatePocketTxParams{
+		Owner:      parent.Owner,
+		Currency:   parent.Currency,
+		ParentID:   parent.ID,
+		Name:       req.Name,
+		GoalAmount: req.GoalAmount,
+		TargetDate: targetDate,
+		RoundUpTo:  req.RoundUpTo,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return newProblem(http.StatusConflict, CodePocketExists, fmt.Sprintf("account [%d] already has a pocket named %q", parent.ID, req.Name))
+		}
+		return err
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionPocketCreate,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourcePocket,
+		ResourceID:   strconv.FormatInt(result.Pocket.AccountID, 10),
+		Metadata: map[string]interface{}{
+			"parent_id":   parent.ID,
+			"goal_amount": result.Pocket.GoalAmount,
+			"round_up_to": result.Pocket.RoundUpTo,
+		},
+	})
+
+	return ctx.JSON(http.StatusCreated, newPocketResponse(result.Pocket, result.Account))
+}
+
+// pocketTargetDate parses a target date, which must not be in the past. An
+// empty date is no target date.
+func pocketTargetDate(date string) (sql.NullTime, error) {
+	if date == "" {
+		return sql.NullTime{}, nil
+	}
+	targetDate, _ := time.Parse(time.DateOnly, date)
+	if targetDate.Before(today()) {
+		return sql.NullTime{}, newProblem(http.StatusBadRequest, CodeValidationFailed, "the target date must not be in the past")
+	}
+	return sql.NullTime{Time: targetDate, Valid: true}, nil
+}
+
+// checkRoundUpPocket makes sure no other pocket of the parent account rounds
+// up payments already; except is the pocket being changed, if any.
+func (server *Server) checkRoundUpPocket(ctx echo.Context, parentID int64, except int64) error {
+	pocket, err := server.store.GetRoundUpPocket(ctx.Request().Context(), parentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if pocket.AccountID == except {
+		return nil
+	}
+	detail := fmt.Sprintf("pocket %q of account [%d] already rounds up payments", pocket.Name, parentID)
+	return newProblem(http.StatusConflict, CodeRoundUpPocketExists, detail)
+}
+
+// listPockets godoc
+// @Summary List pockets
+// @Description Get the pockets set aside in an account of the authenticated user, oldest first, with their balances and goal progress.
+// @Tags Pockets
+// @Produce json
+// @Param id path int true "Parent account ID"
+// @Success 200 {array} pocketResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Insufficient Scope"
+// @Failure 404 {object} Problem "Account Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /accounts/{id}/pockets [get]
+func (server *Server) listPockets(ctx echo.Context) error {
+	parent, err := server.authorizedAccount(ctx, db.AccountActionView)
+	if err != nil {
+		return err
+	}
+
+	pockets, err := server.store.ListPockets(ctx.Request().Context(), parent.ID)
+	if err != nil {
+		return err
+	}
+
+	res := make([]pocketResponse, len(pockets))
+	for i, pocket := range pockets {
+		account, err := server.store.GetAccount(ctx.Request().Context(), pocket.AccountID)
+		if err != nil {
+			return err
+		}
+		res[i] = newPocketResponse(pocket, account)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// ownedPocket loads the pocket of the id path parameter, with its account,
+// if the authenticated user's role on it allows action.
+func (server *Server) ownedPocket(ctx echo.Context, action string) (db.Pocket, db.Account, error) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil || id < 1 {
+		return db.Pocket{}, db.Account{}, newProblem(http.StatusBadRequest, CodeInvalidRequest, "invalid pocket id")
+	}
+
+	notFound := newProblem(http.StatusNotFound, CodePocketNotFound, fmt.Sprintf("pocket [%d] not found", id))
+	pocket, err := server.store.GetPocket(ctx.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return pocket, db.Account{}, notFound
+		}
+		return pocket, db.Account{}, err
+	}
+
+	account, err := server.store.GetAccount(ctx.Request().Context(), pocket.AccountID)
+	if err != nil {
+		return pocket, account, err
+	}
+	access, err := db.AuthorizeAccount(ctx.Request().Context(), server.store, account, authUser(ctx).Username, action)
+	if errors.Is(err, db.ErrNotAccountMember) {
+		return pocket, account, notFound
+	}
+	if err != nil {
+		return pocket, account, accountAccessError(account, access, err)
+	}
+	return pocket, account, nil
+}
+
+// getPocket godoc
+// @Summary Get a pocket
+// @Description Get a pocket of the authenticated user, with its balance and goal progress.
+// @Tags Pockets
+// @Produce json
+// @Param id path int true "Pocket account ID"
+// @Success 200 {object} pocketResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Insufficient Scope"
+// @Failure 404 {object} Problem "Pocket Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /pockets/{id} [get]
+func (server *Server) getPocket(ctx echo.Context) error {
+	pocket, account, err := server.ownedPocket(ctx, db.AccountActionView)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, newPocketResponse(pocket, account))
+}
+
+type updatePocketRequest struct {
+	Name       *string `json:"name" validate:"omitnil,min=1,max=50"`
+	GoalAmount *int64  `json:"goal_amount" validate:"omitnil,min=0"`
+	// TargetDate is removed when set to an empty string.
+	TargetDate *string `json:"target_date" validate:"omitnil,len=0|datetime=2006-01-02"`
+	RoundUpTo  *int64  `json:"round_up_to" validate:"omitnil,min=0,max=100000"`
+}
+
+// updatePocket godoc
+// @Summary Update a pocket
+// @Description Rename a pocket of the authenticated user, or change its goal, target date or round-up. Fields left out are kept.
+// @Tags Pockets
+// @Accept json
+// @Produce json
+// @Param id path int true "Pocket account ID"
+// @Param request body updatePocketRequest true "Pocket changes"
+// @Success 200 {object} pocketResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Insufficient Scope or Account Action Forbidden"
+// @Failure 404 {object} Problem "Pocket Not Found"
+// @Failure 409 {object} Problem "Pocket Exists or Round-Up Pocket Exists"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /pockets/{id} [patch]
+func (server *Server) updatePocket(ctx echo.Context) error {
+	pocket, account, err := server.ownedPocket(ctx, db.AccountActionManage)
+	if err != nil {
+		return err
+	}
+
+	req := new(updatePocketRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	params := db.UpdatePocketParams{
+		Name:       pocket.Name,
+		GoalAmount: pocket.GoalAmount,
+		TargetDate: pocket.TargetDate,
+		RoundUpTo:  pocket.RoundUpTo,
+		AccountID:  pocket.AccountID,
+	}
+	if req.Name != nil {
+		params.Name = *req.Name
+	}
+	if req.GoalAmount != nil {
+		params.GoalAmount = *req.GoalAmount
+	}
+	if req.TargetDate != nil {
+		params.TargetDate, err = pocketTargetDate(*req.TargetDate)
+		if err != nil {
+			return err
+		}
+	}
+	if req.RoundUpTo != nil {
+		params.RoundUpTo = *req.RoundUpTo
+	}
+
+	if params.RoundUpTo > 0 {
+		if err := server.checkRoundUpPocket(ctx, pocket.ParentID, pocket.AccountID); err != nil {
+			return err
+		}
+	}
+
+	updated, err := server.store.UpdatePocket(ctx.Request().Context(), params)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return newProblem(http.StatusConflict, CodePocketExists, fmt.Sprintf("account [%d] already has a pocket named %q", pocket.ParentID, params.Name))
+		}
+		return err
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionPocketUpdate,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourcePocket,
+		ResourceID:   strconv.FormatInt(pocket.AccountID, 10),
+		Metadata: map[string]interface{}{
+			"goal_amount": updated.GoalAmount,
+			"round_up_to": updated.RoundUpTo,
+		},
+	})
+
+	return ctx.JSON(http.StatusOK, newPocketResponse(updated, account))
+}
+
+type pocketMoveResponse struct {
+	ID            int64  `json:"id"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Kind          string `json:"kind" enums:"move,round_up"`
+	// PaymentID is the payment a round-up was swept from.
+	PaymentID *int64    `json:"payment_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newPocketMoveResponse(move db.PocketMove) pocketMoveResponse {
+	res := pocketMoveResponse{
+		ID:            move.ID,
+		FromAccountID: move.FromAccountID,
+		ToAccountID:   move.ToAccountID,
+		Amount:        move.Amount,
+		Kind:          move.Kind,
+		CreatedAt:     move.CreatedAt,
+	}
+	if move.PaymentID.Valid {
+		res.PaymentID = &move.PaymentID.Int64
+	}
+	return res
+}
+
+type movePocketRequest struct {
+	FromAccountID int64 `json:"from_account_id" validate:"required,min=1"`
+	ToAccountID   int64 `json:"to_account_id" validate:"required,min=1,nefield=FromAccountID"`
+	Amount        int64 `json:"amount" validate:"required,gt=0"`
+}
+
+type movePocketResponse struct {
+	Move        pocketMoveResponse `json:"move"`
+	FromAccount db.Account         `json:"from_account"`
+	ToAccount   db.Account         `json:"to_account"`
+}
+
+// movePocket godoc
+// @Summary Move money between pockets
+// @Description Move money at once between a checking account of the authenticated user and its pockets, or between two pockets of the same account. Moves stay within the account, so they aren't payments and aren't limited or screened like them.
+// @Tags Pockets
+// @Accept json
+// @Produce json
+// @Param request body movePocketRequest true "Move"
+// @Success 201 {object} movePocketResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Insufficient Scope or Account Action Forbidden"
+// @Failure 404 {object} Problem "Account Not Found"
+// @Failure 422 {object} Problem "Pocket Move Invalid, Account Frozen or Insufficient Funds"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /pockets/moves [post]
+func (server *Server) movePocket(ctx echo.Context) error {
+	req := new(movePocketRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	fromParent, err := server.pocketParent(ctx, req.FromAccountID)
+	if err != nil {
+		return err
+	}
+	toParent, err := server.pocketParent(ctx, req.ToAccountID)
+	if err != nil {
+		return err
+	}
+	if fromParent != toParent {
+		return newProblem(http.StatusUnprocessableEntity, CodePocketMoveInvalid, "money only moves between an account and its own pockets")
+	}
+
+	result, err := server.store.MovePocketTx(ctx.Request().Context(), db.MovePocketTxParams{
+		ParentID:      fromParent,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrInsufficientFunds):
+			return newProblem(http.StatusUnprocessableEntity, CodeInsufficientFunds, err.Error())
+		case errors.Is(err, db.ErrAccountFrozen):
+			return newProblem(http.StatusUnprocessableEntity, CodeAccountFrozen, err.Error())
+		}
+		return err
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionPocketMove,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourcePocket,
+		ResourceID:   strconv.FormatInt(result.Move.ID, 10),
+		Metadata: map[string]interface{}{
+			"from_account_id": req.FromAccountID,
+			"to_account_id":   req.ToAccountID,
+			"amount":          req.Amount,
+		},
+	})
+
+	events.PublishAll(ctx.Request().Context(), server.broker, events.PocketMoveEvents(result))
+
+	return ctx.JSON(http.StatusCreated, movePocketResponse{
+		Move:        newPocketMoveResponse(result.Move),
+		FromAccount: result.FromAccount,
+		ToAccount:   result.ToAccount,
+	})
+}
+
+// pocketParent finds the account money moves within for a side of a move:
+// the parent of a pocket, or a checking account itself. The account must
+// be one the authenticated user may manage.
+func (server *Server) pocketParent(ctx echo.Context, accountID int64) (int64, error) {
+	account, err := server.store.GetAccount(ctx.Request().Context(), accountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, newProblem(http.StatusNotFound, CodeAccountNotFound, fmt.Sprintf("account [%d] not found", accountID))
+		}
+		return 0, err
+	}
+	if _, err := server.authorizeAccount(ctx, account, db.AccountActionManage); err != nil {
+		return 0, err
+	}
+
+	switch account.Type {
+	case db.AccountChecking:
+		return account.ID, nil
+	case db.AccountPocket:
+		pocket, err := server.store.GetPocket(ctx.Request().Context(), account.ID)
+		if err != nil {
+			return 0, err
+		}
+		return pocket.ParentID, nil
+	}
+	detail := fmt.Sprintf("account [%d] is neither a checking account nor a pocket", account.ID)
+	return 0, newProblem(http.StatusUnprocessableEntity, CodePocketMoveInvalid, detail)
+}
+
+type listPocketMovesRequest struct {
+	PageID   int32 `query:"page_id" validate:"required,min=1"`
+	PageSize int32 `query:"page_size" validate:"required,min=5,max=50"`
+}
+
+// listPocketMoves godoc
+// @Summary List pocket moves
+// @Description Get the money moved in and out of a pocket of the authenticated user, latest first, with pagination: moves made by the user and round-ups swept from payments.
+// @Tags Pockets
+// @Produce json
+// @Param id path int true "Pocket account ID"
+// @Param page_id query int true "Page ID for pagination"
+// @Param page_size query int true "Number of moves per page (min: 5, max: 50)"
+// @Success 200 {array} pocketMoveResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Insufficient Scope"
+// @Failure 404 {object} Problem "Pocket Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /pockets/{id}/moves [get]
+func (server *Server) listPocketMoves(ctx echo.Context) error {
+	pocket, _, err := server.ownedPocket(ctx, db.AccountActionView)
+	if err != nil {
+		return err
+	}
+
+	req := new(listPocketMovesRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	moves, err := server.store.ListPocketMoves(ctx.Request().Context(), db.ListPocketMovesParams{
+		AccountID: pocket.AccountID,
+		Limit:     req.PageSize,
+		Offset:    (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	res := make([]pocketMoveResponse, len(moves))
+	for i, move := range moves {
+		res[i] = newPocketMoveResponse(move)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
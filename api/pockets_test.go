@@ -0,0 +1,531 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/labstack/echo/v4"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// randomPocket sets a pocket aside in parent, returning the pocket and its
+// account.
+func randomPocket(parent db.Account, name string) (db.Pocket, db.Account) {
+	account := randomAccount(parent.Owner)
+	account.ID = parent.ID + 1
+	account.Currency = parent.Currency
+	account.Type = db.AccountPocket
+
+	pocket := db.Pocket{
+		AccountID:  account.ID,
+		ParentID:   parent.ID,
+		Name:       name,
+		GoalAmount: account.Balance * 2,
+		CreatedAt:  time.Now().UTC().Truncate(time.Second),
+	}
+	return pocket, account
+}
+
+func TestCreatePocketAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	other, _ := randomUser(t)
+	parent := randomAccount(user.Username)
+	parent.Type = db.AccountChecking
+	pocket, account := randomPocket(parent, "Holiday")
+	targetDate := time.Now().AddDate(1, 0, 0).Format(dateFormat)
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		body          echo.Map
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			user: user,
+			body: echo.Map{"name": "Holiday", "goal_amount": 100_000, "target_date": targetDate, "round_up_to": 100},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(parent.ID)).Times(1).Return(parent, nil)
+				store.EXPECT().GetRoundUpPocket(gomock.Any(), gomock.Eq(parent.ID)).Times(1).Return(db.Pocket{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreatePocketTx(gomock.Any(), gomock.Cond(func(x any) bool {
+						arg := x.(db.CreatePocketTxParams)
+						return arg.Owner == user.Username && arg.Currency == parent.Currency &&
+							arg.ParentID == parent.ID && arg.Name == "Holiday" && arg.GoalAmount == 100_000 &&
+							arg.TargetDate.Valid && arg.TargetDate.Time.Format(dateFormat) == targetDate &&
+							arg.RoundUpTo == 100
+					})).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreatePocketTxParams) (db.CreatePocketTxResult, error) {
+						created := pocket
+						created.GoalAmount = arg.GoalAmount
+						created.TargetDate = arg.TargetDate
+						created.RoundUpTo = arg.RoundUpTo
+						created.Name = arg.Name
+						funded := account
+						funded.Balance = 25_000
+						return db.CreatePocketTxResult{Account: funded, Pocket: created}, nil
+					})
+				expectAuditEvent(store, audit.ActionPocketCreate, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var res pocketResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, pocket.AccountID, res.AccountID)
+				require.Equal(t, parent.ID, res.ParentID)
+				require.Equal(t, int64(25), res.GoalProgress)
+				require.NotNil(t, res.TargetDate)
+				require.Equal(t, targetDate, *res.TargetDate)
+				require.Equal(t, int64(100), res.RoundUpTo)
+			},
+		},
+		{
+			name: "RoundUpPocketExists",
+			user: user,
+			body: echo.Map{"name": "Spare change", "round_up_to": 100},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(parent.ID)).Times(1).Return(parent, nil)
+				store.EXPECT().GetRoundUpPocket(gomock.Any(), gomock.Eq(parent.ID)).Times(1).Return(pocket, nil)
+				store.EXPECT().CreatePocketTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, CodeRoundUpPocketExists)
+			},
+		},
+		{
+			name: "NameTaken",
+			user: user,
+			body: echo.Map{"name": "Holiday"},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(parent.ID)).Times(1).Return(parent, nil)
+				store.EXPECT().GetRoundUpPocket(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					CreatePocketTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreatePocketTxResult{}, &pq.Error{Code: pqUniqueViolation})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, CodePocketExists)
+			},
+		},
+		{
+			name: "TargetDateInThePast",
+			user: user,
+			body: echo.Map{"name": "Holiday", "target_date": "2020-01-01"},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(parent.ID)).Times(1).Return(parent, nil)
+				store.EXPECT().CreatePocketTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name: "SavingsParent",
+			user: user,
+			body: echo.Map{"name": "Holiday"},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				savings := parent
+				savings.Type = db.AccountSavings
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(parent.ID)).Times(1).Return(savings, nil)
+				store.EXPECT().CreatePocketTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, CodePocketParentInvalid)
+			},
+		},
+		{
+			name: "NotOwner",
+			user: other,
+			body: echo.Map{"name": "Holiday"},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, other)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(parent.ID)).Times(1).Return(parent, nil)
+				expectNoMembership(store)
+				store.EXPECT().CreatePocketTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireProblemCode(t, recorder, CodeAccountNotOwned)
+			},
+		},
+		{
+			name: "MissingName",
+			user: user,
+			body: echo.Map{"goal_amount": 100},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(parent.ID)).Times(1).Return(parent, nil)
+				store.EXPECT().CreatePocketTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/pockets", parent.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListPocketsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	parent := randomAccount(user.Username)
+	parent.Type = db.AccountChecking
+	pocket, account := randomPocket(parent, "Holiday")
+	account.Balance = 80_000
+	pocket.GoalAmount = 50_000
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	expectUser(store, user)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(parent.ID)).Times(1).Return(parent, nil)
+	store.EXPECT().ListPockets(gomock.Any(), gomock.Eq(parent.ID)).Times(1).Return([]db.Pocket{pocket}, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/accounts/%d/pockets", parent.ID), nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var res []pocketResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+	require.Len(t, res, 1)
+	require.Equal(t, int64(80_000), res[0].Balance)
+	// progress stops at the goal
+	require.Equal(t, int64(100), res[0].GoalProgress)
+	require.Nil(t, res[0].TargetDate)
+}
+
+func TestUpdatePocketAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	other, _ := randomUser(t)
+	parent := randomAccount(user.Username)
+	pocket, account := randomPocket(parent, "Holiday")
+	pocket.TargetDate = sql.NullTime{Time: time.Now().AddDate(1, 0, 0), Valid: true}
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		body          echo.Map
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			user: user,
+			body: echo.Map{"name": "Car", "target_date": "", "round_up_to": 500},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().GetPocket(gomock.Any(), gomock.Eq(pocket.AccountID)).Times(1).Return(pocket, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				// the pocket itself may round up already
+				store.EXPECT().GetRoundUpPocket(gomock.Any(), gomock.Eq(parent.ID)).Times(1).Return(pocket, nil)
+				store.EXPECT().
+					UpdatePocket(gomock.Any(), gomock.Eq(db.UpdatePocketParams{
+						Name:       "Car",
+						GoalAmount: pocket.GoalAmount,
+						RoundUpTo:  500,
+						AccountID:  pocket.AccountID,
+					})).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.UpdatePocketParams) (db.Pocket, error) {
+						updated := pocket
+						updated.Name = arg.Name
+						updated.TargetDate = arg.TargetDate
+						updated.RoundUpTo = arg.RoundUpTo
+						return updated, nil
+					})
+				expectAuditEvent(store, audit.ActionPocketUpdate, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res pocketResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, "Car", res.Name)
+				require.Nil(t, res.TargetDate)
+				require.Equal(t, int64(500), res.RoundUpTo)
+			},
+		},
+		{
+			name: "EmptyName",
+			user: user,
+			body: echo.Map{"name": ""},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().GetPocket(gomock.Any(), gomock.Eq(pocket.AccountID)).Times(1).Return(pocket, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().UpdatePocket(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotOwner",
+			user: other,
+			body: echo.Map{"name": "Car"},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, other)
+				// once more to find the parent members are looked up on
+				store.EXPECT().GetPocket(gomock.Any(), gomock.Eq(pocket.AccountID)).Times(2).Return(pocket, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				expectNoMembership(store)
+				store.EXPECT().UpdatePocket(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, CodePocketNotFound)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/pockets/%d", pocket.AccountID)
+			request, err := http.NewRequest(http.MethodPatch, url, bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestMovePocketAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	other, _ := randomUser(t)
+	parent := randomAccount(user.Username)
+	parent.Type = db.AccountChecking
+	pocket, account := randomPocket(parent, "Holiday")
+	otherParent := randomAccount(other.Username)
+	otherParent.ID = parent.ID + 2
+	otherParent.Type = db.AccountChecking
+
+	testCases := []struct {
+		name          string
+		body          echo.Map
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: echo.Map{"from_account_id": account.ID, "to_account_id": parent.ID, "amount": 300},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetPocket(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(pocket, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(parent.ID)).Times(1).Return(parent, nil)
+				store.EXPECT().
+					MovePocketTx(gomock.Any(), gomock.Eq(db.MovePocketTxParams{
+						ParentID:      parent.ID,
+						FromAccountID: account.ID,
+						ToAccountID:   parent.ID,
+						Amount:        300,
+					})).
+					Times(1).
+					Return(db.MovePocketTxResult{
+						Move: db.PocketMove{
+							ID:            1,
+							ParentID:      parent.ID,
+							FromAccountID: account.ID,
+							ToAccountID:   parent.ID,
+							Amount:        300,
+							Kind:          db.PocketMoveKindMove,
+						},
+						FromAccount: account,
+						ToAccount:   parent,
+					}, nil)
+				expectAuditEvent(store, audit.ActionPocketMove, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var res movePocketResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, int64(300), res.Move.Amount)
+				require.Equal(t, db.PocketMoveKindMove, res.Move.Kind)
+				require.Nil(t, res.Move.PaymentID)
+			},
+		},
+		{
+			name: "OtherUsersAccount",
+			body: echo.Map{"from_account_id": account.ID, "to_account_id": otherParent.ID, "amount": 300},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetPocket(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(pocket, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(otherParent.ID)).Times(1).Return(otherParent, nil)
+				expectNoMembership(store)
+				store.EXPECT().MovePocketTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireProblemCode(t, recorder, CodeAccountNotOwned)
+			},
+		},
+		{
+			name: "TwoCheckingAccounts",
+			body: echo.Map{"from_account_id": parent.ID, "to_account_id": parent.ID + 2, "amount": 300},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				second := parent
+				second.ID = parent.ID + 2
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(parent.ID)).Times(1).Return(parent, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(second.ID)).Times(1).Return(second, nil)
+				store.EXPECT().MovePocketTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, CodePocketMoveInvalid)
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			body: echo.Map{"from_account_id": parent.ID, "to_account_id": account.ID, "amount": 300},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(parent.ID)).Times(1).Return(parent, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetPocket(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(pocket, nil)
+				store.EXPECT().
+					MovePocketTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.MovePocketTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, CodeInsufficientFunds)
+			},
+		},
+		{
+			name: "SameAccount",
+			body: echo.Map{"from_account_id": parent.ID, "to_account_id": parent.ID, "amount": 300},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().MovePocketTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/pockets/moves", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreatePaymentToPocketAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	parent := randomAccount(user.Username)
+	parent.Type = db.AccountChecking
+	_, account := randomPocket(parent, "Holiday")
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	expectUser(store, user)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(parent.ID)).Times(1).Return(parent, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	store.EXPECT().PaymentTx(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(echo.Map{
+		"from_account_id": parent.ID,
+		"to_account_id":   account.ID,
+		"amount":          10,
+		"currency":        parent.Currency,
+	})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/payments", bytes.NewReader(data))
+	require.NoError(t, err)
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	requireProblemCode(t, recorder, CodePocketAccount)
+}
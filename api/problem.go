@@ -0,0 +1,287 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/logging"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/lib/pq"
+)
+
+const (
+	problemContentType = "application/problem+json"
+	problemTypeBase    = "https://neobank.dev/problems/"
+)
+
+// Stable, machine-readable error codes. Clients should branch on these rather
+// than on the human readable title or detail.
+const (
+	CodeInvalidRequest          = "invalid_request"
+	CodeValidationFailed        = "validation_failed"
+	CodeUnauthorized            = "unauthorized"
+	CodeInvalidCredentials      = "invalid_credentials"
+	CodeForbidden               = "forbidden"
+	CodeAccountNotOwned         = "account_not_owned"
+	CodeNotFound                = "not_found"
+	CodeAccountNotFound         = "account_not_found"
+	CodeMethodNotAllowed        = "method_not_allowed"
+	CodeConflict                = "conflict"
+	CodeUserAlreadyExists       = "user_already_exists"
+	CodeAccountAlreadyExists    = "account_already_exists"
+	CodeReferenceNotFound       = "reference_not_found"
+	CodeCurrencyMismatch        = "currency_mismatch"
+	CodeAccountFrozen           = "account_frozen"
+	CodeUserDisabled            = "user_disabled"
+	CodeRateLimited             = "rate_limited"
+	CodeInvalidToken            = "invalid_token"
+	CodeEmailAlreadyVerified    = "email_already_verified"
+	CodeEmailNotVerified        = "email_not_verified"
+	CodeEmailTaken              = "email_taken"
+	CodeAdminRequired           = "admin_required"
+	CodeSupportRequired         = "support_required"
+	CodeInsufficientScope       = "insufficient_scope"
+	CodeIPNotAllowed            = "ip_not_allowed"
+	CodeAPIKeyNotFound          = "api_key_not_found"
+	CodeOAuthConsentNotFound    = "oauth_consent_not_found"
+	CodeAdjustmentNotFound      = "adjustment_not_found"
+	CodeAdjustmentNotPending    = "adjustment_not_pending"
+	CodeAdjustmentExpired       = "adjustment_expired"
+	CodeSelfReview              = "self_review"
+	CodeKYCRequired             = "kyc_required"
+	CodeKYCNotEditable          = "kyc_not_editable"
+	CodeKYCIncomplete           = "kyc_incomplete"
+	CodeKYCNotPending           = "kyc_not_pending"
+	CodeKYCDocumentNotFound     = "kyc_document_not_found"
+	CodeUnsupportedMedia        = "unsupported_media_type"
+	CodeScreeningHold           = "screening_hold"
+	CodeScreeningHitNotFound    = "screening_hit_not_found"
+	CodeScreeningHitNotOpen     = "screening_hit_not_open"
+	CodeFraudChallenge          = "fraud_challenge"
+	CodeFraudDecisionNotFound   = "fraud_decision_not_found"
+	CodeFraudDecisionNotPending = "fraud_decision_not_pending"
+	CodeAMLAlertNotFound        = "aml_alert_not_found"
+	CodeAMLAlertNotOpen         = "aml_alert_not_open"
+	CodeAMLAlertNotEscalated    = "aml_alert_not_escalated"
+	CodeAssigneeNotStaff        = "assignee_not_staff"
+	CodeSavingsProductNotFound  = "savings_product_not_found"
+	CodeLoanProductNotFound     = "loan_product_not_found"
+	CodeLoanNotFound            = "loan_not_found"
+	CodeLoanTermsInvalid        = "loan_terms_invalid"
+	CodeLoanInArrears           = "loan_in_arrears"
+	CodeLoanLimitExceeded       = "loan_limit_exceeded"
+	CodeLoanClosed              = "loan_closed"
+	CodeRepaymentTooLarge       = "repayment_too_large"
+	CodeInsufficientFunds       = "insufficient_funds"
+	CodeCardNotFound            = "card_not_found"
+	CodeCardAccountInvalid      = "card_account_invalid"
+	CodeCardLimitsInvalid       = "card_limits_invalid"
+	CodePocketAccount           = "pocket_account"
+	CodePocketNotFound          = "pocket_not_found"
+	CodePocketExists            = "pocket_exists"
+	CodeRoundUpPocketExists     = "round_up_pocket_exists"
+	CodePocketParentInvalid     = "pocket_parent_invalid"
+	CodePocketMoveInvalid       = "pocket_move_invalid"
+	CodeAccountActionForbidden  = "account_action_forbidden"
+	CodePaymentLimitExceeded    = "payment_limit_exceeded"
+	CodeAccountNotShareable     = "account_not_shareable"
+	CodeUserNotFound            = "user_not_found"
+	CodeInvitationInvalid       = "invitation_invalid"
+	CodeInvitationExists        = "invitation_exists"
+	CodeInvitationNotFound      = "invitation_not_found"
+	CodeInvitationNotPending    = "invitation_not_pending"
+	CodeInvitationExpired       = "invitation_expired"
+	CodeAccountMemberNotFound   = "account_member_not_found"
+	CodeUnavailable             = "service_unavailable"
+	CodeInternal                = "internal_error"
+)
+
+// postgres error codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pqForeignKeyViolation = "23503"
+	pqUniqueViolation     = "23505"
+)
+
+// Problem is an RFC 7807 problem details object, returned as application/problem+json.
+type Problem struct {
+	Type          string       `json:"type"`
+	Title         string       `json:"title"`
+	Status        int          `json:"status"`
+	Code          string       `json:"code"`
+	Detail        string       `json:"detail,omitempty"`
+	Instance      string       `json:"instance,omitempty"`
+	CorrelationID string       `json:"correlation_id,omitempty"`
+	Errors        []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes a single field that failed validation.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (problem *Problem) Error() string {
+	if problem.Detail != "" {
+		return problem.Detail
+	}
+	return problem.Title
+}
+
+func newProblem(status int, code string, detail string) *Problem {
+	return &Problem{
+		Type:   problemTypeBase + code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Code:   code,
+		Detail: detail,
+	}
+}
+
+func validationProblem(validationErrors validator.ValidationErrors) *Problem {
+	problem := newProblem(http.StatusBadRequest, CodeValidationFailed, "the request contains invalid fields")
+	for _, fieldErr := range validationErrors {
+		problem.Errors = append(problem.Errors, FieldError{
+			Field:   fieldErr.Field(),
+			Rule:    fieldErr.Tag(),
+			Message: fieldErrorMessage(fieldErr),
+		})
+	}
+	return problem
+}
+
+func fieldErrorMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "min", "gte":
+		return fmt.Sprintf("must be at least %s", fieldErr.Param())
+	case "max", "lte":
+		return fmt.Sprintf("must be at most %s", fieldErr.Param())
+	case "gt":
+		if fieldErr.Param() == "" {
+			// gt without a parameter compares times with now
+			return "must be in the future"
+		}
+		return fmt.Sprintf("must be greater than %s", fieldErr.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of [%s]", fieldErr.Param())
+	case "email":
+		return "must be a valid email address"
+	case "len":
+		return fmt.Sprintf("must be %s characters long", fieldErr.Param())
+	case "datetime":
+		return fmt.Sprintf("must be a date formatted as %s", fieldErr.Param())
+	case "iso3166_1_alpha2":
+		return "must be an ISO 3166 country code"
+	case "alphanum":
+		return "must contain only letters and digits"
+	case "cidr|ip":
+		return "must be an IP address or a CIDR"
+	default:
+		return fmt.Sprintf("failed the %s rule", fieldErr.Tag())
+	}
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation
+}
+
+// toProblem maps any handler error to a problem. Anything that is not a known
+// client error is reported as an opaque internal error: the original message
+// is only logged, under a correlation ID that is also returned to the client.
+func toProblem(ctx echo.Context, err error) *Problem {
+	var problem *Problem
+	if errors.As(err, &problem) {
+		return problem
+	}
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		return validationProblem(validationErrors)
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return newProblem(http.StatusNotFound, CodeNotFound, "the requested resource does not exist")
+	}
+
+	if errors.Is(err, db.ErrStoreDraining) {
+		return newProblem(http.StatusServiceUnavailable, CodeUnavailable, "the server is shutting down, retry the request")
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case pqUniqueViolation:
+			return newProblem(http.StatusConflict, CodeConflict, "the resource already exists")
+		case pqForeignKeyViolation:
+			return newProblem(http.StatusUnprocessableEntity, CodeReferenceNotFound, "the request references a resource that does not exist")
+		}
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) && httpErr.Code < http.StatusInternalServerError {
+		return httpErrorProblem(httpErr)
+	}
+
+	correlationID := logging.RequestID(ctx.Request().Context())
+	if correlationID == "" {
+		correlationID = uuid.NewString()
+	}
+	slog.ErrorContext(ctx.Request().Context(), "internal error",
+		slog.String("correlation_id", correlationID),
+		slog.String("method", ctx.Request().Method),
+		slog.String("path", ctx.Request().URL.Path),
+		slog.Any("error", err),
+	)
+
+	problem = newProblem(http.StatusInternalServerError, CodeInternal, "an internal error occurred")
+	problem.CorrelationID = correlationID
+	return problem
+}
+
+func httpErrorProblem(httpErr *echo.HTTPError) *Problem {
+	code := CodeInvalidRequest
+	switch httpErr.Code {
+	case http.StatusUnauthorized:
+		code = CodeUnauthorized
+	case http.StatusForbidden:
+		code = CodeForbidden
+	case http.StatusNotFound:
+		code = CodeNotFound
+	case http.StatusMethodNotAllowed:
+		code = CodeMethodNotAllowed
+	}
+
+	detail, _ := httpErr.Message.(string)
+	return newProblem(httpErr.Code, code, detail)
+}
+
+// errorHandler renders every error returned by a handler or middleware as problem+json.
+func errorHandler(err error, ctx echo.Context) {
+	if ctx.Response().Committed {
+		return
+	}
+
+	problem := toProblem(ctx, err)
+	if problem.Instance == "" {
+		problem.Instance = ctx.Request().URL.Path
+	}
+
+	ctx.Response().Header().Set(echo.HeaderContentType, problemContentType)
+	if ctx.Request().Method == http.MethodHead {
+		err = ctx.NoContent(problem.Status)
+	} else {
+		err = ctx.JSON(problem.Status, problem)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx.Request().Context(), "cannot write error response", slog.Any("error", err))
+	}
+}
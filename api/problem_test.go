@@ -0,0 +1,121 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorHandler(t *testing.T) {
+	testCases := []struct {
+		name          string
+		handler       echo.HandlerFunc
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, problem Problem)
+	}{
+		{
+			name: "Problem",
+			handler: func(ctx echo.Context) error {
+				return newProblem(http.StatusNotFound, CodeAccountNotFound, "account not found")
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, problem Problem) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Equal(t, CodeAccountNotFound, problem.Code)
+				require.Equal(t, problemTypeBase+CodeAccountNotFound, problem.Type)
+				require.Equal(t, "/problem", problem.Instance)
+			},
+		},
+		{
+			name: "ValidationErrors",
+			handler: func(ctx echo.Context) error {
+				return ctx.Validate(&createUserRequest{Username: "bad-name!", Password: "123"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, problem Problem) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, CodeValidationFailed, problem.Code)
+
+				rules := make(map[string]string)
+				for _, fieldErr := range problem.Errors {
+					rules[fieldErr.Field] = fieldErr.Rule
+				}
+				require.Equal(t, map[string]string{
+					"username":  "alphanum",
+					"password":  "min",
+					"full_name": "required",
+					"email":     "required",
+				}, rules)
+			},
+		},
+		{
+			name: "UniqueViolation",
+			handler: func(ctx echo.Context) error {
+				return &pq.Error{Code: pqUniqueViolation, Message: "duplicate key value violates unique constraint"}
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, problem Problem) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				require.Equal(t, CodeConflict, problem.Code)
+				require.NotContains(t, problem.Detail, "duplicate key")
+			},
+		},
+		{
+			name: "ForeignKeyViolation",
+			handler: func(ctx echo.Context) error {
+				return &pq.Error{Code: pqForeignKeyViolation}
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, problem Problem) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				require.Equal(t, CodeReferenceNotFound, problem.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			handler: func(ctx echo.Context) error {
+				return errors.New("pq: connection refused to 10.0.0.1")
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, problem Problem) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Equal(t, CodeInternal, problem.Code)
+				require.NotEmpty(t, problem.CorrelationID)
+				require.NotContains(t, recorder.Body.String(), "10.0.0.1")
+			},
+		},
+		{
+			name: "HTTPError",
+			handler: func(ctx echo.Context) error {
+				return echo.NewHTTPError(http.StatusBadRequest, "malformed body")
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, problem Problem) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, CodeInvalidRequest, problem.Code)
+				require.Equal(t, "malformed body", problem.Detail)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil)
+			server.router.GET("/problem", tc.handler)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/problem", nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			require.True(t, strings.HasPrefix(recorder.Header().Get(echo.HeaderContentType), problemContentType))
+
+			var problem Problem
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+			require.Equal(t, recorder.Code, problem.Status)
+			tc.checkResponse(t, recorder, problem)
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/mail"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/labstack/echo/v4"
+)
+
+// getCurrentUser godoc
+// @Summary Get the authenticated user
+// @Description Get the profile of the authenticated user.
+// @Tags Users
+// @Produce json
+// @Success 200 {object} userResponse
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 429 {object} Problem "Rate Limited"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /users/me [get]
+func (server *Server) getCurrentUser(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, newUserResponse(authUser(ctx)))
+}
+
+type updateCurrentUserRequest struct {
+	FullName *string `json:"full_name" validate:"omitempty,min=1"`
+	Email    *string `json:"email" validate:"omitempty,email"`
+}
+
+type updateCurrentUserResponse struct {
+	User userResponse `json:"user"`
+	// PendingEmail is the new email waiting for a confirmation from both the
+	// current and the new address.
+	PendingEmail string `json:"pending_email,omitempty"`
+}
+
+// updateCurrentUser godoc
+// @Summary Update the authenticated user
+// @Description Change the full name or the email of the authenticated user. The full name changes right away. A new email is only used once the links sent to the current and to the new address were both opened.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param request body updateCurrentUserRequest true "Request body for updating the authenticated user"
+// @Success 200 {object} updateCurrentUserResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 409 {object} Problem "Email Taken"
+// @Failure 429 {object} Problem "Rate Limited"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /users/me [patch]
+func (server *Server) updateCurrentUser(ctx echo.Context) error {
+	req := new(updateCurrentUserRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	user := authUser(ctx)
+	res := updateCurrentUserResponse{}
+
+	if req.FullName != nil && *req.FullName != user.FullName {
+		updated, err := server.store.UpdateUserFullName(ctx.Request().Context(), db.UpdateUserFullNameParams{
+			FullName: *req.FullName,
+			Username: user.Username,
+		})
+		if err != nil {
+			return err
+		}
+
+		audit.Record(ctx.Request().Context(), server.store, audit.Event{
+			Actor:        user.Username,
+			Action:       audit.ActionUserUpdate,
+			Outcome:      audit.OutcomeSuccess,
+			ResourceType: audit.ResourceUser,
+			ResourceID:   user.Username,
+			Metadata:     map[string]interface{}{"fields": []string{"full_name"}},
+		})
+		user = updated
+	}
+
+	if req.Email != nil && *req.Email != user.Email {
+		if err := server.requestEmailChange(ctx, user, *req.Email); err != nil {
+			return err
+		}
+		res.PendingEmail = *req.Email
+	}
+
+	res.User = newUserResponse(user)
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// requestEmailChange mails a confirmation link to the current and to the new
+// address of user.
+func (server *Server) requestEmailChange(ctx echo.Context, user db.User, newEmail string) error {
+	_, err := server.store.GetUserByEmail(ctx.Request().Context(), newEmail)
+	if err == nil {
+		return newProblem(http.StatusConflict, CodeEmailTaken, "email is already taken")
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	ttl := server.config.EmailVerifyTTL
+	if ttl <= 0 {
+		ttl = defaultEmailVerificationTTL
+	}
+
+	result, err := server.store.RequestEmailChangeTx(ctx.Request().Context(), db.RequestEmailChangeTxParams{
+		Username: user.Username,
+		OldEmail: user.Email,
+		NewEmail: newEmail,
+		TTL:      ttl,
+	})
+	if err != nil {
+		return err
+	}
+
+	messages := []mail.Message{
+		mail.ConfirmOldEmail(user.Email, newEmail, mail.Link(server.config.AppBaseURL, "/confirm-email", result.OldToken)),
+		mail.ConfirmNewEmail(newEmail, mail.Link(server.config.AppBaseURL, "/confirm-email", result.NewToken)),
+	}
+	for _, msg := range messages {
+		if err := server.mailer.Send(ctx.Request().Context(), msg); err != nil {
+			return err
+		}
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Actor:        user.Username,
+		Action:       audit.ActionUserEmailChangeRequest,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceUser,
+		ResourceID:   user.Username,
+		Metadata:     map[string]interface{}{"old_email": user.Email, "new_email": newEmail},
+	})
+	return nil
+}
+
+type confirmEmailChangeRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type confirmEmailChangeResponse struct {
+	NewEmail     string `json:"new_email"`
+	OldConfirmed bool   `json:"old_email_confirmed"`
+	NewConfirmed bool   `json:"new_email_confirmed"`
+	// Completed is set once both addresses confirmed and the email of the
+	// user was replaced.
+	Completed bool `json:"completed"`
+}
+
+// confirmEmailChange godoc
+// @Summary Confirm an email change
+// @Description Confirm a change of email with the token sent to the current or to the new address. The email is replaced after both confirmed.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param request body confirmEmailChangeRequest true "Request body for confirming an email change"
+// @Success 200 {object} confirmEmailChangeResponse
+// @Failure 400 {object} Problem "Bad Request or Invalid Token"
+// @Failure 409 {object} Problem "Email Taken"
+// @Failure 429 {object} Problem "Rate Limited"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /users/email/confirm [post]
+func (server *Server) confirmEmailChange(ctx echo.Context) error {
+	req := new(confirmEmailChangeRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	result, err := server.store.ConfirmEmailChangeTx(ctx.Request().Context(), req.Token)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidUserToken) {
+			return newProblem(http.StatusBadRequest, CodeInvalidToken, err.Error())
+		}
+		if isUniqueViolation(err) {
+			return newProblem(http.StatusConflict, CodeEmailTaken, "email is already taken")
+		}
+		return err
+	}
+
+	change := result.EmailChange
+	if result.Completed {
+		audit.Record(ctx.Request().Context(), server.store, audit.Event{
+			Actor:        change.Username,
+			Action:       audit.ActionUserEmailChange,
+			Outcome:      audit.OutcomeSuccess,
+			ResourceType: audit.ResourceUser,
+			ResourceID:   change.Username,
+			Metadata:     map[string]interface{}{"old_email": change.OldEmail, "new_email": change.NewEmail},
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, confirmEmailChangeResponse{
+		NewEmail:     change.NewEmail,
+		OldConfirmed: change.OldConfirmedAt.Valid,
+		NewConfirmed: change.NewConfirmedAt.Valid,
+		Completed:    result.Completed,
+	})
+}
+
+type changePasswordRequest struct {
+	OldPassword string `json:"old_password" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=6"`
+}
+
+// changePassword godoc
+// @Summary Change the password
+// @Description Change the password of the authenticated user. Access tokens issued before stop working, so the response holds a new one. Wrong old passwords count towards a lockout like failed logins, and during a lockout the answer is the same as for a wrong password.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param request body changePasswordRequest true "Request body for changing the password"
+// @Success 200 {object} loginUserResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized or Invalid Credentials"
+// @Failure 429 {object} Problem "Rate Limited"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /users/me/password [post]
+func (server *Server) changePassword(ctx echo.Context) error {
+	req := new(changePasswordRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	user := authUser(ctx)
+	// like a login, a locked user is refused before the password is checked
+	// and gets the same answer as a wrong password
+	if user.LockedUntil.After(time.Now()) {
+		server.auditPasswordChangeFailure(ctx, user.Username, "locked")
+		return newProblem(http.StatusUnauthorized, CodeInvalidCredentials, "invalid credentials")
+	}
+
+	if err := utils.CheckPassword(user.HashedPassword, req.OldPassword); err != nil {
+		server.auditPasswordChangeFailure(ctx, user.Username, "wrong_password")
+		if err := server.recordLoginFailure(ctx, user.Username); err != nil {
+			return err
+		}
+		return newProblem(http.StatusUnauthorized, CodeInvalidCredentials, "invalid credentials")
+	}
+
+	hash, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	user, err = server.store.ChangePasswordTx(ctx.Request().Context(), db.ChangePasswordTxParams{
+		Username:       user.Username,
+		HashedPassword: hash,
+	})
+	if err != nil {
+		return err
+	}
+
+	token, err := server.tokenMaker.CreateToken(user.Username, server.config.AccessTokenDuration)
+	if err != nil {
+		return err
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Actor:        user.Username,
+		Action:       audit.ActionUserPasswordChange,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceUser,
+		ResourceID:   user.Username,
+	})
+
+	return ctx.JSON(http.StatusOK, loginUserResponse{
+		AccessToken: token,
+		User:        newUserResponse(user),
+	})
+}
+
+func (server *Server) auditPasswordChangeFailure(ctx echo.Context, username string, reason string) {
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Actor:        username,
+		Action:       audit.ActionUserPasswordChange,
+		Outcome:      audit.OutcomeFailure,
+		ResourceType: audit.ResourceUser,
+		ResourceID:   username,
+		Metadata:     map[string]interface{}{"reason": reason},
+	})
+}
@@ -0,0 +1,427 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/mail"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/labstack/echo/v4"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetCurrentUserAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	expectUser(store, user)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/users/me", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var got userResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+	require.Equal(t, newUserResponse(user), got)
+	require.NotContains(t, recorder.Body.String(), "hashed_password")
+}
+
+func TestUpdateCurrentUserAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	newEmail := utils.RandomEmail()
+
+	testCases := []struct {
+		name          string
+		body          map[string]interface{}
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, mailer *mail.MemoryMailer)
+	}{
+		{
+			name: "FullName",
+			body: map[string]interface{}{"full_name": "New Name"},
+			buildStubs: func(store *mockdb.MockStore) {
+				updated := user
+				updated.FullName = "New Name"
+				store.EXPECT().
+					UpdateUserFullName(gomock.Any(), gomock.Eq(db.UpdateUserFullNameParams{
+						FullName: "New Name",
+						Username: user.Username,
+					})).
+					Times(1).
+					Return(updated, nil)
+				expectAuditEvent(store, audit.ActionUserUpdate, audit.OutcomeSuccess)
+				store.EXPECT().RequestEmailChangeTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, mailer *mail.MemoryMailer) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got updateCurrentUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, "New Name", got.User.FullName)
+				require.Empty(t, got.PendingEmail)
+				require.Empty(t, mailer.Messages())
+			},
+		},
+		{
+			name: "Email",
+			body: map[string]interface{}{"email": newEmail},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(newEmail)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().
+					RequestEmailChangeTx(gomock.Any(), gomock.Eq(db.RequestEmailChangeTxParams{
+						Username: user.Username,
+						OldEmail: user.Email,
+						NewEmail: newEmail,
+						TTL:      defaultEmailVerificationTTL,
+					})).
+					Times(1).
+					Return(db.RequestEmailChangeTxResult{OldToken: "old", NewToken: "new"}, nil)
+				expectAuditEvent(store, audit.ActionUserEmailChangeRequest, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, mailer *mail.MemoryMailer) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got updateCurrentUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, user.Email, got.User.Email)
+				require.Equal(t, newEmail, got.PendingEmail)
+
+				messages := mailer.Messages()
+				require.Len(t, messages, 2)
+				require.Equal(t, user.Email, messages[0].To)
+				require.Contains(t, messages[0].Body, "/confirm-email?token=old")
+				require.Equal(t, newEmail, messages[1].To)
+				require.Contains(t, messages[1].Body, "/confirm-email?token=new")
+			},
+		},
+		{
+			name: "SameEmail",
+			body: map[string]interface{}{"email": user.Email},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().RequestEmailChangeTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, mailer *mail.MemoryMailer) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Empty(t, mailer.Messages())
+			},
+		},
+		{
+			name: "EmailTaken",
+			body: map[string]interface{}{"email": newEmail},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(newEmail)).
+					Times(1).
+					Return(db.User{Username: utils.RandomOwner(), Email: newEmail}, nil)
+				store.EXPECT().RequestEmailChangeTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, mailer *mail.MemoryMailer) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, CodeEmailTaken)
+				require.Empty(t, mailer.Messages())
+			},
+		},
+		{
+			name: "InvalidEmail",
+			body: map[string]interface{}{"email": "invalid-email"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, mailer *mail.MemoryMailer) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name: "EmptyFullName",
+			body: map[string]interface{}{"full_name": ""},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserFullName(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, mailer *mail.MemoryMailer) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectUser(store, user)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPatch, "/users/me", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, server.mailer.(*mail.MemoryMailer))
+		})
+	}
+}
+
+func TestConfirmEmailChangeAPI(t *testing.T) {
+	change := db.EmailChange{
+		ID:        utils.RandomInt(1, 1000),
+		Username:  utils.RandomOwner(),
+		OldEmail:  utils.RandomEmail(),
+		NewEmail:  utils.RandomEmail(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	confirmed := sql.NullTime{Time: time.Now(), Valid: true}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "FirstConfirmation",
+			buildStubs: func(store *mockdb.MockStore) {
+				partial := change
+				partial.OldConfirmedAt = confirmed
+				store.EXPECT().
+					ConfirmEmailChangeTx(gomock.Any(), gomock.Eq("token")).
+					Times(1).
+					Return(db.ConfirmEmailChangeTxResult{EmailChange: partial}, nil)
+				store.EXPECT().CreateAuditEvent(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got confirmEmailChangeResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, confirmEmailChangeResponse{
+					NewEmail:     change.NewEmail,
+					OldConfirmed: true,
+				}, got)
+			},
+		},
+		{
+			name: "Completed",
+			buildStubs: func(store *mockdb.MockStore) {
+				done := change
+				done.OldConfirmedAt = confirmed
+				done.NewConfirmedAt = confirmed
+				done.CompletedAt = confirmed
+				store.EXPECT().
+					ConfirmEmailChangeTx(gomock.Any(), gomock.Eq("token")).
+					Times(1).
+					Return(db.ConfirmEmailChangeTxResult{EmailChange: done, Completed: true}, nil)
+				expectAuditEvent(store, audit.ActionUserEmailChange, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got confirmEmailChangeResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.True(t, got.Completed)
+				require.True(t, got.OldConfirmed)
+				require.True(t, got.NewConfirmed)
+			},
+		},
+		{
+			name: "InvalidToken",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ConfirmEmailChangeTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ConfirmEmailChangeTxResult{}, db.ErrInvalidUserToken)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeInvalidToken)
+			},
+		},
+		{
+			name: "EmailTaken",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ConfirmEmailChangeTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ConfirmEmailChangeTxResult{}, &pq.Error{Code: pqUniqueViolation})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, CodeEmailTaken)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(map[string]interface{}{"token": "token"})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/email/confirm", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestChangePasswordAPI(t *testing.T) {
+	user, password := randomUser(t)
+	newPassword := utils.RandomString(8)
+
+	testCases := []struct {
+		name          string
+		user          func() db.User
+		body          map[string]interface{}
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			user: func() db.User { return user },
+			body: map[string]interface{}{"old_password": password, "new_password": newPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				changed := user
+				changed.PasswordChangedAt = time.Now()
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Cond(func(x any) bool {
+						arg, ok := x.(db.ChangePasswordTxParams)
+						return ok && arg.Username == user.Username &&
+							utils.CheckPassword(arg.HashedPassword, newPassword) == nil
+					})).
+					Times(1).
+					Return(changed, nil)
+				expectAuditEvent(store, audit.ActionUserPasswordChange, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got loginUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.NotEmpty(t, got.AccessToken)
+				require.Equal(t, user.Username, got.User.Username)
+			},
+		},
+		{
+			name: "WrongPassword",
+			user: func() db.User { return user },
+			body: map[string]interface{}{"old_password": "wrong-password", "new_password": newPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuditEvent(store, audit.ActionUserPasswordChange, audit.OutcomeFailure)
+				store.EXPECT().
+					LoginFailureTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.LoginFailureTxResult{User: user}, nil)
+				store.EXPECT().ChangePasswordTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireProblemCode(t, recorder, CodeInvalidCredentials)
+			},
+		},
+		{
+			name: "Locked",
+			user: func() db.User {
+				locked := user
+				locked.LockedUntil = time.Now().Add(time.Minute)
+				return locked
+			},
+			body: map[string]interface{}{"old_password": password, "new_password": newPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				// refused like a wrong password, without counting another attempt
+				expectAuditEvent(store, audit.ActionUserPasswordChange, audit.OutcomeFailure)
+				store.EXPECT().LoginFailureTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ChangePasswordTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireProblemCode(t, recorder, CodeInvalidCredentials)
+				require.Empty(t, recorder.Header().Get(echo.HeaderRetryAfter))
+			},
+		},
+		{
+			name: "ShortPassword",
+			user: func() db.User { return user },
+			body: map[string]interface{}{"old_password": password, "new_password": "123"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ChangePasswordTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectUser(store, tc.user())
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/me/password", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
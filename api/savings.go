@@ -0,0 +1,304 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/labstack/echo/v4"
+)
+
+// dateFormat is how days, like accrual dates, are written in responses.
+const dateFormat = "2006-01-02"
+
+type createSavingsProductRequest struct {
+	Name     string `json:"name" validate:"required,max=100"`
+	Currency string `json:"currency" validate:"required,oneof=USD EUR CAD"`
+	// RateBps is the annual rate in basis points: 250 is 2.5%.
+	RateBps         int32  `json:"rate_bps" validate:"min=0,max=10000"`
+	DayCount        string `json:"day_count" validate:"required,oneof=act/365 30/360"`
+	Compounding     string `json:"compounding" validate:"required,oneof=none daily"`
+	PayoutFrequency string `json:"payout_frequency" validate:"required,oneof=monthly quarterly annually"`
+}
+
+// createSavingsProduct godoc
+// @Summary Create a savings product
+// @Description Define the interest savings accounts opened under the product earn: an annual rate in basis points, the day-count convention (act/365 or 30/360), whether interest accrued but not paid out earns interest (daily compounding) and how often it is paid out. Only admins may create products.
+// @Tags Savings
+// @Accept json
+// @Produce json
+// @Param request body createSavingsProductRequest true "Savings product"
+// @Success 201 {object} db.SavingsProduct
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Admin Required"
+// @Failure 409 {object} Problem "Conflict"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /savings/products [post]
+func (server *Server) createSavingsProduct(ctx echo.Context) error {
+	req := new(createSavingsProductRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	product, err := server.store.CreateSavingsProduct(ctx.Request().Context(), db.CreateSavingsProductParams{
+		Name:            req.Name,
+		Currency:        req.Currency,
+		RateBps:         req.RateBps,
+		DayCount:        req.DayCount,
+		Compounding:     req.Compounding,
+		PayoutFrequency: req.PayoutFrequency,
+		CreatedBy:       authUser(ctx).Username,
+	})
+	if err != nil {
+		return err
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionSavingsProductCreate,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceSavingsProduct,
+		ResourceID:   strconv.FormatInt(product.ID, 10),
+		Metadata: map[string]interface{}{
+			"name":             product.Name,
+			"currency":         product.Currency,
+			"rate_bps":         product.RateBps,
+			"day_count":        product.DayCount,
+			"compounding":      product.Compounding,
+			"payout_frequency": product.PayoutFrequency,
+		},
+	})
+
+	return ctx.JSON(http.StatusCreated, product)
+}
+
+// listSavingsProducts godoc
+// @Summary List savings products
+// @Description List the products savings accounts can be opened under.
+// @Tags Savings
+// @Produce json
+// @Success 200 {array} db.SavingsProduct
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /savings/products [get]
+func (server *Server) listSavingsProducts(ctx echo.Context) error {
+	products, err := server.store.ListSavingsProducts(ctx.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, products)
+}
+
+// createSavingsAccount opens a savings account for createAccount.
+func (server *Server) createSavingsAccount(ctx echo.Context, req *createAccountRequest, user db.User) error {
+	product, err := server.store.GetSavingsProduct(ctx.Request().Context(), req.SavingsProductID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return newProblem(http.StatusNotFound, CodeSavingsProductNotFound, "savings product not found")
+		}
+		return err
+	}
+
+	if product.Currency != req.Currency {
+		return newProblem(http.StatusUnprocessableEntity, CodeCurrencyMismatch, "the savings product is in "+product.Currency)
+	}
+
+	result, err := server.store.CreateSavingsAccountTx(ctx.Request().Context(), db.CreateSavingsAccountTxParams{
+		Owner:     user.Username,
+		Currency:  req.Currency,
+		ProductID: product.ID,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return newProblem(http.StatusConflict, CodeAccountAlreadyExists, "a savings account in this currency already exists")
+		}
+		return err
+	}
+
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       audit.ActionAccountCreate,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceAccount,
+		ResourceID:   strconv.FormatInt(result.Account.ID, 10),
+		Metadata: map[string]interface{}{
+			"currency":           result.Account.Currency,
+			"type":               result.Account.Type,
+			"savings_product_id": product.ID,
+		},
+	})
+
+	return ctx.JSON(http.StatusCreated, result.Account)
+}
+
+type listInterestRequest struct {
+	PageID   int32 `query:"page_id" validate:"required,min=1"`
+	PageSize int32 `query:"page_size" validate:"required,min=5,max=50"`
+}
+
+type interestAccrualResponse struct {
+	ID int64 `json:"id"`
+	// Date is the day the interest was earned on, as YYYY-MM-DD.
+	Date    string `json:"date"`
+	Balance int64  `json:"balance"`
+	Base    int64  `json:"base"`
+	RateBps int32  `json:"rate_bps"`
+	// DayCount and Days are the convention and the days the day counted for.
+	DayCount string `json:"day_count"`
+	Days     int32  `json:"days"`
+	// AmountMicros is the interest in millionths of a minor unit.
+	AmountMicros int64  `json:"amount_micros"`
+	Paid         bool   `json:"paid"`
+	PayoutID     *int64 `json:"payout_id,omitempty"`
+}
+
+func newInterestAccrualResponse(accrual db.InterestAccrual) interestAccrualResponse {
+	res := interestAccrualResponse{
+		ID:           accrual.ID,
+		Date:         accrual.AccrualDate.Format(dateFormat),
+		Balance:      accrual.Balance,
+		Base:         accrual.Base,
+		RateBps:      accrual.RateBps,
+		DayCount:     accrual.DayCount,
+		Days:         accrual.Days,
+		AmountMicros: accrual.AmountMicros,
+		Paid:         accrual.PayoutID.Valid,
+	}
+	if accrual.PayoutID.Valid {
+		res.PayoutID = &accrual.PayoutID.Int64
+	}
+	return res
+}
+
+// listAccountInterest godoc
+// @Summary List interest accruals
+// @Description Get the interest a savings account of the authenticated user earned day by day, latest first, with pagination. Interest is accrued in millionths of a minor unit on the end-of-day balance and paid out in whole minor units.
+// @Tags Savings
+// @Produce json
+// @Param id path int true "Account ID"
+// @Param page_id query int true "Page ID for pagination"
+// @Param page_size query int true "Number of accruals per page (min: 5, max: 50)"
+// @Success 200 {array} interestAccrualResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Insufficient Scope"
+// @Failure 404 {object} Problem "Account Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /accounts/{id}/interest [get]
+func (server *Server) listAccountInterest(ctx echo.Context) error {
+	account, err := server.authorizedAccount(ctx, db.AccountActionView)
+	if err != nil {
+		return err
+	}
+
+	req := new(listInterestRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	accruals, err := server.store.ListInterestAccruals(ctx.Request().Context(), db.ListInterestAccrualsParams{
+		AccountID: account.ID,
+		Limit:     req.PageSize,
+		Offset:    (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	res := make([]interestAccrualResponse, len(accruals))
+	for i, accrual := range accruals {
+		res[i] = newInterestAccrualResponse(accrual)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+type interestPayoutResponse struct {
+	ID int64 `json:"id"`
+	// PeriodStart and PeriodEnd are the first day paid for and the day after
+	// the last, as YYYY-MM-DD.
+	PeriodStart string `json:"period_start"`
+	PeriodEnd   string `json:"period_end"`
+	// AccruedMicros is the interest accrued over the period, Amount what was
+	// paid in minor units and CarryMicros the fraction left for the next
+	// payout.
+	AccruedMicros int64 `json:"accrued_micros"`
+	Amount        int64 `json:"amount"`
+	CarryMicros   int64 `json:"carry_micros"`
+	// EntryID is the entry on the account, unless nothing was paid.
+	EntryID   *int64    `json:"entry_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newInterestPayoutResponse(payout db.InterestPayout) interestPayoutResponse {
+	res := interestPayoutResponse{
+		ID:            payout.ID,
+		PeriodStart:   payout.PeriodStart.Format(dateFormat),
+		PeriodEnd:     payout.PeriodEnd.Format(dateFormat),
+		AccruedMicros: payout.AccruedMicros,
+		Amount:        payout.Amount,
+		CarryMicros:   payout.CarryMicros,
+		CreatedAt:     payout.CreatedAt,
+	}
+	if payout.EntryID.Valid {
+		res.EntryID = &payout.EntryID.Int64
+	}
+	return res
+}
+
+// listAccountInterestPayouts godoc
+// @Summary List interest payouts
+// @Description Get the interest paid out to a savings account of the authenticated user, latest first, with pagination.
+// @Tags Savings
+// @Produce json
+// @Param id path int true "Account ID"
+// @Param page_id query int true "Page ID for pagination"
+// @Param page_size query int true "Number of payouts per page (min: 5, max: 50)"
+// @Success 200 {array} interestPayoutResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Insufficient Scope"
+// @Failure 404 {object} Problem "Account Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /accounts/{id}/interest/payouts [get]
+func (server *Server) listAccountInterestPayouts(ctx echo.Context) error {
+	account, err := server.authorizedAccount(ctx, db.AccountActionView)
+	if err != nil {
+		return err
+	}
+
+	req := new(listInterestRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	payouts, err := server.store.ListInterestPayouts(ctx.Request().Context(), db.ListInterestPayoutsParams{
+		AccountID: account.ID,
+		Limit:     req.PageSize,
+		Offset:    (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	res := make([]interestPayoutResponse, len(payouts))
+	for i, payout := range payouts {
+		res[i] = newInterestPayoutResponse(payout)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
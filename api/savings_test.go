@@ -0,0 +1,495 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/labstack/echo/v4"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func randomSavingsProduct(createdBy string) db.SavingsProduct {
+	return db.SavingsProduct{
+		ID:              utils.RandomInt(1, 1000),
+		Name:            "Saver " + utils.RandomString(6),
+		Currency:        "EUR",
+		RateBps:         250,
+		DayCount:        db.DayCountACT365,
+		Compounding:     db.CompoundingDaily,
+		PayoutFrequency: db.PayoutMonthly,
+		CreatedBy:       createdBy,
+		CreatedAt:       time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func TestCreateSavingsProductAPI(t *testing.T) {
+	admin := randomAdmin(t)
+	customer, _ := randomUser(t)
+	product := randomSavingsProduct(admin.Username)
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		body          echo.Map
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			user: admin,
+			body: echo.Map{
+				"name":             product.Name,
+				"currency":         product.Currency,
+				"rate_bps":         product.RateBps,
+				"day_count":        product.DayCount,
+				"compounding":      product.Compounding,
+				"payout_frequency": product.PayoutFrequency,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, admin)
+				store.EXPECT().
+					CreateSavingsProduct(gomock.Any(), gomock.Eq(db.CreateSavingsProductParams{
+						Name:            product.Name,
+						Currency:        product.Currency,
+						RateBps:         product.RateBps,
+						DayCount:        product.DayCount,
+						Compounding:     product.Compounding,
+						PayoutFrequency: product.PayoutFrequency,
+						CreatedBy:       admin.Username,
+					})).
+					Times(1).
+					Return(product, nil)
+				expectAuditEvent(store, audit.ActionSavingsProductCreate, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var res db.SavingsProduct
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, product, res)
+			},
+		},
+		{
+			name: "InvalidDayCount",
+			user: admin,
+			body: echo.Map{
+				"name":             product.Name,
+				"currency":         product.Currency,
+				"rate_bps":         product.RateBps,
+				"day_count":        "act/360",
+				"compounding":      product.Compounding,
+				"payout_frequency": product.PayoutFrequency,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, admin)
+				store.EXPECT().CreateSavingsProduct(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name: "NegativeRate",
+			user: admin,
+			body: echo.Map{
+				"name":             product.Name,
+				"currency":         product.Currency,
+				"rate_bps":         -1,
+				"day_count":        product.DayCount,
+				"compounding":      product.Compounding,
+				"payout_frequency": product.PayoutFrequency,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, admin)
+				store.EXPECT().CreateSavingsProduct(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name: "Customer",
+			user: customer,
+			body: echo.Map{
+				"name":             product.Name,
+				"currency":         product.Currency,
+				"rate_bps":         product.RateBps,
+				"day_count":        product.DayCount,
+				"compounding":      product.Compounding,
+				"payout_frequency": product.PayoutFrequency,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, customer)
+				store.EXPECT().CreateSavingsProduct(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeAdminRequired)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/savings/products", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListSavingsProductsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	products := []db.SavingsProduct{randomSavingsProduct("admin"), randomSavingsProduct("admin")}
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	expectUser(store, user)
+	store.EXPECT().
+		ListSavingsProducts(gomock.Any()).
+		Times(1).
+		Return(products, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/savings/products", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var res []db.SavingsProduct
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+	require.Equal(t, products, res)
+}
+
+func TestCreateSavingsAccountAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	product := randomSavingsProduct("admin")
+	account := db.Account{
+		ID:       utils.RandomInt(1, 1000),
+		Owner:    user.Username,
+		Currency: product.Currency,
+		Type:     db.AccountSavings,
+	}
+
+	testCases := []struct {
+		name          string
+		body          echo.Map
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: echo.Map{"currency": product.Currency, "savings_product_id": product.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().
+					GetSavingsProduct(gomock.Any(), gomock.Eq(product.ID)).
+					Times(1).
+					Return(product, nil)
+				store.EXPECT().
+					CreateSavingsAccountTx(gomock.Any(), gomock.Eq(db.CreateSavingsAccountTxParams{
+						Owner:     user.Username,
+						Currency:  product.Currency,
+						ProductID: product.ID,
+					})).
+					Times(1).
+					Return(db.CreateSavingsAccountTxResult{
+						Account:        account,
+						SavingsAccount: db.SavingsAccount{AccountID: account.ID, ProductID: product.ID},
+					}, nil)
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
+				expectAuditEvent(store, audit.ActionAccountCreate, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, account)
+			},
+		},
+		{
+			name: "ProductNotFound",
+			body: echo.Map{"currency": product.Currency, "savings_product_id": product.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().
+					GetSavingsProduct(gomock.Any(), gomock.Eq(product.ID)).
+					Times(1).
+					Return(db.SavingsProduct{}, sql.ErrNoRows)
+				store.EXPECT().CreateSavingsAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, CodeSavingsProductNotFound)
+			},
+		},
+		{
+			name: "CurrencyMismatch",
+			body: echo.Map{"currency": "USD", "savings_product_id": product.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().
+					GetSavingsProduct(gomock.Any(), gomock.Eq(product.ID)).
+					Times(1).
+					Return(product, nil)
+				store.EXPECT().CreateSavingsAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireProblemCode(t, recorder, CodeCurrencyMismatch)
+			},
+		},
+		{
+			name: "AlreadyExists",
+			body: echo.Map{"currency": product.Currency, "savings_product_id": product.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().
+					GetSavingsProduct(gomock.Any(), gomock.Eq(product.ID)).
+					Times(1).
+					Return(product, nil)
+				store.EXPECT().
+					CreateSavingsAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateSavingsAccountTxResult{}, &pq.Error{Code: pqUniqueViolation})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, CodeAccountAlreadyExists)
+			},
+		},
+		{
+			name: "InvalidProductID",
+			body: echo.Map{"currency": product.Currency, "savings_product_id": -1},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().GetSavingsProduct(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/accounts", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListAccountInterestAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	other, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.Type = db.AccountSavings
+	otherAccount := randomAccount(other.Username)
+
+	payoutID := utils.RandomInt(1, 1000)
+	accruals := []db.InterestAccrual{
+		{
+			ID:           2,
+			AccountID:    account.ID,
+			AccrualDate:  time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+			Balance:      1_000_000,
+			Base:         1_000_000,
+			RateBps:      250,
+			DayCount:     db.DayCountACT365,
+			Days:         1,
+			AmountMicros: 68_493_151,
+		},
+		{
+			ID:           1,
+			AccountID:    account.ID,
+			AccrualDate:  time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+			Balance:      1_000_000,
+			Base:         1_000_000,
+			RateBps:      250,
+			DayCount:     db.DayCountACT365,
+			Days:         1,
+			AmountMicros: 68_493_151,
+			PayoutID:     sql.NullInt64{Int64: payoutID, Valid: true},
+		},
+	}
+	payouts := []db.InterestPayout{
+		{
+			ID:            payoutID,
+			AccountID:     account.ID,
+			PeriodStart:   time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+			PeriodEnd:     time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+			AccruedMicros: 1_986_301_379,
+			Amount:        1_986,
+			CarryMicros:   301_379,
+			EntryID:       sql.NullInt64{Int64: 7, Valid: true},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		path          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Accruals",
+			path: fmt.Sprintf("/accounts/%d/interest?page_id=1&page_size=5", account.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					ListInterestAccruals(gomock.Any(), gomock.Eq(db.ListInterestAccrualsParams{
+						AccountID: account.ID,
+						Limit:     5,
+						Offset:    0,
+					})).
+					Times(1).
+					Return(accruals, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res []interestAccrualResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Len(t, res, 2)
+				require.Equal(t, "2024-03-01", res[0].Date)
+				require.False(t, res[0].Paid)
+				require.Nil(t, res[0].PayoutID)
+				require.Equal(t, int64(68_493_151), res[0].AmountMicros)
+				require.True(t, res[1].Paid)
+				require.Equal(t, payoutID, *res[1].PayoutID)
+			},
+		},
+		{
+			name: "Payouts",
+			path: fmt.Sprintf("/accounts/%d/interest/payouts?page_id=2&page_size=5", account.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					ListInterestPayouts(gomock.Any(), gomock.Eq(db.ListInterestPayoutsParams{
+						AccountID: account.ID,
+						Limit:     5,
+						Offset:    5,
+					})).
+					Times(1).
+					Return(payouts, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res []interestPayoutResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Len(t, res, 1)
+				require.Equal(t, "2024-02-01", res[0].PeriodStart)
+				require.Equal(t, "2024-03-01", res[0].PeriodEnd)
+				require.Equal(t, int64(1_986), res[0].Amount)
+				require.Equal(t, int64(301_379), res[0].CarryMicros)
+				require.Equal(t, int64(7), *res[0].EntryID)
+			},
+		},
+		{
+			name: "NotOwned",
+			path: fmt.Sprintf("/accounts/%d/interest?page_id=1&page_size=5", otherAccount.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(otherAccount.ID)).
+					Times(1).
+					Return(otherAccount, nil)
+				expectNoMembership(store)
+				store.EXPECT().ListInterestAccruals(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireProblemCode(t, recorder, CodeAccountNotOwned)
+			},
+		},
+		{
+			name: "InvalidPageSize",
+			path: fmt.Sprintf("/accounts/%d/interest?page_id=1&page_size=500", account.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().ListInterestAccruals(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, tc.path, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/labstack/echo/v4"
+)
+
+// screenSignUp screens the name of a new user. The user is created either
+// way: open hits hold their payments until an analyst reviews them, and
+// payments screen the user again, so an error is only logged.
+func (server *Server) screenSignUp(ctx echo.Context, user db.User) {
+	if _, err := server.screener.Record(ctx.Request().Context(), server.store, db.ScreeningSourceOnboarding, user); err != nil {
+		slog.ErrorContext(ctx.Request().Context(), "cannot screen new user",
+			slog.String("username", user.Username),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// screenPayment screens the sender and the recipient of a payment, whose
+// names or the lists may have changed since they were last screened, and
+// holds the payment while either has an open or confirmed hit.
+func (server *Server) screenPayment(ctx echo.Context, sender db.User, recipient string) error {
+	parties := []db.User{sender}
+	if recipient != sender.Username {
+		user, err := server.store.GetUser(ctx.Request().Context(), recipient)
+		if err != nil {
+			return err
+		}
+		parties = append(parties, user)
+	}
+
+	if _, err := server.screener.Record(ctx.Request().Context(), server.store, db.ScreeningSourcePayment, parties...); err != nil {
+		return err
+	}
+
+	usernames := make([]string, len(parties))
+	for i, party := range parties {
+		usernames[i] = party.Username
+	}
+	blocking, err := server.store.CountBlockingScreeningHits(ctx.Request().Context(), usernames)
+	if err != nil {
+		return err
+	}
+	if blocking > 0 {
+		audit.Record(ctx.Request().Context(), server.store, audit.Event{
+			Action:       audit.ActionPaymentCreate,
+			Outcome:      audit.OutcomeFailure,
+			ResourceType: audit.ResourcePayment,
+			Metadata: map[string]interface{}{
+				"reason":    "screening_hold",
+				"recipient": recipient,
+			},
+		})
+		return newProblem(http.StatusForbidden, CodeScreeningHold, "the payment is held for a compliance review")
+	}
+	return nil
+}
+
+type screeningHitResponse struct {
+	ID           int64      `json:"id"`
+	Username     string     `json:"username"`
+	Source       string     `json:"source"`
+	ScreenedName string     `json:"screened_name"`
+	List         string     `json:"list"`
+	EntryID      string     `json:"entry_id"`
+	EntryName    string     `json:"entry_name"`
+	Score        float64    `json:"score"`
+	Status       string     `json:"status"`
+	ReviewedBy   string     `json:"reviewed_by,omitempty"`
+	ReviewedAt   *time.Time `json:"reviewed_at,omitempty"`
+	ReviewNote   string     `json:"review_note,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func newScreeningHitResponse(hit db.ScreeningHit) screeningHitResponse {
+	res := screeningHitResponse{
+		ID:           hit.ID,
+		Username:     hit.Username,
+		Source:       hit.Source,
+		ScreenedName: hit.ScreenedName,
+		List:         hit.List,
+		EntryID:      hit.EntryID,
+		EntryName:    hit.EntryName,
+		Score:        hit.Score,
+		Status:       hit.Status,
+		ReviewedBy:   hit.ReviewedBy.String,
+		ReviewNote:   hit.ReviewNote,
+		CreatedAt:    hit.CreatedAt,
+	}
+	if hit.ReviewedAt.Valid {
+		res.ReviewedAt = &hit.ReviewedAt.Time
+	}
+	return res
+}
+
+type listScreeningHitsRequest struct {
+	Status   string `query:"status" validate:"required,oneof=open cleared confirmed"`
+	PageID   int32  `query:"page_id" validate:"required,min=1"`
+	PageSize int32  `query:"page_size" validate:"required,min=5,max=50"`
+}
+
+// listScreeningHits godoc
+// @Summary List screening hits
+// @Description List the names that matched a sanctions list by status, oldest first. Support staff only.
+// @Tags Screening
+// @Produce json
+// @Param status query string true "Status" Enums(open, cleared, confirmed)
+// @Param page_id query int true "Page ID for pagination"
+// @Param page_size query int true "Number of hits per page (min: 5, max: 50)"
+// @Success 200 {array} screeningHitResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Support Required"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /screening/hits [get]
+func (server *Server) listScreeningHits(ctx echo.Context) error {
+	req := new(listScreeningHitsRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	hits, err := server.store.ListScreeningHits(ctx.Request().Context(), db.ListScreeningHitsParams{
+		Status: req.Status,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	res := make([]screeningHitResponse, len(hits))
+	for i, hit := range hits {
+		res[i] = newScreeningHitResponse(hit)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+type getScreeningHitRequest struct {
+	ID int64 `param:"id" validate:"required,min=1"`
+}
+
+// getScreeningHit godoc
+// @Summary Get a screening hit
+// @Description Support staff only.
+// @Tags Screening
+// @Produce json
+// @Param id path int true "Hit ID"
+// @Success 200 {object} screeningHitResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Support Required"
+// @Failure 404 {object} Problem "Screening Hit Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /screening/hits/{id} [get]
+func (server *Server) getScreeningHit(ctx echo.Context) error {
+	req := new(getScreeningHitRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	hit, err := server.store.GetScreeningHit(ctx.Request().Context(), req.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return newProblem(http.StatusNotFound, CodeScreeningHitNotFound, fmt.Sprintf("screening hit [%d] not found", req.ID))
+		}
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, newScreeningHitResponse(hit))
+}
+
+type reviewScreeningHitRequest struct {
+	ID   int64  `param:"id" validate:"required,min=1"`
+	Note string `json:"note" validate:"required,max=1000"`
+}
+
+type reviewScreeningHitResponse struct {
+	Hit            screeningHitResponse `json:"hit"`
+	FrozenAccounts []db.Account         `json:"frozen_accounts"`
+}
+
+// clearScreeningHit godoc
+// @Summary Clear a screening hit
+// @Description Close an open hit as a false positive, which releases the payments it held. The user is not flagged for the same entry again unless their name changes. Support staff only.
+// @Tags Screening
+// @Accept json
+// @Produce json
+// @Param id path int true "Hit ID"
+// @Param request body reviewScreeningHitRequest true "Why the hit is a false positive"
+// @Success 200 {object} reviewScreeningHitResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Support Required or Self Review"
+// @Failure 404 {object} Problem "Screening Hit Not Found"
+// @Failure 409 {object} Problem "Screening Hit Not Open"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /screening/hits/{id}/clear [post]
+func (server *Server) clearScreeningHit(ctx echo.Context) error {
+	return server.reviewScreeningHit(ctx, false)
+}
+
+// confirmScreeningHit godoc
+// @Summary Confirm a screening hit
+// @Description Confirm that the user is the listed person or organisation. This disables the user and freezes all their accounts. Support staff only.
+// @Tags Screening
+// @Accept json
+// @Produce json
+// @Param id path int true "Hit ID"
+// @Param request body reviewScreeningHitRequest true "Evidence for the match"
+// @Success 200 {object} reviewScreeningHitResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Support Required or Self Review"
+// @Failure 404 {object} Problem "Screening Hit Not Found"
+// @Failure 409 {object} Problem "Screening Hit Not Open"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /screening/hits/{id}/confirm [post]
+func (server *Server) confirmScreeningHit(ctx echo.Context) error {
+	return server.reviewScreeningHit(ctx, true)
+}
+
+func (server *Server) reviewScreeningHit(ctx echo.Context, confirm bool) error {
+	req := new(reviewScreeningHitRequest)
+	if err := ctx.Bind(req); err != nil {
+		return err
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return err
+	}
+
+	action := audit.ActionScreeningClear
+	if confirm {
+		action = audit.ActionScreeningConfirm
+	}
+
+	result, err := server.store.ReviewScreeningHitTx(ctx.Request().Context(), db.ReviewScreeningHitTxParams{
+		ID:         req.ID,
+		ReviewedBy: authUser(ctx).Username,
+		Confirm:    confirm,
+		Note:       req.Note,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return newProblem(http.StatusNotFound, CodeScreeningHitNotFound, fmt.Sprintf("screening hit [%d] not found", req.ID))
+		case errors.Is(err, db.ErrScreeningSelfReview):
+			audit.Record(ctx.Request().Context(), server.store, audit.Event{
+				Action:       action,
+				Outcome:      audit.OutcomeFailure,
+				ResourceType: audit.ResourceScreeningHit,
+				ResourceID:   strconv.FormatInt(req.ID, 10),
+				Metadata:     map[string]interface{}{"reason": "self_review"},
+			})
+			return newProblem(http.StatusForbidden, CodeSelfReview, err.Error())
+		case errors.Is(err, db.ErrScreeningHitNotOpen):
+			return newProblem(http.StatusConflict, CodeScreeningHitNotOpen, err.Error())
+		}
+		return err
+	}
+
+	frozen := make([]int64, len(result.FrozenAccounts))
+	for i, account := range result.FrozenAccounts {
+		frozen[i] = account.ID
+	}
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Action:       action,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceScreeningHit,
+		ResourceID:   strconv.FormatInt(req.ID, 10),
+		Metadata: map[string]interface{}{
+			"username":        result.Hit.Username,
+			"list":            result.Hit.List,
+			"entry_id":        result.Hit.EntryID,
+			"frozen_accounts": frozen,
+		},
+	})
+
+	return ctx.JSON(http.StatusOK, reviewScreeningHitResponse{
+		Hit:            newScreeningHitResponse(result.Hit),
+		FrozenAccounts: result.FrozenAccounts,
+	})
+}
@@ -0,0 +1,396 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/screening"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func randomScreeningHit(user db.User) db.ScreeningHit {
+	return db.ScreeningHit{
+		ID:           utils.RandomInt(1, 1000),
+		Username:     user.Username,
+		Source:       db.ScreeningSourceOnboarding,
+		ScreenedName: user.FullName,
+		List:         screening.ListOFAC,
+		EntryID:      strconv.FormatInt(utils.RandomInt(1, 100000), 10),
+		EntryName:    strings.ToUpper(user.FullName),
+		Score:        0.97,
+		Status:       db.ScreeningOpen,
+		CreatedAt:    time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func TestScreenPaymentAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	recipient, _ := randomUser(t)
+	fromAccount := randomAccount(user.Username)
+	toAccount := randomAccount(recipient.Username)
+	toAccount.ID = fromAccount.ID + 1
+	toAccount.Currency = fromAccount.Currency
+	body := `{"from_account_id":` + strconv.FormatInt(fromAccount.ID, 10) +
+		`,"to_account_id":` + strconv.FormatInt(toAccount.ID, 10) +
+		`,"amount":10,"currency":"` + fromAccount.Currency + `"}`
+
+	testCases := []struct {
+		name          string
+		entries       []screening.Entry
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "NoMatch",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				expectUser(store, recipient)
+				store.EXPECT().RecordScreeningHitsTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					CountBlockingScreeningHits(gomock.Any(), gomock.Eq([]string{user.Username, recipient.Username})).
+					Times(1).
+					Return(int64(0), nil)
+				expectFraudDecision(store, user, db.FraudAllow, db.FraudPassed)
+				store.EXPECT().
+					PaymentTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PaymentTxResult{Payment: db.Payment{ID: 1}}, nil)
+				store.EXPECT().SetFraudDecisionPayment(gomock.Any(), gomock.Any()).Times(1)
+				expectAuditEvent(store, audit.ActionPaymentCreate, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "RecipientMatches",
+			entries: []screening.Entry{
+				{List: screening.ListOFAC, ID: "36", Type: "Individual", Names: []string{strings.ToUpper(recipient.FullName)}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				expectUser(store, recipient)
+				store.EXPECT().
+					RecordScreeningHitsTx(gomock.Any(), gomock.Cond(func(x any) bool {
+						hits, ok := x.([]db.UpsertScreeningHitParams)
+						return ok && len(hits) == 1 &&
+							hits[0].Username == recipient.Username &&
+							hits[0].Source == db.ScreeningSourcePayment &&
+							hits[0].EntryID == "36"
+					})).
+					Times(1).
+					Return([]db.ScreeningHit{randomScreeningHit(recipient)}, nil)
+				store.EXPECT().
+					CountBlockingScreeningHits(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(1), nil)
+				expectAuditEvent(store, audit.ActionPaymentCreate, audit.OutcomeFailure)
+				store.EXPECT().PaymentTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeScreeningHold)
+			},
+		},
+		{
+			name: "ConfirmedHit",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, user)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				expectUser(store, recipient)
+				store.EXPECT().
+					CountBlockingScreeningHits(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(1), nil)
+				expectAuditEvent(store, audit.ActionPaymentCreate, audit.OutcomeFailure)
+				store.EXPECT().PaymentTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeScreeningHold)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.screener = screening.NewScreener(0, tc.entries)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/payments", strings.NewReader(body))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListScreeningHitsAPI(t *testing.T) {
+	support := randomSupport(t)
+	customer, _ := randomUser(t)
+	hit := randomScreeningHit(customer)
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			user:  support,
+			query: "status=open&page_id=2&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().
+					ListScreeningHits(gomock.Any(), gomock.Eq(db.ListScreeningHitsParams{Status: db.ScreeningOpen, Limit: 5, Offset: 5})).
+					Times(1).
+					Return([]db.ScreeningHit{hit}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res []screeningHitResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Len(t, res, 1)
+				require.Equal(t, hit.ID, res[0].ID)
+				require.Equal(t, hit.EntryName, res[0].EntryName)
+				require.Nil(t, res[0].ReviewedAt)
+			},
+		},
+		{
+			name:  "InvalidStatus",
+			user:  support,
+			query: "status=closed&page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().ListScreeningHits(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name:  "Customer",
+			user:  customer,
+			query: "status=open&page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, customer)
+				store.EXPECT().ListScreeningHits(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeSupportRequired)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/screening/hits?"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestReviewScreeningHitAPI(t *testing.T) {
+	support := randomSupport(t)
+	customer, _ := randomUser(t)
+	hit := randomScreeningHit(customer)
+	path := "/screening/hits/" + strconv.FormatInt(hit.ID, 10)
+
+	reviewed := func(status string) db.ScreeningHit {
+		reviewed := hit
+		reviewed.Status = status
+		reviewed.ReviewedBy = sql.NullString{String: support.Username, Valid: true}
+		reviewed.ReviewedAt = sql.NullTime{Time: time.Now().UTC(), Valid: true}
+		reviewed.ReviewNote = "checked the date of birth"
+		return reviewed
+	}
+	frozen := randomAccount(customer.Username)
+	frozen.Frozen = true
+
+	testCases := []struct {
+		name          string
+		path          string
+		body          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Clear",
+			path: path + "/clear",
+			body: `{"note":"checked the date of birth"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().
+					ReviewScreeningHitTx(gomock.Any(), gomock.Eq(db.ReviewScreeningHitTxParams{
+						ID:         hit.ID,
+						ReviewedBy: support.Username,
+						Note:       "checked the date of birth",
+					})).
+					Times(1).
+					Return(db.ReviewScreeningHitTxResult{Hit: reviewed(db.ScreeningCleared)}, nil)
+				expectAuditEvent(store, audit.ActionScreeningClear, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res reviewScreeningHitResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, db.ScreeningCleared, res.Hit.Status)
+				require.Equal(t, support.Username, res.Hit.ReviewedBy)
+				require.NotNil(t, res.Hit.ReviewedAt)
+				require.Empty(t, res.FrozenAccounts)
+			},
+		},
+		{
+			name: "Confirm",
+			path: path + "/confirm",
+			body: `{"note":"checked the date of birth"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().
+					ReviewScreeningHitTx(gomock.Any(), gomock.Eq(db.ReviewScreeningHitTxParams{
+						ID:         hit.ID,
+						ReviewedBy: support.Username,
+						Confirm:    true,
+						Note:       "checked the date of birth",
+					})).
+					Times(1).
+					Return(db.ReviewScreeningHitTxResult{
+						Hit:            reviewed(db.ScreeningConfirmed),
+						FrozenAccounts: []db.Account{frozen},
+					}, nil)
+				expectAuditEvent(store, audit.ActionScreeningConfirm, audit.OutcomeSuccess)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res reviewScreeningHitResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, db.ScreeningConfirmed, res.Hit.Status)
+				require.Len(t, res.FrozenAccounts, 1)
+				require.True(t, res.FrozenAccounts[0].Frozen)
+			},
+		},
+		{
+			name: "NoNote",
+			path: path + "/clear",
+			body: `{}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().ReviewScreeningHitTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireProblemCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name: "SelfReview",
+			path: path + "/clear",
+			body: `{"note":"that isn't me"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().
+					ReviewScreeningHitTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReviewScreeningHitTxResult{}, db.ErrScreeningSelfReview)
+				expectAuditEvent(store, audit.ActionScreeningClear, audit.OutcomeFailure)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireProblemCode(t, recorder, CodeSelfReview)
+			},
+		},
+		{
+			name: "NotOpen",
+			path: path + "/confirm",
+			body: `{"note":"checked the date of birth"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().
+					ReviewScreeningHitTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReviewScreeningHitTxResult{}, db.ErrScreeningHitNotOpen)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireProblemCode(t, recorder, CodeScreeningHitNotOpen)
+			},
+		},
+		{
+			name: "NotFound",
+			path: path + "/confirm",
+			body: `{"note":"checked the date of birth"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, support)
+				store.EXPECT().
+					ReviewScreeningHitTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReviewScreeningHitTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireProblemCode(t, recorder, CodeScreeningHitNotFound)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, support.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -1,17 +1,29 @@
 package api
 
 import (
+	"context"
 	"fmt"
-	"net/http"
+	"reflect"
+	"strings"
+	"sync"
 
+	"github.com/danielmoisa/neobank/blob"
+	"github.com/danielmoisa/neobank/cards"
 	db "github.com/danielmoisa/neobank/db/sqlc"
 	_ "github.com/danielmoisa/neobank/docs"
+	"github.com/danielmoisa/neobank/events"
+	"github.com/danielmoisa/neobank/fraud"
+	"github.com/danielmoisa/neobank/mail"
+	"github.com/danielmoisa/neobank/metrics"
+	"github.com/danielmoisa/neobank/ratelimit"
+	"github.com/danielmoisa/neobank/screening"
 	"github.com/danielmoisa/neobank/tokens"
 	"github.com/danielmoisa/neobank/utils"
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	echoSwagger "github.com/swaggo/echo-swagger"
+	"go.opentelemetry.io/otel"
 )
 
 type Server struct {
@@ -19,56 +31,249 @@ type Server struct {
 	router     *echo.Echo
 	tokenMaker tokens.Maker
 	config     utils.Config
+	broker     events.Broker
+	metrics    *metrics.Metrics
+	limiter    ratelimit.Limiter
+	mailer     mail.Mailer
+	blobs      blob.Storage
+	screener   *screening.Screener
+	fraud      *fraud.Engine
+	cardSwitch *cards.Switch
+	// cardBIN is the BIN new cards are issued under.
+	cardBIN string
+
+	// done is closed when the server starts shutting down, which ends the
+	// long-lived event streams so they don't hold up the drain.
+	done         chan struct{}
+	shutdownOnce sync.Once
 }
 
-func NewServer(config utils.Config, store db.Store) (*Server, error) {
-	tokenMaker, err := tokens.NewPasetoMaker(config.TokenSymmetricKey)
+func NewServer(config utils.Config, store db.Store, broker events.Broker, metrics *metrics.Metrics, limiter ratelimit.Limiter, mailer mail.Mailer, blobs blob.Storage, screener *screening.Screener, fraud *fraud.Engine) (*Server, error) {
+	tokenMaker, err := tokens.New(config)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
 
-	server := &Server{store: store, tokenMaker: tokenMaker, config: config}
+	cardBIN := config.CardBIN
+	if cardBIN == "" {
+		cardBIN = cards.DefaultBIN
+	}
+	if _, err := cards.GeneratePAN(cardBIN); err != nil {
+		return nil, fmt.Errorf("cannot issue cards under CARD_BIN: %w", err)
+	}
+	holdTTL := config.CardHoldTTL
+	if holdTTL <= 0 {
+		holdTTL = cards.DefaultHoldTTL
+	}
+	extractIP, err := ipExtractor(config.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse TRUSTED_PROXIES: %w", err)
+	}
+
+	server := &Server{
+		store:      store,
+		tokenMaker: tokenMaker,
+		config:     config,
+		broker:     broker,
+		metrics:    metrics,
+		limiter:    limiter,
+		mailer:     mailer,
+		blobs:      blobs,
+		screener:   screener,
+		fraud:      fraud,
+		cardSwitch: cards.NewSwitch(store, broker, holdTTL),
+		cardBIN:    cardBIN,
+		done:       make(chan struct{}),
+	}
 	e := echo.New()
+	e.IPExtractor = extractIP
 
-	// Register validator
-	e.Validator = &CustomValidator{validator: validator.New()}
+	// Register validator and problem+json error handler
+	e.Validator = newCustomValidator()
+	e.HTTPErrorHandler = errorHandler
 
 	// Middleware
-	e.Use(middleware.Logger())
+	e.Use(requestContextMiddleware())
+	e.Use(tracingMiddleware(otel.GetTracerProvider(), otel.GetTextMapPropagator()))
+	e.Use(accessLogMiddleware())
+	e.Use(metricsMiddleware(server.metrics))
 	e.Use(middleware.Recover())
 
 	// Routes
-	e.POST("/users", server.createUser)
-	e.POST("/users/login", server.loginUser)
+	e.POST("/users", server.createUser, server.rateLimit("signup", ratelimit.AuthPolicy, byClientIP))
+	e.POST("/users/login", server.loginUser, server.rateLimit("login", ratelimit.AuthPolicy, byClientIP))
+	e.POST("/users/verify-email", server.verifyEmail, server.rateLimit("verify_email", ratelimit.AuthPolicy, byClientIP))
+	e.POST("/users/password/forgot", server.forgotPassword, server.rateLimit("password_reset", ratelimit.AuthPolicy, byClientIP))
+	e.POST("/users/password/reset", server.resetPassword, server.rateLimit("password_reset", ratelimit.AuthPolicy, byClientIP))
+	e.POST("/users/email/confirm", server.confirmEmailChange, server.rateLimit("verify_email", ratelimit.AuthPolicy, byClientIP))
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
+	e.GET("/metrics", echo.WrapHandler(server.metrics.Handler()))
+	e.GET("/.well-known/jwks.json", server.jwks)
+	e.GET("/healthz", server.healthz)
+	e.GET("/readyz", server.readyz)
+	e.POST("/oauth/token", server.createOAuthToken, server.rateLimit("oauth_token", ratelimit.AuthPolicy, byClientIP))
 
 	// Protected routes
-	e.POST("/accounts", server.createAccount, authMiddleware(server.tokenMaker))
-	e.GET("/accounts/:id", server.getAccount, authMiddleware(server.tokenMaker))
-	e.GET("/accounts", server.listAccounts, authMiddleware(server.tokenMaker))
-	e.POST("/payments", server.createPayment, authMiddleware(server.tokenMaker))
+	userAuth := []echo.MiddlewareFunc{authMiddleware(server.tokenMaker, server.store), server.rateLimit("user", ratelimit.UserPolicy, byUser)}
+	// routes that can be called with an API key or by a third-party app name
+	// the scopes they need
+	scoped := func(scopes ...string) []echo.MiddlewareFunc {
+		return []echo.MiddlewareFunc{authMiddleware(server.tokenMaker, server.store, scopes...), server.rateLimit("user", ratelimit.UserPolicy, byUser)}
+	}
+	e.POST("/accounts", server.createAccount, scoped(ScopeAccountsWrite)...)
+	e.GET("/accounts/:id", server.getAccount, scoped(ScopeAccountsRead)...)
+	e.GET("/accounts", server.listAccounts, scoped(ScopeAccountsRead)...)
+	e.GET("/accounts/:id/entries", server.listAccountEntries, scoped(ScopeAccountsRead)...)
+	e.GET("/accounts/:id/stream", server.streamAccount, scoped(ScopeAccountsRead)...)
+	e.GET("/accounts/:id/ws", server.streamAccountWebSocket, scoped(ScopeAccountsRead)...)
+	e.GET("/accounts/:id/interest", server.listAccountInterest, scoped(ScopeAccountsRead)...)
+	e.GET("/accounts/:id/interest/payouts", server.listAccountInterestPayouts, scoped(ScopeAccountsRead)...)
+	e.POST("/accounts/:id/pockets", server.createPocket, scoped(ScopeAccountsWrite)...)
+	e.GET("/accounts/:id/pockets", server.listPockets, scoped(ScopeAccountsRead)...)
+	e.POST("/pockets/moves", server.movePocket, scoped(ScopeAccountsWrite)...)
+	e.GET("/pockets/:id", server.getPocket, scoped(ScopeAccountsRead)...)
+	e.PATCH("/pockets/:id", server.updatePocket, scoped(ScopeAccountsWrite)...)
+	e.GET("/pockets/:id/moves", server.listPocketMoves, scoped(ScopeAccountsRead)...)
+	e.GET("/accounts/:id/members", server.listAccountMembers, scoped(ScopeAccountsRead)...)
+	e.DELETE("/accounts/:id/members/:username", server.removeAccountMember, scoped(ScopeAccountsWrite)...)
+	e.POST("/accounts/:id/invitations", server.inviteAccountMember, scoped(ScopeAccountsWrite)...)
+	e.GET("/accounts/:id/invitations", server.listAccountInvitations, scoped(ScopeAccountsRead)...)
+	e.GET("/invitations", server.listUserInvitations, scoped(ScopeAccountsRead)...)
+	e.POST("/invitations/:id/accept", server.acceptAccountInvitation, scoped(ScopeAccountsWrite)...)
+	e.POST("/invitations/:id/decline", server.declineAccountInvitation, scoped(ScopeAccountsWrite)...)
+	e.DELETE("/invitations/:id", server.revokeAccountInvitation, scoped(ScopeAccountsWrite)...)
+	e.POST("/payments", server.createPayment, authMiddleware(server.tokenMaker, server.store, ScopePaymentsWrite), server.rateLimit("payments", ratelimit.PaymentPolicy, byUser))
+	e.GET("/notifications", server.listNotifications, scoped(ScopeNotificationsRead)...)
+	e.POST("/users/verify-email/resend", server.resendVerificationEmail, userAuth...)
+	e.GET("/users/me", server.getCurrentUser, userAuth...)
+	e.PATCH("/users/me", server.updateCurrentUser, userAuth...)
+	e.POST("/users/me/password", server.changePassword, authMiddleware(server.tokenMaker, server.store), server.rateLimit("password_change", ratelimit.AuthPolicy, byUser))
+	e.POST("/api-keys", server.createAPIKey, userAuth...)
+	e.GET("/api-keys", server.listAPIKeys, userAuth...)
+	e.DELETE("/api-keys/:id", server.revokeAPIKey, userAuth...)
+	// only the user themselves, never an app, may grant or revoke consents
+	e.GET("/oauth/authorize", server.getOAuthAuthorization, userAuth...)
+	e.POST("/oauth/authorize", server.decideOAuthAuthorization, userAuth...)
+	e.GET("/oauth/consents", server.listOAuthConsents, userAuth...)
+	e.DELETE("/oauth/consents/:id", server.revokeOAuthConsent, userAuth...)
+	e.GET("/kyc", server.getKYC, userAuth...)
+	e.PUT("/kyc/profile", server.saveKYCProfile, userAuth...)
+	e.POST("/kyc/documents", server.uploadKYCDocument, userAuth...)
+	e.GET("/kyc/documents/:id", server.getKYCDocument, userAuth...)
+	e.POST("/kyc/submit", server.submitKYC, userAuth...)
+	e.GET("/savings/products", server.listSavingsProducts, userAuth...)
+	e.GET("/loans/products", server.listLoanProducts, userAuth...)
+	e.POST("/loans", server.createLoan, userAuth...)
+	e.GET("/loans", server.listLoans, userAuth...)
+	e.GET("/loans/:id", server.getLoan, userAuth...)
+	e.POST("/loans/:id/repay", server.repayLoan, userAuth...)
+	e.POST("/cards", server.createCard, userAuth...)
+	e.GET("/cards", server.listCards, userAuth...)
+	e.GET("/cards/:id", server.getCard, userAuth...)
+	e.PATCH("/cards/:id", server.updateCard, userAuth...)
+	e.GET("/cards/:id/authorizations", server.listCardAuthorizations, userAuth...)
+
+	// Support routes, open to admins as well
+	supportAuth := []echo.MiddlewareFunc{
+		authMiddleware(server.tokenMaker, server.store),
+		server.rateLimit("user", ratelimit.UserPolicy, byUser),
+		supportMiddleware(),
+	}
+	e.GET("/kyc/reviews", server.listKYCReviews, supportAuth...)
+	e.GET("/kyc/reviews/:username", server.getKYCReview, supportAuth...)
+	e.POST("/kyc/reviews/:username/approve", server.approveKYC, supportAuth...)
+	e.POST("/kyc/reviews/:username/reject", server.rejectKYC, supportAuth...)
+	e.GET("/screening/hits", server.listScreeningHits, supportAuth...)
+	e.GET("/screening/hits/:id", server.getScreeningHit, supportAuth...)
+	e.POST("/screening/hits/:id/clear", server.clearScreeningHit, supportAuth...)
+	e.POST("/screening/hits/:id/confirm", server.confirmScreeningHit, supportAuth...)
+	e.GET("/fraud/decisions", server.listFraudDecisions, supportAuth...)
+	e.GET("/fraud/decisions/:id", server.getFraudDecision, supportAuth...)
+	e.POST("/fraud/decisions/:id/approve", server.approveFraudDecision, supportAuth...)
+	e.POST("/fraud/decisions/:id/reject", server.rejectFraudDecision, supportAuth...)
+	e.GET("/aml/alerts", server.listAMLAlerts, supportAuth...)
+	e.GET("/aml/alerts/:id", server.getAMLAlert, supportAuth...)
+	e.POST("/aml/alerts/:id/assign", server.assignAMLAlert, supportAuth...)
+	e.POST("/aml/alerts/:id/comments", server.commentAMLAlert, supportAuth...)
+	e.POST("/aml/alerts/:id/close", server.closeAMLAlert, supportAuth...)
+	e.POST("/aml/alerts/:id/escalate", server.escalateAMLAlert, supportAuth...)
+	e.GET("/aml/alerts/:id/sar", server.exportAMLAlertSAR, supportAuth...)
+	e.GET("/aml/jobs", server.listAMLJobRuns, supportAuth...)
+
+	// Admin routes
+	adminAuth := []echo.MiddlewareFunc{
+		authMiddleware(server.tokenMaker, server.store),
+		server.rateLimit("user", ratelimit.UserPolicy, byUser),
+		adminMiddleware(),
+	}
+	e.POST("/adjustments", server.proposeAdjustment, adminAuth...)
+	e.GET("/adjustments", server.listAdjustments, adminAuth...)
+	e.POST("/adjustments/:id/approve", server.approveAdjustment, adminAuth...)
+	e.POST("/adjustments/:id/reject", server.rejectAdjustment, adminAuth...)
+	e.POST("/oauth/clients", server.createOAuthClient, adminAuth...)
+	e.GET("/oauth/clients", server.listOAuthClients, adminAuth...)
+	e.POST("/savings/products", server.createSavingsProduct, adminAuth...)
+	e.POST("/loans/products", server.createLoanProduct, adminAuth...)
+	e.POST("/cards/switch", server.simulateCardMessage, adminAuth...)
 
 	server.router = e
 	return server, nil
 }
 
-// Start runs the HTTP server on a specific address.
+func (server *Server) rateLimit(route string, policy ratelimit.Policy, key rateLimitKey) echo.MiddlewareFunc {
+	return rateLimitMiddleware(server.limiter, server.metrics, route, policy, key)
+}
+
+// lockoutPolicy locks users out after LOGIN_MAX_ATTEMPTS failed logins.
+func (server *Server) lockoutPolicy() db.LockoutPolicy {
+	return db.LockoutPolicy{
+		MaxAttempts:  server.config.LoginMaxAttempts,
+		BaseDuration: server.config.LoginLockoutBase,
+		MaxDuration:  server.config.LoginLockoutMax,
+	}
+}
+
+// Start runs the HTTP server on a specific address. It returns
+// http.ErrServerClosed after Shutdown.
 func (server *Server) Start(address string) error {
 	return server.router.Start(address)
 }
 
+// Shutdown stops accepting connections, closes the event streams and waits
+// for in-flight requests to finish until ctx is done.
+func (server *Server) Shutdown(ctx context.Context) error {
+	server.shutdownOnce.Do(func() {
+		close(server.done)
+	})
+	return server.router.Shutdown(ctx)
+}
+
 // CustomValidator represents a custom validator for Echo
 type CustomValidator struct {
 	validator *validator.Validate
 }
 
-func (cv *CustomValidator) Validate(i interface{}) error {
-	if err := cv.validator.Struct(i); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
-	}
-	return nil
+// newCustomValidator reports fields by the name the client used in the request.
+func newCustomValidator() *CustomValidator {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "query", "param"} {
+			name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return field.Name
+	})
+
+	return &CustomValidator{validator: validate}
 }
 
-type ErrorResponse struct {
-	Error string `json:"error"`
+// Validate returns validator.ValidationErrors, which errorHandler turns into
+// per-field problem details.
+func (cv *CustomValidator) Validate(i interface{}) error {
+	return cv.validator.Struct(i)
 }
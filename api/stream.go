@@ -0,0 +1,164 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/events"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+)
+
+const streamHeartbeatInterval = 15 * time.Second
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// streamAccount godoc
+// @Summary Stream account changes
+// @Description Push balance changes and new entries of an account as Server-Sent Events.
+// @Tags Accounts
+// @Produce text/event-stream
+// @Param id path int true "Account ID"
+// @Success 200 {object} events.Event
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 404 {object} Problem "Account Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /accounts/{id}/stream [get]
+func (server *Server) streamAccount(ctx echo.Context) error {
+	account, err := server.authorizedAccount(ctx, db.AccountActionView)
+	if err != nil {
+		return err
+	}
+
+	eventsCh, unsubscribe := server.broker.Subscribe(account.ID)
+	defer unsubscribe()
+
+	res := ctx.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.WriteHeader(http.StatusOK)
+
+	snapshot := events.Event{
+		Type:      events.EventTypeBalance,
+		AccountID: account.ID,
+		Account:   &account,
+		CreatedAt: time.Now(),
+	}
+	if err := writeServerSentEvent(res, snapshot); err != nil {
+		return nil
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Request().Context().Done():
+			return nil
+		case <-server.done:
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		case event, ok := <-eventsCh:
+			if !ok {
+				return nil
+			}
+			if err := writeServerSentEvent(res, event); err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+func writeServerSentEvent(res *echo.Response, event events.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+		return err
+	}
+	res.Flush()
+	return nil
+}
+
+// streamAccountWebSocket godoc
+// @Summary Stream account changes over WebSocket
+// @Description Push balance changes and new entries of an account as JSON WebSocket messages.
+// @Tags Accounts
+// @Param id path int true "Account ID"
+// @Success 101 {object} events.Event
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 404 {object} Problem "Account Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /accounts/{id}/ws [get]
+func (server *Server) streamAccountWebSocket(ctx echo.Context) error {
+	account, err := server.authorizedAccount(ctx, db.AccountActionView)
+	if err != nil {
+		return err
+	}
+
+	conn, err := upgrader.Upgrade(ctx.Response(), ctx.Request(), nil)
+	if err != nil {
+		return nil
+	}
+	defer conn.Close()
+
+	eventsCh, unsubscribe := server.broker.Subscribe(account.ID)
+	defer unsubscribe()
+
+	// the client never sends anything useful; reading only detects the close
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	err = conn.WriteJSON(events.Event{
+		Type:      events.EventTypeBalance,
+		AccountID: account.ID,
+		Account:   &account,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return nil
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return nil
+		case <-server.done:
+			return nil
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second)); err != nil {
+				return nil
+			}
+		case event, ok := <-eventsCh:
+			if !ok {
+				return nil
+			}
+			if err := conn.WriteJSON(event); err != nil {
+				return nil
+			}
+		}
+	}
+}
@@ -1,9 +1,12 @@
 package api
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
 	"time"
 
+	"github.com/danielmoisa/neobank/audit"
 	db "github.com/danielmoisa/neobank/db/sqlc"
 	"github.com/danielmoisa/neobank/utils"
 	"github.com/labstack/echo/v4"
@@ -24,22 +27,24 @@ type createUserRequest struct {
 // @Produce json
 // @Param request body createUserRequest true "Request body for creating a user"
 // @Success 201 {object} userResponse
-// @Failure 400 {object} ErrorResponse "Bad Request"
-// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 409 {object} Problem "User Already Exists"
+// @Failure 429 {object} Problem "Rate Limited"
+// @Failure 500 {object} Problem "Internal Server Error"
 // @Router /users [post]
 func (server *Server) createUser(ctx echo.Context) error {
 	req := new(createUserRequest)
 	if err := ctx.Bind(req); err != nil {
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return err
 	}
 
 	if err := ctx.Validate(req); err != nil {
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return err
 	}
 
 	hash, err := utils.HashPassword(req.Password)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return err
 	}
 
 	user, err := server.store.CreateUser(ctx.Request().Context(), db.CreateUserParams{
@@ -49,9 +54,23 @@ func (server *Server) createUser(ctx echo.Context) error {
 		HashedPassword: hash,
 	})
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		if isUniqueViolation(err) {
+			return newProblem(http.StatusConflict, CodeUserAlreadyExists, "username or email is already taken")
+		}
+		return err
 	}
 
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Actor:        user.Username,
+		Action:       audit.ActionUserCreate,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceUser,
+		ResourceID:   user.Username,
+	})
+
+	server.sendVerificationEmailAfterSignUp(ctx, user)
+	server.screenSignUp(ctx, user)
+
 	res := newUserResponse(user)
 	return ctx.JSON(http.StatusCreated, res)
 }
@@ -65,6 +84,9 @@ type userResponse struct {
 	Username          string    `json:"username"`
 	FullName          string    `json:"full_name"`
 	Email             string    `json:"email"`
+	Role              string    `json:"role"`
+	EmailVerified     bool      `json:"email_verified"`
+	KYCStatus         string    `json:"kyc_status"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
 }
@@ -79,6 +101,9 @@ func newUserResponse(user db.User) userResponse {
 		Username:          user.Username,
 		FullName:          user.FullName,
 		Email:             user.Email,
+		Role:              user.Role,
+		EmailVerified:     !user.EmailVerifiedAt.IsZero(),
+		KYCStatus:         user.KYCStatus,
 		PasswordChangedAt: user.PasswordChangedAt,
 		CreatedAt:         user.CreatedAt,
 	}
@@ -91,28 +116,58 @@ func newUserResponse(user db.User) userResponse {
 // @Accept json
 // @Produce json
 // @Param request body loginUserRequest true "Request body for login in a user"
-// @Success 201 {object} loginUserResponse
-// @Failure 400 {object} ErrorResponse "Bad Request"
-// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Success 200 {object} loginUserResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Invalid Credentials"
+// @Failure 403 {object} Problem "User Disabled"
+// @Failure 429 {object} Problem "Rate Limited"
+// @Failure 500 {object} Problem "Internal Server Error"
 // @Router /users/login [post]
 func (server *Server) loginUser(ctx echo.Context) error {
 	req := new(loginUserRequest)
 	if err := ctx.Bind(req); err != nil {
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return err
 	}
 
 	if err := ctx.Validate(req); err != nil {
-		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return err
 	}
 
 	user, err := server.store.GetUser(ctx.Request().Context(), req.Username)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Invalid credentials"})
+		if errors.Is(err, sql.ErrNoRows) {
+			server.auditLoginFailure(ctx, req.Username, "unknown_user")
+			return newProblem(http.StatusUnauthorized, CodeInvalidCredentials, "invalid credentials")
+		}
+		return err
+	}
+
+	// a locked user is refused before the password is checked, so guessing
+	// can't go on during the lockout. The answer is the one an unknown user
+	// gets, so the lockout doesn't tell which usernames exist
+	if user.LockedUntil.After(time.Now()) {
+		server.auditLoginFailure(ctx, req.Username, "locked")
+		return newProblem(http.StatusUnauthorized, CodeInvalidCredentials, "invalid credentials")
 	}
 
 	err = utils.CheckPassword(user.HashedPassword, req.Password)
 	if err != nil {
-		return ctx.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid credentials"})
+		server.auditLoginFailure(ctx, req.Username, "wrong_password")
+		if err := server.recordLoginFailure(ctx, user.Username); err != nil {
+			return err
+		}
+		return newProblem(http.StatusUnauthorized, CodeInvalidCredentials, "invalid credentials")
+	}
+
+	if user.Disabled {
+		server.auditLoginFailure(ctx, req.Username, "disabled")
+		return newProblem(http.StatusForbidden, CodeUserDisabled, "user is disabled")
+	}
+
+	if user.FailedLoginAttempts > 0 || user.Lockouts > 0 {
+		if err := server.store.ResetFailedLogins(ctx.Request().Context(), user.Username); err != nil {
+			return err
+		}
 	}
 
 	token, err := server.tokenMaker.CreateToken(
@@ -120,9 +175,17 @@ func (server *Server) loginUser(ctx echo.Context) error {
 		server.config.AccessTokenDuration,
 	)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return err
 	}
 
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Actor:        user.Username,
+		Action:       audit.ActionUserLogin,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceUser,
+		ResourceID:   user.Username,
+	})
+
 	res := loginUserResponse{
 		AccessToken: token,
 		User:        newUserResponse(user),
@@ -130,3 +193,42 @@ func (server *Server) loginUser(ctx echo.Context) error {
 
 	return ctx.JSON(http.StatusOK, res)
 }
+
+func (server *Server) auditLoginFailure(ctx echo.Context, username string, reason string) {
+	server.metrics.IncLoginFailure(reason)
+	audit.Record(ctx.Request().Context(), server.store, audit.Event{
+		Actor:        username,
+		Action:       audit.ActionUserLogin,
+		Outcome:      audit.OutcomeFailure,
+		ResourceType: audit.ResourceUser,
+		ResourceID:   username,
+		Metadata:     map[string]interface{}{"reason": reason},
+	})
+}
+
+// recordLoginFailure counts a wrong password towards the lockout of the user
+// and audits the lockout when this was the last attempt.
+func (server *Server) recordLoginFailure(ctx echo.Context, username string) error {
+	result, err := server.store.LoginFailureTx(ctx.Request().Context(), db.LoginFailureTxParams{
+		Username: username,
+		Policy:   server.lockoutPolicy(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if result.Locked {
+		audit.Record(ctx.Request().Context(), server.store, audit.Event{
+			Actor:        username,
+			Action:       audit.ActionUserLock,
+			Outcome:      audit.OutcomeSuccess,
+			ResourceType: audit.ResourceUser,
+			ResourceID:   username,
+			Metadata: map[string]interface{}{
+				"locked_until": result.User.LockedUntil,
+				"lockouts":     result.User.Lockouts,
+			},
+		})
+	}
+	return nil
+}
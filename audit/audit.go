@@ -0,0 +1,153 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/logging"
+)
+
+// Security relevant actions recorded in audit_events.
+const (
+	ActionUserCreate      = "user.create"
+	ActionUserLogin       = "user.login"
+	ActionUserDisable     = "user.disable"
+	ActionUserEnable      = "user.enable"
+	ActionUserRole        = "user.role"
+	ActionUserLock        = "user.lock"
+	ActionUserVerifyEmail = "user.verify_email"
+	ActionAccountCreate   = "account.create"
+	ActionAccountFreeze   = "account.freeze"
+	ActionAccountUnfreeze = "account.unfreeze"
+	ActionPaymentCreate   = "payment.create"
+	ActionTokenMint       = "token.mint"
+
+	ActionUserPasswordResetRequest = "user.password_reset_request"
+	ActionUserPasswordReset        = "user.password_reset"
+	ActionUserPasswordChange       = "user.password_change"
+	ActionUserUpdate               = "user.update"
+	ActionUserEmailChangeRequest   = "user.email_change_request"
+	ActionUserEmailChange          = "user.email_change"
+
+	ActionAdjustmentPropose = "adjustment.propose"
+	ActionAdjustmentApprove = "adjustment.approve"
+	ActionAdjustmentReject  = "adjustment.reject"
+	ActionAdjustmentExpire  = "adjustment.expire"
+
+	ActionAPIKeyCreate = "api_key.create"
+	ActionAPIKeyRevoke = "api_key.revoke"
+
+	ActionOAuthClientCreate  = "oauth_client.create"
+	ActionOAuthConsentGrant  = "oauth_consent.grant"
+	ActionOAuthConsentRevoke = "oauth_consent.revoke"
+
+	ActionKYCProfileUpdate  = "kyc.profile_update"
+	ActionKYCDocumentUpload = "kyc.document_upload"
+	ActionKYCSubmit         = "kyc.submit"
+	ActionKYCApprove        = "kyc.approve"
+	ActionKYCReject         = "kyc.reject"
+
+	ActionScreeningClear   = "screening.clear"
+	ActionScreeningConfirm = "screening.confirm"
+	ActionFraudApprove     = "fraud.approve"
+	ActionFraudReject      = "fraud.reject"
+	ActionAMLAssign        = "aml.assign"
+	ActionAMLComment       = "aml.comment"
+	ActionAMLClose         = "aml.close"
+	ActionAMLEscalate      = "aml.escalate"
+	ActionAMLSARExport     = "aml.sar_export"
+
+	ActionSavingsProductCreate = "savings_product.create"
+	ActionLoanProductCreate    = "loan_product.create"
+	ActionLoanOriginate        = "loan.originate"
+	ActionLoanRepay            = "loan.repay"
+	ActionCardIssue            = "card.issue"
+	ActionCardUpdate           = "card.update"
+	ActionPocketCreate         = "pocket.create"
+	ActionPocketUpdate         = "pocket.update"
+	ActionPocketMove           = "pocket.move"
+
+	ActionAccountInvite            = "account.invite"
+	ActionAccountInvitationAccept  = "account.invitation_accept"
+	ActionAccountInvitationDecline = "account.invitation_decline"
+	ActionAccountInvitationRevoke  = "account.invitation_revoke"
+	ActionAccountMemberRemove      = "account.member_remove"
+)
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+const (
+	ResourceUser           = "user"
+	ResourceAccount        = "account"
+	ResourcePayment        = "payment"
+	ResourceAdjustment     = "adjustment"
+	ResourceAPIKey         = "api_key"
+	ResourceOAuthClient    = "oauth_client"
+	ResourceOAuthConsent   = "oauth_consent"
+	ResourceKYC            = "kyc"
+	ResourceKYCDocument    = "kyc_document"
+	ResourceScreeningHit   = "screening_hit"
+	ResourceFraudDecision  = "fraud_decision"
+	ResourceAMLAlert       = "aml_alert"
+	ResourceSavingsProduct = "savings_product"
+	ResourceLoanProduct    = "loan_product"
+	ResourceLoan           = "loan"
+	ResourceCard           = "card"
+	ResourcePocket         = "pocket"
+
+	ResourceAccountInvitation = "account_invitation"
+)
+
+// Event is a single entry of the audit trail. Actor defaults to the
+// authenticated user of the request.
+type Event struct {
+	Actor        string
+	Action       string
+	Outcome      string
+	ResourceType string
+	ResourceID   string
+	Metadata     map[string]interface{}
+}
+
+// Record appends the event to audit_events together with the request ID and
+// client IP from the context. A failure to write the audit trail is logged
+// but does not fail the operation being audited.
+func Record(ctx context.Context, q db.Querier, event Event) {
+	actor := event.Actor
+	if actor == "" {
+		actor = logging.Username(ctx)
+	}
+
+	metadata := []byte("{}")
+	if len(event.Metadata) > 0 {
+		var err error
+		metadata, err = json.Marshal(event.Metadata)
+		if err != nil {
+			slog.ErrorContext(ctx, "cannot encode audit metadata", slog.String("action", event.Action), slog.Any("error", err))
+			metadata = []byte("{}")
+		}
+	}
+
+	_, err := q.CreateAuditEvent(ctx, db.CreateAuditEventParams{
+		Actor:        actor,
+		Action:       event.Action,
+		Outcome:      event.Outcome,
+		ResourceType: event.ResourceType,
+		ResourceID:   event.ResourceID,
+		RequestID:    logging.RequestID(ctx),
+		ClientIp:     logging.ClientIP(ctx),
+		Metadata:     metadata,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "cannot record audit event",
+			slog.String("action", event.Action),
+			slog.String("outcome", event.Outcome),
+			slog.Any("error", err),
+		)
+	}
+}
@@ -0,0 +1,68 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/logging"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRecord(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+	ctx = logging.WithUsername(ctx, "alice")
+	ctx = logging.WithClientIP(ctx, "10.0.0.1")
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		CreateAuditEvent(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateAuditEventParams) (db.AuditEvent, error) {
+			require.Equal(t, "alice", arg.Actor)
+			require.Equal(t, ActionPaymentCreate, arg.Action)
+			require.Equal(t, OutcomeSuccess, arg.Outcome)
+			require.Equal(t, "req-1", arg.RequestID)
+			require.Equal(t, "10.0.0.1", arg.ClientIp)
+
+			var metadata map[string]interface{}
+			require.NoError(t, json.Unmarshal(arg.Metadata, &metadata))
+			require.Equal(t, float64(10), metadata["amount"])
+			return db.AuditEvent{}, nil
+		})
+
+	Record(ctx, store, Event{
+		Action:       ActionPaymentCreate,
+		Outcome:      OutcomeSuccess,
+		ResourceType: ResourcePayment,
+		ResourceID:   "1",
+		Metadata:     map[string]interface{}{"amount": 10},
+	})
+}
+
+func TestRecordExplicitActor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		CreateAuditEvent(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateAuditEventParams) (db.AuditEvent, error) {
+			require.Equal(t, "bob", arg.Actor)
+			require.JSONEq(t, "{}", string(arg.Metadata))
+			return db.AuditEvent{}, nil
+		})
+
+	Record(context.Background(), store, Event{
+		Actor:   "bob",
+		Action:  ActionUserLogin,
+		Outcome: OutcomeFailure,
+	})
+}
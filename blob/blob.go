@@ -0,0 +1,54 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/danielmoisa/neobank/utils"
+)
+
+var (
+	ErrNotFound   = errors.New("blob not found")
+	ErrInvalidKey = errors.New("invalid blob key")
+)
+
+// Storage keeps opaque blobs, such as the documents users upload, under keys
+// made of slash-separated segments like "kyc/alice/3f2a".
+type Storage interface {
+	// Put stores the content of r under key, replacing any blob already there.
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Get opens the blob under key. The caller closes it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob under key. Deleting a missing blob isn't an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// New creates the storage selected by BLOB_STORAGE, defaulting to files in
+// BLOB_DIR.
+func New(config utils.Config) (Storage, error) {
+	switch config.BlobStorage {
+	case "", "file":
+		return NewFileStorage(config.BlobDir), nil
+	case "memory":
+		return NewMemoryStorage(), nil
+	default:
+		return nil, fmt.Errorf("unsupported blob storage %q", config.BlobStorage)
+	}
+}
+
+// validateKey makes sure key can't escape the storage: every segment must be
+// non-empty, not a dot or dot-dot, and free of backslashes.
+func validateKey(key string) error {
+	if key == "" || strings.ContainsAny(key, "\\\x00") {
+		return ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
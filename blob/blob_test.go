@@ -0,0 +1,67 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func testStorage(t *testing.T, storage Storage) {
+	ctx := context.Background()
+
+	_, err := storage.Get(ctx, "kyc/alice/doc")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, storage.Put(ctx, "kyc/alice/doc", strings.NewReader("first")))
+	require.NoError(t, storage.Put(ctx, "kyc/alice/doc", strings.NewReader("second")))
+
+	r, err := storage.Get(ctx, "kyc/alice/doc")
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	require.Equal(t, "second", string(data))
+
+	require.NoError(t, storage.Delete(ctx, "kyc/alice/doc"))
+	require.NoError(t, storage.Delete(ctx, "kyc/alice/doc"))
+	_, err = storage.Get(ctx, "kyc/alice/doc")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	for _, key := range []string{"", "/abs", "a//b", "../escape", "a/../../b", "a/.", `a\b`} {
+		require.ErrorIs(t, storage.Put(ctx, key, strings.NewReader("x")), ErrInvalidKey, key)
+	}
+}
+
+func TestFileStorage(t *testing.T) {
+	dir := t.TempDir()
+	testStorage(t, NewFileStorage(filepath.Join(dir, "blobs")))
+
+	entries, err := os.ReadDir(filepath.Join(dir, "blobs", "kyc", "alice"))
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestMemoryStorage(t *testing.T) {
+	storage := NewMemoryStorage()
+	testStorage(t, storage)
+	require.Empty(t, storage.Keys())
+}
+
+func TestNew(t *testing.T) {
+	storage, err := New(utils.Config{BlobDir: t.TempDir()})
+	require.NoError(t, err)
+	require.IsType(t, &FileStorage{}, storage)
+
+	storage, err = New(utils.Config{BlobStorage: "memory"})
+	require.NoError(t, err)
+	require.IsType(t, &MemoryStorage{}, storage)
+
+	_, err = New(utils.Config{BlobStorage: "s3"})
+	require.EqualError(t, err, `unsupported blob storage "s3"`)
+}
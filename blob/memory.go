@@ -0,0 +1,66 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+)
+
+// MemoryStorage keeps blobs in memory, for tests.
+type MemoryStorage struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{blobs: make(map[string][]byte)}
+}
+
+func (storage *MemoryStorage) Put(_ context.Context, key string, r io.Reader) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+
+	storage.blobs[key] = data
+	return nil
+}
+
+func (storage *MemoryStorage) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+
+	data, ok := storage.blobs[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (storage *MemoryStorage) Delete(_ context.Context, key string) error {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+
+	delete(storage.blobs, key)
+	return nil
+}
+
+// Keys returns the keys of the blobs stored so far, in no particular order.
+func (storage *MemoryStorage) Keys() []string {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+
+	keys := make([]string, 0, len(storage.blobs))
+	for key := range storage.blobs {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,156 @@
+// Package cards issues virtual debit cards and runs a local ISO 8583-style
+// switch that authorizes, captures and reverses card payments against the
+// linked accounts, so card flows can be tested without a card network.
+package cards
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/utils"
+)
+
+const (
+	// PANLength is how many digits the cards issued have, check digit
+	// included.
+	PANLength = 16
+	// DefaultBIN is the bank identification number cards are issued under
+	// when none is configured; it falls in a range test cards use.
+	DefaultBIN = "400000"
+	// ValidYears is how long a card is valid for.
+	ValidYears = 3
+	cvvLength  = 3
+)
+
+// Default limits of a new card, in minor units.
+const (
+	DefaultTransactionLimit = 100_000
+	DefaultDailyLimit       = 250_000
+)
+
+var ErrInvalidBIN = errors.New("BIN must be 6 to 8 digits")
+
+// Details are the details of a new card. The PAN and CVV are only shown
+// once, when the card is issued; the CVV is stored hashed.
+type Details struct {
+	PAN         string
+	CVV         string
+	HashedCVV   string
+	ExpiryMonth int32
+	ExpiryYear  int32
+}
+
+// NewDetails generates the details of a card issued under bin at now: a
+// random PAN with its Luhn check digit, a random CVV, and an expiry in
+// the same month ValidYears later.
+func NewDetails(bin string, now time.Time) (Details, error) {
+	pan, err := GeneratePAN(bin)
+	if err != nil {
+		return Details{}, err
+	}
+	cvv, err := randomDigits(cvvLength)
+	if err != nil {
+		return Details{}, err
+	}
+	hashedCVV, err := utils.HashPassword(cvv)
+	if err != nil {
+		return Details{}, err
+	}
+
+	now = now.UTC()
+	return Details{
+		PAN:         pan,
+		CVV:         cvv,
+		HashedCVV:   hashedCVV,
+		ExpiryMonth: int32(now.Month()),
+		ExpiryYear:  int32(now.Year() + ValidYears),
+	}, nil
+}
+
+// GeneratePAN returns a random PAN of PANLength digits starting with bin.
+func GeneratePAN(bin string) (string, error) {
+	if len(bin) < 6 || len(bin) > 8 || !isDigits(bin) {
+		return "", ErrInvalidBIN
+	}
+	account, err := randomDigits(PANLength - len(bin) - 1)
+	if err != nil {
+		return "", err
+	}
+	pan := bin + account
+	return pan + string(rune('0'+luhnDigit(pan))), nil
+}
+
+// LuhnValid reports whether the last digit of number is its Luhn check
+// digit.
+func LuhnValid(number string) bool {
+	if len(number) < 2 || !isDigits(number) {
+		return false
+	}
+	last := len(number) - 1
+	return int(number[last]-'0') == luhnDigit(number[:last])
+}
+
+// luhnDigit returns the check digit to append to digits.
+func luhnDigit(digits string) int {
+	sum := 0
+	double := true
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return (10 - sum%10) % 10
+}
+
+// Mask hides all but the BIN and the last four digits of a PAN.
+func Mask(pan string) string {
+	if len(pan) < 10 {
+		return strings.Repeat("*", len(pan))
+	}
+	return pan[:6] + strings.Repeat("*", len(pan)-10) + pan[len(pan)-4:]
+}
+
+// Expired reports whether card is past the last day of its expiry month at
+// now.
+func Expired(card db.Card, now time.Time) bool {
+	end := time.Date(int(card.ExpiryYear), time.Month(card.ExpiryMonth)+1, 1, 0, 0, 0, 0, time.UTC)
+	return !now.Before(end)
+}
+
+// CheckCVV reports whether cvv is the CVV of card.
+func CheckCVV(card db.Card, cvv string) bool {
+	return cvv != "" && utils.CheckPassword(card.HashedCVV, cvv) == nil
+}
+
+func randomDigits(n int) (string, error) {
+	var b strings.Builder
+	ten := big.NewInt(10)
+	for i := 0; i < n; i++ {
+		d, err := rand.Int(rand.Reader, ten)
+		if err != nil {
+			return "", fmt.Errorf("cannot generate random digits: %w", err)
+		}
+		b.WriteByte(byte('0' + d.Int64()))
+	}
+	return b.String(), nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,74 @@
+package cards
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLuhnValid(t *testing.T) {
+	testCases := []struct {
+		number string
+		valid  bool
+	}{
+		{"4111111111111111", true},
+		{"5555555555554444", true},
+		{"378282246310005", true},
+		{"79927398713", true},
+		{"4111111111111112", false},
+		{"79927398710", false},
+		{"4111-1111-1111-1111", false},
+		{"0", false},
+		{"", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.number, func(t *testing.T) {
+			require.Equal(t, tc.valid, LuhnValid(tc.number))
+		})
+	}
+}
+
+func TestGeneratePAN(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		pan, err := GeneratePAN("400000")
+		require.NoError(t, err)
+		require.Len(t, pan, PANLength)
+		require.True(t, strings.HasPrefix(pan, "400000"))
+		require.True(t, LuhnValid(pan), pan)
+	}
+
+	for _, bin := range []string{"", "40000", "400000000", "4000a0"} {
+		_, err := GeneratePAN(bin)
+		require.ErrorIs(t, err, ErrInvalidBIN, bin)
+	}
+}
+
+func TestNewDetails(t *testing.T) {
+	details, err := NewDetails(DefaultBIN, time.Date(2024, time.February, 29, 23, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.True(t, LuhnValid(details.PAN))
+	require.Len(t, details.CVV, 3)
+	require.NotEqual(t, details.CVV, details.HashedCVV)
+	require.Equal(t, int32(2), details.ExpiryMonth)
+	require.Equal(t, int32(2027), details.ExpiryYear)
+
+	card := db.Card{HashedCVV: details.HashedCVV}
+	require.True(t, CheckCVV(card, details.CVV))
+	require.False(t, CheckCVV(card, ""))
+	require.False(t, CheckCVV(card, "1234"))
+}
+
+func TestMask(t *testing.T) {
+	require.Equal(t, "400000******1234", Mask("4000001234561234"))
+	require.Equal(t, "*****", Mask("12345"))
+}
+
+func TestExpired(t *testing.T) {
+	card := db.Card{ExpiryMonth: 12, ExpiryYear: 2026}
+	require.False(t, Expired(card, time.Date(2026, time.December, 31, 23, 59, 59, 0, time.UTC)))
+	require.True(t, Expired(card, time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)))
+}
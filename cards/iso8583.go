@@ -0,0 +1,109 @@
+package cards
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Message is an ISO 8583-style message: a message type indicator and the
+// data elements it carries, by field number. Messages are exchanged as JSON
+// rather than packed bitmaps; only the fields the switch uses are known.
+type Message struct {
+	MTI    string         `json:"mti" example:"0100"`
+	Fields map[int]string `json:"fields"`
+}
+
+// Message types the switch handles. Each request is answered with the type
+// whose third digit is one more: 0100 with 0110, 0420 with 0430.
+const (
+	MTIAuthorizationRequest   = "0100"
+	MTIAuthorizationResponse  = "0110"
+	MTIAdvice                 = "0220"
+	MTIAdviceResponse         = "0230"
+	MTIReversalRequest        = "0400"
+	MTIReversalResponse       = "0410"
+	MTIReversalAdvice         = "0420"
+	MTIReversalAdviceResponse = "0430"
+)
+
+// Data elements the switch reads and writes.
+const (
+	FieldPAN            = 2
+	FieldProcessingCode = 3
+	FieldAmount         = 4
+	FieldSTAN           = 11
+	FieldExpiry         = 14
+	FieldMCC            = 18
+	FieldRRN            = 37
+	FieldAuthCode       = 38
+	FieldResponseCode   = 39
+	FieldMerchant       = 43
+	FieldCVV2           = 48
+	FieldCurrency       = 49
+)
+
+// echoedFields are copied from a request into its response.
+var echoedFields = []int{FieldPAN, FieldProcessingCode, FieldAmount, FieldSTAN, FieldRRN, FieldCurrency}
+
+// Response codes of field 39.
+const (
+	RespApproved             = "00"
+	RespDoNotHonour          = "05"
+	RespInvalidTransaction   = "12"
+	RespInvalidAmount        = "13"
+	RespInvalidCard          = "14"
+	RespOriginalNotFound     = "25"
+	RespFormatError          = "30"
+	RespInsufficientFunds    = "51"
+	RespExpiredCard          = "54"
+	RespNotPermitted         = "57"
+	RespExceedsLimit         = "61"
+	RespRestrictedCard       = "62"
+	RespDuplicateTransaction = "94"
+	RespCVVMismatch          = "N7"
+)
+
+// amountLength is the width of field 4, zero-padded.
+const amountLength = 12
+
+// currencies maps the ISO 4217 numeric codes of field 49 to the currencies
+// of accounts.
+var currencies = map[string]string{
+	"840": "USD",
+	"978": "EUR",
+	"124": "CAD",
+}
+
+var ErrInvalidMTI = errors.New("message type indicator must be 4 digits")
+
+// ResponseMTI returns the message type answering mti.
+func ResponseMTI(mti string) (string, error) {
+	if len(mti) != 4 || !isDigits(mti) || mti[2]%2 != 0 {
+		return "", ErrInvalidMTI
+	}
+	return mti[:2] + string(mti[2]+1) + mti[3:], nil
+}
+
+// FormatAmount formats amount, in minor units, as field 4.
+func FormatAmount(amount int64) string {
+	return fmt.Sprintf("%0*d", amountLength, amount)
+}
+
+func parseAmount(field string) (int64, bool) {
+	if len(field) != amountLength || !isDigits(field) {
+		return 0, false
+	}
+	amount, err := strconv.ParseInt(field, 10, 64)
+	return amount, err == nil && amount > 0
+}
+
+// CurrencyCode returns the numeric code of field 49 for currency.
+func CurrencyCode(currency string) string {
+	for code, c := range currencies {
+		if c == currency {
+			return code
+		}
+	}
+	return ""
+}
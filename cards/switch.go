@@ -0,0 +1,280 @@
+package cards
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/events"
+	"github.com/lib/pq"
+)
+
+// DefaultHoldTTL is how long an approved authorization holds its amount
+// when no hold TTL is configured.
+const DefaultHoldTTL = 7 * 24 * time.Hour
+
+const (
+	authCodeLength    = 6
+	pqUniqueViolation = "23505"
+)
+
+// Switch plays the card network and the issuer's host: it answers
+// authorization, capture and reversal messages for the cards issued, holding
+// and settling the amounts on the linked accounts. Captures and reversals
+// are published to the subscribers of the account.
+type Switch struct {
+	store   db.Store
+	broker  events.Broker
+	holdTTL time.Duration
+	now     func() time.Time
+}
+
+func NewSwitch(store db.Store, broker events.Broker, holdTTL time.Duration) *Switch {
+	return &Switch{store: store, broker: broker, holdTTL: holdTTL, now: time.Now}
+}
+
+// Handle answers a message. Declines are answered with their response code
+// in field 39, not returned as errors; an error means the message has a type
+// the switch doesn't handle or the store failed.
+//
+//   - 0100 authorizes a payment and holds its amount on the account.
+//   - 0220 captures an approved authorization, found by its RRN, for the
+//     amount of field 4, which may be less than the authorized amount.
+//   - 0400 and 0420 reverse an approved authorization and release its hold.
+func (s *Switch) Handle(ctx context.Context, req Message) (Message, error) {
+	mti, err := ResponseMTI(req.MTI)
+	if err != nil {
+		return Message{}, err
+	}
+
+	resp := Message{MTI: mti, Fields: map[int]string{}}
+	for _, field := range echoedFields {
+		if value, ok := req.Fields[field]; ok {
+			resp.Fields[field] = value
+		}
+	}
+
+	var code string
+	switch req.MTI {
+	case MTIAuthorizationRequest:
+		code, err = s.authorize(ctx, req, resp)
+	case MTIAdvice:
+		code, err = s.capture(ctx, req, resp)
+	case MTIReversalRequest, MTIReversalAdvice:
+		code, err = s.reverse(ctx, req)
+	default:
+		return Message{}, fmt.Errorf("%w: %s is not handled", ErrInvalidMTI, req.MTI)
+	}
+	if err != nil {
+		return Message{}, err
+	}
+
+	resp.Fields[FieldResponseCode] = code
+	return resp, nil
+}
+
+func (s *Switch) authorize(ctx context.Context, req Message, resp Message) (string, error) {
+	card, found, err := s.card(ctx, req)
+	if err != nil || !found {
+		return RespInvalidCard, err
+	}
+
+	amount, ok := parseAmount(req.Fields[FieldAmount])
+	currency, known := currencies[req.Fields[FieldCurrency]]
+	stan, rrn := req.Fields[FieldSTAN], req.Fields[FieldRRN]
+	if !ok || !known || stan == "" || rrn == "" {
+		return RespFormatError, nil
+	}
+
+	_, err = s.store.GetCardAuthorizationByRRN(ctx, db.GetCardAuthorizationByRRNParams{CardID: card.ID, RRN: rrn})
+	if err == nil {
+		return RespDuplicateTransaction, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", err
+	}
+
+	account, err := s.store.GetAccount(ctx, card.AccountID)
+	if err != nil {
+		return "", err
+	}
+
+	now := s.now().UTC()
+	params := db.CreateCardAuthorizationParams{
+		CardID:    card.ID,
+		AccountID: account.ID,
+		STAN:      stan,
+		RRN:       rrn,
+		Amount:    amount,
+		Currency:  currency,
+		Merchant:  req.Fields[FieldMerchant],
+		MCC:       req.Fields[FieldMCC],
+		ExpiresAt: now.Add(s.holdTTL),
+	}
+
+	code := check(card, account, req, amount, currency, now)
+	if code == RespApproved {
+		params.ResponseCode = RespApproved
+		params.AuthCode, err = randomDigits(authCodeLength)
+		if err != nil {
+			return "", err
+		}
+
+		year, month, day := now.Date()
+		authorization, err := s.store.AuthorizeCardTx(ctx, db.AuthorizeCardTxParams{
+			Authorization: params,
+			DailyLimit:    card.DailyLimit,
+			Since:         time.Date(year, month, day, 0, 0, 0, 0, time.UTC),
+		})
+		switch {
+		case err == nil:
+			resp.Fields[FieldAuthCode] = authorization.AuthCode
+			return RespApproved, nil
+		case errors.Is(err, db.ErrAccountFrozen):
+			code = RespDoNotHonour
+		case errors.Is(err, db.ErrCardLimitExceeded):
+			code = RespExceedsLimit
+		case errors.Is(err, db.ErrInsufficientFunds):
+			code = RespInsufficientFunds
+		case isUniqueViolation(err):
+			return RespDuplicateTransaction, nil
+		default:
+			return "", err
+		}
+	}
+
+	params.Status = db.CardAuthDeclined
+	params.ResponseCode = code
+	params.AuthCode = ""
+	_, err = s.store.CreateCardAuthorization(ctx, params)
+	if isUniqueViolation(err) {
+		return RespDuplicateTransaction, nil
+	}
+	return code, err
+}
+
+// check runs the checks that need no lock on the account, and returns the
+// response code of the first one that fails or RespApproved.
+func check(card db.Card, account db.Account, req Message, amount int64, currency string, now time.Time) string {
+	expiry := fmt.Sprintf("%02d%02d", card.ExpiryYear%100, card.ExpiryMonth)
+	switch {
+	case req.Fields[FieldExpiry] != expiry || Expired(card, now):
+		return RespExpiredCard
+	case !CheckCVV(card, req.Fields[FieldCVV2]):
+		return RespCVVMismatch
+	case card.Frozen:
+		return RespRestrictedCard
+	case account.Frozen:
+		return RespDoNotHonour
+	case currency != account.Currency:
+		return RespNotPermitted
+	case amount > card.TransactionLimit:
+		return RespExceedsLimit
+	}
+	return RespApproved
+}
+
+func (s *Switch) capture(ctx context.Context, req Message, resp Message) (string, error) {
+	authorization, code, err := s.original(ctx, req)
+	if err != nil || code != "" {
+		return code, err
+	}
+
+	amount, ok := parseAmount(req.Fields[FieldAmount])
+	if !ok {
+		return RespFormatError, nil
+	}
+
+	result, err := s.store.CaptureCardTx(ctx, db.CaptureCardTxParams{
+		AuthorizationID: authorization.ID,
+		Amount:          amount,
+	})
+	switch {
+	case err == nil:
+		events.PublishAll(ctx, s.broker, events.AccountEvents(result.Account, result.Entry))
+		resp.Fields[FieldAuthCode] = authorization.AuthCode
+		return RespApproved, nil
+	case errors.Is(err, db.ErrAuthorizationNotOpen):
+		return settledCode(authorization, db.CardAuthCaptured), nil
+	case errors.Is(err, db.ErrCaptureTooLarge):
+		return RespInvalidAmount, nil
+	}
+	return "", err
+}
+
+func (s *Switch) reverse(ctx context.Context, req Message) (string, error) {
+	authorization, code, err := s.original(ctx, req)
+	if err != nil || code != "" {
+		return code, err
+	}
+
+	reversed, err := s.store.ReverseCardAuthorization(ctx, authorization.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return settledCode(authorization, db.CardAuthReversed), nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// the balance stays, but the amount held is available again
+	account, err := s.store.GetAccount(ctx, reversed.AccountID)
+	if err != nil {
+		slog.ErrorContext(ctx, "cannot publish card reversal", slog.Int64("account_id", reversed.AccountID), slog.Any("error", err))
+		return RespApproved, nil
+	}
+	events.PublishAll(ctx, s.broker, events.AccountEvents(account))
+	return RespApproved, nil
+}
+
+// original finds the authorization a capture or reversal refers to by the
+// card and RRN. It returns a response code when there is none.
+func (s *Switch) original(ctx context.Context, req Message) (db.CardAuthorization, string, error) {
+	card, found, err := s.card(ctx, req)
+	if err != nil || !found {
+		return db.CardAuthorization{}, RespInvalidCard, err
+	}
+	if req.Fields[FieldRRN] == "" {
+		return db.CardAuthorization{}, RespFormatError, nil
+	}
+
+	authorization, err := s.store.GetCardAuthorizationByRRN(ctx, db.GetCardAuthorizationByRRNParams{
+		CardID: card.ID,
+		RRN:    req.Fields[FieldRRN],
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.CardAuthorization{}, RespOriginalNotFound, nil
+	}
+	return authorization, "", err
+}
+
+// settledCode answers a capture or reversal of an authorization that isn't
+// approved anymore: repeating the one already done is a duplicate, anything
+// else isn't allowed.
+func settledCode(authorization db.CardAuthorization, status string) string {
+	if authorization.Status == status {
+		return RespDuplicateTransaction
+	}
+	return RespInvalidTransaction
+}
+
+// card finds the card of field 2.
+func (s *Switch) card(ctx context.Context, req Message) (db.Card, bool, error) {
+	pan := req.Fields[FieldPAN]
+	if !LuhnValid(pan) {
+		return db.Card{}, false, nil
+	}
+	card, err := s.store.GetCardByPAN(ctx, pan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.Card{}, false, nil
+	}
+	return card, err == nil, err
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation
+}
@@ -0,0 +1,436 @@
+package cards
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/events"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	testPAN = "4000001234567899"
+	testCVV = "123"
+)
+
+var testNow = time.Date(2026, time.March, 10, 14, 30, 0, 0, time.UTC)
+
+// hashedTestCVV is hashed once, bcrypt being slow on purpose.
+var hashedTestCVV = sync.OnceValue(func() string {
+	hash, err := utils.HashPassword(testCVV)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+})
+
+func newTestSwitch(t *testing.T) (*Switch, *mockdb.MockStore, db.Card, db.Account) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	s := NewSwitch(store, events.NewMemoryBroker(), time.Hour)
+	s.now = func() time.Time { return testNow }
+
+	card := db.Card{
+		ID:               7,
+		AccountID:        3,
+		Owner:            "alice",
+		PAN:              testPAN,
+		Last4:            "7899",
+		ExpiryMonth:      5,
+		ExpiryYear:       2028,
+		HashedCVV:        hashedTestCVV(),
+		TransactionLimit: 50_000,
+		DailyLimit:       80_000,
+	}
+	account := db.Account{ID: 3, Owner: "alice", Balance: 100_000, Currency: "EUR", Type: db.AccountChecking}
+	return s, store, card, account
+}
+
+func authorizationRequest(amount int64) Message {
+	return Message{
+		MTI: MTIAuthorizationRequest,
+		Fields: map[int]string{
+			FieldPAN:            testPAN,
+			FieldProcessingCode: "000000",
+			FieldAmount:         FormatAmount(amount),
+			FieldSTAN:           "000123",
+			FieldExpiry:         "2805",
+			FieldMCC:            "5814",
+			FieldRRN:            "406912345678",
+			FieldMerchant:       "Coffee Shop Berlin",
+			FieldCVV2:           testCVV,
+			FieldCurrency:       "978",
+		},
+	}
+}
+
+func TestSwitchAuthorize(t *testing.T) {
+	s, store, card, account := newTestSwitch(t)
+
+	store.EXPECT().GetCardByPAN(gomock.Any(), testPAN).Times(1).Return(card, nil)
+	store.EXPECT().
+		GetCardAuthorizationByRRN(gomock.Any(), gomock.Eq(db.GetCardAuthorizationByRRNParams{CardID: card.ID, RRN: "406912345678"})).
+		Times(1).
+		Return(db.CardAuthorization{}, sql.ErrNoRows)
+	store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+	store.EXPECT().
+		AuthorizeCardTx(gomock.Any(), gomock.Cond(func(x any) bool {
+			args := x.(db.AuthorizeCardTxParams)
+			auth := args.Authorization
+			return auth.CardID == card.ID && auth.AccountID == account.ID &&
+				auth.Amount == 12_345 && auth.Currency == "EUR" &&
+				auth.STAN == "000123" && auth.RRN == "406912345678" &&
+				auth.Merchant == "Coffee Shop Berlin" && auth.MCC == "5814" &&
+				auth.ResponseCode == RespApproved && len(auth.AuthCode) == authCodeLength &&
+				auth.ExpiresAt.Equal(testNow.Add(time.Hour)) &&
+				args.DailyLimit == card.DailyLimit &&
+				args.Since.Equal(time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC))
+		})).
+		Times(1).
+		DoAndReturn(func(_ context.Context, args db.AuthorizeCardTxParams) (db.CardAuthorization, error) {
+			return db.CardAuthorization{ID: 1, Status: db.CardAuthApproved, AuthCode: args.Authorization.AuthCode}, nil
+		})
+	store.EXPECT().CreateCardAuthorization(gomock.Any(), gomock.Any()).Times(0)
+
+	resp, err := s.Handle(context.Background(), authorizationRequest(12_345))
+	require.NoError(t, err)
+	require.Equal(t, MTIAuthorizationResponse, resp.MTI)
+	require.Equal(t, RespApproved, resp.Fields[FieldResponseCode])
+	require.Len(t, resp.Fields[FieldAuthCode], authCodeLength)
+	require.Equal(t, "000000012345", resp.Fields[FieldAmount])
+	require.Equal(t, "000123", resp.Fields[FieldSTAN])
+	require.Equal(t, "406912345678", resp.Fields[FieldRRN])
+	require.NotContains(t, resp.Fields, FieldCVV2)
+}
+
+func TestSwitchAuthorizeDeclined(t *testing.T) {
+	testCases := []struct {
+		name    string
+		request func(req Message)
+		card    func(card *db.Card, account *db.Account)
+		txErr   error
+		code    string
+	}{
+		{
+			name:    "ExpiryMismatch",
+			request: func(req Message) { req.Fields[FieldExpiry] = "2806" },
+			code:    RespExpiredCard,
+		},
+		{
+			name: "Expired",
+			card: func(card *db.Card, account *db.Account) {
+				card.ExpiryMonth = 2
+				card.ExpiryYear = 2026
+			},
+			request: func(req Message) { req.Fields[FieldExpiry] = "2602" },
+			code:    RespExpiredCard,
+		},
+		{
+			name:    "WrongCVV",
+			request: func(req Message) { req.Fields[FieldCVV2] = "999" },
+			code:    RespCVVMismatch,
+		},
+		{
+			name:    "NoCVV",
+			request: func(req Message) { delete(req.Fields, FieldCVV2) },
+			code:    RespCVVMismatch,
+		},
+		{
+			name: "CardFrozen",
+			card: func(card *db.Card, account *db.Account) { card.Frozen = true },
+			code: RespRestrictedCard,
+		},
+		{
+			name: "AccountFrozen",
+			card: func(card *db.Card, account *db.Account) { account.Frozen = true },
+			code: RespDoNotHonour,
+		},
+		{
+			name:    "CurrencyMismatch",
+			request: func(req Message) { req.Fields[FieldCurrency] = "840" },
+			code:    RespNotPermitted,
+		},
+		{
+			name:    "OverTransactionLimit",
+			request: func(req Message) { req.Fields[FieldAmount] = FormatAmount(50_001) },
+			code:    RespExceedsLimit,
+		},
+		{
+			name:  "InsufficientFunds",
+			txErr: db.ErrInsufficientFunds,
+			code:  RespInsufficientFunds,
+		},
+		{
+			name:  "OverDailyLimit",
+			txErr: db.ErrCardLimitExceeded,
+			code:  RespExceedsLimit,
+		},
+		{
+			name:  "AccountFrozenSince",
+			txErr: db.ErrAccountFrozen,
+			code:  RespDoNotHonour,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, store, card, account := newTestSwitch(t)
+			req := authorizationRequest(12_345)
+			if tc.request != nil {
+				tc.request(req)
+			}
+			if tc.card != nil {
+				tc.card(&card, &account)
+			}
+
+			store.EXPECT().GetCardByPAN(gomock.Any(), testPAN).Times(1).Return(card, nil)
+			store.EXPECT().GetCardAuthorizationByRRN(gomock.Any(), gomock.Any()).Times(1).Return(db.CardAuthorization{}, sql.ErrNoRows)
+			store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+			if tc.txErr != nil {
+				store.EXPECT().AuthorizeCardTx(gomock.Any(), gomock.Any()).Times(1).Return(db.CardAuthorization{}, tc.txErr)
+			} else {
+				store.EXPECT().AuthorizeCardTx(gomock.Any(), gomock.Any()).Times(0)
+			}
+			store.EXPECT().
+				CreateCardAuthorization(gomock.Any(), gomock.Cond(func(x any) bool {
+					arg := x.(db.CreateCardAuthorizationParams)
+					return arg.CardID == card.ID && arg.Status == db.CardAuthDeclined &&
+						arg.ResponseCode == tc.code && arg.AuthCode == ""
+				})).
+				Times(1).
+				Return(db.CardAuthorization{ID: 1}, nil)
+
+			resp, err := s.Handle(context.Background(), req)
+			require.NoError(t, err)
+			require.Equal(t, MTIAuthorizationResponse, resp.MTI)
+			require.Equal(t, tc.code, resp.Fields[FieldResponseCode])
+			require.NotContains(t, resp.Fields, FieldAuthCode)
+		})
+	}
+}
+
+func TestSwitchAuthorizeRejected(t *testing.T) {
+	testCases := []struct {
+		name       string
+		request    func(req Message)
+		buildStubs func(store *mockdb.MockStore, card db.Card)
+		code       string
+	}{
+		{
+			name:    "InvalidPAN",
+			request: func(req Message) { req.Fields[FieldPAN] = "4000001234567890" },
+			buildStubs: func(store *mockdb.MockStore, card db.Card) {
+				store.EXPECT().GetCardByPAN(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: RespInvalidCard,
+		},
+		{
+			name: "UnknownCard",
+			buildStubs: func(store *mockdb.MockStore, card db.Card) {
+				store.EXPECT().GetCardByPAN(gomock.Any(), testPAN).Times(1).Return(db.Card{}, sql.ErrNoRows)
+			},
+			code: RespInvalidCard,
+		},
+		{
+			name:    "BadAmount",
+			request: func(req Message) { req.Fields[FieldAmount] = "12.50" },
+			buildStubs: func(store *mockdb.MockStore, card db.Card) {
+				store.EXPECT().GetCardByPAN(gomock.Any(), testPAN).Times(1).Return(card, nil)
+			},
+			code: RespFormatError,
+		},
+		{
+			name:    "UnknownCurrency",
+			request: func(req Message) { req.Fields[FieldCurrency] = "999" },
+			buildStubs: func(store *mockdb.MockStore, card db.Card) {
+				store.EXPECT().GetCardByPAN(gomock.Any(), testPAN).Times(1).Return(card, nil)
+			},
+			code: RespFormatError,
+		},
+		{
+			name:    "NoRRN",
+			request: func(req Message) { delete(req.Fields, FieldRRN) },
+			buildStubs: func(store *mockdb.MockStore, card db.Card) {
+				store.EXPECT().GetCardByPAN(gomock.Any(), testPAN).Times(1).Return(card, nil)
+			},
+			code: RespFormatError,
+		},
+		{
+			name: "DuplicateRRN",
+			buildStubs: func(store *mockdb.MockStore, card db.Card) {
+				store.EXPECT().GetCardByPAN(gomock.Any(), testPAN).Times(1).Return(card, nil)
+				store.EXPECT().GetCardAuthorizationByRRN(gomock.Any(), gomock.Any()).Times(1).Return(db.CardAuthorization{ID: 1}, nil)
+			},
+			code: RespDuplicateTransaction,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, store, card, _ := newTestSwitch(t)
+			req := authorizationRequest(12_345)
+			if tc.request != nil {
+				tc.request(req)
+			}
+			tc.buildStubs(store, card)
+			store.EXPECT().AuthorizeCardTx(gomock.Any(), gomock.Any()).Times(0)
+			store.EXPECT().CreateCardAuthorization(gomock.Any(), gomock.Any()).Times(0)
+
+			resp, err := s.Handle(context.Background(), req)
+			require.NoError(t, err)
+			require.Equal(t, tc.code, resp.Fields[FieldResponseCode])
+		})
+	}
+}
+
+func TestSwitchCapture(t *testing.T) {
+	approved := db.CardAuthorization{ID: 11, CardID: 7, Amount: 12_345, Status: db.CardAuthApproved, AuthCode: "654321"}
+	captured := approved
+	captured.Status = db.CardAuthCaptured
+	reversed := approved
+	reversed.Status = db.CardAuthReversed
+
+	testCases := []struct {
+		name          string
+		amount        int64
+		authorization *db.CardAuthorization
+		txErr         error
+		code          string
+	}{
+		{name: "OK", amount: 10_000, authorization: &approved, code: RespApproved},
+		{name: "TooLarge", amount: 12_346, authorization: &approved, txErr: db.ErrCaptureTooLarge, code: RespInvalidAmount},
+		{name: "AlreadyCaptured", amount: 10_000, authorization: &captured, txErr: db.ErrAuthorizationNotOpen, code: RespDuplicateTransaction},
+		{name: "Reversed", amount: 10_000, authorization: &reversed, txErr: db.ErrAuthorizationNotOpen, code: RespInvalidTransaction},
+		{name: "OriginalNotFound", amount: 10_000, code: RespOriginalNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, store, card, account := newTestSwitch(t)
+			sub, unsubscribe := s.broker.Subscribe(account.ID)
+			defer unsubscribe()
+			req := Message{
+				MTI: MTIAdvice,
+				Fields: map[int]string{
+					FieldPAN:    testPAN,
+					FieldAmount: FormatAmount(tc.amount),
+					FieldSTAN:   "000124",
+					FieldRRN:    "406912345678",
+				},
+			}
+
+			store.EXPECT().GetCardByPAN(gomock.Any(), testPAN).Times(1).Return(card, nil)
+			if tc.authorization == nil {
+				store.EXPECT().GetCardAuthorizationByRRN(gomock.Any(), gomock.Any()).Times(1).Return(db.CardAuthorization{}, sql.ErrNoRows)
+				store.EXPECT().CaptureCardTx(gomock.Any(), gomock.Any()).Times(0)
+			} else {
+				store.EXPECT().
+					GetCardAuthorizationByRRN(gomock.Any(), gomock.Eq(db.GetCardAuthorizationByRRNParams{CardID: card.ID, RRN: "406912345678"})).
+					Times(1).
+					Return(*tc.authorization, nil)
+				result := db.CaptureCardTxResult{}
+				if tc.txErr == nil {
+					account.Balance -= tc.amount
+					result = db.CaptureCardTxResult{Account: account, Entry: db.Entry{ID: 21, AccountID: account.ID, Amount: -tc.amount}}
+				}
+				store.EXPECT().
+					CaptureCardTx(gomock.Any(), gomock.Eq(db.CaptureCardTxParams{AuthorizationID: tc.authorization.ID, Amount: tc.amount})).
+					Times(1).
+					Return(result, tc.txErr)
+			}
+
+			resp, err := s.Handle(context.Background(), req)
+			require.NoError(t, err)
+			require.Equal(t, MTIAdviceResponse, resp.MTI)
+			require.Equal(t, tc.code, resp.Fields[FieldResponseCode])
+			require.Equal(t, "000124", resp.Fields[FieldSTAN])
+			if tc.code == RespApproved {
+				require.Equal(t, "654321", resp.Fields[FieldAuthCode])
+				// the entry and the new balance are published
+				require.Len(t, sub, 2)
+				require.Equal(t, events.EventTypeEntry, (<-sub).Type)
+				require.Equal(t, account.Balance, (<-sub).Account.Balance)
+			} else {
+				require.Empty(t, sub)
+			}
+		})
+	}
+}
+
+func TestSwitchReverse(t *testing.T) {
+	approved := db.CardAuthorization{ID: 11, CardID: 7, AccountID: 3, Amount: 12_345, Status: db.CardAuthApproved}
+	captured := approved
+	captured.Status = db.CardAuthCaptured
+	reversed := approved
+	reversed.Status = db.CardAuthReversed
+
+	testCases := []struct {
+		name          string
+		mti           string
+		authorization db.CardAuthorization
+		reverseErr    error
+		responseMTI   string
+		code          string
+	}{
+		{name: "Request", mti: MTIReversalRequest, authorization: approved, responseMTI: MTIReversalResponse, code: RespApproved},
+		{name: "Advice", mti: MTIReversalAdvice, authorization: approved, responseMTI: MTIReversalAdviceResponse, code: RespApproved},
+		{name: "AlreadyReversed", mti: MTIReversalRequest, authorization: reversed, reverseErr: sql.ErrNoRows, responseMTI: MTIReversalResponse, code: RespDuplicateTransaction},
+		{name: "Captured", mti: MTIReversalAdvice, authorization: captured, reverseErr: sql.ErrNoRows, responseMTI: MTIReversalAdviceResponse, code: RespInvalidTransaction},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, store, card, account := newTestSwitch(t)
+			sub, unsubscribe := s.broker.Subscribe(account.ID)
+			defer unsubscribe()
+			req := Message{
+				MTI: tc.mti,
+				Fields: map[int]string{
+					FieldPAN:    testPAN,
+					FieldAmount: FormatAmount(12_345),
+					FieldRRN:    "406912345678",
+				},
+			}
+
+			store.EXPECT().GetCardByPAN(gomock.Any(), testPAN).Times(1).Return(card, nil)
+			store.EXPECT().GetCardAuthorizationByRRN(gomock.Any(), gomock.Any()).Times(1).Return(tc.authorization, nil)
+			if tc.reverseErr == nil {
+				reversed := tc.authorization
+				reversed.Status = db.CardAuthReversed
+				store.EXPECT().ReverseCardAuthorization(gomock.Any(), tc.authorization.ID).Times(1).Return(reversed, nil)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+			} else {
+				store.EXPECT().ReverseCardAuthorization(gomock.Any(), tc.authorization.ID).Times(1).Return(db.CardAuthorization{}, tc.reverseErr)
+			}
+
+			resp, err := s.Handle(context.Background(), req)
+			require.NoError(t, err)
+			require.Equal(t, tc.responseMTI, resp.MTI)
+			require.Equal(t, tc.code, resp.Fields[FieldResponseCode])
+			if tc.code == RespApproved {
+				// the released hold is published with the balance
+				require.Len(t, sub, 1)
+				require.Equal(t, events.EventTypeBalance, (<-sub).Type)
+			} else {
+				require.Empty(t, sub)
+			}
+		})
+	}
+}
+
+func TestSwitchInvalidMTI(t *testing.T) {
+	s, _, _, _ := newTestSwitch(t)
+
+	for _, mti := range []string{"", "0110", "0800", "01A0", "01000"} {
+		_, err := s.Handle(context.Background(), Message{MTI: mti})
+		require.ErrorIs(t, err, ErrInvalidMTI, mti)
+	}
+}
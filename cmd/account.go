@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/spf13/cobra"
+)
+
+func newAccountCommand(app *app) *cobra.Command {
+	account := &cobra.Command{
+		Use:   "account",
+		Short: "Manage accounts",
+	}
+
+	account.AddCommand(
+		newSetAccountFrozenCommand(app, true),
+		newSetAccountFrozenCommand(app, false),
+		newAdjustCommand(app),
+	)
+	return account
+}
+
+func newSetAccountFrozenCommand(app *app, frozen bool) *cobra.Command {
+	use, short, action := "unfreeze ID", "Allow payments on a frozen account again", audit.ActionAccountUnfreeze
+	if frozen {
+		use, short, action = "freeze ID", "Block payments from and to an account", audit.ActionAccountFreeze
+	}
+
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := app.requireActor(); err != nil {
+				return err
+			}
+
+			id, err := parseAccountID(args[0])
+			if err != nil {
+				return err
+			}
+
+			account, err := app.store.SetAccountFrozen(cmd.Context(), db.SetAccountFrozenParams{
+				ID:     id,
+				Frozen: frozen,
+			})
+			if err != nil {
+				return notFound(err, "account %d", id)
+			}
+
+			app.audit(cmd.Context(), audit.Event{
+				Action:       action,
+				Outcome:      audit.OutcomeSuccess,
+				ResourceType: audit.ResourceAccount,
+				ResourceID:   strconv.FormatInt(account.ID, 10),
+			})
+
+			fmt.Fprintf(cmd.OutOrStdout(), "account %d frozen: %t\n", account.ID, account.Frozen)
+			return nil
+		},
+	}
+}
+
+func newAdjustCommand(app *app) *cobra.Command {
+	var amount int64
+	var reason, note, evidence string
+
+	adjust := &cobra.Command{
+		Use:   "adjust ID",
+		Short: "Propose a manual balance adjustment",
+		Long: "Propose a manual balance adjustment. The amount is in minor units and may be negative. " +
+			"Nothing is booked until a different admin approves it with 'adjustment approve'. " +
+			"Reason codes: " + strings.Join(db.AdjustmentReasons, ", ") + ".",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := app.requireAdmin(cmd); err != nil {
+				return err
+			}
+
+			id, err := parseAccountID(args[0])
+			if err != nil {
+				return err
+			}
+
+			params := db.ProposeAdjustmentParams{
+				AccountID:  id,
+				Amount:     amount,
+				ReasonCode: reason,
+				Note:       note,
+				Evidence:   evidence,
+				CreatedBy:  app.actor,
+			}
+			if app.config.AdjustmentTTL > 0 {
+				params.ExpiresAt = time.Now().Add(app.config.AdjustmentTTL)
+			}
+
+			adjustment, err := app.store.ProposeAdjustment(cmd.Context(), params)
+			if err != nil {
+				return fmt.Errorf("cannot adjust account %d: %w", id, err)
+			}
+
+			app.audit(cmd.Context(), audit.Event{
+				Action:       audit.ActionAdjustmentPropose,
+				Outcome:      audit.OutcomeSuccess,
+				ResourceType: audit.ResourceAdjustment,
+				ResourceID:   strconv.FormatInt(adjustment.ID, 10),
+				Metadata: map[string]interface{}{
+					"account_id":  id,
+					"amount":      amount,
+					"reason_code": reason,
+				},
+			})
+
+			fmt.Fprintf(cmd.OutOrStdout(), "adjustment %d proposed, awaiting approval until %s\n",
+				adjustment.ID, adjustment.ExpiresAt.Format(time.RFC3339))
+			return nil
+		},
+	}
+	adjust.Flags().Int64Var(&amount, "amount", 0, "amount in minor units, negative to debit")
+	adjust.Flags().StringVar(&reason, "reason", "", "reason code")
+	adjust.Flags().StringVar(&note, "note", "", "free text explaining the adjustment")
+	adjust.Flags().StringVar(&evidence, "evidence", "", "where the supporting evidence is, e.g. a ticket or document reference")
+	return adjust
+}
+
+func parseAccountID(arg string) (int64, error) {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid account ID %q", arg)
+	}
+	return id, nil
+}
+
+// notFound turns sql.ErrNoRows into a readable error.
+func notFound(err error, format string, args ...interface{}) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf(format+" not found", args...)
+	}
+	return err
+}
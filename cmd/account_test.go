@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/danielmoisa/neobank/audit"
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAccountCommand(t *testing.T) {
+	testCases := []struct {
+		name       string
+		args       []string
+		buildStubs func(store *mockdb.MockStore)
+		checkRun   func(t *testing.T, out string, err error)
+	}{
+		{
+			name: "Freeze",
+			args: []string{"account", "freeze", "7"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SetAccountFrozen(gomock.Any(), gomock.Eq(db.SetAccountFrozenParams{ID: 7, Frozen: true})).
+					Times(1).
+					Return(db.Account{ID: 7, Frozen: true}, nil)
+				expectAuditEvent(store, audit.ActionAccountFreeze)
+			},
+			checkRun: func(t *testing.T, out string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, out, "account 7 frozen: true")
+			},
+		},
+		{
+			name: "FreezeInvalidID",
+			args: []string{"account", "freeze", "abc"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SetAccountFrozen(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkRun: func(t *testing.T, out string, err error) {
+				require.EqualError(t, err, `invalid account ID "abc"`)
+			},
+		},
+		{
+			name: "Adjust",
+			args: []string{"account", "adjust", "7", "--amount", "-250", "--reason", db.ReasonFeeRefund, "--note", "double charge", "--evidence", "ticket 42"},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAdminActor(store)
+				store.EXPECT().
+					ProposeAdjustment(gomock.Any(), gomock.Eq(db.ProposeAdjustmentParams{
+						AccountID:  7,
+						Amount:     -250,
+						ReasonCode: db.ReasonFeeRefund,
+						Note:       "double charge",
+						Evidence:   "ticket 42",
+						CreatedBy:  "ops",
+					})).
+					Times(1).
+					Return(db.Adjustment{ID: 3, Status: db.AdjustmentPending}, nil)
+				expectAuditEvent(store, audit.ActionAdjustmentPropose)
+			},
+			checkRun: func(t *testing.T, out string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, out, "adjustment 3 proposed")
+			},
+		},
+		{
+			name: "AdjustNotAdmin",
+			args: []string{"account", "adjust", "7", "--amount", "100", "--reason", db.ReasonGoodwill, "--note", "sorry", "--evidence", "ticket 42"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("ops")).
+					Times(1).
+					Return(db.User{Username: "ops", Role: db.RoleCustomer}, nil)
+				store.EXPECT().
+					ProposeAdjustment(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkRun: func(t *testing.T, out string, err error) {
+				require.EqualError(t, err, "--actor ops is not an admin")
+			},
+		},
+		{
+			name: "AdjustInvalidReason",
+			args: []string{"account", "adjust", "7", "--amount", "100", "--reason", "because", "--note", "sorry", "--evidence", "ticket 42"},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAdminActor(store)
+				store.EXPECT().
+					ProposeAdjustment(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Adjustment{}, db.ErrInvalidReasonCode)
+			},
+			checkRun: func(t *testing.T, out string, err error) {
+				require.ErrorIs(t, err, db.ErrInvalidReasonCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			out, err := runCommand(t, store, tc.args...)
+			tc.checkRun(t, out, err)
+		})
+	}
+}
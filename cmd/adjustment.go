@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/spf13/cobra"
+)
+
+func newAdjustmentCommand(app *app) *cobra.Command {
+	adjustment := &cobra.Command{
+		Use:   "adjustment",
+		Short: "Review proposed balance adjustments",
+	}
+
+	adjustment.AddCommand(
+		newListAdjustmentsCommand(app),
+		newApproveAdjustmentCommand(app),
+		newRejectAdjustmentCommand(app),
+		newExpireAdjustmentsCommand(app),
+	)
+	return adjustment
+}
+
+func newListAdjustmentsCommand(app *app) *cobra.Command {
+	var status string
+	var limit int32
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List adjustments, oldest first",
+		Long:  "List adjustments, oldest first. Pending adjustments past their expiry are marked expired first.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := app.expireAdjustments(cmd); err != nil {
+				return err
+			}
+
+			adjustments, err := app.store.ListAdjustments(cmd.Context(), db.ListAdjustmentsParams{
+				Status: status,
+				Limit:  limit,
+			})
+			if err != nil {
+				return err
+			}
+
+			printAdjustments(cmd.OutOrStdout(), adjustments)
+			return nil
+		},
+	}
+	list.Flags().StringVar(&status, "status", db.AdjustmentPending, "pending, approved, rejected or expired")
+	list.Flags().Int32Var(&limit, "limit", 50, "maximum number of adjustments to list")
+	return list
+}
+
+func printAdjustments(out io.Writer, adjustments []db.Adjustment) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tACCOUNT\tAMOUNT\tREASON\tPROPOSED BY\tEXPIRES\tNOTE")
+	for _, a := range adjustments {
+		fmt.Fprintf(w, "%d\t%d\t%d\t%s\t%s\t%s\t%s\n",
+			a.ID, a.AccountID, a.Amount, a.ReasonCode, a.CreatedBy, a.ExpiresAt.Format(time.RFC3339), a.Note)
+	}
+	w.Flush()
+}
+
+func newApproveAdjustmentCommand(app *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "approve ID",
+		Short: "Book a pending adjustment proposed by another admin",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := app.requireAdmin(cmd); err != nil {
+				return err
+			}
+
+			id, err := parseAdjustmentID(args[0])
+			if err != nil {
+				return err
+			}
+
+			result, err := app.store.ApproveAdjustmentTx(cmd.Context(), db.ReviewAdjustmentTxParams{
+				AdjustmentID: id,
+				ReviewedBy:   app.actor,
+			})
+			if err != nil {
+				return app.reviewError(cmd, audit.ActionAdjustmentApprove, id, err)
+			}
+
+			app.audit(cmd.Context(), audit.Event{
+				Action:       audit.ActionAdjustmentApprove,
+				Outcome:      audit.OutcomeSuccess,
+				ResourceType: audit.ResourceAdjustment,
+				ResourceID:   strconv.FormatInt(id, 10),
+				Metadata: map[string]interface{}{
+					"account_id":        result.Account.ID,
+					"amount":            result.Adjustment.Amount,
+					"entry_id":          result.Entry.ID,
+					"suspense_entry_id": result.SuspenseEntry.ID,
+				},
+			})
+
+			fmt.Fprintf(cmd.OutOrStdout(), "adjustment %d approved: account %d balance is now %d %s\n",
+				id, result.Account.ID, result.Account.Balance, result.Account.Currency)
+			return nil
+		},
+	}
+}
+
+func newRejectAdjustmentCommand(app *app) *cobra.Command {
+	var note string
+
+	reject := &cobra.Command{
+		Use:   "reject ID",
+		Short: "Close a pending adjustment proposed by another admin without booking it",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := app.requireAdmin(cmd); err != nil {
+				return err
+			}
+			if note == "" {
+				return fmt.Errorf("--note is required")
+			}
+
+			id, err := parseAdjustmentID(args[0])
+			if err != nil {
+				return err
+			}
+
+			_, err = app.store.RejectAdjustmentTx(cmd.Context(), db.ReviewAdjustmentTxParams{
+				AdjustmentID: id,
+				ReviewedBy:   app.actor,
+				Note:         note,
+			})
+			if err != nil {
+				return app.reviewError(cmd, audit.ActionAdjustmentReject, id, err)
+			}
+
+			app.audit(cmd.Context(), audit.Event{
+				Action:       audit.ActionAdjustmentReject,
+				Outcome:      audit.OutcomeSuccess,
+				ResourceType: audit.ResourceAdjustment,
+				ResourceID:   strconv.FormatInt(id, 10),
+			})
+
+			fmt.Fprintf(cmd.OutOrStdout(), "adjustment %d rejected\n", id)
+			return nil
+		},
+	}
+	reject.Flags().StringVar(&note, "note", "", "why the adjustment is rejected")
+	return reject
+}
+
+func newExpireAdjustmentsCommand(app *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "expire",
+		Short: "Mark pending adjustments past their expiry as expired",
+		Long: "Mark pending adjustments past their expiry as expired. The API does this whenever the " +
+			"review queue is listed; run this from cron to keep the queue tidy without it.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			expired, err := app.expireAdjustments(cmd)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "%d adjustment(s) expired\n", expired)
+			return nil
+		},
+	}
+}
+
+// expireAdjustments closes the pending adjustments nobody reviewed in time.
+func (app *app) expireAdjustments(cmd *cobra.Command) (int, error) {
+	expired, err := app.store.ExpireAdjustments(cmd.Context())
+	if err != nil {
+		return 0, err
+	}
+
+	for _, adjustment := range expired {
+		app.auditExpiry(cmd, adjustment.ID)
+	}
+	return len(expired), nil
+}
+
+func (app *app) auditExpiry(cmd *cobra.Command, id int64) {
+	app.audit(cmd.Context(), audit.Event{
+		Action:       audit.ActionAdjustmentExpire,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceAdjustment,
+		ResourceID:   strconv.FormatInt(id, 10),
+	})
+}
+
+// reviewError explains why an adjustment could not be reviewed. Attempts to
+// review one's own adjustment are audited.
+func (app *app) reviewError(cmd *cobra.Command, action string, id int64, err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return notFound(err, "adjustment %d", id)
+	case errors.Is(err, db.ErrSelfReview):
+		app.audit(cmd.Context(), audit.Event{
+			Action:       action,
+			Outcome:      audit.OutcomeFailure,
+			ResourceType: audit.ResourceAdjustment,
+			ResourceID:   strconv.FormatInt(id, 10),
+			Metadata:     map[string]interface{}{"reason": "self_review"},
+		})
+	case errors.Is(err, db.ErrAdjustmentExpired):
+		app.auditExpiry(cmd, id)
+	}
+	return fmt.Errorf("adjustment %d: %w", id, err)
+}
+
+func parseAdjustmentID(arg string) (int64, error) {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid adjustment ID %q", arg)
+	}
+	return id, nil
+}
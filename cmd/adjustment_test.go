@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/danielmoisa/neobank/audit"
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAdjustmentCommand(t *testing.T) {
+	otherPasswordHash, err := utils.HashPassword("someone-else")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		args       []string
+		buildStubs func(store *mockdb.MockStore)
+		checkRun   func(t *testing.T, out string, err error)
+	}{
+		{
+			name: "Approve",
+			args: []string{"adjustment", "approve", "3"},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAdminActor(store)
+				store.EXPECT().
+					ApproveAdjustmentTx(gomock.Any(), gomock.Eq(db.ReviewAdjustmentTxParams{AdjustmentID: 3, ReviewedBy: "ops"})).
+					Times(1).
+					Return(db.ApproveAdjustmentTxResult{
+						Adjustment: db.Adjustment{ID: 3, Amount: -250, Status: db.AdjustmentApproved},
+						Account:    db.Account{ID: 7, Balance: 750, Currency: "EUR"},
+					}, nil)
+				expectAuditEvent(store, audit.ActionAdjustmentApprove)
+			},
+			checkRun: func(t *testing.T, out string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, out, "balance is now 750 EUR")
+			},
+		},
+		{
+			name: "ApproveOwn",
+			args: []string{"adjustment", "approve", "3"},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAdminActor(store)
+				store.EXPECT().
+					ApproveAdjustmentTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ApproveAdjustmentTxResult{}, db.ErrSelfReview)
+				store.EXPECT().
+					CreateAuditEvent(gomock.Any(), gomock.Cond(func(x any) bool {
+						arg, ok := x.(db.CreateAuditEventParams)
+						return ok && arg.Action == audit.ActionAdjustmentApprove && arg.Outcome == audit.OutcomeFailure
+					})).
+					Times(1)
+			},
+			checkRun: func(t *testing.T, out string, err error) {
+				require.ErrorIs(t, err, db.ErrSelfReview)
+			},
+		},
+		{
+			name: "ApproveWrongPassword",
+			args: []string{"adjustment", "approve", "3"},
+			buildStubs: func(store *mockdb.MockStore) {
+				// the --actor names an admin, but whoever runs the command
+				// doesn't know their password
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("ops")).
+					Times(1).
+					Return(db.User{Username: "ops", Role: db.RoleAdmin, HashedPassword: otherPasswordHash}, nil)
+				store.EXPECT().
+					ApproveAdjustmentTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkRun: func(t *testing.T, out string, err error) {
+				require.EqualError(t, err, "wrong password for --actor ops")
+			},
+		},
+		{
+			name: "RejectWithoutNote",
+			args: []string{"adjustment", "reject", "3"},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAdminActor(store)
+				store.EXPECT().
+					RejectAdjustmentTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkRun: func(t *testing.T, out string, err error) {
+				require.EqualError(t, err, "--note is required")
+			},
+		},
+		{
+			name: "Reject",
+			args: []string{"adjustment", "reject", "3", "--note", "duplicate"},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAdminActor(store)
+				store.EXPECT().
+					RejectAdjustmentTx(gomock.Any(), gomock.Eq(db.ReviewAdjustmentTxParams{AdjustmentID: 3, ReviewedBy: "ops", Note: "duplicate"})).
+					Times(1).
+					Return(db.Adjustment{ID: 3, Status: db.AdjustmentRejected}, nil)
+				expectAuditEvent(store, audit.ActionAdjustmentReject)
+			},
+			checkRun: func(t *testing.T, out string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, out, "adjustment 3 rejected")
+			},
+		},
+		{
+			name: "List",
+			args: []string{"adjustment", "list"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ExpireAdjustments(gomock.Any()).
+					Times(1).
+					Return([]db.Adjustment{{ID: 2, Status: db.AdjustmentExpired}}, nil)
+				expectAuditEvent(store, audit.ActionAdjustmentExpire)
+				store.EXPECT().
+					ListAdjustments(gomock.Any(), gomock.Eq(db.ListAdjustmentsParams{Status: db.AdjustmentPending, Limit: 50})).
+					Times(1).
+					Return([]db.Adjustment{{ID: 3, AccountID: 7, Amount: -250, ReasonCode: db.ReasonFeeRefund, CreatedBy: "jane"}}, nil)
+			},
+			checkRun: func(t *testing.T, out string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, out, "fee_refund")
+				require.Contains(t, out, "jane")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			out, err := runCommand(t, store, tc.args...)
+			tc.checkRun(t, out, err)
+		})
+	}
+}
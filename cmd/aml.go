@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/danielmoisa/neobank/monitoring"
+	"github.com/spf13/cobra"
+)
+
+func newAMLCommand(app *app) *cobra.Command {
+	aml := &cobra.Command{
+		Use:   "aml",
+		Short: "Transaction monitoring",
+	}
+
+	var scenarios []string
+	run := &cobra.Command{
+		Use:   "run",
+		Short: "Run the transaction monitoring scenarios once",
+		Long: "Run the monitoring scenarios over their window ending now and record the alerts they " +
+			"raise, like the job the server runs every AML_JOB_INTERVAL. Scenarios: " +
+			strings.Join([]string{monitoring.ScenarioStructuring, monitoring.ScenarioPassThrough,
+				monitoring.ScenarioRoundTrip, monitoring.ScenarioDormant}, ", ") + ".",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			runs, err := monitoring.New(app.config, app.store).Run(cmd.Context(), scenarios...)
+			for _, run := range runs {
+				status := "ok"
+				if run.Error != "" {
+					status = run.Error
+				}
+				fmt.Fprintf(out, "%s: %d alert(s), %s\n", run.Scenario, run.Alerts, status)
+			}
+			return err
+		},
+	}
+	run.Flags().StringSliceVar(&scenarios, "scenario", nil, "scenario to run, repeatable (default all)")
+
+	aml.AddCommand(run)
+	return aml
+}
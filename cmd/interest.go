@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/danielmoisa/neobank/events"
+	"github.com/danielmoisa/neobank/interest"
+	"github.com/spf13/cobra"
+)
+
+func newInterestCommand(app *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "interest",
+		Short: "Savings interest",
+	}
+
+	var date string
+	run := &cobra.Command{
+		Use:   "run",
+		Short: "Accrue and pay out savings interest once",
+		Long: "Accrue the interest of every savings account for each day before --date not accrued yet and " +
+			"pay out the periods ended by then, like the job the server runs every INTEREST_JOB_INTERVAL.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			today := time.Now()
+			if date != "" {
+				var err error
+				today, err = time.Parse(time.DateOnly, date)
+				if err != nil {
+					return fmt.Errorf("invalid date %q", date)
+				}
+			}
+
+			// with the postgres broker, the subscribers of the server hear of
+			// the money moved
+			broker, err := events.NewBroker(app.config)
+			if err != nil {
+				return fmt.Errorf("cannot create event broker: %w", err)
+			}
+			defer broker.Close()
+
+			result, err := interest.NewJob(app.store, broker).Run(cmd.Context(), today)
+			fmt.Fprintf(cmd.OutOrStdout(), "%d account(s), %d accrual(s), %d payout(s)\n", result.Accounts, result.Accruals, result.Payouts)
+			return err
+		},
+	}
+	run.Flags().StringVar(&date, "date", "", "day to run as, YYYY-MM-DD (default today)")
+
+	cmd.AddCommand(run)
+	return cmd
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestInterestRunCommand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListSavingsAccounts(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]db.ListSavingsAccountsRow{{
+			AccountID:       1,
+			DayCount:        db.DayCountACT365,
+			Compounding:     db.CompoundingNone,
+			PayoutFrequency: db.PayoutMonthly,
+		}}, nil)
+	store.EXPECT().
+		GetLastInterestAccrual(gomock.Any(), gomock.Eq(int64(1))).
+		Times(1).
+		Return(db.InterestAccrual{AccrualDate: time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC)}, nil)
+	store.EXPECT().
+		GetLastInterestPayout(gomock.Any(), gomock.Eq(int64(1))).
+		Times(1).
+		Return(db.InterestPayout{PeriodEnd: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)}, nil)
+	store.EXPECT().
+		PayInterestTx(gomock.Any(), gomock.Eq(db.PayInterestTxParams{
+			AccountID: 1,
+			PeriodEnd: time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC),
+		})).
+		Times(1).
+		Return(db.PayInterestTxResult{}, nil)
+
+	out, err := runCommand(t, store, "interest", "run", "--date", "2024-04-01")
+	require.NoError(t, err)
+	require.Contains(t, out, "1 account(s), 0 accrual(s), 1 payout(s)")
+
+	_, err = runCommand(t, store, "interest", "run", "--date", "April")
+	require.EqualError(t, err, `invalid date "April"`)
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func newLedgerCommand(app *app) *cobra.Command {
+	ledger := &cobra.Command{
+		Use:   "ledger",
+		Short: "Inspect the ledger",
+	}
+
+	ledger.AddCommand(&cobra.Command{
+		Use:   "verify",
+		Short: "Check that every balance matches its entries",
+		Long: "Check that every account balance equals the sum of its entries, and that all entries " +
+			"net to zero, i.e. neither payments nor adjustments create or destroy money. Adjustments " +
+			"booked before they were balanced against a suspense account are allowed for.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			mismatches, err := app.store.ListLedgerMismatches(cmd.Context())
+			if err != nil {
+				return err
+			}
+			for _, m := range mismatches {
+				fmt.Fprintf(out, "account %d: balance %d, entries %d, difference %d\n",
+					m.ID, m.Balance, m.EntriesTotal, m.Balance-m.EntriesTotal)
+			}
+
+			totals, err := app.store.GetLedgerTotals(cmd.Context())
+			if err != nil {
+				return err
+			}
+			unbalanced := totals.EntriesTotal != totals.AdjustmentsTotal
+			if unbalanced {
+				fmt.Fprintf(out, "entries total %d, adjustments total %d, difference %d\n",
+					totals.EntriesTotal, totals.AdjustmentsTotal, totals.EntriesTotal-totals.AdjustmentsTotal)
+			}
+
+			if len(mismatches) > 0 || unbalanced {
+				return fmt.Errorf("ledger does not balance: %d account(s) mismatched", len(mismatches))
+			}
+
+			fmt.Fprintln(out, "ledger balances")
+			return nil
+		},
+	})
+	return ledger
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"testing"
+
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLedgerVerifyCommand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListLedgerMismatches(gomock.Any()).
+		Times(1).
+		Return([]db.ListLedgerMismatchesRow{}, nil)
+	store.EXPECT().
+		GetLedgerTotals(gomock.Any()).
+		Times(1).
+		Return(db.GetLedgerTotalsRow{EntriesTotal: 500, AdjustmentsTotal: 500}, nil)
+
+	out, err := runCommand(t, store, "ledger", "verify")
+	require.NoError(t, err)
+	require.Contains(t, out, "ledger balances")
+}
+
+func TestLedgerVerifyCommandMismatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListLedgerMismatches(gomock.Any()).
+		Times(1).
+		Return([]db.ListLedgerMismatchesRow{{ID: 7, Balance: 900, EntriesTotal: 1000}}, nil)
+	store.EXPECT().
+		GetLedgerTotals(gomock.Any()).
+		Times(1).
+		Return(db.GetLedgerTotalsRow{EntriesTotal: 500, AdjustmentsTotal: 500}, nil)
+
+	out, err := runCommand(t, store, "ledger", "verify")
+	require.Error(t, err)
+	require.Contains(t, out, "account 7: balance 900, entries 1000, difference -100")
+}
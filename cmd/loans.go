@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/danielmoisa/neobank/events"
+	"github.com/danielmoisa/neobank/loans"
+	"github.com/spf13/cobra"
+)
+
+func newLoansCommand(app *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "loans",
+		Short: "Consumer loans",
+	}
+
+	var date string
+	collect := &cobra.Command{
+		Use:   "collect",
+		Short: "Collect the loan installments due once",
+		Long: "Collect every loan installment due on or before --date that isn't paid yet from the borrowers' accounts " +
+			"and charge late fees past the grace days, like the job the server runs every LOAN_JOB_INTERVAL.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			today := time.Now()
+			if date != "" {
+				var err error
+				today, err = time.Parse(time.DateOnly, date)
+				if err != nil {
+					return fmt.Errorf("invalid date %q", date)
+				}
+			}
+
+			// with the postgres broker, the subscribers of the server hear of
+			// the money moved
+			broker, err := events.NewBroker(app.config)
+			if err != nil {
+				return fmt.Errorf("cannot create event broker: %w", err)
+			}
+			defer broker.Close()
+
+			result, err := loans.NewJob(app.store, broker).Run(cmd.Context(), today)
+			fmt.Fprintf(cmd.OutOrStdout(), "%d installment(s), %d paid, %d overdue, %d collected\n", result.Installments, result.Paid, result.Overdue, result.Collected)
+			return err
+		},
+	}
+	collect.Flags().StringVar(&date, "date", "", "day to run as, YYYY-MM-DD (default today)")
+
+	cmd.AddCommand(collect)
+	return cmd
+}
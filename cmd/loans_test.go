@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLoansCollectCommand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	today := time.Date(2024, time.April, 15, 0, 0, 0, 0, time.UTC)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListDueLoanInstallments(gomock.Any(), gomock.Eq(db.ListDueLoanInstallmentsParams{DueOn: today, Limit: 100})).
+		Times(1).
+		Return([]db.LoanInstallment{{ID: 1, DueDate: today, Status: db.InstallmentScheduled}}, nil)
+	store.EXPECT().
+		CollectInstallmentTx(gomock.Any(), gomock.Eq(db.CollectInstallmentTxParams{InstallmentID: 1, Today: today})).
+		Times(1).
+		Return(db.CollectInstallmentTxResult{
+			Installment: db.LoanInstallment{ID: 1, Status: db.InstallmentPaid},
+			Repayment:   db.LoanRepayment{Amount: 88_849},
+		}, nil)
+
+	out, err := runCommand(t, store, "loans", "collect", "--date", "2024-04-15")
+	require.NoError(t, err)
+	require.Contains(t, out, "1 installment(s), 1 paid, 0 overdue, 88849 collected")
+
+	_, err = runCommand(t, store, "loans", "collect", "--date", "April")
+	require.EqualError(t, err, `invalid date "April"`)
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/utils"
+	"go.uber.org/mock/gomock"
+)
+
+// adminPassword is what the --actor types when a command asks for it.
+const adminPassword = "secret-admin"
+
+var adminPasswordHash = func() string {
+	hashedPassword, err := utils.HashPassword(adminPassword)
+	if err != nil {
+		panic(err)
+	}
+	return hashedPassword
+}()
+
+// runCommand runs the command line args against store and returns what the
+// command printed.
+func runCommand(t *testing.T, store db.Store, args ...string) (string, error) {
+	app := &app{
+		store: store,
+		config: utils.Config{
+			TokenSymmetricKey:   utils.RandomString(32),
+			AccessTokenDuration: time.Minute,
+		},
+	}
+
+	var out bytes.Buffer
+	root := newRootCommand(app)
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetIn(strings.NewReader(adminPassword + "\n"))
+	root.SetArgs(append([]string{"--actor", "ops"}, args...))
+
+	err := root.Execute()
+	return out.String(), err
+}
+
+// expectAuditEvent expects exactly one audit event with the given action,
+// recorded as the --actor.
+func expectAuditEvent(store *mockdb.MockStore, action string) {
+	store.EXPECT().
+		CreateAuditEvent(gomock.Any(), gomock.Cond(func(x any) bool {
+			arg, ok := x.(db.CreateAuditEventParams)
+			return ok && arg.Action == action && arg.Actor == "ops"
+		})).
+		Times(1).
+		Return(db.AuditEvent{}, nil)
+}
+
+// expectAdminActor makes the --actor an admin whose password is
+// adminPassword.
+func expectAdminActor(store *mockdb.MockStore) {
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq("ops")).
+		Times(1).
+		Return(db.User{Username: "ops", Role: db.RoleAdmin, HashedPassword: adminPasswordHash}, nil)
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/danielmoisa/neobank/db/migrations"
+	"github.com/spf13/cobra"
+)
+
+func newMigrateCommand(app *app) *cobra.Command {
+	migrate := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply or roll back the embedded database migrations",
+	}
+
+	migrate.AddCommand(
+		&cobra.Command{
+			Use:   "up",
+			Short: "Apply every pending migration",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return app.withMigrator(cmd, func(migrator *migrations.Migrator) error {
+					return migrator.Up()
+				})
+			},
+		},
+		&cobra.Command{
+			Use:   "down [N]",
+			Short: "Roll back the last N migrations (default 1)",
+			Args:  cobra.MaximumNArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				steps := 1
+				if len(args) == 1 {
+					var err error
+					steps, err = strconv.Atoi(args[0])
+					if err != nil {
+						return fmt.Errorf("invalid number of steps %q", args[0])
+					}
+				}
+				return app.withMigrator(cmd, func(migrator *migrations.Migrator) error {
+					return migrator.Down(steps)
+				})
+			},
+		},
+		&cobra.Command{
+			Use:   "status",
+			Short: "Show the schema version",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return app.withMigrator(cmd, func(migrator *migrations.Migrator) error {
+					return nil
+				})
+			},
+		},
+		&cobra.Command{
+			Use:   "force VERSION",
+			Short: "Set the schema version without migrating, to recover a dirty database",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				version, err := strconv.Atoi(args[0])
+				if err != nil {
+					return fmt.Errorf("invalid version %q", args[0])
+				}
+				return app.withMigrator(cmd, func(migrator *migrations.Migrator) error {
+					return migrator.Force(version)
+				})
+			},
+		},
+	)
+
+	return migrate
+}
+
+// withMigrator runs fn and then prints the resulting schema status.
+func (app *app) withMigrator(cmd *cobra.Command, fn func(*migrations.Migrator) error) error {
+	migrator, err := migrations.NewMigrator(cmd.Context(), app.conn)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	if err := fn(migrator); err != nil {
+		return err
+	}
+
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "version: %d\nlatest: %d\ndirty: %t\n", status.Version, status.Latest, status.Dirty)
+	return nil
+}
@@ -0,0 +1,164 @@
+// Package cmd implements the neobank binary: the API servers and the
+// operator commands used for support tasks.
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/logging"
+	"github.com/danielmoisa/neobank/utils"
+	_ "github.com/lib/pq"
+	"github.com/spf13/cobra"
+)
+
+// app is the state shared by the commands. It is filled in before a command
+// runs, unless a test already provided the store.
+type app struct {
+	config utils.Config
+	conn   *sql.DB
+	store  db.Store
+	actor  string
+}
+
+// Execute runs the command named on the command line. Without a command it
+// serves the API, as the binary always did.
+func Execute() {
+	if err := newRootCommand(&app{}).Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func newRootCommand(app *app) *cobra.Command {
+	root := &cobra.Command{
+		Use:          "neobank",
+		Short:        "Neobank API server and operator tools",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// the server logs to stdout like it always did; the other
+			// commands keep stdout for their output
+			logOutput := cmd.ErrOrStderr()
+			if isServeCommand(cmd) {
+				logOutput = cmd.OutOrStdout()
+			}
+			return app.load(logOutput)
+		},
+		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+			return app.close()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return app.serve(cmd.Context())
+		},
+	}
+
+	root.PersistentFlags().StringVar(&app.actor, "actor", os.Getenv("USER"), "operator name recorded in the audit trail")
+
+	root.AddCommand(
+		newServeCommand(app),
+		newMigrateCommand(app),
+		newUserCommand(app),
+		newAccountCommand(app),
+		newAdjustmentCommand(app),
+		newLedgerCommand(app),
+		newTokenCommand(app),
+		newScreeningCommand(app),
+		newAMLCommand(app),
+		newInterestCommand(app),
+		newLoansCommand(app),
+	)
+	return root
+}
+
+func (app *app) load(logOutput io.Writer) error {
+	if app.store != nil {
+		return nil
+	}
+
+	config, err := utils.LoadConfig(".")
+	if err != nil {
+		return fmt.Errorf("cannot load config: %w", err)
+	}
+
+	slog.SetDefault(logging.NewLogger(logOutput, config.LogLevel))
+
+	conn, err := sql.Open(config.DBDriver, config.DBSource())
+	if err != nil {
+		return fmt.Errorf("cannot connect to db: %w", err)
+	}
+
+	app.config = config
+	app.conn = conn
+	app.store = db.NewStore(conn)
+	return nil
+}
+
+func (app *app) close() error {
+	if app.conn == nil {
+		return nil
+	}
+	return app.conn.Close()
+}
+
+// audit records an operator action. Operator commands always act as the
+// --actor, so the trail shows who ran them.
+func (app *app) audit(ctx context.Context, event audit.Event) {
+	event.Actor = app.actor
+	if event.Metadata == nil {
+		event.Metadata = map[string]interface{}{}
+	}
+	event.Metadata["via"] = "cli"
+	audit.Record(ctx, app.store, event)
+}
+
+// requireActor makes commands that change data refuse to run anonymously.
+func (app *app) requireActor() error {
+	if app.actor == "" {
+		return fmt.Errorf("--actor is required")
+	}
+	return nil
+}
+
+// requireAdmin makes sure the --actor is an enabled admin user who knows
+// their password, read from stdin. Adjustments are reviewed by a second
+// admin, so the actor has to be someone the API would also accept, and
+// anyone can type a name into --actor.
+func (app *app) requireAdmin(cmd *cobra.Command) error {
+	if err := app.requireActor(); err != nil {
+		return err
+	}
+
+	user, err := app.store.GetUser(cmd.Context(), app.actor)
+	if err != nil {
+		return notFound(err, "--actor %s", app.actor)
+	}
+	if user.Disabled {
+		return fmt.Errorf("--actor %s is disabled", app.actor)
+	}
+	if user.Role != db.RoleAdmin {
+		return fmt.Errorf("--actor %s is not an admin", app.actor)
+	}
+	if user.LockedUntil.After(time.Now()) {
+		return fmt.Errorf("--actor %s is locked", app.actor)
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "password for %s: ", app.actor)
+	password, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("cannot read the password: %w", err)
+	}
+	if err := utils.CheckPassword(user.HashedPassword, strings.TrimRight(password, "\r\n")); err != nil {
+		return fmt.Errorf("wrong password for --actor %s", app.actor)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/danielmoisa/neobank/screening"
+	"github.com/spf13/cobra"
+)
+
+func newScreeningCommand(app *app) *cobra.Command {
+	screeningCmd := &cobra.Command{
+		Use:   "screening",
+		Short: "Sanctions screening",
+	}
+
+	var threshold float64
+	check := &cobra.Command{
+		Use:   "check NAME",
+		Short: "Screen a name against the configured sanctions lists",
+		Long: "Screen a name against the lists in SCREENING_OFAC_SDN_FILE and SCREENING_EU_FILE and " +
+			"print the entries it matches with their scores. Nothing is recorded; use --threshold " +
+			"to see how a different SCREENING_THRESHOLD would behave.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config := app.config
+			if threshold > 0 {
+				config.ScreeningThreshold = threshold
+			}
+
+			screener, err := screening.New(config)
+			if err != nil {
+				return err
+			}
+			if screener.Len() == 0 {
+				return fmt.Errorf("no sanctions lists are configured")
+			}
+
+			out := cmd.OutOrStdout()
+			matches := screener.Screen(strings.Join(args, " "))
+			for _, match := range matches {
+				fmt.Fprintf(out, "%.3f %s %s %s\n", match.Score, match.List, match.EntryID, match.EntryName)
+			}
+			if len(matches) == 0 {
+				fmt.Fprintf(out, "no match at threshold %.3f\n", screener.Threshold())
+			}
+			return nil
+		},
+	}
+	check.Flags().Float64Var(&threshold, "threshold", 0, "score from which a name matches (default SCREENING_THRESHOLD)")
+
+	screeningCmd.AddCommand(check)
+	return screeningCmd
+}
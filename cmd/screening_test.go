@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestScreeningCheckCommand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sdnFile := filepath.Join(t.TempDir(), "sdn.csv")
+	sdn := `9874,"PUTIN, Vladimir Vladimirovich","individual","RUSSIA-EO14024",-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0-` + "\n"
+	require.NoError(t, os.WriteFile(sdnFile, []byte(sdn), 0o600))
+
+	run := func(args ...string) (string, error) {
+		app := &app{
+			store:  mockdb.NewMockStore(ctrl),
+			config: utils.Config{ScreeningOFACFile: sdnFile},
+		}
+
+		var out bytes.Buffer
+		root := newRootCommand(app)
+		root.SetOut(&out)
+		root.SetErr(&out)
+		root.SetArgs(args)
+
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	out, err := run("screening", "check", "Vladimir", "Poutine")
+	require.NoError(t, err)
+	require.Contains(t, out, "ofac_sdn 9874 PUTIN, Vladimir Vladimirovich")
+
+	out, err = run("screening", "check", "Vladimir Poutine", "--threshold", "0.99")
+	require.NoError(t, err)
+	require.Equal(t, "no match at threshold 0.990\n", out)
+
+	_, err = runCommand(t, mockdb.NewMockStore(ctrl), "screening", "check", "Vladimir Putin")
+	require.EqualError(t, err, "no sanctions lists are configured")
+}
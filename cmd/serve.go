@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/danielmoisa/neobank/api"
+	"github.com/danielmoisa/neobank/blob"
+	"github.com/danielmoisa/neobank/db/migrations"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/events"
+	"github.com/danielmoisa/neobank/fraud"
+	"github.com/danielmoisa/neobank/gapi"
+	"github.com/danielmoisa/neobank/interest"
+	"github.com/danielmoisa/neobank/loans"
+	"github.com/danielmoisa/neobank/mail"
+	"github.com/danielmoisa/neobank/metrics"
+	"github.com/danielmoisa/neobank/monitoring"
+	"github.com/danielmoisa/neobank/ratelimit"
+	"github.com/danielmoisa/neobank/screening"
+	"github.com/danielmoisa/neobank/tracing"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel"
+)
+
+// defaultShutdownTimeout is used when SHUTDOWN_TIMEOUT is not set. It should
+// stay below the orchestrator's grace period, e.g. Kubernetes' 30s default.
+const defaultShutdownTimeout = 25 * time.Second
+
+// rateLimitPruneInterval is how often stale buckets are deleted from
+// rate_limit_buckets when the postgres limiter is used.
+const rateLimitPruneInterval = 10 * time.Minute
+
+func newServeCommand(app *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Run the HTTP and gRPC servers",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return app.serve(cmd.Context())
+		},
+	}
+}
+
+func isServeCommand(cmd *cobra.Command) bool {
+	return !cmd.HasParent() || cmd.Name() == "serve"
+}
+
+// serve runs both servers until SIGINT or SIGTERM and then drains them.
+func (app *app) serve(ctx context.Context) error {
+	config := app.config
+	conn := app.conn
+
+	if config.MigrateOnStart {
+		if err := migrations.UpWithLock(ctx, conn); err != nil {
+			return fmt.Errorf("cannot migrate db: %w", err)
+		}
+	}
+
+	shutdownTracing, err := tracing.Setup(ctx, config)
+	if err != nil {
+		return fmt.Errorf("cannot set up tracing: %w", err)
+	}
+
+	metrics := metrics.New()
+	metrics.RegisterDBStats(conn)
+
+	store := db.NewStore(conn,
+		db.WithPaymentObserver(metrics),
+		db.WithTracerProvider(otel.GetTracerProvider()),
+	)
+
+	broker, err := events.NewBroker(config)
+	if err != nil {
+		return fmt.Errorf("cannot create event broker: %w", err)
+	}
+
+	limiter, err := ratelimit.New(config, store)
+	if err != nil {
+		return fmt.Errorf("cannot create rate limiter: %w", err)
+	}
+
+	mailer, err := mail.New(config)
+	if err != nil {
+		return fmt.Errorf("cannot create mailer: %w", err)
+	}
+
+	blobs, err := blob.New(config)
+	if err != nil {
+		return fmt.Errorf("cannot create blob storage: %w", err)
+	}
+
+	screener, err := screening.New(config)
+	if err != nil {
+		return fmt.Errorf("cannot load sanctions lists: %w", err)
+	}
+	slog.Info("loaded sanctions lists", slog.Int("entries", screener.Len()))
+
+	fraudEngine, err := fraud.New(config)
+	if err != nil {
+		return fmt.Errorf("cannot load fraud rules: %w", err)
+	}
+	slog.Info("loaded fraud rules", slog.Int("rules", fraudEngine.Len()))
+
+	httpServer, err := api.NewServer(config, store, broker, metrics, limiter, mailer, blobs, screener, fraudEngine)
+	if err != nil {
+		return fmt.Errorf("cannot create server: %w", err)
+	}
+
+	grpcServer, err := gapi.NewServer(config, store, broker, metrics, limiter, mailer, screener, fraudEngine)
+	if err != nil {
+		return fmt.Errorf("cannot create gRPC server: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 2)
+	go func() {
+		slog.Info("start HTTP server", slog.String("address", config.ServerAddress))
+		if err := httpServer.Start(config.ServerAddress); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- fmt.Errorf("HTTP server: %w", err)
+		}
+	}()
+	go func() {
+		slog.Info("start gRPC server", slog.String("address", config.GRPCServerAddress))
+		if err := grpcServer.Start(config.GRPCServerAddress); err != nil {
+			serveErr <- fmt.Errorf("gRPC server: %w", err)
+		}
+	}()
+
+	// the monitoring job stops with ctx, between runs or in the middle of one
+	monitoringDone := make(chan struct{})
+	if config.AMLJobInterval > 0 {
+		go func() {
+			defer close(monitoringDone)
+			slog.Info("start transaction monitoring", slog.Duration("interval", config.AMLJobInterval))
+			monitoring.New(config, store).Start(ctx, config.AMLJobInterval)
+		}()
+	} else {
+		close(monitoringDone)
+	}
+
+	// so does the interest job; accruals and payouts not made yet are picked
+	// up by the next run
+	interestDone := make(chan struct{})
+	if config.InterestJobInterval > 0 {
+		go func() {
+			defer close(interestDone)
+			slog.Info("start interest job", slog.Duration("interval", config.InterestJobInterval))
+			interest.NewJob(store, broker).Start(ctx, config.InterestJobInterval)
+		}()
+	} else {
+		close(interestDone)
+	}
+
+	// and the loan collection job; installments it didn't get to stay due
+	loansDone := make(chan struct{})
+	if config.LoanJobInterval > 0 {
+		go func() {
+			defer close(loansDone)
+			slog.Info("start loan collection job", slog.Duration("interval", config.LoanJobInterval))
+			loans.NewJob(store, broker).Start(ctx, config.LoanJobInterval)
+		}()
+	} else {
+		close(loansDone)
+	}
+
+	// every replica prunes the shared rate limit buckets; the deletes don't
+	// conflict
+	pruneDone := make(chan struct{})
+	if pgLimiter, ok := limiter.(*ratelimit.PGLimiter); ok {
+		go func() {
+			defer close(pruneDone)
+			pgLimiter.Start(ctx, rateLimitPruneInterval)
+		}()
+	} else {
+		close(pruneDone)
+	}
+
+	var failure error
+	select {
+	case <-ctx.Done():
+		slog.Info("received shutdown signal")
+	case failure = <-serveErr:
+		slog.Error("server stopped unexpectedly", slog.Any("error", failure))
+	}
+	stop()
+
+	timeout := config.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// Stop taking requests first; both servers wait for the requests they are
+	// still serving. Then wait for any PaymentTx that outlived its request,
+	// and only then release the broker and the database.
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			slog.Error("cannot drain HTTP server", slog.Any("error", err))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		if err := grpcServer.Shutdown(shutdownCtx); err != nil {
+			slog.Error("cannot drain gRPC server", slog.Any("error", err))
+		}
+	}()
+	wg.Wait()
+	<-monitoringDone
+	<-interestDone
+	<-loansDone
+	<-pruneDone
+
+	if err := store.Drain(shutdownCtx); err != nil {
+		slog.Error("payments still running at shutdown timeout", slog.Any("error", err))
+	}
+	if err := broker.Close(); err != nil {
+		slog.Error("cannot close event broker", slog.Any("error", err))
+	}
+	if err := conn.Close(); err != nil {
+		slog.Error("cannot close db", slog.Any("error", err))
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("cannot flush traces", slog.Any("error", err))
+	}
+
+	slog.Info("shutdown complete")
+	return failure
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danielmoisa/neobank/audit"
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/tokens"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTokenMintCommand(t *testing.T) {
+	username := utils.RandomOwner()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(username)).
+		Times(1).
+		Return(db.User{Username: username}, nil)
+	expectAuditEvent(store, audit.ActionTokenMint)
+
+	out, err := runCommand(t, store, "token", "mint", username, "--duration", "5m")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(out, "v2.local."))
+}
+
+func TestTokenMintCommandDisabledUser(t *testing.T) {
+	username := utils.RandomOwner()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(username)).
+		Times(1).
+		Return(db.User{Username: username, Disabled: true}, nil)
+	store.EXPECT().
+		CreateAuditEvent(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	_, err := runCommand(t, store, "token", "mint", username)
+	require.EqualError(t, err, "user "+username+" is disabled")
+}
+
+func TestTokenKeygenCommand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	keyFile := filepath.Join(t.TempDir(), "token_key")
+	out, err := runCommand(t, mockdb.NewMockStore(ctrl), "token", "keygen", keyFile)
+	require.NoError(t, err)
+
+	keyring, err := tokens.LoadKeyring(keyFile, []string{keyFile + ".pub"})
+	require.NoError(t, err)
+	keyID, _, err := keyring.SigningKey()
+	require.NoError(t, err)
+	require.Equal(t, keyID+"\n", out)
+
+	info, err := os.Stat(keyFile)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	_, err = runCommand(t, mockdb.NewMockStore(ctrl), "token", "keygen", keyFile, "--type", "dsa")
+	require.EqualError(t, err, `unsupported key type "dsa"`)
+}
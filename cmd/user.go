@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/danielmoisa/neobank/audit"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/spf13/cobra"
+)
+
+type createUserOptions struct {
+	Username string `validate:"required,alphanum"`
+	Password string `validate:"required,min=6"`
+	FullName string `validate:"required"`
+	Email    string `validate:"required,email"`
+}
+
+func newUserCommand(app *app) *cobra.Command {
+	user := &cobra.Command{
+		Use:   "user",
+		Short: "Manage users",
+	}
+
+	var opts createUserOptions
+	create := &cobra.Command{
+		Use:   "create",
+		Short: "Create a user",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := app.requireActor(); err != nil {
+				return err
+			}
+			if err := validator.New().Struct(opts); err != nil {
+				return err
+			}
+
+			hash, err := utils.HashPassword(opts.Password)
+			if err != nil {
+				return err
+			}
+
+			created, err := app.store.CreateUser(cmd.Context(), db.CreateUserParams{
+				Username:       opts.Username,
+				HashedPassword: hash,
+				FullName:       opts.FullName,
+				Email:          opts.Email,
+			})
+			if err != nil {
+				return fmt.Errorf("cannot create user: %w", err)
+			}
+
+			app.audit(cmd.Context(), audit.Event{
+				Action:       audit.ActionUserCreate,
+				Outcome:      audit.OutcomeSuccess,
+				ResourceType: audit.ResourceUser,
+				ResourceID:   created.Username,
+			})
+
+			fmt.Fprintf(cmd.OutOrStdout(), "created user %s\n", created.Username)
+			return nil
+		},
+	}
+	create.Flags().StringVar(&opts.Username, "username", "", "username")
+	create.Flags().StringVar(&opts.Password, "password", "", "initial password")
+	create.Flags().StringVar(&opts.FullName, "full-name", "", "full name")
+	create.Flags().StringVar(&opts.Email, "email", "", "email address")
+
+	user.AddCommand(
+		create,
+		newSetUserDisabledCommand(app, true),
+		newSetUserDisabledCommand(app, false),
+		newSetUserRoleCommand(app),
+	)
+	return user
+}
+
+func newSetUserRoleCommand(app *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "role USERNAME ROLE",
+		Short: "Set the role of a user, customer, support or admin",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := app.requireActor(); err != nil {
+				return err
+			}
+
+			role := args[1]
+			if role != db.RoleCustomer && role != db.RoleSupport && role != db.RoleAdmin {
+				return fmt.Errorf("role must be %s, %s or %s", db.RoleCustomer, db.RoleSupport, db.RoleAdmin)
+			}
+
+			user, err := app.store.SetUserRole(cmd.Context(), db.SetUserRoleParams{
+				Username: args[0],
+				Role:     role,
+			})
+			if err != nil {
+				return notFound(err, "user %s", args[0])
+			}
+
+			app.audit(cmd.Context(), audit.Event{
+				Action:       audit.ActionUserRole,
+				Outcome:      audit.OutcomeSuccess,
+				ResourceType: audit.ResourceUser,
+				ResourceID:   user.Username,
+				Metadata:     map[string]interface{}{"role": user.Role},
+			})
+
+			fmt.Fprintf(cmd.OutOrStdout(), "user %s role: %s\n", user.Username, user.Role)
+			return nil
+		},
+	}
+}
+
+func newSetUserDisabledCommand(app *app, disabled bool) *cobra.Command {
+	use, short, action := "enable USERNAME", "Allow a disabled user to log in again", audit.ActionUserEnable
+	if disabled {
+		use, short, action = "disable USERNAME", "Stop a user from logging in", audit.ActionUserDisable
+	}
+
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := app.requireActor(); err != nil {
+				return err
+			}
+
+			user, err := app.store.SetUserDisabled(cmd.Context(), db.SetUserDisabledParams{
+				Username: args[0],
+				Disabled: disabled,
+			})
+			if err != nil {
+				return notFound(err, "user %s", args[0])
+			}
+
+			app.audit(cmd.Context(), audit.Event{
+				Action:       action,
+				Outcome:      audit.OutcomeSuccess,
+				ResourceType: audit.ResourceUser,
+				ResourceID:   user.Username,
+			})
+
+			fmt.Fprintf(cmd.OutOrStdout(), "user %s disabled: %t\n", user.Username, user.Disabled)
+			return nil
+		},
+	}
+}
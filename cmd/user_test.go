@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/danielmoisa/neobank/audit"
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUserCommand(t *testing.T) {
+	username := utils.RandomOwner()
+
+	testCases := []struct {
+		name       string
+		args       []string
+		buildStubs func(store *mockdb.MockStore)
+		checkRun   func(t *testing.T, out string, err error)
+	}{
+		{
+			name: "Create",
+			args: []string{"user", "create", "--username", username, "--password", "secret", "--full-name", "Jane Doe", "--email", utils.RandomEmail()},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUser(gomock.Any(), gomock.Cond(func(x any) bool {
+						arg, ok := x.(db.CreateUserParams)
+						return ok && arg.Username == username && utils.CheckPassword(arg.HashedPassword, "secret") == nil
+					})).
+					Times(1).
+					Return(db.User{Username: username}, nil)
+				expectAuditEvent(store, audit.ActionUserCreate)
+			},
+			checkRun: func(t *testing.T, out string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, out, "created user "+username)
+			},
+		},
+		{
+			name: "CreateInvalidEmail",
+			args: []string{"user", "create", "--username", username, "--password", "secret", "--full-name", "Jane Doe", "--email", "invalid"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkRun: func(t *testing.T, out string, err error) {
+				require.Error(t, err)
+			},
+		},
+		{
+			name: "Disable",
+			args: []string{"user", "disable", username},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SetUserDisabled(gomock.Any(), gomock.Eq(db.SetUserDisabledParams{Username: username, Disabled: true})).
+					Times(1).
+					Return(db.User{Username: username, Disabled: true}, nil)
+				expectAuditEvent(store, audit.ActionUserDisable)
+			},
+			checkRun: func(t *testing.T, out string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, out, "disabled: true")
+			},
+		},
+		{
+			name: "EnableNotFound",
+			args: []string{"user", "enable", username},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SetUserDisabled(gomock.Any(), gomock.Eq(db.SetUserDisabledParams{Username: username, Disabled: false})).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+			},
+			checkRun: func(t *testing.T, out string, err error) {
+				require.EqualError(t, err, "user "+username+" not found")
+			},
+		},
+		{
+			name: "Role",
+			args: []string{"user", "role", username, db.RoleAdmin},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SetUserRole(gomock.Any(), gomock.Eq(db.SetUserRoleParams{Username: username, Role: db.RoleAdmin})).
+					Times(1).
+					Return(db.User{Username: username, Role: db.RoleAdmin}, nil)
+				expectAuditEvent(store, audit.ActionUserRole)
+			},
+			checkRun: func(t *testing.T, out string, err error) {
+				require.NoError(t, err)
+				require.Contains(t, out, "role: admin")
+			},
+		},
+		{
+			name: "InvalidRole",
+			args: []string{"user", "role", username, "root"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SetUserRole(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkRun: func(t *testing.T, out string, err error) {
+				require.Error(t, err)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			out, err := runCommand(t, store, tc.args...)
+			tc.checkRun(t, out, err)
+		})
+	}
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "adjustments";
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "frozen";
+ALTER TABLE IF EXISTS "users" DROP COLUMN IF EXISTS "disabled";
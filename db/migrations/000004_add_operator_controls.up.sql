@@ -0,0 +1,18 @@
+ALTER TABLE "users" ADD COLUMN "disabled" boolean NOT NULL DEFAULT false;
+
+ALTER TABLE "accounts" ADD COLUMN "frozen" boolean NOT NULL DEFAULT false;
+
+-- manual balance adjustments made by operators; each one is journaled as an
+-- entry so the ledger still explains every balance
+CREATE TABLE "adjustments" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+  "entry_id" bigint NOT NULL REFERENCES "entries" ("id"),
+  "amount" bigint NOT NULL CHECK ("amount" <> 0),
+  "reason_code" varchar NOT NULL CHECK ("reason_code" IN ('correction', 'fee_refund', 'chargeback', 'goodwill', 'write_off')),
+  "note" varchar NOT NULL,
+  "created_by" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "adjustments" ("account_id");
@@ -0,0 +1,19 @@
+-- adjustments that were never booked have no place in the old table
+DELETE FROM "adjustments" WHERE "entry_id" IS NULL;
+
+ALTER TABLE IF EXISTS "adjustments"
+  DROP CONSTRAINT IF EXISTS "adjustments_four_eyes",
+  DROP COLUMN IF EXISTS "suspense_entry_id",
+  DROP COLUMN IF EXISTS "evidence",
+  DROP COLUMN IF EXISTS "status",
+  DROP COLUMN IF EXISTS "reviewed_by",
+  DROP COLUMN IF EXISTS "reviewed_at",
+  DROP COLUMN IF EXISTS "review_note",
+  DROP COLUMN IF EXISTS "expires_at",
+  ALTER COLUMN "entry_id" SET NOT NULL;
+
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" = '_system');
+DELETE FROM "accounts" WHERE "owner" = '_system';
+DELETE FROM "users" WHERE "username" = '_system';
+
+ALTER TABLE IF EXISTS "users" DROP COLUMN IF EXISTS "role";
@@ -0,0 +1,32 @@
+ALTER TABLE "users" ADD COLUMN "role" varchar NOT NULL DEFAULT 'customer' CHECK ("role" IN ('customer', 'admin'));
+
+-- the system user owns the suspense accounts; it is disabled and its empty
+-- password hash matches no password, so nobody can log in as it
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email", "disabled")
+VALUES ('_system', '', 'Neobank', 'system@neobank.invalid', true);
+
+-- approved adjustments are booked against the suspense account of their
+-- currency, so an adjustment moves money instead of creating it
+INSERT INTO "accounts" ("owner", "balance", "currency")
+VALUES ('_system', 0, 'USD'), ('_system', 0, 'EUR'), ('_system', 0, 'CAD');
+
+-- adjustments are now proposed by one admin and approved or rejected by
+-- another; the entries are only booked on approval. Adjustments made before
+-- this migration were booked directly and count as approved.
+ALTER TABLE "adjustments"
+  ALTER COLUMN "entry_id" DROP NOT NULL,
+  ADD COLUMN "suspense_entry_id" bigint REFERENCES "entries" ("id"),
+  ADD COLUMN "evidence" varchar NOT NULL DEFAULT '',
+  ADD COLUMN "status" varchar NOT NULL DEFAULT 'approved' CHECK ("status" IN ('pending', 'approved', 'rejected', 'expired')),
+  ADD COLUMN "reviewed_by" varchar,
+  ADD COLUMN "reviewed_at" timestamptz,
+  ADD COLUMN "review_note" varchar NOT NULL DEFAULT '',
+  ADD COLUMN "expires_at" timestamptz NOT NULL DEFAULT (now()),
+  ADD CONSTRAINT "adjustments_four_eyes" CHECK ("reviewed_by" <> "created_by");
+
+ALTER TABLE "adjustments"
+  ALTER COLUMN "evidence" DROP DEFAULT,
+  ALTER COLUMN "status" SET DEFAULT 'pending',
+  ALTER COLUMN "expires_at" DROP DEFAULT;
+
+CREATE INDEX ON "adjustments" ("status", "expires_at");
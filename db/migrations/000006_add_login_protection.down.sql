@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "rate_limit_buckets";
+DROP TABLE IF EXISTS "notifications";
+
+ALTER TABLE IF EXISTS "users"
+  DROP COLUMN IF EXISTS "failed_login_attempts",
+  DROP COLUMN IF EXISTS "lockouts",
+  DROP COLUMN IF EXISTS "locked_until";
@@ -0,0 +1,26 @@
+-- failed logins since the last successful one; reaching the limit locks the
+-- user until locked_until, for longer after every lockout
+ALTER TABLE "users"
+  ADD COLUMN "failed_login_attempts" integer NOT NULL DEFAULT 0,
+  ADD COLUMN "lockouts" integer NOT NULL DEFAULT 0,
+  ADD COLUMN "locked_until" timestamptz NOT NULL DEFAULT ('0001-01-01 00:00:00Z');
+
+-- messages for a user about their own account, e.g. that it was locked
+CREATE TABLE "notifications" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL REFERENCES "users" ("username"),
+  "type" varchar NOT NULL,
+  "payload" jsonb NOT NULL DEFAULT '{}',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "notifications" ("username", "id");
+
+-- token buckets of the rate limiter shared by all replicas; losing them in a
+-- crash only resets the limits, so the table is not WAL-logged
+CREATE UNLOGGED TABLE "rate_limit_buckets" (
+  "key" varchar PRIMARY KEY,
+  "tokens" double precision NOT NULL,
+  "allowed" boolean NOT NULL,
+  "updated_at" timestamptz NOT NULL
+);
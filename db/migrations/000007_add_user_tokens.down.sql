@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "user_tokens";
+
+ALTER TABLE IF EXISTS "users" DROP COLUMN IF EXISTS "email_verified_at";
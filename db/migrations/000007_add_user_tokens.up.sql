@@ -0,0 +1,19 @@
+-- zero until the user proved they own users.email; changing the email
+-- resets it
+ALTER TABLE "users" ADD COLUMN "email_verified_at" timestamptz NOT NULL DEFAULT ('0001-01-01 00:00:00Z');
+
+-- single-use tokens sent by email. Only the SHA-256 of the token is stored,
+-- so a leaked table can't be used to verify or reset anything.
+CREATE TABLE "user_tokens" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL REFERENCES "users" ("username"),
+  "purpose" varchar NOT NULL CHECK ("purpose" IN ('email_verification', 'password_reset')),
+  "token_hash" varchar NOT NULL UNIQUE,
+  -- the address the token was sent to
+  "email" varchar NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "user_tokens" ("username", "purpose");
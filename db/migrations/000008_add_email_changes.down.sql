@@ -0,0 +1,7 @@
+DELETE FROM "user_tokens" WHERE "purpose" = 'email_change';
+
+ALTER TABLE IF EXISTS "user_tokens" DROP CONSTRAINT IF EXISTS "user_tokens_purpose_check";
+ALTER TABLE IF EXISTS "user_tokens" ADD CONSTRAINT "user_tokens_purpose_check"
+  CHECK ("purpose" IN ('email_verification', 'password_reset'));
+
+DROP TABLE IF EXISTS "email_changes";
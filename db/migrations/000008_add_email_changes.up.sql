@@ -0,0 +1,19 @@
+-- a new email only replaces users.email once the link sent to the current
+-- address and the one sent to the new address were both opened
+CREATE TABLE "email_changes" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL REFERENCES "users" ("username"),
+  "old_email" varchar NOT NULL,
+  "new_email" varchar NOT NULL,
+  "old_confirmed_at" timestamptz,
+  "new_confirmed_at" timestamptz,
+  "completed_at" timestamptz,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "email_changes" ("username");
+
+ALTER TABLE "user_tokens" DROP CONSTRAINT "user_tokens_purpose_check";
+ALTER TABLE "user_tokens" ADD CONSTRAINT "user_tokens_purpose_check"
+  CHECK ("purpose" IN ('email_verification', 'password_reset', 'email_change'));
@@ -0,0 +1,20 @@
+-- keys for server-to-server integrations. Like user_tokens only the SHA-256
+-- of a key is stored; prefix is the public part of the key, shown in
+-- listings so owners can tell their keys apart.
+CREATE TABLE "api_keys" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL REFERENCES "users" ("username"),
+  "name" varchar NOT NULL,
+  "prefix" varchar NOT NULL UNIQUE,
+  "key_hash" varchar NOT NULL UNIQUE,
+  "scopes" varchar[] NOT NULL,
+  -- CIDRs the key may be used from; empty allows any address
+  "allowed_ips" varchar[] NOT NULL DEFAULT '{}',
+  -- NULL keys don't expire
+  "expires_at" timestamptz,
+  "last_used_at" timestamptz,
+  "revoked_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "api_keys" ("username");
@@ -0,0 +1,53 @@
+-- third-party apps that may ask users for access, registered by admins
+CREATE TABLE "oauth_clients" (
+  "id" varchar PRIMARY KEY,
+  "name" varchar NOT NULL,
+  -- NULL for public clients such as mobile apps, which can't keep a secret
+  -- and rely on PKCE alone
+  "secret_hash" varchar,
+  "redirect_uris" varchar[] NOT NULL,
+  -- the most a user can grant the client
+  "scopes" varchar[] NOT NULL,
+  "created_by" varchar NOT NULL REFERENCES "users" ("username"),
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+-- what a user allowed a client to do, until it expires or is revoked. The
+-- codes and refresh tokens of a client only work while their consent does.
+CREATE TABLE "oauth_consents" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL REFERENCES "users" ("username"),
+  "client_id" varchar NOT NULL REFERENCES "oauth_clients" ("id"),
+  "scopes" varchar[] NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "revoked_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "oauth_consents" ("username", "client_id");
+
+-- like user_tokens, codes and refresh tokens are only stored as SHA-256
+CREATE TABLE "oauth_authorization_codes" (
+  "id" bigserial PRIMARY KEY,
+  "code_hash" varchar NOT NULL UNIQUE,
+  "consent_id" bigint NOT NULL REFERENCES "oauth_consents" ("id"),
+  "redirect_uri" varchar NOT NULL,
+  -- base64url SHA-256 of the PKCE code verifier (method S256)
+  "code_challenge" varchar NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+-- refresh tokens rotate: each one is used once and replaced by a new one
+CREATE TABLE "oauth_refresh_tokens" (
+  "id" bigserial PRIMARY KEY,
+  "token_hash" varchar NOT NULL UNIQUE,
+  "consent_id" bigint NOT NULL REFERENCES "oauth_consents" ("id"),
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "oauth_authorization_codes" ("consent_id");
+CREATE INDEX ON "oauth_refresh_tokens" ("consent_id");
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS "payments_from_account_id_created_at_idx";
+
+DROP TABLE IF EXISTS "kyc_documents";
+DROP TABLE IF EXISTS "kyc_profiles";
+
+UPDATE "users" SET "role" = 'customer' WHERE "role" = 'support';
+ALTER TABLE IF EXISTS "users" DROP CONSTRAINT IF EXISTS "users_role_check";
+ALTER TABLE IF EXISTS "users" ADD CONSTRAINT "users_role_check"
+  CHECK ("role" IN ('customer', 'admin'));
+
+ALTER TABLE IF EXISTS "users" DROP COLUMN IF EXISTS "kyc_status";
@@ -0,0 +1,43 @@
+-- users start unverified and go through review before the limits of their
+-- tier are lifted: unverified -> pending -> verified or rejected, and back to
+-- pending when a rejected user submits again
+ALTER TABLE "users" ADD COLUMN "kyc_status" varchar NOT NULL DEFAULT 'unverified'
+  CHECK ("kyc_status" IN ('unverified', 'pending', 'verified', 'rejected'));
+
+-- support staff review KYC submissions
+ALTER TABLE "users" DROP CONSTRAINT "users_role_check";
+ALTER TABLE "users" ADD CONSTRAINT "users_role_check"
+  CHECK ("role" IN ('customer', 'support', 'admin'));
+
+CREATE TABLE "kyc_profiles" (
+  "username" varchar PRIMARY KEY REFERENCES "users" ("username"),
+  "legal_name" varchar NOT NULL,
+  "date_of_birth" date NOT NULL,
+  "nationality" varchar(2) NOT NULL,
+  "address_line" varchar NOT NULL,
+  "city" varchar NOT NULL,
+  "postal_code" varchar NOT NULL,
+  "country" varchar(2) NOT NULL,
+  "submitted_at" timestamptz,
+  "reviewed_by" varchar REFERENCES "users" ("username"),
+  "reviewed_at" timestamptz,
+  "rejection_reason" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+-- the files themselves are kept in blob storage under storage_key
+CREATE TABLE "kyc_documents" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL REFERENCES "users" ("username"),
+  "kind" varchar NOT NULL CHECK ("kind" IN ('passport', 'national_id', 'driving_licence', 'proof_of_address')),
+  "storage_key" varchar UNIQUE NOT NULL,
+  "content_type" varchar NOT NULL,
+  "size" bigint NOT NULL,
+  "sha256" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "kyc_documents" ("username");
+
+CREATE INDEX ON "payments" ("from_account_id", "created_at");
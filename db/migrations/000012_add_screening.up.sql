@@ -0,0 +1,22 @@
+-- names that matched an entry of a sanctions list, kept for analysts to
+-- clear as false positives or confirm. A user is screened against an entry
+-- once: the hit is only opened again when the screened name changes
+CREATE TABLE "screening_hits" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL REFERENCES "users" ("username"),
+  "source" varchar NOT NULL CHECK ("source" IN ('onboarding', 'payment')),
+  "screened_name" varchar NOT NULL,
+  "list" varchar NOT NULL,
+  "entry_id" varchar NOT NULL,
+  "entry_name" varchar NOT NULL,
+  "score" double precision NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'open' CHECK ("status" IN ('open', 'cleared', 'confirmed')),
+  "reviewed_by" varchar REFERENCES "users" ("username"),
+  "reviewed_at" timestamptz,
+  "review_note" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  UNIQUE ("username", "list", "entry_id")
+);
+
+CREATE INDEX ON "screening_hits" ("status", "created_at");
@@ -0,0 +1,23 @@
+-- the outcome of the fraud rules for every payment, with the rules that
+-- matched and the values they saw, so the rules can be tuned. Blocked
+-- payments wait here for an analyst to approve or reject them
+CREATE TABLE "fraud_decisions" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL REFERENCES "users" ("username"),
+  "from_account_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+  "to_account_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+  "amount" bigint NOT NULL,
+  "score" integer NOT NULL,
+  "outcome" varchar NOT NULL CHECK ("outcome" IN ('allow', 'challenge', 'block')),
+  "status" varchar NOT NULL CHECK ("status" IN ('passed', 'challenged', 'pending', 'approved', 'rejected')),
+  "trace" jsonb NOT NULL DEFAULT '[]',
+  "features" jsonb NOT NULL DEFAULT '{}',
+  "payment_id" bigint REFERENCES "payments" ("id"),
+  "reviewed_by" varchar REFERENCES "users" ("username"),
+  "reviewed_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "fraud_decisions" ("status", "created_at");
+
+CREATE INDEX ON "fraud_decisions" ("username", "created_at");
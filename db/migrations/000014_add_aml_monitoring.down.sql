@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "aml_alert_comments";
+DROP TABLE IF EXISTS "aml_alerts";
+DROP TABLE IF EXISTS "aml_job_runs";
@@ -0,0 +1,49 @@
+-- every run of a transaction monitoring scenario, with the activity it
+-- looked at and why it failed if it did
+CREATE TABLE "aml_job_runs" (
+  "id" bigserial PRIMARY KEY,
+  "scenario" varchar NOT NULL,
+  "window_start" timestamptz NOT NULL,
+  "window_end" timestamptz NOT NULL,
+  "alerts" integer NOT NULL DEFAULT 0,
+  "error" varchar NOT NULL DEFAULT '',
+  "started_at" timestamptz NOT NULL DEFAULT (now()),
+  "finished_at" timestamptz
+);
+
+CREATE INDEX ON "aml_job_runs" ("started_at");
+
+-- suspicious activity found by the scenarios, with the payments and entries
+-- that make it up. An account has at most one open alert per scenario,
+-- which later runs update; activity already in an alert isn't raised again
+CREATE TABLE "aml_alerts" (
+  "id" bigserial PRIMARY KEY,
+  "scenario" varchar NOT NULL,
+  "username" varchar NOT NULL REFERENCES "users" ("username"),
+  "account_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+  "summary" varchar NOT NULL,
+  "evidence" jsonb NOT NULL DEFAULT '{}',
+  "status" varchar NOT NULL DEFAULT 'open' CHECK ("status" IN ('open', 'closed', 'escalated')),
+  "assigned_to" varchar REFERENCES "users" ("username"),
+  "resolution" varchar NOT NULL DEFAULT '',
+  "resolved_by" varchar REFERENCES "users" ("username"),
+  "resolved_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "aml_alerts" ("scenario", "account_id") WHERE "status" = 'open';
+
+CREATE INDEX ON "aml_alerts" ("account_id", "scenario");
+
+CREATE INDEX ON "aml_alerts" ("status", "created_at");
+
+CREATE TABLE "aml_alert_comments" (
+  "id" bigserial PRIMARY KEY,
+  "alert_id" bigint NOT NULL REFERENCES "aml_alerts" ("id"),
+  "author" varchar NOT NULL REFERENCES "users" ("username"),
+  "body" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "aml_alert_comments" ("alert_id", "created_at");
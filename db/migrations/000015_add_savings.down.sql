@@ -0,0 +1,25 @@
+-- savings accounts hold customer money and share an owner and currency with
+-- their checking account, so they can neither be dropped with the ledger
+-- left balanced nor folded into the single account per currency the old
+-- index allows; they have to be removed before migrating down
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM "accounts" WHERE "type" = 'savings') THEN
+    RAISE EXCEPTION 'savings accounts exist: remove them before migrating down';
+  END IF;
+END;
+$$;
+
+DROP TABLE IF EXISTS "interest_accruals";
+DROP TABLE IF EXISTS "interest_payouts";
+DROP TABLE IF EXISTS "savings_accounts";
+
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "type" = 'interest_expense');
+DELETE FROM "accounts" WHERE "type" = 'interest_expense';
+
+DROP INDEX IF EXISTS "accounts_owner_currency_type_idx";
+CREATE UNIQUE INDEX ON "accounts" ("owner", "currency");
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "type";
+
+DROP TABLE IF EXISTS "savings_products";
@@ -0,0 +1,69 @@
+-- what a savings account earns: an annual rate in basis points, how days
+-- are counted, whether accrued interest earns interest before it is paid
+-- out, and how often it is paid out
+CREATE TABLE "savings_products" (
+  "id" bigserial PRIMARY KEY,
+  "name" varchar UNIQUE NOT NULL,
+  "currency" varchar NOT NULL,
+  "rate_bps" integer NOT NULL CHECK ("rate_bps" >= 0),
+  "day_count" varchar NOT NULL CHECK ("day_count" IN ('act/365', '30/360')),
+  "compounding" varchar NOT NULL CHECK ("compounding" IN ('none', 'daily')),
+  "payout_frequency" varchar NOT NULL CHECK ("payout_frequency" IN ('monthly', 'quarterly', 'annually')),
+  "created_by" varchar NOT NULL REFERENCES "users" ("username"),
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+-- the system accounts are typed too, so the suspense account of a currency
+-- is told apart from the account interest is paid from
+ALTER TABLE "accounts" ADD COLUMN "type" varchar NOT NULL DEFAULT 'checking'
+  CHECK ("type" IN ('checking', 'savings', 'suspense', 'interest_expense'));
+
+UPDATE "accounts" SET "type" = 'suspense' WHERE "owner" = '_system';
+
+DROP INDEX IF EXISTS "accounts_owner_currency_idx";
+CREATE UNIQUE INDEX ON "accounts" ("owner", "currency", "type");
+
+INSERT INTO "accounts" ("owner", "balance", "currency", "type")
+VALUES ('_system', 0, 'USD', 'interest_expense'), ('_system', 0, 'EUR', 'interest_expense'), ('_system', 0, 'CAD', 'interest_expense');
+
+CREATE TABLE "savings_accounts" (
+  "account_id" bigint PRIMARY KEY REFERENCES "accounts" ("id"),
+  "product_id" bigint NOT NULL REFERENCES "savings_products" ("id"),
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+-- interest paid to a savings account, journaled as an entry on the account
+-- and the opposite one on the interest expense account of the currency.
+-- Only whole minor units are paid; the rest is carried to the next payout
+CREATE TABLE "interest_payouts" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+  "period_start" date NOT NULL,
+  "period_end" date NOT NULL,
+  "accrued_micros" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "carry_micros" bigint NOT NULL,
+  "entry_id" bigint REFERENCES "entries" ("id"),
+  "expense_entry_id" bigint REFERENCES "entries" ("id"),
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  UNIQUE ("account_id", "period_end")
+);
+
+-- the interest of one day on the end-of-day balance, in millionths of a
+-- minor unit
+CREATE TABLE "interest_accruals" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+  "accrual_date" date NOT NULL,
+  "balance" bigint NOT NULL,
+  "base" bigint NOT NULL,
+  "rate_bps" integer NOT NULL,
+  "day_count" varchar NOT NULL,
+  "days" integer NOT NULL,
+  "amount_micros" bigint NOT NULL,
+  "payout_id" bigint REFERENCES "interest_payouts" ("id"),
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  UNIQUE ("account_id", "accrual_date")
+);
+
+CREATE INDEX ON "interest_accruals" ("account_id", "accrual_date") WHERE "payout_id" IS NULL;
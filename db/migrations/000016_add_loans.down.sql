@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS "loan_repayments";
+DROP TABLE IF EXISTS "loan_installments";
+DROP TABLE IF EXISTS "loans";
+DROP TABLE IF EXISTS "loan_products";
+
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "type" = 'loans');
+DELETE FROM "accounts" WHERE "type" = 'loans';
+
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "accounts_type_check";
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_type_check"
+  CHECK ("type" IN ('checking', 'savings', 'suspense', 'interest_expense'));
@@ -0,0 +1,90 @@
+-- the loan book of a currency: disbursements are paid from it and
+-- repayments, interest and late fees paid into it
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "accounts_type_check";
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_type_check"
+  CHECK ("type" IN ('checking', 'savings', 'suspense', 'interest_expense', 'loans'));
+
+INSERT INTO "accounts" ("owner", "balance", "currency", "type")
+VALUES ('_system', 0, 'USD', 'loans'), ('_system', 0, 'EUR', 'loans'), ('_system', 0, 'CAD', 'loans');
+
+-- what a loan can be taken out for: an annual rate in basis points, how it
+-- is amortized, the bounds of the principal and of the term in months, and
+-- the fee charged once for every installment still unpaid after the grace
+-- days
+CREATE TABLE "loan_products" (
+  "id" bigserial PRIMARY KEY,
+  "name" varchar UNIQUE NOT NULL,
+  "currency" varchar NOT NULL,
+  "rate_bps" integer NOT NULL CHECK ("rate_bps" >= 0),
+  "method" varchar NOT NULL CHECK ("method" IN ('annuity', 'linear')),
+  "min_principal" bigint NOT NULL CHECK ("min_principal" > 0),
+  "max_principal" bigint NOT NULL CHECK ("max_principal" >= "min_principal"),
+  "min_term" integer NOT NULL CHECK ("min_term" > 0),
+  "max_term" integer NOT NULL CHECK ("max_term" >= "min_term"),
+  "late_fee" bigint NOT NULL CHECK ("late_fee" >= 0),
+  "grace_days" integer NOT NULL CHECK ("grace_days" >= 0),
+  "created_by" varchar NOT NULL REFERENCES "users" ("username"),
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+-- the terms are copied from the product, which may change later
+CREATE TABLE "loans" (
+  "id" bigserial PRIMARY KEY,
+  "product_id" bigint NOT NULL REFERENCES "loan_products" ("id"),
+  "account_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+  "borrower" varchar NOT NULL REFERENCES "users" ("username"),
+  "principal" bigint NOT NULL CHECK ("principal" > 0),
+  "rate_bps" integer NOT NULL,
+  "term_months" integer NOT NULL,
+  "method" varchar NOT NULL,
+  "late_fee" bigint NOT NULL,
+  "grace_days" integer NOT NULL,
+  "outstanding_principal" bigint NOT NULL CHECK ("outstanding_principal" >= 0),
+  "status" varchar NOT NULL DEFAULT 'active' CHECK ("status" IN ('active', 'paid_off')),
+  "disbursed_on" date NOT NULL,
+  "disbursement_entry_id" bigint REFERENCES "entries" ("id"),
+  "funding_entry_id" bigint REFERENCES "entries" ("id"),
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "closed_at" timestamptz
+);
+
+CREATE INDEX ON "loans" ("borrower");
+
+-- an installment is due in a month of the term (its period); those an early
+-- repayment replaces are cancelled
+CREATE TABLE "loan_installments" (
+  "id" bigserial PRIMARY KEY,
+  "loan_id" bigint NOT NULL REFERENCES "loans" ("id"),
+  "period" integer NOT NULL,
+  "due_date" date NOT NULL,
+  "principal" bigint NOT NULL,
+  "interest" bigint NOT NULL,
+  "fee" bigint NOT NULL DEFAULT 0,
+  "paid_principal" bigint NOT NULL DEFAULT 0,
+  "paid_interest" bigint NOT NULL DEFAULT 0,
+  "paid_fee" bigint NOT NULL DEFAULT 0,
+  "status" varchar NOT NULL DEFAULT 'scheduled' CHECK ("status" IN ('scheduled', 'overdue', 'paid', 'cancelled')),
+  "paid_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "loan_installments" ("loan_id", "period") WHERE "status" <> 'cancelled';
+CREATE INDEX ON "loan_installments" ("due_date") WHERE "status" IN ('scheduled', 'overdue');
+
+-- money collected for a loan, journaled as an entry on the borrower's
+-- account and the opposite one on the loan book
+CREATE TABLE "loan_repayments" (
+  "id" bigserial PRIMARY KEY,
+  "loan_id" bigint NOT NULL REFERENCES "loans" ("id"),
+  "installment_id" bigint REFERENCES "loan_installments" ("id"),
+  "kind" varchar NOT NULL CHECK ("kind" IN ('scheduled', 'early')),
+  "amount" bigint NOT NULL CHECK ("amount" > 0),
+  "principal" bigint NOT NULL,
+  "interest" bigint NOT NULL,
+  "fee" bigint NOT NULL,
+  "entry_id" bigint NOT NULL REFERENCES "entries" ("id"),
+  "loan_entry_id" bigint NOT NULL REFERENCES "entries" ("id"),
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "loan_repayments" ("loan_id");
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS "card_authorizations";
+DROP TABLE IF EXISTS "cards";
+
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "type" = 'card_settlement');
+DELETE FROM "accounts" WHERE "type" = 'card_settlement';
+
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "accounts_type_check";
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_type_check"
+  CHECK ("type" IN ('checking', 'savings', 'suspense', 'interest_expense', 'loans'));
@@ -0,0 +1,56 @@
+-- card payments captured are paid into the settlement account of their
+-- currency, which the card network settles with the bank
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "accounts_type_check";
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_type_check"
+  CHECK ("type" IN ('checking', 'savings', 'suspense', 'interest_expense', 'loans', 'card_settlement'));
+
+INSERT INTO "accounts" ("owner", "balance", "currency", "type")
+VALUES ('_system', 0, 'USD', 'card_settlement'), ('_system', 0, 'EUR', 'card_settlement'), ('_system', 0, 'CAD', 'card_settlement');
+
+-- virtual debit cards spending from an account; the CVV is only kept hashed
+CREATE TABLE "cards" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+  "owner" varchar NOT NULL REFERENCES "users" ("username"),
+  "pan" varchar UNIQUE NOT NULL,
+  "last4" varchar NOT NULL,
+  "expiry_month" integer NOT NULL CHECK ("expiry_month" BETWEEN 1 AND 12),
+  "expiry_year" integer NOT NULL,
+  "hashed_cvv" varchar NOT NULL,
+  "frozen" boolean NOT NULL DEFAULT false,
+  "transaction_limit" bigint NOT NULL CHECK ("transaction_limit" > 0),
+  "daily_limit" bigint NOT NULL CHECK ("daily_limit" > 0),
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "cards" ("owner");
+
+-- every authorization request the switch received for a card. An approved
+-- one holds its amount on the account until it is captured into entries,
+-- reversed or expires; declined ones are kept with their response code
+CREATE TABLE "card_authorizations" (
+  "id" bigserial PRIMARY KEY,
+  "card_id" bigint NOT NULL REFERENCES "cards" ("id"),
+  "account_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+  "stan" varchar NOT NULL,
+  "rrn" varchar NOT NULL,
+  "amount" bigint NOT NULL CHECK ("amount" > 0),
+  "currency" varchar NOT NULL,
+  "merchant" varchar NOT NULL,
+  "mcc" varchar NOT NULL,
+  "status" varchar NOT NULL CHECK ("status" IN ('approved', 'declined', 'captured', 'reversed')),
+  "response_code" varchar NOT NULL,
+  "auth_code" varchar NOT NULL DEFAULT '',
+  "captured_amount" bigint NOT NULL DEFAULT 0,
+  "entry_id" bigint REFERENCES "entries" ("id"),
+  "settlement_entry_id" bigint REFERENCES "entries" ("id"),
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "captured_at" timestamptz,
+  "reversed_at" timestamptz
+);
+
+-- the retrieval reference number identifies an authorization of a card in
+-- captures and reversals
+CREATE UNIQUE INDEX ON "card_authorizations" ("card_id", "rrn");
+CREATE INDEX ON "card_authorizations" ("account_id") WHERE "status" = 'approved';
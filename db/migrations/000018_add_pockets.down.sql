@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS "pocket_moves";
+DROP TABLE IF EXISTS "pockets";
+
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "type" = 'pocket');
+DELETE FROM "accounts" WHERE "type" = 'pocket';
+
+DROP INDEX IF EXISTS "accounts_owner_currency_type_idx";
+CREATE UNIQUE INDEX ON "accounts" ("owner", "currency", "type");
+
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "accounts_type_check";
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_type_check"
+  CHECK ("type" IN ('checking', 'savings', 'suspense', 'interest_expense', 'loans', 'card_settlement'));
@@ -0,0 +1,44 @@
+-- pockets are accounts of their own, set aside inside a main account: they
+-- share its owner and currency, so more than one per currency is allowed
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "accounts_type_check";
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_type_check"
+  CHECK ("type" IN ('checking', 'savings', 'suspense', 'interest_expense', 'loans', 'card_settlement', 'pocket'));
+
+DROP INDEX IF EXISTS "accounts_owner_currency_type_idx";
+CREATE UNIQUE INDEX "accounts_owner_currency_type_idx" ON "accounts" ("owner", "currency", "type")
+  WHERE "type" <> 'pocket';
+
+-- links a pocket account to its parent. A goal amount of 0 means no goal.
+-- With round_up_to set, every payment out of the parent sweeps the spare
+-- change up to the next multiple of it into the pocket; only one pocket of a
+-- parent rounds up
+CREATE TABLE "pockets" (
+  "account_id" bigint PRIMARY KEY REFERENCES "accounts" ("id"),
+  "parent_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+  "name" varchar NOT NULL,
+  "goal_amount" bigint NOT NULL DEFAULT 0 CHECK ("goal_amount" >= 0),
+  "target_date" date,
+  "round_up_to" bigint NOT NULL DEFAULT 0 CHECK ("round_up_to" >= 0),
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  UNIQUE ("parent_id", "name")
+);
+
+CREATE UNIQUE INDEX ON "pockets" ("parent_id") WHERE "round_up_to" > 0;
+
+-- money moved between a parent account and its pockets, journaled as an
+-- entry on each side. Round-ups point to the payment they were swept from
+CREATE TABLE "pocket_moves" (
+  "id" bigserial PRIMARY KEY,
+  "parent_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+  "from_account_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+  "to_account_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+  "amount" bigint NOT NULL CHECK ("amount" > 0),
+  "kind" varchar NOT NULL CHECK ("kind" IN ('move', 'round_up')),
+  "payment_id" bigint REFERENCES "payments" ("id"),
+  "from_entry_id" bigint NOT NULL REFERENCES "entries" ("id"),
+  "to_entry_id" bigint NOT NULL REFERENCES "entries" ("id"),
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "pocket_moves" ("from_account_id");
+CREATE INDEX ON "pocket_moves" ("to_account_id");
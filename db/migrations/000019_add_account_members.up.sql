@@ -0,0 +1,31 @@
+-- members share an account with its owner, the user in accounts.owner.
+-- Co-owners may do anything the owner does but share the account, viewers
+-- only see it and payers may also send payments of up to their payment limit
+CREATE TABLE "account_members" (
+  "account_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+  "username" varchar NOT NULL REFERENCES "users" ("username"),
+  "role" varchar NOT NULL CHECK ("role" IN ('co_owner', 'viewer', 'payer')),
+  "payment_limit" bigint NOT NULL DEFAULT 0 CHECK ("payment_limit" >= 0),
+  "invited_by" varchar NOT NULL REFERENCES "users" ("username"),
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "username")
+);
+
+CREATE INDEX ON "account_members" ("username");
+
+-- users become members by accepting an invitation before it expires
+CREATE TABLE "account_invitations" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+  "invitee" varchar NOT NULL REFERENCES "users" ("username"),
+  "role" varchar NOT NULL CHECK ("role" IN ('co_owner', 'viewer', 'payer')),
+  "payment_limit" bigint NOT NULL DEFAULT 0 CHECK ("payment_limit" >= 0),
+  "invited_by" varchar NOT NULL REFERENCES "users" ("username"),
+  "status" varchar NOT NULL DEFAULT 'pending' CHECK ("status" IN ('pending', 'accepted', 'declined', 'revoked')),
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "responded_at" timestamptz
+);
+
+CREATE UNIQUE INDEX ON "account_invitations" ("account_id", "invitee") WHERE "status" = 'pending';
+CREATE INDEX ON "account_invitations" ("invitee");
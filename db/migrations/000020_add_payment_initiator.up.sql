@@ -0,0 +1,13 @@
+-- the user who sent a payment, who is not always the owner of the account it
+-- was sent from since accounts are shared. Limits and fraud rules count the
+-- payments of that user. Payments made before accounts were shared were all
+-- sent by the owner
+ALTER TABLE "payments" ADD COLUMN "initiated_by" varchar REFERENCES "users" ("username");
+
+UPDATE "payments" SET "initiated_by" = "accounts"."owner"
+FROM "accounts"
+WHERE "accounts"."id" = "payments"."from_account_id";
+
+ALTER TABLE "payments" ALTER COLUMN "initiated_by" SET NOT NULL;
+
+CREATE INDEX ON "payments" ("initiated_by", "created_at");
@@ -0,0 +1,135 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// Migrator applies the embedded migrations to a database.
+type Migrator struct {
+	m *migrate.Migrate
+}
+
+// Status describes the schema version of a database.
+type Status struct {
+	Version uint
+	Dirty   bool
+	Latest  uint
+}
+
+// NewMigrator reads the migrations from FS and applies them through a
+// connection of its own taken from db. Close releases that connection but
+// leaves db open: the driver is not given db itself, since closing the driver
+// would close db too.
+func NewMigrator(ctx context.Context, db *sql.DB) (*Migrator, error) {
+	source, err := iofs.New(FS, ".")
+	if err != nil {
+		return nil, fmt.Errorf("cannot read migrations: %w", err)
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get a connection to migrate: %w", err)
+	}
+
+	driver, err := postgres.WithConnection(ctx, conn, &postgres.Config{})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("cannot create migration driver: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", source, "postgres", driver)
+	if err != nil {
+		driver.Close()
+		return nil, fmt.Errorf("cannot create migrator: %w", err)
+	}
+	m.Log = migrateLogger{}
+
+	return &Migrator{m: m}, nil
+}
+
+// Up applies every pending migration.
+func (migrator *Migrator) Up() error {
+	return ignoreNoChange(migrator.m.Up())
+}
+
+// Down rolls back the given number of migrations.
+func (migrator *Migrator) Down(steps int) error {
+	if steps < 1 {
+		return fmt.Errorf("steps must be positive, got %d", steps)
+	}
+	return ignoreNoChange(migrator.m.Steps(-steps))
+}
+
+// Force sets the version without running any migration. It is how a dirty
+// database is recovered after the failed migration was fixed by hand.
+func (migrator *Migrator) Force(version int) error {
+	return migrator.m.Force(version)
+}
+
+func (migrator *Migrator) Status() (Status, error) {
+	latest, err := LatestVersion()
+	if err != nil {
+		return Status{}, err
+	}
+
+	version, dirty, err := migrator.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return Status{Latest: latest}, nil
+	}
+	if err != nil {
+		return Status{}, err
+	}
+
+	return Status{Version: version, Dirty: dirty, Latest: latest}, nil
+}
+
+func (migrator *Migrator) Close() error {
+	sourceErr, dbErr := migrator.m.Close()
+	if sourceErr != nil {
+		return sourceErr
+	}
+	return dbErr
+}
+
+// UpWithLock applies pending migrations while holding a Postgres advisory
+// lock, so replicas starting at the same time migrate one after another
+// instead of racing. Replicas that get the lock later find nothing to do.
+// The lock is the one migrate takes around Up itself, keyed on the database
+// and the migrations table, so no lock of our own is needed. db stays open
+// for the caller.
+func UpWithLock(ctx context.Context, db *sql.DB) error {
+	migrator, err := NewMigrator(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	return migrator.Up()
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
+
+// migrateLogger forwards the progress messages of migrate to slog.
+type migrateLogger struct{}
+
+func (migrateLogger) Printf(format string, v ...interface{}) {
+	slog.Info(strings.TrimSpace(fmt.Sprintf(format, v...)), slog.String("component", "migrate"))
+}
+
+func (migrateLogger) Verbose() bool {
+	return false
+}
@@ -0,0 +1,219 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/utils"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+// newScratchDB creates an empty database for the test and drops it afterwards,
+// so migrating down never touches the development schema.
+func newScratchDB(t *testing.T) *sql.DB {
+	config, err := utils.LoadConfig("../..")
+	if err != nil {
+		t.Skip("no config to reach postgres:", err)
+	}
+
+	admin, err := sql.Open(config.DBDriver, config.DBSource())
+	require.NoError(t, err)
+	t.Cleanup(func() { admin.Close() })
+
+	if err := admin.Ping(); err != nil {
+		t.Skip("postgres is not reachable:", err)
+	}
+
+	name := "neobank_migrations_" + strings.ToLower(utils.RandomString(8))
+	_, err = admin.Exec(fmt.Sprintf("CREATE DATABASE %s", name))
+	require.NoError(t, err)
+
+	config.DBName = name
+	conn, err := sql.Open(config.DBDriver, config.DBSource())
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		conn.Close()
+		_, err := admin.Exec(fmt.Sprintf("DROP DATABASE %s", name))
+		require.NoError(t, err)
+	})
+
+	return conn
+}
+
+func requireTables(t *testing.T, conn *sql.DB, exist bool, tables ...string) {
+	for _, table := range tables {
+		var name sql.NullString
+		err := conn.QueryRow("SELECT to_regclass($1)::text", table).Scan(&name)
+		require.NoError(t, err)
+		require.Equal(t, exist, name.Valid, table)
+	}
+}
+
+func TestMigrateUpDown(t *testing.T) {
+	conn := newScratchDB(t)
+
+	migrator, err := NewMigrator(context.Background(), conn)
+	require.NoError(t, err)
+	defer migrator.Close()
+
+	latest, err := LatestVersion()
+	require.NoError(t, err)
+
+	status, err := migrator.Status()
+	require.NoError(t, err)
+	require.Equal(t, Status{Latest: latest}, status)
+
+	require.NoError(t, migrator.Up())
+	requireTables(t, conn, true, "users", "accounts", "entries", "payments", "audit_events")
+
+	status, err = migrator.Status()
+	require.NoError(t, err)
+	require.Equal(t, Status{Version: latest, Latest: latest}, status)
+
+	// applying again is a no-op
+	require.NoError(t, migrator.Up())
+
+	require.NoError(t, migrator.Down(1))
+	status, err = migrator.Status()
+	require.NoError(t, err)
+	require.Equal(t, latest-1, status.Version)
+
+	require.NoError(t, migrator.Down(int(latest-1)))
+	requireTables(t, conn, false, "users", "accounts", "entries", "payments", "audit_events")
+
+	status, err = migrator.Status()
+	require.NoError(t, err)
+	require.Equal(t, Status{Latest: latest}, status)
+
+	// the down migrations leave nothing behind that breaks a fresh up
+	require.NoError(t, migrator.Up())
+	requireTables(t, conn, true, "users", "accounts", "entries", "payments", "audit_events")
+}
+
+func TestMigrateDownWithSavingsAccount(t *testing.T) {
+	conn := newScratchDB(t)
+
+	migrator, err := NewMigrator(context.Background(), conn)
+	require.NoError(t, err)
+	defer migrator.Close()
+
+	latest, err := LatestVersion()
+	require.NoError(t, err)
+	require.NoError(t, migrator.Up())
+
+	// a checking and a savings account in the same currency
+	user, err := db.NewStore(conn).CreateUser(context.Background(), db.CreateUserParams{
+		Username:       utils.RandomOwner(),
+		HashedPassword: utils.RandomString(16),
+		FullName:       utils.RandomOwner(),
+		Email:          utils.RandomEmail(),
+	})
+	require.NoError(t, err)
+	_, err = conn.Exec(`INSERT INTO accounts (owner, balance, currency, type)
+VALUES ($1, 100, 'USD', 'checking'), ($1, 50, 'USD', 'savings')`, user.Username)
+	require.NoError(t, err)
+
+	require.NoError(t, migrator.Down(int(latest-15)))
+
+	// the savings migration refuses to go down and changes nothing
+	err = migrator.Down(1)
+	require.ErrorContains(t, err, "savings accounts exist")
+	requireTables(t, conn, true, "savings_accounts", "interest_payouts")
+
+	var accounts int
+	err = conn.QueryRow("SELECT count(*) FROM accounts WHERE owner = $1", user.Username).Scan(&accounts)
+	require.NoError(t, err)
+	require.Equal(t, 2, accounts)
+
+	_, err = conn.Exec("DELETE FROM accounts WHERE type = 'savings'")
+	require.NoError(t, err)
+	require.NoError(t, migrator.Force(15))
+
+	require.NoError(t, migrator.Down(1))
+	requireTables(t, conn, false, "savings_accounts", "interest_payouts")
+
+	status, err := migrator.Status()
+	require.NoError(t, err)
+	require.Equal(t, Status{Version: 14, Latest: latest}, status)
+}
+
+func TestMigrateForce(t *testing.T) {
+	conn := newScratchDB(t)
+
+	migrator, err := NewMigrator(context.Background(), conn)
+	require.NoError(t, err)
+	defer migrator.Close()
+
+	require.NoError(t, migrator.Up())
+
+	_, err = conn.Exec("UPDATE schema_migrations SET dirty = true")
+	require.NoError(t, err)
+
+	status, err := migrator.Status()
+	require.NoError(t, err)
+	require.True(t, status.Dirty)
+
+	require.NoError(t, migrator.Force(int(status.Version)))
+
+	status, err = migrator.Status()
+	require.NoError(t, err)
+	require.False(t, status.Dirty)
+}
+
+func TestUpWithLockConcurrently(t *testing.T) {
+	conn := newScratchDB(t)
+
+	n := 3
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = UpWithLock(context.Background(), conn)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	latest, err := LatestVersion()
+	require.NoError(t, err)
+
+	var version uint
+	var dirty bool
+	err = conn.QueryRow("SELECT version, dirty FROM schema_migrations").Scan(&version, &dirty)
+	require.NoError(t, err)
+	require.Equal(t, latest, version)
+	require.False(t, dirty)
+}
+
+func TestStoreAfterUpWithLock(t *testing.T) {
+	conn := newScratchDB(t)
+
+	require.NoError(t, UpWithLock(context.Background(), conn))
+
+	// the server goes on with the same pool once migrated
+	store := db.NewStore(conn)
+	user, err := store.CreateUser(context.Background(), db.CreateUserParams{
+		Username:       utils.RandomOwner(),
+		HashedPassword: utils.RandomString(16),
+		FullName:       utils.RandomOwner(),
+		Email:          utils.RandomEmail(),
+	})
+	require.NoError(t, err)
+
+	got, err := store.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, user.Username, got.Username)
+}
@@ -12,6 +12,7 @@ package mockdb
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	db "github.com/danielmoisa/neobank/db/sqlc"
 	gomock "go.uber.org/mock/gomock"
@@ -40,6 +41,21 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// AcceptAccountInvitationTx mocks base method.
+func (m *MockStore) AcceptAccountInvitationTx(arg0 context.Context, arg1 db.AcceptAccountInvitationTxParams) (db.AcceptAccountInvitationTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptAccountInvitationTx", arg0, arg1)
+	ret0, _ := ret[0].(db.AcceptAccountInvitationTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptAccountInvitationTx indicates an expected call of AcceptAccountInvitationTx.
+func (mr *MockStoreMockRecorder) AcceptAccountInvitationTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptAccountInvitationTx", reflect.TypeOf((*MockStore)(nil).AcceptAccountInvitationTx), arg0, arg1)
+}
+
 // AddAccountBalance mocks base method.
 func (m *MockStore) AddAccountBalance(arg0 context.Context, arg1 db.AddAccountBalanceParams) (db.Account, error) {
 	m.ctrl.T.Helper()
@@ -55,226 +71,3290 @@ func (mr *MockStoreMockRecorder) AddAccountBalance(arg0, arg1 any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAccountBalance", reflect.TypeOf((*MockStore)(nil).AddAccountBalance), arg0, arg1)
 }
 
-// CreateAccount mocks base method.
-func (m *MockStore) CreateAccount(arg0 context.Context, arg1 db.CreateAccountParams) (db.Account, error) {
+// AddKYCDocumentTx mocks base method.
+func (m *MockStore) AddKYCDocumentTx(arg0 context.Context, arg1 db.CreateKYCDocumentParams) (db.KYCDocument, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateAccount", arg0, arg1)
-	ret0, _ := ret[0].(db.Account)
+	ret := m.ctrl.Call(m, "AddKYCDocumentTx", arg0, arg1)
+	ret0, _ := ret[0].(db.KYCDocument)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateAccount indicates an expected call of CreateAccount.
-func (mr *MockStoreMockRecorder) CreateAccount(arg0, arg1 any) *gomock.Call {
+// AddKYCDocumentTx indicates an expected call of AddKYCDocumentTx.
+func (mr *MockStoreMockRecorder) AddKYCDocumentTx(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockStore)(nil).CreateAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddKYCDocumentTx", reflect.TypeOf((*MockStore)(nil).AddKYCDocumentTx), arg0, arg1)
 }
 
-// CreateEntry mocks base method.
-func (m *MockStore) CreateEntry(arg0 context.Context, arg1 db.CreateEntryParams) (db.Entry, error) {
+// ApproveAdjustmentTx mocks base method.
+func (m *MockStore) ApproveAdjustmentTx(arg0 context.Context, arg1 db.ReviewAdjustmentTxParams) (db.ApproveAdjustmentTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateEntry", arg0, arg1)
-	ret0, _ := ret[0].(db.Entry)
+	ret := m.ctrl.Call(m, "ApproveAdjustmentTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ApproveAdjustmentTxResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateEntry indicates an expected call of CreateEntry.
-func (mr *MockStoreMockRecorder) CreateEntry(arg0, arg1 any) *gomock.Call {
+// ApproveAdjustmentTx indicates an expected call of ApproveAdjustmentTx.
+func (mr *MockStoreMockRecorder) ApproveAdjustmentTx(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEntry", reflect.TypeOf((*MockStore)(nil).CreateEntry), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveAdjustmentTx", reflect.TypeOf((*MockStore)(nil).ApproveAdjustmentTx), arg0, arg1)
 }
 
-// CreatePayment mocks base method.
-func (m *MockStore) CreatePayment(arg0 context.Context, arg1 db.CreatePaymentParams) (db.Payment, error) {
+// AssignAMLAlert mocks base method.
+func (m *MockStore) AssignAMLAlert(arg0 context.Context, arg1 db.AssignAMLAlertParams) (db.AMLAlert, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreatePayment", arg0, arg1)
-	ret0, _ := ret[0].(db.Payment)
+	ret := m.ctrl.Call(m, "AssignAMLAlert", arg0, arg1)
+	ret0, _ := ret[0].(db.AMLAlert)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreatePayment indicates an expected call of CreatePayment.
-func (mr *MockStoreMockRecorder) CreatePayment(arg0, arg1 any) *gomock.Call {
+// AssignAMLAlert indicates an expected call of AssignAMLAlert.
+func (mr *MockStoreMockRecorder) AssignAMLAlert(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePayment", reflect.TypeOf((*MockStore)(nil).CreatePayment), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignAMLAlert", reflect.TypeOf((*MockStore)(nil).AssignAMLAlert), arg0, arg1)
 }
 
-// CreateUser mocks base method.
-func (m *MockStore) CreateUser(arg0 context.Context, arg1 db.CreateUserParams) (db.User, error) {
+// AuthorizeCardTx mocks base method.
+func (m *MockStore) AuthorizeCardTx(arg0 context.Context, arg1 db.AuthorizeCardTxParams) (db.CardAuthorization, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1)
-	ret0, _ := ret[0].(db.User)
+	ret := m.ctrl.Call(m, "AuthorizeCardTx", arg0, arg1)
+	ret0, _ := ret[0].(db.CardAuthorization)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateUser indicates an expected call of CreateUser.
-func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 any) *gomock.Call {
+// AuthorizeCardTx indicates an expected call of AuthorizeCardTx.
+func (mr *MockStoreMockRecorder) AuthorizeCardTx(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthorizeCardTx", reflect.TypeOf((*MockStore)(nil).AuthorizeCardTx), arg0, arg1)
 }
 
-// DeleteAccount mocks base method.
-func (m *MockStore) DeleteAccount(arg0 context.Context, arg1 int64) error {
+// AuthorizeOAuthClientTx mocks base method.
+func (m *MockStore) AuthorizeOAuthClientTx(arg0 context.Context, arg1 db.AuthorizeOAuthClientTxParams) (db.AuthorizeOAuthClientTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteAccount", arg0, arg1)
+	ret := m.ctrl.Call(m, "AuthorizeOAuthClientTx", arg0, arg1)
+	ret0, _ := ret[0].(db.AuthorizeOAuthClientTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthorizeOAuthClientTx indicates an expected call of AuthorizeOAuthClientTx.
+func (mr *MockStoreMockRecorder) AuthorizeOAuthClientTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthorizeOAuthClientTx", reflect.TypeOf((*MockStore)(nil).AuthorizeOAuthClientTx), arg0, arg1)
+}
+
+// CancelLoanInstallments mocks base method.
+func (m *MockStore) CancelLoanInstallments(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelLoanInstallments", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteAccount indicates an expected call of DeleteAccount.
-func (mr *MockStoreMockRecorder) DeleteAccount(arg0, arg1 any) *gomock.Call {
+// CancelLoanInstallments indicates an expected call of CancelLoanInstallments.
+func (mr *MockStoreMockRecorder) CancelLoanInstallments(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockStore)(nil).DeleteAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelLoanInstallments", reflect.TypeOf((*MockStore)(nil).CancelLoanInstallments), arg0, arg1)
 }
 
-// GetAccount mocks base method.
-func (m *MockStore) GetAccount(arg0 context.Context, arg1 int64) (db.Account, error) {
+// CaptureCardAuthorization mocks base method.
+func (m *MockStore) CaptureCardAuthorization(arg0 context.Context, arg1 db.CaptureCardAuthorizationParams) (db.CardAuthorization, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccount", arg0, arg1)
-	ret0, _ := ret[0].(db.Account)
+	ret := m.ctrl.Call(m, "CaptureCardAuthorization", arg0, arg1)
+	ret0, _ := ret[0].(db.CardAuthorization)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAccount indicates an expected call of GetAccount.
-func (mr *MockStoreMockRecorder) GetAccount(arg0, arg1 any) *gomock.Call {
+// CaptureCardAuthorization indicates an expected call of CaptureCardAuthorization.
+func (mr *MockStoreMockRecorder) CaptureCardAuthorization(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockStore)(nil).GetAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureCardAuthorization", reflect.TypeOf((*MockStore)(nil).CaptureCardAuthorization), arg0, arg1)
 }
 
-// GetAccountForUpdate mocks base method.
-func (m *MockStore) GetAccountForUpdate(arg0 context.Context, arg1 int64) (db.Account, error) {
+// CaptureCardTx mocks base method.
+func (m *MockStore) CaptureCardTx(arg0 context.Context, arg1 db.CaptureCardTxParams) (db.CaptureCardTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccountForUpdate", arg0, arg1)
-	ret0, _ := ret[0].(db.Account)
+	ret := m.ctrl.Call(m, "CaptureCardTx", arg0, arg1)
+	ret0, _ := ret[0].(db.CaptureCardTxResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAccountForUpdate indicates an expected call of GetAccountForUpdate.
-func (mr *MockStoreMockRecorder) GetAccountForUpdate(arg0, arg1 any) *gomock.Call {
+// CaptureCardTx indicates an expected call of CaptureCardTx.
+func (mr *MockStoreMockRecorder) CaptureCardTx(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountForUpdate", reflect.TypeOf((*MockStore)(nil).GetAccountForUpdate), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureCardTx", reflect.TypeOf((*MockStore)(nil).CaptureCardTx), arg0, arg1)
 }
 
-// GetEntry mocks base method.
-func (m *MockStore) GetEntry(arg0 context.Context, arg1 int64) (db.Entry, error) {
+// ChangePasswordTx mocks base method.
+func (m *MockStore) ChangePasswordTx(arg0 context.Context, arg1 db.ChangePasswordTxParams) (db.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetEntry", arg0, arg1)
-	ret0, _ := ret[0].(db.Entry)
+	ret := m.ctrl.Call(m, "ChangePasswordTx", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetEntry indicates an expected call of GetEntry.
-func (mr *MockStoreMockRecorder) GetEntry(arg0, arg1 any) *gomock.Call {
+// ChangePasswordTx indicates an expected call of ChangePasswordTx.
+func (mr *MockStoreMockRecorder) ChangePasswordTx(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntry", reflect.TypeOf((*MockStore)(nil).GetEntry), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePasswordTx", reflect.TypeOf((*MockStore)(nil).ChangePasswordTx), arg0, arg1)
 }
 
-// GetPayment mocks base method.
-func (m *MockStore) GetPayment(arg0 context.Context, arg1 int64) (db.Payment, error) {
+// CloseLoan mocks base method.
+func (m *MockStore) CloseLoan(arg0 context.Context, arg1 int64) (db.Loan, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPayment", arg0, arg1)
-	ret0, _ := ret[0].(db.Payment)
+	ret := m.ctrl.Call(m, "CloseLoan", arg0, arg1)
+	ret0, _ := ret[0].(db.Loan)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetPayment indicates an expected call of GetPayment.
-func (mr *MockStoreMockRecorder) GetPayment(arg0, arg1 any) *gomock.Call {
+// CloseLoan indicates an expected call of CloseLoan.
+func (mr *MockStoreMockRecorder) CloseLoan(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPayment", reflect.TypeOf((*MockStore)(nil).GetPayment), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseLoan", reflect.TypeOf((*MockStore)(nil).CloseLoan), arg0, arg1)
 }
 
-// GetUser mocks base method.
-func (m *MockStore) GetUser(arg0 context.Context, arg1 string) (db.User, error) {
+// CollectInstallmentTx mocks base method.
+func (m *MockStore) CollectInstallmentTx(arg0 context.Context, arg1 db.CollectInstallmentTxParams) (db.CollectInstallmentTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
-	ret0, _ := ret[0].(db.User)
+	ret := m.ctrl.Call(m, "CollectInstallmentTx", arg0, arg1)
+	ret0, _ := ret[0].(db.CollectInstallmentTxResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUser indicates an expected call of GetUser.
-func (mr *MockStoreMockRecorder) GetUser(arg0, arg1 any) *gomock.Call {
+// CollectInstallmentTx indicates an expected call of CollectInstallmentTx.
+func (mr *MockStoreMockRecorder) CollectInstallmentTx(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStore)(nil).GetUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CollectInstallmentTx", reflect.TypeOf((*MockStore)(nil).CollectInstallmentTx), arg0, arg1)
 }
 
-// ListAccounts mocks base method.
-func (m *MockStore) ListAccounts(arg0 context.Context, arg1 db.ListAccountsParams) ([]db.Account, error) {
+// CompleteEmailChange mocks base method.
+func (m *MockStore) CompleteEmailChange(arg0 context.Context, arg1 int64) (db.EmailChange, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListAccounts", arg0, arg1)
-	ret0, _ := ret[0].([]db.Account)
+	ret := m.ctrl.Call(m, "CompleteEmailChange", arg0, arg1)
+	ret0, _ := ret[0].(db.EmailChange)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListAccounts indicates an expected call of ListAccounts.
-func (mr *MockStoreMockRecorder) ListAccounts(arg0, arg1 any) *gomock.Call {
+// CompleteEmailChange indicates an expected call of CompleteEmailChange.
+func (mr *MockStoreMockRecorder) CompleteEmailChange(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockStore)(nil).ListAccounts), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteEmailChange", reflect.TypeOf((*MockStore)(nil).CompleteEmailChange), arg0, arg1)
 }
 
-// ListEntries mocks base method.
-func (m *MockStore) ListEntries(arg0 context.Context, arg1 db.ListEntriesParams) ([]db.Entry, error) {
+// ConfirmEmailChange mocks base method.
+func (m *MockStore) ConfirmEmailChange(arg0 context.Context, arg1 db.ConfirmEmailChangeParams) (db.EmailChange, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListEntries", arg0, arg1)
-	ret0, _ := ret[0].([]db.Entry)
+	ret := m.ctrl.Call(m, "ConfirmEmailChange", arg0, arg1)
+	ret0, _ := ret[0].(db.EmailChange)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListEntries indicates an expected call of ListEntries.
-func (mr *MockStoreMockRecorder) ListEntries(arg0, arg1 any) *gomock.Call {
+// ConfirmEmailChange indicates an expected call of ConfirmEmailChange.
+func (mr *MockStoreMockRecorder) ConfirmEmailChange(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntries", reflect.TypeOf((*MockStore)(nil).ListEntries), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmEmailChange", reflect.TypeOf((*MockStore)(nil).ConfirmEmailChange), arg0, arg1)
 }
 
-// ListPayments mocks base method.
-func (m *MockStore) ListPayments(arg0 context.Context, arg1 db.ListPaymentsParams) ([]db.Payment, error) {
+// ConfirmEmailChangeTx mocks base method.
+func (m *MockStore) ConfirmEmailChangeTx(arg0 context.Context, arg1 string) (db.ConfirmEmailChangeTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListPayments", arg0, arg1)
-	ret0, _ := ret[0].([]db.Payment)
+	ret := m.ctrl.Call(m, "ConfirmEmailChangeTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ConfirmEmailChangeTxResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListPayments indicates an expected call of ListPayments.
-func (mr *MockStoreMockRecorder) ListPayments(arg0, arg1 any) *gomock.Call {
+// ConfirmEmailChangeTx indicates an expected call of ConfirmEmailChangeTx.
+func (mr *MockStoreMockRecorder) ConfirmEmailChangeTx(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPayments", reflect.TypeOf((*MockStore)(nil).ListPayments), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmEmailChangeTx", reflect.TypeOf((*MockStore)(nil).ConfirmEmailChangeTx), arg0, arg1)
 }
 
-// PaymentTx mocks base method.
-func (m *MockStore) PaymentTx(arg0 context.Context, arg1 db.PaymentTxParams) (db.PaymentTxResult, error) {
+// ConsumeUserToken mocks base method.
+func (m *MockStore) ConsumeUserToken(arg0 context.Context, arg1 db.ConsumeUserTokenParams) (db.UserToken, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PaymentTx", arg0, arg1)
-	ret0, _ := ret[0].(db.PaymentTxResult)
+	ret := m.ctrl.Call(m, "ConsumeUserToken", arg0, arg1)
+	ret0, _ := ret[0].(db.UserToken)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// PaymentTx indicates an expected call of PaymentTx.
-func (mr *MockStoreMockRecorder) PaymentTx(arg0, arg1 any) *gomock.Call {
+// ConsumeUserToken indicates an expected call of ConsumeUserToken.
+func (mr *MockStoreMockRecorder) ConsumeUserToken(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PaymentTx", reflect.TypeOf((*MockStore)(nil).PaymentTx), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeUserToken", reflect.TypeOf((*MockStore)(nil).ConsumeUserToken), arg0, arg1)
+}
+
+// CountAccounts mocks base method.
+func (m *MockStore) CountAccounts(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountAccounts", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountAccounts indicates an expected call of CountAccounts.
+func (mr *MockStoreMockRecorder) CountAccounts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountAccounts", reflect.TypeOf((*MockStore)(nil).CountAccounts), arg0, arg1)
+}
+
+// CountBlockingScreeningHits mocks base method.
+func (m *MockStore) CountBlockingScreeningHits(arg0 context.Context, arg1 []string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountBlockingScreeningHits", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountBlockingScreeningHits indicates an expected call of CountBlockingScreeningHits.
+func (mr *MockStoreMockRecorder) CountBlockingScreeningHits(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountBlockingScreeningHits", reflect.TypeOf((*MockStore)(nil).CountBlockingScreeningHits), arg0, arg1)
+}
+
+// CountUnpaidLoanInstallments mocks base method.
+func (m *MockStore) CountUnpaidLoanInstallments(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnpaidLoanInstallments", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnpaidLoanInstallments indicates an expected call of CountUnpaidLoanInstallments.
+func (mr *MockStoreMockRecorder) CountUnpaidLoanInstallments(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnpaidLoanInstallments", reflect.TypeOf((*MockStore)(nil).CountUnpaidLoanInstallments), arg0, arg1)
+}
+
+// CreateAMLAlertComment mocks base method.
+func (m *MockStore) CreateAMLAlertComment(arg0 context.Context, arg1 db.CreateAMLAlertCommentParams) (db.AMLAlertComment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAMLAlertComment", arg0, arg1)
+	ret0, _ := ret[0].(db.AMLAlertComment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAMLAlertComment indicates an expected call of CreateAMLAlertComment.
+func (mr *MockStoreMockRecorder) CreateAMLAlertComment(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAMLAlertComment", reflect.TypeOf((*MockStore)(nil).CreateAMLAlertComment), arg0, arg1)
+}
+
+// CreateAMLJobRun mocks base method.
+func (m *MockStore) CreateAMLJobRun(arg0 context.Context, arg1 db.CreateAMLJobRunParams) (db.AMLJobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAMLJobRun", arg0, arg1)
+	ret0, _ := ret[0].(db.AMLJobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAMLJobRun indicates an expected call of CreateAMLJobRun.
+func (mr *MockStoreMockRecorder) CreateAMLJobRun(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAMLJobRun", reflect.TypeOf((*MockStore)(nil).CreateAMLJobRun), arg0, arg1)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockStore) CreateAPIKey(arg0 context.Context, arg1 db.CreateAPIKeyParams) (db.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(db.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockStoreMockRecorder) CreateAPIKey(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockStore)(nil).CreateAPIKey), arg0, arg1)
 }
 
-// UpdateAccount mocks base method.
-func (m *MockStore) UpdateAccount(arg0 context.Context, arg1 db.UpdateAccountParams) (db.Account, error) {
+// CreateAccount mocks base method.
+func (m *MockStore) CreateAccount(arg0 context.Context, arg1 db.CreateAccountParams) (db.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateAccount", arg0, arg1)
+	ret := m.ctrl.Call(m, "CreateAccount", arg0, arg1)
 	ret0, _ := ret[0].(db.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdateAccount indicates an expected call of UpdateAccount.
-func (mr *MockStoreMockRecorder) UpdateAccount(arg0, arg1 any) *gomock.Call {
+// CreateAccount indicates an expected call of CreateAccount.
+func (mr *MockStoreMockRecorder) CreateAccount(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockStore)(nil).CreateAccount), arg0, arg1)
+}
+
+// CreateAccountInvitation mocks base method.
+func (m *MockStore) CreateAccountInvitation(arg0 context.Context, arg1 db.CreateAccountInvitationParams) (db.AccountInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccountInvitation", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccountInvitation indicates an expected call of CreateAccountInvitation.
+func (mr *MockStoreMockRecorder) CreateAccountInvitation(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccountInvitation", reflect.TypeOf((*MockStore)(nil).CreateAccountInvitation), arg0, arg1)
+}
+
+// CreateAdjustment mocks base method.
+func (m *MockStore) CreateAdjustment(arg0 context.Context, arg1 db.CreateAdjustmentParams) (db.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAdjustment", arg0, arg1)
+	ret0, _ := ret[0].(db.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAdjustment indicates an expected call of CreateAdjustment.
+func (mr *MockStoreMockRecorder) CreateAdjustment(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAdjustment", reflect.TypeOf((*MockStore)(nil).CreateAdjustment), arg0, arg1)
+}
+
+// CreateAuditEvent mocks base method.
+func (m *MockStore) CreateAuditEvent(arg0 context.Context, arg1 db.CreateAuditEventParams) (db.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditEvent", arg0, arg1)
+	ret0, _ := ret[0].(db.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAuditEvent indicates an expected call of CreateAuditEvent.
+func (mr *MockStoreMockRecorder) CreateAuditEvent(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditEvent", reflect.TypeOf((*MockStore)(nil).CreateAuditEvent), arg0, arg1)
+}
+
+// CreateCard mocks base method.
+func (m *MockStore) CreateCard(arg0 context.Context, arg1 db.CreateCardParams) (db.Card, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCard", arg0, arg1)
+	ret0, _ := ret[0].(db.Card)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCard indicates an expected call of CreateCard.
+func (mr *MockStoreMockRecorder) CreateCard(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCard", reflect.TypeOf((*MockStore)(nil).CreateCard), arg0, arg1)
+}
+
+// CreateCardAuthorization mocks base method.
+func (m *MockStore) CreateCardAuthorization(arg0 context.Context, arg1 db.CreateCardAuthorizationParams) (db.CardAuthorization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCardAuthorization", arg0, arg1)
+	ret0, _ := ret[0].(db.CardAuthorization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCardAuthorization indicates an expected call of CreateCardAuthorization.
+func (mr *MockStoreMockRecorder) CreateCardAuthorization(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCardAuthorization", reflect.TypeOf((*MockStore)(nil).CreateCardAuthorization), arg0, arg1)
+}
+
+// CreateEmailChange mocks base method.
+func (m *MockStore) CreateEmailChange(arg0 context.Context, arg1 db.CreateEmailChangeParams) (db.EmailChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEmailChange", arg0, arg1)
+	ret0, _ := ret[0].(db.EmailChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEmailChange indicates an expected call of CreateEmailChange.
+func (mr *MockStoreMockRecorder) CreateEmailChange(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEmailChange", reflect.TypeOf((*MockStore)(nil).CreateEmailChange), arg0, arg1)
+}
+
+// CreateEntry mocks base method.
+func (m *MockStore) CreateEntry(arg0 context.Context, arg1 db.CreateEntryParams) (db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEntry", arg0, arg1)
+	ret0, _ := ret[0].(db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEntry indicates an expected call of CreateEntry.
+func (mr *MockStoreMockRecorder) CreateEntry(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEntry", reflect.TypeOf((*MockStore)(nil).CreateEntry), arg0, arg1)
+}
+
+// CreateFraudDecision mocks base method.
+func (m *MockStore) CreateFraudDecision(arg0 context.Context, arg1 db.CreateFraudDecisionParams) (db.FraudDecision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFraudDecision", arg0, arg1)
+	ret0, _ := ret[0].(db.FraudDecision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFraudDecision indicates an expected call of CreateFraudDecision.
+func (mr *MockStoreMockRecorder) CreateFraudDecision(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFraudDecision", reflect.TypeOf((*MockStore)(nil).CreateFraudDecision), arg0, arg1)
+}
+
+// CreateInterestAccrual mocks base method.
+func (m *MockStore) CreateInterestAccrual(arg0 context.Context, arg1 db.CreateInterestAccrualParams) (db.InterestAccrual, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInterestAccrual", arg0, arg1)
+	ret0, _ := ret[0].(db.InterestAccrual)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInterestAccrual indicates an expected call of CreateInterestAccrual.
+func (mr *MockStoreMockRecorder) CreateInterestAccrual(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInterestAccrual", reflect.TypeOf((*MockStore)(nil).CreateInterestAccrual), arg0, arg1)
+}
+
+// CreateInterestPayout mocks base method.
+func (m *MockStore) CreateInterestPayout(arg0 context.Context, arg1 db.CreateInterestPayoutParams) (db.InterestPayout, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInterestPayout", arg0, arg1)
+	ret0, _ := ret[0].(db.InterestPayout)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInterestPayout indicates an expected call of CreateInterestPayout.
+func (mr *MockStoreMockRecorder) CreateInterestPayout(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInterestPayout", reflect.TypeOf((*MockStore)(nil).CreateInterestPayout), arg0, arg1)
+}
+
+// CreateKYCDocument mocks base method.
+func (m *MockStore) CreateKYCDocument(arg0 context.Context, arg1 db.CreateKYCDocumentParams) (db.KYCDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateKYCDocument", arg0, arg1)
+	ret0, _ := ret[0].(db.KYCDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateKYCDocument indicates an expected call of CreateKYCDocument.
+func (mr *MockStoreMockRecorder) CreateKYCDocument(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateKYCDocument", reflect.TypeOf((*MockStore)(nil).CreateKYCDocument), arg0, arg1)
+}
+
+// CreateLoan mocks base method.
+func (m *MockStore) CreateLoan(arg0 context.Context, arg1 db.CreateLoanParams) (db.Loan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLoan", arg0, arg1)
+	ret0, _ := ret[0].(db.Loan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateLoan indicates an expected call of CreateLoan.
+func (mr *MockStoreMockRecorder) CreateLoan(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLoan", reflect.TypeOf((*MockStore)(nil).CreateLoan), arg0, arg1)
+}
+
+// CreateLoanInstallment mocks base method.
+func (m *MockStore) CreateLoanInstallment(arg0 context.Context, arg1 db.CreateLoanInstallmentParams) (db.LoanInstallment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLoanInstallment", arg0, arg1)
+	ret0, _ := ret[0].(db.LoanInstallment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateLoanInstallment indicates an expected call of CreateLoanInstallment.
+func (mr *MockStoreMockRecorder) CreateLoanInstallment(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLoanInstallment", reflect.TypeOf((*MockStore)(nil).CreateLoanInstallment), arg0, arg1)
+}
+
+// CreateLoanProduct mocks base method.
+func (m *MockStore) CreateLoanProduct(arg0 context.Context, arg1 db.CreateLoanProductParams) (db.LoanProduct, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLoanProduct", arg0, arg1)
+	ret0, _ := ret[0].(db.LoanProduct)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateLoanProduct indicates an expected call of CreateLoanProduct.
+func (mr *MockStoreMockRecorder) CreateLoanProduct(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLoanProduct", reflect.TypeOf((*MockStore)(nil).CreateLoanProduct), arg0, arg1)
+}
+
+// CreateLoanRepayment mocks base method.
+func (m *MockStore) CreateLoanRepayment(arg0 context.Context, arg1 db.CreateLoanRepaymentParams) (db.LoanRepayment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLoanRepayment", arg0, arg1)
+	ret0, _ := ret[0].(db.LoanRepayment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateLoanRepayment indicates an expected call of CreateLoanRepayment.
+func (mr *MockStoreMockRecorder) CreateLoanRepayment(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLoanRepayment", reflect.TypeOf((*MockStore)(nil).CreateLoanRepayment), arg0, arg1)
+}
+
+// CreateNotification mocks base method.
+func (m *MockStore) CreateNotification(arg0 context.Context, arg1 db.CreateNotificationParams) (db.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNotification", arg0, arg1)
+	ret0, _ := ret[0].(db.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateNotification indicates an expected call of CreateNotification.
+func (mr *MockStoreMockRecorder) CreateNotification(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNotification", reflect.TypeOf((*MockStore)(nil).CreateNotification), arg0, arg1)
+}
+
+// CreateOAuthAuthorizationCode mocks base method.
+func (m *MockStore) CreateOAuthAuthorizationCode(arg0 context.Context, arg1 db.CreateOAuthAuthorizationCodeParams) (db.OAuthAuthorizationCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOAuthAuthorizationCode", arg0, arg1)
+	ret0, _ := ret[0].(db.OAuthAuthorizationCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOAuthAuthorizationCode indicates an expected call of CreateOAuthAuthorizationCode.
+func (mr *MockStoreMockRecorder) CreateOAuthAuthorizationCode(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOAuthAuthorizationCode", reflect.TypeOf((*MockStore)(nil).CreateOAuthAuthorizationCode), arg0, arg1)
+}
+
+// CreateOAuthClient mocks base method.
+func (m *MockStore) CreateOAuthClient(arg0 context.Context, arg1 db.CreateOAuthClientParams) (db.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOAuthClient", arg0, arg1)
+	ret0, _ := ret[0].(db.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOAuthClient indicates an expected call of CreateOAuthClient.
+func (mr *MockStoreMockRecorder) CreateOAuthClient(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOAuthClient", reflect.TypeOf((*MockStore)(nil).CreateOAuthClient), arg0, arg1)
+}
+
+// CreateOAuthConsent mocks base method.
+func (m *MockStore) CreateOAuthConsent(arg0 context.Context, arg1 db.CreateOAuthConsentParams) (db.OAuthConsent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOAuthConsent", arg0, arg1)
+	ret0, _ := ret[0].(db.OAuthConsent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOAuthConsent indicates an expected call of CreateOAuthConsent.
+func (mr *MockStoreMockRecorder) CreateOAuthConsent(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOAuthConsent", reflect.TypeOf((*MockStore)(nil).CreateOAuthConsent), arg0, arg1)
+}
+
+// CreateOAuthRefreshToken mocks base method.
+func (m *MockStore) CreateOAuthRefreshToken(arg0 context.Context, arg1 db.CreateOAuthRefreshTokenParams) (db.OAuthRefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOAuthRefreshToken", arg0, arg1)
+	ret0, _ := ret[0].(db.OAuthRefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOAuthRefreshToken indicates an expected call of CreateOAuthRefreshToken.
+func (mr *MockStoreMockRecorder) CreateOAuthRefreshToken(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOAuthRefreshToken", reflect.TypeOf((*MockStore)(nil).CreateOAuthRefreshToken), arg0, arg1)
+}
+
+// CreatePayment mocks base method.
+func (m *MockStore) CreatePayment(arg0 context.Context, arg1 db.CreatePaymentParams) (db.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePayment", arg0, arg1)
+	ret0, _ := ret[0].(db.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePayment indicates an expected call of CreatePayment.
+func (mr *MockStoreMockRecorder) CreatePayment(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePayment", reflect.TypeOf((*MockStore)(nil).CreatePayment), arg0, arg1)
+}
+
+// CreatePocket mocks base method.
+func (m *MockStore) CreatePocket(arg0 context.Context, arg1 db.CreatePocketParams) (db.Pocket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePocket", arg0, arg1)
+	ret0, _ := ret[0].(db.Pocket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePocket indicates an expected call of CreatePocket.
+func (mr *MockStoreMockRecorder) CreatePocket(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePocket", reflect.TypeOf((*MockStore)(nil).CreatePocket), arg0, arg1)
+}
+
+// CreatePocketAccount mocks base method.
+func (m *MockStore) CreatePocketAccount(arg0 context.Context, arg1 db.CreatePocketAccountParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePocketAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePocketAccount indicates an expected call of CreatePocketAccount.
+func (mr *MockStoreMockRecorder) CreatePocketAccount(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePocketAccount", reflect.TypeOf((*MockStore)(nil).CreatePocketAccount), arg0, arg1)
+}
+
+// CreatePocketMove mocks base method.
+func (m *MockStore) CreatePocketMove(arg0 context.Context, arg1 db.CreatePocketMoveParams) (db.PocketMove, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePocketMove", arg0, arg1)
+	ret0, _ := ret[0].(db.PocketMove)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePocketMove indicates an expected call of CreatePocketMove.
+func (mr *MockStoreMockRecorder) CreatePocketMove(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePocketMove", reflect.TypeOf((*MockStore)(nil).CreatePocketMove), arg0, arg1)
+}
+
+// CreatePocketTx mocks base method.
+func (m *MockStore) CreatePocketTx(arg0 context.Context, arg1 db.CreatePocketTxParams) (db.CreatePocketTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePocketTx", arg0, arg1)
+	ret0, _ := ret[0].(db.CreatePocketTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePocketTx indicates an expected call of CreatePocketTx.
+func (mr *MockStoreMockRecorder) CreatePocketTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePocketTx", reflect.TypeOf((*MockStore)(nil).CreatePocketTx), arg0, arg1)
+}
+
+// CreateSavingsAccount mocks base method.
+func (m *MockStore) CreateSavingsAccount(arg0 context.Context, arg1 db.CreateSavingsAccountParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSavingsAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSavingsAccount indicates an expected call of CreateSavingsAccount.
+func (mr *MockStoreMockRecorder) CreateSavingsAccount(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSavingsAccount", reflect.TypeOf((*MockStore)(nil).CreateSavingsAccount), arg0, arg1)
+}
+
+// CreateSavingsAccountLink mocks base method.
+func (m *MockStore) CreateSavingsAccountLink(arg0 context.Context, arg1 db.CreateSavingsAccountLinkParams) (db.SavingsAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSavingsAccountLink", arg0, arg1)
+	ret0, _ := ret[0].(db.SavingsAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSavingsAccountLink indicates an expected call of CreateSavingsAccountLink.
+func (mr *MockStoreMockRecorder) CreateSavingsAccountLink(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSavingsAccountLink", reflect.TypeOf((*MockStore)(nil).CreateSavingsAccountLink), arg0, arg1)
+}
+
+// CreateSavingsAccountTx mocks base method.
+func (m *MockStore) CreateSavingsAccountTx(arg0 context.Context, arg1 db.CreateSavingsAccountTxParams) (db.CreateSavingsAccountTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSavingsAccountTx", arg0, arg1)
+	ret0, _ := ret[0].(db.CreateSavingsAccountTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSavingsAccountTx indicates an expected call of CreateSavingsAccountTx.
+func (mr *MockStoreMockRecorder) CreateSavingsAccountTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSavingsAccountTx", reflect.TypeOf((*MockStore)(nil).CreateSavingsAccountTx), arg0, arg1)
+}
+
+// CreateSavingsProduct mocks base method.
+func (m *MockStore) CreateSavingsProduct(arg0 context.Context, arg1 db.CreateSavingsProductParams) (db.SavingsProduct, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSavingsProduct", arg0, arg1)
+	ret0, _ := ret[0].(db.SavingsProduct)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSavingsProduct indicates an expected call of CreateSavingsProduct.
+func (mr *MockStoreMockRecorder) CreateSavingsProduct(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSavingsProduct", reflect.TypeOf((*MockStore)(nil).CreateSavingsProduct), arg0, arg1)
+}
+
+// CreateUser mocks base method.
+func (m *MockStore) CreateUser(arg0 context.Context, arg1 db.CreateUserParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
+}
+
+// CreateUserToken mocks base method.
+func (m *MockStore) CreateUserToken(arg0 context.Context, arg1 db.CreateUserTokenParams) (db.UserToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserToken", arg0, arg1)
+	ret0, _ := ret[0].(db.UserToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUserToken indicates an expected call of CreateUserToken.
+func (mr *MockStoreMockRecorder) CreateUserToken(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserToken", reflect.TypeOf((*MockStore)(nil).CreateUserToken), arg0, arg1)
+}
+
+// DeleteAccount mocks base method.
+func (m *MockStore) DeleteAccount(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockStoreMockRecorder) DeleteAccount(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockStore)(nil).DeleteAccount), arg0, arg1)
+}
+
+// DeleteAccountMember mocks base method.
+func (m *MockStore) DeleteAccountMember(arg0 context.Context, arg1 db.DeleteAccountMemberParams) (db.AccountMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccountMember", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAccountMember indicates an expected call of DeleteAccountMember.
+func (mr *MockStoreMockRecorder) DeleteAccountMember(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccountMember", reflect.TypeOf((*MockStore)(nil).DeleteAccountMember), arg0, arg1)
+}
+
+// DeleteStaleRateLimitBuckets mocks base method.
+func (m *MockStore) DeleteStaleRateLimitBuckets(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStaleRateLimitBuckets", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteStaleRateLimitBuckets indicates an expected call of DeleteStaleRateLimitBuckets.
+func (mr *MockStoreMockRecorder) DeleteStaleRateLimitBuckets(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStaleRateLimitBuckets", reflect.TypeOf((*MockStore)(nil).DeleteStaleRateLimitBuckets), arg0, arg1)
+}
+
+// Drain mocks base method.
+func (m *MockStore) Drain(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Drain", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Drain indicates an expected call of Drain.
+func (mr *MockStoreMockRecorder) Drain(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Drain", reflect.TypeOf((*MockStore)(nil).Drain), arg0)
+}
+
+// ExchangeOAuthCodeTx mocks base method.
+func (m *MockStore) ExchangeOAuthCodeTx(arg0 context.Context, arg1 db.ExchangeOAuthCodeTxParams) (db.OAuthGrantTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExchangeOAuthCodeTx", arg0, arg1)
+	ret0, _ := ret[0].(db.OAuthGrantTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExchangeOAuthCodeTx indicates an expected call of ExchangeOAuthCodeTx.
+func (mr *MockStoreMockRecorder) ExchangeOAuthCodeTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExchangeOAuthCodeTx", reflect.TypeOf((*MockStore)(nil).ExchangeOAuthCodeTx), arg0, arg1)
+}
+
+// ExpireAdjustments mocks base method.
+func (m *MockStore) ExpireAdjustments(arg0 context.Context) ([]db.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireAdjustments", arg0)
+	ret0, _ := ret[0].([]db.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireAdjustments indicates an expected call of ExpireAdjustments.
+func (mr *MockStoreMockRecorder) ExpireAdjustments(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireAdjustments", reflect.TypeOf((*MockStore)(nil).ExpireAdjustments), arg0)
+}
+
+// FindDormantReactivations mocks base method.
+func (m *MockStore) FindDormantReactivations(arg0 context.Context, arg1 db.FindDormantReactivationsParams) ([]db.FindDormantReactivationsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDormantReactivations", arg0, arg1)
+	ret0, _ := ret[0].([]db.FindDormantReactivationsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDormantReactivations indicates an expected call of FindDormantReactivations.
+func (mr *MockStoreMockRecorder) FindDormantReactivations(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDormantReactivations", reflect.TypeOf((*MockStore)(nil).FindDormantReactivations), arg0, arg1)
+}
+
+// FindPassThrough mocks base method.
+func (m *MockStore) FindPassThrough(arg0 context.Context, arg1 db.FindPassThroughParams) ([]db.FindPassThroughRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPassThrough", arg0, arg1)
+	ret0, _ := ret[0].([]db.FindPassThroughRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPassThrough indicates an expected call of FindPassThrough.
+func (mr *MockStoreMockRecorder) FindPassThrough(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPassThrough", reflect.TypeOf((*MockStore)(nil).FindPassThrough), arg0, arg1)
+}
+
+// FindRoundTrips mocks base method.
+func (m *MockStore) FindRoundTrips(arg0 context.Context, arg1 db.FindRoundTripsParams) ([]db.FindRoundTripsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRoundTrips", arg0, arg1)
+	ret0, _ := ret[0].([]db.FindRoundTripsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRoundTrips indicates an expected call of FindRoundTrips.
+func (mr *MockStoreMockRecorder) FindRoundTrips(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRoundTrips", reflect.TypeOf((*MockStore)(nil).FindRoundTrips), arg0, arg1)
+}
+
+// FindStructuring mocks base method.
+func (m *MockStore) FindStructuring(arg0 context.Context, arg1 db.FindStructuringParams) ([]db.FindStructuringRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindStructuring", arg0, arg1)
+	ret0, _ := ret[0].([]db.FindStructuringRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindStructuring indicates an expected call of FindStructuring.
+func (mr *MockStoreMockRecorder) FindStructuring(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindStructuring", reflect.TypeOf((*MockStore)(nil).FindStructuring), arg0, arg1)
+}
+
+// FinishAMLJobRun mocks base method.
+func (m *MockStore) FinishAMLJobRun(arg0 context.Context, arg1 db.FinishAMLJobRunParams) (db.AMLJobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishAMLJobRun", arg0, arg1)
+	ret0, _ := ret[0].(db.AMLJobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishAMLJobRun indicates an expected call of FinishAMLJobRun.
+func (mr *MockStoreMockRecorder) FinishAMLJobRun(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishAMLJobRun", reflect.TypeOf((*MockStore)(nil).FinishAMLJobRun), arg0, arg1)
+}
+
+// FreezeOwnerAccounts mocks base method.
+func (m *MockStore) FreezeOwnerAccounts(arg0 context.Context, arg1 string) ([]db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FreezeOwnerAccounts", arg0, arg1)
+	ret0, _ := ret[0].([]db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FreezeOwnerAccounts indicates an expected call of FreezeOwnerAccounts.
+func (mr *MockStoreMockRecorder) FreezeOwnerAccounts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FreezeOwnerAccounts", reflect.TypeOf((*MockStore)(nil).FreezeOwnerAccounts), arg0, arg1)
+}
+
+// GetAMLAlert mocks base method.
+func (m *MockStore) GetAMLAlert(arg0 context.Context, arg1 int64) (db.AMLAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAMLAlert", arg0, arg1)
+	ret0, _ := ret[0].(db.AMLAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAMLAlert indicates an expected call of GetAMLAlert.
+func (mr *MockStoreMockRecorder) GetAMLAlert(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAMLAlert", reflect.TypeOf((*MockStore)(nil).GetAMLAlert), arg0, arg1)
+}
+
+// GetAPIKeyByHash mocks base method.
+func (m *MockStore) GetAPIKeyByHash(arg0 context.Context, arg1 string) (db.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyByHash", arg0, arg1)
+	ret0, _ := ret[0].(db.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyByHash indicates an expected call of GetAPIKeyByHash.
+func (mr *MockStoreMockRecorder) GetAPIKeyByHash(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByHash", reflect.TypeOf((*MockStore)(nil).GetAPIKeyByHash), arg0, arg1)
+}
+
+// GetAccount mocks base method.
+func (m *MockStore) GetAccount(arg0 context.Context, arg1 int64) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccount indicates an expected call of GetAccount.
+func (mr *MockStoreMockRecorder) GetAccount(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockStore)(nil).GetAccount), arg0, arg1)
+}
+
+// GetAccountForUpdate mocks base method.
+func (m *MockStore) GetAccountForUpdate(arg0 context.Context, arg1 int64) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountForUpdate indicates an expected call of GetAccountForUpdate.
+func (mr *MockStoreMockRecorder) GetAccountForUpdate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountForUpdate", reflect.TypeOf((*MockStore)(nil).GetAccountForUpdate), arg0, arg1)
+}
+
+// GetAccountHolds mocks base method.
+func (m *MockStore) GetAccountHolds(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountHolds", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountHolds indicates an expected call of GetAccountHolds.
+func (mr *MockStoreMockRecorder) GetAccountHolds(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountHolds", reflect.TypeOf((*MockStore)(nil).GetAccountHolds), arg0, arg1)
+}
+
+// GetAccountInvitation mocks base method.
+func (m *MockStore) GetAccountInvitation(arg0 context.Context, arg1 int64) (db.AccountInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountInvitation", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountInvitation indicates an expected call of GetAccountInvitation.
+func (mr *MockStoreMockRecorder) GetAccountInvitation(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountInvitation", reflect.TypeOf((*MockStore)(nil).GetAccountInvitation), arg0, arg1)
+}
+
+// GetAccountInvitationForUpdate mocks base method.
+func (m *MockStore) GetAccountInvitationForUpdate(arg0 context.Context, arg1 int64) (db.AccountInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountInvitationForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountInvitationForUpdate indicates an expected call of GetAccountInvitationForUpdate.
+func (mr *MockStoreMockRecorder) GetAccountInvitationForUpdate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountInvitationForUpdate", reflect.TypeOf((*MockStore)(nil).GetAccountInvitationForUpdate), arg0, arg1)
+}
+
+// GetAccountMember mocks base method.
+func (m *MockStore) GetAccountMember(arg0 context.Context, arg1 db.GetAccountMemberParams) (db.AccountMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountMember", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountMember indicates an expected call of GetAccountMember.
+func (mr *MockStoreMockRecorder) GetAccountMember(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountMember", reflect.TypeOf((*MockStore)(nil).GetAccountMember), arg0, arg1)
+}
+
+// GetAccountPaymentTotal mocks base method.
+func (m *MockStore) GetAccountPaymentTotal(arg0 context.Context, arg1 db.GetAccountPaymentTotalParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountPaymentTotal", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountPaymentTotal indicates an expected call of GetAccountPaymentTotal.
+func (mr *MockStoreMockRecorder) GetAccountPaymentTotal(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountPaymentTotal", reflect.TypeOf((*MockStore)(nil).GetAccountPaymentTotal), arg0, arg1)
+}
+
+// GetAdjustment mocks base method.
+func (m *MockStore) GetAdjustment(arg0 context.Context, arg1 int64) (db.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAdjustment", arg0, arg1)
+	ret0, _ := ret[0].(db.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAdjustment indicates an expected call of GetAdjustment.
+func (mr *MockStoreMockRecorder) GetAdjustment(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAdjustment", reflect.TypeOf((*MockStore)(nil).GetAdjustment), arg0, arg1)
+}
+
+// GetAdjustmentForUpdate mocks base method.
+func (m *MockStore) GetAdjustmentForUpdate(arg0 context.Context, arg1 int64) (db.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAdjustmentForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAdjustmentForUpdate indicates an expected call of GetAdjustmentForUpdate.
+func (mr *MockStoreMockRecorder) GetAdjustmentForUpdate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAdjustmentForUpdate", reflect.TypeOf((*MockStore)(nil).GetAdjustmentForUpdate), arg0, arg1)
+}
+
+// GetBorrowerLoanExposure mocks base method.
+func (m *MockStore) GetBorrowerLoanExposure(arg0 context.Context, arg1 db.GetBorrowerLoanExposureParams) (db.GetBorrowerLoanExposureRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBorrowerLoanExposure", arg0, arg1)
+	ret0, _ := ret[0].(db.GetBorrowerLoanExposureRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBorrowerLoanExposure indicates an expected call of GetBorrowerLoanExposure.
+func (mr *MockStoreMockRecorder) GetBorrowerLoanExposure(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBorrowerLoanExposure", reflect.TypeOf((*MockStore)(nil).GetBorrowerLoanExposure), arg0, arg1)
+}
+
+// GetCard mocks base method.
+func (m *MockStore) GetCard(arg0 context.Context, arg1 int64) (db.Card, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCard", arg0, arg1)
+	ret0, _ := ret[0].(db.Card)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCard indicates an expected call of GetCard.
+func (mr *MockStoreMockRecorder) GetCard(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCard", reflect.TypeOf((*MockStore)(nil).GetCard), arg0, arg1)
+}
+
+// GetCardAuthorizationByRRN mocks base method.
+func (m *MockStore) GetCardAuthorizationByRRN(arg0 context.Context, arg1 db.GetCardAuthorizationByRRNParams) (db.CardAuthorization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCardAuthorizationByRRN", arg0, arg1)
+	ret0, _ := ret[0].(db.CardAuthorization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCardAuthorizationByRRN indicates an expected call of GetCardAuthorizationByRRN.
+func (mr *MockStoreMockRecorder) GetCardAuthorizationByRRN(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCardAuthorizationByRRN", reflect.TypeOf((*MockStore)(nil).GetCardAuthorizationByRRN), arg0, arg1)
+}
+
+// GetCardAuthorizationForUpdate mocks base method.
+func (m *MockStore) GetCardAuthorizationForUpdate(arg0 context.Context, arg1 int64) (db.CardAuthorization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCardAuthorizationForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.CardAuthorization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCardAuthorizationForUpdate indicates an expected call of GetCardAuthorizationForUpdate.
+func (mr *MockStoreMockRecorder) GetCardAuthorizationForUpdate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCardAuthorizationForUpdate", reflect.TypeOf((*MockStore)(nil).GetCardAuthorizationForUpdate), arg0, arg1)
+}
+
+// GetCardByPAN mocks base method.
+func (m *MockStore) GetCardByPAN(arg0 context.Context, arg1 string) (db.Card, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCardByPAN", arg0, arg1)
+	ret0, _ := ret[0].(db.Card)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCardByPAN indicates an expected call of GetCardByPAN.
+func (mr *MockStoreMockRecorder) GetCardByPAN(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCardByPAN", reflect.TypeOf((*MockStore)(nil).GetCardByPAN), arg0, arg1)
+}
+
+// GetCardSettlementAccount mocks base method.
+func (m *MockStore) GetCardSettlementAccount(arg0 context.Context, arg1 string) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCardSettlementAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCardSettlementAccount indicates an expected call of GetCardSettlementAccount.
+func (mr *MockStoreMockRecorder) GetCardSettlementAccount(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCardSettlementAccount", reflect.TypeOf((*MockStore)(nil).GetCardSettlementAccount), arg0, arg1)
+}
+
+// GetCardSpend mocks base method.
+func (m *MockStore) GetCardSpend(arg0 context.Context, arg1 db.GetCardSpendParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCardSpend", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCardSpend indicates an expected call of GetCardSpend.
+func (mr *MockStoreMockRecorder) GetCardSpend(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCardSpend", reflect.TypeOf((*MockStore)(nil).GetCardSpend), arg0, arg1)
+}
+
+// GetEndOfDayBalance mocks base method.
+func (m *MockStore) GetEndOfDayBalance(arg0 context.Context, arg1 db.GetEndOfDayBalanceParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEndOfDayBalance", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEndOfDayBalance indicates an expected call of GetEndOfDayBalance.
+func (mr *MockStoreMockRecorder) GetEndOfDayBalance(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEndOfDayBalance", reflect.TypeOf((*MockStore)(nil).GetEndOfDayBalance), arg0, arg1)
+}
+
+// GetEntry mocks base method.
+func (m *MockStore) GetEntry(arg0 context.Context, arg1 int64) (db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntry", arg0, arg1)
+	ret0, _ := ret[0].(db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntry indicates an expected call of GetEntry.
+func (mr *MockStoreMockRecorder) GetEntry(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntry", reflect.TypeOf((*MockStore)(nil).GetEntry), arg0, arg1)
+}
+
+// GetFraudDecision mocks base method.
+func (m *MockStore) GetFraudDecision(arg0 context.Context, arg1 int64) (db.FraudDecision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFraudDecision", arg0, arg1)
+	ret0, _ := ret[0].(db.FraudDecision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFraudDecision indicates an expected call of GetFraudDecision.
+func (mr *MockStoreMockRecorder) GetFraudDecision(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFraudDecision", reflect.TypeOf((*MockStore)(nil).GetFraudDecision), arg0, arg1)
+}
+
+// GetFraudDecisionForUpdate mocks base method.
+func (m *MockStore) GetFraudDecisionForUpdate(arg0 context.Context, arg1 int64) (db.FraudDecision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFraudDecisionForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.FraudDecision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFraudDecisionForUpdate indicates an expected call of GetFraudDecisionForUpdate.
+func (mr *MockStoreMockRecorder) GetFraudDecisionForUpdate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFraudDecisionForUpdate", reflect.TypeOf((*MockStore)(nil).GetFraudDecisionForUpdate), arg0, arg1)
+}
+
+// GetInterestExpenseAccount mocks base method.
+func (m *MockStore) GetInterestExpenseAccount(arg0 context.Context, arg1 string) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInterestExpenseAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInterestExpenseAccount indicates an expected call of GetInterestExpenseAccount.
+func (mr *MockStoreMockRecorder) GetInterestExpenseAccount(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInterestExpenseAccount", reflect.TypeOf((*MockStore)(nil).GetInterestExpenseAccount), arg0, arg1)
+}
+
+// GetKYCDocument mocks base method.
+func (m *MockStore) GetKYCDocument(arg0 context.Context, arg1 int64) (db.KYCDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKYCDocument", arg0, arg1)
+	ret0, _ := ret[0].(db.KYCDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKYCDocument indicates an expected call of GetKYCDocument.
+func (mr *MockStoreMockRecorder) GetKYCDocument(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKYCDocument", reflect.TypeOf((*MockStore)(nil).GetKYCDocument), arg0, arg1)
+}
+
+// GetKYCProfile mocks base method.
+func (m *MockStore) GetKYCProfile(arg0 context.Context, arg1 string) (db.KYCProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKYCProfile", arg0, arg1)
+	ret0, _ := ret[0].(db.KYCProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKYCProfile indicates an expected call of GetKYCProfile.
+func (mr *MockStoreMockRecorder) GetKYCProfile(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKYCProfile", reflect.TypeOf((*MockStore)(nil).GetKYCProfile), arg0, arg1)
+}
+
+// GetLastInterestAccrual mocks base method.
+func (m *MockStore) GetLastInterestAccrual(arg0 context.Context, arg1 int64) (db.InterestAccrual, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastInterestAccrual", arg0, arg1)
+	ret0, _ := ret[0].(db.InterestAccrual)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastInterestAccrual indicates an expected call of GetLastInterestAccrual.
+func (mr *MockStoreMockRecorder) GetLastInterestAccrual(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastInterestAccrual", reflect.TypeOf((*MockStore)(nil).GetLastInterestAccrual), arg0, arg1)
+}
+
+// GetLastInterestPayout mocks base method.
+func (m *MockStore) GetLastInterestPayout(arg0 context.Context, arg1 int64) (db.InterestPayout, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastInterestPayout", arg0, arg1)
+	ret0, _ := ret[0].(db.InterestPayout)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastInterestPayout indicates an expected call of GetLastInterestPayout.
+func (mr *MockStoreMockRecorder) GetLastInterestPayout(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastInterestPayout", reflect.TypeOf((*MockStore)(nil).GetLastInterestPayout), arg0, arg1)
+}
+
+// GetLatestEmailChangeForUpdate mocks base method.
+func (m *MockStore) GetLatestEmailChangeForUpdate(arg0 context.Context, arg1 string) (db.EmailChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestEmailChangeForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.EmailChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestEmailChangeForUpdate indicates an expected call of GetLatestEmailChangeForUpdate.
+func (mr *MockStoreMockRecorder) GetLatestEmailChangeForUpdate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestEmailChangeForUpdate", reflect.TypeOf((*MockStore)(nil).GetLatestEmailChangeForUpdate), arg0, arg1)
+}
+
+// GetLedgerTotals mocks base method.
+func (m *MockStore) GetLedgerTotals(arg0 context.Context) (db.GetLedgerTotalsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLedgerTotals", arg0)
+	ret0, _ := ret[0].(db.GetLedgerTotalsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLedgerTotals indicates an expected call of GetLedgerTotals.
+func (mr *MockStoreMockRecorder) GetLedgerTotals(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLedgerTotals", reflect.TypeOf((*MockStore)(nil).GetLedgerTotals), arg0)
+}
+
+// GetLoan mocks base method.
+func (m *MockStore) GetLoan(arg0 context.Context, arg1 int64) (db.Loan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoan", arg0, arg1)
+	ret0, _ := ret[0].(db.Loan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoan indicates an expected call of GetLoan.
+func (mr *MockStoreMockRecorder) GetLoan(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoan", reflect.TypeOf((*MockStore)(nil).GetLoan), arg0, arg1)
+}
+
+// GetLoanArrears mocks base method.
+func (m *MockStore) GetLoanArrears(arg0 context.Context, arg1 db.GetLoanArrearsParams) (db.GetLoanArrearsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoanArrears", arg0, arg1)
+	ret0, _ := ret[0].(db.GetLoanArrearsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoanArrears indicates an expected call of GetLoanArrears.
+func (mr *MockStoreMockRecorder) GetLoanArrears(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoanArrears", reflect.TypeOf((*MockStore)(nil).GetLoanArrears), arg0, arg1)
+}
+
+// GetLoanForUpdate mocks base method.
+func (m *MockStore) GetLoanForUpdate(arg0 context.Context, arg1 int64) (db.Loan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoanForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Loan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoanForUpdate indicates an expected call of GetLoanForUpdate.
+func (mr *MockStoreMockRecorder) GetLoanForUpdate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoanForUpdate", reflect.TypeOf((*MockStore)(nil).GetLoanForUpdate), arg0, arg1)
+}
+
+// GetLoanInstallment mocks base method.
+func (m *MockStore) GetLoanInstallment(arg0 context.Context, arg1 int64) (db.LoanInstallment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoanInstallment", arg0, arg1)
+	ret0, _ := ret[0].(db.LoanInstallment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoanInstallment indicates an expected call of GetLoanInstallment.
+func (mr *MockStoreMockRecorder) GetLoanInstallment(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoanInstallment", reflect.TypeOf((*MockStore)(nil).GetLoanInstallment), arg0, arg1)
+}
+
+// GetLoanInstallmentForUpdate mocks base method.
+func (m *MockStore) GetLoanInstallmentForUpdate(arg0 context.Context, arg1 int64) (db.LoanInstallment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoanInstallmentForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.LoanInstallment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoanInstallmentForUpdate indicates an expected call of GetLoanInstallmentForUpdate.
+func (mr *MockStoreMockRecorder) GetLoanInstallmentForUpdate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoanInstallmentForUpdate", reflect.TypeOf((*MockStore)(nil).GetLoanInstallmentForUpdate), arg0, arg1)
+}
+
+// GetLoanProduct mocks base method.
+func (m *MockStore) GetLoanProduct(arg0 context.Context, arg1 int64) (db.LoanProduct, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoanProduct", arg0, arg1)
+	ret0, _ := ret[0].(db.LoanProduct)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoanProduct indicates an expected call of GetLoanProduct.
+func (mr *MockStoreMockRecorder) GetLoanProduct(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoanProduct", reflect.TypeOf((*MockStore)(nil).GetLoanProduct), arg0, arg1)
+}
+
+// GetLoansAccount mocks base method.
+func (m *MockStore) GetLoansAccount(arg0 context.Context, arg1 string) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoansAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoansAccount indicates an expected call of GetLoansAccount.
+func (mr *MockStoreMockRecorder) GetLoansAccount(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoansAccount", reflect.TypeOf((*MockStore)(nil).GetLoansAccount), arg0, arg1)
+}
+
+// GetOAuthAuthorizationCodeForUpdate mocks base method.
+func (m *MockStore) GetOAuthAuthorizationCodeForUpdate(arg0 context.Context, arg1 string) (db.OAuthAuthorizationCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuthAuthorizationCodeForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.OAuthAuthorizationCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuthAuthorizationCodeForUpdate indicates an expected call of GetOAuthAuthorizationCodeForUpdate.
+func (mr *MockStoreMockRecorder) GetOAuthAuthorizationCodeForUpdate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuthAuthorizationCodeForUpdate", reflect.TypeOf((*MockStore)(nil).GetOAuthAuthorizationCodeForUpdate), arg0, arg1)
+}
+
+// GetOAuthClient mocks base method.
+func (m *MockStore) GetOAuthClient(arg0 context.Context, arg1 string) (db.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuthClient", arg0, arg1)
+	ret0, _ := ret[0].(db.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuthClient indicates an expected call of GetOAuthClient.
+func (mr *MockStoreMockRecorder) GetOAuthClient(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuthClient", reflect.TypeOf((*MockStore)(nil).GetOAuthClient), arg0, arg1)
+}
+
+// GetOAuthConsent mocks base method.
+func (m *MockStore) GetOAuthConsent(arg0 context.Context, arg1 int64) (db.OAuthConsent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuthConsent", arg0, arg1)
+	ret0, _ := ret[0].(db.OAuthConsent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuthConsent indicates an expected call of GetOAuthConsent.
+func (mr *MockStoreMockRecorder) GetOAuthConsent(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuthConsent", reflect.TypeOf((*MockStore)(nil).GetOAuthConsent), arg0, arg1)
+}
+
+// GetOAuthRefreshTokenForUpdate mocks base method.
+func (m *MockStore) GetOAuthRefreshTokenForUpdate(arg0 context.Context, arg1 string) (db.OAuthRefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuthRefreshTokenForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.OAuthRefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuthRefreshTokenForUpdate indicates an expected call of GetOAuthRefreshTokenForUpdate.
+func (mr *MockStoreMockRecorder) GetOAuthRefreshTokenForUpdate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuthRefreshTokenForUpdate", reflect.TypeOf((*MockStore)(nil).GetOAuthRefreshTokenForUpdate), arg0, arg1)
+}
+
+// GetOutgoingPaymentTotal mocks base method.
+func (m *MockStore) GetOutgoingPaymentTotal(arg0 context.Context, arg1 db.GetOutgoingPaymentTotalParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutgoingPaymentTotal", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutgoingPaymentTotal indicates an expected call of GetOutgoingPaymentTotal.
+func (mr *MockStoreMockRecorder) GetOutgoingPaymentTotal(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutgoingPaymentTotal", reflect.TypeOf((*MockStore)(nil).GetOutgoingPaymentTotal), arg0, arg1)
+}
+
+// GetPayment mocks base method.
+func (m *MockStore) GetPayment(arg0 context.Context, arg1 int64) (db.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPayment", arg0, arg1)
+	ret0, _ := ret[0].(db.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPayment indicates an expected call of GetPayment.
+func (mr *MockStoreMockRecorder) GetPayment(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPayment", reflect.TypeOf((*MockStore)(nil).GetPayment), arg0, arg1)
+}
+
+// GetPaymentHistory mocks base method.
+func (m *MockStore) GetPaymentHistory(arg0 context.Context, arg1 db.GetPaymentHistoryParams) (db.GetPaymentHistoryRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentHistory", arg0, arg1)
+	ret0, _ := ret[0].(db.GetPaymentHistoryRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentHistory indicates an expected call of GetPaymentHistory.
+func (mr *MockStoreMockRecorder) GetPaymentHistory(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentHistory", reflect.TypeOf((*MockStore)(nil).GetPaymentHistory), arg0, arg1)
+}
+
+// GetPocket mocks base method.
+func (m *MockStore) GetPocket(arg0 context.Context, arg1 int64) (db.Pocket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPocket", arg0, arg1)
+	ret0, _ := ret[0].(db.Pocket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPocket indicates an expected call of GetPocket.
+func (mr *MockStoreMockRecorder) GetPocket(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPocket", reflect.TypeOf((*MockStore)(nil).GetPocket), arg0, arg1)
+}
+
+// GetRoundUpPocket mocks base method.
+func (m *MockStore) GetRoundUpPocket(arg0 context.Context, arg1 int64) (db.Pocket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoundUpPocket", arg0, arg1)
+	ret0, _ := ret[0].(db.Pocket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoundUpPocket indicates an expected call of GetRoundUpPocket.
+func (mr *MockStoreMockRecorder) GetRoundUpPocket(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoundUpPocket", reflect.TypeOf((*MockStore)(nil).GetRoundUpPocket), arg0, arg1)
+}
+
+// GetSavingsAccount mocks base method.
+func (m *MockStore) GetSavingsAccount(arg0 context.Context, arg1 int64) (db.SavingsAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSavingsAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.SavingsAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSavingsAccount indicates an expected call of GetSavingsAccount.
+func (mr *MockStoreMockRecorder) GetSavingsAccount(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSavingsAccount", reflect.TypeOf((*MockStore)(nil).GetSavingsAccount), arg0, arg1)
+}
+
+// GetSavingsProduct mocks base method.
+func (m *MockStore) GetSavingsProduct(arg0 context.Context, arg1 int64) (db.SavingsProduct, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSavingsProduct", arg0, arg1)
+	ret0, _ := ret[0].(db.SavingsProduct)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSavingsProduct indicates an expected call of GetSavingsProduct.
+func (mr *MockStoreMockRecorder) GetSavingsProduct(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSavingsProduct", reflect.TypeOf((*MockStore)(nil).GetSavingsProduct), arg0, arg1)
+}
+
+// GetScreeningHit mocks base method.
+func (m *MockStore) GetScreeningHit(arg0 context.Context, arg1 int64) (db.ScreeningHit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScreeningHit", arg0, arg1)
+	ret0, _ := ret[0].(db.ScreeningHit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScreeningHit indicates an expected call of GetScreeningHit.
+func (mr *MockStoreMockRecorder) GetScreeningHit(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScreeningHit", reflect.TypeOf((*MockStore)(nil).GetScreeningHit), arg0, arg1)
+}
+
+// GetScreeningHitForUpdate mocks base method.
+func (m *MockStore) GetScreeningHitForUpdate(arg0 context.Context, arg1 int64) (db.ScreeningHit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScreeningHitForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.ScreeningHit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScreeningHitForUpdate indicates an expected call of GetScreeningHitForUpdate.
+func (mr *MockStoreMockRecorder) GetScreeningHitForUpdate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScreeningHitForUpdate", reflect.TypeOf((*MockStore)(nil).GetScreeningHitForUpdate), arg0, arg1)
+}
+
+// GetSuspenseAccount mocks base method.
+func (m *MockStore) GetSuspenseAccount(arg0 context.Context, arg1 string) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSuspenseAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSuspenseAccount indicates an expected call of GetSuspenseAccount.
+func (mr *MockStoreMockRecorder) GetSuspenseAccount(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSuspenseAccount", reflect.TypeOf((*MockStore)(nil).GetSuspenseAccount), arg0, arg1)
+}
+
+// GetUnpaidInterest mocks base method.
+func (m *MockStore) GetUnpaidInterest(arg0 context.Context, arg1 db.GetUnpaidInterestParams) (db.GetUnpaidInterestRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnpaidInterest", arg0, arg1)
+	ret0, _ := ret[0].(db.GetUnpaidInterestRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnpaidInterest indicates an expected call of GetUnpaidInterest.
+func (mr *MockStoreMockRecorder) GetUnpaidInterest(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnpaidInterest", reflect.TypeOf((*MockStore)(nil).GetUnpaidInterest), arg0, arg1)
+}
+
+// GetUser mocks base method.
+func (m *MockStore) GetUser(arg0 context.Context, arg1 string) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockStoreMockRecorder) GetUser(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStore)(nil).GetUser), arg0, arg1)
+}
+
+// GetUserByEmail mocks base method.
+func (m *MockStore) GetUserByEmail(arg0 context.Context, arg1 string) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByEmail", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByEmail indicates an expected call of GetUserByEmail.
+func (mr *MockStoreMockRecorder) GetUserByEmail(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockStore)(nil).GetUserByEmail), arg0, arg1)
+}
+
+// GetUserForUpdate mocks base method.
+func (m *MockStore) GetUserForUpdate(arg0 context.Context, arg1 string) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserForUpdate indicates an expected call of GetUserForUpdate.
+func (mr *MockStoreMockRecorder) GetUserForUpdate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserForUpdate", reflect.TypeOf((*MockStore)(nil).GetUserForUpdate), arg0, arg1)
+}
+
+// InvalidateUserTokens mocks base method.
+func (m *MockStore) InvalidateUserTokens(arg0 context.Context, arg1 db.InvalidateUserTokensParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateUserTokens", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateUserTokens indicates an expected call of InvalidateUserTokens.
+func (mr *MockStoreMockRecorder) InvalidateUserTokens(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateUserTokens", reflect.TypeOf((*MockStore)(nil).InvalidateUserTokens), arg0, arg1)
+}
+
+// InviteAccountMemberTx mocks base method.
+func (m *MockStore) InviteAccountMemberTx(arg0 context.Context, arg1 db.CreateAccountInvitationParams) (db.AccountInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InviteAccountMemberTx", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InviteAccountMemberTx indicates an expected call of InviteAccountMemberTx.
+func (mr *MockStoreMockRecorder) InviteAccountMemberTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InviteAccountMemberTx", reflect.TypeOf((*MockStore)(nil).InviteAccountMemberTx), arg0, arg1)
+}
+
+// IssueUserTokenTx mocks base method.
+func (m *MockStore) IssueUserTokenTx(arg0 context.Context, arg1 db.IssueUserTokenParams) (db.IssueUserTokenResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueUserTokenTx", arg0, arg1)
+	ret0, _ := ret[0].(db.IssueUserTokenResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueUserTokenTx indicates an expected call of IssueUserTokenTx.
+func (mr *MockStoreMockRecorder) IssueUserTokenTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueUserTokenTx", reflect.TypeOf((*MockStore)(nil).IssueUserTokenTx), arg0, arg1)
+}
+
+// ListAMLAlertComments mocks base method.
+func (m *MockStore) ListAMLAlertComments(arg0 context.Context, arg1 int64) ([]db.AMLAlertComment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAMLAlertComments", arg0, arg1)
+	ret0, _ := ret[0].([]db.AMLAlertComment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAMLAlertComments indicates an expected call of ListAMLAlertComments.
+func (mr *MockStoreMockRecorder) ListAMLAlertComments(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAMLAlertComments", reflect.TypeOf((*MockStore)(nil).ListAMLAlertComments), arg0, arg1)
+}
+
+// ListAMLAlerts mocks base method.
+func (m *MockStore) ListAMLAlerts(arg0 context.Context, arg1 db.ListAMLAlertsParams) ([]db.AMLAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAMLAlerts", arg0, arg1)
+	ret0, _ := ret[0].([]db.AMLAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAMLAlerts indicates an expected call of ListAMLAlerts.
+func (mr *MockStoreMockRecorder) ListAMLAlerts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAMLAlerts", reflect.TypeOf((*MockStore)(nil).ListAMLAlerts), arg0, arg1)
+}
+
+// ListAMLJobRuns mocks base method.
+func (m *MockStore) ListAMLJobRuns(arg0 context.Context, arg1 db.ListAMLJobRunsParams) ([]db.AMLJobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAMLJobRuns", arg0, arg1)
+	ret0, _ := ret[0].([]db.AMLJobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAMLJobRuns indicates an expected call of ListAMLJobRuns.
+func (mr *MockStoreMockRecorder) ListAMLJobRuns(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAMLJobRuns", reflect.TypeOf((*MockStore)(nil).ListAMLJobRuns), arg0, arg1)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockStore) ListAPIKeys(arg0 context.Context, arg1 string) ([]db.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", arg0, arg1)
+	ret0, _ := ret[0].([]db.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockStoreMockRecorder) ListAPIKeys(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockStore)(nil).ListAPIKeys), arg0, arg1)
+}
+
+// ListAccountInvitations mocks base method.
+func (m *MockStore) ListAccountInvitations(arg0 context.Context, arg1 int64) ([]db.AccountInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountInvitations", arg0, arg1)
+	ret0, _ := ret[0].([]db.AccountInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountInvitations indicates an expected call of ListAccountInvitations.
+func (mr *MockStoreMockRecorder) ListAccountInvitations(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountInvitations", reflect.TypeOf((*MockStore)(nil).ListAccountInvitations), arg0, arg1)
+}
+
+// ListAccountMembers mocks base method.
+func (m *MockStore) ListAccountMembers(arg0 context.Context, arg1 int64) ([]db.AccountMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountMembers", arg0, arg1)
+	ret0, _ := ret[0].([]db.AccountMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountMembers indicates an expected call of ListAccountMembers.
+func (mr *MockStoreMockRecorder) ListAccountMembers(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountMembers", reflect.TypeOf((*MockStore)(nil).ListAccountMembers), arg0, arg1)
+}
+
+// ListAccounts mocks base method.
+func (m *MockStore) ListAccounts(arg0 context.Context, arg1 db.ListAccountsParams) ([]db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccounts", arg0, arg1)
+	ret0, _ := ret[0].([]db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccounts indicates an expected call of ListAccounts.
+func (mr *MockStoreMockRecorder) ListAccounts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockStore)(nil).ListAccounts), arg0, arg1)
+}
+
+// ListAdjustments mocks base method.
+func (m *MockStore) ListAdjustments(arg0 context.Context, arg1 db.ListAdjustmentsParams) ([]db.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAdjustments", arg0, arg1)
+	ret0, _ := ret[0].([]db.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAdjustments indicates an expected call of ListAdjustments.
+func (mr *MockStoreMockRecorder) ListAdjustments(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAdjustments", reflect.TypeOf((*MockStore)(nil).ListAdjustments), arg0, arg1)
+}
+
+// ListAuditEvents mocks base method.
+func (m *MockStore) ListAuditEvents(arg0 context.Context, arg1 db.ListAuditEventsParams) ([]db.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditEvents", arg0, arg1)
+	ret0, _ := ret[0].([]db.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditEvents indicates an expected call of ListAuditEvents.
+func (mr *MockStoreMockRecorder) ListAuditEvents(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditEvents", reflect.TypeOf((*MockStore)(nil).ListAuditEvents), arg0, arg1)
+}
+
+// ListCardAuthorizations mocks base method.
+func (m *MockStore) ListCardAuthorizations(arg0 context.Context, arg1 db.ListCardAuthorizationsParams) ([]db.CardAuthorization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCardAuthorizations", arg0, arg1)
+	ret0, _ := ret[0].([]db.CardAuthorization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCardAuthorizations indicates an expected call of ListCardAuthorizations.
+func (mr *MockStoreMockRecorder) ListCardAuthorizations(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCardAuthorizations", reflect.TypeOf((*MockStore)(nil).ListCardAuthorizations), arg0, arg1)
+}
+
+// ListCards mocks base method.
+func (m *MockStore) ListCards(arg0 context.Context, arg1 string) ([]db.Card, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCards", arg0, arg1)
+	ret0, _ := ret[0].([]db.Card)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCards indicates an expected call of ListCards.
+func (mr *MockStoreMockRecorder) ListCards(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCards", reflect.TypeOf((*MockStore)(nil).ListCards), arg0, arg1)
+}
+
+// ListDueLoanInstallments mocks base method.
+func (m *MockStore) ListDueLoanInstallments(arg0 context.Context, arg1 db.ListDueLoanInstallmentsParams) ([]db.LoanInstallment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDueLoanInstallments", arg0, arg1)
+	ret0, _ := ret[0].([]db.LoanInstallment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDueLoanInstallments indicates an expected call of ListDueLoanInstallments.
+func (mr *MockStoreMockRecorder) ListDueLoanInstallments(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDueLoanInstallments", reflect.TypeOf((*MockStore)(nil).ListDueLoanInstallments), arg0, arg1)
+}
+
+// ListEntries mocks base method.
+func (m *MockStore) ListEntries(arg0 context.Context, arg1 db.ListEntriesParams) ([]db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEntries", arg0, arg1)
+	ret0, _ := ret[0].([]db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEntries indicates an expected call of ListEntries.
+func (mr *MockStoreMockRecorder) ListEntries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntries", reflect.TypeOf((*MockStore)(nil).ListEntries), arg0, arg1)
+}
+
+// ListEntriesByID mocks base method.
+func (m *MockStore) ListEntriesByID(arg0 context.Context, arg1 []int64) ([]db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEntriesByID", arg0, arg1)
+	ret0, _ := ret[0].([]db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEntriesByID indicates an expected call of ListEntriesByID.
+func (mr *MockStoreMockRecorder) ListEntriesByID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntriesByID", reflect.TypeOf((*MockStore)(nil).ListEntriesByID), arg0, arg1)
+}
+
+// ListFraudDecisions mocks base method.
+func (m *MockStore) ListFraudDecisions(arg0 context.Context, arg1 db.ListFraudDecisionsParams) ([]db.FraudDecision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFraudDecisions", arg0, arg1)
+	ret0, _ := ret[0].([]db.FraudDecision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFraudDecisions indicates an expected call of ListFraudDecisions.
+func (mr *MockStoreMockRecorder) ListFraudDecisions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFraudDecisions", reflect.TypeOf((*MockStore)(nil).ListFraudDecisions), arg0, arg1)
+}
+
+// ListInterestAccruals mocks base method.
+func (m *MockStore) ListInterestAccruals(arg0 context.Context, arg1 db.ListInterestAccrualsParams) ([]db.InterestAccrual, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInterestAccruals", arg0, arg1)
+	ret0, _ := ret[0].([]db.InterestAccrual)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInterestAccruals indicates an expected call of ListInterestAccruals.
+func (mr *MockStoreMockRecorder) ListInterestAccruals(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInterestAccruals", reflect.TypeOf((*MockStore)(nil).ListInterestAccruals), arg0, arg1)
+}
+
+// ListInterestPayouts mocks base method.
+func (m *MockStore) ListInterestPayouts(arg0 context.Context, arg1 db.ListInterestPayoutsParams) ([]db.InterestPayout, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInterestPayouts", arg0, arg1)
+	ret0, _ := ret[0].([]db.InterestPayout)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInterestPayouts indicates an expected call of ListInterestPayouts.
+func (mr *MockStoreMockRecorder) ListInterestPayouts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInterestPayouts", reflect.TypeOf((*MockStore)(nil).ListInterestPayouts), arg0, arg1)
+}
+
+// ListKYCDocuments mocks base method.
+func (m *MockStore) ListKYCDocuments(arg0 context.Context, arg1 string) ([]db.KYCDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListKYCDocuments", arg0, arg1)
+	ret0, _ := ret[0].([]db.KYCDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListKYCDocuments indicates an expected call of ListKYCDocuments.
+func (mr *MockStoreMockRecorder) ListKYCDocuments(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListKYCDocuments", reflect.TypeOf((*MockStore)(nil).ListKYCDocuments), arg0, arg1)
+}
+
+// ListKYCReviewQueue mocks base method.
+func (m *MockStore) ListKYCReviewQueue(arg0 context.Context, arg1 db.ListKYCReviewQueueParams) ([]db.KYCProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListKYCReviewQueue", arg0, arg1)
+	ret0, _ := ret[0].([]db.KYCProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListKYCReviewQueue indicates an expected call of ListKYCReviewQueue.
+func (mr *MockStoreMockRecorder) ListKYCReviewQueue(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListKYCReviewQueue", reflect.TypeOf((*MockStore)(nil).ListKYCReviewQueue), arg0, arg1)
+}
+
+// ListLedgerMismatches mocks base method.
+func (m *MockStore) ListLedgerMismatches(arg0 context.Context) ([]db.ListLedgerMismatchesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLedgerMismatches", arg0)
+	ret0, _ := ret[0].([]db.ListLedgerMismatchesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLedgerMismatches indicates an expected call of ListLedgerMismatches.
+func (mr *MockStoreMockRecorder) ListLedgerMismatches(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLedgerMismatches", reflect.TypeOf((*MockStore)(nil).ListLedgerMismatches), arg0)
+}
+
+// ListLoanInstallments mocks base method.
+func (m *MockStore) ListLoanInstallments(arg0 context.Context, arg1 int64) ([]db.LoanInstallment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLoanInstallments", arg0, arg1)
+	ret0, _ := ret[0].([]db.LoanInstallment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLoanInstallments indicates an expected call of ListLoanInstallments.
+func (mr *MockStoreMockRecorder) ListLoanInstallments(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLoanInstallments", reflect.TypeOf((*MockStore)(nil).ListLoanInstallments), arg0, arg1)
+}
+
+// ListLoanProducts mocks base method.
+func (m *MockStore) ListLoanProducts(arg0 context.Context) ([]db.LoanProduct, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLoanProducts", arg0)
+	ret0, _ := ret[0].([]db.LoanProduct)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLoanProducts indicates an expected call of ListLoanProducts.
+func (mr *MockStoreMockRecorder) ListLoanProducts(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLoanProducts", reflect.TypeOf((*MockStore)(nil).ListLoanProducts), arg0)
+}
+
+// ListLoanRepayments mocks base method.
+func (m *MockStore) ListLoanRepayments(arg0 context.Context, arg1 int64) ([]db.LoanRepayment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLoanRepayments", arg0, arg1)
+	ret0, _ := ret[0].([]db.LoanRepayment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLoanRepayments indicates an expected call of ListLoanRepayments.
+func (mr *MockStoreMockRecorder) ListLoanRepayments(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLoanRepayments", reflect.TypeOf((*MockStore)(nil).ListLoanRepayments), arg0, arg1)
+}
+
+// ListLoans mocks base method.
+func (m *MockStore) ListLoans(arg0 context.Context, arg1 db.ListLoansParams) ([]db.Loan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLoans", arg0, arg1)
+	ret0, _ := ret[0].([]db.Loan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLoans indicates an expected call of ListLoans.
+func (mr *MockStoreMockRecorder) ListLoans(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLoans", reflect.TypeOf((*MockStore)(nil).ListLoans), arg0, arg1)
+}
+
+// ListNotifications mocks base method.
+func (m *MockStore) ListNotifications(arg0 context.Context, arg1 db.ListNotificationsParams) ([]db.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNotifications", arg0, arg1)
+	ret0, _ := ret[0].([]db.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNotifications indicates an expected call of ListNotifications.
+func (mr *MockStoreMockRecorder) ListNotifications(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNotifications", reflect.TypeOf((*MockStore)(nil).ListNotifications), arg0, arg1)
+}
+
+// ListOAuthClients mocks base method.
+func (m *MockStore) ListOAuthClients(arg0 context.Context) ([]db.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOAuthClients", arg0)
+	ret0, _ := ret[0].([]db.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOAuthClients indicates an expected call of ListOAuthClients.
+func (mr *MockStoreMockRecorder) ListOAuthClients(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOAuthClients", reflect.TypeOf((*MockStore)(nil).ListOAuthClients), arg0)
+}
+
+// ListOAuthConsents mocks base method.
+func (m *MockStore) ListOAuthConsents(arg0 context.Context, arg1 string) ([]db.ListOAuthConsentsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOAuthConsents", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListOAuthConsentsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOAuthConsents indicates an expected call of ListOAuthConsents.
+func (mr *MockStoreMockRecorder) ListOAuthConsents(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOAuthConsents", reflect.TypeOf((*MockStore)(nil).ListOAuthConsents), arg0, arg1)
+}
+
+// ListPayments mocks base method.
+func (m *MockStore) ListPayments(arg0 context.Context, arg1 db.ListPaymentsParams) ([]db.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPayments", arg0, arg1)
+	ret0, _ := ret[0].([]db.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPayments indicates an expected call of ListPayments.
+func (mr *MockStoreMockRecorder) ListPayments(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPayments", reflect.TypeOf((*MockStore)(nil).ListPayments), arg0, arg1)
+}
+
+// ListPaymentsByID mocks base method.
+func (m *MockStore) ListPaymentsByID(arg0 context.Context, arg1 []int64) ([]db.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPaymentsByID", arg0, arg1)
+	ret0, _ := ret[0].([]db.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPaymentsByID indicates an expected call of ListPaymentsByID.
+func (mr *MockStoreMockRecorder) ListPaymentsByID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPaymentsByID", reflect.TypeOf((*MockStore)(nil).ListPaymentsByID), arg0, arg1)
+}
+
+// ListPocketMoves mocks base method.
+func (m *MockStore) ListPocketMoves(arg0 context.Context, arg1 db.ListPocketMovesParams) ([]db.PocketMove, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPocketMoves", arg0, arg1)
+	ret0, _ := ret[0].([]db.PocketMove)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPocketMoves indicates an expected call of ListPocketMoves.
+func (mr *MockStoreMockRecorder) ListPocketMoves(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPocketMoves", reflect.TypeOf((*MockStore)(nil).ListPocketMoves), arg0, arg1)
+}
+
+// ListPockets mocks base method.
+func (m *MockStore) ListPockets(arg0 context.Context, arg1 int64) ([]db.Pocket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPockets", arg0, arg1)
+	ret0, _ := ret[0].([]db.Pocket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPockets indicates an expected call of ListPockets.
+func (mr *MockStoreMockRecorder) ListPockets(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPockets", reflect.TypeOf((*MockStore)(nil).ListPockets), arg0, arg1)
+}
+
+// ListSavingsAccounts mocks base method.
+func (m *MockStore) ListSavingsAccounts(arg0 context.Context, arg1 db.ListSavingsAccountsParams) ([]db.ListSavingsAccountsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSavingsAccounts", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListSavingsAccountsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSavingsAccounts indicates an expected call of ListSavingsAccounts.
+func (mr *MockStoreMockRecorder) ListSavingsAccounts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSavingsAccounts", reflect.TypeOf((*MockStore)(nil).ListSavingsAccounts), arg0, arg1)
+}
+
+// ListSavingsProducts mocks base method.
+func (m *MockStore) ListSavingsProducts(arg0 context.Context) ([]db.SavingsProduct, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSavingsProducts", arg0)
+	ret0, _ := ret[0].([]db.SavingsProduct)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSavingsProducts indicates an expected call of ListSavingsProducts.
+func (mr *MockStoreMockRecorder) ListSavingsProducts(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSavingsProducts", reflect.TypeOf((*MockStore)(nil).ListSavingsProducts), arg0)
+}
+
+// ListScreeningHits mocks base method.
+func (m *MockStore) ListScreeningHits(arg0 context.Context, arg1 db.ListScreeningHitsParams) ([]db.ScreeningHit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScreeningHits", arg0, arg1)
+	ret0, _ := ret[0].([]db.ScreeningHit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScreeningHits indicates an expected call of ListScreeningHits.
+func (mr *MockStoreMockRecorder) ListScreeningHits(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScreeningHits", reflect.TypeOf((*MockStore)(nil).ListScreeningHits), arg0, arg1)
+}
+
+// ListUserInvitations mocks base method.
+func (m *MockStore) ListUserInvitations(arg0 context.Context, arg1 db.ListUserInvitationsParams) ([]db.AccountInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserInvitations", arg0, arg1)
+	ret0, _ := ret[0].([]db.AccountInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserInvitations indicates an expected call of ListUserInvitations.
+func (mr *MockStoreMockRecorder) ListUserInvitations(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserInvitations", reflect.TypeOf((*MockStore)(nil).ListUserInvitations), arg0, arg1)
+}
+
+// LockUser mocks base method.
+func (m *MockStore) LockUser(arg0 context.Context, arg1 db.LockUserParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockUser indicates an expected call of LockUser.
+func (mr *MockStoreMockRecorder) LockUser(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockUser", reflect.TypeOf((*MockStore)(nil).LockUser), arg0, arg1)
+}
+
+// LoginFailureTx mocks base method.
+func (m *MockStore) LoginFailureTx(arg0 context.Context, arg1 db.LoginFailureTxParams) (db.LoginFailureTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginFailureTx", arg0, arg1)
+	ret0, _ := ret[0].(db.LoginFailureTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoginFailureTx indicates an expected call of LoginFailureTx.
+func (mr *MockStoreMockRecorder) LoginFailureTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginFailureTx", reflect.TypeOf((*MockStore)(nil).LoginFailureTx), arg0, arg1)
+}
+
+// MarkAdjustmentApproved mocks base method.
+func (m *MockStore) MarkAdjustmentApproved(arg0 context.Context, arg1 db.MarkAdjustmentApprovedParams) (db.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAdjustmentApproved", arg0, arg1)
+	ret0, _ := ret[0].(db.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkAdjustmentApproved indicates an expected call of MarkAdjustmentApproved.
+func (mr *MockStoreMockRecorder) MarkAdjustmentApproved(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAdjustmentApproved", reflect.TypeOf((*MockStore)(nil).MarkAdjustmentApproved), arg0, arg1)
+}
+
+// MarkAdjustmentExpired mocks base method.
+func (m *MockStore) MarkAdjustmentExpired(arg0 context.Context, arg1 int64) (db.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAdjustmentExpired", arg0, arg1)
+	ret0, _ := ret[0].(db.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkAdjustmentExpired indicates an expected call of MarkAdjustmentExpired.
+func (mr *MockStoreMockRecorder) MarkAdjustmentExpired(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAdjustmentExpired", reflect.TypeOf((*MockStore)(nil).MarkAdjustmentExpired), arg0, arg1)
+}
+
+// MarkAdjustmentRejected mocks base method.
+func (m *MockStore) MarkAdjustmentRejected(arg0 context.Context, arg1 db.MarkAdjustmentRejectedParams) (db.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAdjustmentRejected", arg0, arg1)
+	ret0, _ := ret[0].(db.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkAdjustmentRejected indicates an expected call of MarkAdjustmentRejected.
+func (mr *MockStoreMockRecorder) MarkAdjustmentRejected(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAdjustmentRejected", reflect.TypeOf((*MockStore)(nil).MarkAdjustmentRejected), arg0, arg1)
+}
+
+// MarkEmailVerified mocks base method.
+func (m *MockStore) MarkEmailVerified(arg0 context.Context, arg1 db.MarkEmailVerifiedParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkEmailVerified", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkEmailVerified indicates an expected call of MarkEmailVerified.
+func (mr *MockStoreMockRecorder) MarkEmailVerified(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkEmailVerified", reflect.TypeOf((*MockStore)(nil).MarkEmailVerified), arg0, arg1)
+}
+
+// MarkInterestAccrualsPaid mocks base method.
+func (m *MockStore) MarkInterestAccrualsPaid(arg0 context.Context, arg1 db.MarkInterestAccrualsPaidParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkInterestAccrualsPaid", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkInterestAccrualsPaid indicates an expected call of MarkInterestAccrualsPaid.
+func (mr *MockStoreMockRecorder) MarkInterestAccrualsPaid(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkInterestAccrualsPaid", reflect.TypeOf((*MockStore)(nil).MarkInterestAccrualsPaid), arg0, arg1)
+}
+
+// MovePocketTx mocks base method.
+func (m *MockStore) MovePocketTx(arg0 context.Context, arg1 db.MovePocketTxParams) (db.MovePocketTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MovePocketTx", arg0, arg1)
+	ret0, _ := ret[0].(db.MovePocketTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MovePocketTx indicates an expected call of MovePocketTx.
+func (mr *MockStoreMockRecorder) MovePocketTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MovePocketTx", reflect.TypeOf((*MockStore)(nil).MovePocketTx), arg0, arg1)
+}
+
+// OriginateLoanTx mocks base method.
+func (m *MockStore) OriginateLoanTx(arg0 context.Context, arg1 db.OriginateLoanTxParams) (db.OriginateLoanTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OriginateLoanTx", arg0, arg1)
+	ret0, _ := ret[0].(db.OriginateLoanTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OriginateLoanTx indicates an expected call of OriginateLoanTx.
+func (mr *MockStoreMockRecorder) OriginateLoanTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OriginateLoanTx", reflect.TypeOf((*MockStore)(nil).OriginateLoanTx), arg0, arg1)
+}
+
+// PayInterestTx mocks base method.
+func (m *MockStore) PayInterestTx(arg0 context.Context, arg1 db.PayInterestTxParams) (db.PayInterestTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PayInterestTx", arg0, arg1)
+	ret0, _ := ret[0].(db.PayInterestTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PayInterestTx indicates an expected call of PayInterestTx.
+func (mr *MockStoreMockRecorder) PayInterestTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PayInterestTx", reflect.TypeOf((*MockStore)(nil).PayInterestTx), arg0, arg1)
+}
+
+// PaymentTx mocks base method.
+func (m *MockStore) PaymentTx(arg0 context.Context, arg1 db.PaymentTxParams) (db.PaymentTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PaymentTx", arg0, arg1)
+	ret0, _ := ret[0].(db.PaymentTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PaymentTx indicates an expected call of PaymentTx.
+func (mr *MockStoreMockRecorder) PaymentTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PaymentTx", reflect.TypeOf((*MockStore)(nil).PaymentTx), arg0, arg1)
+}
+
+// Ping mocks base method.
+func (m *MockStore) Ping(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockStoreMockRecorder) Ping(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockStore)(nil).Ping), arg0)
+}
+
+// ProposeAdjustment mocks base method.
+func (m *MockStore) ProposeAdjustment(arg0 context.Context, arg1 db.ProposeAdjustmentParams) (db.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProposeAdjustment", arg0, arg1)
+	ret0, _ := ret[0].(db.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProposeAdjustment indicates an expected call of ProposeAdjustment.
+func (mr *MockStoreMockRecorder) ProposeAdjustment(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProposeAdjustment", reflect.TypeOf((*MockStore)(nil).ProposeAdjustment), arg0, arg1)
+}
+
+// RecordAMLAlertsTx mocks base method.
+func (m *MockStore) RecordAMLAlertsTx(arg0 context.Context, arg1 []db.UpsertAMLAlertParams) ([]db.AMLAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordAMLAlertsTx", arg0, arg1)
+	ret0, _ := ret[0].([]db.AMLAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordAMLAlertsTx indicates an expected call of RecordAMLAlertsTx.
+func (mr *MockStoreMockRecorder) RecordAMLAlertsTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordAMLAlertsTx", reflect.TypeOf((*MockStore)(nil).RecordAMLAlertsTx), arg0, arg1)
+}
+
+// RecordFailedLogin mocks base method.
+func (m *MockStore) RecordFailedLogin(arg0 context.Context, arg1 string) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordFailedLogin", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordFailedLogin indicates an expected call of RecordFailedLogin.
+func (mr *MockStoreMockRecorder) RecordFailedLogin(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFailedLogin", reflect.TypeOf((*MockStore)(nil).RecordFailedLogin), arg0, arg1)
+}
+
+// RecordScreeningHitsTx mocks base method.
+func (m *MockStore) RecordScreeningHitsTx(arg0 context.Context, arg1 []db.UpsertScreeningHitParams) ([]db.ScreeningHit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordScreeningHitsTx", arg0, arg1)
+	ret0, _ := ret[0].([]db.ScreeningHit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordScreeningHitsTx indicates an expected call of RecordScreeningHitsTx.
+func (mr *MockStoreMockRecorder) RecordScreeningHitsTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordScreeningHitsTx", reflect.TypeOf((*MockStore)(nil).RecordScreeningHitsTx), arg0, arg1)
+}
+
+// ReduceLoanPrincipal mocks base method.
+func (m *MockStore) ReduceLoanPrincipal(arg0 context.Context, arg1 db.ReduceLoanPrincipalParams) (db.Loan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReduceLoanPrincipal", arg0, arg1)
+	ret0, _ := ret[0].(db.Loan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReduceLoanPrincipal indicates an expected call of ReduceLoanPrincipal.
+func (mr *MockStoreMockRecorder) ReduceLoanPrincipal(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReduceLoanPrincipal", reflect.TypeOf((*MockStore)(nil).ReduceLoanPrincipal), arg0, arg1)
+}
+
+// RefreshOAuthTokenTx mocks base method.
+func (m *MockStore) RefreshOAuthTokenTx(arg0 context.Context, arg1 db.RefreshOAuthTokenTxParams) (db.OAuthGrantTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshOAuthTokenTx", arg0, arg1)
+	ret0, _ := ret[0].(db.OAuthGrantTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshOAuthTokenTx indicates an expected call of RefreshOAuthTokenTx.
+func (mr *MockStoreMockRecorder) RefreshOAuthTokenTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshOAuthTokenTx", reflect.TypeOf((*MockStore)(nil).RefreshOAuthTokenTx), arg0, arg1)
+}
+
+// RejectAdjustmentTx mocks base method.
+func (m *MockStore) RejectAdjustmentTx(arg0 context.Context, arg1 db.ReviewAdjustmentTxParams) (db.Adjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectAdjustmentTx", arg0, arg1)
+	ret0, _ := ret[0].(db.Adjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RejectAdjustmentTx indicates an expected call of RejectAdjustmentTx.
+func (mr *MockStoreMockRecorder) RejectAdjustmentTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectAdjustmentTx", reflect.TypeOf((*MockStore)(nil).RejectAdjustmentTx), arg0, arg1)
+}
+
+// RepayLoanEarlyTx mocks base method.
+func (m *MockStore) RepayLoanEarlyTx(arg0 context.Context, arg1 db.RepayLoanEarlyTxParams) (db.RepayLoanEarlyTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RepayLoanEarlyTx", arg0, arg1)
+	ret0, _ := ret[0].(db.RepayLoanEarlyTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RepayLoanEarlyTx indicates an expected call of RepayLoanEarlyTx.
+func (mr *MockStoreMockRecorder) RepayLoanEarlyTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RepayLoanEarlyTx", reflect.TypeOf((*MockStore)(nil).RepayLoanEarlyTx), arg0, arg1)
+}
+
+// RequestEmailChangeTx mocks base method.
+func (m *MockStore) RequestEmailChangeTx(arg0 context.Context, arg1 db.RequestEmailChangeTxParams) (db.RequestEmailChangeTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestEmailChangeTx", arg0, arg1)
+	ret0, _ := ret[0].(db.RequestEmailChangeTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestEmailChangeTx indicates an expected call of RequestEmailChangeTx.
+func (mr *MockStoreMockRecorder) RequestEmailChangeTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestEmailChangeTx", reflect.TypeOf((*MockStore)(nil).RequestEmailChangeTx), arg0, arg1)
+}
+
+// ResetFailedLogins mocks base method.
+func (m *MockStore) ResetFailedLogins(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetFailedLogins", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetFailedLogins indicates an expected call of ResetFailedLogins.
+func (mr *MockStoreMockRecorder) ResetFailedLogins(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetFailedLogins", reflect.TypeOf((*MockStore)(nil).ResetFailedLogins), arg0, arg1)
+}
+
+// ResetPasswordTx mocks base method.
+func (m *MockStore) ResetPasswordTx(arg0 context.Context, arg1 db.ResetPasswordTxParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPasswordTx", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetPasswordTx indicates an expected call of ResetPasswordTx.
+func (mr *MockStoreMockRecorder) ResetPasswordTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPasswordTx", reflect.TypeOf((*MockStore)(nil).ResetPasswordTx), arg0, arg1)
+}
+
+// ResolveAMLAlert mocks base method.
+func (m *MockStore) ResolveAMLAlert(arg0 context.Context, arg1 db.ResolveAMLAlertParams) (db.AMLAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveAMLAlert", arg0, arg1)
+	ret0, _ := ret[0].(db.AMLAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveAMLAlert indicates an expected call of ResolveAMLAlert.
+func (mr *MockStoreMockRecorder) ResolveAMLAlert(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveAMLAlert", reflect.TypeOf((*MockStore)(nil).ResolveAMLAlert), arg0, arg1)
+}
+
+// RespondAccountInvitation mocks base method.
+func (m *MockStore) RespondAccountInvitation(arg0 context.Context, arg1 db.RespondAccountInvitationParams) (db.AccountInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RespondAccountInvitation", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RespondAccountInvitation indicates an expected call of RespondAccountInvitation.
+func (mr *MockStoreMockRecorder) RespondAccountInvitation(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RespondAccountInvitation", reflect.TypeOf((*MockStore)(nil).RespondAccountInvitation), arg0, arg1)
+}
+
+// ReverseCardAuthorization mocks base method.
+func (m *MockStore) ReverseCardAuthorization(arg0 context.Context, arg1 int64) (db.CardAuthorization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReverseCardAuthorization", arg0, arg1)
+	ret0, _ := ret[0].(db.CardAuthorization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReverseCardAuthorization indicates an expected call of ReverseCardAuthorization.
+func (mr *MockStoreMockRecorder) ReverseCardAuthorization(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReverseCardAuthorization", reflect.TypeOf((*MockStore)(nil).ReverseCardAuthorization), arg0, arg1)
+}
+
+// ReviewFraudDecision mocks base method.
+func (m *MockStore) ReviewFraudDecision(arg0 context.Context, arg1 db.ReviewFraudDecisionParams) (db.FraudDecision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewFraudDecision", arg0, arg1)
+	ret0, _ := ret[0].(db.FraudDecision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReviewFraudDecision indicates an expected call of ReviewFraudDecision.
+func (mr *MockStoreMockRecorder) ReviewFraudDecision(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewFraudDecision", reflect.TypeOf((*MockStore)(nil).ReviewFraudDecision), arg0, arg1)
+}
+
+// ReviewFraudDecisionTx mocks base method.
+func (m *MockStore) ReviewFraudDecisionTx(arg0 context.Context, arg1 db.ReviewFraudDecisionTxParams) (db.ReviewFraudDecisionTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewFraudDecisionTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ReviewFraudDecisionTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReviewFraudDecisionTx indicates an expected call of ReviewFraudDecisionTx.
+func (mr *MockStoreMockRecorder) ReviewFraudDecisionTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewFraudDecisionTx", reflect.TypeOf((*MockStore)(nil).ReviewFraudDecisionTx), arg0, arg1)
+}
+
+// ReviewKYCProfile mocks base method.
+func (m *MockStore) ReviewKYCProfile(arg0 context.Context, arg1 db.ReviewKYCProfileParams) (db.KYCProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewKYCProfile", arg0, arg1)
+	ret0, _ := ret[0].(db.KYCProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReviewKYCProfile indicates an expected call of ReviewKYCProfile.
+func (mr *MockStoreMockRecorder) ReviewKYCProfile(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewKYCProfile", reflect.TypeOf((*MockStore)(nil).ReviewKYCProfile), arg0, arg1)
+}
+
+// ReviewKYCTx mocks base method.
+func (m *MockStore) ReviewKYCTx(arg0 context.Context, arg1 db.ReviewKYCTxParams) (db.KYCTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewKYCTx", arg0, arg1)
+	ret0, _ := ret[0].(db.KYCTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReviewKYCTx indicates an expected call of ReviewKYCTx.
+func (mr *MockStoreMockRecorder) ReviewKYCTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewKYCTx", reflect.TypeOf((*MockStore)(nil).ReviewKYCTx), arg0, arg1)
+}
+
+// ReviewScreeningHit mocks base method.
+func (m *MockStore) ReviewScreeningHit(arg0 context.Context, arg1 db.ReviewScreeningHitParams) (db.ScreeningHit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewScreeningHit", arg0, arg1)
+	ret0, _ := ret[0].(db.ScreeningHit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReviewScreeningHit indicates an expected call of ReviewScreeningHit.
+func (mr *MockStoreMockRecorder) ReviewScreeningHit(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewScreeningHit", reflect.TypeOf((*MockStore)(nil).ReviewScreeningHit), arg0, arg1)
+}
+
+// ReviewScreeningHitTx mocks base method.
+func (m *MockStore) ReviewScreeningHitTx(arg0 context.Context, arg1 db.ReviewScreeningHitTxParams) (db.ReviewScreeningHitTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewScreeningHitTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ReviewScreeningHitTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReviewScreeningHitTx indicates an expected call of ReviewScreeningHitTx.
+func (mr *MockStoreMockRecorder) ReviewScreeningHitTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewScreeningHitTx", reflect.TypeOf((*MockStore)(nil).ReviewScreeningHitTx), arg0, arg1)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockStore) RevokeAPIKey(arg0 context.Context, arg1 db.RevokeAPIKeyParams) (db.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(db.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockStoreMockRecorder) RevokeAPIKey(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockStore)(nil).RevokeAPIKey), arg0, arg1)
+}
+
+// RevokeOAuthClientConsents mocks base method.
+func (m *MockStore) RevokeOAuthClientConsents(arg0 context.Context, arg1 db.RevokeOAuthClientConsentsParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeOAuthClientConsents", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeOAuthClientConsents indicates an expected call of RevokeOAuthClientConsents.
+func (mr *MockStoreMockRecorder) RevokeOAuthClientConsents(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeOAuthClientConsents", reflect.TypeOf((*MockStore)(nil).RevokeOAuthClientConsents), arg0, arg1)
+}
+
+// RevokeOAuthConsent mocks base method.
+func (m *MockStore) RevokeOAuthConsent(arg0 context.Context, arg1 db.RevokeOAuthConsentParams) (db.OAuthConsent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeOAuthConsent", arg0, arg1)
+	ret0, _ := ret[0].(db.OAuthConsent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeOAuthConsent indicates an expected call of RevokeOAuthConsent.
+func (mr *MockStoreMockRecorder) RevokeOAuthConsent(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeOAuthConsent", reflect.TypeOf((*MockStore)(nil).RevokeOAuthConsent), arg0, arg1)
+}
+
+// RevokeUserAPIKeys mocks base method.
+func (m *MockStore) RevokeUserAPIKeys(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserAPIKeys", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserAPIKeys indicates an expected call of RevokeUserAPIKeys.
+func (mr *MockStoreMockRecorder) RevokeUserAPIKeys(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserAPIKeys", reflect.TypeOf((*MockStore)(nil).RevokeUserAPIKeys), arg0, arg1)
+}
+
+// RevokeUserOAuthConsents mocks base method.
+func (m *MockStore) RevokeUserOAuthConsents(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserOAuthConsents", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserOAuthConsents indicates an expected call of RevokeUserOAuthConsents.
+func (mr *MockStoreMockRecorder) RevokeUserOAuthConsents(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserOAuthConsents", reflect.TypeOf((*MockStore)(nil).RevokeUserOAuthConsents), arg0, arg1)
+}
+
+// SaveKYCProfileTx mocks base method.
+func (m *MockStore) SaveKYCProfileTx(arg0 context.Context, arg1 db.UpsertKYCProfileParams) (db.KYCProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveKYCProfileTx", arg0, arg1)
+	ret0, _ := ret[0].(db.KYCProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveKYCProfileTx indicates an expected call of SaveKYCProfileTx.
+func (mr *MockStoreMockRecorder) SaveKYCProfileTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveKYCProfileTx", reflect.TypeOf((*MockStore)(nil).SaveKYCProfileTx), arg0, arg1)
+}
+
+// SchemaVersion mocks base method.
+func (m *MockStore) SchemaVersion(arg0 context.Context) (uint, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SchemaVersion", arg0)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SchemaVersion indicates an expected call of SchemaVersion.
+func (mr *MockStoreMockRecorder) SchemaVersion(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SchemaVersion", reflect.TypeOf((*MockStore)(nil).SchemaVersion), arg0)
+}
+
+// SetAccountFrozen mocks base method.
+func (m *MockStore) SetAccountFrozen(arg0 context.Context, arg1 db.SetAccountFrozenParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAccountFrozen", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetAccountFrozen indicates an expected call of SetAccountFrozen.
+func (mr *MockStoreMockRecorder) SetAccountFrozen(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAccountFrozen", reflect.TypeOf((*MockStore)(nil).SetAccountFrozen), arg0, arg1)
+}
+
+// SetFraudDecisionPayment mocks base method.
+func (m *MockStore) SetFraudDecisionPayment(arg0 context.Context, arg1 db.SetFraudDecisionPaymentParams) (db.FraudDecision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFraudDecisionPayment", arg0, arg1)
+	ret0, _ := ret[0].(db.FraudDecision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetFraudDecisionPayment indicates an expected call of SetFraudDecisionPayment.
+func (mr *MockStoreMockRecorder) SetFraudDecisionPayment(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFraudDecisionPayment", reflect.TypeOf((*MockStore)(nil).SetFraudDecisionPayment), arg0, arg1)
+}
+
+// SetKYCStatus mocks base method.
+func (m *MockStore) SetKYCStatus(arg0 context.Context, arg1 db.SetKYCStatusParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetKYCStatus", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetKYCStatus indicates an expected call of SetKYCStatus.
+func (mr *MockStoreMockRecorder) SetKYCStatus(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetKYCStatus", reflect.TypeOf((*MockStore)(nil).SetKYCStatus), arg0, arg1)
+}
+
+// SetLoanDisbursement mocks base method.
+func (m *MockStore) SetLoanDisbursement(arg0 context.Context, arg1 db.SetLoanDisbursementParams) (db.Loan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLoanDisbursement", arg0, arg1)
+	ret0, _ := ret[0].(db.Loan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetLoanDisbursement indicates an expected call of SetLoanDisbursement.
+func (mr *MockStoreMockRecorder) SetLoanDisbursement(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLoanDisbursement", reflect.TypeOf((*MockStore)(nil).SetLoanDisbursement), arg0, arg1)
+}
+
+// SetUserDisabled mocks base method.
+func (m *MockStore) SetUserDisabled(arg0 context.Context, arg1 db.SetUserDisabledParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserDisabled", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserDisabled indicates an expected call of SetUserDisabled.
+func (mr *MockStoreMockRecorder) SetUserDisabled(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserDisabled", reflect.TypeOf((*MockStore)(nil).SetUserDisabled), arg0, arg1)
+}
+
+// SetUserRole mocks base method.
+func (m *MockStore) SetUserRole(arg0 context.Context, arg1 db.SetUserRoleParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserRole", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserRole indicates an expected call of SetUserRole.
+func (mr *MockStoreMockRecorder) SetUserRole(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRole", reflect.TypeOf((*MockStore)(nil).SetUserRole), arg0, arg1)
+}
+
+// SubmitKYCProfile mocks base method.
+func (m *MockStore) SubmitKYCProfile(arg0 context.Context, arg1 string) (db.KYCProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubmitKYCProfile", arg0, arg1)
+	ret0, _ := ret[0].(db.KYCProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubmitKYCProfile indicates an expected call of SubmitKYCProfile.
+func (mr *MockStoreMockRecorder) SubmitKYCProfile(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitKYCProfile", reflect.TypeOf((*MockStore)(nil).SubmitKYCProfile), arg0, arg1)
+}
+
+// SubmitKYCTx mocks base method.
+func (m *MockStore) SubmitKYCTx(arg0 context.Context, arg1 string) (db.KYCTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubmitKYCTx", arg0, arg1)
+	ret0, _ := ret[0].(db.KYCTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubmitKYCTx indicates an expected call of SubmitKYCTx.
+func (mr *MockStoreMockRecorder) SubmitKYCTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitKYCTx", reflect.TypeOf((*MockStore)(nil).SubmitKYCTx), arg0, arg1)
+}
+
+// TakeRateLimitToken mocks base method.
+func (m *MockStore) TakeRateLimitToken(arg0 context.Context, arg1 db.TakeRateLimitTokenParams) (db.TakeRateLimitTokenRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeRateLimitToken", arg0, arg1)
+	ret0, _ := ret[0].(db.TakeRateLimitTokenRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeRateLimitToken indicates an expected call of TakeRateLimitToken.
+func (mr *MockStoreMockRecorder) TakeRateLimitToken(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeRateLimitToken", reflect.TypeOf((*MockStore)(nil).TakeRateLimitToken), arg0, arg1)
+}
+
+// TouchAPIKey mocks base method.
+func (m *MockStore) TouchAPIKey(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchAPIKey indicates an expected call of TouchAPIKey.
+func (mr *MockStoreMockRecorder) TouchAPIKey(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchAPIKey", reflect.TypeOf((*MockStore)(nil).TouchAPIKey), arg0, arg1)
+}
+
+// UnlockUser mocks base method.
+func (m *MockStore) UnlockUser(arg0 context.Context, arg1 string) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnlockUser indicates an expected call of UnlockUser.
+func (mr *MockStoreMockRecorder) UnlockUser(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockUser", reflect.TypeOf((*MockStore)(nil).UnlockUser), arg0, arg1)
+}
+
+// UpdateCard mocks base method.
+func (m *MockStore) UpdateCard(arg0 context.Context, arg1 db.UpdateCardParams) (db.Card, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCard", arg0, arg1)
+	ret0, _ := ret[0].(db.Card)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCard indicates an expected call of UpdateCard.
+func (mr *MockStoreMockRecorder) UpdateCard(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCard", reflect.TypeOf((*MockStore)(nil).UpdateCard), arg0, arg1)
+}
+
+// UpdateLoanInstallment mocks base method.
+func (m *MockStore) UpdateLoanInstallment(arg0 context.Context, arg1 db.UpdateLoanInstallmentParams) (db.LoanInstallment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLoanInstallment", arg0, arg1)
+	ret0, _ := ret[0].(db.LoanInstallment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateLoanInstallment indicates an expected call of UpdateLoanInstallment.
+func (mr *MockStoreMockRecorder) UpdateLoanInstallment(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLoanInstallment", reflect.TypeOf((*MockStore)(nil).UpdateLoanInstallment), arg0, arg1)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockStore) UpdatePassword(arg0 context.Context, arg1 db.UpdatePasswordParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockStoreMockRecorder) UpdatePassword(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockStore)(nil).UpdatePassword), arg0, arg1)
+}
+
+// UpdatePocket mocks base method.
+func (m *MockStore) UpdatePocket(arg0 context.Context, arg1 db.UpdatePocketParams) (db.Pocket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePocket", arg0, arg1)
+	ret0, _ := ret[0].(db.Pocket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePocket indicates an expected call of UpdatePocket.
+func (mr *MockStoreMockRecorder) UpdatePocket(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePocket", reflect.TypeOf((*MockStore)(nil).UpdatePocket), arg0, arg1)
+}
+
+// UpdateUserEmail mocks base method.
+func (m *MockStore) UpdateUserEmail(arg0 context.Context, arg1 db.UpdateUserEmailParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserEmail", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserEmail indicates an expected call of UpdateUserEmail.
+func (mr *MockStoreMockRecorder) UpdateUserEmail(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserEmail", reflect.TypeOf((*MockStore)(nil).UpdateUserEmail), arg0, arg1)
+}
+
+// UpdateUserFullName mocks base method.
+func (m *MockStore) UpdateUserFullName(arg0 context.Context, arg1 db.UpdateUserFullNameParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserFullName", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserFullName indicates an expected call of UpdateUserFullName.
+func (mr *MockStoreMockRecorder) UpdateUserFullName(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserFullName", reflect.TypeOf((*MockStore)(nil).UpdateUserFullName), arg0, arg1)
+}
+
+// UpsertAMLAlert mocks base method.
+func (m *MockStore) UpsertAMLAlert(arg0 context.Context, arg1 db.UpsertAMLAlertParams) (db.AMLAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertAMLAlert", arg0, arg1)
+	ret0, _ := ret[0].(db.AMLAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertAMLAlert indicates an expected call of UpsertAMLAlert.
+func (mr *MockStoreMockRecorder) UpsertAMLAlert(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertAMLAlert", reflect.TypeOf((*MockStore)(nil).UpsertAMLAlert), arg0, arg1)
+}
+
+// UpsertAccountMember mocks base method.
+func (m *MockStore) UpsertAccountMember(arg0 context.Context, arg1 db.UpsertAccountMemberParams) (db.AccountMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertAccountMember", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertAccountMember indicates an expected call of UpsertAccountMember.
+func (mr *MockStoreMockRecorder) UpsertAccountMember(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertAccountMember", reflect.TypeOf((*MockStore)(nil).UpsertAccountMember), arg0, arg1)
+}
+
+// UpsertKYCProfile mocks base method.
+func (m *MockStore) UpsertKYCProfile(arg0 context.Context, arg1 db.UpsertKYCProfileParams) (db.KYCProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertKYCProfile", arg0, arg1)
+	ret0, _ := ret[0].(db.KYCProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertKYCProfile indicates an expected call of UpsertKYCProfile.
+func (mr *MockStoreMockRecorder) UpsertKYCProfile(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertKYCProfile", reflect.TypeOf((*MockStore)(nil).UpsertKYCProfile), arg0, arg1)
+}
+
+// UpsertScreeningHit mocks base method.
+func (m *MockStore) UpsertScreeningHit(arg0 context.Context, arg1 db.UpsertScreeningHitParams) (db.ScreeningHit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertScreeningHit", arg0, arg1)
+	ret0, _ := ret[0].(db.ScreeningHit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertScreeningHit indicates an expected call of UpsertScreeningHit.
+func (mr *MockStoreMockRecorder) UpsertScreeningHit(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertScreeningHit", reflect.TypeOf((*MockStore)(nil).UpsertScreeningHit), arg0, arg1)
+}
+
+// UseOAuthAuthorizationCode mocks base method.
+func (m *MockStore) UseOAuthAuthorizationCode(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseOAuthAuthorizationCode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseOAuthAuthorizationCode indicates an expected call of UseOAuthAuthorizationCode.
+func (mr *MockStoreMockRecorder) UseOAuthAuthorizationCode(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseOAuthAuthorizationCode", reflect.TypeOf((*MockStore)(nil).UseOAuthAuthorizationCode), arg0, arg1)
+}
+
+// UseOAuthRefreshToken mocks base method.
+func (m *MockStore) UseOAuthRefreshToken(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseOAuthRefreshToken", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseOAuthRefreshToken indicates an expected call of UseOAuthRefreshToken.
+func (mr *MockStoreMockRecorder) UseOAuthRefreshToken(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseOAuthRefreshToken", reflect.TypeOf((*MockStore)(nil).UseOAuthRefreshToken), arg0, arg1)
+}
+
+// VerifyEmailTx mocks base method.
+func (m *MockStore) VerifyEmailTx(arg0 context.Context, arg1 string) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmailTx", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyEmailTx indicates an expected call of VerifyEmailTx.
+func (mr *MockStoreMockRecorder) VerifyEmailTx(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccount", reflect.TypeOf((*MockStore)(nil).UpdateAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmailTx", reflect.TypeOf((*MockStore)(nil).VerifyEmailTx), arg0, arg1)
 }
@@ -0,0 +1,71 @@
+-- name: GetAccountMember :one
+SELECT * FROM account_members
+WHERE account_id = $1 AND username = $2 LIMIT 1;
+
+-- name: ListAccountMembers :many
+SELECT * FROM account_members
+WHERE account_id = $1
+ORDER BY created_at;
+
+-- name: UpsertAccountMember :one
+INSERT INTO account_members (
+  account_id,
+  username,
+  role,
+  payment_limit,
+  invited_by
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (account_id, username) DO UPDATE
+SET
+  role = EXCLUDED.role,
+  payment_limit = EXCLUDED.payment_limit,
+  invited_by = EXCLUDED.invited_by
+RETURNING *;
+
+-- name: DeleteAccountMember :one
+DELETE FROM account_members
+WHERE account_id = $1 AND username = $2
+RETURNING *;
+
+-- name: CreateAccountInvitation :one
+INSERT INTO account_invitations (
+  account_id,
+  invitee,
+  role,
+  payment_limit,
+  invited_by,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetAccountInvitation :one
+SELECT * FROM account_invitations
+WHERE id = $1 LIMIT 1;
+
+-- name: GetAccountInvitationForUpdate :one
+SELECT * FROM account_invitations
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListAccountInvitations :many
+-- the pending invitations to an account, expired or not
+SELECT * FROM account_invitations
+WHERE account_id = $1 AND status = 'pending'
+ORDER BY id;
+
+-- name: ListUserInvitations :many
+-- the invitations a user may still accept
+SELECT * FROM account_invitations
+WHERE invitee = sqlc.arg(invitee) AND status = 'pending' AND expires_at > sqlc.arg(now)
+ORDER BY id;
+
+-- name: RespondAccountInvitation :one
+UPDATE account_invitations
+SET
+  status = sqlc.arg(status),
+  responded_at = now()
+WHERE id = sqlc.arg(id) AND status = 'pending'
+RETURNING *;
@@ -13,18 +13,23 @@ RETURNING *;
 SELECT * FROM accounts WHERE id = $1 LIMIT 1;
 
 -- name: ListAccounts :many
+-- the accounts of a user and those shared with them, with their pockets
 SELECT * FROM accounts
-WHERE owner = $1 
+WHERE owner = $1
+  OR id IN (SELECT account_id FROM account_members WHERE username = $1)
+  OR id IN (
+    SELECT p.account_id FROM pockets p
+    JOIN account_members m ON m.account_id = p.parent_id
+    WHERE m.username = $1
+  )
 ORDER BY id
 LIMIT $2
 OFFSET $3;
 
--- name: UpdateAccount :one
-UPDATE accounts
-SET balance = $2
-WHERE id = $1
-
-RETURNING *;
+-- name: CountAccounts :one
+-- pockets are part of their parent account and aren't counted
+SELECT count(*) FROM accounts
+WHERE owner = $1 AND type <> 'pocket';
 
 -- name: DeleteAccount :exec
 DELETE FROM accounts
@@ -38,3 +43,31 @@ UPDATE accounts
 SET balance = balance + sqlc.arg(amount)
 WHERE id = sqlc.arg(id)
 RETURNING *;
+
+-- name: SetAccountFrozen :one
+UPDATE accounts
+SET frozen = sqlc.arg(frozen), updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: GetSuspenseAccount :one
+SELECT * FROM accounts
+WHERE owner = '_system' AND type = 'suspense' AND currency = $1 LIMIT 1;
+
+-- name: GetInterestExpenseAccount :one
+SELECT * FROM accounts
+WHERE owner = '_system' AND type = 'interest_expense' AND currency = $1 LIMIT 1;
+
+-- name: FreezeOwnerAccounts :many
+UPDATE accounts
+SET frozen = true, updated_at = now()
+WHERE owner = $1 AND NOT frozen
+RETURNING *;
+
+-- name: GetLoansAccount :one
+SELECT * FROM accounts
+WHERE owner = '_system' AND type = 'loans' AND currency = $1 LIMIT 1;
+
+-- name: GetCardSettlementAccount :one
+SELECT * FROM accounts
+WHERE owner = '_system' AND type = 'card_settlement' AND currency = $1 LIMIT 1;
@@ -0,0 +1,61 @@
+-- name: CreateAdjustment :one
+INSERT INTO adjustments (
+  account_id,
+  amount,
+  reason_code,
+  note,
+  evidence,
+  created_by,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetAdjustment :one
+SELECT * FROM adjustments
+WHERE id = $1 LIMIT 1;
+
+-- name: GetAdjustmentForUpdate :one
+SELECT * FROM adjustments
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListAdjustments :many
+SELECT * FROM adjustments
+WHERE status = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: MarkAdjustmentApproved :one
+UPDATE adjustments
+SET
+  status = 'approved',
+  reviewed_by = sqlc.arg(reviewed_by),
+  reviewed_at = now(),
+  entry_id = sqlc.arg(entry_id),
+  suspense_entry_id = sqlc.arg(suspense_entry_id)
+WHERE id = sqlc.arg(id) AND status = 'pending'
+RETURNING *;
+
+-- name: MarkAdjustmentRejected :one
+UPDATE adjustments
+SET
+  status = 'rejected',
+  reviewed_by = sqlc.arg(reviewed_by),
+  reviewed_at = now(),
+  review_note = sqlc.arg(review_note)
+WHERE id = sqlc.arg(id) AND status = 'pending'
+RETURNING *;
+
+-- name: MarkAdjustmentExpired :one
+UPDATE adjustments
+SET status = 'expired', reviewed_at = now()
+WHERE id = $1 AND status = 'pending'
+RETURNING *;
+
+-- name: ExpireAdjustments :many
+UPDATE adjustments
+SET status = 'expired', reviewed_at = now()
+WHERE status = 'pending' AND expires_at <= now()
+RETURNING *;
@@ -0,0 +1,202 @@
+-- name: CreateAMLJobRun :one
+INSERT INTO aml_job_runs (
+  scenario,
+  window_start,
+  window_end
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: FinishAMLJobRun :one
+UPDATE aml_job_runs
+SET
+  alerts = $2,
+  error = $3,
+  finished_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: ListAMLJobRuns :many
+SELECT * FROM aml_job_runs
+ORDER BY started_at DESC, id DESC
+LIMIT $1
+OFFSET $2;
+
+-- name: UpsertAMLAlert :one
+-- opens an alert, or updates the open alert of the account for the
+-- scenario. Returns no row when an alert already holds all the evidence
+INSERT INTO aml_alerts (
+  scenario,
+  username,
+  account_id,
+  summary,
+  evidence
+)
+SELECT
+  sqlc.arg(scenario)::varchar,
+  sqlc.arg(username)::varchar,
+  sqlc.arg(account_id)::bigint,
+  sqlc.arg(summary)::varchar,
+  sqlc.arg(evidence)::jsonb
+WHERE NOT EXISTS (
+  SELECT 1 FROM aml_alerts
+  WHERE scenario = sqlc.arg(scenario)
+    AND account_id = sqlc.arg(account_id)
+    AND evidence @> sqlc.arg(coverage)::jsonb
+)
+ON CONFLICT (scenario, account_id) WHERE status = 'open' DO UPDATE SET
+  summary = EXCLUDED.summary,
+  evidence = EXCLUDED.evidence,
+  updated_at = now()
+RETURNING *;
+
+-- name: GetAMLAlert :one
+SELECT * FROM aml_alerts
+WHERE id = $1 LIMIT 1;
+
+-- name: ListAMLAlerts :many
+SELECT * FROM aml_alerts
+WHERE status = sqlc.arg(status)
+  AND (sqlc.narg(assigned_to)::varchar IS NULL OR assigned_to = sqlc.narg(assigned_to))
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: AssignAMLAlert :one
+UPDATE aml_alerts
+SET
+  assigned_to = $2,
+  updated_at = now()
+WHERE id = $1 AND status = 'open'
+RETURNING *;
+
+-- name: ResolveAMLAlert :one
+-- closes or escalates an open alert
+UPDATE aml_alerts
+SET
+  status = $2,
+  resolution = $3,
+  resolved_by = $4,
+  resolved_at = now(),
+  updated_at = now()
+WHERE id = $1 AND status = 'open'
+RETURNING *;
+
+-- name: CreateAMLAlertComment :one
+INSERT INTO aml_alert_comments (
+  alert_id,
+  author,
+  body
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: ListAMLAlertComments :many
+SELECT * FROM aml_alert_comments
+WHERE alert_id = $1
+ORDER BY created_at, id;
+
+-- name: FindStructuring :many
+-- finds accounts that sent several payments just under the threshold which
+-- add up to more than it
+SELECT
+  accounts.id AS account_id,
+  accounts.owner,
+  accounts.currency,
+  count(*) AS payment_count,
+  sum(payments.amount)::bigint AS total,
+  array_agg(payments.id ORDER BY payments.id)::bigint[] AS payment_ids
+FROM payments
+JOIN accounts ON accounts.id = payments.from_account_id
+WHERE payments.created_at >= sqlc.arg(since)
+  AND payments.created_at < sqlc.arg(until)
+  AND payments.amount >= sqlc.arg(min_amount)::bigint
+  AND payments.amount < sqlc.arg(threshold)::bigint
+  AND accounts.owner <> '_system'
+GROUP BY accounts.id
+HAVING count(*) >= sqlc.arg(min_payments)::bigint
+  AND sum(payments.amount) >= sqlc.arg(threshold)::bigint
+ORDER BY accounts.id;
+
+-- name: FindPassThrough :many
+-- finds accounts that sent on most of the money they received, after
+-- receiving it
+SELECT
+  accounts.id AS account_id,
+  accounts.owner,
+  accounts.currency,
+  (sum(entries.amount) FILTER (WHERE entries.amount > 0))::bigint AS total_in,
+  (-sum(entries.amount) FILTER (WHERE entries.amount < 0))::bigint AS total_out,
+  array_agg(entries.id ORDER BY entries.id)::bigint[] AS entry_ids
+FROM entries
+JOIN accounts ON accounts.id = entries.account_id
+WHERE entries.created_at >= sqlc.arg(since)
+  AND entries.created_at < sqlc.arg(until)
+  AND accounts.owner <> '_system'
+GROUP BY accounts.id
+HAVING sum(entries.amount) FILTER (WHERE entries.amount > 0) >= sqlc.arg(min_amount)::bigint
+  AND -sum(entries.amount) FILTER (WHERE entries.amount < 0) * 100
+    >= sum(entries.amount) FILTER (WHERE entries.amount > 0) * sqlc.arg(min_out_percent)::bigint
+  AND min(entries.created_at) FILTER (WHERE entries.amount > 0)
+    < max(entries.created_at) FILTER (WHERE entries.amount < 0)
+ORDER BY accounts.id;
+
+-- name: FindRoundTrips :many
+-- finds payments to another owner that came back to the payer within
+-- max_delay_hours for about the same amount
+SELECT
+  outgoing.from_account_id AS account_id,
+  senders.owner,
+  senders.currency,
+  receivers.owner AS counterparty,
+  outgoing.id AS outgoing_payment_id,
+  back.id AS back_payment_id,
+  outgoing.amount AS outgoing_amount,
+  back.amount AS back_amount
+FROM payments AS outgoing
+JOIN accounts AS senders ON senders.id = outgoing.from_account_id
+JOIN accounts AS receivers ON receivers.id = outgoing.to_account_id
+JOIN payments AS back ON back.created_at > outgoing.created_at
+  AND back.created_at <= outgoing.created_at + sqlc.arg(max_delay_hours)::integer * interval '1 hour'
+JOIN accounts AS back_senders ON back_senders.id = back.from_account_id
+JOIN accounts AS back_receivers ON back_receivers.id = back.to_account_id
+WHERE outgoing.created_at >= sqlc.arg(since)
+  AND back.created_at < sqlc.arg(until)
+  AND outgoing.amount >= sqlc.arg(min_amount)::bigint
+  AND senders.owner <> receivers.owner
+  AND senders.owner <> '_system'
+  AND receivers.owner <> '_system'
+  AND back_senders.owner = receivers.owner
+  AND back_receivers.owner = senders.owner
+  AND back.amount * 100 BETWEEN outgoing.amount * (100 - sqlc.arg(tolerance_percent)::bigint)
+    AND outgoing.amount * (100 + sqlc.arg(tolerance_percent)::bigint)
+ORDER BY outgoing.from_account_id, outgoing.id, back.id;
+
+-- name: FindDormantReactivations :many
+-- finds accounts opened before dormant_since without entries from then
+-- until since, which moved at least min_amount since
+SELECT
+  accounts.id AS account_id,
+  accounts.owner,
+  accounts.currency,
+  coalesce((
+    SELECT max(earlier.created_at) FROM entries AS earlier
+    WHERE earlier.account_id = accounts.id AND earlier.created_at < sqlc.arg(since)
+  ), accounts.created_at)::timestamptz AS last_active_at,
+  sum(abs(entries.amount))::bigint AS turnover,
+  array_agg(entries.id ORDER BY entries.id)::bigint[] AS entry_ids
+FROM accounts
+JOIN entries ON entries.account_id = accounts.id
+WHERE entries.created_at >= sqlc.arg(since)
+  AND entries.created_at < sqlc.arg(until)
+  AND accounts.created_at < sqlc.arg(dormant_since)
+  AND accounts.owner <> '_system'
+  AND NOT EXISTS (
+    SELECT 1 FROM entries AS earlier
+    WHERE earlier.account_id = accounts.id
+      AND earlier.created_at >= sqlc.arg(dormant_since)
+      AND earlier.created_at < sqlc.arg(since)
+  )
+GROUP BY accounts.id
+HAVING sum(abs(entries.amount)) >= sqlc.arg(min_amount)::bigint
+ORDER BY accounts.id;
@@ -0,0 +1,20 @@
+-- name: CreateAuditEvent :one
+INSERT INTO audit_events (
+  actor,
+  action,
+  outcome,
+  resource_type,
+  resource_id,
+  request_id,
+  client_ip,
+  metadata
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: ListAuditEvents :many
+SELECT * FROM audit_events
+WHERE actor = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
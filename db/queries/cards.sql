@@ -0,0 +1,106 @@
+-- name: CreateCard :one
+INSERT INTO cards (
+  account_id,
+  owner,
+  pan,
+  last4,
+  expiry_month,
+  expiry_year,
+  hashed_cvv,
+  transaction_limit,
+  daily_limit
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: GetCard :one
+SELECT * FROM cards
+WHERE id = $1 LIMIT 1;
+
+-- name: GetCardByPAN :one
+SELECT * FROM cards
+WHERE pan = $1 LIMIT 1;
+
+-- name: ListCards :many
+SELECT * FROM cards
+WHERE owner = $1
+ORDER BY id;
+
+-- name: UpdateCard :one
+UPDATE cards
+SET
+  frozen = sqlc.arg(frozen),
+  transaction_limit = sqlc.arg(transaction_limit),
+  daily_limit = sqlc.arg(daily_limit)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CreateCardAuthorization :one
+INSERT INTO card_authorizations (
+  card_id,
+  account_id,
+  stan,
+  rrn,
+  amount,
+  currency,
+  merchant,
+  mcc,
+  status,
+  response_code,
+  auth_code,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+) RETURNING *;
+
+-- name: GetCardAuthorizationByRRN :one
+SELECT * FROM card_authorizations
+WHERE card_id = $1 AND rrn = $2 LIMIT 1;
+
+-- name: GetCardAuthorizationForUpdate :one
+SELECT * FROM card_authorizations
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListCardAuthorizations :many
+SELECT * FROM card_authorizations
+WHERE card_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: GetCardSpend :one
+-- what a card was authorized for since a time, captured or still held
+SELECT COALESCE(sum(CASE status WHEN 'captured' THEN captured_amount ELSE amount END), 0)::bigint
+FROM card_authorizations
+WHERE card_id = sqlc.arg(card_id)
+  AND status IN ('approved', 'captured')
+  AND created_at >= sqlc.arg(since);
+
+-- name: GetAccountHolds :one
+-- what the approved authorizations not expired yet hold on an account
+SELECT COALESCE(sum(amount), 0)::bigint
+FROM card_authorizations
+WHERE account_id = $1
+  AND status = 'approved'
+  AND expires_at > now();
+
+-- name: CaptureCardAuthorization :one
+UPDATE card_authorizations
+SET
+  status = 'captured',
+  captured_amount = sqlc.arg(captured_amount),
+  entry_id = sqlc.arg(entry_id),
+  settlement_entry_id = sqlc.arg(settlement_entry_id),
+  captured_at = now()
+WHERE id = sqlc.arg(id) AND status = 'approved'
+RETURNING *;
+
+-- name: ReverseCardAuthorization :one
+-- releases the hold of an approved authorization
+UPDATE card_authorizations
+SET
+  status = 'reversed',
+  reversed_at = now()
+WHERE id = $1 AND status = 'approved'
+RETURNING *;
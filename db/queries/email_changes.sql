@@ -0,0 +1,32 @@
+-- name: CreateEmailChange :one
+INSERT INTO email_changes (
+  username,
+  old_email,
+  new_email,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetLatestEmailChangeForUpdate :one
+SELECT * FROM email_changes
+WHERE username = $1
+ORDER BY id DESC
+LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ConfirmEmailChange :one
+-- records the confirmation from email, which is either the old or the new
+-- address of the change
+UPDATE email_changes
+SET
+  old_confirmed_at = CASE WHEN old_email = sqlc.arg(email)::varchar THEN now() ELSE old_confirmed_at END,
+  new_confirmed_at = CASE WHEN new_email = sqlc.arg(email)::varchar THEN now() ELSE new_confirmed_at END
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CompleteEmailChange :one
+UPDATE email_changes
+SET completed_at = now()
+WHERE id = $1
+RETURNING *;
@@ -15,4 +15,9 @@ SELECT * FROM entries
 WHERE account_id = $1
 ORDER BY id
 LIMIT $2
-OFFSET $3;
\ No newline at end of file
+OFFSET $3;
+
+-- name: ListEntriesByID :many
+SELECT * FROM entries
+WHERE id = ANY(sqlc.arg(ids)::bigint[])
+ORDER BY created_at, id;
\ No newline at end of file
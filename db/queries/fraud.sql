@@ -0,0 +1,46 @@
+-- name: CreateFraudDecision :one
+INSERT INTO fraud_decisions (
+  username,
+  from_account_id,
+  to_account_id,
+  amount,
+  score,
+  outcome,
+  status,
+  trace,
+  features
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: GetFraudDecision :one
+SELECT * FROM fraud_decisions
+WHERE id = $1 LIMIT 1;
+
+-- name: GetFraudDecisionForUpdate :one
+SELECT * FROM fraud_decisions
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListFraudDecisions :many
+SELECT * FROM fraud_decisions
+WHERE status = $1
+ORDER BY created_at, id
+LIMIT $2
+OFFSET $3;
+
+-- name: SetFraudDecisionPayment :one
+UPDATE fraud_decisions
+SET payment_id = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: ReviewFraudDecision :one
+UPDATE fraud_decisions
+SET
+  status = $1,
+  reviewed_by = $2,
+  reviewed_at = now(),
+  payment_id = $3
+WHERE id = $4
+RETURNING *;
@@ -0,0 +1,76 @@
+-- name: UpsertKYCProfile :one
+INSERT INTO kyc_profiles (
+  username,
+  legal_name,
+  date_of_birth,
+  nationality,
+  address_line,
+  city,
+  postal_code,
+  country
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+)
+ON CONFLICT (username) DO UPDATE SET
+  legal_name = EXCLUDED.legal_name,
+  date_of_birth = EXCLUDED.date_of_birth,
+  nationality = EXCLUDED.nationality,
+  address_line = EXCLUDED.address_line,
+  city = EXCLUDED.city,
+  postal_code = EXCLUDED.postal_code,
+  country = EXCLUDED.country,
+  updated_at = now()
+RETURNING *;
+
+-- name: GetKYCProfile :one
+SELECT * FROM kyc_profiles
+WHERE username = $1 LIMIT 1;
+
+-- name: SubmitKYCProfile :one
+UPDATE kyc_profiles
+SET
+  submitted_at = now(),
+  reviewed_by = NULL,
+  reviewed_at = NULL,
+  rejection_reason = NULL
+WHERE username = $1
+RETURNING *;
+
+-- name: ReviewKYCProfile :one
+UPDATE kyc_profiles
+SET
+  reviewed_by = sqlc.arg(reviewed_by),
+  reviewed_at = now(),
+  rejection_reason = sqlc.narg(rejection_reason)
+WHERE username = sqlc.arg(username)
+RETURNING *;
+
+-- name: ListKYCReviewQueue :many
+-- lists the profiles waiting for review, the oldest submission first
+SELECT kyc_profiles.* FROM kyc_profiles
+JOIN users ON users.username = kyc_profiles.username
+WHERE users.kyc_status = 'pending'
+ORDER BY kyc_profiles.submitted_at
+LIMIT $1
+OFFSET $2;
+
+-- name: CreateKYCDocument :one
+INSERT INTO kyc_documents (
+  username,
+  kind,
+  storage_key,
+  content_type,
+  size,
+  sha256
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetKYCDocument :one
+SELECT * FROM kyc_documents
+WHERE id = $1 LIMIT 1;
+
+-- name: ListKYCDocuments :many
+SELECT * FROM kyc_documents
+WHERE username = $1
+ORDER BY id;
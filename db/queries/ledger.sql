@@ -0,0 +1,12 @@
+-- name: ListLedgerMismatches :many
+SELECT a.id, a.balance, COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+GROUP BY a.id
+HAVING a.balance <> COALESCE(SUM(e.amount), 0)
+ORDER BY a.id;
+
+-- name: GetLedgerTotals :one
+SELECT
+  (SELECT COALESCE(SUM(amount), 0) FROM entries)::bigint AS entries_total,
+  (SELECT COALESCE(SUM(amount), 0) FROM adjustments WHERE status = 'approved' AND suspense_entry_id IS NULL)::bigint AS adjustments_total;
@@ -0,0 +1,178 @@
+-- name: CreateLoanProduct :one
+INSERT INTO loan_products (
+  name,
+  currency,
+  rate_bps,
+  method,
+  min_principal,
+  max_principal,
+  min_term,
+  max_term,
+  late_fee,
+  grace_days,
+  created_by
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+) RETURNING *;
+
+-- name: GetLoanProduct :one
+SELECT * FROM loan_products
+WHERE id = $1 LIMIT 1;
+
+-- name: ListLoanProducts :many
+SELECT * FROM loan_products
+ORDER BY currency, name;
+
+-- name: CreateLoan :one
+INSERT INTO loans (
+  product_id,
+  account_id,
+  borrower,
+  principal,
+  rate_bps,
+  term_months,
+  method,
+  late_fee,
+  grace_days,
+  outstanding_principal,
+  disbursed_on
+) VALUES (
+  sqlc.arg(product_id), sqlc.arg(account_id), sqlc.arg(borrower), sqlc.arg(principal), sqlc.arg(rate_bps),
+  sqlc.arg(term_months), sqlc.arg(method), sqlc.arg(late_fee), sqlc.arg(grace_days), sqlc.arg(principal),
+  sqlc.arg(disbursed_on)
+) RETURNING *;
+
+-- name: GetLoan :one
+SELECT * FROM loans
+WHERE id = $1 LIMIT 1;
+
+-- name: GetLoanForUpdate :one
+SELECT * FROM loans
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListLoans :many
+SELECT * FROM loans
+WHERE borrower = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: SetLoanDisbursement :one
+UPDATE loans
+SET disbursement_entry_id = sqlc.arg(disbursement_entry_id), funding_entry_id = sqlc.arg(funding_entry_id)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ReduceLoanPrincipal :one
+UPDATE loans
+SET outstanding_principal = outstanding_principal - sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CloseLoan :one
+UPDATE loans
+SET status = 'paid_off', closed_at = now()
+WHERE id = $1 AND status = 'active'
+RETURNING *;
+
+-- name: CreateLoanInstallment :one
+INSERT INTO loan_installments (
+  loan_id,
+  period,
+  due_date,
+  principal,
+  interest
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetLoanInstallment :one
+SELECT * FROM loan_installments
+WHERE id = $1 LIMIT 1;
+
+-- name: GetLoanInstallmentForUpdate :one
+SELECT * FROM loan_installments
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListLoanInstallments :many
+-- the installments of a loan in the order they are due, the cancelled ones
+-- included
+SELECT * FROM loan_installments
+WHERE loan_id = $1
+ORDER BY period, id;
+
+-- name: ListDueLoanInstallments :many
+-- pages through the installments due on or before a day that aren't paid
+SELECT * FROM loan_installments
+WHERE status IN ('scheduled', 'overdue')
+  AND due_date <= sqlc.arg(due_on)
+  AND id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: UpdateLoanInstallment :one
+UPDATE loan_installments
+SET
+  fee = sqlc.arg(fee),
+  paid_principal = sqlc.arg(paid_principal),
+  paid_interest = sqlc.arg(paid_interest),
+  paid_fee = sqlc.arg(paid_fee),
+  status = sqlc.arg(status),
+  paid_at = sqlc.narg(paid_at)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CancelLoanInstallments :exec
+UPDATE loan_installments
+SET status = 'cancelled'
+WHERE loan_id = $1 AND status = 'scheduled';
+
+-- name: CountUnpaidLoanInstallments :one
+SELECT count(*) FROM loan_installments
+WHERE loan_id = $1 AND status IN ('scheduled', 'overdue');
+
+-- name: GetLoanArrears :one
+-- what is owed on the installments due before a day
+SELECT
+  count(*) AS installments,
+  COALESCE(sum(principal + interest + fee - paid_principal - paid_interest - paid_fee), 0)::bigint AS amount
+FROM loan_installments
+WHERE loan_id = sqlc.arg(loan_id)
+  AND status IN ('scheduled', 'overdue')
+  AND due_date < sqlc.arg(due_before);
+
+-- name: GetBorrowerLoanExposure :one
+-- the principal a borrower owes under a product, and how many of their loans
+-- have installments due before a day that aren't paid
+SELECT
+  COALESCE(sum(outstanding_principal) FILTER (WHERE product_id = sqlc.arg(product_id)), 0)::bigint AS outstanding_principal,
+  count(*) FILTER (WHERE EXISTS (
+    SELECT 1 FROM loan_installments i
+    WHERE i.loan_id = loans.id
+      AND i.status IN ('scheduled', 'overdue')
+      AND i.due_date < sqlc.arg(due_before)
+  )) AS loans_in_arrears
+FROM loans
+WHERE borrower = sqlc.arg(borrower) AND status = 'active';
+
+-- name: CreateLoanRepayment :one
+INSERT INTO loan_repayments (
+  loan_id,
+  installment_id,
+  kind,
+  amount,
+  principal,
+  interest,
+  fee,
+  entry_id,
+  loan_entry_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: ListLoanRepayments :many
+SELECT * FROM loan_repayments
+WHERE loan_id = $1
+ORDER BY id;
@@ -0,0 +1,101 @@
+-- name: CreateOAuthClient :one
+INSERT INTO oauth_clients (
+  id,
+  name,
+  secret_hash,
+  redirect_uris,
+  scopes,
+  created_by
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetOAuthClient :one
+SELECT * FROM oauth_clients
+WHERE id = $1 LIMIT 1;
+
+-- name: ListOAuthClients :many
+SELECT * FROM oauth_clients
+ORDER BY created_at;
+
+-- name: CreateOAuthConsent :one
+INSERT INTO oauth_consents (
+  username,
+  client_id,
+  scopes,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetOAuthConsent :one
+SELECT * FROM oauth_consents
+WHERE id = $1 LIMIT 1;
+
+-- name: ListOAuthConsents :many
+-- lists the consents of a user that are in force
+SELECT oauth_consents.*, oauth_clients.name AS client_name
+FROM oauth_consents
+JOIN oauth_clients ON oauth_clients.id = oauth_consents.client_id
+WHERE oauth_consents.username = $1
+  AND oauth_consents.revoked_at IS NULL
+  AND oauth_consents.expires_at > now()
+ORDER BY oauth_consents.id;
+
+-- name: RevokeOAuthConsent :one
+UPDATE oauth_consents
+SET revoked_at = now()
+WHERE id = $1 AND username = $2 AND revoked_at IS NULL
+RETURNING *;
+
+-- name: RevokeOAuthClientConsents :exec
+-- revokes the consents a user gave to a client before
+UPDATE oauth_consents
+SET revoked_at = now()
+WHERE username = $1 AND client_id = $2 AND revoked_at IS NULL;
+
+-- name: RevokeUserOAuthConsents :exec
+-- revokes every consent of a user, which ends their refresh tokens too
+UPDATE oauth_consents
+SET revoked_at = now()
+WHERE username = $1 AND revoked_at IS NULL;
+
+-- name: CreateOAuthAuthorizationCode :one
+INSERT INTO oauth_authorization_codes (
+  code_hash,
+  consent_id,
+  redirect_uri,
+  code_challenge,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetOAuthAuthorizationCodeForUpdate :one
+SELECT * FROM oauth_authorization_codes
+WHERE code_hash = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: UseOAuthAuthorizationCode :exec
+UPDATE oauth_authorization_codes
+SET used_at = now()
+WHERE id = $1;
+
+-- name: CreateOAuthRefreshToken :one
+INSERT INTO oauth_refresh_tokens (
+  token_hash,
+  consent_id,
+  expires_at
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: GetOAuthRefreshTokenForUpdate :one
+SELECT * FROM oauth_refresh_tokens
+WHERE token_hash = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: UseOAuthRefreshToken :exec
+UPDATE oauth_refresh_tokens
+SET used_at = now()
+WHERE id = $1;
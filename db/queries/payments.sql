@@ -2,9 +2,10 @@
 INSERT INTO payments (
   from_account_id,
   to_account_id,
-  amount
+  amount,
+  initiated_by
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4
 ) RETURNING *;
 
 -- name: GetPayment :one
@@ -18,4 +19,42 @@ WHERE
     to_account_id = $2
 ORDER BY id
 LIMIT $3
-OFFSET $4;
\ No newline at end of file
+OFFSET $4;
+
+-- name: GetOutgoingPaymentTotal :one
+-- sums what a user sent in a currency since a time, from any account they
+-- may pay from
+SELECT COALESCE(SUM(payments.amount), 0)::bigint AS total
+FROM payments
+JOIN accounts ON accounts.id = payments.from_account_id
+WHERE payments.initiated_by = sqlc.arg(initiated_by)
+  AND accounts.currency = sqlc.arg(currency)
+  AND payments.created_at >= sqlc.arg(since);
+
+-- name: GetAccountPaymentTotal :one
+-- sums what a user sent from one account since a time
+SELECT COALESCE(SUM(amount), 0)::bigint AS total
+FROM payments
+WHERE from_account_id = sqlc.arg(from_account_id)
+  AND initiated_by = sqlc.arg(initiated_by)
+  AND created_at >= sqlc.arg(since);
+
+-- name: GetPaymentHistory :one
+-- summarises the payments a user sent before, for the fraud rules
+SELECT
+  count(*) AS payment_count,
+  count(*) FILTER (WHERE to_accounts.owner = sqlc.arg(payee)) AS payee_payments,
+  count(*) FILTER (WHERE payments.created_at >= sqlc.arg(hour_ago)) AS payments_last_hour,
+  count(*) FILTER (WHERE payments.created_at >= sqlc.arg(day_ago)) AS payments_last_day,
+  COALESCE(SUM(payments.amount) FILTER (WHERE from_accounts.currency = sqlc.arg(currency) AND payments.created_at >= sqlc.arg(day_ago)), 0)::bigint AS amount_last_day,
+  COALESCE(AVG(payments.amount) FILTER (WHERE from_accounts.currency = sqlc.arg(currency)), 0)::float8 AS average_amount,
+  count(*) FILTER (WHERE payments.created_at >= sqlc.arg(password_changed_at)) AS payments_since_password_change
+FROM payments
+JOIN accounts AS from_accounts ON from_accounts.id = payments.from_account_id
+JOIN accounts AS to_accounts ON to_accounts.id = payments.to_account_id
+WHERE payments.initiated_by = sqlc.arg(initiated_by);
+
+-- name: ListPaymentsByID :many
+SELECT * FROM payments
+WHERE id = ANY(sqlc.arg(ids)::bigint[])
+ORDER BY created_at, id;
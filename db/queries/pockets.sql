@@ -0,0 +1,65 @@
+-- name: CreatePocketAccount :one
+INSERT INTO accounts (
+  owner,
+  balance,
+  currency,
+  type
+) VALUES (
+  $1, 0, $2, 'pocket'
+) RETURNING *;
+
+-- name: CreatePocket :one
+INSERT INTO pockets (
+  account_id,
+  parent_id,
+  name,
+  goal_amount,
+  target_date,
+  round_up_to
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetPocket :one
+SELECT * FROM pockets
+WHERE account_id = $1 LIMIT 1;
+
+-- name: ListPockets :many
+SELECT * FROM pockets
+WHERE parent_id = $1
+ORDER BY account_id;
+
+-- name: GetRoundUpPocket :one
+SELECT * FROM pockets
+WHERE parent_id = $1 AND round_up_to > 0 LIMIT 1;
+
+-- name: UpdatePocket :one
+UPDATE pockets
+SET
+  name = sqlc.arg(name),
+  goal_amount = sqlc.arg(goal_amount),
+  target_date = sqlc.arg(target_date),
+  round_up_to = sqlc.arg(round_up_to)
+WHERE account_id = sqlc.arg(account_id)
+RETURNING *;
+
+-- name: CreatePocketMove :one
+INSERT INTO pocket_moves (
+  parent_id,
+  from_account_id,
+  to_account_id,
+  amount,
+  kind,
+  payment_id,
+  from_entry_id,
+  to_entry_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: ListPocketMoves :many
+SELECT * FROM pocket_moves
+WHERE from_account_id = sqlc.arg(account_id) OR to_account_id = sqlc.arg(account_id)
+ORDER BY id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
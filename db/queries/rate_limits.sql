@@ -0,0 +1,21 @@
+-- name: TakeRateLimitToken :one
+-- refills the bucket for the time since it was last used, up to burst, and
+-- takes a token if there is a whole one left, all in one statement
+INSERT INTO rate_limit_buckets AS b (key, tokens, allowed, updated_at)
+VALUES (sqlc.arg(key), sqlc.arg(burst)::float8 - 1, sqlc.arg(burst)::float8 >= 1, now())
+ON CONFLICT (key) DO UPDATE
+SET
+  tokens = CASE
+    WHEN LEAST(sqlc.arg(burst)::float8, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at) * sqlc.arg(rate)::float8) >= 1
+    THEN LEAST(sqlc.arg(burst)::float8, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at) * sqlc.arg(rate)::float8) - 1
+    ELSE LEAST(sqlc.arg(burst)::float8, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at) * sqlc.arg(rate)::float8)
+  END,
+  allowed = LEAST(sqlc.arg(burst)::float8, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at) * sqlc.arg(rate)::float8) >= 1,
+  updated_at = now()
+RETURNING tokens, allowed;
+
+-- name: DeleteStaleRateLimitBuckets :execrows
+-- buckets nobody used since updated_before have refilled and behave like
+-- missing ones
+DELETE FROM rate_limit_buckets
+WHERE updated_at < sqlc.arg(updated_before);
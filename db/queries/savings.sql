@@ -0,0 +1,144 @@
+-- name: CreateSavingsProduct :one
+INSERT INTO savings_products (
+  name,
+  currency,
+  rate_bps,
+  day_count,
+  compounding,
+  payout_frequency,
+  created_by
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetSavingsProduct :one
+SELECT * FROM savings_products
+WHERE id = $1 LIMIT 1;
+
+-- name: ListSavingsProducts :many
+SELECT * FROM savings_products
+ORDER BY currency, name;
+
+-- name: CreateSavingsAccount :one
+INSERT INTO accounts (
+  owner,
+  balance,
+  currency,
+  type
+) VALUES (
+  $1, 0, $2, 'savings'
+) RETURNING *;
+
+-- name: CreateSavingsAccountLink :one
+INSERT INTO savings_accounts (
+  account_id,
+  product_id
+) VALUES (
+  $1, $2
+) RETURNING *;
+
+-- name: GetSavingsAccount :one
+SELECT * FROM savings_accounts
+WHERE account_id = $1 LIMIT 1;
+
+-- name: ListSavingsAccounts :many
+-- pages through the savings accounts with their product, by id
+SELECT
+  accounts.id AS account_id,
+  accounts.currency,
+  accounts.created_at,
+  savings_products.id AS product_id,
+  savings_products.rate_bps,
+  savings_products.day_count,
+  savings_products.compounding,
+  savings_products.payout_frequency
+FROM savings_accounts
+JOIN accounts ON accounts.id = savings_accounts.account_id
+JOIN savings_products ON savings_products.id = savings_accounts.product_id
+WHERE accounts.id > sqlc.arg(after_id)
+ORDER BY accounts.id
+LIMIT sqlc.arg('limit');
+
+-- name: GetEndOfDayBalance :one
+-- the balance of an account at a time, from its balance now and the entries
+-- booked since
+SELECT (accounts.balance - COALESCE((
+  SELECT sum(entries.amount) FROM entries
+  WHERE entries.account_id = accounts.id AND entries.created_at >= sqlc.arg(at)
+), 0))::bigint AS balance
+FROM accounts
+WHERE accounts.id = sqlc.arg(account_id);
+
+-- name: CreateInterestAccrual :one
+INSERT INTO interest_accruals (
+  account_id,
+  accrual_date,
+  balance,
+  base,
+  rate_bps,
+  day_count,
+  days,
+  amount_micros
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+)
+ON CONFLICT (account_id, accrual_date) DO NOTHING
+RETURNING *;
+
+-- name: GetLastInterestAccrual :one
+SELECT * FROM interest_accruals
+WHERE account_id = $1
+ORDER BY accrual_date DESC
+LIMIT 1;
+
+-- name: ListInterestAccruals :many
+SELECT * FROM interest_accruals
+WHERE account_id = $1
+ORDER BY accrual_date DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: GetUnpaidInterest :one
+-- the interest accrued before a day and not paid out yet
+SELECT
+  count(*) AS accruals,
+  COALESCE(sum(amount_micros), 0)::bigint AS amount_micros,
+  COALESCE(min(accrual_date), sqlc.arg(before))::date AS first_date
+FROM interest_accruals
+WHERE account_id = sqlc.arg(account_id)
+  AND accrual_date < sqlc.arg(before)
+  AND payout_id IS NULL;
+
+-- name: MarkInterestAccrualsPaid :exec
+UPDATE interest_accruals
+SET payout_id = sqlc.arg(payout_id)
+WHERE account_id = sqlc.arg(account_id)
+  AND accrual_date < sqlc.arg(before)
+  AND payout_id IS NULL;
+
+-- name: CreateInterestPayout :one
+INSERT INTO interest_payouts (
+  account_id,
+  period_start,
+  period_end,
+  accrued_micros,
+  amount,
+  carry_micros,
+  entry_id,
+  expense_entry_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: GetLastInterestPayout :one
+SELECT * FROM interest_payouts
+WHERE account_id = $1
+ORDER BY period_end DESC
+LIMIT 1;
+
+-- name: ListInterestPayouts :many
+SELECT * FROM interest_payouts
+WHERE account_id = $1
+ORDER BY period_end DESC
+LIMIT $2
+OFFSET $3;
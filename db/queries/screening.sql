@@ -0,0 +1,57 @@
+-- name: UpsertScreeningHit :one
+-- records a match, opening it again if it was reviewed for another name
+INSERT INTO screening_hits (
+  username,
+  source,
+  screened_name,
+  list,
+  entry_id,
+  entry_name,
+  score
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (username, list, entry_id) DO UPDATE SET
+  source = EXCLUDED.source,
+  screened_name = EXCLUDED.screened_name,
+  entry_name = EXCLUDED.entry_name,
+  score = EXCLUDED.score,
+  status = CASE WHEN screening_hits.screened_name = EXCLUDED.screened_name THEN screening_hits.status ELSE 'open' END,
+  reviewed_by = CASE WHEN screening_hits.screened_name = EXCLUDED.screened_name THEN screening_hits.reviewed_by END,
+  reviewed_at = CASE WHEN screening_hits.screened_name = EXCLUDED.screened_name THEN screening_hits.reviewed_at END,
+  review_note = CASE WHEN screening_hits.screened_name = EXCLUDED.screened_name THEN screening_hits.review_note ELSE '' END,
+  updated_at = now()
+RETURNING *;
+
+-- name: GetScreeningHit :one
+SELECT * FROM screening_hits
+WHERE id = $1 LIMIT 1;
+
+-- name: GetScreeningHitForUpdate :one
+SELECT * FROM screening_hits
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListScreeningHits :many
+SELECT * FROM screening_hits
+WHERE status = $1
+ORDER BY created_at, id
+LIMIT $2
+OFFSET $3;
+
+-- name: CountBlockingScreeningHits :one
+-- counts the hits of the users that are open or confirmed
+SELECT count(*) FROM screening_hits
+WHERE username = ANY(sqlc.arg(usernames)::varchar[])
+  AND status IN ('open', 'confirmed');
+
+-- name: ReviewScreeningHit :one
+UPDATE screening_hits
+SET
+  status = $1,
+  reviewed_by = $2,
+  reviewed_at = now(),
+  review_note = $3,
+  updated_at = now()
+WHERE id = $4
+RETURNING *;
@@ -10,4 +10,90 @@ INSERT INTO users (
 
 -- name: GetUser :one
 SELECT * FROM users
-WHERE username = $1 LIMIT 1;
\ No newline at end of file
+WHERE username = $1 LIMIT 1;
+
+-- name: SetUserDisabled :one
+UPDATE users
+SET disabled = sqlc.arg(disabled)
+WHERE username = sqlc.arg(username)
+RETURNING *;
+
+-- name: SetUserRole :one
+UPDATE users
+SET role = sqlc.arg(role)
+WHERE username = sqlc.arg(username)
+RETURNING *;
+
+-- name: RecordFailedLogin :one
+UPDATE users
+SET failed_login_attempts = failed_login_attempts + 1
+WHERE username = $1
+RETURNING *;
+
+-- name: LockUser :one
+UPDATE users
+SET
+  failed_login_attempts = 0,
+  lockouts = lockouts + 1,
+  locked_until = sqlc.arg(locked_until)
+WHERE username = sqlc.arg(username)
+RETURNING *;
+
+-- name: ResetFailedLogins :exec
+UPDATE users
+SET failed_login_attempts = 0, lockouts = 0
+WHERE username = $1;
+
+-- name: GetUserByEmail :one
+SELECT * FROM users
+WHERE email = $1 LIMIT 1;
+
+-- name: MarkEmailVerified :one
+UPDATE users
+SET email_verified_at = now()
+WHERE username = sqlc.arg(username) AND email = sqlc.arg(email)
+RETURNING *;
+
+-- name: UpdatePassword :one
+UPDATE users
+SET
+  hashed_password = sqlc.arg(hashed_password),
+  password_changed_at = now()
+WHERE username = sqlc.arg(username)
+RETURNING *;
+
+-- name: UnlockUser :one
+UPDATE users
+SET
+  failed_login_attempts = 0,
+  lockouts = 0,
+  locked_until = '0001-01-01 00:00:00Z'
+WHERE username = $1
+RETURNING *;
+
+-- name: UpdateUserFullName :one
+UPDATE users
+SET full_name = sqlc.arg(full_name)
+WHERE username = sqlc.arg(username)
+RETURNING *;
+
+-- name: UpdateUserEmail :one
+-- only replaces the email if it is still old_email; the new address counts
+-- as verified
+UPDATE users
+SET
+  email = sqlc.arg(new_email),
+  email_verified_at = now()
+WHERE username = sqlc.arg(username) AND email = sqlc.arg(old_email)
+RETURNING *;
+
+-- name: GetUserForUpdate :one
+SELECT * FROM users
+WHERE username = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: SetKYCStatus :one
+UPDATE users
+SET kyc_status = sqlc.arg(kyc_status)
+WHERE username = sqlc.arg(username)
+RETURNING *;
@@ -0,0 +1,237 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Roles of the users of an account. The owner is the user in accounts.owner;
+// the others are members who accepted an invitation.
+const (
+	AccountRoleOwner   = "owner"
+	AccountRoleCoOwner = "co_owner"
+	AccountRoleViewer  = "viewer"
+	AccountRolePayer   = "payer"
+)
+
+// Actions on an account, checked by AuthorizeAccount.
+const (
+	// AccountActionView reads the account, its entries and its pockets.
+	AccountActionView = "view"
+	// AccountActionPay sends payments from the account.
+	AccountActionPay = "pay"
+	// AccountActionManage changes the account: its pockets, cards and loans.
+	AccountActionManage = "manage"
+	// AccountActionShare invites members and removes them.
+	AccountActionShare = "share"
+)
+
+var accountPermissions = map[string][]string{
+	AccountRoleOwner:   {AccountActionView, AccountActionPay, AccountActionManage, AccountActionShare},
+	AccountRoleCoOwner: {AccountActionView, AccountActionPay, AccountActionManage},
+	AccountRoleViewer:  {AccountActionView},
+	AccountRolePayer:   {AccountActionView, AccountActionPay},
+}
+
+// Statuses of account invitations.
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+	InvitationRevoked  = "revoked"
+)
+
+// NotificationAccountInvitation tells a user they were invited to an account.
+const NotificationAccountInvitation = "account.invitation"
+
+var (
+	ErrNotAccountMember     = errors.New("account doesn't belong to the user")
+	ErrAccountActionDenied  = errors.New("the user's role on the account doesn't allow this")
+	ErrPaymentLimitExceeded = errors.New("payment is more than the payer's daily limit")
+	ErrPaymentAmountInvalid = errors.New("payment amount must be positive")
+	ErrInvitationNotPending = errors.New("invitation was already accepted, declined or revoked")
+	ErrInvitationExpired    = errors.New("invitation expired")
+)
+
+// AccountAccess is what a user may do with an account.
+type AccountAccess struct {
+	Role string `json:"role"`
+	// PaymentLimit is the most a payer may send from the account in a day.
+	PaymentLimit int64 `json:"payment_limit"`
+}
+
+// CheckPayment makes sure the user may send amount from the account. Only
+// positive amounts are ever sent: a negative one would pull money from the
+// payee instead.
+func (access AccountAccess) CheckPayment(amount int64) error {
+	if amount <= 0 {
+		return ErrPaymentAmountInvalid
+	}
+	if access.Role == AccountRolePayer && amount > access.PaymentLimit {
+		return ErrPaymentLimitExceeded
+	}
+	return nil
+}
+
+// CheckPayerAllowance makes sure a payer stays within their payment limit
+// for the day, counting what they sent from account in the last 24 hours.
+// The other roles have no allowance. Like the KYC daily limit, concurrent
+// payments may together go over it by up to one payment.
+func CheckPayerAllowance(ctx context.Context, q Querier, access AccountAccess, account Account, username string, amount int64) error {
+	if access.Role != AccountRolePayer {
+		return nil
+	}
+
+	sent, err := q.GetAccountPaymentTotal(ctx, GetAccountPaymentTotalParams{
+		FromAccountID: account.ID,
+		InitiatedBy:   username,
+		Since:         time.Now().Add(-24 * time.Hour),
+	})
+	if err != nil {
+		return err
+	}
+	if sent+amount > access.PaymentLimit {
+		return ErrPaymentLimitExceeded
+	}
+	return nil
+}
+
+// AuthorizeAccount is where every check of a user's access to an account is
+// made. It returns the role of username on account if that role allows
+// action, and fails with ErrNotAccountMember when the user has no role on it
+// or ErrAccountActionDenied when the role doesn't allow the action. Members
+// of an account have the same role on its pockets.
+func AuthorizeAccount(ctx context.Context, q Querier, account Account, username string, action string) (AccountAccess, error) {
+	access, err := accountAccess(ctx, q, account, username)
+	if err != nil {
+		return access, err
+	}
+	if !slices.Contains(accountPermissions[access.Role], action) {
+		return access, ErrAccountActionDenied
+	}
+	return access, nil
+}
+
+func accountAccess(ctx context.Context, q Querier, account Account, username string) (AccountAccess, error) {
+	if account.Owner == username {
+		return AccountAccess{Role: AccountRoleOwner}, nil
+	}
+
+	accountID := account.ID
+	if account.Type == AccountPocket {
+		pocket, err := q.GetPocket(ctx, account.ID)
+		if err != nil {
+			return AccountAccess{}, err
+		}
+		accountID = pocket.ParentID
+	}
+
+	member, err := q.GetAccountMember(ctx, GetAccountMemberParams{
+		AccountID: accountID,
+		Username:  username,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return AccountAccess{}, ErrNotAccountMember
+	}
+	if err != nil {
+		return AccountAccess{}, err
+	}
+	return AccountAccess{Role: member.Role, PaymentLimit: member.PaymentLimit}, nil
+}
+
+// InviteAccountMemberTx invites a user to an account and tells them through
+// a notification. A user has one pending invitation to an account at most.
+func (store *SQLStore) InviteAccountMemberTx(ctx context.Context, args CreateAccountInvitationParams) (invitation AccountInvitation, err error) {
+	ctx, span := store.tracer.Start(ctx, "InviteAccountMemberTx", trace.WithAttributes(
+		attribute.Int64("account.id", args.AccountID),
+	))
+	defer endSpan(span, &err)
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		var err error
+		invitation, err = q.CreateAccountInvitation(ctx, args)
+		if err != nil {
+			return err
+		}
+
+		payload, err := json.Marshal(map[string]interface{}{
+			"invitation_id": invitation.ID,
+			"account_id":    invitation.AccountID,
+			"role":          invitation.Role,
+			"invited_by":    invitation.InvitedBy,
+			"expires_at":    invitation.ExpiresAt.UTC(),
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateNotification(ctx, CreateNotificationParams{
+			Username: invitation.Invitee,
+			Type:     NotificationAccountInvitation,
+			Payload:  payload,
+		})
+		return err
+	})
+
+	return invitation, err
+}
+
+type AcceptAccountInvitationTxParams struct {
+	InvitationID int64     `json:"invitation_id"`
+	Now          time.Time `json:"now"`
+}
+
+type AcceptAccountInvitationTxResult struct {
+	Invitation AccountInvitation `json:"invitation"`
+	Member     AccountMember     `json:"member"`
+}
+
+// AcceptAccountInvitationTx makes the invitee a member of the account with
+// the role of the invitation, replacing the role they had. It fails with
+// ErrInvitationNotPending or ErrInvitationExpired when the invitation can't
+// be accepted anymore; the caller checks that the invitee accepts it.
+func (store *SQLStore) AcceptAccountInvitationTx(ctx context.Context, args AcceptAccountInvitationTxParams) (result AcceptAccountInvitationTxResult, err error) {
+	ctx, span := store.tracer.Start(ctx, "AcceptAccountInvitationTx", trace.WithAttributes(
+		attribute.Int64("account_invitation.id", args.InvitationID),
+	))
+	defer endSpan(span, &err)
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		invitation, err := q.GetAccountInvitationForUpdate(ctx, args.InvitationID)
+		if err != nil {
+			return err
+		}
+		if invitation.Status != InvitationPending {
+			return ErrInvitationNotPending
+		}
+		if !args.Now.Before(invitation.ExpiresAt) {
+			return ErrInvitationExpired
+		}
+
+		result.Invitation, err = q.RespondAccountInvitation(ctx, RespondAccountInvitationParams{
+			Status: InvitationAccepted,
+			ID:     invitation.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Member, err = q.UpsertAccountMember(ctx, UpsertAccountMemberParams{
+			AccountID:    invitation.AccountID,
+			Username:     invitation.Invitee,
+			Role:         invitation.Role,
+			PaymentLimit: invitation.PaymentLimit,
+			InvitedBy:    invitation.InvitedBy,
+		})
+		return err
+	})
+
+	return result, err
+}
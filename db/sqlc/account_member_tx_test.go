@@ -0,0 +1,208 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomInvitation(t *testing.T, account Account, role string, paymentLimit int64, expiresAt time.Time) AccountInvitation {
+	invitee := createRandomUser(t)
+	invitation, err := NewStore(testDB).InviteAccountMemberTx(context.Background(), CreateAccountInvitationParams{
+		AccountID:    account.ID,
+		Invitee:      invitee.Username,
+		Role:         role,
+		PaymentLimit: paymentLimit,
+		InvitedBy:    account.Owner,
+		ExpiresAt:    expiresAt,
+	})
+	require.NoError(t, err)
+	return invitation
+}
+
+func TestInviteAccountMemberTx(t *testing.T) {
+	account := createRandomAccount(t)
+	invitation := createRandomInvitation(t, account, AccountRoleViewer, 0, time.Now().Add(time.Hour))
+	require.Equal(t, InvitationPending, invitation.Status)
+	require.Equal(t, account.Owner, invitation.InvitedBy)
+	require.False(t, invitation.RespondedAt.Valid)
+
+	// the invitee is told through a notification
+	notifications, err := testQueries.ListNotifications(context.Background(), ListNotificationsParams{
+		Username: invitation.Invitee,
+		Limit:    5,
+	})
+	require.NoError(t, err)
+	require.Len(t, notifications, 1)
+	require.Equal(t, NotificationAccountInvitation, notifications[0].Type)
+
+	// one pending invitation per invitee
+	_, err = NewStore(testDB).InviteAccountMemberTx(context.Background(), CreateAccountInvitationParams{
+		AccountID: account.ID,
+		Invitee:   invitation.Invitee,
+		Role:      AccountRoleCoOwner,
+		InvitedBy: account.Owner,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.Error(t, err)
+
+	invitations, err := testQueries.ListUserInvitations(context.Background(), ListUserInvitationsParams{
+		Invitee: invitation.Invitee,
+		Now:     time.Now(),
+	})
+	require.NoError(t, err)
+	require.Len(t, invitations, 1)
+	require.Equal(t, invitation.ID, invitations[0].ID)
+}
+
+func TestAcceptAccountInvitationTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	invitation := createRandomInvitation(t, account, AccountRolePayer, 500, time.Now().Add(time.Hour))
+
+	result, err := store.AcceptAccountInvitationTx(context.Background(), AcceptAccountInvitationTxParams{
+		InvitationID: invitation.ID,
+		Now:          time.Now(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, InvitationAccepted, result.Invitation.Status)
+	require.True(t, result.Invitation.RespondedAt.Valid)
+	require.Equal(t, account.ID, result.Member.AccountID)
+	require.Equal(t, invitation.Invitee, result.Member.Username)
+	require.Equal(t, AccountRolePayer, result.Member.Role)
+	require.Equal(t, int64(500), result.Member.PaymentLimit)
+
+	// an invitation is accepted once
+	_, err = store.AcceptAccountInvitationTx(context.Background(), AcceptAccountInvitationTxParams{
+		InvitationID: invitation.ID,
+		Now:          time.Now(),
+	})
+	require.ErrorIs(t, err, ErrInvitationNotPending)
+
+	// the account is listed for the member now
+	accounts, err := testQueries.ListAccounts(context.Background(), ListAccountsParams{
+		Owner: invitation.Invitee,
+		Limit: 5,
+	})
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, account.ID, accounts[0].ID)
+
+	expired := createRandomInvitation(t, account, AccountRoleViewer, 0, time.Now().Add(-time.Minute))
+	_, err = store.AcceptAccountInvitationTx(context.Background(), AcceptAccountInvitationTxParams{
+		InvitationID: expired.ID,
+		Now:          time.Now(),
+	})
+	require.ErrorIs(t, err, ErrInvitationExpired)
+
+	_, err = testQueries.GetAccountMember(context.Background(), GetAccountMemberParams{
+		AccountID: account.ID,
+		Username:  expired.Invitee,
+	})
+	require.Error(t, err)
+}
+
+func TestAuthorizeAccount(t *testing.T) {
+	account := createRandomAccount(t)
+	pocket := createRandomPocket(t, account, 0)
+	stranger := createRandomUser(t)
+
+	members := map[string]AccountMember{}
+	for _, role := range []string{AccountRoleCoOwner, AccountRoleViewer, AccountRolePayer} {
+		user := createRandomUser(t)
+		member, err := testQueries.UpsertAccountMember(context.Background(), UpsertAccountMemberParams{
+			AccountID:    account.ID,
+			Username:     user.Username,
+			Role:         role,
+			PaymentLimit: 100,
+			InvitedBy:    account.Owner,
+		})
+		require.NoError(t, err)
+		members[role] = member
+	}
+
+	testCases := []struct {
+		name     string
+		account  Account
+		username string
+		action   string
+		err      error
+	}{
+		{"OwnerShares", account, account.Owner, AccountActionShare, nil},
+		{"CoOwnerManages", account, members[AccountRoleCoOwner].Username, AccountActionManage, nil},
+		{"CoOwnerCannotShare", account, members[AccountRoleCoOwner].Username, AccountActionShare, ErrAccountActionDenied},
+		{"ViewerViews", account, members[AccountRoleViewer].Username, AccountActionView, nil},
+		{"ViewerCannotPay", account, members[AccountRoleViewer].Username, AccountActionPay, ErrAccountActionDenied},
+		{"PayerPays", account, members[AccountRolePayer].Username, AccountActionPay, nil},
+		{"PayerCannotManage", account, members[AccountRolePayer].Username, AccountActionManage, ErrAccountActionDenied},
+		{"Stranger", account, stranger.Username, AccountActionView, ErrNotAccountMember},
+		{"PocketOfParent", pocket.Account, members[AccountRoleViewer].Username, AccountActionView, nil},
+		{"PocketStranger", pocket.Account, stranger.Username, AccountActionView, ErrNotAccountMember},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := AuthorizeAccount(context.Background(), testQueries, tc.account, tc.username, tc.action)
+			if tc.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+
+	access, err := AuthorizeAccount(context.Background(), testQueries, account, members[AccountRolePayer].Username, AccountActionPay)
+	require.NoError(t, err)
+	require.NoError(t, access.CheckPayment(100))
+	require.ErrorIs(t, access.CheckPayment(101), ErrPaymentLimitExceeded)
+}
+
+func TestCheckPayment(t *testing.T) {
+	payer := AccountAccess{Role: AccountRolePayer, PaymentLimit: 100}
+	require.NoError(t, payer.CheckPayment(1))
+	require.NoError(t, payer.CheckPayment(100))
+	require.ErrorIs(t, payer.CheckPayment(101), ErrPaymentLimitExceeded)
+	// a negative payment would take money from the payee
+	require.ErrorIs(t, payer.CheckPayment(-500), ErrPaymentAmountInvalid)
+	require.ErrorIs(t, payer.CheckPayment(0), ErrPaymentAmountInvalid)
+
+	owner := AccountAccess{Role: AccountRoleOwner}
+	require.NoError(t, owner.CheckPayment(1_000_000))
+	require.ErrorIs(t, owner.CheckPayment(-1), ErrPaymentAmountInvalid)
+}
+
+func TestPaymentsCountForInitiator(t *testing.T) {
+	account := createRandomAccount(t)
+	payee := createRandomAccount(t)
+	payer := createRandomUser(t)
+
+	for initiatedBy, amount := range map[string]int64{payer.Username: 400, account.Owner: 1_000} {
+		_, err := testQueries.CreatePayment(context.Background(), CreatePaymentParams{
+			FromAccountID: account.ID,
+			ToAccountID:   payee.ID,
+			Amount:        amount,
+			InitiatedBy:   initiatedBy,
+		})
+		require.NoError(t, err)
+	}
+
+	// the payer is held to what they sent, not to what the owner sent
+	access := AccountAccess{Role: AccountRolePayer, PaymentLimit: 500}
+	require.NoError(t, CheckPayerAllowance(context.Background(), testQueries, access, account, payer.Username, 100))
+	require.ErrorIs(t, CheckPayerAllowance(context.Background(), testQueries, access, account, payer.Username, 101), ErrPaymentLimitExceeded)
+
+	owner := AccountAccess{Role: AccountRoleOwner}
+	require.NoError(t, CheckPayerAllowance(context.Background(), testQueries, owner, account, account.Owner, 1_000_000))
+
+	for username, sent := range map[string]int64{payer.Username: 400, account.Owner: 1_000} {
+		total, err := testQueries.GetOutgoingPaymentTotal(context.Background(), GetOutgoingPaymentTotalParams{
+			InitiatedBy: username,
+			Currency:    account.Currency,
+			Since:       time.Now().Add(-time.Hour),
+		})
+		require.NoError(t, err)
+		require.Equal(t, sent, total)
+	}
+}
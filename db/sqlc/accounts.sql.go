@@ -13,7 +13,7 @@ const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts
 SET balance = balance + $1
 WHERE id = $2
-RETURNING id, created_at, updated_at, owner, balance, currency
+RETURNING id, created_at, updated_at, owner, balance, currency, frozen, type
 `
 
 type AddAccountBalanceParams struct {
@@ -31,10 +31,25 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Owner,
 		&i.Balance,
 		&i.Currency,
+		&i.Frozen,
+		&i.Type,
 	)
 	return i, err
 }
 
+const countAccounts = `-- name: CountAccounts :one
+SELECT count(*) FROM accounts
+WHERE owner = $1 AND type <> 'pocket'
+`
+
+// pockets are part of their parent account and aren't counted
+func (q *Queries) CountAccounts(ctx context.Context, owner string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAccounts, owner)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO accounts
     (
@@ -44,7 +59,7 @@ INSERT INTO accounts
     )
 VALUES
     ($1, $2, $3)
-RETURNING id, created_at, updated_at, owner, balance, currency
+RETURNING id, created_at, updated_at, owner, balance, currency, frozen, type
 `
 
 type CreateAccountParams struct {
@@ -63,6 +78,8 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Owner,
 		&i.Balance,
 		&i.Currency,
+		&i.Frozen,
+		&i.Type,
 	)
 	return i, err
 }
@@ -77,8 +94,47 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 	return err
 }
 
+const freezeOwnerAccounts = `-- name: FreezeOwnerAccounts :many
+UPDATE accounts
+SET frozen = true, updated_at = now()
+WHERE owner = $1 AND NOT frozen
+RETURNING id, created_at, updated_at, owner, balance, currency, frozen, type
+`
+
+func (q *Queries) FreezeOwnerAccounts(ctx context.Context, owner string) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, freezeOwnerAccounts, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.Frozen,
+			&i.Type,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getAccount = `-- name: GetAccount :one
-SELECT id, created_at, updated_at, owner, balance, currency FROM accounts WHERE id = $1 LIMIT 1
+SELECT id, created_at, updated_at, owner, balance, currency, frozen, type FROM accounts WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
@@ -91,12 +147,14 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Owner,
 		&i.Balance,
 		&i.Currency,
+		&i.Frozen,
+		&i.Type,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, created_at, updated_at, owner, balance, currency FROM accounts WHERE id = $1 LIMIT 1 FOR NO KEY UPDATE
+SELECT id, created_at, updated_at, owner, balance, currency, frozen, type FROM accounts WHERE id = $1 LIMIT 1 FOR NO KEY UPDATE
 `
 
 func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, error) {
@@ -109,13 +167,105 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Owner,
 		&i.Balance,
 		&i.Currency,
+		&i.Frozen,
+		&i.Type,
+	)
+	return i, err
+}
+
+const getCardSettlementAccount = `-- name: GetCardSettlementAccount :one
+SELECT id, created_at, updated_at, owner, balance, currency, frozen, type FROM accounts
+WHERE owner = '_system' AND type = 'card_settlement' AND currency = $1 LIMIT 1
+`
+
+func (q *Queries) GetCardSettlementAccount(ctx context.Context, currency string) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getCardSettlementAccount, currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.Frozen,
+		&i.Type,
+	)
+	return i, err
+}
+
+const getInterestExpenseAccount = `-- name: GetInterestExpenseAccount :one
+SELECT id, created_at, updated_at, owner, balance, currency, frozen, type FROM accounts
+WHERE owner = '_system' AND type = 'interest_expense' AND currency = $1 LIMIT 1
+`
+
+func (q *Queries) GetInterestExpenseAccount(ctx context.Context, currency string) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getInterestExpenseAccount, currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.Frozen,
+		&i.Type,
+	)
+	return i, err
+}
+
+const getLoansAccount = `-- name: GetLoansAccount :one
+SELECT id, created_at, updated_at, owner, balance, currency, frozen, type FROM accounts
+WHERE owner = '_system' AND type = 'loans' AND currency = $1 LIMIT 1
+`
+
+func (q *Queries) GetLoansAccount(ctx context.Context, currency string) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getLoansAccount, currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.Frozen,
+		&i.Type,
+	)
+	return i, err
+}
+
+const getSuspenseAccount = `-- name: GetSuspenseAccount :one
+SELECT id, created_at, updated_at, owner, balance, currency, frozen, type FROM accounts
+WHERE owner = '_system' AND type = 'suspense' AND currency = $1 LIMIT 1
+`
+
+func (q *Queries) GetSuspenseAccount(ctx context.Context, currency string) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getSuspenseAccount, currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.Frozen,
+		&i.Type,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, created_at, updated_at, owner, balance, currency FROM accounts
-WHERE owner = $1 
+SELECT id, created_at, updated_at, owner, balance, currency, frozen, type FROM accounts
+WHERE owner = $1
+  OR id IN (SELECT account_id FROM account_members WHERE username = $1)
+  OR id IN (
+    SELECT p.account_id FROM pockets p
+    JOIN account_members m ON m.account_id = p.parent_id
+    WHERE m.username = $1
+  )
 ORDER BY id
 LIMIT $2
 OFFSET $3
@@ -127,6 +277,7 @@ type ListAccountsParams struct {
 	Offset int32  `json:"offset"`
 }
 
+// the accounts of a user and those shared with them, with their pockets
 func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
 	rows, err := q.db.QueryContext(ctx, listAccounts, arg.Owner, arg.Limit, arg.Offset)
 	if err != nil {
@@ -143,6 +294,8 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Owner,
 			&i.Balance,
 			&i.Currency,
+			&i.Frozen,
+			&i.Type,
 		); err != nil {
 			return nil, err
 		}
@@ -157,21 +310,20 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 	return items, nil
 }
 
-const updateAccount = `-- name: UpdateAccount :one
+const setAccountFrozen = `-- name: SetAccountFrozen :one
 UPDATE accounts
-SET balance = $2
-WHERE id = $1
-
-RETURNING id, created_at, updated_at, owner, balance, currency
+SET frozen = $1, updated_at = now()
+WHERE id = $2
+RETURNING id, created_at, updated_at, owner, balance, currency, frozen, type
 `
 
-type UpdateAccountParams struct {
-	ID      int64 `json:"id"`
-	Balance int64 `json:"balance"`
+type SetAccountFrozenParams struct {
+	Frozen bool  `json:"frozen"`
+	ID     int64 `json:"id"`
 }
 
-func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, updateAccount, arg.ID, arg.Balance)
+func (q *Queries) SetAccountFrozen(ctx context.Context, arg SetAccountFrozenParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, setAccountFrozen, arg.Frozen, arg.ID)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -180,6 +332,8 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Owner,
 		&i.Balance,
 		&i.Currency,
+		&i.Frozen,
+		&i.Type,
 	)
 	return i, err
 }
@@ -50,23 +50,6 @@ func TestGetAccount(t *testing.T) {
 	require.WithinDuration(t, createAcc.CreatedAt, getAcc.CreatedAt, time.Second)
 }
 
-func TestUpdateAccount(t *testing.T) {
-	acc := createRandomAccount(t)
-	args := UpdateAccountParams{
-		ID:      acc.ID,
-		Balance: utils.RandomMoney(),
-	}
-	update, err := testQueries.UpdateAccount(context.Background(), args)
-
-	require.NoError(t, err)
-	require.NotEmpty(t, update)
-	require.Equal(t, acc.ID, update.ID)
-	require.Equal(t, acc.Owner, update.Owner)
-	require.Equal(t, args.Balance, update.Balance)
-	require.Equal(t, acc.Currency, update.Currency)
-	require.WithinDuration(t, acc.CreatedAt, update.CreatedAt, time.Second)
-}
-
 func TestDeleteAccount(t *testing.T) {
 	acc := createRandomAccount(t)
 	err := testQueries.DeleteAccount(context.Background(), acc.ID)
@@ -99,3 +82,26 @@ func TestListAccounts(t *testing.T) {
 		require.Equal(t, lastAccount.Owner, account.Owner)
 	}
 }
+
+func TestSetAccountFrozen(t *testing.T) {
+	acc := createRandomAccount(t)
+	require.False(t, acc.Frozen)
+
+	frozen, err := testQueries.SetAccountFrozen(context.Background(), SetAccountFrozenParams{ID: acc.ID, Frozen: true})
+	require.NoError(t, err)
+	require.True(t, frozen.Frozen)
+	require.Equal(t, acc.Balance, frozen.Balance)
+
+	unfrozen, err := testQueries.SetAccountFrozen(context.Background(), SetAccountFrozenParams{ID: acc.ID, Frozen: false})
+	require.NoError(t, err)
+	require.False(t, unfrozen.Frozen)
+}
+
+func TestGetSuspenseAccount(t *testing.T) {
+	for _, currency := range []string{"USD", "EUR", "CAD"} {
+		account, err := testQueries.GetSuspenseAccount(context.Background(), currency)
+		require.NoError(t, err)
+		require.Equal(t, SystemUsername, account.Owner)
+		require.Equal(t, currency, account.Currency)
+	}
+}
@@ -0,0 +1,253 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Reason codes of manual balance adjustments. The adjustments table only
+// accepts these.
+const (
+	ReasonCorrection = "correction"
+	ReasonFeeRefund  = "fee_refund"
+	ReasonChargeback = "chargeback"
+	ReasonGoodwill   = "goodwill"
+	ReasonWriteOff   = "write_off"
+)
+
+// AdjustmentReasons lists every valid reason code.
+var AdjustmentReasons = []string{
+	ReasonCorrection,
+	ReasonFeeRefund,
+	ReasonChargeback,
+	ReasonGoodwill,
+	ReasonWriteOff,
+}
+
+// Adjustment statuses. An adjustment is proposed as pending and ends up in
+// exactly one of the others.
+const (
+	AdjustmentPending  = "pending"
+	AdjustmentApproved = "approved"
+	AdjustmentRejected = "rejected"
+	AdjustmentExpired  = "expired"
+)
+
+// Roles of users. Only admins may propose and review adjustments. Support
+// staff review identity verifications, which admins may do as well.
+const (
+	RoleCustomer = "customer"
+	RoleSupport  = "support"
+	RoleAdmin    = "admin"
+)
+
+// SystemUsername owns the suspense accounts. It cannot log in.
+const SystemUsername = "_system"
+
+// DefaultAdjustmentTTL is how long a proposed adjustment waits for review
+// when the proposal doesn't say.
+const DefaultAdjustmentTTL = 72 * time.Hour
+
+var (
+	ErrInvalidReasonCode    = fmt.Errorf("reason code must be one of %s", strings.Join(AdjustmentReasons, ", "))
+	ErrZeroAdjustment       = errors.New("adjustment amount must not be zero")
+	ErrMissingNote          = errors.New("adjustment note is required")
+	ErrMissingEvidence      = errors.New("adjustment evidence is required")
+	ErrAdjustmentNotPending = errors.New("adjustment has already been reviewed")
+	ErrAdjustmentExpired    = errors.New("adjustment has expired")
+	ErrSelfReview           = errors.New("an adjustment must be reviewed by someone other than its proposer")
+)
+
+type ProposeAdjustmentParams struct {
+	AccountID  int64     `json:"account_id"`
+	Amount     int64     `json:"amount"`
+	ReasonCode string    `json:"reason_code"`
+	Note       string    `json:"note"`
+	Evidence   string    `json:"evidence"`
+	CreatedBy  string    `json:"created_by"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+func (args ProposeAdjustmentParams) validate() error {
+	valid := false
+	for _, reason := range AdjustmentReasons {
+		if args.ReasonCode == reason {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return ErrInvalidReasonCode
+	}
+	if args.Amount == 0 {
+		return ErrZeroAdjustment
+	}
+	if strings.TrimSpace(args.Note) == "" {
+		return ErrMissingNote
+	}
+	if strings.TrimSpace(args.Evidence) == "" {
+		return ErrMissingEvidence
+	}
+	return nil
+}
+
+// ProposeAdjustment records a pending credit (positive amount) or debit
+// (negative amount). Nothing is booked until another admin approves it with
+// ApproveAdjustmentTx before ExpiresAt, which defaults to
+// DefaultAdjustmentTTL from now.
+func (store *SQLStore) ProposeAdjustment(ctx context.Context, args ProposeAdjustmentParams) (Adjustment, error) {
+	if err := args.validate(); err != nil {
+		return Adjustment{}, err
+	}
+
+	if args.ExpiresAt.IsZero() {
+		args.ExpiresAt = time.Now().Add(DefaultAdjustmentTTL)
+	}
+
+	return store.CreateAdjustment(ctx, CreateAdjustmentParams{
+		AccountID:  args.AccountID,
+		Amount:     args.Amount,
+		ReasonCode: args.ReasonCode,
+		Note:       args.Note,
+		Evidence:   args.Evidence,
+		CreatedBy:  args.CreatedBy,
+		ExpiresAt:  args.ExpiresAt,
+	})
+}
+
+type ReviewAdjustmentTxParams struct {
+	AdjustmentID int64  `json:"adjustment_id"`
+	ReviewedBy   string `json:"reviewed_by"`
+	// Note explains a rejection. Approvals don't need one.
+	Note string `json:"note"`
+}
+
+type ApproveAdjustmentTxResult struct {
+	Adjustment      Adjustment `json:"adjustment"`
+	Account         Account    `json:"account"`
+	SuspenseAccount Account    `json:"suspense_account"`
+	Entry           Entry      `json:"entry"`
+	SuspenseEntry   Entry      `json:"suspense_entry"`
+}
+
+// ApproveAdjustmentTx books a pending adjustment: the account gets an entry
+// for the amount and the suspense account of its currency the opposite entry,
+// so the ledger stays balanced. An expired adjustment is marked as such and
+// ErrAdjustmentExpired returned.
+func (store *SQLStore) ApproveAdjustmentTx(ctx context.Context, args ReviewAdjustmentTxParams) (result ApproveAdjustmentTxResult, err error) {
+	ctx, span := store.tracer.Start(ctx, "ApproveAdjustmentTx", trace.WithAttributes(
+		attribute.Int64("adjustment.id", args.AdjustmentID),
+	))
+	defer endSpan(span, &err)
+
+	expired := false
+	err = store.execTx(ctx, func(q *Queries) error {
+		adjustment, err := lockPendingAdjustment(ctx, q, args)
+		if err != nil {
+			return err
+		}
+
+		if !adjustment.ExpiresAt.After(time.Now()) {
+			expired = true
+			result.Adjustment, err = q.MarkAdjustmentExpired(ctx, adjustment.ID)
+			return err
+		}
+
+		account, err := q.GetAccount(ctx, adjustment.AccountID)
+		if err != nil {
+			return err
+		}
+
+		suspense, err := q.GetSuspenseAccount(ctx, account.Currency)
+		if err != nil {
+			return fmt.Errorf("cannot find suspense account for %s: %w", account.Currency, err)
+		}
+
+		result.Entry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: account.ID,
+			Amount:    adjustment.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.SuspenseEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: suspense.ID,
+			Amount:    -adjustment.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		if account.ID < suspense.ID {
+			result.Account, result.SuspenseAccount, err = store.addMoney(ctx, q, account.ID, adjustment.Amount, suspense.ID, -adjustment.Amount)
+		} else {
+			result.SuspenseAccount, result.Account, err = store.addMoney(ctx, q, suspense.ID, -adjustment.Amount, account.ID, adjustment.Amount)
+		}
+		if err != nil {
+			return err
+		}
+
+		result.Adjustment, err = q.MarkAdjustmentApproved(ctx, MarkAdjustmentApprovedParams{
+			ReviewedBy:      sql.NullString{String: args.ReviewedBy, Valid: true},
+			EntryID:         sql.NullInt64{Int64: result.Entry.ID, Valid: true},
+			SuspenseEntryID: sql.NullInt64{Int64: result.SuspenseEntry.ID, Valid: true},
+			ID:              adjustment.ID,
+		})
+		return err
+	})
+	if err == nil && expired {
+		err = ErrAdjustmentExpired
+	}
+
+	return result, err
+}
+
+// RejectAdjustmentTx closes a pending adjustment without booking it.
+func (store *SQLStore) RejectAdjustmentTx(ctx context.Context, args ReviewAdjustmentTxParams) (adjustment Adjustment, err error) {
+	ctx, span := store.tracer.Start(ctx, "RejectAdjustmentTx", trace.WithAttributes(
+		attribute.Int64("adjustment.id", args.AdjustmentID),
+	))
+	defer endSpan(span, &err)
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		pending, err := lockPendingAdjustment(ctx, q, args)
+		if err != nil {
+			return err
+		}
+
+		adjustment, err = q.MarkAdjustmentRejected(ctx, MarkAdjustmentRejectedParams{
+			ReviewedBy: sql.NullString{String: args.ReviewedBy, Valid: true},
+			ReviewNote: args.Note,
+			ID:         pending.ID,
+		})
+		return err
+	})
+
+	return adjustment, err
+}
+
+// lockPendingAdjustment locks the adjustment under review and checks that it
+// can still be reviewed, and by this reviewer.
+func lockPendingAdjustment(ctx context.Context, q *Queries, args ReviewAdjustmentTxParams) (Adjustment, error) {
+	adjustment, err := q.GetAdjustmentForUpdate(ctx, args.AdjustmentID)
+	if err != nil {
+		return adjustment, err
+	}
+
+	if adjustment.Status != AdjustmentPending {
+		return adjustment, ErrAdjustmentNotPending
+	}
+	if adjustment.CreatedBy == args.ReviewedBy {
+		return adjustment, ErrSelfReview
+	}
+
+	return adjustment, nil
+}
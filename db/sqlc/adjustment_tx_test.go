@@ -0,0 +1,231 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func proposeRandomAdjustment(t *testing.T, store Store, account Account, amount int64) Adjustment {
+	args := ProposeAdjustmentParams{
+		AccountID:  account.ID,
+		Amount:     amount,
+		ReasonCode: ReasonChargeback,
+		Note:       "card dispute " + utils.RandomString(6),
+		Evidence:   "ticket " + utils.RandomString(6),
+		CreatedBy:  utils.RandomOwner(),
+	}
+
+	adjustment, err := store.ProposeAdjustment(context.Background(), args)
+	require.NoError(t, err)
+
+	require.NotZero(t, adjustment.ID)
+	require.Equal(t, AdjustmentPending, adjustment.Status)
+	require.Equal(t, args.Amount, adjustment.Amount)
+	require.Equal(t, args.ReasonCode, adjustment.ReasonCode)
+	require.Equal(t, args.Note, adjustment.Note)
+	require.Equal(t, args.Evidence, adjustment.Evidence)
+	require.Equal(t, args.CreatedBy, adjustment.CreatedBy)
+	require.False(t, adjustment.EntryID.Valid)
+	require.False(t, adjustment.ReviewedBy.Valid)
+	require.WithinDuration(t, time.Now().Add(DefaultAdjustmentTTL), adjustment.ExpiresAt, time.Minute)
+
+	return adjustment
+}
+
+func TestProposeAdjustment(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	proposeRandomAdjustment(t, store, account, -25)
+
+	// nothing is booked until the adjustment is approved
+	unchanged, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, unchanged.Balance)
+}
+
+func TestProposeAdjustmentInvalid(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	testCases := []struct {
+		name string
+		args ProposeAdjustmentParams
+		err  error
+	}{
+		{
+			name: "UnknownReason",
+			args: ProposeAdjustmentParams{AccountID: account.ID, Amount: 10, ReasonCode: "because", Note: "note", Evidence: "ticket"},
+			err:  ErrInvalidReasonCode,
+		},
+		{
+			name: "ZeroAmount",
+			args: ProposeAdjustmentParams{AccountID: account.ID, Amount: 0, ReasonCode: ReasonCorrection, Note: "note", Evidence: "ticket"},
+			err:  ErrZeroAdjustment,
+		},
+		{
+			name: "MissingNote",
+			args: ProposeAdjustmentParams{AccountID: account.ID, Amount: 10, ReasonCode: ReasonCorrection, Note: "  ", Evidence: "ticket"},
+			err:  ErrMissingNote,
+		},
+		{
+			name: "MissingEvidence",
+			args: ProposeAdjustmentParams{AccountID: account.ID, Amount: 10, ReasonCode: ReasonCorrection, Note: "note"},
+			err:  ErrMissingEvidence,
+		},
+	}
+
+	for _, tc := range testCases {
+		_, err := store.ProposeAdjustment(context.Background(), tc.args)
+		require.ErrorIs(t, err, tc.err, tc.name)
+	}
+}
+
+func TestApproveAdjustmentTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	adjustment := proposeRandomAdjustment(t, store, account, -25)
+
+	suspense, err := testQueries.GetSuspenseAccount(context.Background(), account.Currency)
+	require.NoError(t, err)
+	require.Equal(t, SystemUsername, suspense.Owner)
+
+	reviewer := utils.RandomOwner()
+	result, err := store.ApproveAdjustmentTx(context.Background(), ReviewAdjustmentTxParams{
+		AdjustmentID: adjustment.ID,
+		ReviewedBy:   reviewer,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, account.Balance-25, result.Account.Balance)
+	require.Equal(t, suspense.ID, result.SuspenseAccount.ID)
+
+	// the suspense account may have been used by concurrent tests, so only
+	// check that the entries balance out
+	require.Equal(t, account.ID, result.Entry.AccountID)
+	require.Equal(t, int64(-25), result.Entry.Amount)
+	require.Equal(t, suspense.ID, result.SuspenseEntry.AccountID)
+	require.Equal(t, int64(25), result.SuspenseEntry.Amount)
+
+	require.Equal(t, AdjustmentApproved, result.Adjustment.Status)
+	require.Equal(t, reviewer, result.Adjustment.ReviewedBy.String)
+	require.True(t, result.Adjustment.ReviewedAt.Valid)
+	require.Equal(t, result.Entry.ID, result.Adjustment.EntryID.Int64)
+	require.Equal(t, result.SuspenseEntry.ID, result.Adjustment.SuspenseEntryID.Int64)
+
+	// an adjustment is only booked once
+	_, err = store.ApproveAdjustmentTx(context.Background(), ReviewAdjustmentTxParams{
+		AdjustmentID: adjustment.ID,
+		ReviewedBy:   reviewer,
+	})
+	require.ErrorIs(t, err, ErrAdjustmentNotPending)
+}
+
+func TestApproveAdjustmentTxSelfReview(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	adjustment := proposeRandomAdjustment(t, store, account, 10)
+
+	_, err := store.ApproveAdjustmentTx(context.Background(), ReviewAdjustmentTxParams{
+		AdjustmentID: adjustment.ID,
+		ReviewedBy:   adjustment.CreatedBy,
+	})
+	require.ErrorIs(t, err, ErrSelfReview)
+
+	_, err = store.RejectAdjustmentTx(context.Background(), ReviewAdjustmentTxParams{
+		AdjustmentID: adjustment.ID,
+		ReviewedBy:   adjustment.CreatedBy,
+	})
+	require.ErrorIs(t, err, ErrSelfReview)
+
+	pending, err := testQueries.GetAdjustment(context.Background(), adjustment.ID)
+	require.NoError(t, err)
+	require.Equal(t, AdjustmentPending, pending.Status)
+}
+
+func TestApproveAdjustmentTxExpired(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	adjustment, err := store.ProposeAdjustment(context.Background(), ProposeAdjustmentParams{
+		AccountID:  account.ID,
+		Amount:     10,
+		ReasonCode: ReasonGoodwill,
+		Note:       utils.RandomString(10),
+		Evidence:   utils.RandomString(10),
+		CreatedBy:  utils.RandomOwner(),
+		ExpiresAt:  time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	_, err = store.ApproveAdjustmentTx(context.Background(), ReviewAdjustmentTxParams{
+		AdjustmentID: adjustment.ID,
+		ReviewedBy:   utils.RandomOwner(),
+	})
+	require.ErrorIs(t, err, ErrAdjustmentExpired)
+
+	expired, err := testQueries.GetAdjustment(context.Background(), adjustment.ID)
+	require.NoError(t, err)
+	require.Equal(t, AdjustmentExpired, expired.Status)
+	require.False(t, expired.EntryID.Valid)
+
+	unchanged, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, unchanged.Balance)
+}
+
+func TestRejectAdjustmentTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	adjustment := proposeRandomAdjustment(t, store, account, 10)
+
+	reviewer := utils.RandomOwner()
+	rejected, err := store.RejectAdjustmentTx(context.Background(), ReviewAdjustmentTxParams{
+		AdjustmentID: adjustment.ID,
+		ReviewedBy:   reviewer,
+		Note:         "duplicate of an earlier refund",
+	})
+	require.NoError(t, err)
+	require.Equal(t, AdjustmentRejected, rejected.Status)
+	require.Equal(t, reviewer, rejected.ReviewedBy.String)
+	require.Equal(t, "duplicate of an earlier refund", rejected.ReviewNote)
+	require.False(t, rejected.EntryID.Valid)
+
+	_, err = store.ApproveAdjustmentTx(context.Background(), ReviewAdjustmentTxParams{
+		AdjustmentID: adjustment.ID,
+		ReviewedBy:   reviewer,
+	})
+	require.ErrorIs(t, err, ErrAdjustmentNotPending)
+}
+
+func TestExpireAdjustments(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	adjustment, err := store.ProposeAdjustment(context.Background(), ProposeAdjustmentParams{
+		AccountID:  account.ID,
+		Amount:     10,
+		ReasonCode: ReasonGoodwill,
+		Note:       utils.RandomString(10),
+		Evidence:   utils.RandomString(10),
+		CreatedBy:  utils.RandomOwner(),
+		ExpiresAt:  time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+	pending := proposeRandomAdjustment(t, store, account, 10)
+
+	expired, err := testQueries.ExpireAdjustments(context.Background())
+	require.NoError(t, err)
+
+	ids := make([]int64, len(expired))
+	for i, a := range expired {
+		require.Equal(t, AdjustmentExpired, a.Status)
+		ids[i] = a.ID
+	}
+	require.Contains(t, ids, adjustment.ID)
+	require.NotContains(t, ids, pending.ID)
+}
@@ -0,0 +1,43 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Statuses of AML alerts. Open alerts are worked by analysts until they are
+// closed as explained or escalated for a suspicious activity report.
+const (
+	AMLAlertOpen      = "open"
+	AMLAlertClosed    = "closed"
+	AMLAlertEscalated = "escalated"
+)
+
+// RecordAMLAlertsTx records what a monitoring run found. Findings an alert
+// already holds are skipped, so only the new and updated alerts come back.
+func (store *SQLStore) RecordAMLAlertsTx(ctx context.Context, alerts []UpsertAMLAlertParams) (recorded []AMLAlert, err error) {
+	ctx, span := store.tracer.Start(ctx, "RecordAMLAlertsTx", trace.WithAttributes(
+		attribute.Int("aml.findings", len(alerts)),
+	))
+	defer endSpan(span, &err)
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		recorded = make([]AMLAlert, 0, len(alerts))
+		for _, alert := range alerts {
+			row, err := q.UpsertAMLAlert(ctx, alert)
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			recorded = append(recorded, row)
+		}
+		return nil
+	})
+	return recorded, err
+}
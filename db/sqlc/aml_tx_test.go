@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAMLJobRun(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	run, err := testQueries.CreateAMLJobRun(context.Background(), CreateAMLJobRunParams{
+		Scenario:    "structuring",
+		WindowStart: now.Add(-time.Hour),
+		WindowEnd:   now,
+	})
+	require.NoError(t, err)
+	require.Zero(t, run.Alerts)
+	require.False(t, run.FinishedAt.Valid)
+
+	run, err = testQueries.FinishAMLJobRun(context.Background(), FinishAMLJobRunParams{ID: run.ID, Alerts: 2, Error: "timeout"})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), run.Alerts)
+	require.Equal(t, "timeout", run.Error)
+	require.True(t, run.FinishedAt.Valid)
+
+	runs, err := testQueries.ListAMLJobRuns(context.Background(), ListAMLJobRunsParams{Limit: 5})
+	require.NoError(t, err)
+	require.NotEmpty(t, runs)
+}
+
+func TestFindStructuring(t *testing.T) {
+	from := createRandomAccount(t)
+	to := createRandomAccount(t)
+
+	// amounts no other test uses, so only these payments are found
+	const threshold = 1_000_000_000_000
+	var ids []int64
+	for _, amount := range []int64{950_000_000_000, 960_000_000_000, 970_000_000_000, threshold} {
+		payment, err := testQueries.CreatePayment(context.Background(), CreatePaymentParams{
+			FromAccountID: from.ID,
+			ToAccountID:   to.ID,
+			Amount:        amount,
+			InitiatedBy:   from.Owner,
+		})
+		require.NoError(t, err)
+		ids = append(ids, payment.ID)
+	}
+
+	rows, err := testQueries.FindStructuring(context.Background(), FindStructuringParams{
+		Since:       time.Now().Add(-time.Hour),
+		Until:       time.Now().Add(time.Hour),
+		MinAmount:   900_000_000_000,
+		Threshold:   threshold,
+		MinPayments: 3,
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, from.ID, rows[0].AccountID)
+	require.Equal(t, from.Owner, rows[0].Owner)
+	require.Equal(t, int64(3), rows[0].PaymentCount)
+	require.Equal(t, int64(2_880_000_000_000), rows[0].Total)
+	// the payment at the threshold isn't structuring
+	require.Equal(t, ids[:3], rows[0].PaymentIDs)
+
+	payments, err := testQueries.ListPaymentsByID(context.Background(), ids[:2])
+	require.NoError(t, err)
+	require.Len(t, payments, 2)
+}
+
+func TestRecordAMLAlertsTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	analyst := createRandomUser(t)
+
+	alert := func(summary string, paymentIDs ...int64) UpsertAMLAlertParams {
+		evidence, err := json.Marshal(map[string]any{"payment_ids": paymentIDs, "entry_ids": []int64{}, "summary": summary})
+		require.NoError(t, err)
+		coverage, err := json.Marshal(map[string][]int64{"payment_ids": paymentIDs, "entry_ids": {}})
+		require.NoError(t, err)
+		return UpsertAMLAlertParams{
+			Scenario:  "structuring",
+			Username:  account.Owner,
+			AccountID: account.ID,
+			Summary:   summary,
+			Evidence:  evidence,
+			Coverage:  coverage,
+		}
+	}
+
+	alerts, err := store.RecordAMLAlertsTx(context.Background(), []UpsertAMLAlertParams{alert("first", 1, 2, 3)})
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	opened := alerts[0]
+	require.Equal(t, AMLAlertOpen, opened.Status)
+
+	// the same payments don't raise it again
+	alerts, err = store.RecordAMLAlertsTx(context.Background(), []UpsertAMLAlertParams{alert("again", 2, 3)})
+	require.NoError(t, err)
+	require.Empty(t, alerts)
+
+	// more payments update the open alert
+	alerts, err = store.RecordAMLAlertsTx(context.Background(), []UpsertAMLAlertParams{alert("more", 1, 2, 3, 4)})
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	require.Equal(t, opened.ID, alerts[0].ID)
+	require.Equal(t, "more", alerts[0].Summary)
+
+	assigned, err := testQueries.AssignAMLAlert(context.Background(), AssignAMLAlertParams{
+		ID:         opened.ID,
+		AssignedTo: sql.NullString{String: analyst.Username, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, analyst.Username, assigned.AssignedTo.String)
+
+	comment, err := testQueries.CreateAMLAlertComment(context.Background(), CreateAMLAlertCommentParams{
+		AlertID: opened.ID,
+		Author:  analyst.Username,
+		Body:    "salary advances",
+	})
+	require.NoError(t, err)
+	comments, err := testQueries.ListAMLAlertComments(context.Background(), opened.ID)
+	require.NoError(t, err)
+	require.Equal(t, []AMLAlertComment{comment}, comments)
+
+	closed, err := testQueries.ResolveAMLAlert(context.Background(), ResolveAMLAlertParams{
+		ID:         opened.ID,
+		Status:     AMLAlertClosed,
+		Resolution: "salary advances",
+		ResolvedBy: sql.NullString{String: analyst.Username, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, AMLAlertClosed, closed.Status)
+	require.True(t, closed.ResolvedAt.Valid)
+
+	_, err = testQueries.ResolveAMLAlert(context.Background(), ResolveAMLAlertParams{ID: opened.ID, Status: AMLAlertEscalated})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = testQueries.AssignAMLAlert(context.Background(), AssignAMLAlertParams{ID: opened.ID})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// a closed alert isn't raised again for what it covered, but new
+	// payments open another one
+	alerts, err = store.RecordAMLAlertsTx(context.Background(), []UpsertAMLAlertParams{alert("closed", 1, 2)})
+	require.NoError(t, err)
+	require.Empty(t, alerts)
+
+	alerts, err = store.RecordAMLAlertsTx(context.Background(), []UpsertAMLAlertParams{alert("new", 4, 5)})
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	require.NotEqual(t, opened.ID, alerts[0].ID)
+}
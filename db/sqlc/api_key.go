@@ -0,0 +1,43 @@
+package db
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+// APIKeyPrefix starts every API key, which tells keys apart from access
+// tokens and lets secret scanners spot leaked keys.
+const APIKeyPrefix = "nbk_"
+
+// apiKeyIDBytes is the length of the public part of a key. The secret part
+// is as strong as a user token, so a plain SHA-256 is safe to store.
+const apiKeyIDBytes = 6
+
+// NewAPIKey generates a key of the form nbk_<id>_<secret>. prefix is the
+// nbk_<id> part, which identifies the key without revealing it.
+func NewAPIKey() (key string, prefix string, err error) {
+	id := make([]byte, apiKeyIDBytes)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+
+	secret, err := newUserToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	prefix = APIKeyPrefix + hex.EncodeToString(id)
+	return prefix + "_" + secret, prefix, nil
+}
+
+// IsAPIKey reports whether credential looks like an API key rather than an
+// access token.
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
+
+// HashAPIKey returns the form of an API key that is stored.
+func HashAPIKey(key string) string {
+	return HashUserToken(key)
+}
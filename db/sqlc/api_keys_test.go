@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomAPIKey(t *testing.T, user User) (string, APIKey) {
+	key, prefix, err := NewAPIKey()
+	require.NoError(t, err)
+	require.True(t, IsAPIKey(key))
+
+	arg := CreateAPIKeyParams{
+		Username:   user.Username,
+		Name:       "integration",
+		Prefix:     prefix,
+		KeyHash:    HashAPIKey(key),
+		Scopes:     []string{"accounts:read", "payments:write"},
+		AllowedIPs: []string{"10.0.0.0/8"},
+		ExpiresAt:  sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+
+	apiKey, err := testQueries.CreateAPIKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Prefix, apiKey.Prefix)
+	require.Equal(t, arg.Scopes, apiKey.Scopes)
+	require.Equal(t, arg.AllowedIPs, apiKey.AllowedIPs)
+	require.WithinDuration(t, arg.ExpiresAt.Time, apiKey.ExpiresAt.Time, time.Second)
+	require.False(t, apiKey.LastUsedAt.Valid)
+	require.False(t, apiKey.RevokedAt.Valid)
+
+	return key, apiKey
+}
+
+func TestGetAPIKeyByHash(t *testing.T) {
+	user := createRandomUser(t)
+	key, apiKey := createRandomAPIKey(t, user)
+
+	found, err := testQueries.GetAPIKeyByHash(context.Background(), HashAPIKey(key))
+	require.NoError(t, err)
+	require.Equal(t, apiKey.ID, found.ID)
+
+	_, err = testQueries.GetAPIKeyByHash(context.Background(), HashAPIKey(key+"x"))
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestTouchAPIKey(t *testing.T) {
+	user := createRandomUser(t)
+	_, apiKey := createRandomAPIKey(t, user)
+
+	require.NoError(t, testQueries.TouchAPIKey(context.Background(), apiKey.ID))
+	touched, err := testQueries.GetAPIKeyByHash(context.Background(), apiKey.KeyHash)
+	require.NoError(t, err)
+	require.True(t, touched.LastUsedAt.Valid)
+
+	// within a minute the time isn't written again
+	require.NoError(t, testQueries.TouchAPIKey(context.Background(), apiKey.ID))
+	again, err := testQueries.GetAPIKeyByHash(context.Background(), apiKey.KeyHash)
+	require.NoError(t, err)
+	require.Equal(t, touched.LastUsedAt.Time, again.LastUsedAt.Time)
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	user := createRandomUser(t)
+	other := createRandomUser(t)
+	_, apiKey := createRandomAPIKey(t, user)
+	createRandomAPIKey(t, user)
+
+	// only the owner can revoke a key
+	_, err := testQueries.RevokeAPIKey(context.Background(), RevokeAPIKeyParams{ID: apiKey.ID, Username: other.Username})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	revoked, err := testQueries.RevokeAPIKey(context.Background(), RevokeAPIKeyParams{ID: apiKey.ID, Username: user.Username})
+	require.NoError(t, err)
+	require.True(t, revoked.RevokedAt.Valid)
+
+	_, err = testQueries.RevokeAPIKey(context.Background(), RevokeAPIKeyParams{ID: apiKey.ID, Username: user.Username})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	keys, err := testQueries.ListAPIKeys(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	require.Equal(t, apiKey.ID, keys[0].ID)
+	require.True(t, keys[0].RevokedAt.Valid)
+	require.False(t, keys[1].RevokedAt.Valid)
+}
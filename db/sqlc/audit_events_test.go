@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomAuditEvent(t *testing.T, actor string) AuditEvent {
+	args := CreateAuditEventParams{
+		Actor:        actor,
+		Action:       "user.login",
+		Outcome:      "success",
+		ResourceType: "user",
+		ResourceID:   actor,
+		RequestID:    utils.RandomString(12),
+		ClientIp:     "127.0.0.1",
+		Metadata:     json.RawMessage(`{"reason": "test"}`),
+	}
+
+	event, err := testQueries.CreateAuditEvent(context.Background(), args)
+	require.NoError(t, err)
+	require.NotEmpty(t, event)
+
+	require.Equal(t, args.Actor, event.Actor)
+	require.Equal(t, args.Action, event.Action)
+	require.Equal(t, args.Outcome, event.Outcome)
+	require.Equal(t, args.RequestID, event.RequestID)
+	require.JSONEq(t, string(args.Metadata), string(event.Metadata))
+	require.NotZero(t, event.ID)
+	require.NotZero(t, event.CreatedAt)
+
+	return event
+}
+
+func TestCreateAuditEvent(t *testing.T) {
+	createRandomAuditEvent(t, utils.RandomOwner())
+}
+
+func TestListAuditEvents(t *testing.T) {
+	actor := utils.RandomOwner()
+	for i := 0; i < 3; i++ {
+		createRandomAuditEvent(t, actor)
+	}
+
+	events, err := testQueries.ListAuditEvents(context.Background(), ListAuditEventsParams{
+		Actor:  actor,
+		Limit:  5,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	for _, event := range events {
+		require.Equal(t, actor, event.Actor)
+	}
+}
+
+func TestAuditEventsAreAppendOnly(t *testing.T) {
+	event := createRandomAuditEvent(t, utils.RandomOwner())
+
+	_, err := testDB.Exec("UPDATE audit_events SET outcome = 'failure' WHERE id = $1", event.ID)
+	require.ErrorContains(t, err, "append-only")
+
+	_, err = testDB.Exec("DELETE FROM audit_events WHERE id = $1", event.ID)
+	require.ErrorContains(t, err, "append-only")
+}
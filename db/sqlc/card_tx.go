@@ -0,0 +1,166 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Statuses of card authorizations. An approved authorization holds its
+// amount on the account until it is captured or reversed.
+const (
+	CardAuthApproved = "approved"
+	CardAuthDeclined = "declined"
+	CardAuthCaptured = "captured"
+	CardAuthReversed = "reversed"
+)
+
+var (
+	ErrCardLimitExceeded    = errors.New("card daily limit exceeded")
+	ErrAuthorizationNotOpen = errors.New("authorization is not approved or was already settled")
+	ErrCaptureTooLarge      = errors.New("capture is more than the authorized amount")
+)
+
+type AuthorizeCardTxParams struct {
+	// Authorization is recorded as approved, with the response code and
+	// authorization code it carries.
+	Authorization CreateCardAuthorizationParams `json:"authorization"`
+	DailyLimit    int64                         `json:"daily_limit"`
+	// Since is when the current day of the daily limit started.
+	Since time.Time `json:"since"`
+}
+
+// AuthorizeCardTx approves a card authorization and holds its amount on the
+// account. It fails with ErrAccountFrozen, ErrCardLimitExceeded or
+// ErrInsufficientFunds, and records nothing, when the account is frozen,
+// the card already spent its daily limit or the balance left once the other
+// holds are taken out doesn't cover the amount.
+func (store *SQLStore) AuthorizeCardTx(ctx context.Context, args AuthorizeCardTxParams) (authorization CardAuthorization, err error) {
+	ctx, span := store.tracer.Start(ctx, "AuthorizeCardTx", trace.WithAttributes(
+		attribute.Int64("card.id", args.Authorization.CardID),
+		attribute.Int64("card_authorization.amount", args.Authorization.Amount),
+	))
+	defer endSpan(span, &err)
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		// holds on an account are checked and placed one at a time
+		account, err := q.GetAccountForUpdate(ctx, args.Authorization.AccountID)
+		if err != nil {
+			return err
+		}
+		if account.Frozen {
+			return ErrAccountFrozen
+		}
+
+		spent, err := q.GetCardSpend(ctx, GetCardSpendParams{
+			CardID: args.Authorization.CardID,
+			Since:  args.Since,
+		})
+		if err != nil {
+			return err
+		}
+		if spent+args.Authorization.Amount > args.DailyLimit {
+			return ErrCardLimitExceeded
+		}
+
+		held, err := q.GetAccountHolds(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+		if account.Balance-held < args.Authorization.Amount {
+			return ErrInsufficientFunds
+		}
+
+		params := args.Authorization
+		params.Status = CardAuthApproved
+		authorization, err = q.CreateCardAuthorization(ctx, params)
+		return err
+	})
+
+	return authorization, err
+}
+
+type CaptureCardTxParams struct {
+	AuthorizationID int64 `json:"authorization_id"`
+	// Amount may be less than the authorized amount; the rest of the hold
+	// is released.
+	Amount int64 `json:"amount"`
+}
+
+type CaptureCardTxResult struct {
+	Authorization     CardAuthorization `json:"authorization"`
+	Account           Account           `json:"account"`
+	SettlementAccount Account           `json:"settlement_account"`
+	Entry             Entry             `json:"entry"`
+	SettlementEntry   Entry             `json:"settlement_entry"`
+}
+
+// CaptureCardTx settles an approved authorization: the account is debited
+// and the card settlement account of its currency credited. A capture is
+// made even if the hold expired or the account was frozen since, as the
+// merchant was already told the payment is good.
+func (store *SQLStore) CaptureCardTx(ctx context.Context, args CaptureCardTxParams) (result CaptureCardTxResult, err error) {
+	ctx, span := store.tracer.Start(ctx, "CaptureCardTx", trace.WithAttributes(
+		attribute.Int64("card_authorization.id", args.AuthorizationID),
+		attribute.Int64("card_authorization.captured_amount", args.Amount),
+	))
+	defer endSpan(span, &err)
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		authorization, err := q.GetCardAuthorizationForUpdate(ctx, args.AuthorizationID)
+		if err != nil {
+			return err
+		}
+		if authorization.Status != CardAuthApproved {
+			return ErrAuthorizationNotOpen
+		}
+		if args.Amount > authorization.Amount {
+			return ErrCaptureTooLarge
+		}
+
+		settlement, err := q.GetCardSettlementAccount(ctx, authorization.Currency)
+		if err != nil {
+			return fmt.Errorf("cannot find card settlement account for %s: %w", authorization.Currency, err)
+		}
+
+		result.Entry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: authorization.AccountID,
+			Amount:    -args.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.SettlementEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: settlement.ID,
+			Amount:    args.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		if authorization.AccountID < settlement.ID {
+			result.Account, result.SettlementAccount, err = store.addMoney(ctx, q, authorization.AccountID, -args.Amount, settlement.ID, args.Amount)
+		} else {
+			result.SettlementAccount, result.Account, err = store.addMoney(ctx, q, settlement.ID, args.Amount, authorization.AccountID, -args.Amount)
+		}
+		if err != nil {
+			return err
+		}
+
+		result.Authorization, err = q.CaptureCardAuthorization(ctx, CaptureCardAuthorizationParams{
+			CapturedAmount:    args.Amount,
+			EntryID:           sql.NullInt64{Int64: result.Entry.ID, Valid: true},
+			SettlementEntryID: sql.NullInt64{Int64: result.SettlementEntry.ID, Valid: true},
+			ID:                authorization.ID,
+		})
+		return err
+	})
+
+	return result, err
+}
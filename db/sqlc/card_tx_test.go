@@ -0,0 +1,144 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomCard(t *testing.T, account Account) Card {
+	pan := fmt.Sprintf("4%015d", utils.RandomInt(0, 999_999_999_999_999))
+
+	card, err := testQueries.CreateCard(context.Background(), CreateCardParams{
+		AccountID:        account.ID,
+		Owner:            account.Owner,
+		PAN:              pan,
+		Last4:            pan[12:],
+		ExpiryMonth:      12,
+		ExpiryYear:       int32(time.Now().Year() + 3),
+		HashedCVV:        "hashed",
+		TransactionLimit: 5_000,
+		DailyLimit:       8_000,
+	})
+	require.NoError(t, err)
+	return card
+}
+
+func authorizeCard(card Card, amount int64) AuthorizeCardTxParams {
+	return AuthorizeCardTxParams{
+		Authorization: CreateCardAuthorizationParams{
+			CardID:       card.ID,
+			AccountID:    card.AccountID,
+			STAN:         utils.RandomString(6),
+			RRN:          utils.RandomString(12),
+			Amount:       amount,
+			Currency:     "USD",
+			Merchant:     "Coffee Shop",
+			MCC:          "5814",
+			ResponseCode: "00",
+			AuthCode:     "123456",
+			ExpiresAt:    time.Now().Add(time.Hour),
+		},
+		DailyLimit: card.DailyLimit,
+		Since:      time.Now().Add(-time.Hour),
+	}
+}
+
+func TestAuthorizeCardTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	setBalance(t, account, 6_000)
+	card := createRandomCard(t, account)
+
+	authorization, err := store.AuthorizeCardTx(context.Background(), authorizeCard(card, 4_000))
+	require.NoError(t, err)
+	require.Equal(t, CardAuthApproved, authorization.Status)
+	require.Equal(t, "00", authorization.ResponseCode)
+	require.Equal(t, int64(4_000), authorization.Amount)
+
+	held, err := testQueries.GetAccountHolds(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(4_000), held)
+
+	// the hold leaves 2000 to spend
+	_, err = store.AuthorizeCardTx(context.Background(), authorizeCard(card, 3_000))
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// and the daily limit 4000
+	setBalance(t, account, 100_000)
+	_, err = store.AuthorizeCardTx(context.Background(), authorizeCard(card, 4_001))
+	require.ErrorIs(t, err, ErrCardLimitExceeded)
+	_, err = store.AuthorizeCardTx(context.Background(), authorizeCard(card, 4_000))
+	require.NoError(t, err)
+
+	_, err = testQueries.SetAccountFrozen(context.Background(), SetAccountFrozenParams{ID: account.ID, Frozen: true})
+	require.NoError(t, err)
+	_, err = store.AuthorizeCardTx(context.Background(), authorizeCard(card, 1))
+	require.ErrorIs(t, err, ErrAccountFrozen)
+}
+
+func TestCaptureCardTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	setBalance(t, account, 10_000)
+	card := createRandomCard(t, account)
+
+	params := authorizeCard(card, 4_000)
+	params.Authorization.Currency = account.Currency
+	authorization, err := store.AuthorizeCardTx(context.Background(), params)
+	require.NoError(t, err)
+
+	settlement, err := testQueries.GetCardSettlementAccount(context.Background(), account.Currency)
+	require.NoError(t, err)
+
+	_, err = store.CaptureCardTx(context.Background(), CaptureCardTxParams{AuthorizationID: authorization.ID, Amount: 4_001})
+	require.ErrorIs(t, err, ErrCaptureTooLarge)
+
+	result, err := store.CaptureCardTx(context.Background(), CaptureCardTxParams{AuthorizationID: authorization.ID, Amount: 3_500})
+	require.NoError(t, err)
+	require.Equal(t, CardAuthCaptured, result.Authorization.Status)
+	require.Equal(t, int64(3_500), result.Authorization.CapturedAmount)
+	require.Equal(t, result.Entry.ID, result.Authorization.EntryID.Int64)
+	require.Equal(t, result.SettlementEntry.ID, result.Authorization.SettlementEntryID.Int64)
+	require.True(t, result.Authorization.CapturedAt.Valid)
+
+	require.Equal(t, int64(6_500), result.Account.Balance)
+	require.Equal(t, int64(-3_500), result.Entry.Amount)
+	require.Equal(t, settlement.ID, result.SettlementAccount.ID)
+	require.Equal(t, settlement.Balance+3_500, result.SettlementAccount.Balance)
+	require.Equal(t, int64(3_500), result.SettlementEntry.Amount)
+
+	// the rest of the hold is released
+	held, err := testQueries.GetAccountHolds(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Zero(t, held)
+
+	_, err = store.CaptureCardTx(context.Background(), CaptureCardTxParams{AuthorizationID: authorization.ID, Amount: 500})
+	require.ErrorIs(t, err, ErrAuthorizationNotOpen)
+}
+
+func TestReverseCardAuthorization(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	setBalance(t, account, 10_000)
+	card := createRandomCard(t, account)
+
+	authorization, err := store.AuthorizeCardTx(context.Background(), authorizeCard(card, 4_000))
+	require.NoError(t, err)
+
+	reversed, err := testQueries.ReverseCardAuthorization(context.Background(), authorization.ID)
+	require.NoError(t, err)
+	require.Equal(t, CardAuthReversed, reversed.Status)
+	require.True(t, reversed.ReversedAt.Valid)
+
+	held, err := testQueries.GetAccountHolds(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Zero(t, held)
+
+	_, err = store.CaptureCardTx(context.Background(), CaptureCardTxParams{AuthorizationID: authorization.ID, Amount: 4_000})
+	require.ErrorIs(t, err, ErrAuthorizationNotOpen)
+}
@@ -7,6 +7,8 @@ package db
 
 import (
 	"context"
+
+	"github.com/lib/pq"
 )
 
 const createEntry = `-- name: CreateEntry :one
@@ -96,3 +98,38 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 	}
 	return items, nil
 }
+
+const listEntriesByID = `-- name: ListEntriesByID :many
+SELECT id, created_at, updated_at, amount, account_id FROM entries
+WHERE id = ANY($1::bigint[])
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListEntriesByID(ctx context.Context, ids []int64) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listEntriesByID, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Amount,
+			&i.AccountID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,152 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Outcomes of the fraud rules for a payment.
+const (
+	FraudAllow     = "allow"
+	FraudChallenge = "challenge"
+	FraudBlock     = "block"
+)
+
+// Statuses of fraud decisions. A challenged payment is made once the user
+// confirms it, under a new decision; a blocked one waits as pending until an
+// analyst approves or rejects it.
+const (
+	FraudPassed     = "passed"
+	FraudChallenged = "challenged"
+	FraudPending    = "pending"
+	FraudApproved   = "approved"
+	FraudRejected   = "rejected"
+)
+
+var (
+	ErrFraudDecisionNotPending = errors.New("payment is not waiting for review")
+	ErrFraudSelfReview         = errors.New("payment must be reviewed by someone other than the payer")
+	ErrAccountFrozen           = errors.New("account is frozen")
+)
+
+type ReviewFraudDecisionTxParams struct {
+	ID         int64  `json:"id"`
+	ReviewedBy string `json:"reviewed_by"`
+	// Approve makes the held payment; otherwise it is dropped.
+	Approve bool `json:"approve"`
+}
+
+type ReviewFraudDecisionTxResult struct {
+	Decision FraudDecision `json:"decision"`
+	// Payment is the payment an approval made.
+	Payment *PaymentTxResult `json:"payment,omitempty"`
+}
+
+// ReviewFraudDecisionTx approves or rejects a payment the fraud rules
+// blocked. An approved payment is made as it was requested, after the checks
+// of a new payment are run again: one of the accounts may have been frozen,
+// the payer may have lost their role on the account or gone over their
+// limits, or a screening hit may have come up while the payment waited.
+func (store *SQLStore) ReviewFraudDecisionTx(ctx context.Context, args ReviewFraudDecisionTxParams) (result ReviewFraudDecisionTxResult, err error) {
+	done, err := store.track()
+	if err != nil {
+		return result, err
+	}
+	defer done()
+
+	ctx, span := store.tracer.Start(ctx, "ReviewFraudDecisionTx", trace.WithAttributes(
+		attribute.Int64("fraud.decision_id", args.ID),
+		attribute.Bool("fraud.approve", args.Approve),
+	))
+	defer endSpan(span, &err)
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		decision, err := q.GetFraudDecisionForUpdate(ctx, args.ID)
+		if err != nil {
+			return err
+		}
+		if decision.Status != FraudPending {
+			return ErrFraudDecisionNotPending
+		}
+		if decision.Username == args.ReviewedBy {
+			return ErrFraudSelfReview
+		}
+
+		review := ReviewFraudDecisionParams{
+			Status:     FraudRejected,
+			ReviewedBy: sql.NullString{String: args.ReviewedBy, Valid: true},
+			ID:         args.ID,
+		}
+		if args.Approve {
+			if err := checkHeldPayment(ctx, q, decision); err != nil {
+				return err
+			}
+
+			payment, err := store.transfer(ctx, q, PaymentTxParams{
+				FromAccountID: decision.FromAccountID,
+				ToAccountID:   decision.ToAccountID,
+				Amount:        decision.Amount,
+				InitiatedBy:   decision.Username,
+			})
+			if err != nil {
+				return err
+			}
+			result.Payment = &payment
+
+			review.Status = FraudApproved
+			review.PaymentID = sql.NullInt64{Int64: payment.Payment.ID, Valid: true}
+		}
+
+		result.Decision, err = q.ReviewFraudDecision(ctx, review)
+		return err
+	})
+	return result, err
+}
+
+// checkHeldPayment runs the checks of a new payment on a held one, as of now.
+func checkHeldPayment(ctx context.Context, q Querier, decision FraudDecision) error {
+	accounts := make([]Account, 2)
+	for i, id := range []int64{decision.FromAccountID, decision.ToAccountID} {
+		account, err := q.GetAccount(ctx, id)
+		if err != nil {
+			return err
+		}
+		if account.Frozen {
+			return ErrAccountFrozen
+		}
+		accounts[i] = account
+	}
+	from, to := accounts[0], accounts[1]
+
+	access, err := AuthorizeAccount(ctx, q, from, decision.Username, AccountActionPay)
+	if err != nil {
+		return err
+	}
+	if err := access.CheckPayment(decision.Amount); err != nil {
+		return err
+	}
+	if err := CheckPayerAllowance(ctx, q, access, from, decision.Username, decision.Amount); err != nil {
+		return err
+	}
+
+	payer, err := q.GetUser(ctx, decision.Username)
+	if err != nil {
+		return err
+	}
+	if err := CheckKYCPaymentLimit(ctx, q, payer, from.Currency, decision.Amount); err != nil {
+		return err
+	}
+
+	blocking, err := q.CountBlockingScreeningHits(ctx, []string{decision.Username, to.Owner})
+	if err != nil {
+		return err
+	}
+	if blocking > 0 {
+		return ErrScreeningHold
+	}
+	return nil
+}
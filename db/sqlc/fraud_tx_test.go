@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func createPendingFraudDecision(t *testing.T, from, to Account, amount int64) FraudDecision {
+	decision, err := testQueries.CreateFraudDecision(context.Background(), CreateFraudDecisionParams{
+		Username:      from.Owner,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        amount,
+		Score:         120,
+		Outcome:       FraudBlock,
+		Status:        FraudPending,
+		Trace:         json.RawMessage(`[{"rule":"velocity_hour","expression":"payments_last_hour >= 5","score":120,"matched":true}]`),
+		Features:      json.RawMessage(`{"payments_last_hour":7}`),
+	})
+	require.NoError(t, err)
+	require.Equal(t, FraudPending, decision.Status)
+	require.False(t, decision.PaymentID.Valid)
+
+	return decision
+}
+
+func TestReviewFraudDecisionTx(t *testing.T) {
+	store := NewStore(testDB)
+	from := createRandomAccount(t)
+	to := createRandomAccount(t)
+	analyst := createRandomUser(t)
+	amount := utils.RandomInt(1, from.Balance)
+
+	decision := createPendingFraudDecision(t, from, to, amount)
+
+	_, err := store.ReviewFraudDecisionTx(context.Background(), ReviewFraudDecisionTxParams{ID: decision.ID, ReviewedBy: from.Owner, Approve: true})
+	require.ErrorIs(t, err, ErrFraudSelfReview)
+
+	result, err := store.ReviewFraudDecisionTx(context.Background(), ReviewFraudDecisionTxParams{
+		ID:         decision.ID,
+		ReviewedBy: analyst.Username,
+		Approve:    true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, FraudApproved, result.Decision.Status)
+	require.Equal(t, analyst.Username, result.Decision.ReviewedBy.String)
+	require.NotNil(t, result.Payment)
+	require.Equal(t, amount, result.Payment.Payment.Amount)
+	require.Equal(t, result.Payment.Payment.ID, result.Decision.PaymentID.Int64)
+	require.Equal(t, from.Balance-amount, result.Payment.FromAccount.Balance)
+	require.Equal(t, to.Balance+amount, result.Payment.ToAccount.Balance)
+
+	_, err = store.ReviewFraudDecisionTx(context.Background(), ReviewFraudDecisionTxParams{ID: decision.ID, ReviewedBy: analyst.Username})
+	require.ErrorIs(t, err, ErrFraudDecisionNotPending)
+
+	// a rejected payment leaves the balances alone
+	decision = createPendingFraudDecision(t, from, to, amount)
+	result, err = store.ReviewFraudDecisionTx(context.Background(), ReviewFraudDecisionTxParams{ID: decision.ID, ReviewedBy: analyst.Username})
+	require.NoError(t, err)
+	require.Equal(t, FraudRejected, result.Decision.Status)
+	require.Nil(t, result.Payment)
+
+	account, err := testQueries.GetAccount(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance-amount, account.Balance)
+
+	// an account frozen while the payment waited stops it
+	decision = createPendingFraudDecision(t, from, to, amount)
+	_, err = testQueries.FreezeOwnerAccounts(context.Background(), to.Owner)
+	require.NoError(t, err)
+	_, err = store.ReviewFraudDecisionTx(context.Background(), ReviewFraudDecisionTxParams{ID: decision.ID, ReviewedBy: analyst.Username, Approve: true})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+}
+
+func TestReviewFraudDecisionTxChecksPayment(t *testing.T) {
+	store := NewStore(testDB)
+	from := createRandomAccount(t)
+	to := createRandomAccount(t)
+	analyst := createRandomUser(t)
+	amount := utils.RandomInt(1, from.Balance)
+
+	// a user who isn't a member of the account can't have a payment approved
+	stranger := createRandomUser(t)
+	decision, err := testQueries.CreateFraudDecision(context.Background(), CreateFraudDecisionParams{
+		Username:      stranger.Username,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        amount,
+		Score:         120,
+		Outcome:       FraudBlock,
+		Status:        FraudPending,
+		Trace:         json.RawMessage(`[]`),
+		Features:      json.RawMessage(`{}`),
+	})
+	require.NoError(t, err)
+	_, err = store.ReviewFraudDecisionTx(context.Background(), ReviewFraudDecisionTxParams{ID: decision.ID, ReviewedBy: analyst.Username, Approve: true})
+	require.ErrorIs(t, err, ErrNotAccountMember)
+
+	// a screening hit on the payee that came up while the payment waited
+	decision = createPendingFraudDecision(t, from, to, amount)
+	recordScreeningHit(t, store, to.Owner, utils.RandomOwner(), utils.RandomString(8))
+	_, err = store.ReviewFraudDecisionTx(context.Background(), ReviewFraudDecisionTxParams{ID: decision.ID, ReviewedBy: analyst.Username, Approve: true})
+	require.ErrorIs(t, err, ErrScreeningHold)
+
+	account, err := testQueries.GetAccount(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance, account.Balance)
+}
@@ -0,0 +1,295 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// KYC statuses of users. Every user starts unverified.
+const (
+	KYCUnverified = "unverified"
+	KYCPending    = "pending"
+	KYCVerified   = "verified"
+	KYCRejected   = "rejected"
+)
+
+// kycTransitions lists the statuses each KYC status can move to. Users submit
+// their profile for review, and submit again after a rejection; support
+// staff approve or reject pending submissions.
+var kycTransitions = map[string][]string{
+	KYCUnverified: {KYCPending},
+	KYCPending:    {KYCVerified, KYCRejected},
+	KYCRejected:   {KYCPending},
+}
+
+// CanTransitionKYC reports whether a user in KYC status from may move to to.
+func CanTransitionKYC(from, to string) bool {
+	return slices.Contains(kycTransitions[from], to)
+}
+
+// KYCEditable reports whether a user in status may still change their
+// profile and documents: not while they are under review or once verified.
+func KYCEditable(status string) bool {
+	return CanTransitionKYC(status, KYCPending)
+}
+
+// Kinds of KYC documents. A submission needs at least one identity document.
+const (
+	DocumentPassport       = "passport"
+	DocumentNationalID     = "national_id"
+	DocumentDrivingLicence = "driving_licence"
+	DocumentProofOfAddress = "proof_of_address"
+)
+
+// IdentityDocuments are the kinds of documents that prove who a user is.
+var IdentityDocuments = []string{
+	DocumentPassport,
+	DocumentNationalID,
+	DocumentDrivingLicence,
+}
+
+// KYCLimits are the limits of a KYC tier, with amounts in minor units. Zero
+// means no limit.
+type KYCLimits struct {
+	MaxAccounts int64 `json:"max_accounts"`
+	MaxPayment  int64 `json:"max_payment"`
+	// DailyPayments limits what a user sends per currency in 24 hours.
+	DailyPayments int64 `json:"daily_payments"`
+}
+
+// BasicKYCLimits apply to every user who isn't verified, including those
+// under review or rejected, so that a new user can try the bank out right
+// away without moving much money.
+var BasicKYCLimits = KYCLimits{
+	MaxAccounts:   1,
+	MaxPayment:    10_000,
+	DailyPayments: 25_000,
+}
+
+// KYCLimitsFor returns the limits of the tier of a user in KYC status.
+func KYCLimitsFor(status string) KYCLimits {
+	if status == KYCVerified {
+		return KYCLimits{}
+	}
+	return BasicKYCLimits
+}
+
+// KYCLimitError is returned when an action would exceed the limits of the
+// KYC tier of a user.
+type KYCLimitError struct {
+	Limit string
+	Max   int64
+}
+
+func (err *KYCLimitError) Error() string {
+	return fmt.Sprintf("verify your identity to go over the %s limit of %d", err.Limit, err.Max)
+}
+
+// CheckKYCAccountLimit makes sure user may open another account.
+func CheckKYCAccountLimit(ctx context.Context, q Querier, user User) error {
+	limits := KYCLimitsFor(user.KYCStatus)
+	if limits.MaxAccounts == 0 {
+		return nil
+	}
+
+	count, err := q.CountAccounts(ctx, user.Username)
+	if err != nil {
+		return err
+	}
+	if count >= limits.MaxAccounts {
+		return &KYCLimitError{Limit: "account", Max: limits.MaxAccounts}
+	}
+	return nil
+}
+
+// CheckKYCPaymentLimit makes sure user may send amount in currency. The daily
+// limit is checked against the payments the user already sent, from their own
+// accounts or the ones shared with them, so concurrent payments may together
+// go over it by up to one payment.
+func CheckKYCPaymentLimit(ctx context.Context, q Querier, user User, currency string, amount int64) error {
+	limits := KYCLimitsFor(user.KYCStatus)
+	if limits.MaxPayment > 0 && amount > limits.MaxPayment {
+		return &KYCLimitError{Limit: "payment", Max: limits.MaxPayment}
+	}
+	if limits.DailyPayments == 0 {
+		return nil
+	}
+
+	sent, err := q.GetOutgoingPaymentTotal(ctx, GetOutgoingPaymentTotalParams{
+		InitiatedBy: user.Username,
+		Currency:    currency,
+		Since:       time.Now().Add(-24 * time.Hour),
+	})
+	if err != nil {
+		return err
+	}
+	if sent+amount > limits.DailyPayments {
+		return &KYCLimitError{Limit: "daily payment", Max: limits.DailyPayments}
+	}
+	return nil
+}
+
+var (
+	ErrKYCNotEditable = errors.New("identity verification can't be changed while it is under review or once verified")
+	ErrKYCIncomplete  = errors.New("identity verification needs a profile and an identity document")
+	ErrKYCNotPending  = errors.New("identity verification isn't waiting for review")
+	ErrKYCSelfReview  = errors.New("identity verification must be reviewed by someone else")
+)
+
+// lockEditableKYC locks the user and checks that they may change their KYC
+// profile and documents, so that nothing changes under a submission.
+func lockEditableKYC(ctx context.Context, q *Queries, username string) (User, error) {
+	user, err := q.GetUserForUpdate(ctx, username)
+	if err != nil {
+		return user, err
+	}
+	if !KYCEditable(user.KYCStatus) {
+		return user, ErrKYCNotEditable
+	}
+	return user, nil
+}
+
+// SaveKYCProfileTx creates or replaces the KYC profile of a user who may
+// still change it.
+func (store *SQLStore) SaveKYCProfileTx(ctx context.Context, args UpsertKYCProfileParams) (profile KYCProfile, err error) {
+	ctx, span := store.tracer.Start(ctx, "SaveKYCProfileTx", trace.WithAttributes(
+		attribute.String("user.username", args.Username),
+	))
+	defer endSpan(span, &err)
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		if _, err := lockEditableKYC(ctx, q, args.Username); err != nil {
+			return err
+		}
+
+		profile, err = q.UpsertKYCProfile(ctx, args)
+		return err
+	})
+	return profile, err
+}
+
+// AddKYCDocumentTx records a document the user uploaded to blob storage.
+func (store *SQLStore) AddKYCDocumentTx(ctx context.Context, args CreateKYCDocumentParams) (document KYCDocument, err error) {
+	ctx, span := store.tracer.Start(ctx, "AddKYCDocumentTx", trace.WithAttributes(
+		attribute.String("user.username", args.Username),
+		attribute.String("kyc.document_kind", args.Kind),
+	))
+	defer endSpan(span, &err)
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		if _, err := lockEditableKYC(ctx, q, args.Username); err != nil {
+			return err
+		}
+
+		document, err = q.CreateKYCDocument(ctx, args)
+		return err
+	})
+	return document, err
+}
+
+type KYCTxResult struct {
+	User    User       `json:"user"`
+	Profile KYCProfile `json:"profile"`
+}
+
+// SubmitKYCTx puts the KYC profile of a user in the review queue. The user
+// needs a profile and at least one identity document.
+func (store *SQLStore) SubmitKYCTx(ctx context.Context, username string) (result KYCTxResult, err error) {
+	ctx, span := store.tracer.Start(ctx, "SubmitKYCTx", trace.WithAttributes(
+		attribute.String("user.username", username),
+	))
+	defer endSpan(span, &err)
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		if _, err := lockEditableKYC(ctx, q, username); err != nil {
+			return err
+		}
+
+		if _, err := q.GetKYCProfile(ctx, username); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrKYCIncomplete
+			}
+			return err
+		}
+
+		documents, err := q.ListKYCDocuments(ctx, username)
+		if err != nil {
+			return err
+		}
+		if !slices.ContainsFunc(documents, func(document KYCDocument) bool {
+			return slices.Contains(IdentityDocuments, document.Kind)
+		}) {
+			return ErrKYCIncomplete
+		}
+
+		result.Profile, err = q.SubmitKYCProfile(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		result.User, err = q.SetKYCStatus(ctx, SetKYCStatusParams{
+			KYCStatus: KYCPending,
+			Username:  username,
+		})
+		return err
+	})
+	return result, err
+}
+
+type ReviewKYCTxParams struct {
+	Username   string `json:"username"`
+	ReviewedBy string `json:"reviewed_by"`
+	Approve    bool   `json:"approve"`
+	// Reason tells a rejected user what to fix before submitting again.
+	Reason string `json:"reason"`
+}
+
+// ReviewKYCTx verifies or rejects a pending KYC submission.
+func (store *SQLStore) ReviewKYCTx(ctx context.Context, args ReviewKYCTxParams) (result KYCTxResult, err error) {
+	ctx, span := store.tracer.Start(ctx, "ReviewKYCTx", trace.WithAttributes(
+		attribute.String("user.username", args.Username),
+		attribute.Bool("kyc.approve", args.Approve),
+	))
+	defer endSpan(span, &err)
+
+	status := KYCRejected
+	if args.Approve {
+		status = KYCVerified
+	}
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		user, err := q.GetUserForUpdate(ctx, args.Username)
+		if err != nil {
+			return err
+		}
+		if !CanTransitionKYC(user.KYCStatus, status) {
+			return ErrKYCNotPending
+		}
+		if user.Username == args.ReviewedBy {
+			return ErrKYCSelfReview
+		}
+
+		result.Profile, err = q.ReviewKYCProfile(ctx, ReviewKYCProfileParams{
+			ReviewedBy:      sql.NullString{String: args.ReviewedBy, Valid: true},
+			RejectionReason: sql.NullString{String: args.Reason, Valid: !args.Approve},
+			Username:        args.Username,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.User, err = q.SetKYCStatus(ctx, SetKYCStatusParams{
+			KYCStatus: status,
+			Username:  args.Username,
+		})
+		return err
+	})
+	return result, err
+}
@@ -0,0 +1,149 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func saveRandomKYCProfile(t *testing.T, store Store, user User) KYCProfile {
+	arg := UpsertKYCProfileParams{
+		Username:    user.Username,
+		LegalName:   user.FullName,
+		DateOfBirth: time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC),
+		Nationality: "RO",
+		AddressLine: "1 Main Street",
+		City:        "Bucharest",
+		PostalCode:  "010011",
+		Country:     "RO",
+	}
+
+	profile, err := store.SaveKYCProfileTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.LegalName, profile.LegalName)
+	require.True(t, arg.DateOfBirth.Equal(profile.DateOfBirth))
+	require.False(t, profile.SubmittedAt.Valid)
+
+	return profile
+}
+
+func addRandomKYCDocument(t *testing.T, store Store, user User, kind string) (KYCDocument, error) {
+	return store.AddKYCDocumentTx(context.Background(), CreateKYCDocumentParams{
+		Username:    user.Username,
+		Kind:        kind,
+		StorageKey:  "kyc/" + user.Username + "/" + utils.RandomString(12),
+		ContentType: "image/png",
+		Size:        utils.RandomInt(100, 1000),
+		SHA256:      utils.RandomString(64),
+	})
+}
+
+func TestKYCTransitions(t *testing.T) {
+	require.True(t, CanTransitionKYC(KYCUnverified, KYCPending))
+	require.True(t, CanTransitionKYC(KYCPending, KYCVerified))
+	require.True(t, CanTransitionKYC(KYCPending, KYCRejected))
+	require.True(t, CanTransitionKYC(KYCRejected, KYCPending))
+	require.False(t, CanTransitionKYC(KYCUnverified, KYCVerified))
+	require.False(t, CanTransitionKYC(KYCVerified, KYCPending))
+	require.False(t, CanTransitionKYC(KYCRejected, KYCVerified))
+
+	require.True(t, KYCEditable(KYCUnverified))
+	require.True(t, KYCEditable(KYCRejected))
+	require.False(t, KYCEditable(KYCPending))
+	require.False(t, KYCEditable(KYCVerified))
+}
+
+func TestKYCWorkflow(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	reviewer := createRandomUser(t)
+	require.Equal(t, KYCUnverified, user.KYCStatus)
+
+	// a profile alone isn't enough, nor is a proof of address
+	_, err := store.SubmitKYCTx(context.Background(), user.Username)
+	require.ErrorIs(t, err, ErrKYCIncomplete)
+	saveRandomKYCProfile(t, store, user)
+	_, err = addRandomKYCDocument(t, store, user, DocumentProofOfAddress)
+	require.NoError(t, err)
+	_, err = store.SubmitKYCTx(context.Background(), user.Username)
+	require.ErrorIs(t, err, ErrKYCIncomplete)
+
+	_, err = addRandomKYCDocument(t, store, user, DocumentPassport)
+	require.NoError(t, err)
+	result, err := store.SubmitKYCTx(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, KYCPending, result.User.KYCStatus)
+	require.True(t, result.Profile.SubmittedAt.Valid)
+
+	// nothing changes under review
+	_, err = addRandomKYCDocument(t, store, user, DocumentNationalID)
+	require.ErrorIs(t, err, ErrKYCNotEditable)
+	_, err = store.SubmitKYCTx(context.Background(), user.Username)
+	require.ErrorIs(t, err, ErrKYCNotEditable)
+
+	_, err = store.ReviewKYCTx(context.Background(), ReviewKYCTxParams{Username: user.Username, ReviewedBy: user.Username, Approve: true})
+	require.ErrorIs(t, err, ErrKYCSelfReview)
+
+	result, err = store.ReviewKYCTx(context.Background(), ReviewKYCTxParams{
+		Username:   user.Username,
+		ReviewedBy: reviewer.Username,
+		Reason:     "the passport photo is blurry",
+	})
+	require.NoError(t, err)
+	require.Equal(t, KYCRejected, result.User.KYCStatus)
+	require.Equal(t, "the passport photo is blurry", result.Profile.RejectionReason.String)
+
+	// a rejected user fixes their documents and submits again
+	_, err = addRandomKYCDocument(t, store, user, DocumentPassport)
+	require.NoError(t, err)
+	result, err = store.SubmitKYCTx(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.False(t, result.Profile.RejectionReason.Valid)
+
+	result, err = store.ReviewKYCTx(context.Background(), ReviewKYCTxParams{Username: user.Username, ReviewedBy: reviewer.Username, Approve: true})
+	require.NoError(t, err)
+	require.Equal(t, KYCVerified, result.User.KYCStatus)
+	require.Equal(t, reviewer.Username, result.Profile.ReviewedBy.String)
+
+	_, err = store.ReviewKYCTx(context.Background(), ReviewKYCTxParams{Username: user.Username, ReviewedBy: reviewer.Username})
+	require.ErrorIs(t, err, ErrKYCNotPending)
+
+	documents, err := testQueries.ListKYCDocuments(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, documents, 3)
+}
+
+func TestKYCLimits(t *testing.T) {
+	account := createRandomAccount(t)
+	other := createRandomAccount(t)
+	user, err := testQueries.GetUser(context.Background(), account.Owner)
+	require.NoError(t, err)
+
+	var limitErr *KYCLimitError
+	require.ErrorAs(t, CheckKYCAccountLimit(context.Background(), testQueries, user), &limitErr)
+	require.Equal(t, BasicKYCLimits.MaxAccounts, limitErr.Max)
+
+	err = CheckKYCPaymentLimit(context.Background(), testQueries, user, account.Currency, BasicKYCLimits.MaxPayment+1)
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, BasicKYCLimits.MaxPayment, limitErr.Max)
+
+	_, err = testQueries.CreatePayment(context.Background(), CreatePaymentParams{
+		FromAccountID: account.ID,
+		ToAccountID:   other.ID,
+		Amount:        BasicKYCLimits.DailyPayments - 100,
+		InitiatedBy:   user.Username,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, CheckKYCPaymentLimit(context.Background(), testQueries, user, account.Currency, 100))
+	err = CheckKYCPaymentLimit(context.Background(), testQueries, user, account.Currency, 101)
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, BasicKYCLimits.DailyPayments, limitErr.Max)
+
+	user.KYCStatus = KYCVerified
+	require.NoError(t, CheckKYCAccountLimit(context.Background(), testQueries, user))
+	require.NoError(t, CheckKYCPaymentLimit(context.Background(), testQueries, user, account.Currency, BasicKYCLimits.DailyPayments))
+}
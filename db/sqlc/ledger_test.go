@@ -0,0 +1,63 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListLedgerMismatches(t *testing.T) {
+	// createRandomAccount sets a balance without booking an entry, so the
+	// account shows up with only the entry booked here
+	account := createRandomAccount(t)
+
+	_, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{
+		AccountID: account.ID,
+		Amount:    1,
+	})
+	require.NoError(t, err)
+
+	mismatches, err := testQueries.ListLedgerMismatches(context.Background())
+	require.NoError(t, err)
+
+	var found *ListLedgerMismatchesRow
+	for i := range mismatches {
+		if mismatches[i].ID == account.ID {
+			found = &mismatches[i]
+		}
+	}
+	require.NotNil(t, found)
+	require.Equal(t, account.Balance, found.Balance)
+	require.Equal(t, int64(1), found.EntriesTotal)
+}
+
+func TestGetLedgerTotals(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	adjustment, err := store.ProposeAdjustment(context.Background(), ProposeAdjustmentParams{
+		AccountID:  account.ID,
+		Amount:     7,
+		ReasonCode: ReasonGoodwill,
+		Note:       utils.RandomString(10),
+		Evidence:   utils.RandomString(10),
+		CreatedBy:  utils.RandomOwner(),
+	})
+	require.NoError(t, err)
+
+	before, err := testQueries.GetLedgerTotals(context.Background())
+	require.NoError(t, err)
+
+	_, err = store.ApproveAdjustmentTx(context.Background(), ReviewAdjustmentTxParams{
+		AdjustmentID: adjustment.ID,
+		ReviewedBy:   utils.RandomOwner(),
+	})
+	require.NoError(t, err)
+
+	// the suspense entry offsets the adjustment, so the totals don't move
+	after, err := testQueries.GetLedgerTotals(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, before, after)
+}
@@ -0,0 +1,510 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Amortization methods of loans.
+const (
+	LoanAnnuity = "annuity"
+	LoanLinear  = "linear"
+)
+
+// Loan statuses.
+const (
+	LoanActive  = "active"
+	LoanPaidOff = "paid_off"
+)
+
+// Installment statuses. A scheduled installment becomes overdue when it is
+// still unpaid after the grace days, and cancelled when an early repayment
+// replaces it.
+const (
+	InstallmentScheduled = "scheduled"
+	InstallmentOverdue   = "overdue"
+	InstallmentPaid      = "paid"
+	InstallmentCancelled = "cancelled"
+)
+
+// Kinds of loan repayments.
+const (
+	RepaymentScheduled = "scheduled"
+	RepaymentEarly     = "early"
+)
+
+var (
+	ErrLoanClosed           = errors.New("loan is paid off")
+	ErrLoanInArrears        = errors.New("loan has installments due that must be paid first")
+	ErrRepaymentTooLarge    = errors.New("repayment is more than the outstanding principal")
+	ErrInsufficientFunds    = errors.New("account balance is too low")
+	ErrInstallmentSettled   = errors.New("installment is already paid or cancelled")
+	ErrInstallmentNotDueYet = errors.New("installment is not due yet")
+	ErrLoanLimitExceeded    = errors.New("loan would take the borrower over the maximum principal of the product")
+	ErrBorrowerInArrears    = errors.New("borrower has installments due that must be paid first")
+)
+
+// ScheduledInstallment is an installment of an amortization schedule.
+type ScheduledInstallment struct {
+	Period    int32     `json:"period"`
+	DueDate   time.Time `json:"due_date"`
+	Principal int64     `json:"principal"`
+	Interest  int64     `json:"interest"`
+}
+
+// LoanScheduler amortizes the outstanding principal of a loan over count
+// installments from period first.
+type LoanScheduler func(loan Loan, first, count int32) ([]ScheduledInstallment, error)
+
+type OriginateLoanTxParams struct {
+	Loan     CreateLoanParams `json:"loan"`
+	Schedule LoanScheduler    `json:"-"`
+}
+
+type OriginateLoanTxResult struct {
+	Loan         Loan              `json:"loan"`
+	Installments []LoanInstallment `json:"installments"`
+	Account      Account           `json:"account"`
+	LoansAccount Account           `json:"loans_account"`
+	Entry        Entry             `json:"entry"`
+	FundingEntry Entry             `json:"funding_entry"`
+}
+
+// OriginateLoanTx records a loan with its schedule and disburses the
+// principal: the borrower's account is credited and the loan book of its
+// currency debited. A borrower in arrears on any loan can't borrow, and what
+// they owe under a product, the new loan included, is capped by its maximum
+// principal.
+func (store *SQLStore) OriginateLoanTx(ctx context.Context, args OriginateLoanTxParams) (result OriginateLoanTxResult, err error) {
+	ctx, span := store.tracer.Start(ctx, "OriginateLoanTx", trace.WithAttributes(
+		attribute.Int64("account.id", args.Loan.AccountID),
+		attribute.Int64("loan.principal", args.Loan.Principal),
+	))
+	defer endSpan(span, &err)
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		// locking the borrower keeps their loans from being taken out side by
+		// side past the cap
+		if _, err := q.GetUserForUpdate(ctx, args.Loan.Borrower); err != nil {
+			return err
+		}
+		product, err := q.GetLoanProduct(ctx, args.Loan.ProductID)
+		if err != nil {
+			return err
+		}
+		exposure, err := q.GetBorrowerLoanExposure(ctx, GetBorrowerLoanExposureParams{
+			ProductID: product.ID,
+			DueBefore: args.Loan.DisbursedOn,
+			Borrower:  args.Loan.Borrower,
+		})
+		if err != nil {
+			return err
+		}
+		if exposure.LoansInArrears > 0 {
+			return ErrBorrowerInArrears
+		}
+		if exposure.OutstandingPrincipal+args.Loan.Principal > product.MaxPrincipal {
+			return ErrLoanLimitExceeded
+		}
+
+		account, err := q.GetAccountForUpdate(ctx, args.Loan.AccountID)
+		if err != nil {
+			return err
+		}
+		if account.Frozen {
+			return ErrAccountFrozen
+		}
+
+		book, err := q.GetLoansAccount(ctx, account.Currency)
+		if err != nil {
+			return fmt.Errorf("cannot find loans account for %s: %w", account.Currency, err)
+		}
+
+		loan, err := q.CreateLoan(ctx, args.Loan)
+		if err != nil {
+			return err
+		}
+
+		schedule, err := args.Schedule(loan, 1, loan.TermMonths)
+		if err != nil {
+			return err
+		}
+		result.Installments, err = createInstallments(ctx, q, loan.ID, schedule)
+		if err != nil {
+			return err
+		}
+
+		result.Entry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: account.ID,
+			Amount:    loan.Principal,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.FundingEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: book.ID,
+			Amount:    -loan.Principal,
+		})
+		if err != nil {
+			return err
+		}
+
+		if account.ID < book.ID {
+			result.Account, result.LoansAccount, err = store.addMoney(ctx, q, account.ID, loan.Principal, book.ID, -loan.Principal)
+		} else {
+			result.LoansAccount, result.Account, err = store.addMoney(ctx, q, book.ID, -loan.Principal, account.ID, loan.Principal)
+		}
+		if err != nil {
+			return err
+		}
+
+		result.Loan, err = q.SetLoanDisbursement(ctx, SetLoanDisbursementParams{
+			DisbursementEntryID: sql.NullInt64{Int64: result.Entry.ID, Valid: true},
+			FundingEntryID:      sql.NullInt64{Int64: result.FundingEntry.ID, Valid: true},
+			ID:                  loan.ID,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+type CollectInstallmentTxParams struct {
+	InstallmentID int64     `json:"installment_id"`
+	Today         time.Time `json:"today"`
+}
+
+type CollectInstallmentTxResult struct {
+	Installment LoanInstallment `json:"installment"`
+	Loan        Loan            `json:"loan"`
+	// Repayment, and the borrower's Account and the Entry debiting it, are
+	// empty when nothing could be collected.
+	Repayment  LoanRepayment `json:"repayment"`
+	Account    Account       `json:"account"`
+	Entry      Entry         `json:"entry"`
+	FeeCharged bool          `json:"fee_charged"`
+}
+
+// CollectInstallmentTx collects what is owed on an installment due by Today
+// from the borrower's account, as much as its balance less the card holds
+// allows: the late fee first, then the interest and the principal. An
+// installment still unpaid after the grace days of the loan becomes overdue
+// and is charged the late fee, once. The loan is paid off with its last installment.
+func (store *SQLStore) CollectInstallmentTx(ctx context.Context, args CollectInstallmentTxParams) (result CollectInstallmentTxResult, err error) {
+	ctx, span := store.tracer.Start(ctx, "CollectInstallmentTx", trace.WithAttributes(
+		attribute.Int64("loan_installment.id", args.InstallmentID),
+	))
+	defer endSpan(span, &err)
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		installment, err := q.GetLoanInstallment(ctx, args.InstallmentID)
+		if err != nil {
+			return err
+		}
+
+		// the loan is locked first, like early repayments do
+		loan, err := q.GetLoanForUpdate(ctx, installment.LoanID)
+		if err != nil {
+			return err
+		}
+
+		installment, err = q.GetLoanInstallmentForUpdate(ctx, installment.ID)
+		if err != nil {
+			return err
+		}
+		if installment.Status != InstallmentScheduled && installment.Status != InstallmentOverdue {
+			return ErrInstallmentSettled
+		}
+		if installment.DueDate.After(args.Today) {
+			return ErrInstallmentNotDueYet
+		}
+
+		account, err := q.GetAccountForUpdate(ctx, loan.AccountID)
+		if err != nil {
+			return err
+		}
+		// what approved card authorizations hold is kept for their capture
+		held, err := q.GetAccountHolds(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+
+		update := UpdateLoanInstallmentParams{
+			ID:            installment.ID,
+			Fee:           installment.Fee,
+			PaidPrincipal: installment.PaidPrincipal,
+			PaidInterest:  installment.PaidInterest,
+			PaidFee:       installment.PaidFee,
+			Status:        installment.Status,
+		}
+
+		owed := installmentOwed(installment)
+		if collect := min(owed, account.Balance-held); collect > 0 && !account.Frozen {
+			fee := min(collect, installment.Fee-installment.PaidFee)
+			interest := min(collect-fee, installment.Interest-installment.PaidInterest)
+			principal := collect - fee - interest
+
+			booked, err := store.bookRepayment(ctx, q, loan, account, LoanRepayment{
+				InstallmentID: sql.NullInt64{Int64: installment.ID, Valid: true},
+				Kind:          RepaymentScheduled,
+				Amount:        collect,
+				Principal:     principal,
+				Interest:      interest,
+				Fee:           fee,
+			})
+			if err != nil {
+				return err
+			}
+			loan = booked.loan
+			result.Repayment, result.Account, result.Entry = booked.repayment, booked.account, booked.entry
+
+			update.PaidFee += fee
+			update.PaidInterest += interest
+			update.PaidPrincipal += principal
+			owed -= collect
+		}
+
+		switch {
+		case owed == 0:
+			update.Status = InstallmentPaid
+			update.PaidAt = sql.NullTime{Time: time.Now(), Valid: true}
+		case args.Today.After(installment.DueDate.AddDate(0, 0, int(loan.GraceDays))):
+			if update.Status != InstallmentOverdue {
+				update.Status = InstallmentOverdue
+				update.Fee += loan.LateFee
+				result.FeeCharged = loan.LateFee > 0
+			}
+		}
+
+		result.Installment, err = q.UpdateLoanInstallment(ctx, update)
+		if err != nil {
+			return err
+		}
+
+		result.Loan = loan
+		if update.Status != InstallmentPaid {
+			return nil
+		}
+		unpaid, err := q.CountUnpaidLoanInstallments(ctx, loan.ID)
+		if err != nil || unpaid > 0 {
+			return err
+		}
+		result.Loan, err = q.CloseLoan(ctx, loan.ID)
+		return err
+	})
+
+	return result, err
+}
+
+type RepayLoanEarlyTxParams struct {
+	LoanID int64     `json:"loan_id"`
+	Amount int64     `json:"amount"`
+	Today  time.Time `json:"today"`
+	// Schedule amortizes the principal left over the installments that
+	// weren't due yet.
+	Schedule LoanScheduler `json:"-"`
+}
+
+type RepayLoanEarlyTxResult struct {
+	Loan         Loan              `json:"loan"`
+	Repayment    LoanRepayment     `json:"repayment"`
+	Installments []LoanInstallment `json:"installments"`
+	// Account is the borrower's account and Entry the one debiting it.
+	Account Account `json:"account"`
+	Entry   Entry   `json:"entry"`
+}
+
+// RepayLoanEarlyTx repays part or all of the outstanding principal of a loan
+// from the borrower's account. The installments that aren't due yet are
+// cancelled and the principal left is amortized again over the same
+// periods, so the term stays and the installments shrink. Installments that
+// are due must be paid first.
+func (store *SQLStore) RepayLoanEarlyTx(ctx context.Context, args RepayLoanEarlyTxParams) (result RepayLoanEarlyTxResult, err error) {
+	ctx, span := store.tracer.Start(ctx, "RepayLoanEarlyTx", trace.WithAttributes(
+		attribute.Int64("loan.id", args.LoanID),
+		attribute.Int64("loan.repayment", args.Amount),
+	))
+	defer endSpan(span, &err)
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		loan, err := q.GetLoanForUpdate(ctx, args.LoanID)
+		if err != nil {
+			return err
+		}
+		if loan.Status != LoanActive {
+			return ErrLoanClosed
+		}
+		if args.Amount > loan.OutstandingPrincipal {
+			return ErrRepaymentTooLarge
+		}
+
+		arrears, err := q.GetLoanArrears(ctx, GetLoanArrearsParams{
+			LoanID:    loan.ID,
+			DueBefore: args.Today.AddDate(0, 0, 1),
+		})
+		if err != nil {
+			return err
+		}
+		if arrears.Installments > 0 {
+			return ErrLoanInArrears
+		}
+
+		account, err := q.GetAccountForUpdate(ctx, loan.AccountID)
+		if err != nil {
+			return err
+		}
+		if account.Frozen {
+			return ErrAccountFrozen
+		}
+		held, err := q.GetAccountHolds(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+		if account.Balance-held < args.Amount {
+			return ErrInsufficientFunds
+		}
+
+		booked, err := store.bookRepayment(ctx, q, loan, account, LoanRepayment{
+			Kind:      RepaymentEarly,
+			Amount:    args.Amount,
+			Principal: args.Amount,
+		})
+		if err != nil {
+			return err
+		}
+		loan = booked.loan
+		result.Repayment, result.Account, result.Entry = booked.repayment, booked.account, booked.entry
+
+		installments, err := q.ListLoanInstallments(ctx, loan.ID)
+		if err != nil {
+			return err
+		}
+		first, count := int32(0), int32(0)
+		for _, installment := range installments {
+			if installment.Status != InstallmentScheduled {
+				continue
+			}
+			if count == 0 {
+				first = installment.Period
+			}
+			count++
+		}
+
+		err = q.CancelLoanInstallments(ctx, loan.ID)
+		if err != nil {
+			return err
+		}
+
+		result.Loan = loan
+		if loan.OutstandingPrincipal == 0 {
+			result.Loan, err = q.CloseLoan(ctx, loan.ID)
+			return err
+		}
+
+		schedule, err := args.Schedule(loan, first, count)
+		if err != nil {
+			return err
+		}
+		result.Installments, err = createInstallments(ctx, q, loan.ID, schedule)
+		return err
+	})
+
+	return result, err
+}
+
+// bookedRepayment is what booking a repayment changed.
+type bookedRepayment struct {
+	repayment LoanRepayment
+	loan      Loan
+	account   Account
+	entry     Entry
+}
+
+// bookRepayment moves a repayment from the borrower's account to the loan
+// book of its currency and records it against the loan.
+func (store *SQLStore) bookRepayment(ctx context.Context, q *Queries, loan Loan, account Account, repayment LoanRepayment) (booked bookedRepayment, err error) {
+	booked.loan = loan
+	book, err := q.GetLoansAccount(ctx, account.Currency)
+	if err != nil {
+		return booked, fmt.Errorf("cannot find loans account for %s: %w", account.Currency, err)
+	}
+
+	booked.entry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: account.ID,
+		Amount:    -repayment.Amount,
+	})
+	if err != nil {
+		return booked, err
+	}
+
+	loanEntry, err := q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: book.ID,
+		Amount:    repayment.Amount,
+	})
+	if err != nil {
+		return booked, err
+	}
+
+	if account.ID < book.ID {
+		booked.account, _, err = store.addMoney(ctx, q, account.ID, -repayment.Amount, book.ID, repayment.Amount)
+	} else {
+		_, booked.account, err = store.addMoney(ctx, q, book.ID, repayment.Amount, account.ID, -repayment.Amount)
+	}
+	if err != nil {
+		return booked, err
+	}
+
+	booked.repayment, err = q.CreateLoanRepayment(ctx, CreateLoanRepaymentParams{
+		LoanID:        loan.ID,
+		InstallmentID: repayment.InstallmentID,
+		Kind:          repayment.Kind,
+		Amount:        repayment.Amount,
+		Principal:     repayment.Principal,
+		Interest:      repayment.Interest,
+		Fee:           repayment.Fee,
+		EntryID:       booked.entry.ID,
+		LoanEntryID:   loanEntry.ID,
+	})
+	if err != nil {
+		return booked, err
+	}
+
+	if repayment.Principal > 0 {
+		booked.loan, err = q.ReduceLoanPrincipal(ctx, ReduceLoanPrincipalParams{
+			Amount: repayment.Principal,
+			ID:     loan.ID,
+		})
+	}
+	return booked, err
+}
+
+func createInstallments(ctx context.Context, q *Queries, loanID int64, schedule []ScheduledInstallment) ([]LoanInstallment, error) {
+	installments := make([]LoanInstallment, len(schedule))
+	for i, scheduled := range schedule {
+		var err error
+		installments[i], err = q.CreateLoanInstallment(ctx, CreateLoanInstallmentParams{
+			LoanID:    loanID,
+			Period:    scheduled.Period,
+			DueDate:   scheduled.DueDate,
+			Principal: scheduled.Principal,
+			Interest:  scheduled.Interest,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return installments, nil
+}
+
+// installmentOwed is what is left to pay on an installment.
+func installmentOwed(installment LoanInstallment) int64 {
+	return installment.Principal + installment.Interest + installment.Fee -
+		installment.PaidPrincipal - installment.PaidInterest - installment.PaidFee
+}
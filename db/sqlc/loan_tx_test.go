@@ -0,0 +1,381 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// flatSchedule repays the same principal every month with 100 of interest,
+// the last installment taking what is left.
+func flatSchedule(loan Loan, first, count int32) ([]ScheduledInstallment, error) {
+	installments := make([]ScheduledInstallment, count)
+	share := loan.OutstandingPrincipal / int64(count)
+	for k := range installments {
+		principal := share
+		if k == len(installments)-1 {
+			principal = loan.OutstandingPrincipal - share*int64(count-1)
+		}
+		period := first + int32(k)
+		installments[k] = ScheduledInstallment{
+			Period:    period,
+			DueDate:   loan.DisbursedOn.AddDate(0, int(period), 0),
+			Principal: principal,
+			Interest:  100,
+		}
+	}
+	return installments, nil
+}
+
+func originateRandomLoan(t *testing.T, principal int64, term int32) OriginateLoanTxResult {
+	admin := createRandomUser(t)
+	account := createRandomAccount(t)
+	product, err := testQueries.CreateLoanProduct(context.Background(), CreateLoanProductParams{
+		Name:         "Loan " + utils.RandomString(8),
+		Currency:     account.Currency,
+		RateBps:      1200,
+		Method:       LoanLinear,
+		MinPrincipal: 1,
+		MaxPrincipal: 10_000_000,
+		MinTerm:      1,
+		MaxTerm:      60,
+		LateFee:      500,
+		GraceDays:    5,
+		CreatedBy:    admin.Username,
+	})
+	require.NoError(t, err)
+
+	book, err := testQueries.GetLoansAccount(context.Background(), account.Currency)
+	require.NoError(t, err)
+
+	result, err := NewStore(testDB).OriginateLoanTx(context.Background(), OriginateLoanTxParams{
+		Loan: CreateLoanParams{
+			ProductID:   product.ID,
+			AccountID:   account.ID,
+			Borrower:    account.Owner,
+			Principal:   principal,
+			RateBps:     product.RateBps,
+			TermMonths:  term,
+			Method:      product.Method,
+			LateFee:     product.LateFee,
+			GraceDays:   product.GraceDays,
+			DisbursedOn: time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC),
+		},
+		Schedule: flatSchedule,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, LoanActive, result.Loan.Status)
+	require.Equal(t, principal, result.Loan.OutstandingPrincipal)
+	require.Len(t, result.Installments, int(term))
+	require.Equal(t, account.Balance+principal, result.Account.Balance)
+	require.Equal(t, principal, result.Entry.Amount)
+	require.Equal(t, account.ID, result.Entry.AccountID)
+	require.Equal(t, -principal, result.FundingEntry.Amount)
+	require.Equal(t, book.ID, result.FundingEntry.AccountID)
+	require.Equal(t, AccountLoans, result.LoansAccount.Type)
+	require.Equal(t, result.Entry.ID, result.Loan.DisbursementEntryID.Int64)
+	require.Equal(t, result.FundingEntry.ID, result.Loan.FundingEntryID.Int64)
+
+	return result
+}
+
+func setBalance(t *testing.T, account Account, balance int64) {
+	account, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	_, err = testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     account.ID,
+		Amount: balance - account.Balance,
+	})
+	require.NoError(t, err)
+}
+
+func TestOriginateLoanTx(t *testing.T) {
+	originated := originateRandomLoan(t, 120_000, 3)
+
+	installments, err := testQueries.ListLoanInstallments(context.Background(), originated.Loan.ID)
+	require.NoError(t, err)
+	require.Len(t, installments, 3)
+	for i, installment := range installments {
+		require.Equal(t, int32(i+1), installment.Period)
+		require.Equal(t, int64(40_000), installment.Principal)
+		require.Equal(t, int64(100), installment.Interest)
+		require.Equal(t, InstallmentScheduled, installment.Status)
+	}
+
+	// frozen accounts can't borrow
+	frozen, err := testQueries.SetAccountFrozen(context.Background(), SetAccountFrozenParams{ID: originated.Account.ID, Frozen: true})
+	require.NoError(t, err)
+	_, err = NewStore(testDB).OriginateLoanTx(context.Background(), OriginateLoanTxParams{
+		Loan: CreateLoanParams{
+			ProductID:   originated.Loan.ProductID,
+			AccountID:   frozen.ID,
+			Borrower:    frozen.Owner,
+			Principal:   1_000,
+			RateBps:     1200,
+			TermMonths:  1,
+			Method:      LoanLinear,
+			DisbursedOn: originated.Loan.DisbursedOn,
+		},
+		Schedule: flatSchedule,
+	})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+}
+
+func TestOriginateLoanTxLimits(t *testing.T) {
+	store := NewStore(testDB)
+	originated := originateRandomLoan(t, 9_000_000, 3)
+	product, err := testQueries.GetLoanProduct(context.Background(), originated.Loan.ProductID)
+	require.NoError(t, err)
+
+	originate := func(principal int64, on time.Time) error {
+		_, err := store.OriginateLoanTx(context.Background(), OriginateLoanTxParams{
+			Loan: CreateLoanParams{
+				ProductID:   product.ID,
+				AccountID:   originated.Account.ID,
+				Borrower:    originated.Loan.Borrower,
+				Principal:   principal,
+				RateBps:     product.RateBps,
+				TermMonths:  1,
+				Method:      product.Method,
+				DisbursedOn: on,
+			},
+			Schedule: flatSchedule,
+		})
+		return err
+	}
+
+	// what the borrower owes under the product is capped by its maximum
+	// principal
+	on := originated.Loan.DisbursedOn
+	require.ErrorIs(t, originate(product.MaxPrincipal-9_000_000+1, on), ErrLoanLimitExceeded)
+	require.NoError(t, originate(product.MaxPrincipal-9_000_000, on))
+
+	// once an installment is past due the borrower can't borrow at all
+	require.ErrorIs(t, originate(1, on.AddDate(0, 1, 1)), ErrBorrowerInArrears)
+}
+
+func TestCollectInstallmentTx(t *testing.T) {
+	store := NewStore(testDB)
+	originated := originateRandomLoan(t, 120_000, 3)
+	first, second := originated.Installments[0], originated.Installments[1]
+
+	_, err := store.CollectInstallmentTx(context.Background(), CollectInstallmentTxParams{
+		InstallmentID: second.ID,
+		Today:         first.DueDate,
+	})
+	require.ErrorIs(t, err, ErrInstallmentNotDueYet)
+
+	// within the grace days, what the balance allows is collected
+	setBalance(t, originated.Account, 10_000)
+	result, err := store.CollectInstallmentTx(context.Background(), CollectInstallmentTxParams{
+		InstallmentID: first.ID,
+		Today:         first.DueDate.AddDate(0, 0, 1),
+	})
+	require.NoError(t, err)
+	require.Equal(t, RepaymentScheduled, result.Repayment.Kind)
+	require.Equal(t, int64(10_000), result.Repayment.Amount)
+	require.Equal(t, int64(100), result.Repayment.Interest)
+	require.Equal(t, int64(9_900), result.Repayment.Principal)
+	require.Equal(t, first.ID, result.Repayment.InstallmentID.Int64)
+	require.Equal(t, InstallmentScheduled, result.Installment.Status)
+	require.Equal(t, int64(9_900), result.Installment.PaidPrincipal)
+	require.Equal(t, int64(110_100), result.Loan.OutstandingPrincipal)
+	require.False(t, result.FeeCharged)
+
+	// past them, the installment is overdue and charged the late fee once
+	for i := 0; i < 2; i++ {
+		result, err = store.CollectInstallmentTx(context.Background(), CollectInstallmentTxParams{
+			InstallmentID: first.ID,
+			Today:         first.DueDate.AddDate(0, 0, 10),
+		})
+		require.NoError(t, err)
+		require.Zero(t, result.Repayment.ID)
+		require.Equal(t, InstallmentOverdue, result.Installment.Status)
+		require.Equal(t, int64(500), result.Installment.Fee)
+		require.Equal(t, i == 0, result.FeeCharged)
+	}
+
+	arrears, err := testQueries.GetLoanArrears(context.Background(), GetLoanArrearsParams{
+		LoanID:    originated.Loan.ID,
+		DueBefore: first.DueDate.AddDate(0, 0, 11),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), arrears.Installments)
+	require.Equal(t, int64(30_600), arrears.Amount)
+
+	// the fee is paid first
+	setBalance(t, originated.Account, 100_000)
+	result, err = store.CollectInstallmentTx(context.Background(), CollectInstallmentTxParams{
+		InstallmentID: first.ID,
+		Today:         first.DueDate.AddDate(0, 0, 11),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(30_600), result.Repayment.Amount)
+	require.Equal(t, int64(500), result.Repayment.Fee)
+	require.Zero(t, result.Repayment.Interest)
+	require.Equal(t, int64(30_100), result.Repayment.Principal)
+	require.Equal(t, InstallmentPaid, result.Installment.Status)
+	require.True(t, result.Installment.PaidAt.Valid)
+	require.Equal(t, int64(80_000), result.Loan.OutstandingPrincipal)
+	require.Equal(t, LoanActive, result.Loan.Status)
+
+	account, err := testQueries.GetAccount(context.Background(), originated.Account.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(69_400), account.Balance)
+
+	_, err = store.CollectInstallmentTx(context.Background(), CollectInstallmentTxParams{
+		InstallmentID: first.ID,
+		Today:         first.DueDate.AddDate(0, 0, 11),
+	})
+	require.ErrorIs(t, err, ErrInstallmentSettled)
+
+	repayments, err := testQueries.ListLoanRepayments(context.Background(), originated.Loan.ID)
+	require.NoError(t, err)
+	require.Len(t, repayments, 2)
+}
+
+func TestCollectInstallmentTxPaysOffLoan(t *testing.T) {
+	store := NewStore(testDB)
+	originated := originateRandomLoan(t, 50_000, 1)
+	installment := originated.Installments[0]
+
+	// frozen accounts aren't collected from
+	_, err := testQueries.SetAccountFrozen(context.Background(), SetAccountFrozenParams{ID: originated.Account.ID, Frozen: true})
+	require.NoError(t, err)
+	result, err := store.CollectInstallmentTx(context.Background(), CollectInstallmentTxParams{
+		InstallmentID: installment.ID,
+		Today:         installment.DueDate,
+	})
+	require.NoError(t, err)
+	require.Zero(t, result.Repayment.ID)
+	require.Equal(t, InstallmentScheduled, result.Installment.Status)
+
+	_, err = testQueries.SetAccountFrozen(context.Background(), SetAccountFrozenParams{ID: originated.Account.ID, Frozen: false})
+	require.NoError(t, err)
+	result, err = store.CollectInstallmentTx(context.Background(), CollectInstallmentTxParams{
+		InstallmentID: installment.ID,
+		Today:         installment.DueDate,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(50_100), result.Repayment.Amount)
+	require.Equal(t, InstallmentPaid, result.Installment.Status)
+	require.Equal(t, LoanPaidOff, result.Loan.Status)
+	require.Zero(t, result.Loan.OutstandingPrincipal)
+	require.True(t, result.Loan.ClosedAt.Valid)
+}
+
+func TestRepayLoanEarlyTx(t *testing.T) {
+	store := NewStore(testDB)
+	originated := originateRandomLoan(t, 100_000, 4)
+	first := originated.Installments[0]
+
+	repay := func(amount int64) (RepayLoanEarlyTxResult, error) {
+		return store.RepayLoanEarlyTx(context.Background(), RepayLoanEarlyTxParams{
+			LoanID:   originated.Loan.ID,
+			Amount:   amount,
+			Today:    first.DueDate,
+			Schedule: flatSchedule,
+		})
+	}
+
+	// the installment due today must be paid first
+	_, err := repay(10_000)
+	require.ErrorIs(t, err, ErrLoanInArrears)
+
+	_, err = store.CollectInstallmentTx(context.Background(), CollectInstallmentTxParams{
+		InstallmentID: first.ID,
+		Today:         first.DueDate,
+	})
+	require.NoError(t, err)
+
+	_, err = repay(75_001)
+	require.ErrorIs(t, err, ErrRepaymentTooLarge)
+
+	setBalance(t, originated.Account, 29_999)
+	_, err = repay(30_000)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	setBalance(t, originated.Account, 100_000)
+	result, err := repay(30_000)
+	require.NoError(t, err)
+	require.Equal(t, RepaymentEarly, result.Repayment.Kind)
+	require.Equal(t, int64(30_000), result.Repayment.Principal)
+	require.False(t, result.Repayment.InstallmentID.Valid)
+	require.Equal(t, int64(45_000), result.Loan.OutstandingPrincipal)
+
+	// the 3 installments left are rescheduled over the same periods
+	require.Len(t, result.Installments, 3)
+	for i, installment := range result.Installments {
+		require.Equal(t, int32(i+2), installment.Period)
+		require.Equal(t, int64(15_000), installment.Principal)
+	}
+
+	installments, err := testQueries.ListLoanInstallments(context.Background(), originated.Loan.ID)
+	require.NoError(t, err)
+	statuses := map[string]int{}
+	for _, installment := range installments {
+		statuses[installment.Status]++
+	}
+	require.Equal(t, map[string]int{InstallmentPaid: 1, InstallmentCancelled: 3, InstallmentScheduled: 3}, statuses)
+
+	// repaying everything left pays the loan off
+	result, err = repay(45_000)
+	require.NoError(t, err)
+	require.Equal(t, LoanPaidOff, result.Loan.Status)
+	require.Empty(t, result.Installments)
+
+	unpaid, err := testQueries.CountUnpaidLoanInstallments(context.Background(), originated.Loan.ID)
+	require.NoError(t, err)
+	require.Zero(t, unpaid)
+
+	account, err := testQueries.GetAccount(context.Background(), originated.Account.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(25_000), account.Balance)
+
+	_, err = repay(1)
+	require.ErrorIs(t, err, ErrLoanClosed)
+}
+
+func TestLoanTxKeepsCardHolds(t *testing.T) {
+	store := NewStore(testDB)
+	originated := originateRandomLoan(t, 100_000, 4)
+	first := originated.Installments[0]
+
+	setBalance(t, originated.Account, 10_000)
+	card := createRandomCard(t, originated.Account)
+	_, err := store.AuthorizeCardTx(context.Background(), authorizeCard(card, 4_000))
+	require.NoError(t, err)
+
+	// the held amount is left for the capture
+	result, err := store.CollectInstallmentTx(context.Background(), CollectInstallmentTxParams{
+		InstallmentID: first.ID,
+		Today:         first.DueDate,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(6_000), result.Repayment.Amount)
+
+	setBalance(t, originated.Account, 100_000)
+	_, err = store.CollectInstallmentTx(context.Background(), CollectInstallmentTxParams{
+		InstallmentID: first.ID,
+		Today:         first.DueDate,
+	})
+	require.NoError(t, err)
+
+	repay := func(amount int64) error {
+		_, err := store.RepayLoanEarlyTx(context.Background(), RepayLoanEarlyTxParams{
+			LoanID:   originated.Loan.ID,
+			Amount:   amount,
+			Today:    first.DueDate,
+			Schedule: flatSchedule,
+		})
+		return err
+	}
+
+	setBalance(t, originated.Account, 30_000)
+	require.ErrorIs(t, repay(30_000), ErrInsufficientFunds)
+	require.NoError(t, repay(26_000))
+}
@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NotificationLoginLocked tells a user that their account was locked after
+// too many failed logins.
+const NotificationLoginLocked = "login.locked"
+
+// LockoutPolicy decides when failed logins lock a user and for how long.
+// Zero fields take the defaults below.
+type LockoutPolicy struct {
+	// MaxAttempts is the number of failed logins in a row that lock the user.
+	MaxAttempts int32
+	// BaseDuration is how long the first lockout lasts. Every further
+	// lockout without a successful login in between lasts twice as long.
+	BaseDuration time.Duration
+	// MaxDuration caps the length of a lockout.
+	MaxDuration time.Duration
+}
+
+const (
+	DefaultLoginMaxAttempts = 5
+	DefaultLoginLockoutBase = time.Minute
+	DefaultLoginLockoutMax  = 24 * time.Hour
+)
+
+func (policy LockoutPolicy) withDefaults() LockoutPolicy {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = DefaultLoginMaxAttempts
+	}
+	if policy.BaseDuration <= 0 {
+		policy.BaseDuration = DefaultLoginLockoutBase
+	}
+	if policy.MaxDuration <= 0 {
+		policy.MaxDuration = DefaultLoginLockoutMax
+	}
+	return policy
+}
+
+// LockDuration is how long a user is locked given the number of lockouts
+// they already had.
+func (policy LockoutPolicy) LockDuration(lockouts int32) time.Duration {
+	policy = policy.withDefaults()
+
+	duration := policy.BaseDuration
+	for i := int32(0); i < lockouts && duration < policy.MaxDuration; i++ {
+		duration *= 2
+	}
+	if duration > policy.MaxDuration {
+		return policy.MaxDuration
+	}
+	return duration
+}
+
+type LoginFailureTxParams struct {
+	Username string        `json:"username"`
+	Policy   LockoutPolicy `json:"-"`
+}
+
+type LoginFailureTxResult struct {
+	User User `json:"user"`
+	// Locked is set when this failure locked the user. Notification is then
+	// the message sent to them about it.
+	Locked       bool         `json:"locked"`
+	Notification Notification `json:"notification"`
+}
+
+// LoginFailureTx counts a failed login. Once the user reaches
+// Policy.MaxAttempts they are locked for Policy.LockDuration, the counter
+// starts over and they get a NotificationLoginLocked.
+func (store *SQLStore) LoginFailureTx(ctx context.Context, args LoginFailureTxParams) (result LoginFailureTxResult, err error) {
+	ctx, span := store.tracer.Start(ctx, "LoginFailureTx", trace.WithAttributes(
+		attribute.String("user.username", args.Username),
+	))
+	defer endSpan(span, &err)
+
+	policy := args.Policy.withDefaults()
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		user, err := q.RecordFailedLogin(ctx, args.Username)
+		if err != nil {
+			return err
+		}
+		result.User = user
+
+		if user.FailedLoginAttempts < policy.MaxAttempts {
+			return nil
+		}
+
+		lockedUntil := time.Now().Add(policy.LockDuration(user.Lockouts))
+		result.User, err = q.LockUser(ctx, LockUserParams{
+			LockedUntil: lockedUntil,
+			Username:    user.Username,
+		})
+		if err != nil {
+			return err
+		}
+		result.Locked = true
+
+		payload, err := json.Marshal(map[string]interface{}{
+			"locked_until":    lockedUntil.UTC(),
+			"failed_attempts": user.FailedLoginAttempts,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Notification, err = q.CreateNotification(ctx, CreateNotificationParams{
+			Username: user.Username,
+			Type:     NotificationLoginLocked,
+			Payload:  payload,
+		})
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockDuration(t *testing.T) {
+	policy := LockoutPolicy{MaxAttempts: 3, BaseDuration: time.Minute, MaxDuration: 10 * time.Minute}
+
+	require.Equal(t, time.Minute, policy.LockDuration(0))
+	require.Equal(t, 2*time.Minute, policy.LockDuration(1))
+	require.Equal(t, 8*time.Minute, policy.LockDuration(3))
+	require.Equal(t, 10*time.Minute, policy.LockDuration(4))
+	require.Equal(t, 10*time.Minute, policy.LockDuration(1000))
+
+	require.Equal(t, DefaultLoginLockoutBase, LockoutPolicy{}.LockDuration(0))
+}
+
+func TestLoginFailureTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	policy := LockoutPolicy{MaxAttempts: 3, BaseDuration: time.Minute, MaxDuration: time.Hour}
+	args := LoginFailureTxParams{Username: user.Username, Policy: policy}
+
+	for i := int32(1); i < policy.MaxAttempts; i++ {
+		result, err := store.LoginFailureTx(context.Background(), args)
+		require.NoError(t, err)
+		require.False(t, result.Locked)
+		require.Equal(t, i, result.User.FailedLoginAttempts)
+		require.False(t, result.User.LockedUntil.After(time.Now()))
+	}
+
+	result, err := store.LoginFailureTx(context.Background(), args)
+	require.NoError(t, err)
+	require.True(t, result.Locked)
+	require.Zero(t, result.User.FailedLoginAttempts)
+	require.Equal(t, int32(1), result.User.Lockouts)
+	require.WithinDuration(t, time.Now().Add(time.Minute), result.User.LockedUntil, 5*time.Second)
+
+	require.Equal(t, user.Username, result.Notification.Username)
+	require.Equal(t, NotificationLoginLocked, result.Notification.Type)
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(result.Notification.Payload, &payload))
+	require.EqualValues(t, policy.MaxAttempts, payload["failed_attempts"])
+
+	// the second lockout lasts twice as long
+	for i := int32(0); i < policy.MaxAttempts; i++ {
+		result, err = store.LoginFailureTx(context.Background(), args)
+		require.NoError(t, err)
+	}
+	require.True(t, result.Locked)
+	require.Equal(t, int32(2), result.User.Lockouts)
+	require.WithinDuration(t, time.Now().Add(2*time.Minute), result.User.LockedUntil, 5*time.Second)
+
+	notifications, err := testQueries.ListNotifications(context.Background(), ListNotificationsParams{
+		Username: user.Username,
+		Limit:    5,
+	})
+	require.NoError(t, err)
+	require.Len(t, notifications, 2)
+	require.Equal(t, result.Notification.ID, notifications[0].ID)
+
+	err = testQueries.ResetFailedLogins(context.Background(), user.Username)
+	require.NoError(t, err)
+	reset, err := testQueries.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Zero(t, reset.FailedLoginAttempts)
+	require.Zero(t, reset.Lockouts)
+}
+
+func TestTakeRateLimitToken(t *testing.T) {
+	args := TakeRateLimitTokenParams{
+		Key:   "test:" + createRandomUser(t).Username,
+		Burst: 2,
+		Rate:  0.001,
+	}
+
+	for i := 0; i < 2; i++ {
+		row, err := testQueries.TakeRateLimitToken(context.Background(), args)
+		require.NoError(t, err)
+		require.True(t, row.Allowed)
+	}
+
+	row, err := testQueries.TakeRateLimitToken(context.Background(), args)
+	require.NoError(t, err)
+	require.False(t, row.Allowed)
+	require.Less(t, row.Tokens, 1.0)
+}
+
+func TestDeleteStaleRateLimitBuckets(t *testing.T) {
+	args := TakeRateLimitTokenParams{
+		Key:   "test:" + createRandomUser(t).Username,
+		Burst: 2,
+		Rate:  0.001,
+	}
+	_, err := testQueries.TakeRateLimitToken(context.Background(), args)
+	require.NoError(t, err)
+
+	// a bucket used since the cutoff stays
+	_, err = testQueries.DeleteStaleRateLimitBuckets(context.Background(), time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	row, err := testQueries.TakeRateLimitToken(context.Background(), args)
+	require.NoError(t, err)
+	require.True(t, row.Allowed)
+	require.Less(t, row.Tokens, 1.0)
+
+	pruned, err := testQueries.DeleteStaleRateLimitBuckets(context.Background(), time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, pruned, int64(1))
+
+	// the next use starts from a full bucket
+	row, err = testQueries.TakeRateLimitToken(context.Background(), args)
+	require.NoError(t, err)
+	require.True(t, row.Allowed)
+	require.Equal(t, 1.0, row.Tokens)
+}
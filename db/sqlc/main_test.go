@@ -2,7 +2,6 @@ package db
 
 import (
 	"database/sql"
-	"fmt"
 	"log"
 	"os"
 	"testing"
@@ -20,17 +19,7 @@ func TestMain(m *testing.M) {
 		log.Fatal("cannot load config:", err)
 	}
 
-	dbSource := fmt.Sprintf(
-		"postgresql://%s:%s@%s:%s/%s?sslmode=%s",
-		config.DBUser,
-		config.DBPassword,
-		config.DBHost,
-		config.DBPort,
-		config.DBName,
-		config.DBSSLMode,
-	)
-
-	testDB, err = sql.Open(config.DBDriver, dbSource)
+	testDB, err = sql.Open(config.DBDriver, config.DBSource())
 	if err != nil {
 		log.Fatal("cannot connect to db:", err)
 	}
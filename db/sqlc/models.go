@@ -5,6 +5,8 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 )
 
@@ -15,6 +17,159 @@ type Account struct {
 	Owner     string    `json:"owner"`
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
+	Frozen    bool      `json:"frozen"`
+	Type      string    `json:"type"`
+}
+
+type AccountInvitation struct {
+	ID           int64        `json:"id"`
+	AccountID    int64        `json:"account_id"`
+	Invitee      string       `json:"invitee"`
+	Role         string       `json:"role"`
+	PaymentLimit int64        `json:"payment_limit"`
+	InvitedBy    string       `json:"invited_by"`
+	Status       string       `json:"status"`
+	ExpiresAt    time.Time    `json:"expires_at"`
+	CreatedAt    time.Time    `json:"created_at"`
+	RespondedAt  sql.NullTime `json:"responded_at"`
+}
+
+type AccountMember struct {
+	AccountID    int64     `json:"account_id"`
+	Username     string    `json:"username"`
+	Role         string    `json:"role"`
+	PaymentLimit int64     `json:"payment_limit"`
+	InvitedBy    string    `json:"invited_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type Adjustment struct {
+	ID              int64          `json:"id"`
+	AccountID       int64          `json:"account_id"`
+	EntryID         sql.NullInt64  `json:"entry_id"`
+	Amount          int64          `json:"amount"`
+	ReasonCode      string         `json:"reason_code"`
+	Note            string         `json:"note"`
+	CreatedBy       string         `json:"created_by"`
+	CreatedAt       time.Time      `json:"created_at"`
+	SuspenseEntryID sql.NullInt64  `json:"suspense_entry_id"`
+	Evidence        string         `json:"evidence"`
+	Status          string         `json:"status"`
+	ReviewedBy      sql.NullString `json:"reviewed_by"`
+	ReviewedAt      sql.NullTime   `json:"reviewed_at"`
+	ReviewNote      string         `json:"review_note"`
+	ExpiresAt       time.Time      `json:"expires_at"`
+}
+
+type AMLAlert struct {
+	ID         int64           `json:"id"`
+	Scenario   string          `json:"scenario"`
+	Username   string          `json:"username"`
+	AccountID  int64           `json:"account_id"`
+	Summary    string          `json:"summary"`
+	Evidence   json.RawMessage `json:"evidence"`
+	Status     string          `json:"status"`
+	AssignedTo sql.NullString  `json:"assigned_to"`
+	Resolution string          `json:"resolution"`
+	ResolvedBy sql.NullString  `json:"resolved_by"`
+	ResolvedAt sql.NullTime    `json:"resolved_at"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+type AMLAlertComment struct {
+	ID        int64     `json:"id"`
+	AlertID   int64     `json:"alert_id"`
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AMLJobRun struct {
+	ID          int64        `json:"id"`
+	Scenario    string       `json:"scenario"`
+	WindowStart time.Time    `json:"window_start"`
+	WindowEnd   time.Time    `json:"window_end"`
+	Alerts      int32        `json:"alerts"`
+	Error       string       `json:"error"`
+	StartedAt   time.Time    `json:"started_at"`
+	FinishedAt  sql.NullTime `json:"finished_at"`
+}
+
+type APIKey struct {
+	ID         int64        `json:"id"`
+	Username   string       `json:"username"`
+	Name       string       `json:"name"`
+	Prefix     string       `json:"prefix"`
+	KeyHash    string       `json:"key_hash"`
+	Scopes     []string     `json:"scopes"`
+	AllowedIPs []string     `json:"allowed_ips"`
+	ExpiresAt  sql.NullTime `json:"expires_at"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+	RevokedAt  sql.NullTime `json:"revoked_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+type AuditEvent struct {
+	ID           int64           `json:"id"`
+	Actor        string          `json:"actor"`
+	Action       string          `json:"action"`
+	Outcome      string          `json:"outcome"`
+	ResourceType string          `json:"resource_type"`
+	ResourceID   string          `json:"resource_id"`
+	RequestID    string          `json:"request_id"`
+	ClientIp     string          `json:"client_ip"`
+	Metadata     json.RawMessage `json:"metadata"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+type Card struct {
+	ID               int64     `json:"id"`
+	AccountID        int64     `json:"account_id"`
+	Owner            string    `json:"owner"`
+	PAN              string    `json:"pan"`
+	Last4            string    `json:"last4"`
+	ExpiryMonth      int32     `json:"expiry_month"`
+	ExpiryYear       int32     `json:"expiry_year"`
+	HashedCVV        string    `json:"hashed_cvv"`
+	Frozen           bool      `json:"frozen"`
+	TransactionLimit int64     `json:"transaction_limit"`
+	DailyLimit       int64     `json:"daily_limit"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type CardAuthorization struct {
+	ID                int64         `json:"id"`
+	CardID            int64         `json:"card_id"`
+	AccountID         int64         `json:"account_id"`
+	STAN              string        `json:"stan"`
+	RRN               string        `json:"rrn"`
+	Amount            int64         `json:"amount"`
+	Currency          string        `json:"currency"`
+	Merchant          string        `json:"merchant"`
+	MCC               string        `json:"mcc"`
+	Status            string        `json:"status"`
+	ResponseCode      string        `json:"response_code"`
+	AuthCode          string        `json:"auth_code"`
+	CapturedAmount    int64         `json:"captured_amount"`
+	EntryID           sql.NullInt64 `json:"entry_id"`
+	SettlementEntryID sql.NullInt64 `json:"settlement_entry_id"`
+	ExpiresAt         time.Time     `json:"expires_at"`
+	CreatedAt         time.Time     `json:"created_at"`
+	CapturedAt        sql.NullTime  `json:"captured_at"`
+	ReversedAt        sql.NullTime  `json:"reversed_at"`
+}
+
+type EmailChange struct {
+	ID             int64        `json:"id"`
+	Username       string       `json:"username"`
+	OldEmail       string       `json:"old_email"`
+	NewEmail       string       `json:"new_email"`
+	OldConfirmedAt sql.NullTime `json:"old_confirmed_at"`
+	NewConfirmedAt sql.NullTime `json:"new_confirmed_at"`
+	CompletedAt    sql.NullTime `json:"completed_at"`
+	ExpiresAt      time.Time    `json:"expires_at"`
+	CreatedAt      time.Time    `json:"created_at"`
 }
 
 type Entry struct {
@@ -25,6 +180,192 @@ type Entry struct {
 	AccountID int64     `json:"account_id"`
 }
 
+type FraudDecision struct {
+	ID            int64           `json:"id"`
+	Username      string          `json:"username"`
+	FromAccountID int64           `json:"from_account_id"`
+	ToAccountID   int64           `json:"to_account_id"`
+	Amount        int64           `json:"amount"`
+	Score         int32           `json:"score"`
+	Outcome       string          `json:"outcome"`
+	Status        string          `json:"status"`
+	Trace         json.RawMessage `json:"trace"`
+	Features      json.RawMessage `json:"features"`
+	PaymentID     sql.NullInt64   `json:"payment_id"`
+	ReviewedBy    sql.NullString  `json:"reviewed_by"`
+	ReviewedAt    sql.NullTime    `json:"reviewed_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+type InterestAccrual struct {
+	ID           int64         `json:"id"`
+	AccountID    int64         `json:"account_id"`
+	AccrualDate  time.Time     `json:"accrual_date"`
+	Balance      int64         `json:"balance"`
+	Base         int64         `json:"base"`
+	RateBps      int32         `json:"rate_bps"`
+	DayCount     string        `json:"day_count"`
+	Days         int32         `json:"days"`
+	AmountMicros int64         `json:"amount_micros"`
+	PayoutID     sql.NullInt64 `json:"payout_id"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+type InterestPayout struct {
+	ID             int64         `json:"id"`
+	AccountID      int64         `json:"account_id"`
+	PeriodStart    time.Time     `json:"period_start"`
+	PeriodEnd      time.Time     `json:"period_end"`
+	AccruedMicros  int64         `json:"accrued_micros"`
+	Amount         int64         `json:"amount"`
+	CarryMicros    int64         `json:"carry_micros"`
+	EntryID        sql.NullInt64 `json:"entry_id"`
+	ExpenseEntryID sql.NullInt64 `json:"expense_entry_id"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+type KYCDocument struct {
+	ID          int64     `json:"id"`
+	Username    string    `json:"username"`
+	Kind        string    `json:"kind"`
+	StorageKey  string    `json:"storage_key"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type KYCProfile struct {
+	Username        string         `json:"username"`
+	LegalName       string         `json:"legal_name"`
+	DateOfBirth     time.Time      `json:"date_of_birth"`
+	Nationality     string         `json:"nationality"`
+	AddressLine     string         `json:"address_line"`
+	City            string         `json:"city"`
+	PostalCode      string         `json:"postal_code"`
+	Country         string         `json:"country"`
+	SubmittedAt     sql.NullTime   `json:"submitted_at"`
+	ReviewedBy      sql.NullString `json:"reviewed_by"`
+	ReviewedAt      sql.NullTime   `json:"reviewed_at"`
+	RejectionReason sql.NullString `json:"rejection_reason"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+}
+
+type Loan struct {
+	ID                   int64         `json:"id"`
+	ProductID            int64         `json:"product_id"`
+	AccountID            int64         `json:"account_id"`
+	Borrower             string        `json:"borrower"`
+	Principal            int64         `json:"principal"`
+	RateBps              int32         `json:"rate_bps"`
+	TermMonths           int32         `json:"term_months"`
+	Method               string        `json:"method"`
+	LateFee              int64         `json:"late_fee"`
+	GraceDays            int32         `json:"grace_days"`
+	OutstandingPrincipal int64         `json:"outstanding_principal"`
+	Status               string        `json:"status"`
+	DisbursedOn          time.Time     `json:"disbursed_on"`
+	DisbursementEntryID  sql.NullInt64 `json:"disbursement_entry_id"`
+	FundingEntryID       sql.NullInt64 `json:"funding_entry_id"`
+	CreatedAt            time.Time     `json:"created_at"`
+	ClosedAt             sql.NullTime  `json:"closed_at"`
+}
+
+type LoanInstallment struct {
+	ID            int64        `json:"id"`
+	LoanID        int64        `json:"loan_id"`
+	Period        int32        `json:"period"`
+	DueDate       time.Time    `json:"due_date"`
+	Principal     int64        `json:"principal"`
+	Interest      int64        `json:"interest"`
+	Fee           int64        `json:"fee"`
+	PaidPrincipal int64        `json:"paid_principal"`
+	PaidInterest  int64        `json:"paid_interest"`
+	PaidFee       int64        `json:"paid_fee"`
+	Status        string       `json:"status"`
+	PaidAt        sql.NullTime `json:"paid_at"`
+	CreatedAt     time.Time    `json:"created_at"`
+}
+
+type LoanProduct struct {
+	ID           int64     `json:"id"`
+	Name         string    `json:"name"`
+	Currency     string    `json:"currency"`
+	RateBps      int32     `json:"rate_bps"`
+	Method       string    `json:"method"`
+	MinPrincipal int64     `json:"min_principal"`
+	MaxPrincipal int64     `json:"max_principal"`
+	MinTerm      int32     `json:"min_term"`
+	MaxTerm      int32     `json:"max_term"`
+	LateFee      int64     `json:"late_fee"`
+	GraceDays    int32     `json:"grace_days"`
+	CreatedBy    string    `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type LoanRepayment struct {
+	ID            int64         `json:"id"`
+	LoanID        int64         `json:"loan_id"`
+	InstallmentID sql.NullInt64 `json:"installment_id"`
+	Kind          string        `json:"kind"`
+	Amount        int64         `json:"amount"`
+	Principal     int64         `json:"principal"`
+	Interest      int64         `json:"interest"`
+	Fee           int64         `json:"fee"`
+	EntryID       int64         `json:"entry_id"`
+	LoanEntryID   int64         `json:"loan_entry_id"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+type Notification struct {
+	ID        int64           `json:"id"`
+	Username  string          `json:"username"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type OAuthAuthorizationCode struct {
+	ID            int64        `json:"id"`
+	CodeHash      string       `json:"code_hash"`
+	ConsentID     int64        `json:"consent_id"`
+	RedirectURI   string       `json:"redirect_uri"`
+	CodeChallenge string       `json:"code_challenge"`
+	ExpiresAt     time.Time    `json:"expires_at"`
+	UsedAt        sql.NullTime `json:"used_at"`
+	CreatedAt     time.Time    `json:"created_at"`
+}
+
+type OAuthClient struct {
+	ID           string         `json:"id"`
+	Name         string         `json:"name"`
+	SecretHash   sql.NullString `json:"secret_hash"`
+	RedirectURIs []string       `json:"redirect_uris"`
+	Scopes       []string       `json:"scopes"`
+	CreatedBy    string         `json:"created_by"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+type OAuthConsent struct {
+	ID        int64        `json:"id"`
+	Username  string       `json:"username"`
+	ClientID  string       `json:"client_id"`
+	Scopes    []string     `json:"scopes"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	RevokedAt sql.NullTime `json:"revoked_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type OAuthRefreshToken struct {
+	ID        int64        `json:"id"`
+	TokenHash string       `json:"token_hash"`
+	ConsentID int64        `json:"consent_id"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
 type Payment struct {
 	ID            int64     `json:"id"`
 	CreatedAt     time.Time `json:"created_at"`
@@ -32,13 +373,97 @@ type Payment struct {
 	Amount        int64     `json:"amount"`
 	FromAccountID int64     `json:"from_account_id"`
 	ToAccountID   int64     `json:"to_account_id"`
+	InitiatedBy   string    `json:"initiated_by"`
+}
+
+type Pocket struct {
+	AccountID  int64        `json:"account_id"`
+	ParentID   int64        `json:"parent_id"`
+	Name       string       `json:"name"`
+	GoalAmount int64        `json:"goal_amount"`
+	TargetDate sql.NullTime `json:"target_date"`
+	RoundUpTo  int64        `json:"round_up_to"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+type PocketMove struct {
+	ID            int64         `json:"id"`
+	ParentID      int64         `json:"parent_id"`
+	FromAccountID int64         `json:"from_account_id"`
+	ToAccountID   int64         `json:"to_account_id"`
+	Amount        int64         `json:"amount"`
+	Kind          string        `json:"kind"`
+	PaymentID     sql.NullInt64 `json:"payment_id"`
+	FromEntryID   int64         `json:"from_entry_id"`
+	ToEntryID     int64         `json:"to_entry_id"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+type RateLimitBucket struct {
+	Key       string    `json:"key"`
+	Tokens    float64   `json:"tokens"`
+	Allowed   bool      `json:"allowed"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type SavingsAccount struct {
+	AccountID int64     `json:"account_id"`
+	ProductID int64     `json:"product_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SavingsProduct struct {
+	ID              int64     `json:"id"`
+	Name            string    `json:"name"`
+	Currency        string    `json:"currency"`
+	RateBps         int32     `json:"rate_bps"`
+	DayCount        string    `json:"day_count"`
+	Compounding     string    `json:"compounding"`
+	PayoutFrequency string    `json:"payout_frequency"`
+	CreatedBy       string    `json:"created_by"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type ScreeningHit struct {
+	ID           int64          `json:"id"`
+	Username     string         `json:"username"`
+	Source       string         `json:"source"`
+	ScreenedName string         `json:"screened_name"`
+	List         string         `json:"list"`
+	EntryID      string         `json:"entry_id"`
+	EntryName    string         `json:"entry_name"`
+	Score        float64        `json:"score"`
+	Status       string         `json:"status"`
+	ReviewedBy   sql.NullString `json:"reviewed_by"`
+	ReviewedAt   sql.NullTime   `json:"reviewed_at"`
+	ReviewNote   string         `json:"review_note"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
 }
 
 type User struct {
-	Username          string    `json:"username"`
-	HashedPassword    string    `json:"hashed_password"`
-	FullName          string    `json:"full_name"`
-	Email             string    `json:"email"`
-	PasswordChangedAt time.Time `json:"password_changed_at"`
-	CreatedAt         time.Time `json:"created_at"`
+	Username            string    `json:"username"`
+	HashedPassword      string    `json:"hashed_password"`
+	FullName            string    `json:"full_name"`
+	Email               string    `json:"email"`
+	PasswordChangedAt   time.Time `json:"password_changed_at"`
+	CreatedAt           time.Time `json:"created_at"`
+	Disabled            bool      `json:"disabled"`
+	Role                string    `json:"role"`
+	FailedLoginAttempts int32     `json:"failed_login_attempts"`
+	Lockouts            int32     `json:"lockouts"`
+	LockedUntil         time.Time `json:"locked_until"`
+	EmailVerifiedAt     time.Time `json:"email_verified_at"`
+	KYCStatus           string    `json:"kyc_status"`
+}
+
+type UserToken struct {
+	ID        int64        `json:"id"`
+	Username  string       `json:"username"`
+	Purpose   string       `json:"purpose"`
+	TokenHash string       `json:"token_hash"`
+	Email     string       `json:"email"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
 }
@@ -0,0 +1,267 @@
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrInvalidGrant is returned for an authorization code or refresh token
+// that is unknown, was already used, has expired, belongs to another client
+// or whose consent is no longer in force.
+var ErrInvalidGrant = errors.New("grant is invalid, expired or revoked")
+
+const oauthClientIDBytes = 12
+
+// NewOAuthClientID generates the public ID of a client.
+func NewOAuthClientID() (string, error) {
+	b := make([]byte, oauthClientIDBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "client_" + hex.EncodeToString(b), nil
+}
+
+// NewOAuthClientSecret generates the secret of a confidential client. Like
+// user tokens only its hash is stored.
+func NewOAuthClientSecret() (string, error) {
+	return newUserToken()
+}
+
+// OAuthCodeChallenge is the S256 PKCE challenge of verifier (RFC 7636).
+func OAuthCodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Active reports whether the consent still lets its client act for the user.
+func (consent OAuthConsent) Active() bool {
+	return !consent.RevokedAt.Valid && consent.ExpiresAt.After(time.Now())
+}
+
+type AuthorizeOAuthClientTxParams struct {
+	Username      string        `json:"username"`
+	ClientID      string        `json:"client_id"`
+	Scopes        []string      `json:"scopes"`
+	RedirectURI   string        `json:"redirect_uri"`
+	CodeChallenge string        `json:"code_challenge"`
+	ConsentTTL    time.Duration `json:"consent_ttl"`
+	CodeTTL       time.Duration `json:"code_ttl"`
+}
+
+// AuthorizeOAuthClientTxResult holds the authorization code to send back to
+// the client. Code is not stored anywhere and cannot be recovered later.
+type AuthorizeOAuthClientTxResult struct {
+	Consent OAuthConsent `json:"consent"`
+	Code    string       `json:"-"`
+}
+
+// AuthorizeOAuthClientTx records the consent of a user for a client and
+// creates the authorization code the client exchanges for tokens. An earlier
+// consent of the user for the same client is revoked: the new one replaces
+// it.
+func (store *SQLStore) AuthorizeOAuthClientTx(ctx context.Context, args AuthorizeOAuthClientTxParams) (result AuthorizeOAuthClientTxResult, err error) {
+	ctx, span := store.tracer.Start(ctx, "AuthorizeOAuthClientTx", trace.WithAttributes(
+		attribute.String("user.username", args.Username),
+		attribute.String("oauth.client_id", args.ClientID),
+	))
+	defer endSpan(span, &err)
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		err := q.RevokeOAuthClientConsents(ctx, RevokeOAuthClientConsentsParams{
+			Username: args.Username,
+			ClientID: args.ClientID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Consent, err = q.CreateOAuthConsent(ctx, CreateOAuthConsentParams{
+			Username:  args.Username,
+			ClientID:  args.ClientID,
+			Scopes:    args.Scopes,
+			ExpiresAt: time.Now().Add(args.ConsentTTL),
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Code, err = newUserToken()
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateOAuthAuthorizationCode(ctx, CreateOAuthAuthorizationCodeParams{
+			CodeHash:      HashUserToken(result.Code),
+			ConsentID:     result.Consent.ID,
+			RedirectURI:   args.RedirectURI,
+			CodeChallenge: args.CodeChallenge,
+			ExpiresAt:     time.Now().Add(args.CodeTTL),
+		})
+		return err
+	})
+	return result, err
+}
+
+type ExchangeOAuthCodeTxParams struct {
+	Code         string        `json:"-"`
+	ClientID     string        `json:"client_id"`
+	RedirectURI  string        `json:"redirect_uri"`
+	CodeVerifier string        `json:"-"`
+	RefreshTTL   time.Duration `json:"refresh_ttl"`
+}
+
+// OAuthGrantTxResult holds the consent a client was granted tokens for and
+// its new refresh token, which is not stored anywhere and cannot be
+// recovered later.
+type OAuthGrantTxResult struct {
+	Consent      OAuthConsent `json:"consent"`
+	RefreshToken string       `json:"-"`
+}
+
+// ExchangeOAuthCodeTx redeems an authorization code for a refresh token. The
+// code must be used by the client it was issued to, with the same redirect
+// URI and the PKCE verifier of its challenge. A code presented a second time
+// was probably stolen, so its consent is revoked, which also ends the tokens
+// issued the first time.
+func (store *SQLStore) ExchangeOAuthCodeTx(ctx context.Context, args ExchangeOAuthCodeTxParams) (result OAuthGrantTxResult, err error) {
+	ctx, span := store.tracer.Start(ctx, "ExchangeOAuthCodeTx", trace.WithAttributes(
+		attribute.String("oauth.client_id", args.ClientID),
+	))
+	defer endSpan(span, &err)
+
+	var replayed bool
+	err = store.execTx(ctx, func(q *Queries) error {
+		code, err := q.GetOAuthAuthorizationCodeForUpdate(ctx, HashUserToken(args.Code))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInvalidGrant
+			}
+			return err
+		}
+
+		consent, err := q.GetOAuthConsent(ctx, code.ConsentID)
+		if err != nil {
+			return err
+		}
+		if consent.ClientID != args.ClientID {
+			return ErrInvalidGrant
+		}
+
+		if code.UsedAt.Valid {
+			replayed = true
+			return revokeConsent(ctx, q, consent)
+		}
+
+		challenge := OAuthCodeChallenge(args.CodeVerifier)
+		if code.ExpiresAt.Before(time.Now()) ||
+			code.RedirectURI != args.RedirectURI ||
+			subtle.ConstantTimeCompare([]byte(challenge), []byte(code.CodeChallenge)) != 1 ||
+			!consent.Active() {
+			return ErrInvalidGrant
+		}
+
+		if err := q.UseOAuthAuthorizationCode(ctx, code.ID); err != nil {
+			return err
+		}
+
+		result.Consent = consent
+		result.RefreshToken, err = insertOAuthRefreshToken(ctx, q, consent.ID, args.RefreshTTL)
+		return err
+	})
+	if err == nil && replayed {
+		return OAuthGrantTxResult{}, ErrInvalidGrant
+	}
+	return result, err
+}
+
+type RefreshOAuthTokenTxParams struct {
+	RefreshToken string        `json:"-"`
+	ClientID     string        `json:"client_id"`
+	RefreshTTL   time.Duration `json:"refresh_ttl"`
+}
+
+// RefreshOAuthTokenTx rotates a refresh token: it is used up and replaced by
+// a new one. As with codes, a refresh token presented a second time revokes
+// its consent.
+func (store *SQLStore) RefreshOAuthTokenTx(ctx context.Context, args RefreshOAuthTokenTxParams) (result OAuthGrantTxResult, err error) {
+	ctx, span := store.tracer.Start(ctx, "RefreshOAuthTokenTx", trace.WithAttributes(
+		attribute.String("oauth.client_id", args.ClientID),
+	))
+	defer endSpan(span, &err)
+
+	var replayed bool
+	err = store.execTx(ctx, func(q *Queries) error {
+		token, err := q.GetOAuthRefreshTokenForUpdate(ctx, HashUserToken(args.RefreshToken))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInvalidGrant
+			}
+			return err
+		}
+
+		consent, err := q.GetOAuthConsent(ctx, token.ConsentID)
+		if err != nil {
+			return err
+		}
+		if consent.ClientID != args.ClientID {
+			return ErrInvalidGrant
+		}
+
+		if token.UsedAt.Valid {
+			replayed = true
+			return revokeConsent(ctx, q, consent)
+		}
+
+		if token.ExpiresAt.Before(time.Now()) || !consent.Active() {
+			return ErrInvalidGrant
+		}
+
+		if err := q.UseOAuthRefreshToken(ctx, token.ID); err != nil {
+			return err
+		}
+
+		result.Consent = consent
+		result.RefreshToken, err = insertOAuthRefreshToken(ctx, q, consent.ID, args.RefreshTTL)
+		return err
+	})
+	if err == nil && replayed {
+		return OAuthGrantTxResult{}, ErrInvalidGrant
+	}
+	return result, err
+}
+
+func insertOAuthRefreshToken(ctx context.Context, q *Queries, consentID int64, ttl time.Duration) (string, error) {
+	token, err := newUserToken()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = q.CreateOAuthRefreshToken(ctx, CreateOAuthRefreshTokenParams{
+		TokenHash: HashUserToken(token),
+		ConsentID: consentID,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	return token, err
+}
+
+// revokeConsent revokes consent unless that already happened.
+func revokeConsent(ctx context.Context, q *Queries, consent OAuthConsent) error {
+	_, err := q.RevokeOAuthConsent(ctx, RevokeOAuthConsentParams{
+		ID:       consent.ID,
+		Username: consent.Username,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	return err
+}
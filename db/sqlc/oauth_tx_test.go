@@ -0,0 +1,169 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+const testRedirectURI = "https://app.example.com/callback"
+
+func createRandomOAuthClient(t *testing.T, user User) OAuthClient {
+	id, err := NewOAuthClientID()
+	require.NoError(t, err)
+
+	arg := CreateOAuthClientParams{
+		ID:           id,
+		Name:         utils.RandomOwner(),
+		RedirectURIs: []string{testRedirectURI},
+		Scopes:       []string{"accounts:read", "payments:write"},
+		CreatedBy:    user.Username,
+	}
+
+	client, err := testQueries.CreateOAuthClient(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ID, client.ID)
+	require.Equal(t, arg.RedirectURIs, client.RedirectURIs)
+	require.Equal(t, arg.Scopes, client.Scopes)
+	require.False(t, client.SecretHash.Valid)
+
+	return client
+}
+
+func authorizeOAuthClient(t *testing.T, store Store, user User, client OAuthClient, verifier string) AuthorizeOAuthClientTxResult {
+	result, err := store.AuthorizeOAuthClientTx(context.Background(), AuthorizeOAuthClientTxParams{
+		Username:      user.Username,
+		ClientID:      client.ID,
+		Scopes:        []string{"accounts:read"},
+		RedirectURI:   testRedirectURI,
+		CodeChallenge: OAuthCodeChallenge(verifier),
+		ConsentTTL:    time.Hour,
+		CodeTTL:       time.Minute,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, result.Code)
+	require.True(t, result.Consent.Active())
+	require.Equal(t, []string{"accounts:read"}, result.Consent.Scopes)
+
+	return result
+}
+
+func TestOAuthCodeChallenge(t *testing.T) {
+	// RFC 7636 appendix B
+	require.Equal(t,
+		"E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM",
+		OAuthCodeChallenge("dBjftJeZ4CVP-1B0N2bCI6qvEVA5FPJLVWL1lgKIeTA"))
+}
+
+func TestExchangeOAuthCodeTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	client := createRandomOAuthClient(t, user)
+	verifier := utils.RandomString(43)
+	authorized := authorizeOAuthClient(t, store, user, client, verifier)
+
+	exchange := ExchangeOAuthCodeTxParams{
+		Code:         authorized.Code,
+		ClientID:     client.ID,
+		RedirectURI:  testRedirectURI,
+		CodeVerifier: verifier,
+		RefreshTTL:   time.Hour,
+	}
+
+	for _, wrong := range []ExchangeOAuthCodeTxParams{
+		{Code: "unknown", ClientID: client.ID, RedirectURI: testRedirectURI, CodeVerifier: verifier},
+		{Code: authorized.Code, ClientID: "other", RedirectURI: testRedirectURI, CodeVerifier: verifier},
+		{Code: authorized.Code, ClientID: client.ID, RedirectURI: "https://evil.example.com", CodeVerifier: verifier},
+		{Code: authorized.Code, ClientID: client.ID, RedirectURI: testRedirectURI, CodeVerifier: "wrong"},
+	} {
+		_, err := store.ExchangeOAuthCodeTx(context.Background(), wrong)
+		require.ErrorIs(t, err, ErrInvalidGrant)
+	}
+
+	result, err := store.ExchangeOAuthCodeTx(context.Background(), exchange)
+	require.NoError(t, err)
+	require.Equal(t, authorized.Consent.ID, result.Consent.ID)
+	require.NotEmpty(t, result.RefreshToken)
+
+	// using the code again revokes the consent
+	_, err = store.ExchangeOAuthCodeTx(context.Background(), exchange)
+	require.ErrorIs(t, err, ErrInvalidGrant)
+
+	consent, err := testQueries.GetOAuthConsent(context.Background(), authorized.Consent.ID)
+	require.NoError(t, err)
+	require.False(t, consent.Active())
+
+	_, err = store.RefreshOAuthTokenTx(context.Background(), RefreshOAuthTokenTxParams{
+		RefreshToken: result.RefreshToken,
+		ClientID:     client.ID,
+		RefreshTTL:   time.Hour,
+	})
+	require.ErrorIs(t, err, ErrInvalidGrant)
+}
+
+func TestRefreshOAuthTokenTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	client := createRandomOAuthClient(t, user)
+	verifier := utils.RandomString(43)
+	authorized := authorizeOAuthClient(t, store, user, client, verifier)
+
+	exchanged, err := store.ExchangeOAuthCodeTx(context.Background(), ExchangeOAuthCodeTxParams{
+		Code:         authorized.Code,
+		ClientID:     client.ID,
+		RedirectURI:  testRedirectURI,
+		CodeVerifier: verifier,
+		RefreshTTL:   time.Hour,
+	})
+	require.NoError(t, err)
+
+	refresh := RefreshOAuthTokenTxParams{
+		RefreshToken: exchanged.RefreshToken,
+		ClientID:     client.ID,
+		RefreshTTL:   time.Hour,
+	}
+	rotated, err := store.RefreshOAuthTokenTx(context.Background(), refresh)
+	require.NoError(t, err)
+	require.NotEqual(t, exchanged.RefreshToken, rotated.RefreshToken)
+	require.Equal(t, authorized.Consent.ID, rotated.Consent.ID)
+
+	// the rotated token was stolen: replaying it ends the consent
+	_, err = store.RefreshOAuthTokenTx(context.Background(), refresh)
+	require.ErrorIs(t, err, ErrInvalidGrant)
+
+	refresh.RefreshToken = rotated.RefreshToken
+	_, err = store.RefreshOAuthTokenTx(context.Background(), refresh)
+	require.ErrorIs(t, err, ErrInvalidGrant)
+}
+
+func TestAuthorizeOAuthClientTxReplacesConsent(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	client := createRandomOAuthClient(t, user)
+
+	first := authorizeOAuthClient(t, store, user, client, utils.RandomString(43))
+	second := authorizeOAuthClient(t, store, user, client, utils.RandomString(43))
+
+	consents, err := testQueries.ListOAuthConsents(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, consents, 1)
+	require.Equal(t, second.Consent.ID, consents[0].ID)
+	require.Equal(t, client.Name, consents[0].ClientName)
+
+	revoked, err := testQueries.RevokeOAuthConsent(context.Background(), RevokeOAuthConsentParams{
+		ID:       second.Consent.ID,
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+	require.False(t, revoked.Active())
+
+	_, err = testQueries.RevokeOAuthConsent(context.Background(), RevokeOAuthConsentParams{
+		ID:       first.Consent.ID,
+		Username: user.Username,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
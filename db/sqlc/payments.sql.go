@@ -7,26 +7,36 @@ package db
 
 import (
 	"context"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 const createPayment = `-- name: CreatePayment :one
 INSERT INTO payments (
   from_account_id,
   to_account_id,
-  amount
+  amount,
+  initiated_by
 ) VALUES (
-  $1, $2, $3
-) RETURNING id, created_at, updated_at, amount, from_account_id, to_account_id
+  $1, $2, $3, $4
+) RETURNING id, created_at, updated_at, amount, from_account_id, to_account_id, initiated_by
 `
 
 type CreatePaymentParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	InitiatedBy   string `json:"initiated_by"`
 }
 
 func (q *Queries) CreatePayment(ctx context.Context, arg CreatePaymentParams) (Payment, error) {
-	row := q.db.QueryRowContext(ctx, createPayment, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createPayment,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.InitiatedBy,
+	)
 	var i Payment
 	err := row.Scan(
 		&i.ID,
@@ -35,12 +45,59 @@ func (q *Queries) CreatePayment(ctx context.Context, arg CreatePaymentParams) (P
 		&i.Amount,
 		&i.FromAccountID,
 		&i.ToAccountID,
+		&i.InitiatedBy,
 	)
 	return i, err
 }
 
+const getAccountPaymentTotal = `-- name: GetAccountPaymentTotal :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total
+FROM payments
+WHERE from_account_id = $1
+  AND initiated_by = $2
+  AND created_at >= $3
+`
+
+type GetAccountPaymentTotalParams struct {
+	FromAccountID int64     `json:"from_account_id"`
+	InitiatedBy   string    `json:"initiated_by"`
+	Since         time.Time `json:"since"`
+}
+
+// sums what a user sent from one account since a time
+func (q *Queries) GetAccountPaymentTotal(ctx context.Context, arg GetAccountPaymentTotalParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getAccountPaymentTotal, arg.FromAccountID, arg.InitiatedBy, arg.Since)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const getOutgoingPaymentTotal = `-- name: GetOutgoingPaymentTotal :one
+SELECT COALESCE(SUM(payments.amount), 0)::bigint AS total
+FROM payments
+JOIN accounts ON accounts.id = payments.from_account_id
+WHERE payments.initiated_by = $1
+  AND accounts.currency = $2
+  AND payments.created_at >= $3
+`
+
+type GetOutgoingPaymentTotalParams struct {
+	InitiatedBy string    `json:"initiated_by"`
+	Currency    string    `json:"currency"`
+	Since       time.Time `json:"since"`
+}
+
+// sums what a user sent in a currency since a time, from any account they
+// may pay from
+func (q *Queries) GetOutgoingPaymentTotal(ctx context.Context, arg GetOutgoingPaymentTotalParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getOutgoingPaymentTotal, arg.InitiatedBy, arg.Currency, arg.Since)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
 const getPayment = `-- name: GetPayment :one
-SELECT id, created_at, updated_at, amount, from_account_id, to_account_id FROM payments
+SELECT id, created_at, updated_at, amount, from_account_id, to_account_id, initiated_by FROM payments
 WHERE id = $1 LIMIT 1
 `
 
@@ -54,12 +111,70 @@ func (q *Queries) GetPayment(ctx context.Context, id int64) (Payment, error) {
 		&i.Amount,
 		&i.FromAccountID,
 		&i.ToAccountID,
+		&i.InitiatedBy,
+	)
+	return i, err
+}
+
+const getPaymentHistory = `-- name: GetPaymentHistory :one
+SELECT
+  count(*) AS payment_count,
+  count(*) FILTER (WHERE to_accounts.owner = $1) AS payee_payments,
+  count(*) FILTER (WHERE payments.created_at >= $2) AS payments_last_hour,
+  count(*) FILTER (WHERE payments.created_at >= $3) AS payments_last_day,
+  COALESCE(SUM(payments.amount) FILTER (WHERE from_accounts.currency = $4 AND payments.created_at >= $3), 0)::bigint AS amount_last_day,
+  COALESCE(AVG(payments.amount) FILTER (WHERE from_accounts.currency = $4), 0)::float8 AS average_amount,
+  count(*) FILTER (WHERE payments.created_at >= $5) AS payments_since_password_change
+FROM payments
+JOIN accounts AS from_accounts ON from_accounts.id = payments.from_account_id
+JOIN accounts AS to_accounts ON to_accounts.id = payments.to_account_id
+WHERE payments.initiated_by = $6
+`
+
+type GetPaymentHistoryParams struct {
+	Payee             string    `json:"payee"`
+	HourAgo           time.Time `json:"hour_ago"`
+	DayAgo            time.Time `json:"day_ago"`
+	Currency          string    `json:"currency"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+	InitiatedBy       string    `json:"initiated_by"`
+}
+
+type GetPaymentHistoryRow struct {
+	PaymentCount                int64   `json:"payment_count"`
+	PayeePayments               int64   `json:"payee_payments"`
+	PaymentsLastHour            int64   `json:"payments_last_hour"`
+	PaymentsLastDay             int64   `json:"payments_last_day"`
+	AmountLastDay               int64   `json:"amount_last_day"`
+	AverageAmount               float64 `json:"average_amount"`
+	PaymentsSincePasswordChange int64   `json:"payments_since_password_change"`
+}
+
+// summarises the payments a user sent before, for the fraud rules
+func (q *Queries) GetPaymentHistory(ctx context.Context, arg GetPaymentHistoryParams) (GetPaymentHistoryRow, error) {
+	row := q.db.QueryRowContext(ctx, getPaymentHistory,
+		arg.Payee,
+		arg.HourAgo,
+		arg.DayAgo,
+		arg.Currency,
+		arg.PasswordChangedAt,
+		arg.InitiatedBy,
+	)
+	var i GetPaymentHistoryRow
+	err := row.Scan(
+		&i.PaymentCount,
+		&i.PayeePayments,
+		&i.PaymentsLastHour,
+		&i.PaymentsLastDay,
+		&i.AmountLastDay,
+		&i.AverageAmount,
+		&i.PaymentsSincePasswordChange,
 	)
 	return i, err
 }
 
 const listPayments = `-- name: ListPayments :many
-SELECT id, created_at, updated_at, amount, from_account_id, to_account_id FROM payments
+SELECT id, created_at, updated_at, amount, from_account_id, to_account_id, initiated_by FROM payments
 WHERE 
     from_account_id = $1 OR
     to_account_id = $2
@@ -96,6 +211,44 @@ func (q *Queries) ListPayments(ctx context.Context, arg ListPaymentsParams) ([]P
 			&i.Amount,
 			&i.FromAccountID,
 			&i.ToAccountID,
+			&i.InitiatedBy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPaymentsByID = `-- name: ListPaymentsByID :many
+SELECT id, created_at, updated_at, amount, from_account_id, to_account_id, initiated_by FROM payments
+WHERE id = ANY($1::bigint[])
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListPaymentsByID(ctx context.Context, ids []int64) ([]Payment, error) {
+	rows, err := q.db.QueryContext(ctx, listPaymentsByID, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Payment{}
+	for rows.Next() {
+		var i Payment
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Amount,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.InitiatedBy,
 		); err != nil {
 			return nil, err
 		}
@@ -14,6 +14,7 @@ func createRandomPayment(t *testing.T, account1, account2 Account) Payment {
 		FromAccountID: account1.ID,
 		ToAccountID:   account2.ID,
 		Amount:        utils.RandomMoney(),
+		InitiatedBy:   account1.Owner,
 	}
 
 	payment, err := testQueries.CreatePayment(context.Background(), args)
@@ -23,6 +24,7 @@ func createRandomPayment(t *testing.T, account1, account2 Account) Payment {
 	require.Equal(t, args.FromAccountID, payment.FromAccountID)
 	require.Equal(t, args.ToAccountID, payment.ToAccountID)
 	require.Equal(t, args.Amount, payment.Amount)
+	require.Equal(t, args.InitiatedBy, payment.InitiatedBy)
 
 	require.NotZero(t, payment.ID)
 	require.NotZero(t, payment.CreatedAt)
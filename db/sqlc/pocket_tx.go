@@ -0,0 +1,221 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Kinds of pocket moves: moves are made by the owner, round-ups are swept
+// from the payments out of the parent account.
+const (
+	PocketMoveKindMove    = "move"
+	PocketMoveKindRoundUp = "round_up"
+)
+
+type CreatePocketTxParams struct {
+	Owner      string       `json:"owner"`
+	Currency   string       `json:"currency"`
+	ParentID   int64        `json:"parent_id"`
+	Name       string       `json:"name"`
+	GoalAmount int64        `json:"goal_amount"`
+	TargetDate sql.NullTime `json:"target_date"`
+	RoundUpTo  int64        `json:"round_up_to"`
+}
+
+type CreatePocketTxResult struct {
+	Account Account `json:"account"`
+	Pocket  Pocket  `json:"pocket"`
+}
+
+// CreatePocketTx opens a pocket account under a parent account, with the
+// same owner and currency.
+func (store *SQLStore) CreatePocketTx(ctx context.Context, args CreatePocketTxParams) (result CreatePocketTxResult, err error) {
+	ctx, span := store.tracer.Start(ctx, "CreatePocketTx", trace.WithAttributes(
+		attribute.Int64("pocket.parent_id", args.ParentID),
+	))
+	defer endSpan(span, &err)
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.Account, err = q.CreatePocketAccount(ctx, CreatePocketAccountParams{
+			Owner:    args.Owner,
+			Currency: args.Currency,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Pocket, err = q.CreatePocket(ctx, CreatePocketParams{
+			AccountID:  result.Account.ID,
+			ParentID:   args.ParentID,
+			Name:       args.Name,
+			GoalAmount: args.GoalAmount,
+			TargetDate: args.TargetDate,
+			RoundUpTo:  args.RoundUpTo,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+type MovePocketTxParams struct {
+	// ParentID is the parent account of the pockets moved between; the
+	// caller checks that both accounts are it or one of its pockets.
+	ParentID      int64 `json:"parent_id"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	Amount        int64 `json:"amount"`
+}
+
+type MovePocketTxResult struct {
+	Move        PocketMove `json:"move"`
+	FromAccount Account    `json:"from_account"`
+	ToAccount   Account    `json:"to_account"`
+	FromEntry   Entry      `json:"from_entry"`
+	ToEntry     Entry      `json:"to_entry"`
+}
+
+// MovePocketTx moves money between a parent account and its pockets at once.
+// It fails with ErrAccountFrozen or ErrInsufficientFunds, and moves nothing,
+// when either account is frozen or the balance left once the card holds
+// are taken out doesn't cover the amount.
+func (store *SQLStore) MovePocketTx(ctx context.Context, args MovePocketTxParams) (result MovePocketTxResult, err error) {
+	ctx, span := store.tracer.Start(ctx, "MovePocketTx", trace.WithAttributes(
+		attribute.Int64("pocket_move.from_account_id", args.FromAccountID),
+		attribute.Int64("pocket_move.to_account_id", args.ToAccountID),
+		attribute.Int64("pocket_move.amount", args.Amount),
+	))
+	defer endSpan(span, &err)
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		// the accounts are locked lower ID first, like transfer updates them
+		first, second := min(args.FromAccountID, args.ToAccountID), max(args.FromAccountID, args.ToAccountID)
+		accounts := make(map[int64]Account, 2)
+		for _, id := range []int64{first, second} {
+			account, err := q.GetAccountForUpdate(ctx, id)
+			if err != nil {
+				return err
+			}
+			if account.Frozen {
+				return ErrAccountFrozen
+			}
+			accounts[id] = account
+		}
+
+		held, err := q.GetAccountHolds(ctx, args.FromAccountID)
+		if err != nil {
+			return err
+		}
+		if accounts[args.FromAccountID].Balance-held < args.Amount {
+			return ErrInsufficientFunds
+		}
+
+		result, err = store.move(ctx, q, CreatePocketMoveParams{
+			ParentID:      args.ParentID,
+			FromAccountID: args.FromAccountID,
+			ToAccountID:   args.ToAccountID,
+			Amount:        args.Amount,
+			Kind:          PocketMoveKindMove,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// spareChange is what is left from amount up to the next multiple of to.
+func spareChange(amount, to int64) int64 {
+	if to <= 0 {
+		return 0
+	}
+	return (to - amount%to) % to
+}
+
+// roundUp sweeps the spare change of a payment into the round-up pocket of
+// the account it was paid from, if it has one. Nothing is swept when the
+// balance left doesn't cover it or the pocket is frozen; the payment is made
+// either way. The pocket is updated after the accounts of the payment,
+// which it never is one of.
+func (store *SQLStore) roundUp(ctx context.Context, q *Queries, payment *PaymentTxResult) error {
+	pocket, err := q.GetRoundUpPocket(ctx, payment.Payment.FromAccountID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	spare := spareChange(payment.Payment.Amount, pocket.RoundUpTo)
+	if spare == 0 || pocket.AccountID == payment.Payment.ToAccountID {
+		return nil
+	}
+
+	held, err := q.GetAccountHolds(ctx, payment.FromAccount.ID)
+	if err != nil {
+		return err
+	}
+	if payment.FromAccount.Balance-held < spare {
+		return nil
+	}
+
+	account, err := q.GetAccountForUpdate(ctx, pocket.AccountID)
+	if err != nil || account.Frozen {
+		return err
+	}
+
+	sweep, err := store.move(ctx, q, CreatePocketMoveParams{
+		ParentID:      pocket.ParentID,
+		FromAccountID: pocket.ParentID,
+		ToAccountID:   pocket.AccountID,
+		Amount:        spare,
+		Kind:          PocketMoveKindRoundUp,
+		PaymentID:     sql.NullInt64{Int64: payment.Payment.ID, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	payment.RoundUp = &sweep.Move
+	payment.RoundUpSweep = &sweep
+	payment.FromAccount = sweep.FromAccount
+	return nil
+}
+
+// move books a pocket move: an entry on each account, the new balances and
+// the move itself. The entry IDs of args are filled in.
+func (store *SQLStore) move(ctx context.Context, q *Queries, args CreatePocketMoveParams) (result MovePocketTxResult, err error) {
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: args.FromAccountID,
+		Amount:    -args.Amount,
+	})
+	if err != nil {
+		return
+	}
+
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: args.ToAccountID,
+		Amount:    args.Amount,
+	})
+	if err != nil {
+		return
+	}
+
+	if args.FromAccountID < args.ToAccountID {
+		result.FromAccount, result.ToAccount, err = store.addMoney(ctx, q, args.FromAccountID, -args.Amount, args.ToAccountID, args.Amount)
+	} else {
+		result.ToAccount, result.FromAccount, err = store.addMoney(ctx, q, args.ToAccountID, args.Amount, args.FromAccountID, -args.Amount)
+	}
+	if err != nil {
+		return
+	}
+
+	args.FromEntryID = result.FromEntry.ID
+	args.ToEntryID = result.ToEntry.ID
+	result.Move, err = q.CreatePocketMove(ctx, args)
+	return
+}
@@ -0,0 +1,173 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPocket(t *testing.T, parent Account, roundUpTo int64) CreatePocketTxResult {
+	store := NewStore(testDB)
+	result, err := store.CreatePocketTx(context.Background(), CreatePocketTxParams{
+		Owner:      parent.Owner,
+		Currency:   parent.Currency,
+		ParentID:   parent.ID,
+		Name:       utils.RandomString(8),
+		GoalAmount: 50_000,
+		RoundUpTo:  roundUpTo,
+	})
+	require.NoError(t, err)
+	return result
+}
+
+func TestCreatePocketTx(t *testing.T) {
+	parent := createRandomAccount(t)
+
+	first := createRandomPocket(t, parent, 0)
+	require.Equal(t, AccountPocket, first.Account.Type)
+	require.Equal(t, parent.Owner, first.Account.Owner)
+	require.Equal(t, parent.Currency, first.Account.Currency)
+	require.Zero(t, first.Account.Balance)
+	require.Equal(t, first.Account.ID, first.Pocket.AccountID)
+	require.Equal(t, parent.ID, first.Pocket.ParentID)
+
+	// pockets share the owner and currency of their parent
+	second := createRandomPocket(t, parent, 100)
+
+	pockets, err := testQueries.ListPockets(context.Background(), parent.ID)
+	require.NoError(t, err)
+	require.Len(t, pockets, 2)
+	require.Equal(t, first.Pocket.AccountID, pockets[0].AccountID)
+	require.Equal(t, second.Pocket.AccountID, pockets[1].AccountID)
+
+	// one pocket of a parent rounds up
+	_, err = NewStore(testDB).CreatePocketTx(context.Background(), CreatePocketTxParams{
+		Owner:     parent.Owner,
+		Currency:  parent.Currency,
+		ParentID:  parent.ID,
+		Name:      utils.RandomString(8),
+		RoundUpTo: 100,
+	})
+	require.Error(t, err)
+
+	roundUp, err := testQueries.GetRoundUpPocket(context.Background(), parent.ID)
+	require.NoError(t, err)
+	require.Equal(t, second.Pocket.AccountID, roundUp.AccountID)
+}
+
+func TestMovePocketTx(t *testing.T) {
+	store := NewStore(testDB)
+	parent := createRandomAccount(t)
+	setBalance(t, parent, 1_000)
+	pocket := createRandomPocket(t, parent, 0)
+
+	result, err := store.MovePocketTx(context.Background(), MovePocketTxParams{
+		ParentID:      parent.ID,
+		FromAccountID: parent.ID,
+		ToAccountID:   pocket.Account.ID,
+		Amount:        700,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(300), result.FromAccount.Balance)
+	require.Equal(t, int64(700), result.ToAccount.Balance)
+	require.Equal(t, PocketMoveKindMove, result.Move.Kind)
+	require.False(t, result.Move.PaymentID.Valid)
+
+	entry, err := testQueries.GetEntry(context.Background(), result.Move.FromEntryID)
+	require.NoError(t, err)
+	require.Equal(t, int64(-700), entry.Amount)
+
+	// and back, which locks the accounts in the same order
+	result, err = store.MovePocketTx(context.Background(), MovePocketTxParams{
+		ParentID:      parent.ID,
+		FromAccountID: pocket.Account.ID,
+		ToAccountID:   parent.ID,
+		Amount:        200,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(500), result.FromAccount.Balance)
+	require.Equal(t, int64(500), result.ToAccount.Balance)
+
+	moves, err := testQueries.ListPocketMoves(context.Background(), ListPocketMovesParams{
+		AccountID: pocket.Account.ID,
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Len(t, moves, 2)
+	require.Equal(t, result.Move.ID, moves[0].ID)
+
+	_, err = store.MovePocketTx(context.Background(), MovePocketTxParams{
+		ParentID:      parent.ID,
+		FromAccountID: pocket.Account.ID,
+		ToAccountID:   parent.ID,
+		Amount:        501,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = testQueries.SetAccountFrozen(context.Background(), SetAccountFrozenParams{ID: parent.ID, Frozen: true})
+	require.NoError(t, err)
+	_, err = store.MovePocketTx(context.Background(), MovePocketTxParams{
+		ParentID:      parent.ID,
+		FromAccountID: pocket.Account.ID,
+		ToAccountID:   parent.ID,
+		Amount:        100,
+	})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+}
+
+func TestPaymentTxRoundUp(t *testing.T) {
+	store := NewStore(testDB)
+	parent := createRandomAccount(t)
+	setBalance(t, parent, 1_000)
+	payee := createRandomAccount(t)
+	pocket := createRandomPocket(t, parent, 100)
+
+	result, err := store.PaymentTx(context.Background(), PaymentTxParams{
+		FromAccountID: parent.ID,
+		ToAccountID:   payee.ID,
+		Amount:        430,
+		InitiatedBy:   parent.Owner,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, result.RoundUp)
+	require.Equal(t, int64(70), result.RoundUp.Amount)
+	require.Equal(t, PocketMoveKindRoundUp, result.RoundUp.Kind)
+	require.Equal(t, sql.NullInt64{Int64: result.Payment.ID, Valid: true}, result.RoundUp.PaymentID)
+	require.Equal(t, int64(500), result.FromAccount.Balance)
+
+	account, err := testQueries.GetAccount(context.Background(), pocket.Account.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(70), account.Balance)
+
+	// a round amount has no spare change
+	result, err = store.PaymentTx(context.Background(), PaymentTxParams{
+		FromAccountID: parent.ID,
+		ToAccountID:   payee.ID,
+		Amount:        200,
+		InitiatedBy:   parent.Owner,
+	})
+	require.NoError(t, err)
+	require.Nil(t, result.RoundUp)
+
+	// the payment is made even when the spare change can't be swept
+	setBalance(t, parent, 50)
+	result, err = store.PaymentTx(context.Background(), PaymentTxParams{
+		FromAccountID: parent.ID,
+		ToAccountID:   payee.ID,
+		Amount:        20,
+		InitiatedBy:   parent.Owner,
+	})
+	require.NoError(t, err)
+	require.Nil(t, result.RoundUp)
+	require.Equal(t, int64(30), result.FromAccount.Balance)
+}
+
+func TestSpareChange(t *testing.T) {
+	require.Equal(t, int64(70), spareChange(430, 100))
+	require.Equal(t, int64(0), spareChange(400, 100))
+	require.Equal(t, int64(1), spareChange(99, 100))
+	require.Equal(t, int64(0), spareChange(430, 0))
+}
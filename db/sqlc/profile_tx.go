@@ -0,0 +1,190 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type RequestEmailChangeTxParams struct {
+	Username string        `json:"username"`
+	OldEmail string        `json:"old_email"`
+	NewEmail string        `json:"new_email"`
+	TTL      time.Duration `json:"ttl"`
+}
+
+// RequestEmailChangeTxResult holds the token to send to each address. Like
+// IssueUserTokenResult, the tokens cannot be recovered later.
+type RequestEmailChangeTxResult struct {
+	EmailChange EmailChange `json:"email_change"`
+	OldToken    string      `json:"-"`
+	NewToken    string      `json:"-"`
+}
+
+// RequestEmailChangeTx starts replacing the email of a user with NewEmail.
+// It issues one TokenEmailChange for the current address and one for the new
+// one; the change only happens once both were used. A change requested
+// before stops working.
+func (store *SQLStore) RequestEmailChangeTx(ctx context.Context, args RequestEmailChangeTxParams) (result RequestEmailChangeTxResult, err error) {
+	ctx, span := store.tracer.Start(ctx, "RequestEmailChangeTx", trace.WithAttributes(
+		attribute.String("user.username", args.Username),
+	))
+	defer endSpan(span, &err)
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		err := q.InvalidateUserTokens(ctx, InvalidateUserTokensParams{
+			Username: args.Username,
+			Purpose:  TokenEmailChange,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.EmailChange, err = q.CreateEmailChange(ctx, CreateEmailChangeParams{
+			Username:  args.Username,
+			OldEmail:  args.OldEmail,
+			NewEmail:  args.NewEmail,
+			ExpiresAt: time.Now().Add(args.TTL),
+		})
+		if err != nil {
+			return err
+		}
+
+		oldToken, err := insertUserToken(ctx, q, IssueUserTokenParams{
+			Username: args.Username,
+			Purpose:  TokenEmailChange,
+			Email:    args.OldEmail,
+			TTL:      args.TTL,
+		})
+		if err != nil {
+			return err
+		}
+		result.OldToken = oldToken.Token
+
+		newToken, err := insertUserToken(ctx, q, IssueUserTokenParams{
+			Username: args.Username,
+			Purpose:  TokenEmailChange,
+			Email:    args.NewEmail,
+			TTL:      args.TTL,
+		})
+		if err != nil {
+			return err
+		}
+		result.NewToken = newToken.Token
+		return nil
+	})
+
+	return result, err
+}
+
+type ConfirmEmailChangeTxResult struct {
+	EmailChange EmailChange `json:"email_change"`
+	// Completed is set when this confirmation was the second one. User then
+	// has the new email.
+	Completed bool `json:"completed"`
+	User      User `json:"user"`
+}
+
+// ConfirmEmailChangeTx uses a TokenEmailChange sent to either address of the
+// latest change of the user. With the second confirmation the email of the
+// user is replaced, and the tokens sent to the old address stop working.
+func (store *SQLStore) ConfirmEmailChangeTx(ctx context.Context, token string) (result ConfirmEmailChangeTxResult, err error) {
+	ctx, span := store.tracer.Start(ctx, "ConfirmEmailChangeTx")
+	defer endSpan(span, &err)
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		userToken, err := useUserToken(ctx, q, token, TokenEmailChange)
+		if err != nil {
+			return err
+		}
+
+		change, err := q.GetLatestEmailChangeForUpdate(ctx, userToken.Username)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInvalidUserToken
+			}
+			return err
+		}
+		if change.CompletedAt.Valid || change.ExpiresAt.Before(time.Now()) || userToken.CreatedAt.Before(change.CreatedAt) {
+			return ErrInvalidUserToken
+		}
+
+		result.EmailChange, err = q.ConfirmEmailChange(ctx, ConfirmEmailChangeParams{
+			Email: userToken.Email,
+			ID:    change.ID,
+		})
+		if err != nil {
+			return err
+		}
+		if !result.EmailChange.OldConfirmedAt.Valid || !result.EmailChange.NewConfirmedAt.Valid {
+			return nil
+		}
+
+		result.User, err = q.UpdateUserEmail(ctx, UpdateUserEmailParams{
+			NewEmail: change.NewEmail,
+			Username: change.Username,
+			OldEmail: change.OldEmail,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInvalidUserToken
+			}
+			return err
+		}
+
+		result.EmailChange, err = q.CompleteEmailChange(ctx, change.ID)
+		if err != nil {
+			return err
+		}
+		result.Completed = true
+
+		for _, purpose := range []string{TokenEmailVerification, TokenPasswordReset} {
+			err = q.InvalidateUserTokens(ctx, InvalidateUserTokensParams{
+				Username: change.Username,
+				Purpose:  purpose,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+type ChangePasswordTxParams struct {
+	Username       string `json:"username"`
+	HashedPassword string `json:"-"`
+}
+
+// ChangePasswordTx sets a new password for a user who knows the current one.
+// Like ResetPasswordTx it revokes the access tokens issued before, and the
+// password reset links still out stop working.
+func (store *SQLStore) ChangePasswordTx(ctx context.Context, args ChangePasswordTxParams) (user User, err error) {
+	ctx, span := store.tracer.Start(ctx, "ChangePasswordTx", trace.WithAttributes(
+		attribute.String("user.username", args.Username),
+	))
+	defer endSpan(span, &err)
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		user, err = q.UpdatePassword(ctx, UpdatePasswordParams{
+			HashedPassword: args.HashedPassword,
+			Username:       args.Username,
+		})
+		if err != nil {
+			return err
+		}
+
+		return q.InvalidateUserTokens(ctx, InvalidateUserTokensParams{
+			Username: args.Username,
+			Purpose:  TokenPasswordReset,
+		})
+	})
+
+	return user, err
+}
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func requestEmailChange(t *testing.T, store Store, user User, newEmail string) RequestEmailChangeTxResult {
+	result, err := store.RequestEmailChangeTx(context.Background(), RequestEmailChangeTxParams{
+		Username: user.Username,
+		OldEmail: user.Email,
+		NewEmail: newEmail,
+		TTL:      time.Hour,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, result.OldToken)
+	require.NotEmpty(t, result.NewToken)
+	require.NotEqual(t, result.OldToken, result.NewToken)
+	require.Equal(t, user.Email, result.EmailChange.OldEmail)
+	require.Equal(t, newEmail, result.EmailChange.NewEmail)
+
+	return result
+}
+
+func TestEmailChangeTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	newEmail := utils.RandomEmail()
+
+	reset := issueUserToken(t, store, user, TokenPasswordReset, time.Hour)
+	change := requestEmailChange(t, store, user, newEmail)
+
+	// the new address confirms first; nothing changes yet
+	result, err := store.ConfirmEmailChangeTx(context.Background(), change.NewToken)
+	require.NoError(t, err)
+	require.False(t, result.Completed)
+	require.True(t, result.EmailChange.NewConfirmedAt.Valid)
+	require.False(t, result.EmailChange.OldConfirmedAt.Valid)
+
+	current, err := testQueries.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, user.Email, current.Email)
+
+	_, err = store.ConfirmEmailChangeTx(context.Background(), change.NewToken)
+	require.ErrorIs(t, err, ErrInvalidUserToken)
+
+	result, err = store.ConfirmEmailChangeTx(context.Background(), change.OldToken)
+	require.NoError(t, err)
+	require.True(t, result.Completed)
+	require.True(t, result.EmailChange.CompletedAt.Valid)
+	require.Equal(t, newEmail, result.User.Email)
+	require.False(t, result.User.EmailVerifiedAt.IsZero())
+
+	// a reset link sent to the old address stops working
+	_, err = store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{Token: reset, HashedPassword: "hash"})
+	require.ErrorIs(t, err, ErrInvalidUserToken)
+}
+
+func TestEmailChangeTxSuperseded(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	first := requestEmailChange(t, store, user, utils.RandomEmail())
+	second := requestEmailChange(t, store, user, utils.RandomEmail())
+
+	_, err := store.ConfirmEmailChangeTx(context.Background(), first.OldToken)
+	require.ErrorIs(t, err, ErrInvalidUserToken)
+
+	result, err := store.ConfirmEmailChangeTx(context.Background(), second.OldToken)
+	require.NoError(t, err)
+	require.Equal(t, second.EmailChange.ID, result.EmailChange.ID)
+	require.False(t, result.Completed)
+}
+
+func TestChangePasswordTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	reset := issueUserToken(t, store, user, TokenPasswordReset, time.Hour)
+
+	hash, err := utils.HashPassword(utils.RandomString(8))
+	require.NoError(t, err)
+
+	updated, err := store.ChangePasswordTx(context.Background(), ChangePasswordTxParams{
+		Username:       user.Username,
+		HashedPassword: hash,
+	})
+	require.NoError(t, err)
+	require.Equal(t, hash, updated.HashedPassword)
+	require.WithinDuration(t, time.Now(), updated.PasswordChangedAt, 5*time.Second)
+
+	_, err = store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{Token: reset, HashedPassword: hash})
+	require.ErrorIs(t, err, ErrInvalidUserToken)
+}
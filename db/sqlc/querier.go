@@ -6,24 +6,245 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 type Querier interface {
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AssignAMLAlert(ctx context.Context, arg AssignAMLAlertParams) (AMLAlert, error)
+	CancelLoanInstallments(ctx context.Context, loanID int64) error
+	CaptureCardAuthorization(ctx context.Context, arg CaptureCardAuthorizationParams) (CardAuthorization, error)
+	CloseLoan(ctx context.Context, id int64) (Loan, error)
+	CompleteEmailChange(ctx context.Context, id int64) (EmailChange, error)
+	// records the confirmation from email, which is either the old or the new
+	// address of the change
+	ConfirmEmailChange(ctx context.Context, arg ConfirmEmailChangeParams) (EmailChange, error)
+	ConsumeUserToken(ctx context.Context, arg ConsumeUserTokenParams) (UserToken, error)
+	// pockets are part of their parent account and aren't counted
+	CountAccounts(ctx context.Context, owner string) (int64, error)
+	// counts the hits of the users that are open or confirmed
+	CountBlockingScreeningHits(ctx context.Context, usernames []string) (int64, error)
+	CountUnpaidLoanInstallments(ctx context.Context, loanID int64) (int64, error)
+	CreateAMLAlertComment(ctx context.Context, arg CreateAMLAlertCommentParams) (AMLAlertComment, error)
+	CreateAMLJobRun(ctx context.Context, arg CreateAMLJobRunParams) (AMLJobRun, error)
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (APIKey, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAccountInvitation(ctx context.Context, arg CreateAccountInvitationParams) (AccountInvitation, error)
+	CreateAdjustment(ctx context.Context, arg CreateAdjustmentParams) (Adjustment, error)
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error)
+	CreateCard(ctx context.Context, arg CreateCardParams) (Card, error)
+	CreateCardAuthorization(ctx context.Context, arg CreateCardAuthorizationParams) (CardAuthorization, error)
+	CreateEmailChange(ctx context.Context, arg CreateEmailChangeParams) (EmailChange, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateFraudDecision(ctx context.Context, arg CreateFraudDecisionParams) (FraudDecision, error)
+	CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (InterestAccrual, error)
+	CreateInterestPayout(ctx context.Context, arg CreateInterestPayoutParams) (InterestPayout, error)
+	CreateKYCDocument(ctx context.Context, arg CreateKYCDocumentParams) (KYCDocument, error)
+	CreateLoan(ctx context.Context, arg CreateLoanParams) (Loan, error)
+	CreateLoanInstallment(ctx context.Context, arg CreateLoanInstallmentParams) (LoanInstallment, error)
+	CreateLoanProduct(ctx context.Context, arg CreateLoanProductParams) (LoanProduct, error)
+	CreateLoanRepayment(ctx context.Context, arg CreateLoanRepaymentParams) (LoanRepayment, error)
+	CreateNotification(ctx context.Context, arg CreateNotificationParams) (Notification, error)
+	CreateOAuthAuthorizationCode(ctx context.Context, arg CreateOAuthAuthorizationCodeParams) (OAuthAuthorizationCode, error)
+	CreateOAuthClient(ctx context.Context, arg CreateOAuthClientParams) (OAuthClient, error)
+	CreateOAuthConsent(ctx context.Context, arg CreateOAuthConsentParams) (OAuthConsent, error)
+	CreateOAuthRefreshToken(ctx context.Context, arg CreateOAuthRefreshTokenParams) (OAuthRefreshToken, error)
 	CreatePayment(ctx context.Context, arg CreatePaymentParams) (Payment, error)
+	CreatePocket(ctx context.Context, arg CreatePocketParams) (Pocket, error)
+	CreatePocketAccount(ctx context.Context, arg CreatePocketAccountParams) (Account, error)
+	CreatePocketMove(ctx context.Context, arg CreatePocketMoveParams) (PocketMove, error)
+	CreateSavingsAccount(ctx context.Context, arg CreateSavingsAccountParams) (Account, error)
+	CreateSavingsAccountLink(ctx context.Context, arg CreateSavingsAccountLinkParams) (SavingsAccount, error)
+	CreateSavingsProduct(ctx context.Context, arg CreateSavingsProductParams) (SavingsProduct, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateUserToken(ctx context.Context, arg CreateUserTokenParams) (UserToken, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	DeleteAccountMember(ctx context.Context, arg DeleteAccountMemberParams) (AccountMember, error)
+	// buckets nobody used since updated_before have refilled and behave like
+	// missing ones
+	DeleteStaleRateLimitBuckets(ctx context.Context, updatedBefore time.Time) (int64, error)
+	ExpireAdjustments(ctx context.Context) ([]Adjustment, error)
+	// finds accounts opened before dormant_since without entries from then
+	// until since, which moved at least min_amount since
+	FindDormantReactivations(ctx context.Context, arg FindDormantReactivationsParams) ([]FindDormantReactivationsRow, error)
+	// finds accounts that sent on most of the money they received, after
+	// receiving it
+	FindPassThrough(ctx context.Context, arg FindPassThroughParams) ([]FindPassThroughRow, error)
+	// finds payments to another owner that came back to the payer within
+	// max_delay_hours for about the same amount
+	FindRoundTrips(ctx context.Context, arg FindRoundTripsParams) ([]FindRoundTripsRow, error)
+	// finds accounts that sent several payments just under the threshold which
+	// add up to more than it
+	FindStructuring(ctx context.Context, arg FindStructuringParams) ([]FindStructuringRow, error)
+	FinishAMLJobRun(ctx context.Context, arg FinishAMLJobRunParams) (AMLJobRun, error)
+	FreezeOwnerAccounts(ctx context.Context, owner string) ([]Account, error)
+	GetAMLAlert(ctx context.Context, id int64) (AMLAlert, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (APIKey, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	// what the approved authorizations not expired yet hold on an account
+	GetAccountHolds(ctx context.Context, accountID int64) (int64, error)
+	GetAccountInvitation(ctx context.Context, id int64) (AccountInvitation, error)
+	GetAccountInvitationForUpdate(ctx context.Context, id int64) (AccountInvitation, error)
+	GetAccountMember(ctx context.Context, arg GetAccountMemberParams) (AccountMember, error)
+	// sums what a user sent from one account since a time
+	GetAccountPaymentTotal(ctx context.Context, arg GetAccountPaymentTotalParams) (int64, error)
+	GetAdjustment(ctx context.Context, id int64) (Adjustment, error)
+	GetAdjustmentForUpdate(ctx context.Context, id int64) (Adjustment, error)
+	// the principal a borrower owes under a product, and how many of their loans
+	// have installments due before a day that aren't paid
+	GetBorrowerLoanExposure(ctx context.Context, arg GetBorrowerLoanExposureParams) (GetBorrowerLoanExposureRow, error)
+	GetCard(ctx context.Context, id int64) (Card, error)
+	GetCardAuthorizationByRRN(ctx context.Context, arg GetCardAuthorizationByRRNParams) (CardAuthorization, error)
+	GetCardAuthorizationForUpdate(ctx context.Context, id int64) (CardAuthorization, error)
+	GetCardByPAN(ctx context.Context, pan string) (Card, error)
+	GetCardSettlementAccount(ctx context.Context, currency string) (Account, error)
+	// what a card was authorized for since a time, captured or still held
+	GetCardSpend(ctx context.Context, arg GetCardSpendParams) (int64, error)
+	// the balance of an account at a time, from its balance now and the entries
+	// booked since
+	GetEndOfDayBalance(ctx context.Context, arg GetEndOfDayBalanceParams) (int64, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetFraudDecision(ctx context.Context, id int64) (FraudDecision, error)
+	GetFraudDecisionForUpdate(ctx context.Context, id int64) (FraudDecision, error)
+	GetInterestExpenseAccount(ctx context.Context, currency string) (Account, error)
+	GetKYCDocument(ctx context.Context, id int64) (KYCDocument, error)
+	GetKYCProfile(ctx context.Context, username string) (KYCProfile, error)
+	GetLastInterestAccrual(ctx context.Context, accountID int64) (InterestAccrual, error)
+	GetLastInterestPayout(ctx context.Context, accountID int64) (InterestPayout, error)
+	GetLatestEmailChangeForUpdate(ctx context.Context, username string) (EmailChange, error)
+	GetLedgerTotals(ctx context.Context) (GetLedgerTotalsRow, error)
+	GetLoan(ctx context.Context, id int64) (Loan, error)
+	// what is owed on the installments due before a day
+	GetLoanArrears(ctx context.Context, arg GetLoanArrearsParams) (GetLoanArrearsRow, error)
+	GetLoanForUpdate(ctx context.Context, id int64) (Loan, error)
+	GetLoanInstallment(ctx context.Context, id int64) (LoanInstallment, error)
+	GetLoanInstallmentForUpdate(ctx context.Context, id int64) (LoanInstallment, error)
+	GetLoanProduct(ctx context.Context, id int64) (LoanProduct, error)
+	GetLoansAccount(ctx context.Context, currency string) (Account, error)
+	GetOAuthAuthorizationCodeForUpdate(ctx context.Context, codeHash string) (OAuthAuthorizationCode, error)
+	GetOAuthClient(ctx context.Context, id string) (OAuthClient, error)
+	GetOAuthConsent(ctx context.Context, id int64) (OAuthConsent, error)
+	GetOAuthRefreshTokenForUpdate(ctx context.Context, tokenHash string) (OAuthRefreshToken, error)
+	// sums what a user sent in a currency since a time, from any account they
+	// may pay from
+	GetOutgoingPaymentTotal(ctx context.Context, arg GetOutgoingPaymentTotalParams) (int64, error)
 	GetPayment(ctx context.Context, id int64) (Payment, error)
+	// summarises the payments a user sent before, for the fraud rules
+	GetPaymentHistory(ctx context.Context, arg GetPaymentHistoryParams) (GetPaymentHistoryRow, error)
+	GetPocket(ctx context.Context, accountID int64) (Pocket, error)
+	GetRoundUpPocket(ctx context.Context, parentID int64) (Pocket, error)
+	GetSavingsAccount(ctx context.Context, accountID int64) (SavingsAccount, error)
+	GetSavingsProduct(ctx context.Context, id int64) (SavingsProduct, error)
+	GetScreeningHit(ctx context.Context, id int64) (ScreeningHit, error)
+	GetScreeningHitForUpdate(ctx context.Context, id int64) (ScreeningHit, error)
+	GetSuspenseAccount(ctx context.Context, currency string) (Account, error)
+	// the interest accrued before a day and not paid out yet
+	GetUnpaidInterest(ctx context.Context, arg GetUnpaidInterestParams) (GetUnpaidInterestRow, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserForUpdate(ctx context.Context, username string) (User, error)
+	InvalidateUserTokens(ctx context.Context, arg InvalidateUserTokensParams) error
+	ListAMLAlertComments(ctx context.Context, alertID int64) ([]AMLAlertComment, error)
+	ListAMLAlerts(ctx context.Context, arg ListAMLAlertsParams) ([]AMLAlert, error)
+	ListAMLJobRuns(ctx context.Context, arg ListAMLJobRunsParams) ([]AMLJobRun, error)
+	ListAPIKeys(ctx context.Context, username string) ([]APIKey, error)
+	// the pending invitations to an account, expired or not
+	ListAccountInvitations(ctx context.Context, accountID int64) ([]AccountInvitation, error)
+	ListAccountMembers(ctx context.Context, accountID int64) ([]AccountMember, error)
+	// the accounts of a user and those shared with them, with their pockets
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListAdjustments(ctx context.Context, arg ListAdjustmentsParams) ([]Adjustment, error)
+	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error)
+	ListCardAuthorizations(ctx context.Context, arg ListCardAuthorizationsParams) ([]CardAuthorization, error)
+	ListCards(ctx context.Context, owner string) ([]Card, error)
+	// pages through the installments due on or before a day that aren't paid
+	ListDueLoanInstallments(ctx context.Context, arg ListDueLoanInstallmentsParams) ([]LoanInstallment, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListEntriesByID(ctx context.Context, ids []int64) ([]Entry, error)
+	ListFraudDecisions(ctx context.Context, arg ListFraudDecisionsParams) ([]FraudDecision, error)
+	ListInterestAccruals(ctx context.Context, arg ListInterestAccrualsParams) ([]InterestAccrual, error)
+	ListInterestPayouts(ctx context.Context, arg ListInterestPayoutsParams) ([]InterestPayout, error)
+	ListKYCDocuments(ctx context.Context, username string) ([]KYCDocument, error)
+	// lists the profiles waiting for review, the oldest submission first
+	ListKYCReviewQueue(ctx context.Context, arg ListKYCReviewQueueParams) ([]KYCProfile, error)
+	ListLedgerMismatches(ctx context.Context) ([]ListLedgerMismatchesRow, error)
+	// the installments of a loan in the order they are due, the cancelled ones
+	// included
+	ListLoanInstallments(ctx context.Context, loanID int64) ([]LoanInstallment, error)
+	ListLoanProducts(ctx context.Context) ([]LoanProduct, error)
+	ListLoanRepayments(ctx context.Context, loanID int64) ([]LoanRepayment, error)
+	ListLoans(ctx context.Context, arg ListLoansParams) ([]Loan, error)
+	ListNotifications(ctx context.Context, arg ListNotificationsParams) ([]Notification, error)
+	ListOAuthClients(ctx context.Context) ([]OAuthClient, error)
+	// lists the consents of a user that are in force
+	ListOAuthConsents(ctx context.Context, username string) ([]ListOAuthConsentsRow, error)
 	ListPayments(ctx context.Context, arg ListPaymentsParams) ([]Payment, error)
-	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	ListPaymentsByID(ctx context.Context, ids []int64) ([]Payment, error)
+	ListPocketMoves(ctx context.Context, arg ListPocketMovesParams) ([]PocketMove, error)
+	ListPockets(ctx context.Context, parentID int64) ([]Pocket, error)
+	// pages through the savings accounts with their product, by id
+	ListSavingsAccounts(ctx context.Context, arg ListSavingsAccountsParams) ([]ListSavingsAccountsRow, error)
+	ListSavingsProducts(ctx context.Context) ([]SavingsProduct, error)
+	ListScreeningHits(ctx context.Context, arg ListScreeningHitsParams) ([]ScreeningHit, error)
+	// the invitations a user may still accept
+	ListUserInvitations(ctx context.Context, arg ListUserInvitationsParams) ([]AccountInvitation, error)
+	LockUser(ctx context.Context, arg LockUserParams) (User, error)
+	MarkAdjustmentApproved(ctx context.Context, arg MarkAdjustmentApprovedParams) (Adjustment, error)
+	MarkAdjustmentExpired(ctx context.Context, id int64) (Adjustment, error)
+	MarkAdjustmentRejected(ctx context.Context, arg MarkAdjustmentRejectedParams) (Adjustment, error)
+	MarkEmailVerified(ctx context.Context, arg MarkEmailVerifiedParams) (User, error)
+	MarkInterestAccrualsPaid(ctx context.Context, arg MarkInterestAccrualsPaidParams) error
+	RecordFailedLogin(ctx context.Context, username string) (User, error)
+	ReduceLoanPrincipal(ctx context.Context, arg ReduceLoanPrincipalParams) (Loan, error)
+	ResetFailedLogins(ctx context.Context, username string) error
+	// closes or escalates an open alert
+	ResolveAMLAlert(ctx context.Context, arg ResolveAMLAlertParams) (AMLAlert, error)
+	RespondAccountInvitation(ctx context.Context, arg RespondAccountInvitationParams) (AccountInvitation, error)
+	// releases the hold of an approved authorization
+	ReverseCardAuthorization(ctx context.Context, id int64) (CardAuthorization, error)
+	ReviewFraudDecision(ctx context.Context, arg ReviewFraudDecisionParams) (FraudDecision, error)
+	ReviewKYCProfile(ctx context.Context, arg ReviewKYCProfileParams) (KYCProfile, error)
+	ReviewScreeningHit(ctx context.Context, arg ReviewScreeningHitParams) (ScreeningHit, error)
+	RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (APIKey, error)
+	// revokes the consents a user gave to a client before
+	RevokeOAuthClientConsents(ctx context.Context, arg RevokeOAuthClientConsentsParams) error
+	RevokeOAuthConsent(ctx context.Context, arg RevokeOAuthConsentParams) (OAuthConsent, error)
+	// revokes every key of a user that is still active
+	RevokeUserAPIKeys(ctx context.Context, username string) error
+	// revokes every consent of a user, which ends their refresh tokens too
+	RevokeUserOAuthConsents(ctx context.Context, username string) error
+	SetAccountFrozen(ctx context.Context, arg SetAccountFrozenParams) (Account, error)
+	SetFraudDecisionPayment(ctx context.Context, arg SetFraudDecisionPaymentParams) (FraudDecision, error)
+	SetKYCStatus(ctx context.Context, arg SetKYCStatusParams) (User, error)
+	SetLoanDisbursement(ctx context.Context, arg SetLoanDisbursementParams) (Loan, error)
+	SetUserDisabled(ctx context.Context, arg SetUserDisabledParams) (User, error)
+	SetUserRole(ctx context.Context, arg SetUserRoleParams) (User, error)
+	SubmitKYCProfile(ctx context.Context, username string) (KYCProfile, error)
+	// refills the bucket for the time since it was last used, up to burst, and
+	// takes a token if there is a whole one left, all in one statement
+	TakeRateLimitToken(ctx context.Context, arg TakeRateLimitTokenParams) (TakeRateLimitTokenRow, error)
+	// records that the key was used, at most once a minute so that a busy
+	// integration doesn't write the row on every request
+	TouchAPIKey(ctx context.Context, id int64) error
+	UnlockUser(ctx context.Context, username string) (User, error)
+	UpdateCard(ctx context.Context, arg UpdateCardParams) (Card, error)
+	UpdateLoanInstallment(ctx context.Context, arg UpdateLoanInstallmentParams) (LoanInstallment, error)
+	UpdatePassword(ctx context.Context, arg UpdatePasswordParams) (User, error)
+	UpdatePocket(ctx context.Context, arg UpdatePocketParams) (Pocket, error)
+	// only replaces the email if it is still old_email; the new address counts
+	// as verified
+	UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (User, error)
+	UpdateUserFullName(ctx context.Context, arg UpdateUserFullNameParams) (User, error)
+	// opens an alert, or updates the open alert of the account for the
+	// scenario. Returns no row when an alert already holds all the evidence
+	UpsertAMLAlert(ctx context.Context, arg UpsertAMLAlertParams) (AMLAlert, error)
+	UpsertAccountMember(ctx context.Context, arg UpsertAccountMemberParams) (AccountMember, error)
+	UpsertKYCProfile(ctx context.Context, arg UpsertKYCProfileParams) (KYCProfile, error)
+	// records a match, opening it again if it was reviewed for another name
+	UpsertScreeningHit(ctx context.Context, arg UpsertScreeningHitParams) (ScreeningHit, error)
+	UseOAuthAuthorizationCode(ctx context.Context, id int64) error
+	UseOAuthRefreshToken(ctx context.Context, id int64) error
 }
 
 var _ Querier = (*Queries)(nil)
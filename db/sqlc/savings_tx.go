@@ -0,0 +1,208 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Types of accounts. Customers hold checking and savings accounts, and
+// pockets set aside inside their checking accounts; the system user holds a
+// suspense, an interest expense, a loans and a card settlement account per
+// currency.
+const (
+	AccountChecking        = "checking"
+	AccountSavings         = "savings"
+	AccountSuspense        = "suspense"
+	AccountInterestExpense = "interest_expense"
+	AccountLoans           = "loans"
+	AccountCardSettlement  = "card_settlement"
+	AccountPocket          = "pocket"
+)
+
+// Day-count conventions of savings products.
+const (
+	DayCountACT365 = "act/365"
+	DayCount30360  = "30/360"
+)
+
+// Compounding of savings products: with daily compounding, interest accrued
+// but not paid out yet earns interest too.
+const (
+	CompoundingNone  = "none"
+	CompoundingDaily = "daily"
+)
+
+// How often savings products pay out the interest accrued.
+const (
+	PayoutMonthly   = "monthly"
+	PayoutQuarterly = "quarterly"
+	PayoutAnnually  = "annually"
+)
+
+// InterestMicros is how many accrual units make a minor unit. Interest is
+// accrued in millionths of a minor unit and only paid out whole.
+const InterestMicros = 1_000_000
+
+// ErrNoInterestDue is returned by PayInterestTx when nothing accrued before
+// the end of the period is left to pay.
+var ErrNoInterestDue = errors.New("no interest accrued for the period")
+
+type CreateSavingsAccountTxParams struct {
+	Owner     string `json:"owner"`
+	Currency  string `json:"currency"`
+	ProductID int64  `json:"product_id"`
+}
+
+type CreateSavingsAccountTxResult struct {
+	Account        Account        `json:"account"`
+	SavingsAccount SavingsAccount `json:"savings_account"`
+}
+
+// CreateSavingsAccountTx opens a savings account under a product.
+func (store *SQLStore) CreateSavingsAccountTx(ctx context.Context, args CreateSavingsAccountTxParams) (result CreateSavingsAccountTxResult, err error) {
+	ctx, span := store.tracer.Start(ctx, "CreateSavingsAccountTx", trace.WithAttributes(
+		attribute.Int64("savings_product.id", args.ProductID),
+	))
+	defer endSpan(span, &err)
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.Account, err = q.CreateSavingsAccount(ctx, CreateSavingsAccountParams{
+			Owner:    args.Owner,
+			Currency: args.Currency,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.SavingsAccount, err = q.CreateSavingsAccountLink(ctx, CreateSavingsAccountLinkParams{
+			AccountID: result.Account.ID,
+			ProductID: args.ProductID,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+type PayInterestTxParams struct {
+	AccountID int64 `json:"account_id"`
+	// PeriodEnd is the first day not paid for: every accrual before it is.
+	PeriodEnd time.Time `json:"period_end"`
+}
+
+type PayInterestTxResult struct {
+	Payout         InterestPayout `json:"payout"`
+	Account        Account        `json:"account"`
+	ExpenseAccount Account        `json:"expense_account"`
+	Entry          Entry          `json:"entry"`
+	ExpenseEntry   Entry          `json:"expense_entry"`
+}
+
+// PayInterestTx pays out the interest accrued on an account before
+// PeriodEnd. The whole minor units of it, together with what the previous
+// payout carried, are credited to the account and debited from the interest
+// expense account of its currency; the fraction left is carried to the next
+// payout. No entries are booked when that is less than a minor unit, but the
+// payout is still recorded.
+func (store *SQLStore) PayInterestTx(ctx context.Context, args PayInterestTxParams) (result PayInterestTxResult, err error) {
+	ctx, span := store.tracer.Start(ctx, "PayInterestTx", trace.WithAttributes(
+		attribute.Int64("account.id", args.AccountID),
+	))
+	defer endSpan(span, &err)
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, args.AccountID)
+		if err != nil {
+			return err
+		}
+
+		unpaid, err := q.GetUnpaidInterest(ctx, GetUnpaidInterestParams{
+			Before:    args.PeriodEnd,
+			AccountID: account.ID,
+		})
+		if err != nil {
+			return err
+		}
+		if unpaid.Accruals == 0 {
+			return ErrNoInterestDue
+		}
+
+		periodStart := unpaid.FirstDate
+		carried := int64(0)
+		last, err := q.GetLastInterestPayout(ctx, account.ID)
+		switch {
+		case err == nil:
+			periodStart = last.PeriodEnd
+			carried = last.CarryMicros
+		case !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+
+		total := unpaid.AmountMicros + carried
+		amount := total / InterestMicros
+		payout := CreateInterestPayoutParams{
+			AccountID:     account.ID,
+			PeriodStart:   periodStart,
+			PeriodEnd:     args.PeriodEnd,
+			AccruedMicros: unpaid.AmountMicros,
+			Amount:        amount,
+			CarryMicros:   total % InterestMicros,
+		}
+
+		result.Account = account
+		if amount > 0 {
+			expense, err := q.GetInterestExpenseAccount(ctx, account.Currency)
+			if err != nil {
+				return fmt.Errorf("cannot find interest expense account for %s: %w", account.Currency, err)
+			}
+
+			result.Entry, err = q.CreateEntry(ctx, CreateEntryParams{
+				AccountID: account.ID,
+				Amount:    amount,
+			})
+			if err != nil {
+				return err
+			}
+
+			result.ExpenseEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+				AccountID: expense.ID,
+				Amount:    -amount,
+			})
+			if err != nil {
+				return err
+			}
+
+			if account.ID < expense.ID {
+				result.Account, result.ExpenseAccount, err = store.addMoney(ctx, q, account.ID, amount, expense.ID, -amount)
+			} else {
+				result.ExpenseAccount, result.Account, err = store.addMoney(ctx, q, expense.ID, -amount, account.ID, amount)
+			}
+			if err != nil {
+				return err
+			}
+
+			payout.EntryID = sql.NullInt64{Int64: result.Entry.ID, Valid: true}
+			payout.ExpenseEntryID = sql.NullInt64{Int64: result.ExpenseEntry.ID, Valid: true}
+		}
+
+		result.Payout, err = q.CreateInterestPayout(ctx, payout)
+		if err != nil {
+			return err
+		}
+
+		return q.MarkInterestAccrualsPaid(ctx, MarkInterestAccrualsPaidParams{
+			PayoutID:  sql.NullInt64{Int64: result.Payout.ID, Valid: true},
+			AccountID: account.ID,
+			Before:    args.PeriodEnd,
+		})
+	})
+
+	return result, err
+}
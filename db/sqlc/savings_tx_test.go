@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomSavingsAccount(t *testing.T) (Account, SavingsProduct) {
+	admin := createRandomUser(t)
+	product, err := testQueries.CreateSavingsProduct(context.Background(), CreateSavingsProductParams{
+		Name:            "Saver " + utils.RandomString(8),
+		Currency:        utils.RandomCurrency(),
+		RateBps:         250,
+		DayCount:        DayCountACT365,
+		Compounding:     CompoundingNone,
+		PayoutFrequency: PayoutMonthly,
+		CreatedBy:       admin.Username,
+	})
+	require.NoError(t, err)
+
+	owner := createRandomUser(t)
+	result, err := NewStore(testDB).CreateSavingsAccountTx(context.Background(), CreateSavingsAccountTxParams{
+		Owner:     owner.Username,
+		Currency:  product.Currency,
+		ProductID: product.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountSavings, result.Account.Type)
+	require.Zero(t, result.Account.Balance)
+	require.Equal(t, product.ID, result.SavingsAccount.ProductID)
+
+	return result.Account, product
+}
+
+func TestCreateSavingsAccountTx(t *testing.T) {
+	account, product := createRandomSavingsAccount(t)
+
+	// a checking account in the same currency is still allowed, another
+	// savings account isn't
+	checking, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    account.Owner,
+		Currency: account.Currency,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountChecking, checking.Type)
+
+	_, err = NewStore(testDB).CreateSavingsAccountTx(context.Background(), CreateSavingsAccountTxParams{
+		Owner:     account.Owner,
+		Currency:  account.Currency,
+		ProductID: product.ID,
+	})
+	require.Error(t, err)
+
+	savings, err := testQueries.GetSavingsAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, product.ID, savings.ProductID)
+}
+
+func TestPayInterestTx(t *testing.T) {
+	store := NewStore(testDB)
+	account, product := createRandomSavingsAccount(t)
+	expense, err := testQueries.GetInterestExpenseAccount(context.Background(), account.Currency)
+	require.NoError(t, err)
+
+	day := func(d int) time.Time {
+		return time.Date(2024, time.February, d, 0, 0, 0, 0, time.UTC)
+	}
+	accrue := func(d int, micros int64) {
+		_, err := testQueries.CreateInterestAccrual(context.Background(), CreateInterestAccrualParams{
+			AccountID:    account.ID,
+			AccrualDate:  day(d),
+			RateBps:      product.RateBps,
+			DayCount:     product.DayCount,
+			Days:         1,
+			AmountMicros: micros,
+		})
+		require.NoError(t, err)
+	}
+
+	_, err = store.PayInterestTx(context.Background(), PayInterestTxParams{AccountID: account.ID, PeriodEnd: day(10)})
+	require.ErrorIs(t, err, ErrNoInterestDue)
+
+	// less than a minor unit is carried without booking anything
+	accrue(1, 400_000)
+	accrue(2, 300_000)
+	accrue(12, 500_000)
+	result, err := store.PayInterestTx(context.Background(), PayInterestTxParams{AccountID: account.ID, PeriodEnd: day(10)})
+	require.NoError(t, err)
+	require.True(t, day(1).Equal(result.Payout.PeriodStart))
+	require.Equal(t, int64(700_000), result.Payout.AccruedMicros)
+	require.Zero(t, result.Payout.Amount)
+	require.Equal(t, int64(700_000), result.Payout.CarryMicros)
+	require.False(t, result.Payout.EntryID.Valid)
+
+	_, err = store.PayInterestTx(context.Background(), PayInterestTxParams{AccountID: account.ID, PeriodEnd: day(10)})
+	require.ErrorIs(t, err, ErrNoInterestDue)
+
+	// the carry makes up a minor unit with the next accruals
+	accrue(13, 2_000_000)
+	result, err = store.PayInterestTx(context.Background(), PayInterestTxParams{AccountID: account.ID, PeriodEnd: day(20)})
+	require.NoError(t, err)
+	require.True(t, day(10).Equal(result.Payout.PeriodStart))
+	require.Equal(t, int64(2_500_000), result.Payout.AccruedMicros)
+	require.Equal(t, int64(3), result.Payout.Amount)
+	require.Equal(t, int64(200_000), result.Payout.CarryMicros)
+
+	require.Equal(t, int64(3), result.Entry.Amount)
+	require.Equal(t, account.ID, result.Entry.AccountID)
+	require.Equal(t, int64(-3), result.ExpenseEntry.Amount)
+	require.Equal(t, expense.ID, result.ExpenseEntry.AccountID)
+	require.Equal(t, result.Entry.ID, result.Payout.EntryID.Int64)
+	require.Equal(t, result.ExpenseEntry.ID, result.Payout.ExpenseEntryID.Int64)
+	require.Equal(t, int64(3), result.Account.Balance)
+	require.Equal(t, expense.Balance-3, result.ExpenseAccount.Balance)
+
+	accruals, err := testQueries.ListInterestAccruals(context.Background(), ListInterestAccrualsParams{AccountID: account.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, accruals, 4)
+	for _, accrual := range accruals {
+		require.True(t, accrual.PayoutID.Valid)
+	}
+	require.Equal(t, result.Payout.ID, accruals[0].PayoutID.Int64)
+
+	payouts, err := testQueries.ListInterestPayouts(context.Background(), ListInterestPayoutsParams{AccountID: account.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, payouts, 2)
+	require.Equal(t, result.Payout.ID, payouts[0].ID)
+}
+
+func TestGetEndOfDayBalance(t *testing.T) {
+	account := createRandomAccount(t)
+
+	entry, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{AccountID: account.ID, Amount: 10})
+	require.NoError(t, err)
+
+	balance, err := testQueries.GetEndOfDayBalance(context.Background(), GetEndOfDayBalanceParams{
+		At:        entry.CreatedAt.Add(time.Second),
+		AccountID: account.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, balance)
+
+	balance, err = testQueries.GetEndOfDayBalance(context.Background(), GetEndOfDayBalanceParams{
+		At:        entry.CreatedAt.Add(-time.Second),
+		AccountID: account.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.Balance-10, balance)
+
+	_, err = testQueries.GetEndOfDayBalance(context.Background(), GetEndOfDayBalanceParams{AccountID: -1})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,116 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Statuses of screening hits. Open and confirmed hits hold the payments of
+// the user.
+const (
+	ScreeningOpen      = "open"
+	ScreeningCleared   = "cleared"
+	ScreeningConfirmed = "confirmed"
+)
+
+// Where a name was screened.
+const (
+	ScreeningSourceOnboarding = "onboarding"
+	ScreeningSourcePayment    = "payment"
+)
+
+var (
+	ErrScreeningHitNotOpen = errors.New("screening hit was already reviewed")
+	ErrScreeningSelfReview = errors.New("screening hit must be reviewed by someone other than the screened user")
+	ErrScreeningHold       = errors.New("payment is held for a compliance review")
+)
+
+// RecordScreeningHitsTx records the matches of a screening. Hits already
+// reviewed for the same name keep their status.
+func (store *SQLStore) RecordScreeningHitsTx(ctx context.Context, hits []UpsertScreeningHitParams) (recorded []ScreeningHit, err error) {
+	ctx, span := store.tracer.Start(ctx, "RecordScreeningHitsTx", trace.WithAttributes(
+		attribute.Int("screening.hits", len(hits)),
+	))
+	defer endSpan(span, &err)
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		recorded = make([]ScreeningHit, 0, len(hits))
+		for _, hit := range hits {
+			row, err := q.UpsertScreeningHit(ctx, hit)
+			if err != nil {
+				return err
+			}
+			recorded = append(recorded, row)
+		}
+		return nil
+	})
+	return recorded, err
+}
+
+type ReviewScreeningHitTxParams struct {
+	ID         int64  `json:"id"`
+	ReviewedBy string `json:"reviewed_by"`
+	// Confirm is true when the user is the listed person or organisation.
+	Confirm bool   `json:"confirm"`
+	Note    string `json:"note"`
+}
+
+type ReviewScreeningHitTxResult struct {
+	Hit ScreeningHit `json:"hit"`
+	// FrozenAccounts are the accounts a confirmation froze.
+	FrozenAccounts []Account `json:"frozen_accounts"`
+}
+
+// ReviewScreeningHitTx clears an open hit as a false positive or confirms
+// it. Confirming disables the user and freezes all their accounts, as the
+// sanctions require their funds to be blocked.
+func (store *SQLStore) ReviewScreeningHitTx(ctx context.Context, args ReviewScreeningHitTxParams) (result ReviewScreeningHitTxResult, err error) {
+	ctx, span := store.tracer.Start(ctx, "ReviewScreeningHitTx", trace.WithAttributes(
+		attribute.Int64("screening.hit_id", args.ID),
+		attribute.Bool("screening.confirm", args.Confirm),
+	))
+	defer endSpan(span, &err)
+
+	status := ScreeningCleared
+	if args.Confirm {
+		status = ScreeningConfirmed
+	}
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		hit, err := q.GetScreeningHitForUpdate(ctx, args.ID)
+		if err != nil {
+			return err
+		}
+		if hit.Status != ScreeningOpen {
+			return ErrScreeningHitNotOpen
+		}
+		if hit.Username == args.ReviewedBy {
+			return ErrScreeningSelfReview
+		}
+
+		result.Hit, err = q.ReviewScreeningHit(ctx, ReviewScreeningHitParams{
+			Status:     status,
+			ReviewedBy: sql.NullString{String: args.ReviewedBy, Valid: true},
+			ReviewNote: args.Note,
+			ID:         args.ID,
+		})
+		if err != nil || !args.Confirm {
+			return err
+		}
+
+		if _, err := q.SetUserDisabled(ctx, SetUserDisabledParams{
+			Disabled: true,
+			Username: hit.Username,
+		}); err != nil {
+			return err
+		}
+
+		result.FrozenAccounts, err = q.FreezeOwnerAccounts(ctx, hit.Username)
+		return err
+	})
+	return result, err
+}
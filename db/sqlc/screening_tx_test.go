@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func recordScreeningHit(t *testing.T, store Store, username, name, entryID string) ScreeningHit {
+	hits, err := store.RecordScreeningHitsTx(context.Background(), []UpsertScreeningHitParams{{
+		Username:     username,
+		Source:       ScreeningSourceOnboarding,
+		ScreenedName: name,
+		List:         "ofac_sdn",
+		EntryID:      entryID,
+		EntryName:    name,
+		Score:        0.95,
+	}})
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	require.Equal(t, username, hits[0].Username)
+
+	return hits[0]
+}
+
+func TestScreeningHitWorkflow(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	user, err := testQueries.GetUser(context.Background(), account.Owner)
+	require.NoError(t, err)
+	analyst := createRandomUser(t)
+	entryID := utils.RandomString(8)
+
+	hit := recordScreeningHit(t, store, user.Username, user.FullName, entryID)
+	require.Equal(t, ScreeningOpen, hit.Status)
+	blocking, err := testQueries.CountBlockingScreeningHits(context.Background(), []string{user.Username, analyst.Username})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), blocking)
+
+	_, err = store.ReviewScreeningHitTx(context.Background(), ReviewScreeningHitTxParams{ID: hit.ID, ReviewedBy: user.Username, Note: "not me"})
+	require.ErrorIs(t, err, ErrScreeningSelfReview)
+
+	result, err := store.ReviewScreeningHitTx(context.Background(), ReviewScreeningHitTxParams{
+		ID:         hit.ID,
+		ReviewedBy: analyst.Username,
+		Note:       "different date of birth",
+	})
+	require.NoError(t, err)
+	require.Equal(t, ScreeningCleared, result.Hit.Status)
+	require.Equal(t, analyst.Username, result.Hit.ReviewedBy.String)
+	require.Empty(t, result.FrozenAccounts)
+
+	_, err = store.ReviewScreeningHitTx(context.Background(), ReviewScreeningHitTxParams{ID: hit.ID, ReviewedBy: analyst.Username, Confirm: true})
+	require.ErrorIs(t, err, ErrScreeningHitNotOpen)
+
+	// screening the same name again keeps the hit cleared
+	again := recordScreeningHit(t, store, user.Username, user.FullName, entryID)
+	require.Equal(t, hit.ID, again.ID)
+	require.Equal(t, ScreeningCleared, again.Status)
+	blocking, err = testQueries.CountBlockingScreeningHits(context.Background(), []string{user.Username})
+	require.NoError(t, err)
+	require.Zero(t, blocking)
+
+	// but a new name is reviewed again
+	again = recordScreeningHit(t, store, user.Username, utils.RandomOwner(), entryID)
+	require.Equal(t, hit.ID, again.ID)
+	require.Equal(t, ScreeningOpen, again.Status)
+	require.False(t, again.ReviewedBy.Valid)
+
+	result, err = store.ReviewScreeningHitTx(context.Background(), ReviewScreeningHitTxParams{
+		ID:         hit.ID,
+		ReviewedBy: analyst.Username,
+		Confirm:    true,
+		Note:       "same passport number",
+	})
+	require.NoError(t, err)
+	require.Equal(t, ScreeningConfirmed, result.Hit.Status)
+	require.Len(t, result.FrozenAccounts, 1)
+	require.Equal(t, account.ID, result.FrozenAccounts[0].ID)
+	require.True(t, result.FrozenAccounts[0].Frozen)
+
+	user, err = testQueries.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.True(t, user.Disabled)
+
+	blocking, err = testQueries.CountBlockingScreeningHits(context.Background(), []string{user.Username})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), blocking)
+}
@@ -3,48 +3,193 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
+
+	"github.com/danielmoisa/neobank/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 type Store interface {
 	Querier
 	PaymentTx(ctx context.Context, args PaymentTxParams) (PaymentTxResult, error)
+	ProposeAdjustment(ctx context.Context, args ProposeAdjustmentParams) (Adjustment, error)
+	ApproveAdjustmentTx(ctx context.Context, args ReviewAdjustmentTxParams) (ApproveAdjustmentTxResult, error)
+	RejectAdjustmentTx(ctx context.Context, args ReviewAdjustmentTxParams) (Adjustment, error)
+	LoginFailureTx(ctx context.Context, args LoginFailureTxParams) (LoginFailureTxResult, error)
+	IssueUserTokenTx(ctx context.Context, args IssueUserTokenParams) (IssueUserTokenResult, error)
+	VerifyEmailTx(ctx context.Context, token string) (User, error)
+	ResetPasswordTx(ctx context.Context, args ResetPasswordTxParams) (User, error)
+	RequestEmailChangeTx(ctx context.Context, args RequestEmailChangeTxParams) (RequestEmailChangeTxResult, error)
+	ConfirmEmailChangeTx(ctx context.Context, token string) (ConfirmEmailChangeTxResult, error)
+	ChangePasswordTx(ctx context.Context, args ChangePasswordTxParams) (User, error)
+	AuthorizeOAuthClientTx(ctx context.Context, args AuthorizeOAuthClientTxParams) (AuthorizeOAuthClientTxResult, error)
+	ExchangeOAuthCodeTx(ctx context.Context, args ExchangeOAuthCodeTxParams) (OAuthGrantTxResult, error)
+	RefreshOAuthTokenTx(ctx context.Context, args RefreshOAuthTokenTxParams) (OAuthGrantTxResult, error)
+	SaveKYCProfileTx(ctx context.Context, args UpsertKYCProfileParams) (KYCProfile, error)
+	AddKYCDocumentTx(ctx context.Context, args CreateKYCDocumentParams) (KYCDocument, error)
+	SubmitKYCTx(ctx context.Context, username string) (KYCTxResult, error)
+	ReviewKYCTx(ctx context.Context, args ReviewKYCTxParams) (KYCTxResult, error)
+	RecordScreeningHitsTx(ctx context.Context, hits []UpsertScreeningHitParams) ([]ScreeningHit, error)
+	ReviewScreeningHitTx(ctx context.Context, args ReviewScreeningHitTxParams) (ReviewScreeningHitTxResult, error)
+	ReviewFraudDecisionTx(ctx context.Context, args ReviewFraudDecisionTxParams) (ReviewFraudDecisionTxResult, error)
+	RecordAMLAlertsTx(ctx context.Context, alerts []UpsertAMLAlertParams) ([]AMLAlert, error)
+	CreateSavingsAccountTx(ctx context.Context, args CreateSavingsAccountTxParams) (CreateSavingsAccountTxResult, error)
+	PayInterestTx(ctx context.Context, args PayInterestTxParams) (PayInterestTxResult, error)
+	OriginateLoanTx(ctx context.Context, args OriginateLoanTxParams) (OriginateLoanTxResult, error)
+	CollectInstallmentTx(ctx context.Context, args CollectInstallmentTxParams) (CollectInstallmentTxResult, error)
+	RepayLoanEarlyTx(ctx context.Context, args RepayLoanEarlyTxParams) (RepayLoanEarlyTxResult, error)
+	AuthorizeCardTx(ctx context.Context, args AuthorizeCardTxParams) (CardAuthorization, error)
+	CaptureCardTx(ctx context.Context, args CaptureCardTxParams) (CaptureCardTxResult, error)
+	CreatePocketTx(ctx context.Context, args CreatePocketTxParams) (CreatePocketTxResult, error)
+	MovePocketTx(ctx context.Context, args MovePocketTxParams) (MovePocketTxResult, error)
+	InviteAccountMemberTx(ctx context.Context, args CreateAccountInvitationParams) (AccountInvitation, error)
+	AcceptAccountInvitationTx(ctx context.Context, args AcceptAccountInvitationTxParams) (AcceptAccountInvitationTxResult, error)
+	// Ping checks that the database is reachable.
+	Ping(ctx context.Context) error
+	// SchemaVersion reports the migration version recorded by migrate.
+	SchemaVersion(ctx context.Context) (version uint, dirty bool, err error)
+	// Drain makes PaymentTx fail with ErrStoreDraining and waits until the
+	// payments already running have finished or ctx is done.
+	Drain(ctx context.Context) error
+}
+
+// ErrStoreDraining is returned by PaymentTx once the store is shutting down.
+var ErrStoreDraining = errors.New("store is shutting down")
+
+// PaymentObserver is told how long each PaymentTx took and how it ended, so
+// the store can be instrumented without depending on a metrics library.
+type PaymentObserver interface {
+	ObservePaymentTx(duration time.Duration, err error)
 }
 
 type SQLStore struct {
 	*Queries
-	db *sql.DB
+	db       *sql.DB
+	observer PaymentObserver
+	tracer   trace.Tracer
+
+	mu       sync.Mutex
+	draining bool
+	inflight sync.WaitGroup
+}
+
+type StoreOption func(*SQLStore)
+
+// WithPaymentObserver reports every PaymentTx to observer.
+func WithPaymentObserver(observer PaymentObserver) StoreOption {
+	return func(store *SQLStore) {
+		store.observer = observer
+	}
 }
 
-func NewStore(db *sql.DB) Store {
-	return &SQLStore{
-		db:      db,
-		Queries: New(db),
+// WithTracerProvider records a span for every query, for execTx and for
+// PaymentTx and its steps.
+func WithTracerProvider(provider trace.TracerProvider) StoreOption {
+	return func(store *SQLStore) {
+		store.tracer = provider.Tracer(tracerName)
 	}
 }
 
-func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
+const tracerName = "github.com/danielmoisa/neobank/db/sqlc"
+
+func NewStore(db *sql.DB, opts ...StoreOption) Store {
+	store := &SQLStore{
+		db:     db,
+		tracer: noop.NewTracerProvider().Tracer(tracerName),
+	}
+	for _, opt := range opts {
+		opt(store)
+	}
+	store.Queries = New(tracing.WrapDB(db, store.tracer))
+	return store
+}
+
+func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) (err error) {
+	ctx, span := store.tracer.Start(ctx, "execTx")
+	defer endSpan(span, &err)
+
 	tx, err := store.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
-	q := New(tx)
+	q := New(tracing.WrapDB(tx, store.tracer))
 	err = fn(q)
 	if err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
 			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
 		}
+		span.AddEvent("rollback")
 		return err
 	}
 
 	return tx.Commit()
 }
 
+func (store *SQLStore) Ping(ctx context.Context) error {
+	return store.db.PingContext(ctx)
+}
+
+func (store *SQLStore) SchemaVersion(ctx context.Context) (version uint, dirty bool, err error) {
+	err = store.db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	return
+}
+
+func (store *SQLStore) Drain(ctx context.Context) error {
+	store.mu.Lock()
+	store.draining = true
+	store.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		store.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// track registers a running payment. The returned function must be called
+// when it is done.
+func (store *SQLStore) track() (func(), error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if store.draining {
+		return nil, ErrStoreDraining
+	}
+	store.inflight.Add(1)
+	return store.inflight.Done, nil
+}
+
+// endSpan marks span as failed when *err is set and ends it. It is meant to be
+// deferred with a pointer to a named error result.
+func endSpan(span trace.Span, err *error) {
+	if *err != nil {
+		span.RecordError(*err)
+		span.SetStatus(codes.Error, (*err).Error())
+	}
+	span.End()
+}
+
 type PaymentTxParams struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
 	Amount        int64 `json:"amount"`
+	// InitiatedBy is the user who sends the payment, the owner of the
+	// account or a member of it.
+	InitiatedBy string `json:"initiated_by"`
 }
 
 type PaymentTxResult struct {
@@ -53,56 +198,89 @@ type PaymentTxResult struct {
 	ToAccount   Account `json:"to_account"`
 	FromEntry   Entry   `json:"from_entry"`
 	ToEntry     Entry   `json:"to_entry"`
+	// RoundUp is the spare change swept into the round-up pocket of the
+	// account paid from, if any.
+	RoundUp *PocketMove `json:"round_up,omitempty" swaggerignore:"true"`
+	// RoundUpSweep has the entries and accounts of the round-up, for the
+	// events of the payment.
+	RoundUpSweep *MovePocketTxResult `json:"-"`
 }
 
-func (store *SQLStore) PaymentTx(ctx context.Context, args PaymentTxParams) (PaymentTxResult, error) {
-	var result PaymentTxResult
+func (store *SQLStore) PaymentTx(ctx context.Context, args PaymentTxParams) (result PaymentTxResult, err error) {
+	done, err := store.track()
+	if err != nil {
+		return result, err
+	}
+	defer done()
 
-	err := store.execTx(ctx, func(q *Queries) error {
-		var err error
+	ctx, span := store.tracer.Start(ctx, "PaymentTx", trace.WithAttributes(
+		attribute.Int64("payment.from_account_id", args.FromAccountID),
+		attribute.Int64("payment.to_account_id", args.ToAccountID),
+		attribute.Int64("payment.amount", args.Amount),
+	))
+	defer endSpan(span, &err)
 
-		result.Payment, err = q.CreatePayment(ctx, CreatePaymentParams{
-			FromAccountID: args.FromAccountID,
-			ToAccountID:   args.ToAccountID,
-			Amount:        args.Amount,
-		})
-		if err != nil {
-			return err
-		}
+	if store.observer != nil {
+		start := time.Now()
+		defer func() {
+			store.observer.ObservePaymentTx(time.Since(start), err)
+		}()
+	}
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: args.FromAccountID,
-			Amount:    -args.Amount,
-		})
-		if err != nil {
-			return err
-		}
+	err = store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = store.transfer(ctx, q, args)
+		return err
+	})
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: args.ToAccountID,
-			Amount:    args.Amount,
-		})
-		if err != nil {
-			return err
-		}
+	return result, err
+}
 
-		if args.FromAccountID < args.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, args.FromAccountID, -args.Amount, args.ToAccountID, args.Amount)
-		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, args.ToAccountID, args.Amount, args.FromAccountID, -args.Amount)
-		}
+// transfer books a payment within a transaction: the payment, an entry on
+// each account, the new balances and the round-up of the payment.
+func (store *SQLStore) transfer(ctx context.Context, q *Queries, args PaymentTxParams) (result PaymentTxResult, err error) {
+	result.Payment, err = q.CreatePayment(ctx, CreatePaymentParams{
+		FromAccountID: args.FromAccountID,
+		ToAccountID:   args.ToAccountID,
+		Amount:        args.Amount,
+		InitiatedBy:   args.InitiatedBy,
+	})
+	if err != nil {
+		return
+	}
 
-		if err != nil {
-			return err
-		}
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: args.FromAccountID,
+		Amount:    -args.Amount,
+	})
+	if err != nil {
+		return
+	}
 
-		return nil
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: args.ToAccountID,
+		Amount:    args.Amount,
 	})
+	if err != nil {
+		return
+	}
 
-	return result, err
+	if args.FromAccountID < args.ToAccountID {
+		result.FromAccount, result.ToAccount, err = store.addMoney(ctx, q, args.FromAccountID, -args.Amount, args.ToAccountID, args.Amount)
+	} else {
+		result.ToAccount, result.FromAccount, err = store.addMoney(ctx, q, args.ToAccountID, args.Amount, args.FromAccountID, -args.Amount)
+	}
+	if err != nil {
+		return
+	}
+
+	err = store.roundUp(ctx, q, &result)
+	return
 }
 
-func addMoney(
+// addMoney updates account1 before account2. transfer always passes the
+// lower account ID first, which keeps opposite payments from deadlocking.
+func (store *SQLStore) addMoney(
 	ctx context.Context,
 	q *Queries,
 	accountID1 int64,
@@ -110,6 +288,9 @@ func addMoney(
 	accountID2 int64,
 	amount2 int64,
 ) (account1 Account, account2 Account, err error) {
+	ctx, span := store.tracer.Start(ctx, "addMoney")
+	defer endSpan(span, &err)
+
 	account1, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
 		ID:     accountID1,
 		Amount: amount1,
@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestPaymentTx(t *testing.T) {
@@ -27,6 +30,7 @@ func TestPaymentTx(t *testing.T) {
 				FromAccountID: acc1.ID,
 				ToAccountID:   acc2.ID,
 				Amount:        amount,
+				InitiatedBy:   acc1.Owner,
 			})
 			errs <- err
 			results <- result
@@ -123,10 +127,12 @@ func TestTransferTxDeadlock(t *testing.T) {
 	for i := 0; i < n; i++ {
 		fromAccountID := account1.ID
 		toAccountID := account2.ID
+		initiatedBy := account1.Owner
 
 		if i%2 == 1 {
 			fromAccountID = account2.ID
 			toAccountID = account1.ID
+			initiatedBy = account2.Owner
 		}
 
 		go func() {
@@ -134,6 +140,7 @@ func TestTransferTxDeadlock(t *testing.T) {
 				FromAccountID: fromAccountID,
 				ToAccountID:   toAccountID,
 				Amount:        amount,
+				InitiatedBy:   initiatedBy,
 			})
 
 			errs <- err
@@ -156,3 +163,61 @@ func TestTransferTxDeadlock(t *testing.T) {
 	require.Equal(t, account1.Balance, updatedAccount1.Balance)
 	require.Equal(t, account2.Balance, updatedAccount2.Balance)
 }
+
+func TestPaymentTxTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	store := NewStore(testDB, WithTracerProvider(provider))
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	_, err := store.PaymentTx(context.Background(), PaymentTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        10,
+		InitiatedBy:   account2.Owner,
+	})
+	require.NoError(t, err)
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	var names []string
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+		names = append(names, span.Name())
+	}
+	require.Equal(t, []string{
+		"CreatePayment",
+		"CreateEntry",
+		"CreateEntry",
+		"AddAccountBalance",
+		"AddAccountBalance",
+		"addMoney",
+		"execTx",
+		"PaymentTx",
+	}, names)
+
+	require.Equal(t, spans["PaymentTx"].SpanContext().SpanID(), spans["execTx"].Parent().SpanID())
+	require.Equal(t, spans["execTx"].SpanContext().SpanID(), spans["addMoney"].Parent().SpanID())
+	require.Equal(t, spans["addMoney"].SpanContext().SpanID(), spans["AddAccountBalance"].Parent().SpanID())
+	require.Equal(t, spans["execTx"].SpanContext().SpanID(), spans["CreatePayment"].Parent().SpanID())
+}
+
+func TestPaymentTxAfterDrain(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, store.Drain(ctx))
+
+	_, err := store.PaymentTx(context.Background(), PaymentTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		InitiatedBy:   account1.Owner,
+	})
+	require.ErrorIs(t, err, ErrStoreDraining)
+}
@@ -0,0 +1,183 @@
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Purposes of user tokens. A token only works for the purpose it was issued
+// for.
+const (
+	TokenEmailVerification = "email_verification"
+	TokenPasswordReset     = "password_reset"
+	TokenEmailChange       = "email_change"
+)
+
+// userTokenBytes is the entropy of a user token. It is high enough that a
+// plain SHA-256 of the token is safe to store, unlike a password.
+const userTokenBytes = 32
+
+// ErrInvalidUserToken is returned for a user token that is unknown, was
+// already used, has expired or no longer matches the user.
+var ErrInvalidUserToken = errors.New("token is invalid or has expired")
+
+// HashUserToken returns the form of a user token that is stored.
+func HashUserToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func newUserToken() (string, error) {
+	b := make([]byte, userTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+type IssueUserTokenParams struct {
+	Username string        `json:"username"`
+	Purpose  string        `json:"purpose"`
+	Email    string        `json:"email"`
+	TTL      time.Duration `json:"ttl"`
+}
+
+// IssueUserTokenResult holds the token to send to the user. Token is not
+// stored anywhere and cannot be recovered later.
+type IssueUserTokenResult struct {
+	Token     string    `json:"-"`
+	UserToken UserToken `json:"user_token"`
+}
+
+// IssueUserTokenTx creates a token for Purpose that is valid for TTL and sent
+// to Email. The unused tokens the user had for the same purpose stop working,
+// so only the latest email can be acted on.
+func (store *SQLStore) IssueUserTokenTx(ctx context.Context, args IssueUserTokenParams) (result IssueUserTokenResult, err error) {
+	ctx, span := store.tracer.Start(ctx, "IssueUserTokenTx", trace.WithAttributes(
+		attribute.String("user.username", args.Username),
+		attribute.String("token.purpose", args.Purpose),
+	))
+	defer endSpan(span, &err)
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		err := q.InvalidateUserTokens(ctx, InvalidateUserTokensParams{
+			Username: args.Username,
+			Purpose:  args.Purpose,
+		})
+		if err != nil {
+			return err
+		}
+
+		result, err = insertUserToken(ctx, q, args)
+		return err
+	})
+
+	return result, err
+}
+
+func insertUserToken(ctx context.Context, q *Queries, args IssueUserTokenParams) (result IssueUserTokenResult, err error) {
+	result.Token, err = newUserToken()
+	if err != nil {
+		return result, err
+	}
+
+	result.UserToken, err = q.CreateUserToken(ctx, CreateUserTokenParams{
+		Username:  args.Username,
+		Purpose:   args.Purpose,
+		TokenHash: HashUserToken(result.Token),
+		Email:     args.Email,
+		ExpiresAt: time.Now().Add(args.TTL),
+	})
+	return result, err
+}
+
+// VerifyEmailTx uses an email verification token and marks the address it
+// was sent to as verified. The token is invalid once the user changed their
+// email since.
+func (store *SQLStore) VerifyEmailTx(ctx context.Context, token string) (user User, err error) {
+	ctx, span := store.tracer.Start(ctx, "VerifyEmailTx")
+	defer endSpan(span, &err)
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		userToken, err := useUserToken(ctx, q, token, TokenEmailVerification)
+		if err != nil {
+			return err
+		}
+
+		user, err = q.MarkEmailVerified(ctx, MarkEmailVerifiedParams{
+			Username: userToken.Username,
+			Email:    userToken.Email,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidUserToken
+		}
+		return err
+	})
+
+	return user, err
+}
+
+type ResetPasswordTxParams struct {
+	Token          string `json:"-"`
+	HashedPassword string `json:"-"`
+}
+
+// ResetPasswordTx uses a password reset token to set a new password. It also
+// moves password_changed_at, which revokes the access tokens issued before,
+// revokes the OAuth consents with their refresh tokens and the API keys of the
+// user, as whoever knew the old password may have created them, and ends a
+// lockout since the user just proved they own the email.
+func (store *SQLStore) ResetPasswordTx(ctx context.Context, args ResetPasswordTxParams) (user User, err error) {
+	ctx, span := store.tracer.Start(ctx, "ResetPasswordTx")
+	defer endSpan(span, &err)
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		userToken, err := useUserToken(ctx, q, args.Token, TokenPasswordReset)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.UpdatePassword(ctx, UpdatePasswordParams{
+			HashedPassword: args.HashedPassword,
+			Username:       userToken.Username,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.RevokeUserOAuthConsents(ctx, userToken.Username)
+		if err != nil {
+			return err
+		}
+
+		err = q.RevokeUserAPIKeys(ctx, userToken.Username)
+		if err != nil {
+			return err
+		}
+
+		user, err = q.UnlockUser(ctx, userToken.Username)
+		return err
+	})
+
+	return user, err
+}
+
+func useUserToken(ctx context.Context, q *Queries, token string, purpose string) (UserToken, error) {
+	userToken, err := q.ConsumeUserToken(ctx, ConsumeUserTokenParams{
+		TokenHash: HashUserToken(token),
+		Purpose:   purpose,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return userToken, ErrInvalidUserToken
+	}
+	return userToken, err
+}
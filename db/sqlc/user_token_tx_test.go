@@ -0,0 +1,127 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func issueUserToken(t *testing.T, store Store, user User, purpose string, ttl time.Duration) string {
+	result, err := store.IssueUserTokenTx(context.Background(), IssueUserTokenParams{
+		Username: user.Username,
+		Purpose:  purpose,
+		Email:    user.Email,
+		TTL:      ttl,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, result.Token)
+	require.Equal(t, HashUserToken(result.Token), result.UserToken.TokenHash)
+	require.False(t, result.UserToken.UsedAt.Valid)
+
+	return result.Token
+}
+
+func TestVerifyEmailTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	require.True(t, user.EmailVerifiedAt.IsZero())
+
+	token := issueUserToken(t, store, user, TokenEmailVerification, time.Hour)
+
+	// a token only works for its purpose
+	_, err := store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{Token: token, HashedPassword: "hash"})
+	require.ErrorIs(t, err, ErrInvalidUserToken)
+
+	verified, err := store.VerifyEmailTx(context.Background(), token)
+	require.NoError(t, err)
+	require.Equal(t, user.Username, verified.Username)
+	require.WithinDuration(t, time.Now(), verified.EmailVerifiedAt, 5*time.Second)
+
+	// and only once
+	_, err = store.VerifyEmailTx(context.Background(), token)
+	require.ErrorIs(t, err, ErrInvalidUserToken)
+}
+
+func TestVerifyEmailTxInvalidToken(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	_, err := store.VerifyEmailTx(context.Background(), utils.RandomString(32))
+	require.ErrorIs(t, err, ErrInvalidUserToken)
+
+	expired := issueUserToken(t, store, user, TokenEmailVerification, -time.Minute)
+	_, err = store.VerifyEmailTx(context.Background(), expired)
+	require.ErrorIs(t, err, ErrInvalidUserToken)
+
+	// issuing a new token invalidates the older ones
+	older := issueUserToken(t, store, user, TokenEmailVerification, time.Hour)
+	newer := issueUserToken(t, store, user, TokenEmailVerification, time.Hour)
+	_, err = store.VerifyEmailTx(context.Background(), older)
+	require.ErrorIs(t, err, ErrInvalidUserToken)
+
+	_, err = store.VerifyEmailTx(context.Background(), newer)
+	require.NoError(t, err)
+}
+
+func TestResetPasswordTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	_, err := testQueries.LockUser(context.Background(), LockUserParams{
+		LockedUntil: time.Now().Add(time.Hour),
+		Username:    user.Username,
+	})
+	require.NoError(t, err)
+
+	client := createRandomOAuthClient(t, user)
+	verifier := utils.RandomString(43)
+	authorized := authorizeOAuthClient(t, store, user, client, verifier)
+	granted, err := store.ExchangeOAuthCodeTx(context.Background(), ExchangeOAuthCodeTxParams{
+		Code:         authorized.Code,
+		ClientID:     client.ID,
+		RedirectURI:  testRedirectURI,
+		CodeVerifier: verifier,
+		RefreshTTL:   time.Hour,
+	})
+	require.NoError(t, err)
+	_, apiKey := createRandomAPIKey(t, user)
+
+	token := issueUserToken(t, store, user, TokenPasswordReset, time.Hour)
+
+	hash, err := utils.HashPassword(utils.RandomString(8))
+	require.NoError(t, err)
+
+	updated, err := store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		Token:          token,
+		HashedPassword: hash,
+	})
+	require.NoError(t, err)
+	require.Equal(t, hash, updated.HashedPassword)
+	require.WithinDuration(t, time.Now(), updated.PasswordChangedAt, 5*time.Second)
+	require.False(t, updated.LockedUntil.After(time.Now()))
+	require.Zero(t, updated.FailedLoginAttempts)
+
+	// whatever was granted with the old password stops working
+	consent, err := testQueries.GetOAuthConsent(context.Background(), authorized.Consent.ID)
+	require.NoError(t, err)
+	require.False(t, consent.Active())
+	_, err = store.RefreshOAuthTokenTx(context.Background(), RefreshOAuthTokenTxParams{
+		RefreshToken: granted.RefreshToken,
+		ClientID:     client.ID,
+		RefreshTTL:   time.Hour,
+	})
+	require.ErrorIs(t, err, ErrInvalidGrant)
+
+	apiKey, err = testQueries.GetAPIKeyByHash(context.Background(), apiKey.KeyHash)
+	require.NoError(t, err)
+	require.True(t, apiKey.RevokedAt.Valid)
+
+	_, err = store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		Token:          token,
+		HashedPassword: hash,
+	})
+	require.ErrorIs(t, err, ErrInvalidUserToken)
+}
@@ -7,6 +7,7 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 const createUser = `-- name: CreateUser :one
@@ -17,7 +18,7 @@ INSERT INTO users (
   email
 ) VALUES (
   $1, $2, $3, $4
-) RETURNING username, hashed_password, full_name, email, password_changed_at, created_at
+) RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, disabled, role, failed_login_attempts, lockouts, locked_until, email_verified_at, kyc_status
 `
 
 type CreateUserParams struct {
@@ -42,12 +43,19 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Disabled,
+		&i.Role,
+		&i.FailedLoginAttempts,
+		&i.Lockouts,
+		&i.LockedUntil,
+		&i.EmailVerifiedAt,
+		&i.KYCStatus,
 	)
 	return i, err
 }
 
 const getUser = `-- name: GetUser :one
-SELECT username, hashed_password, full_name, email, password_changed_at, created_at FROM users
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, disabled, role, failed_login_attempts, lockouts, locked_until, email_verified_at, kyc_status FROM users
 WHERE username = $1 LIMIT 1
 `
 
@@ -61,6 +69,410 @@ func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Disabled,
+		&i.Role,
+		&i.FailedLoginAttempts,
+		&i.Lockouts,
+		&i.LockedUntil,
+		&i.EmailVerifiedAt,
+		&i.KYCStatus,
+	)
+	return i, err
+}
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, disabled, role, failed_login_attempts, lockouts, locked_until, email_verified_at, kyc_status FROM users
+WHERE email = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Disabled,
+		&i.Role,
+		&i.FailedLoginAttempts,
+		&i.Lockouts,
+		&i.LockedUntil,
+		&i.EmailVerifiedAt,
+		&i.KYCStatus,
+	)
+	return i, err
+}
+
+const getUserForUpdate = `-- name: GetUserForUpdate :one
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, disabled, role, failed_login_attempts, lockouts, locked_until, email_verified_at, kyc_status FROM users
+WHERE username = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetUserForUpdate(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserForUpdate, username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Disabled,
+		&i.Role,
+		&i.FailedLoginAttempts,
+		&i.Lockouts,
+		&i.LockedUntil,
+		&i.EmailVerifiedAt,
+		&i.KYCStatus,
+	)
+	return i, err
+}
+
+const lockUser = `-- name: LockUser :one
+UPDATE users
+SET
+  failed_login_attempts = 0,
+  lockouts = lockouts + 1,
+  locked_until = $1
+WHERE username = $2
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, disabled, role, failed_login_attempts, lockouts, locked_until, email_verified_at, kyc_status
+`
+
+type LockUserParams struct {
+	LockedUntil time.Time `json:"locked_until"`
+	Username    string    `json:"username"`
+}
+
+func (q *Queries) LockUser(ctx context.Context, arg LockUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, lockUser, arg.LockedUntil, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Disabled,
+		&i.Role,
+		&i.FailedLoginAttempts,
+		&i.Lockouts,
+		&i.LockedUntil,
+		&i.EmailVerifiedAt,
+		&i.KYCStatus,
+	)
+	return i, err
+}
+
+const markEmailVerified = `-- name: MarkEmailVerified :one
+UPDATE users
+SET email_verified_at = now()
+WHERE username = $1 AND email = $2
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, disabled, role, failed_login_attempts, lockouts, locked_until, email_verified_at, kyc_status
+`
+
+type MarkEmailVerifiedParams struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+func (q *Queries) MarkEmailVerified(ctx context.Context, arg MarkEmailVerifiedParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, markEmailVerified, arg.Username, arg.Email)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Disabled,
+		&i.Role,
+		&i.FailedLoginAttempts,
+		&i.Lockouts,
+		&i.LockedUntil,
+		&i.EmailVerifiedAt,
+		&i.KYCStatus,
+	)
+	return i, err
+}
+
+const recordFailedLogin = `-- name: RecordFailedLogin :one
+UPDATE users
+SET failed_login_attempts = failed_login_attempts + 1
+WHERE username = $1
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, disabled, role, failed_login_attempts, lockouts, locked_until, email_verified_at, kyc_status
+`
+
+func (q *Queries) RecordFailedLogin(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRowContext(ctx, recordFailedLogin, username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Disabled,
+		&i.Role,
+		&i.FailedLoginAttempts,
+		&i.Lockouts,
+		&i.LockedUntil,
+		&i.EmailVerifiedAt,
+		&i.KYCStatus,
+	)
+	return i, err
+}
+
+const resetFailedLogins = `-- name: ResetFailedLogins :exec
+UPDATE users
+SET failed_login_attempts = 0, lockouts = 0
+WHERE username = $1
+`
+
+func (q *Queries) ResetFailedLogins(ctx context.Context, username string) error {
+	_, err := q.db.ExecContext(ctx, resetFailedLogins, username)
+	return err
+}
+
+const setKYCStatus = `-- name: SetKYCStatus :one
+UPDATE users
+SET kyc_status = $1
+WHERE username = $2
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, disabled, role, failed_login_attempts, lockouts, locked_until, email_verified_at, kyc_status
+`
+
+type SetKYCStatusParams struct {
+	KYCStatus string `json:"kyc_status"`
+	Username  string `json:"username"`
+}
+
+func (q *Queries) SetKYCStatus(ctx context.Context, arg SetKYCStatusParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setKYCStatus, arg.KYCStatus, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Disabled,
+		&i.Role,
+		&i.FailedLoginAttempts,
+		&i.Lockouts,
+		&i.LockedUntil,
+		&i.EmailVerifiedAt,
+		&i.KYCStatus,
+	)
+	return i, err
+}
+
+const setUserDisabled = `-- name: SetUserDisabled :one
+UPDATE users
+SET disabled = $1
+WHERE username = $2
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, disabled, role, failed_login_attempts, lockouts, locked_until, email_verified_at, kyc_status
+`
+
+type SetUserDisabledParams struct {
+	Disabled bool   `json:"disabled"`
+	Username string `json:"username"`
+}
+
+func (q *Queries) SetUserDisabled(ctx context.Context, arg SetUserDisabledParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserDisabled, arg.Disabled, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Disabled,
+		&i.Role,
+		&i.FailedLoginAttempts,
+		&i.Lockouts,
+		&i.LockedUntil,
+		&i.EmailVerifiedAt,
+		&i.KYCStatus,
+	)
+	return i, err
+}
+
+const setUserRole = `-- name: SetUserRole :one
+UPDATE users
+SET role = $1
+WHERE username = $2
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, disabled, role, failed_login_attempts, lockouts, locked_until, email_verified_at, kyc_status
+`
+
+type SetUserRoleParams struct {
+	Role     string `json:"role"`
+	Username string `json:"username"`
+}
+
+func (q *Queries) SetUserRole(ctx context.Context, arg SetUserRoleParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserRole, arg.Role, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Disabled,
+		&i.Role,
+		&i.FailedLoginAttempts,
+		&i.Lockouts,
+		&i.LockedUntil,
+		&i.EmailVerifiedAt,
+		&i.KYCStatus,
+	)
+	return i, err
+}
+
+const unlockUser = `-- name: UnlockUser :one
+UPDATE users
+SET
+  failed_login_attempts = 0,
+  lockouts = 0,
+  locked_until = '0001-01-01 00:00:00Z'
+WHERE username = $1
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, disabled, role, failed_login_attempts, lockouts, locked_until, email_verified_at, kyc_status
+`
+
+func (q *Queries) UnlockUser(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRowContext(ctx, unlockUser, username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Disabled,
+		&i.Role,
+		&i.FailedLoginAttempts,
+		&i.Lockouts,
+		&i.LockedUntil,
+		&i.EmailVerifiedAt,
+		&i.KYCStatus,
+	)
+	return i, err
+}
+
+const updatePassword = `-- name: UpdatePassword :one
+UPDATE users
+SET
+  hashed_password = $1,
+  password_changed_at = now()
+WHERE username = $2
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, disabled, role, failed_login_attempts, lockouts, locked_until, email_verified_at, kyc_status
+`
+
+type UpdatePasswordParams struct {
+	HashedPassword string `json:"hashed_password"`
+	Username       string `json:"username"`
+}
+
+func (q *Queries) UpdatePassword(ctx context.Context, arg UpdatePasswordParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updatePassword, arg.HashedPassword, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Disabled,
+		&i.Role,
+		&i.FailedLoginAttempts,
+		&i.Lockouts,
+		&i.LockedUntil,
+		&i.EmailVerifiedAt,
+		&i.KYCStatus,
+	)
+	return i, err
+}
+
+const updateUserEmail = `-- name: UpdateUserEmail :one
+UPDATE users
+SET
+  email = $1,
+  email_verified_at = now()
+WHERE username = $2 AND email = $3
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, disabled, role, failed_login_attempts, lockouts, locked_until, email_verified_at, kyc_status
+`
+
+type UpdateUserEmailParams struct {
+	NewEmail string `json:"new_email"`
+	Username string `json:"username"`
+	OldEmail string `json:"old_email"`
+}
+
+// only replaces the email if it is still old_email; the new address counts
+// as verified
+func (q *Queries) UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserEmail, arg.NewEmail, arg.Username, arg.OldEmail)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Disabled,
+		&i.Role,
+		&i.FailedLoginAttempts,
+		&i.Lockouts,
+		&i.LockedUntil,
+		&i.EmailVerifiedAt,
+		&i.KYCStatus,
+	)
+	return i, err
+}
+
+const updateUserFullName = `-- name: UpdateUserFullName :one
+UPDATE users
+SET full_name = $1
+WHERE username = $2
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, disabled, role, failed_login_attempts, lockouts, locked_until, email_verified_at, kyc_status
+`
+
+type UpdateUserFullNameParams struct {
+	FullName string `json:"full_name"`
+	Username string `json:"username"`
+}
+
+func (q *Queries) UpdateUserFullName(ctx context.Context, arg UpdateUserFullNameParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserFullName, arg.FullName, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Disabled,
+		&i.Role,
+		&i.FailedLoginAttempts,
+		&i.Lockouts,
+		&i.LockedUntil,
+		&i.EmailVerifiedAt,
+		&i.KYCStatus,
 	)
 	return i, err
 }
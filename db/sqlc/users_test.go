@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -51,3 +52,33 @@ func TestGetUser(t *testing.T) {
 	require.WithinDuration(t, user1.PasswordChangedAt, user2.PasswordChangedAt, time.Second)
 	require.WithinDuration(t, user1.CreatedAt, user2.CreatedAt, time.Second)
 }
+
+func TestSetUserDisabled(t *testing.T) {
+	user := createRandomUser(t)
+	require.False(t, user.Disabled)
+
+	disabled, err := testQueries.SetUserDisabled(context.Background(), SetUserDisabledParams{Username: user.Username, Disabled: true})
+	require.NoError(t, err)
+	require.True(t, disabled.Disabled)
+
+	_, err = testQueries.SetUserDisabled(context.Background(), SetUserDisabledParams{Username: utils.RandomOwner(), Disabled: true})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestSetUserRole(t *testing.T) {
+	user := createRandomUser(t)
+	require.Equal(t, RoleCustomer, user.Role)
+
+	admin, err := testQueries.SetUserRole(context.Background(), SetUserRoleParams{
+		Username: user.Username,
+		Role:     RoleAdmin,
+	})
+	require.NoError(t, err)
+	require.Equal(t, RoleAdmin, admin.Role)
+
+	_, err = testQueries.SetUserRole(context.Background(), SetUserRoleParams{
+		Username: user.Username,
+		Role:     "root",
+	})
+	require.Error(t, err)
+}
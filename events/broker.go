@@ -0,0 +1,98 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/utils"
+)
+
+const (
+	EventTypeBalance = "balance"
+	EventTypeEntry   = "entry"
+)
+
+// Event is a change to a single account that is pushed to its subscribers.
+type Event struct {
+	Type      string      `json:"type"`
+	AccountID int64       `json:"account_id"`
+	Account   *db.Account `json:"account,omitempty"`
+	Entry     *db.Entry   `json:"entry,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// Broker fans account events out to every subscriber of that account.
+type Broker interface {
+	Publish(ctx context.Context, event Event) error
+	Subscribe(accountID int64) (<-chan Event, func())
+	Close() error
+}
+
+// PublishAll publishes the events of a committed change. A failure is logged
+// and doesn't stop the other events, as the change is made either way.
+func PublishAll(ctx context.Context, broker Broker, events []Event) {
+	for _, event := range events {
+		if err := broker.Publish(ctx, event); err != nil {
+			slog.ErrorContext(ctx, "cannot publish account event",
+				slog.String("type", event.Type),
+				slog.Int64("account_id", event.AccountID),
+				slog.Any("error", err),
+			)
+		}
+	}
+}
+
+// AccountEvents returns the events of entries booked to an account, followed
+// by the balance event of the account after them.
+func AccountEvents(account db.Account, entries ...db.Entry) []Event {
+	now := time.Now()
+	events := make([]Event, 0, len(entries)+1)
+	for i := range entries {
+		events = append(events, Event{Type: EventTypeEntry, AccountID: entries[i].AccountID, Entry: &entries[i], CreatedAt: now})
+	}
+	return append(events, Event{Type: EventTypeBalance, AccountID: account.ID, Account: &account, CreatedAt: now})
+}
+
+// PaymentEvents returns the balance and entry events for both sides of a
+// committed payment, and for the round-up pocket its spare change went to.
+func PaymentEvents(result db.PaymentTxResult) []Event {
+	fromEntries := []db.Entry{result.FromEntry}
+	sweep := result.RoundUpSweep
+	if sweep != nil {
+		fromEntries = append(fromEntries, sweep.FromEntry)
+	}
+
+	events := AccountEvents(result.FromAccount, fromEntries...)
+	events = append(events, AccountEvents(result.ToAccount, result.ToEntry)...)
+	if sweep != nil {
+		events = append(events, AccountEvents(sweep.ToAccount, sweep.ToEntry)...)
+	}
+	return events
+}
+
+// PocketMoveEvents returns the balance and entry events of both accounts of
+// a pocket move.
+func PocketMoveEvents(result db.MovePocketTxResult) []Event {
+	return append(AccountEvents(result.FromAccount, result.FromEntry), AccountEvents(result.ToAccount, result.ToEntry)...)
+}
+
+// AdjustmentEvents returns the balance and entry events of the account an
+// approved adjustment was booked to. The suspense side is internal.
+func AdjustmentEvents(result db.ApproveAdjustmentTxResult) []Event {
+	return AccountEvents(result.Account, result.Entry)
+}
+
+// NewBroker creates the broker selected by EVENT_BROKER, defaulting to in-memory.
+func NewBroker(config utils.Config) (Broker, error) {
+	switch config.EventBroker {
+	case "", "memory":
+		return NewMemoryBroker(), nil
+	case "postgres":
+		return NewPGBroker(config.DBSource())
+	default:
+		return nil, fmt.Errorf("unsupported event broker %q", config.EventBroker)
+	}
+}
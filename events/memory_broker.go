@@ -0,0 +1,82 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+const subscriberBufferSize = 16
+
+// MemoryBroker delivers events to subscribers within a single process.
+type MemoryBroker struct {
+	mu          sync.RWMutex
+	subscribers map[int64]map[chan Event]struct{}
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		subscribers: make(map[int64]map[chan Event]struct{}),
+	}
+}
+
+func (broker *MemoryBroker) Publish(ctx context.Context, event Event) error {
+	broker.dispatch(event)
+	return nil
+}
+
+// dispatch never blocks: a subscriber that is not keeping up misses the event
+// rather than stalling the payment path.
+func (broker *MemoryBroker) dispatch(event Event) {
+	broker.mu.RLock()
+	defer broker.mu.RUnlock()
+
+	for ch := range broker.subscribers[event.AccountID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+func (broker *MemoryBroker) Subscribe(accountID int64) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBufferSize)
+
+	broker.mu.Lock()
+	if broker.subscribers[accountID] == nil {
+		broker.subscribers[accountID] = make(map[chan Event]struct{})
+	}
+	broker.subscribers[accountID][ch] = struct{}{}
+	broker.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			broker.mu.Lock()
+			defer broker.mu.Unlock()
+
+			if _, ok := broker.subscribers[accountID][ch]; !ok {
+				return
+			}
+			delete(broker.subscribers[accountID], ch)
+			if len(broker.subscribers[accountID]) == 0 {
+				delete(broker.subscribers, accountID)
+			}
+			close(ch)
+		})
+	}
+
+	return ch, unsubscribe
+}
+
+func (broker *MemoryBroker) Close() error {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	for accountID, chans := range broker.subscribers {
+		for ch := range chans {
+			close(ch)
+		}
+		delete(broker.subscribers, accountID)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryBrokerFanOut(t *testing.T) {
+	broker := NewMemoryBroker()
+	defer broker.Close()
+
+	accountID := utils.RandomInt(1, 1000)
+	sub1, unsubscribe1 := broker.Subscribe(accountID)
+	defer unsubscribe1()
+	sub2, unsubscribe2 := broker.Subscribe(accountID)
+	defer unsubscribe2()
+	other, unsubscribeOther := broker.Subscribe(accountID + 1)
+	defer unsubscribeOther()
+
+	event := Event{Type: EventTypeBalance, AccountID: accountID, CreatedAt: time.Now()}
+	require.NoError(t, broker.Publish(context.Background(), event))
+
+	require.Equal(t, event, <-sub1)
+	require.Equal(t, event, <-sub2)
+	require.Empty(t, other)
+}
+
+func TestMemoryBrokerUnsubscribe(t *testing.T) {
+	broker := NewMemoryBroker()
+
+	accountID := utils.RandomInt(1, 1000)
+	sub, unsubscribe := broker.Subscribe(accountID)
+	unsubscribe()
+
+	_, ok := <-sub
+	require.False(t, ok)
+
+	require.NoError(t, broker.Publish(context.Background(), Event{AccountID: accountID}))
+
+	// unsubscribing twice or after Close must not panic
+	unsubscribe()
+	require.NoError(t, broker.Close())
+}
+
+func TestMemoryBrokerSlowSubscriber(t *testing.T) {
+	broker := NewMemoryBroker()
+	defer broker.Close()
+
+	accountID := utils.RandomInt(1, 1000)
+	sub, unsubscribe := broker.Subscribe(accountID)
+	defer unsubscribe()
+
+	for i := 0; i < subscriberBufferSize*2; i++ {
+		require.NoError(t, broker.Publish(context.Background(), Event{AccountID: accountID}))
+	}
+	require.Len(t, sub, subscriberBufferSize)
+}
+
+func TestPaymentEvents(t *testing.T) {
+	result := db.PaymentTxResult{
+		FromAccount: db.Account{ID: 1, Balance: 90},
+		ToAccount:   db.Account{ID: 2, Balance: 110},
+		FromEntry:   db.Entry{ID: 10, AccountID: 1, Amount: -10},
+		ToEntry:     db.Entry{ID: 11, AccountID: 2, Amount: 10},
+	}
+
+	evts := PaymentEvents(result)
+	require.Len(t, evts, 4)
+
+	byAccount := make(map[int64][]string)
+	for _, event := range evts {
+		byAccount[event.AccountID] = append(byAccount[event.AccountID], event.Type)
+	}
+	require.Equal(t, []string{EventTypeEntry, EventTypeBalance}, byAccount[1])
+	require.Equal(t, []string{EventTypeEntry, EventTypeBalance}, byAccount[2])
+}
+
+func TestAdjustmentEvents(t *testing.T) {
+	result := db.ApproveAdjustmentTxResult{
+		Account:         db.Account{ID: 1, Balance: 90},
+		SuspenseAccount: db.Account{ID: 2, Balance: 10},
+		Entry:           db.Entry{ID: 10, AccountID: 1, Amount: -10},
+		SuspenseEntry:   db.Entry{ID: 11, AccountID: 2, Amount: 10},
+	}
+
+	evts := AdjustmentEvents(result)
+	require.Len(t, evts, 2)
+	for _, event := range evts {
+		require.Equal(t, int64(1), event.AccountID)
+	}
+}
+
+func TestPaymentEventsRoundUp(t *testing.T) {
+	result := db.PaymentTxResult{
+		FromAccount: db.Account{ID: 1, Balance: 88},
+		ToAccount:   db.Account{ID: 2, Balance: 110},
+		FromEntry:   db.Entry{ID: 10, AccountID: 1, Amount: -10},
+		ToEntry:     db.Entry{ID: 11, AccountID: 2, Amount: 10},
+		RoundUpSweep: &db.MovePocketTxResult{
+			FromAccount: db.Account{ID: 1, Balance: 88},
+			ToAccount:   db.Account{ID: 3, Balance: 2},
+			FromEntry:   db.Entry{ID: 12, AccountID: 1, Amount: -2},
+			ToEntry:     db.Entry{ID: 13, AccountID: 3, Amount: 2},
+		},
+	}
+
+	evts := PaymentEvents(result)
+	require.Len(t, evts, 7)
+
+	byAccount := make(map[int64][]string)
+	for _, event := range evts {
+		byAccount[event.AccountID] = append(byAccount[event.AccountID], event.Type)
+	}
+	require.Equal(t, []string{EventTypeEntry, EventTypeEntry, EventTypeBalance}, byAccount[1])
+	require.Equal(t, []string{EventTypeEntry, EventTypeBalance}, byAccount[2])
+	require.Equal(t, []string{EventTypeEntry, EventTypeBalance}, byAccount[3])
+	require.Equal(t, int64(88), evts[2].Account.Balance)
+}
+
+func TestPocketMoveEvents(t *testing.T) {
+	result := db.MovePocketTxResult{
+		FromAccount: db.Account{ID: 1, Balance: 90},
+		ToAccount:   db.Account{ID: 2, Balance: 10},
+		FromEntry:   db.Entry{ID: 10, AccountID: 1, Amount: -10},
+		ToEntry:     db.Entry{ID: 11, AccountID: 2, Amount: 10},
+	}
+
+	evts := PocketMoveEvents(result)
+	require.Len(t, evts, 4)
+	require.Equal(t, int64(90), evts[1].Account.Balance)
+	require.Equal(t, int64(10), evts[3].Account.Balance)
+}
@@ -0,0 +1,102 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	notifyChannel        = "account_events"
+	minReconnectInterval = 10 * time.Second
+	maxReconnectInterval = time.Minute
+)
+
+// PGBroker publishes events with Postgres NOTIFY and delivers them from LISTEN,
+// so every API replica connected to the same database sees every event.
+type PGBroker struct {
+	local    *MemoryBroker
+	db       *sql.DB
+	listener *pq.Listener
+	done     chan struct{}
+}
+
+func NewPGBroker(dataSourceName string) (*PGBroker, error) {
+	conn, err := sql.Open("postgres", dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	listener := pq.NewListener(dataSourceName, minReconnectInterval, maxReconnectInterval, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			slog.Error("event listener", slog.Any("error", err))
+		}
+	})
+	if err := listener.Listen(notifyChannel); err != nil {
+		listener.Close()
+		conn.Close()
+		return nil, fmt.Errorf("cannot listen on %s: %w", notifyChannel, err)
+	}
+
+	broker := &PGBroker{
+		local:    NewMemoryBroker(),
+		db:       conn,
+		listener: listener,
+		done:     make(chan struct{}),
+	}
+	go broker.run()
+
+	return broker, nil
+}
+
+// Publish sends the event through NOTIFY only; it reaches local subscribers
+// when it comes back through the listener like on any other replica.
+func (broker *PGBroker) Publish(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = broker.db.ExecContext(ctx, "SELECT pg_notify($1, $2)", notifyChannel, string(payload))
+	return err
+}
+
+func (broker *PGBroker) Subscribe(accountID int64) (<-chan Event, func()) {
+	return broker.local.Subscribe(accountID)
+}
+
+func (broker *PGBroker) run() {
+	for {
+		select {
+		case <-broker.done:
+			return
+		case n := <-broker.listener.Notify:
+			// a nil notification means the connection was re-established
+			if n == nil {
+				continue
+			}
+
+			var event Event
+			if err := json.Unmarshal([]byte(n.Extra), &event); err != nil {
+				slog.Error("cannot decode event", slog.Any("error", err))
+				continue
+			}
+			broker.local.dispatch(event)
+		}
+	}
+}
+
+func (broker *PGBroker) Close() error {
+	close(broker.done)
+	broker.local.Close()
+
+	if err := broker.listener.Close(); err != nil {
+		return err
+	}
+	return broker.db.Close()
+}
@@ -0,0 +1,167 @@
+// Package fraud scores payments with configurable rules and decides whether
+// to let them through, to ask the payer to confirm them or to hold them for
+// review.
+package fraud
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/utils"
+)
+
+// Scores from which a payment is challenged or blocked when
+// FRAUD_CHALLENGE_SCORE and FRAUD_BLOCK_SCORE aren't set.
+const (
+	DefaultChallengeScore = 50
+	DefaultBlockScore     = 100
+)
+
+// RuleResult is how one rule saw a payment.
+type RuleResult struct {
+	Rule       string `json:"rule"`
+	Expression string `json:"expression"`
+	Score      int    `json:"score"`
+	Matched    bool   `json:"matched"`
+}
+
+// Decision is the outcome of the rules for a payment, with the trace of every
+// rule and the features they were evaluated against.
+type Decision struct {
+	Outcome  string       `json:"outcome"`
+	Score    int          `json:"score"`
+	Trace    []RuleResult `json:"trace"`
+	Features Features     `json:"features"`
+}
+
+type compiledRule struct {
+	Rule
+	expr *Expr
+}
+
+// Engine evaluates payments against a fixed set of rules. It is safe for
+// concurrent use.
+type Engine struct {
+	rules          []compiledRule
+	challengeScore int
+	blockScore     int
+	location       *time.Location
+	now            func() time.Time
+}
+
+// NewEngine compiles rules. Zero scores use the defaults.
+func NewEngine(rules []Rule, challengeScore, blockScore int) (*Engine, error) {
+	if challengeScore <= 0 {
+		challengeScore = DefaultChallengeScore
+	}
+	if blockScore <= 0 {
+		blockScore = DefaultBlockScore
+	}
+	if blockScore < challengeScore {
+		return nil, fmt.Errorf("block score %d is below the challenge score %d", blockScore, challengeScore)
+	}
+
+	engine := &Engine{
+		challengeScore: challengeScore,
+		blockScore:     blockScore,
+		location:       time.UTC,
+		now:            time.Now,
+	}
+	names := make(map[string]bool)
+	for _, rule := range rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("rule %q has no name", rule.Expression)
+		}
+		if names[rule.Name] {
+			return nil, fmt.Errorf("duplicate rule %s", rule.Name)
+		}
+		names[rule.Name] = true
+
+		expr, err := Compile(rule.Expression, Variables)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", rule.Name, err)
+		}
+		engine.rules = append(engine.rules, compiledRule{Rule: rule, expr: expr})
+	}
+	return engine, nil
+}
+
+// New builds the engine from config: the rules in FRAUD_RULES_FILE or the
+// default ones, the scores and the time zone of the hour of a payment.
+func New(config utils.Config) (*Engine, error) {
+	rules := DefaultRules
+	if config.FraudRulesFile != "" {
+		file, err := os.Open(config.FraudRulesFile)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		rules, err = LoadRules(file)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load %s: %w", config.FraudRulesFile, err)
+		}
+	}
+
+	engine, err := NewEngine(rules, config.FraudChallengeScore, config.FraudBlockScore)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.FraudTimeZone != "" {
+		engine.location, err = time.LoadLocation(config.FraudTimeZone)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return engine, nil
+}
+
+// Len returns the number of rules.
+func (engine *Engine) Len() int {
+	return len(engine.rules)
+}
+
+// Evaluate runs every rule against features and adds up the scores of those
+// that match.
+func (engine *Engine) Evaluate(features Features) Decision {
+	decision := Decision{
+		Outcome:  db.FraudAllow,
+		Trace:    make([]RuleResult, len(engine.rules)),
+		Features: features,
+	}
+
+	env := features.vars()
+	for i, rule := range engine.rules {
+		matched := rule.expr.Eval(env)
+		decision.Trace[i] = RuleResult{
+			Rule:       rule.Name,
+			Expression: rule.Expression,
+			Score:      rule.Score,
+			Matched:    matched,
+		}
+		if matched {
+			decision.Score += rule.Score
+		}
+	}
+
+	switch {
+	case decision.Score >= engine.blockScore:
+		decision.Outcome = db.FraudBlock
+	case decision.Score >= engine.challengeScore:
+		decision.Outcome = db.FraudChallenge
+	}
+	return decision
+}
+
+// Assess looks up the history of the payer and evaluates the payment.
+func (engine *Engine) Assess(ctx context.Context, q db.Querier, payment Payment) (Decision, error) {
+	features, err := collect(ctx, q, payment, engine.now(), engine.location)
+	if err != nil {
+		return Decision{}, err
+	}
+	return engine.Evaluate(features), nil
+}
@@ -0,0 +1,271 @@
+package fraud
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// usualFeatures are those of a routine payment to a known payee.
+func usualFeatures() Features {
+	return Features{
+		Amount:                      1000,
+		Currency:                    "EUR",
+		PaymentCount:                40,
+		PaymentsLastHour:            0,
+		PaymentsLastDay:             1,
+		AmountLastDay:               800,
+		AverageAmount:               1200,
+		HoursSincePasswordChange:    24 * 90,
+		PaymentsSincePasswordChange: 30,
+		Hour:                        14,
+		AccountAgeDays:              200,
+		UserAgeDays:                 200,
+		KYCVerified:                 true,
+	}
+}
+
+func matchedRules(decision Decision) []string {
+	var matched []string
+	for _, result := range decision.Trace {
+		if result.Matched {
+			matched = append(matched, result.Rule)
+		}
+	}
+	return matched
+}
+
+func TestEvaluateDefaultRules(t *testing.T) {
+	engine, err := NewEngine(DefaultRules, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, len(DefaultRules), engine.Len())
+
+	testCases := []struct {
+		name    string
+		change  func(features *Features)
+		outcome string
+		score   int
+		matched []string
+	}{
+		{
+			name:    "Usual",
+			change:  func(features *Features) {},
+			outcome: db.FraudAllow,
+		},
+		{
+			name: "NewPayeeLargeAmount",
+			change: func(features *Features) {
+				features.NewPayee = true
+				features.Amount = 5000
+			},
+			outcome: db.FraudAllow,
+			score:   40,
+			matched: []string{"new_payee_large_amount"},
+		},
+		{
+			name: "AmountSpikeToNewPayee",
+			change: func(features *Features) {
+				features.NewPayee = true
+				features.Amount = 9000
+			},
+			outcome: db.FraudChallenge,
+			score:   70,
+			matched: []string{"new_payee_large_amount", "amount_spike"},
+		},
+		{
+			name: "AfterPasswordReset",
+			change: func(features *Features) {
+				features.NewPayee = true
+				features.Amount = 5000
+				features.HoursSincePasswordChange = 2
+				features.PaymentsSincePasswordChange = 0
+			},
+			outcome: db.FraudChallenge,
+			score:   80,
+			matched: []string{"new_payee_large_amount", "first_payment_after_password_change"},
+		},
+		{
+			name: "TakeoverAtNight",
+			change: func(features *Features) {
+				features.NewPayee = true
+				features.Amount = 5000
+				features.HoursSincePasswordChange = 2
+				features.PaymentsSincePasswordChange = 0
+				features.Hour = 3
+			},
+			outcome: db.FraudBlock,
+			score:   100,
+			matched: []string{"new_payee_large_amount", "first_payment_after_password_change", "unusual_hour"},
+		},
+		{
+			name: "OwnTransferAtNight",
+			change: func(features *Features) {
+				features.OwnTransfer = true
+				features.Hour = 3
+				features.HoursSincePasswordChange = 2
+				features.PaymentsSincePasswordChange = 0
+			},
+			outcome: db.FraudAllow,
+		},
+		{
+			name: "Velocity",
+			change: func(features *Features) {
+				features.PaymentsLastHour = 5
+				features.PaymentsLastDay = 20
+			},
+			outcome: db.FraudChallenge,
+			score:   70,
+			matched: []string{"velocity_hour", "velocity_day"},
+		},
+		{
+			name: "DailyTotal",
+			change: func(features *Features) {
+				features.AmountLastDay = 49_500
+				features.Amount = 500
+			},
+			outcome: db.FraudAllow,
+			score:   30,
+			matched: []string{"velocity_day"},
+		},
+		{
+			name: "NewUserWithoutHistory",
+			change: func(features *Features) {
+				features.PaymentCount = 0
+				features.AverageAmount = 0
+				features.NewPayee = true
+				features.Amount = 9000
+				features.AccountAgeDays = 0.1
+			},
+			outcome: db.FraudChallenge,
+			score:   60,
+			matched: []string{"new_payee_large_amount", "new_account"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			features := usualFeatures()
+			tc.change(&features)
+
+			decision := engine.Evaluate(features)
+			require.Equal(t, tc.outcome, decision.Outcome)
+			require.Equal(t, tc.score, decision.Score)
+			require.Equal(t, tc.matched, matchedRules(decision))
+			require.Len(t, decision.Trace, len(DefaultRules))
+			require.Equal(t, features, decision.Features)
+		})
+	}
+}
+
+func TestNewEngine(t *testing.T) {
+	rules := []Rule{{Name: "large", Expression: "amount >= 1000", Score: 10}}
+
+	engine, err := NewEngine(rules, 10, 10)
+	require.NoError(t, err)
+	decision := engine.Evaluate(Features{Amount: 1000})
+	require.Equal(t, db.FraudBlock, decision.Outcome)
+	require.Equal(t, []RuleResult{{Rule: "large", Expression: "amount >= 1000", Score: 10, Matched: true}}, decision.Trace)
+
+	_, err = NewEngine(rules, 50, 40)
+	require.EqualError(t, err, "block score 40 is below the challenge score 50")
+
+	_, err = NewEngine(append(rules, rules[0]), 0, 0)
+	require.EqualError(t, err, "duplicate rule large")
+
+	_, err = NewEngine([]Rule{{Expression: "amount > 0"}}, 0, 0)
+	require.EqualError(t, err, `rule "amount > 0" has no name`)
+
+	_, err = NewEngine([]Rule{{Name: "typo", Expression: "amont > 0"}}, 0, 0)
+	require.ErrorContains(t, err, "rule typo: invalid expression")
+}
+
+func TestDecisionStatus(t *testing.T) {
+	require.Equal(t, db.FraudPassed, Decision{Outcome: db.FraudAllow}.Status(false))
+	require.Equal(t, db.FraudChallenged, Decision{Outcome: db.FraudChallenge}.Status(false))
+	require.Equal(t, db.FraudPassed, Decision{Outcome: db.FraudChallenge}.Status(true))
+	require.Equal(t, db.FraudPending, Decision{Outcome: db.FraudBlock}.Status(true))
+}
+
+func TestNew(t *testing.T) {
+	engine, err := New(utils.Config{})
+	require.NoError(t, err)
+	require.Equal(t, len(DefaultRules), engine.Len())
+
+	rulesFile := filepath.Join(t.TempDir(), "rules.json")
+	require.NoError(t, os.WriteFile(rulesFile, []byte(`[
+		{"name": "night", "expression": "hour < 6", "score": 60}
+	]`), 0o600))
+
+	engine, err = New(utils.Config{FraudRulesFile: rulesFile, FraudTimeZone: "Europe/Bucharest"})
+	require.NoError(t, err)
+	require.Equal(t, 1, engine.Len())
+	require.Equal(t, "Europe/Bucharest", engine.location.String())
+
+	_, err = New(utils.Config{FraudTimeZone: "Mars/Olympus"})
+	require.Error(t, err)
+
+	_, err = LoadRules(strings.NewReader(`[{"name": "x", "expr": "amount > 0"}]`))
+	require.ErrorContains(t, err, "unknown field")
+}
+
+func TestAssess(t *testing.T) {
+	now := time.Date(2024, 3, 10, 1, 30, 0, 0, time.UTC)
+	user := db.User{
+		Username:          "alice",
+		KYCStatus:         db.KYCVerified,
+		PasswordChangedAt: now.Add(-3 * time.Hour),
+		CreatedAt:         now.Add(-48 * time.Hour),
+	}
+	payment := Payment{
+		User:        user,
+		FromAccount: db.Account{ID: 1, Owner: "alice", Currency: "EUR", CreatedAt: now.Add(-12 * time.Hour)},
+		ToAccount:   db.Account{ID: 2, Owner: "bob", Currency: "EUR"},
+		Amount:      7000,
+	}
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetPaymentHistory(gomock.Any(), gomock.Eq(db.GetPaymentHistoryParams{
+			Payee:             "bob",
+			HourAgo:           now.Add(-time.Hour),
+			DayAgo:            now.Add(-24 * time.Hour),
+			Currency:          "EUR",
+			PasswordChangedAt: user.PasswordChangedAt,
+			InitiatedBy:       "alice",
+		})).
+		Times(1).
+		Return(db.GetPaymentHistoryRow{PaymentCount: 2, PaymentsLastDay: 2, AmountLastDay: 300, AverageAmount: 150}, nil)
+
+	engine, err := New(utils.Config{FraudTimeZone: "Europe/Bucharest"})
+	require.NoError(t, err)
+	engine.now = func() time.Time { return now }
+
+	decision, err := engine.Assess(context.Background(), store, payment)
+	require.NoError(t, err)
+	require.Equal(t, Features{
+		Amount:                   7000,
+		Currency:                 "EUR",
+		NewPayee:                 true,
+		PaymentCount:             2,
+		PaymentsLastDay:          2,
+		AmountLastDay:            300,
+		AverageAmount:            150,
+		HoursSincePasswordChange: 3,
+		Hour:                     3,
+		AccountAgeDays:           0.5,
+		UserAgeDays:              2,
+		KYCVerified:              true,
+	}, decision.Features)
+	require.Equal(t, db.FraudBlock, decision.Outcome)
+	require.Equal(t, []string{"new_payee_large_amount", "first_payment_after_password_change", "unusual_hour", "new_account"}, matchedRules(decision))
+}
@@ -0,0 +1,424 @@
+package fraud
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Kind is the type of a value in a rule expression.
+type Kind int
+
+const (
+	KindNumber Kind = iota
+	KindBool
+	KindString
+)
+
+func (kind Kind) String() string {
+	switch kind {
+	case KindNumber:
+		return "number"
+	case KindBool:
+		return "bool"
+	default:
+		return "string"
+	}
+}
+
+// Expr is a compiled rule expression, such as
+//
+//	new_payee && amount >= 5000 || payments_last_hour > 5
+//
+// Expressions combine the variables of a payment with numbers, strings in
+// double quotes, true and false, the arithmetic operators + - * /, the
+// comparisons == != < <= > >= and the logical operators ! && ||, with the
+// precedence they have in Go. They are type checked when compiled, so
+// evaluating them can't fail.
+type Expr struct {
+	src  string
+	root node
+}
+
+// Compile parses src and checks it against the kinds of the variables it
+// may use. The expression must be a condition, i.e. a bool.
+func Compile(src string, vars map[string]Kind) (*Expr, error) {
+	p := &parser{lexer: lexer{src: src}, vars: vars}
+	p.next()
+	root, err := p.parseOr()
+	if err == nil && p.tok.kind != tokEOF {
+		err = p.errorf("unexpected %s", p.tok)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", src, err)
+	}
+	if root.kind() != KindBool {
+		return nil, fmt.Errorf("invalid expression %q: is a %s, not a condition", src, root.kind())
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// Eval reports whether the expression holds for the values of the variables.
+// Variables missing from env have their zero value.
+func (expr *Expr) Eval(env map[string]any) bool {
+	return expr.root.eval(env).(bool)
+}
+
+func (expr *Expr) String() string {
+	return expr.src
+}
+
+type node interface {
+	kind() Kind
+	eval(env map[string]any) any
+}
+
+type literal struct {
+	value any
+	k     Kind
+}
+
+func (n literal) kind() Kind              { return n.k }
+func (n literal) eval(map[string]any) any { return n.value }
+
+type variable struct {
+	name string
+	k    Kind
+}
+
+func (n variable) kind() Kind { return n.k }
+
+func (n variable) eval(env map[string]any) any {
+	switch value := env[n.name].(type) {
+	case float64, bool, string:
+		return value
+	case int:
+		return float64(value)
+	case int64:
+		return float64(value)
+	}
+	switch n.k {
+	case KindNumber:
+		return float64(0)
+	case KindBool:
+		return false
+	default:
+		return ""
+	}
+}
+
+type unary struct {
+	op      string
+	operand node
+}
+
+func (n unary) kind() Kind { return n.operand.kind() }
+
+func (n unary) eval(env map[string]any) any {
+	if n.op == "!" {
+		return !n.operand.eval(env).(bool)
+	}
+	return -n.operand.eval(env).(float64)
+}
+
+type binary struct {
+	op          string
+	left, right node
+}
+
+func (n binary) kind() Kind {
+	switch n.op {
+	case "+", "-", "*", "/":
+		return KindNumber
+	}
+	return KindBool
+}
+
+func (n binary) eval(env map[string]any) any {
+	// && and || don't evaluate their right side when the left one decides
+	switch n.op {
+	case "&&":
+		return n.left.eval(env).(bool) && n.right.eval(env).(bool)
+	case "||":
+		return n.left.eval(env).(bool) || n.right.eval(env).(bool)
+	}
+
+	left, right := n.left.eval(env), n.right.eval(env)
+	switch n.op {
+	case "==":
+		return left == right
+	case "!=":
+		return left != right
+	}
+
+	if n.left.kind() == KindString {
+		l, r := left.(string), right.(string)
+		switch n.op {
+		case "<":
+			return l < r
+		case "<=":
+			return l <= r
+		case ">":
+			return l > r
+		default:
+			return l >= r
+		}
+	}
+
+	l, r := left.(float64), right.(float64)
+	switch n.op {
+	case "+":
+		return l + r
+	case "-":
+		return l - r
+	case "*":
+		return l * r
+	case "/":
+		if r == 0 {
+			return float64(0)
+		}
+		return l / r
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	default:
+		return l >= r
+	}
+}
+
+type parser struct {
+	lexer
+	tok  token
+	vars map[string]Kind
+}
+
+func (p *parser) next() {
+	p.tok = p.lexer.next()
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("at %d: %s", p.tok.pos+1, fmt.Sprintf(format, args...))
+}
+
+// binaryLevels lists the binary operators from the loosest to the tightest
+// binding.
+var binaryLevels = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!=", "<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/"},
+}
+
+func (p *parser) parseOr() (node, error) {
+	return p.parseLevel(0)
+}
+
+func (p *parser) parseLevel(level int) (node, error) {
+	if level == len(binaryLevels) {
+		return p.parseUnary()
+	}
+
+	left, err := p.parseLevel(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokOperator && slices.Contains(binaryLevels[level], p.tok.text) {
+		op, pos := p.tok.text, p.tok.pos
+		p.next()
+		right, err := p.parseLevel(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkBinary(op, left, right); err != nil {
+			return nil, fmt.Errorf("at %d: %w", pos+1, err)
+		}
+		left = binary{op: op, left: left, right: right}
+
+		// a < b < c doesn't mean what it seems to
+		if level == 2 && p.tok.kind == tokOperator && slices.Contains(binaryLevels[level], p.tok.text) {
+			return nil, p.errorf("comparisons can't be chained")
+		}
+	}
+	return left, nil
+}
+
+func checkBinary(op string, left, right node) error {
+	switch op {
+	case "&&", "||":
+		if left.kind() != KindBool || right.kind() != KindBool {
+			return fmt.Errorf("%s needs two conditions, got %s and %s", op, left.kind(), right.kind())
+		}
+	case "==", "!=":
+		if left.kind() != right.kind() {
+			return fmt.Errorf("can't compare a %s with a %s", left.kind(), right.kind())
+		}
+	case "<", "<=", ">", ">=":
+		if left.kind() != right.kind() || left.kind() == KindBool {
+			return fmt.Errorf("can't order a %s and a %s", left.kind(), right.kind())
+		}
+	default:
+		if left.kind() != KindNumber || right.kind() != KindNumber {
+			return fmt.Errorf("%s needs two numbers, got %s and %s", op, left.kind(), right.kind())
+		}
+	}
+	return nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.tok.kind == tokOperator && (p.tok.text == "!" || p.tok.text == "-") {
+		op := p.tok.text
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		want := KindBool
+		if op == "-" {
+			want = KindNumber
+		}
+		if operand.kind() != want {
+			return nil, p.errorf("%s needs a %s, got a %s", op, want, operand.kind())
+		}
+		return unary{op: op, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokNumber:
+		p.next()
+		value, err := strconv.ParseFloat(strings.ReplaceAll(tok.text, "_", ""), 64)
+		if err != nil {
+			return nil, fmt.Errorf("at %d: invalid number %s", tok.pos+1, tok.text)
+		}
+		return literal{value: value, k: KindNumber}, nil
+	case tokString:
+		p.next()
+		value, err := strconv.Unquote(tok.text)
+		if err != nil {
+			return nil, fmt.Errorf("at %d: invalid string %s", tok.pos+1, tok.text)
+		}
+		return literal{value: value, k: KindString}, nil
+	case tokIdent:
+		p.next()
+		switch tok.text {
+		case "true", "false":
+			return literal{value: tok.text == "true", k: KindBool}, nil
+		}
+		kind, ok := p.vars[tok.text]
+		if !ok {
+			return nil, fmt.Errorf("at %d: unknown variable %s", tok.pos+1, tok.text)
+		}
+		return variable{name: tok.text, k: kind}, nil
+	case tokOperator:
+		if tok.text == "(" {
+			p.next()
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if p.tok.kind != tokOperator || p.tok.text != ")" {
+				return nil, p.errorf("missing )")
+			}
+			p.next()
+			return inner, nil
+		}
+	case tokError:
+		return nil, p.errorf("%s", tok.text)
+	}
+	return nil, p.errorf("unexpected %s", tok)
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokError
+	tokNumber
+	tokString
+	tokIdent
+	tokOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (tok token) String() string {
+	if tok.kind == tokEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(tok.text)
+}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+// operators are matched longest first.
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/", "(", ")"}
+
+func (l *lexer) next() token {
+	for l.pos < len(l.src) && unicode.IsSpace(rune(l.src[l.pos])) {
+		l.pos++
+	}
+	start := l.pos
+	if start == len(l.src) {
+		return token{kind: tokEOF, pos: start}
+	}
+
+	c := l.src[start]
+	switch {
+	case isDigit(c) || c == '.':
+		for l.pos < len(l.src) && (isDigit(l.src[l.pos]) || l.src[l.pos] == '.' || l.src[l.pos] == '_') {
+			l.pos++
+		}
+		return token{kind: tokNumber, text: l.src[start:l.pos], pos: start}
+	case isLetter(c):
+		for l.pos < len(l.src) && (isLetter(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokIdent, text: l.src[start:l.pos], pos: start}
+	case c == '"':
+		l.pos++
+		for l.pos < len(l.src) && l.src[l.pos] != '"' {
+			if l.src[l.pos] == '\\' {
+				l.pos++
+			}
+			l.pos++
+		}
+		if l.pos >= len(l.src) {
+			return token{kind: tokError, text: "unterminated string", pos: start}
+		}
+		l.pos++
+		return token{kind: tokString, text: l.src[start:l.pos], pos: start}
+	}
+
+	for _, op := range operators {
+		if strings.HasPrefix(l.src[start:], op) {
+			l.pos += len(op)
+			return token{kind: tokOperator, text: op, pos: start}
+		}
+	}
+	l.pos = len(l.src)
+	return token{kind: tokError, text: fmt.Sprintf("unexpected character %q", c), pos: start}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
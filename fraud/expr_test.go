@@ -0,0 +1,96 @@
+package fraud
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testVars = map[string]Kind{
+	"amount":    KindNumber,
+	"count":     KindNumber,
+	"new_payee": KindBool,
+	"currency":  KindString,
+}
+
+func TestExprEval(t *testing.T) {
+	env := map[string]any{
+		"amount":    int64(6000),
+		"count":     3,
+		"new_payee": true,
+		"currency":  "EUR",
+	}
+
+	testCases := []struct {
+		src  string
+		want bool
+	}{
+		{"new_payee", true},
+		{"!new_payee", false},
+		{"amount >= 5000", true},
+		{"amount > 6_000", false},
+		{"new_payee && amount >= 5000", true},
+		{"!new_payee || amount < 100", false},
+		{"amount > 5 * count * 100", true},
+		{"amount / count == 2000", true},
+		{"amount - -1000 == 7000", true},
+		{"(count + 1) * 2 == 8", true},
+		{"count + 1 * 2 == 5", true},
+		{"currency == \"EUR\"", true},
+		{"currency != \"EUR\" || count <= 3", true},
+		{"true && !false", true},
+		// the right side of && and || only counts when it must
+		{"false && amount / 0 == 0", false},
+		// dividing by zero gives zero rather than infinity
+		{"amount / 0 == 0", true},
+		{"count >= 1 && count <= 2 || currency == \"USD\"", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.src, func(t *testing.T) {
+			expr, err := Compile(tc.src, testVars)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, expr.Eval(env))
+			require.Equal(t, tc.src, expr.String())
+		})
+	}
+}
+
+func TestExprZeroValues(t *testing.T) {
+	expr, err := Compile("amount == 0 && !new_payee && currency == \"\"", testVars)
+	require.NoError(t, err)
+	require.True(t, expr.Eval(nil))
+}
+
+func TestCompileErrors(t *testing.T) {
+	testCases := []struct {
+		src string
+		err string
+	}{
+		{"", "unexpected end of expression"},
+		{"amount", "is a number, not a condition"},
+		{"amount >", "unexpected end of expression"},
+		{"amount > 5000 &&", "unexpected end of expression"},
+		{"balance > 0", "unknown variable balance"},
+		{"amount > \"5000\"", "can't order a number and a string"},
+		{"new_payee == 1", "can't compare a bool with a number"},
+		{"new_payee < true", "can't order a bool and a bool"},
+		{"amount && new_payee", "&& needs two conditions"},
+		{"currency + 1 > 0", "+ needs two numbers"},
+		{"!amount", "! needs a bool"},
+		{"-new_payee", "- needs a number"},
+		{"(amount > 1", "missing )"},
+		{"amount > 1)", "unexpected \")\""},
+		{"0 < amount < 10", "comparisons can't be chained"},
+		{"currency == \"EUR", "unterminated string"},
+		{"amount > 1 # comment", "unexpected character '#'"},
+		{"amount > 1.2.3", "invalid number 1.2.3"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.src, func(t *testing.T) {
+			_, err := Compile(tc.src, testVars)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
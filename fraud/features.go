@@ -0,0 +1,111 @@
+package fraud
+
+import (
+	"context"
+	"time"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+)
+
+// Payment is a payment about to be made.
+type Payment struct {
+	User        db.User
+	FromAccount db.Account
+	ToAccount   db.Account
+	Amount      int64
+}
+
+// Features are what the rules know about a payment: the payment itself and
+// the history of the payer. Amounts are in the currency of the payment.
+type Features struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	// OwnTransfer is a payment between two accounts of the payer.
+	OwnTransfer bool `json:"own_transfer"`
+	// NewPayee is a payment to someone the payer never paid before.
+	NewPayee         bool    `json:"new_payee"`
+	PaymentCount     int64   `json:"payment_count"`
+	PaymentsLastHour int64   `json:"payments_last_hour"`
+	PaymentsLastDay  int64   `json:"payments_last_day"`
+	AmountLastDay    int64   `json:"amount_last_day"`
+	AverageAmount    float64 `json:"average_amount"`
+	// HoursSincePasswordChange is very large for a user who never changed
+	// their password.
+	HoursSincePasswordChange    float64 `json:"hours_since_password_change"`
+	PaymentsSincePasswordChange int64   `json:"payments_since_password_change"`
+	// Hour is the hour of the day, 0 to 23, in FRAUD_TIME_ZONE.
+	Hour           int     `json:"hour"`
+	AccountAgeDays float64 `json:"account_age_days"`
+	UserAgeDays    float64 `json:"user_age_days"`
+	KYCVerified    bool    `json:"kyc_verified"`
+}
+
+// vars are the features by the names rules use for them.
+func (features Features) vars() map[string]any {
+	return map[string]any{
+		"amount":                         features.Amount,
+		"currency":                       features.Currency,
+		"own_transfer":                   features.OwnTransfer,
+		"new_payee":                      features.NewPayee,
+		"payment_count":                  features.PaymentCount,
+		"payments_last_hour":             features.PaymentsLastHour,
+		"payments_last_day":              features.PaymentsLastDay,
+		"amount_last_day":                features.AmountLastDay,
+		"average_amount":                 features.AverageAmount,
+		"hours_since_password_change":    features.HoursSincePasswordChange,
+		"payments_since_password_change": features.PaymentsSincePasswordChange,
+		"hour":                           features.Hour,
+		"account_age_days":               features.AccountAgeDays,
+		"user_age_days":                  features.UserAgeDays,
+		"kyc_verified":                   features.KYCVerified,
+	}
+}
+
+// Variables are the names rules can use, with their kinds.
+var Variables = func() map[string]Kind {
+	kinds := make(map[string]Kind)
+	for name, value := range (Features{}).vars() {
+		switch value.(type) {
+		case bool:
+			kinds[name] = KindBool
+		case string:
+			kinds[name] = KindString
+		default:
+			kinds[name] = KindNumber
+		}
+	}
+	return kinds
+}()
+
+// collect works out the features of a payment made at now.
+func collect(ctx context.Context, q db.Querier, payment Payment, now time.Time, location *time.Location) (Features, error) {
+	history, err := q.GetPaymentHistory(ctx, db.GetPaymentHistoryParams{
+		Payee:             payment.ToAccount.Owner,
+		HourAgo:           now.Add(-time.Hour),
+		DayAgo:            now.Add(-24 * time.Hour),
+		Currency:          payment.FromAccount.Currency,
+		PasswordChangedAt: payment.User.PasswordChangedAt,
+		InitiatedBy:       payment.User.Username,
+	})
+	if err != nil {
+		return Features{}, err
+	}
+
+	return Features{
+		Amount:                      payment.Amount,
+		Currency:                    payment.FromAccount.Currency,
+		OwnTransfer:                 payment.ToAccount.Owner == payment.User.Username,
+		NewPayee:                    payment.ToAccount.Owner != payment.User.Username && history.PayeePayments == 0,
+		PaymentCount:                history.PaymentCount,
+		PaymentsLastHour:            history.PaymentsLastHour,
+		PaymentsLastDay:             history.PaymentsLastDay,
+		AmountLastDay:               history.AmountLastDay,
+		AverageAmount:               history.AverageAmount,
+		HoursSincePasswordChange:    now.Sub(payment.User.PasswordChangedAt).Hours(),
+		PaymentsSincePasswordChange: history.PaymentsSincePasswordChange,
+		Hour:                        now.In(location).Hour(),
+		AccountAgeDays:              now.Sub(payment.FromAccount.CreatedAt).Hours() / 24,
+		UserAgeDays:                 now.Sub(payment.User.CreatedAt).Hours() / 24,
+		KYCVerified:                 payment.User.KYCStatus == db.KYCVerified,
+	}, nil
+}
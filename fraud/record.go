@@ -0,0 +1,46 @@
+package fraud
+
+import (
+	"context"
+	"encoding/json"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+)
+
+// Status is what happens to a payment given its decision. A challenged
+// payment passes when the payer confirmed it.
+func (decision Decision) Status(confirmed bool) string {
+	switch decision.Outcome {
+	case db.FraudBlock:
+		return db.FraudPending
+	case db.FraudChallenge:
+		if !confirmed {
+			return db.FraudChallenged
+		}
+	}
+	return db.FraudPassed
+}
+
+// Record stores the decision for a payment with its trace.
+func Record(ctx context.Context, q db.Querier, payment Payment, decision Decision, status string) (db.FraudDecision, error) {
+	trace, err := json.Marshal(decision.Trace)
+	if err != nil {
+		return db.FraudDecision{}, err
+	}
+	features, err := json.Marshal(decision.Features)
+	if err != nil {
+		return db.FraudDecision{}, err
+	}
+
+	return q.CreateFraudDecision(ctx, db.CreateFraudDecisionParams{
+		Username:      payment.User.Username,
+		FromAccountID: payment.FromAccount.ID,
+		ToAccountID:   payment.ToAccount.ID,
+		Amount:        payment.Amount,
+		Score:         int32(decision.Score),
+		Outcome:       decision.Outcome,
+		Status:        status,
+		Trace:         trace,
+		Features:      features,
+	})
+}
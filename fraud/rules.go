@@ -0,0 +1,75 @@
+package fraud
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Rule adds its score to a payment when its expression holds.
+type Rule struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Expression  string `json:"expression"`
+	Score       int    `json:"score"`
+}
+
+// DefaultRules are used when FRAUD_RULES_FILE isn't set. Amounts follow the
+// KYC limits: 5000 is half of what an unverified user may send at once.
+var DefaultRules = []Rule{
+	{
+		Name:        "new_payee_large_amount",
+		Description: "a large payment to someone the payer never paid",
+		Expression:  "new_payee && amount >= 5000",
+		Score:       40,
+	},
+	{
+		Name:        "amount_spike",
+		Description: "far more than the payer usually sends",
+		Expression:  "payment_count >= 3 && amount > 5 * average_amount",
+		Score:       30,
+	},
+	{
+		Name:        "velocity_hour",
+		Description: "many payments in the last hour",
+		Expression:  "payments_last_hour >= 5",
+		Score:       40,
+	},
+	{
+		Name:        "velocity_day",
+		Description: "many payments or a large total in the last day",
+		Expression:  "payments_last_day >= 20 || amount_last_day + amount >= 50000",
+		Score:       30,
+	},
+	{
+		Name:        "first_payment_after_password_change",
+		Description: "the first payment within a day of a password change or reset",
+		Expression:  "hours_since_password_change < 24 && payments_since_password_change == 0 && !own_transfer",
+		Score:       40,
+	},
+	{
+		Name:        "unusual_hour",
+		Description: "a payment in the middle of the night",
+		Expression:  "hour >= 1 && hour < 5 && !own_transfer",
+		Score:       20,
+	},
+	{
+		Name:        "new_account",
+		Description: "a large payment from an account opened today",
+		Expression:  "account_age_days < 1 && amount >= 5000",
+		Score:       20,
+	},
+}
+
+// LoadRules reads rules from a JSON array like
+//
+//	[{"name": "velocity_hour", "expression": "payments_last_hour >= 5", "score": 40}]
+func LoadRules(r io.Reader) ([]Rule, error) {
+	var rules []Rule
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("cannot decode rules: %w", err)
+	}
+	return rules, nil
+}
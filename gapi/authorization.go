@@ -0,0 +1,109 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/logging"
+	"github.com/danielmoisa/neobank/pb"
+	"github.com/danielmoisa/neobank/tokens"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authorizationHeaderKey  = "authorization"
+	authorizationTypeBearer = "bearer"
+)
+
+type (
+	authorizationPayloadKey struct{}
+	authorizationUserKey    struct{}
+)
+
+// publicMethods can be called without an access token.
+var publicMethods = map[string]bool{
+	pb.Neobank_CreateUser_FullMethodName: true,
+	pb.Neobank_LoginUser_FullMethodName:  true,
+}
+
+// authInterceptor applies the same checks as the REST authMiddleware to every
+// Neobank RPC that is not public, including revoking the tokens issued before
+// the password was changed. Health and reflection stay unauthenticated.
+func (server *Server) authInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	if !strings.HasPrefix(info.FullMethod, "/"+pb.Neobank_ServiceDesc.ServiceName+"/") || publicMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+
+	payload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	// the gRPC API has no scopes, so apps only get the consented REST routes
+	if payload.ClientID != "" {
+		return nil, status.Error(codes.PermissionDenied, "tokens of third-party apps can't be used with this API")
+	}
+
+	user, err := server.store.GetUser(ctx, payload.Username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Error(codes.Unauthenticated, errRevokedToken.Error())
+		}
+		return nil, status.Error(codes.Internal, "failed to find user")
+	}
+
+	if user.Disabled {
+		return nil, status.Error(codes.PermissionDenied, "user is disabled")
+	}
+	if payload.IssuedAt.Before(user.PasswordChangedAt) {
+		return nil, status.Error(codes.Unauthenticated, errRevokedToken.Error())
+	}
+
+	ctx = context.WithValue(ctx, authorizationPayloadKey{}, payload)
+	ctx = context.WithValue(ctx, authorizationUserKey{}, user)
+	return handler(logging.WithUsername(ctx, payload.Username), req)
+}
+
+func (server *Server) authorizeUser(ctx context.Context) (*tokens.Payload, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, errMissingMetadata
+	}
+
+	values := md.Get(authorizationHeaderKey)
+	if len(values) == 0 {
+		return nil, errMissingAuthorization
+	}
+
+	fields := strings.Fields(values[0])
+	if len(fields) < 2 {
+		return nil, errInvalidAuthorizationFormat
+	}
+
+	authorizationType := strings.ToLower(fields[0])
+	if authorizationType != authorizationTypeBearer {
+		return nil, unsupportedAuthorizationTypeError(authorizationType)
+	}
+
+	return server.tokenMaker.VerifyToken(fields[1])
+}
+
+// authPayload returns the payload stored by authInterceptor.
+func authPayload(ctx context.Context) *tokens.Payload {
+	return ctx.Value(authorizationPayloadKey{}).(*tokens.Payload)
+}
+
+// authUser returns the user loaded by authInterceptor.
+func authUser(ctx context.Context) db.User {
+	return ctx.Value(authorizationUserKey{}).(db.User)
+}
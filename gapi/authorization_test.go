@@ -0,0 +1,199 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/pb"
+	"github.com/danielmoisa/neobank/tokens"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// expectUser lets authInterceptor load a user with a verified email for
+// username.
+func expectUser(store *mockdb.MockStore, username string) db.User {
+	user := db.User{
+		Username:        username,
+		Email:           utils.RandomEmail(),
+		EmailVerifiedAt: time.Now(),
+		KYCStatus:       db.KYCVerified,
+	}
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(username)).
+		Times(1).
+		Return(user, nil)
+	return user
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	username := utils.RandomOwner()
+	account := db.Account{
+		ID:       utils.RandomInt(1, 1000),
+		Owner:    username,
+		Balance:  utils.RandomMoney(),
+		Currency: utils.RandomCurrency(),
+	}
+
+	testCases := []struct {
+		name       string
+		buildCtx   func(t *testing.T, tokenMaker tokens.Maker) context.Context
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+	}{
+		{
+			name: "OK",
+			buildCtx: func(t *testing.T, tokenMaker tokens.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, authorizationTypeBearer, username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUser(store, username)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name: "NoAuthorization",
+			buildCtx: func(t *testing.T, tokenMaker tokens.Maker) context.Context {
+				return context.Background()
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "UnsupportedAuthorization",
+			buildCtx: func(t *testing.T, tokenMaker tokens.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, "unsupported", username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "InvalidAuthorizationFormat",
+			buildCtx: func(t *testing.T, tokenMaker tokens.Maker) context.Context {
+				md := metadata.MD{authorizationHeaderKey: []string{"bearer"}}
+				return metadata.NewOutgoingContext(context.Background(), md)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "ExpiredToken",
+			buildCtx: func(t *testing.T, tokenMaker tokens.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, authorizationTypeBearer, username, -time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "UserNotFound",
+			buildCtx: func(t *testing.T, tokenMaker tokens.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, authorizationTypeBearer, username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(username)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "UserDisabled",
+			buildCtx: func(t *testing.T, tokenMaker tokens.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, authorizationTypeBearer, username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(username)).
+					Times(1).
+					Return(db.User{Username: username, Disabled: true}, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.PermissionDenied,
+		},
+		{
+			name: "PasswordChanged",
+			buildCtx: func(t *testing.T, tokenMaker tokens.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, authorizationTypeBearer, username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(username)).
+					Times(1).
+					Return(db.User{Username: username, PasswordChangedAt: time.Now().Add(time.Second)}, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "AppToken",
+			buildCtx: func(t *testing.T, tokenMaker tokens.Maker) context.Context {
+				payload, err := tokens.NewPayload(username, time.Minute)
+				require.NoError(t, err)
+				payload.ClientID = "client_app"
+				payload.ConsentID = 1
+				payload.Scopes = []string{"accounts:read"}
+
+				token, err := tokenMaker.CreatePayloadToken(payload)
+				require.NoError(t, err)
+				md := metadata.MD{authorizationHeaderKey: []string{authorizationTypeBearer + " " + token}}
+				return metadata.NewOutgoingContext(context.Background(), md)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.PermissionDenied,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, client := newTestNeobankClient(t, store)
+			ctx := tc.buildCtx(t, server.tokenMaker)
+
+			_, err := client.GetAccount(ctx, &pb.GetAccountRequest{Id: account.ID})
+			require.Equal(t, tc.code, status.Code(err))
+		})
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	_, conn := newTestClient(t, nil)
+
+	res, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{
+		Service: pb.Neobank_ServiceDesc.ServiceName,
+	})
+	require.NoError(t, err)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, res.GetStatus())
+}
@@ -0,0 +1,40 @@
+package gapi
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	errMissingMetadata            = errors.New("missing metadata")
+	errMissingAuthorization       = errors.New("authorization header is not provided")
+	errInvalidAuthorizationFormat = errors.New("invalid authorization header format")
+	errRevokedToken               = errors.New("token has been revoked")
+)
+
+func unsupportedAuthorizationTypeError(authorizationType string) error {
+	return fmt.Errorf("unsupported authorization type %s", authorizationType)
+}
+
+func fieldViolation(field string, err error) *errdetails.BadRequest_FieldViolation {
+	return &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Description: err.Error(),
+	}
+}
+
+func invalidArgumentError(violations []*errdetails.BadRequest_FieldViolation) error {
+	badRequest := &errdetails.BadRequest{FieldViolations: violations}
+	statusInvalid := status.New(codes.InvalidArgument, "invalid parameters")
+
+	statusDetails, err := statusInvalid.WithDetails(badRequest)
+	if err != nil {
+		return statusInvalid.Err()
+	}
+
+	return statusDetails.Err()
+}
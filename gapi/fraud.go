@@ -0,0 +1,106 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/fraud"
+	"github.com/danielmoisa/neobank/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// confirmPasswordHeaderKey carries the password that confirms a payment the
+// fraud rules challenged. It is metadata so the request message stays the
+// same for clients that never see a challenge.
+const confirmPasswordHeaderKey = "x-confirm-password"
+
+// assessPayment runs the fraud rules on a payment and records the decision.
+// Challenged and blocked payments fail with PermissionDenied; the blocked
+// ones wait for a review.
+func (server *Server) assessPayment(ctx context.Context, payment fraud.Payment) (db.FraudDecision, error) {
+	decision, err := server.fraud.Assess(ctx, server.store, payment)
+	if err != nil {
+		return db.FraudDecision{}, status.Error(codes.Internal, "failed to assess payment")
+	}
+
+	var password string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(confirmPasswordHeaderKey); len(values) > 0 {
+			password = values[0]
+		}
+	}
+
+	confirmed := false
+	if decision.Outcome == db.FraudChallenge && password != "" {
+		confirmed, err = server.confirmPassword(ctx, payment.User, password)
+		if err != nil {
+			return db.FraudDecision{}, status.Error(codes.Internal, "failed to record failed login")
+		}
+	}
+
+	record, err := fraud.Record(ctx, server.store, payment, decision, decision.Status(confirmed))
+	if err != nil {
+		return db.FraudDecision{}, status.Error(codes.Internal, "failed to assess payment")
+	}
+
+	switch record.Status {
+	case db.FraudChallenged:
+		// a wrong password is answered like a missing one
+		server.auditHeldPayment(ctx, record, "fraud_challenge")
+		return record, status.Errorf(codes.PermissionDenied, "confirm the payment by sending it again with your password in %s", confirmPasswordHeaderKey)
+	case db.FraudPending:
+		server.auditHeldPayment(ctx, record, "fraud_block")
+		return record, status.Errorf(codes.PermissionDenied, "the payment is held for review (decision %d)", record.ID)
+	}
+	return record, nil
+}
+
+// confirmPassword checks the password sent to confirm a challenged payment.
+// A wrong one counts towards the lockout like a failed login, and a locked
+// user can't confirm at all.
+func (server *Server) confirmPassword(ctx context.Context, user db.User, password string) (bool, error) {
+	if user.LockedUntil.After(time.Now()) {
+		return false, nil
+	}
+	if utils.CheckPassword(user.HashedPassword, password) == nil {
+		return true, nil
+	}
+	return false, server.recordLoginFailure(ctx, user.Username)
+}
+
+func (server *Server) auditHeldPayment(ctx context.Context, decision db.FraudDecision, reason string) {
+	audit.Record(ctx, server.store, audit.Event{
+		Action:       audit.ActionPaymentCreate,
+		Outcome:      audit.OutcomeFailure,
+		ResourceType: audit.ResourcePayment,
+		Metadata: map[string]interface{}{
+			"reason":            reason,
+			"fraud_decision_id": decision.ID,
+			"from_account_id":   decision.FromAccountID,
+			"to_account_id":     decision.ToAccountID,
+			"amount":            decision.Amount,
+		},
+	})
+}
+
+// linkPayment records which payment a decision let through. The payment is
+// already made, so a failure is only logged.
+func (server *Server) linkPayment(ctx context.Context, decision db.FraudDecision, payment db.Payment) {
+	_, err := server.store.SetFraudDecisionPayment(ctx, db.SetFraudDecisionPaymentParams{
+		ID:        decision.ID,
+		PaymentID: sql.NullInt64{Int64: payment.ID, Valid: true},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "cannot link payment to fraud decision",
+			slog.Int64("fraud_decision_id", decision.ID),
+			slog.Int64("payment_id", payment.ID),
+			slog.Any("error", err),
+		)
+	}
+}
@@ -0,0 +1,72 @@
+package gapi
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"unicode"
+
+	"github.com/danielmoisa/neobank/logging"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	requestIDHeaderKey = "x-request-id"
+	maxRequestIDLength = 128
+)
+
+// loggingInterceptor propagates x-request-id like the REST API does and writes
+// one structured log line per call.
+func (server *Server) loggingInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	requestID := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDHeaderKey); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+	if !validRequestID(requestID) {
+		requestID = uuid.NewString()
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDHeaderKey, requestID))
+
+	ctx = logging.WithRequestID(ctx, requestID)
+	if p, ok := peer.FromContext(ctx); ok {
+		ctx = logging.WithClientIP(ctx, p.Addr.String())
+	}
+
+	start := time.Now()
+	result, err := handler(ctx, req)
+
+	level := slog.LevelInfo
+	if err != nil {
+		level = slog.LevelWarn
+	}
+
+	slog.LogAttrs(ctx, level, "grpc request",
+		slog.String("method", info.FullMethod),
+		slog.String("status", status.Code(err).String()),
+		slog.Duration("latency", time.Since(start)),
+	)
+	return result, err
+}
+
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range requestID {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/events"
+	"github.com/danielmoisa/neobank/fraud"
+	"github.com/danielmoisa/neobank/mail"
+	"github.com/danielmoisa/neobank/metrics"
+	"github.com/danielmoisa/neobank/pb"
+	"github.com/danielmoisa/neobank/ratelimit"
+	"github.com/danielmoisa/neobank/screening"
+	"github.com/danielmoisa/neobank/tokens"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const bufSize = 1024 * 1024
+
+// newTestClient serves a test server over an in-memory bufconn listener and
+// returns a client connection to it.
+func newTestClient(t *testing.T, store db.Store) (*Server, *grpc.ClientConn) {
+	config := utils.Config{
+		TokenSymmetricKey:   utils.RandomString(32),
+		AccessTokenDuration: time.Minute,
+	}
+
+	fraudEngine, err := fraud.NewEngine(nil, 0, 0)
+	require.NoError(t, err)
+
+	server, err := NewServer(config, store, events.NewMemoryBroker(), metrics.New(), ratelimit.NewMemoryLimiter(), mail.NewMemoryMailer(), screening.NewScreener(0, nil), fraudEngine)
+	require.NoError(t, err)
+
+	listener := bufconn.Listen(bufSize)
+	go server.Serve(listener)
+	t.Cleanup(server.grpcServer.Stop)
+
+	conn, err := grpc.DialContext(
+		context.Background(),
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return server, conn
+}
+
+func newTestNeobankClient(t *testing.T, store db.Store) (*Server, pb.NeobankClient) {
+	server, conn := newTestClient(t, store)
+	return server, pb.NewNeobankClient(conn)
+}
+
+func newContextWithBearerToken(
+	t *testing.T,
+	tokenMaker tokens.Maker,
+	authorizationType string,
+	username string,
+	duration time.Duration,
+) context.Context {
+	token, err := tokenMaker.CreateToken(username, duration)
+	require.NoError(t, err)
+
+	authorizationHeader := fmt.Sprintf("%s %s", authorizationType, token)
+	md := metadata.MD{
+		authorizationHeaderKey: []string{authorizationHeader},
+	}
+	return metadata.NewOutgoingContext(context.Background(), md)
+}
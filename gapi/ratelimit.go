@@ -0,0 +1,79 @@
+package gapi
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/danielmoisa/neobank/logging"
+	"github.com/danielmoisa/neobank/pb"
+	"github.com/danielmoisa/neobank/ratelimit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const retryAfterHeaderKey = "retry-after"
+
+// rateLimitInterceptor applies the rate limits of the REST API to the
+// Neobank RPCs, using the same route names so both APIs share the buckets.
+// It must run after authInterceptor. Like the REST middleware it lets calls
+// through when the limiter fails.
+func (server *Server) rateLimitInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	if !strings.HasPrefix(info.FullMethod, "/"+pb.Neobank_ServiceDesc.ServiceName+"/") {
+		return handler(ctx, req)
+	}
+
+	route, policy, key := "user", ratelimit.UserPolicy, ""
+	switch info.FullMethod {
+	case pb.Neobank_CreateUser_FullMethodName:
+		route, policy, key = "signup", ratelimit.AuthPolicy, "ip:"+clientHost(ctx)
+	case pb.Neobank_LoginUser_FullMethodName:
+		route, policy, key = "login", ratelimit.AuthPolicy, "ip:"+clientHost(ctx)
+	case pb.Neobank_CreatePayment_FullMethodName:
+		route, policy = "payments", ratelimit.PaymentPolicy
+	}
+	if key == "" {
+		key = "user:" + authPayload(ctx).Username
+	}
+
+	decision, err := server.limiter.Allow(ctx, route+":"+key, policy)
+	if err != nil {
+		slog.ErrorContext(ctx, "cannot check rate limit", slog.String("route", route), slog.Any("error", err))
+		return handler(ctx, req)
+	}
+
+	if !decision.Allowed {
+		server.metrics.IncRateLimited(route)
+		setRetryAfter(ctx, decision.RetryAfter.Seconds())
+		return nil, status.Error(codes.ResourceExhausted, "too many requests, retry later")
+	}
+
+	return handler(ctx, req)
+}
+
+// clientHost drops the port from the peer address, so that every connection
+// from the same host shares a bucket.
+func clientHost(ctx context.Context) string {
+	addr := logging.ClientIP(ctx)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// setRetryAfter tells the client in the response header how many whole
+// seconds to wait.
+func setRetryAfter(ctx context.Context, seconds float64) {
+	wait := int(math.Max(1, math.Ceil(seconds)))
+	grpc.SetHeader(ctx, metadata.Pairs(retryAfterHeaderKey, strconv.Itoa(wait)))
+}
@@ -0,0 +1,116 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"github.com/danielmoisa/neobank/audit"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	err := server.validateFields(
+		fieldRule{"currency", req.GetCurrency(), "required"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.CheckKYCAccountLimit(ctx, server.store, authUser(ctx)); err != nil {
+		return nil, kycError(err)
+	}
+
+	account, err := server.store.CreateAccount(ctx, db.CreateAccountParams{
+		Owner:    authPayload(ctx).Username,
+		Currency: req.GetCurrency(),
+		Balance:  0,
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to create account")
+	}
+
+	audit.Record(ctx, server.store, audit.Event{
+		Action:       audit.ActionAccountCreate,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceAccount,
+		ResourceID:   strconv.FormatInt(account.ID, 10),
+		Metadata:     map[string]interface{}{"currency": account.Currency},
+	})
+
+	return &pb.CreateAccountResponse{Account: convertAccount(account)}, nil
+}
+
+func (server *Server) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	err := server.validateFields(
+		fieldRule{"id", req.GetId(), "required,min=1"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := server.store.GetAccount(ctx, req.GetId())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Error(codes.NotFound, "account not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to get account")
+	}
+
+	if _, err := db.AuthorizeAccount(ctx, server.store, account, authPayload(ctx).Username, db.AccountActionView); err != nil {
+		return nil, accountAccessError(err)
+	}
+
+	return &pb.GetAccountResponse{Account: convertAccount(account)}, nil
+}
+
+func (server *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	err := server.validateFields(
+		fieldRule{"page_id", req.GetPageId(), "required,min=1"},
+		fieldRule{"page_size", req.GetPageSize(), "required,min=5,max=10"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := server.store.ListAccounts(ctx, db.ListAccountsParams{
+		Owner:  authPayload(ctx).Username,
+		Limit:  req.GetPageSize(),
+		Offset: (req.GetPageId() - 1) * req.GetPageSize(),
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to list accounts")
+	}
+
+	// the query finds the accounts the user owns or has a role on, and every
+	// role may view an account
+	res := &pb.ListAccountsResponse{Accounts: make([]*pb.Account, len(accounts))}
+	for i, account := range accounts {
+		res.Accounts[i] = convertAccount(account)
+	}
+	return res, nil
+}
+
+// accountAccessError maps the errors of authorizing access to an account.
+func accountAccessError(err error) error {
+	switch {
+	case errors.Is(err, db.ErrNotAccountMember):
+		return status.Error(codes.PermissionDenied, "account doesn't belongs to auth user")
+	case errors.Is(err, db.ErrAccountActionDenied):
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return status.Error(codes.Internal, "failed to authorize account")
+}
+
+// kycError maps the errors of checking the limits of the KYC tiers.
+func kycError(err error) error {
+	var limitErr *db.KYCLimitError
+	if errors.As(err, &limitErr) {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return status.Error(codes.Internal, "failed to check verification limits")
+}
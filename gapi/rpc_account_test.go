@@ -0,0 +1,496 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/fraud"
+	"github.com/danielmoisa/neobank/pb"
+	"github.com/danielmoisa/neobank/ratelimit"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetAccountRPC(t *testing.T) {
+	username := utils.RandomOwner()
+	account := db.Account{
+		ID:       utils.RandomInt(1, 1000),
+		Owner:    username,
+		Balance:  utils.RandomMoney(),
+		Currency: utils.RandomCurrency(),
+	}
+
+	testCases := []struct {
+		name          string
+		accountID     int64
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.GetAccountResponse, err error)
+	}{
+		{
+			name:      "OK",
+			accountID: account.ID,
+			username:  username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, account.ID, res.GetAccount().GetId())
+				require.Equal(t, account.Owner, res.GetAccount().GetOwner())
+				require.Equal(t, account.Balance, res.GetAccount().GetBalance())
+				require.Equal(t, account.Currency, res.GetAccount().GetCurrency())
+			},
+		},
+		{
+			name:      "NotFound",
+			accountID: account.ID,
+			username:  username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountResponse, err error) {
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name:      "InternalError",
+			accountID: account.ID,
+			username:  username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.Account{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountResponse, err error) {
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name:      "InvalidID",
+			accountID: 0,
+			username:  username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name:      "UnauthorizedUser",
+			accountID: account.ID,
+			username:  utils.RandomOwner(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AccountMember{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectUser(store, tc.username)
+			tc.buildStubs(store)
+
+			server, client := newTestNeobankClient(t, store)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+
+			res, err := client.GetAccount(ctx, &pb.GetAccountRequest{Id: tc.accountID})
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestCreatePaymentRPC(t *testing.T) {
+	username := utils.RandomOwner()
+	fromAccount := db.Account{ID: 1, Owner: username, Balance: 100, Currency: "EUR"}
+	toAccount := db.Account{ID: 2, Owner: utils.RandomOwner(), Balance: 100, Currency: "EUR"}
+	amount := int64(10)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	expectUser(store, username)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+	expectUser(store, toAccount.Owner)
+	store.EXPECT().
+		CountBlockingScreeningHits(gomock.Any(), gomock.Eq([]string{username, toAccount.Owner})).
+		Times(1).
+		Return(int64(0), nil)
+	store.EXPECT().
+		GetPaymentHistory(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.GetPaymentHistoryRow{}, nil)
+	store.EXPECT().
+		CreateFraudDecision(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.FraudDecision{ID: 1, Outcome: db.FraudAllow, Status: db.FraudPassed}, nil)
+	store.EXPECT().
+		SetFraudDecisionPayment(gomock.Any(), gomock.Eq(db.SetFraudDecisionPaymentParams{
+			ID:        1,
+			PaymentID: sql.NullInt64{Int64: 1, Valid: true},
+		})).
+		Times(1)
+	store.EXPECT().
+		PaymentTx(gomock.Any(), gomock.Eq(db.PaymentTxParams{
+			FromAccountID: fromAccount.ID,
+			ToAccountID:   toAccount.ID,
+			Amount:        amount,
+			InitiatedBy:   fromAccount.Owner,
+		})).
+		Times(1).
+		Return(db.PaymentTxResult{
+			Payment:     db.Payment{ID: 1, FromAccountID: fromAccount.ID, ToAccountID: toAccount.ID, Amount: amount},
+			FromAccount: db.Account{ID: fromAccount.ID, Balance: fromAccount.Balance - amount},
+			ToAccount:   db.Account{ID: toAccount.ID, Balance: toAccount.Balance + amount},
+			FromEntry:   db.Entry{ID: 1, AccountID: fromAccount.ID, Amount: -amount},
+			ToEntry:     db.Entry{ID: 2, AccountID: toAccount.ID, Amount: amount},
+		}, nil)
+	store.EXPECT().
+		CreateAuditEvent(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.AuditEvent{}, nil)
+
+	server, client := newTestNeobankClient(t, store)
+	sub, unsubscribe := server.broker.Subscribe(toAccount.ID)
+	defer unsubscribe()
+
+	ctx := newContextWithBearerToken(t, server.tokenMaker, authorizationTypeBearer, username, time.Minute)
+	res, err := client.CreatePayment(ctx, &pb.CreatePaymentRequest{
+		FromAccountId: fromAccount.ID,
+		ToAccountId:   toAccount.ID,
+		Amount:        amount,
+		Currency:      "EUR",
+	})
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance-amount, res.GetFromAccount().GetBalance())
+	require.Equal(t, toAccount.Balance+amount, res.GetToAccount().GetBalance())
+
+	// the receiving account sees the new entry and balance
+	require.Len(t, sub, 2)
+}
+
+func TestCreatePaymentRPCFrozenAccount(t *testing.T) {
+	username := utils.RandomOwner()
+	fromAccount := db.Account{ID: 1, Owner: username, Balance: 100, Currency: "EUR"}
+	toAccount := db.Account{ID: 2, Owner: utils.RandomOwner(), Balance: 100, Currency: "EUR", Frozen: true}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	expectUser(store, username)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+	store.EXPECT().PaymentTx(gomock.Any(), gomock.Any()).Times(0)
+
+	server, client := newTestNeobankClient(t, store)
+	ctx := newContextWithBearerToken(t, server.tokenMaker, authorizationTypeBearer, username, time.Minute)
+	_, err := client.CreatePayment(ctx, &pb.CreatePaymentRequest{
+		FromAccountId: fromAccount.ID,
+		ToAccountId:   toAccount.ID,
+		Amount:        10,
+		Currency:      "EUR",
+	})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestCreatePaymentRPCScreeningHold(t *testing.T) {
+	username := utils.RandomOwner()
+	fromAccount := db.Account{ID: 1, Owner: username, Balance: 100, Currency: "EUR"}
+	toAccount := db.Account{ID: 2, Owner: utils.RandomOwner(), Balance: 100, Currency: "EUR"}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	expectUser(store, username)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+	expectUser(store, toAccount.Owner)
+	store.EXPECT().
+		CountBlockingScreeningHits(gomock.Any(), gomock.Eq([]string{username, toAccount.Owner})).
+		Times(1).
+		Return(int64(1), nil)
+	store.EXPECT().
+		CreateAuditEvent(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.AuditEvent{}, nil)
+	store.EXPECT().PaymentTx(gomock.Any(), gomock.Any()).Times(0)
+
+	server, client := newTestNeobankClient(t, store)
+	ctx := newContextWithBearerToken(t, server.tokenMaker, authorizationTypeBearer, username, time.Minute)
+	_, err := client.CreatePayment(ctx, &pb.CreatePaymentRequest{
+		FromAccountId: fromAccount.ID,
+		ToAccountId:   toAccount.ID,
+		Amount:        10,
+		Currency:      "EUR",
+	})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestCreatePaymentRPCFraudRules(t *testing.T) {
+	password := utils.RandomString(6)
+	hashedPassword, err := utils.HashPassword(password)
+	require.NoError(t, err)
+
+	user := db.User{
+		Username:        utils.RandomOwner(),
+		HashedPassword:  hashedPassword,
+		Email:           utils.RandomEmail(),
+		EmailVerifiedAt: time.Now(),
+		KYCStatus:       db.KYCVerified,
+	}
+	fromAccount := db.Account{ID: 1, Owner: user.Username, Balance: 100_000, Currency: "EUR"}
+	toAccount := db.Account{ID: 2, Owner: utils.RandomOwner(), Balance: 100, Currency: "EUR"}
+
+	testCases := []struct {
+		name     string
+		amount   int64
+		password string
+		status   string
+		code     codes.Code
+		// a wrong password counts as a failed login
+		loginFailures int
+	}{
+		{name: "Challenge", amount: 1000, status: db.FraudChallenged, code: codes.PermissionDenied},
+		{name: "ChallengeWrongPassword", amount: 1000, password: "wrong password", status: db.FraudChallenged, code: codes.PermissionDenied, loginFailures: 1},
+		{name: "ChallengeConfirmed", amount: 1000, password: password, status: db.FraudPassed, code: codes.OK},
+		{name: "Block", amount: 10000, password: password, status: db.FraudPending, code: codes.PermissionDenied},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+			expectUser(store, toAccount.Owner)
+			store.EXPECT().CountBlockingScreeningHits(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+			store.EXPECT().
+				GetPaymentHistory(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.GetPaymentHistoryRow{PayeePayments: 1}, nil)
+			store.EXPECT().
+				LoginFailureTx(gomock.Any(), gomock.Eq(db.LoginFailureTxParams{Username: user.Username})).
+				Times(tc.loginFailures).
+				Return(db.LoginFailureTxResult{User: user}, nil)
+			store.EXPECT().
+				CreateFraudDecision(gomock.Any(), gomock.Cond(func(x any) bool {
+					arg, ok := x.(db.CreateFraudDecisionParams)
+					return ok && arg.Status == tc.status
+				})).
+				Times(1).
+				Return(db.FraudDecision{ID: 1, Status: tc.status}, nil)
+			store.EXPECT().
+				CreateAuditEvent(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.AuditEvent{}, nil)
+			if tc.code == codes.OK {
+				store.EXPECT().
+					PaymentTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PaymentTxResult{Payment: db.Payment{ID: 1}}, nil)
+				store.EXPECT().SetFraudDecisionPayment(gomock.Any(), gomock.Any()).Times(1)
+			} else {
+				store.EXPECT().PaymentTx(gomock.Any(), gomock.Any()).Times(0)
+			}
+
+			server, client := newTestNeobankClient(t, store)
+			server.fraud, err = fraud.NewEngine([]fraud.Rule{
+				{Name: "large", Expression: "amount >= 1000", Score: 60},
+				{Name: "huge", Expression: "amount >= 10000", Score: 60},
+			}, 0, 0)
+			require.NoError(t, err)
+
+			ctx := newContextWithBearerToken(t, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			if tc.password != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, confirmPasswordHeaderKey, tc.password)
+			}
+			_, err := client.CreatePayment(ctx, &pb.CreatePaymentRequest{
+				FromAccountId: fromAccount.ID,
+				ToAccountId:   toAccount.ID,
+				Amount:        tc.amount,
+				Currency:      "EUR",
+			})
+			require.Equal(t, tc.code, status.Code(err))
+			if tc.status == db.FraudChallenged {
+				// the same answer whether a password was sent or not
+				require.Contains(t, status.Convert(err).Message(), "confirm the payment")
+			}
+		})
+	}
+}
+
+func TestCreatePaymentRPCUnverifiedEmail(t *testing.T) {
+	username := utils.RandomOwner()
+	fromAccount := db.Account{ID: 1, Owner: username, Balance: 100_000, Currency: "EUR"}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(username)).
+		Times(1).
+		Return(db.User{Username: username}, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+	store.EXPECT().PaymentTx(gomock.Any(), gomock.Any()).Times(0)
+
+	server, client := newTestNeobankClient(t, store)
+	ctx := newContextWithBearerToken(t, server.tokenMaker, authorizationTypeBearer, username, time.Minute)
+	_, err := client.CreatePayment(ctx, &pb.CreatePaymentRequest{
+		FromAccountId: fromAccount.ID,
+		ToAccountId:   2,
+		Amount:        utils.DefaultUnverifiedPaymentLimit + 1,
+		Currency:      "EUR",
+	})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestCreateUserRPCInvalidArguments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+
+	_, client := newTestNeobankClient(t, store)
+	_, err := client.CreateUser(context.Background(), &pb.CreateUserRequest{
+		Username: "invalid-user#1",
+		Password: "123",
+		FullName: utils.RandomOwner(),
+		Email:    "invalid-email",
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	st, _ := status.FromError(err)
+	require.Len(t, st.Details(), 1)
+}
+
+func TestLoginUserRPCLocked(t *testing.T) {
+	user := db.User{
+		Username:    utils.RandomOwner(),
+		LockedUntil: time.Now().Add(time.Minute),
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+	store.EXPECT().LoginFailureTx(gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().CreateAuditEvent(gomock.Any(), gomock.Any()).Times(1).Return(db.AuditEvent{}, nil)
+
+	_, client := newTestNeobankClient(t, store)
+	var header metadata.MD
+	_, err := client.LoginUser(context.Background(), &pb.LoginUserRequest{
+		Username: user.Username,
+		Password: utils.RandomString(8),
+	}, grpc.Header(&header))
+	// the same answer as for an unknown user
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	require.Empty(t, header.Get(retryAfterHeaderKey))
+}
+
+func TestLoginUserRPCRateLimited(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Any()).
+		Times(ratelimit.AuthPolicy.Burst).
+		Return(db.User{}, sql.ErrNoRows)
+	store.EXPECT().
+		CreateAuditEvent(gomock.Any(), gomock.Any()).
+		Times(ratelimit.AuthPolicy.Burst).
+		Return(db.AuditEvent{}, nil)
+
+	_, client := newTestNeobankClient(t, store)
+	req := &pb.LoginUserRequest{
+		Username: utils.RandomOwner(),
+		Password: utils.RandomString(8),
+	}
+
+	for i := 0; i < ratelimit.AuthPolicy.Burst; i++ {
+		_, err := client.LoginUser(context.Background(), req)
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	}
+
+	var header metadata.MD
+	_, err := client.LoginUser(context.Background(), req, grpc.Header(&header))
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.NotEmpty(t, header.Get(retryAfterHeaderKey))
+}
+
+func TestCreatePaymentRPCKYCLimit(t *testing.T) {
+	username := utils.RandomOwner()
+	fromAccount := db.Account{ID: 1, Owner: username, Balance: 100_000, Currency: "EUR"}
+	toAccount := db.Account{ID: 2, Owner: utils.RandomOwner(), Balance: 100, Currency: "EUR"}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(username)).
+		Times(1).
+		Return(db.User{Username: username, EmailVerifiedAt: time.Now(), KYCStatus: db.KYCPending}, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+	store.EXPECT().
+		GetOutgoingPaymentTotal(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.BasicKYCLimits.DailyPayments-10, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(0)
+	store.EXPECT().PaymentTx(gomock.Any(), gomock.Any()).Times(0)
+
+	server, client := newTestNeobankClient(t, store)
+	ctx := newContextWithBearerToken(t, server.tokenMaker, authorizationTypeBearer, username, time.Minute)
+	_, err := client.CreatePayment(ctx, &pb.CreatePaymentRequest{
+		FromAccountId: fromAccount.ID,
+		ToAccountId:   toAccount.ID,
+		Amount:        20,
+		Currency:      "EUR",
+	})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
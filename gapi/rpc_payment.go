@@ -0,0 +1,140 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"github.com/danielmoisa/neobank/audit"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/events"
+	"github.com/danielmoisa/neobank/fraud"
+	"github.com/danielmoisa/neobank/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreatePayment(ctx context.Context, req *pb.CreatePaymentRequest) (*pb.CreatePaymentResponse, error) {
+	err := server.validateFields(
+		fieldRule{"from_account_id", req.GetFromAccountId(), "required,min=1"},
+		fieldRule{"to_account_id", req.GetToAccountId(), "required,min=1"},
+		fieldRule{"amount", req.GetAmount(), "required,gt=0"},
+		fieldRule{"currency", req.GetCurrency(), "required,oneof=USD EUR CAD"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	fromAccount, err := server.validAccount(ctx, req.GetFromAccountId(), req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	access, err := db.AuthorizeAccount(ctx, server.store, fromAccount, authPayload(ctx).Username, db.AccountActionPay)
+	if err != nil {
+		return nil, accountAccessError(err)
+	}
+	err = access.CheckPayment(req.GetAmount())
+	if err == nil {
+		err = db.CheckPayerAllowance(ctx, server.store, access, fromAccount, authUser(ctx).Username, req.GetAmount())
+	}
+	switch {
+	case errors.Is(err, db.ErrPaymentAmountInvalid):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, db.ErrPaymentLimitExceeded):
+		return nil, status.Errorf(codes.PermissionDenied, "payers of the account may send up to %d a day", access.PaymentLimit)
+	case err != nil:
+		return nil, status.Error(codes.Internal, "failed to check the payment limit")
+	}
+
+	if limit := server.config.UnverifiedPaymentLimit(); authUser(ctx).EmailVerifiedAt.IsZero() && req.GetAmount() > limit {
+		return nil, status.Errorf(codes.PermissionDenied, "verify your email address to send more than %d", limit)
+	}
+
+	if err := db.CheckKYCPaymentLimit(ctx, server.store, authUser(ctx), req.GetCurrency(), req.GetAmount()); err != nil {
+		return nil, kycError(err)
+	}
+
+	toAccount, err := server.validAccount(ctx, req.GetToAccountId(), req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := server.screenPayment(ctx, authUser(ctx), toAccount.Owner); err != nil {
+		return nil, err
+	}
+
+	decision, err := server.assessPayment(ctx, fraud.Payment{
+		User:        authUser(ctx),
+		FromAccount: fromAccount,
+		ToAccount:   toAccount,
+		Amount:      req.GetAmount(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := server.store.PaymentTx(ctx, db.PaymentTxParams{
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   req.GetToAccountId(),
+		Amount:        req.GetAmount(),
+		InitiatedBy:   authUser(ctx).Username,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrStoreDraining) {
+			return nil, status.Error(codes.Unavailable, "server is shutting down")
+		}
+		return nil, status.Error(codes.Internal, "failed to create payment")
+	}
+
+	server.linkPayment(ctx, decision, result.Payment)
+	server.metrics.ObservePayment(req.GetCurrency(), req.GetAmount())
+
+	audit.Record(ctx, server.store, audit.Event{
+		Action:       audit.ActionPaymentCreate,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourcePayment,
+		ResourceID:   strconv.FormatInt(result.Payment.ID, 10),
+		Metadata: map[string]interface{}{
+			"from_account_id": req.GetFromAccountId(),
+			"to_account_id":   req.GetToAccountId(),
+			"amount":          req.GetAmount(),
+			"currency":        req.GetCurrency(),
+		},
+	})
+
+	events.PublishAll(ctx, server.broker, events.PaymentEvents(result))
+
+	return &pb.CreatePaymentResponse{
+		Payment:     convertPayment(result.Payment),
+		FromAccount: convertAccount(result.FromAccount),
+		ToAccount:   convertAccount(result.ToAccount),
+		FromEntry:   convertEntry(result.FromEntry),
+		ToEntry:     convertEntry(result.ToEntry),
+	}, nil
+}
+
+func (server *Server) validAccount(ctx context.Context, accountID int64, currency string) (db.Account, error) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return account, status.Errorf(codes.NotFound, "account [%d] not found", accountID)
+		}
+		return account, status.Error(codes.Internal, "failed to get account")
+	}
+
+	if account.Currency != currency {
+		return account, status.Errorf(codes.InvalidArgument, "account [%d] currency mismatch: %s vs %s", account.ID, account.Currency, currency)
+	}
+
+	if account.Frozen {
+		return account, status.Errorf(codes.FailedPrecondition, "account [%d] is frozen", account.ID)
+	}
+
+	if account.Type == db.AccountPocket {
+		return account, status.Errorf(codes.FailedPrecondition, "account [%d] is a pocket", account.ID)
+	}
+
+	return account, nil
+}
@@ -0,0 +1,199 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/danielmoisa/neobank/audit"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/mail"
+	"github.com/danielmoisa/neobank/pb"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/lib/pq"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const defaultEmailVerificationTTL = 24 * time.Hour
+
+func (server *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
+	err := server.validateFields(
+		fieldRule{"username", req.GetUsername(), "required,alphanum"},
+		fieldRule{"password", req.GetPassword(), "required,min=6"},
+		fieldRule{"full_name", req.GetFullName(), "required"},
+		fieldRule{"email", req.GetEmail(), "required,email"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := utils.HashPassword(req.GetPassword())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to hash password: %s", err)
+	}
+
+	user, err := server.store.CreateUser(ctx, db.CreateUserParams{
+		Username:       req.GetUsername(),
+		HashedPassword: hash,
+		FullName:       req.GetFullName(),
+		Email:          req.GetEmail(),
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			return nil, status.Error(codes.AlreadyExists, "username or email already exists")
+		}
+		return nil, status.Error(codes.Internal, "failed to create user")
+	}
+
+	audit.Record(ctx, server.store, audit.Event{
+		Actor:        user.Username,
+		Action:       audit.ActionUserCreate,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceUser,
+		ResourceID:   user.Username,
+	})
+
+	server.sendVerificationEmail(ctx, user)
+	server.screenSignUp(ctx, user)
+
+	return &pb.CreateUserResponse{User: convertUser(user)}, nil
+}
+
+// sendVerificationEmail mails a verification link to the new user. A failure
+// doesn't fail the sign up: the user can ask for another email.
+func (server *Server) sendVerificationEmail(ctx context.Context, user db.User) {
+	ttl := server.config.EmailVerifyTTL
+	if ttl <= 0 {
+		ttl = defaultEmailVerificationTTL
+	}
+
+	result, err := server.store.IssueUserTokenTx(ctx, db.IssueUserTokenParams{
+		Username: user.Username,
+		Purpose:  db.TokenEmailVerification,
+		Email:    user.Email,
+		TTL:      ttl,
+	})
+	if err == nil {
+		link := mail.Link(server.config.AppBaseURL, "/verify-email", result.Token)
+		err = server.mailer.Send(ctx, mail.VerificationEmail(user.Email, link))
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "cannot send verification email",
+			slog.String("username", user.Username),
+			slog.Any("error", err),
+		)
+	}
+}
+
+func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.LoginUserResponse, error) {
+	err := server.validateFields(
+		fieldRule{"username", req.GetUsername(), "required,alphanum"},
+		fieldRule{"password", req.GetPassword(), "required,min=6"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := server.store.GetUser(ctx, req.GetUsername())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			server.auditLoginFailure(ctx, req.GetUsername(), "unknown_user")
+			return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+		}
+		return nil, status.Error(codes.Internal, "failed to find user")
+	}
+
+	// answered like an unknown user, so the lockout doesn't tell which
+	// usernames exist
+	if user.LockedUntil.After(time.Now()) {
+		server.auditLoginFailure(ctx, req.GetUsername(), "locked")
+		return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+	}
+
+	if err := utils.CheckPassword(user.HashedPassword, req.GetPassword()); err != nil {
+		server.auditLoginFailure(ctx, req.GetUsername(), "wrong_password")
+		if err := server.recordLoginFailure(ctx, user.Username); err != nil {
+			return nil, status.Error(codes.Internal, "failed to record failed login")
+		}
+		return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+	}
+
+	if user.Disabled {
+		server.auditLoginFailure(ctx, req.GetUsername(), "disabled")
+		return nil, status.Error(codes.PermissionDenied, "user is disabled")
+	}
+
+	if user.FailedLoginAttempts > 0 || user.Lockouts > 0 {
+		if err := server.store.ResetFailedLogins(ctx, user.Username); err != nil {
+			return nil, status.Error(codes.Internal, "failed to reset failed logins")
+		}
+	}
+
+	token, err := server.tokenMaker.CreateToken(user.Username, server.config.AccessTokenDuration)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to create access token")
+	}
+
+	payload, err := server.tokenMaker.VerifyToken(token)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to create access token")
+	}
+
+	audit.Record(ctx, server.store, audit.Event{
+		Actor:        user.Username,
+		Action:       audit.ActionUserLogin,
+		Outcome:      audit.OutcomeSuccess,
+		ResourceType: audit.ResourceUser,
+		ResourceID:   user.Username,
+	})
+
+	return &pb.LoginUserResponse{
+		User:                 convertUser(user),
+		AccessToken:          token,
+		AccessTokenExpiresAt: timestamppb.New(payload.ExpiredAt),
+	}, nil
+}
+
+func (server *Server) auditLoginFailure(ctx context.Context, username string, reason string) {
+	server.metrics.IncLoginFailure(reason)
+	audit.Record(ctx, server.store, audit.Event{
+		Actor:        username,
+		Action:       audit.ActionUserLogin,
+		Outcome:      audit.OutcomeFailure,
+		ResourceType: audit.ResourceUser,
+		ResourceID:   username,
+		Metadata:     map[string]interface{}{"reason": reason},
+	})
+}
+
+// recordLoginFailure counts a wrong password towards the lockout of the user
+// and audits the lockout when this was the last attempt.
+func (server *Server) recordLoginFailure(ctx context.Context, username string) error {
+	result, err := server.store.LoginFailureTx(ctx, db.LoginFailureTxParams{
+		Username: username,
+		Policy:   server.lockoutPolicy(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if result.Locked {
+		audit.Record(ctx, server.store, audit.Event{
+			Actor:        username,
+			Action:       audit.ActionUserLock,
+			Outcome:      audit.OutcomeSuccess,
+			ResourceType: audit.ResourceUser,
+			ResourceID:   username,
+			Metadata: map[string]interface{}{
+				"locked_until": result.User.LockedUntil,
+				"lockouts":     result.User.Lockouts,
+			},
+		})
+	}
+	return nil
+}
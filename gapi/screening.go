@@ -0,0 +1,61 @@
+package gapi
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/danielmoisa/neobank/audit"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// screenSignUp screens the name of a new user. A failure doesn't fail the
+// sign up: payments screen the user again.
+func (server *Server) screenSignUp(ctx context.Context, user db.User) {
+	if _, err := server.screener.Record(ctx, server.store, db.ScreeningSourceOnboarding, user); err != nil {
+		slog.ErrorContext(ctx, "cannot screen new user",
+			slog.String("username", user.Username),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// screenPayment screens the sender and the recipient of a payment and holds
+// the payment while either has an open or confirmed hit.
+func (server *Server) screenPayment(ctx context.Context, sender db.User, recipient string) error {
+	parties := []db.User{sender}
+	if recipient != sender.Username {
+		user, err := server.store.GetUser(ctx, recipient)
+		if err != nil {
+			return status.Error(codes.Internal, "failed to get recipient")
+		}
+		parties = append(parties, user)
+	}
+
+	if _, err := server.screener.Record(ctx, server.store, db.ScreeningSourcePayment, parties...); err != nil {
+		return status.Error(codes.Internal, "failed to screen payment")
+	}
+
+	usernames := make([]string, len(parties))
+	for i, party := range parties {
+		usernames[i] = party.Username
+	}
+	blocking, err := server.store.CountBlockingScreeningHits(ctx, usernames)
+	if err != nil {
+		return status.Error(codes.Internal, "failed to screen payment")
+	}
+	if blocking > 0 {
+		audit.Record(ctx, server.store, audit.Event{
+			Action:       audit.ActionPaymentCreate,
+			Outcome:      audit.OutcomeFailure,
+			ResourceType: audit.ResourcePayment,
+			Metadata: map[string]interface{}{
+				"reason":    "screening_hold",
+				"recipient": recipient,
+			},
+		})
+		return status.Error(codes.PermissionDenied, "the payment is held for a compliance review")
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/events"
+	"github.com/danielmoisa/neobank/fraud"
+	"github.com/danielmoisa/neobank/mail"
+	"github.com/danielmoisa/neobank/metrics"
+	"github.com/danielmoisa/neobank/pb"
+	"github.com/danielmoisa/neobank/ratelimit"
+	"github.com/danielmoisa/neobank/screening"
+	"github.com/danielmoisa/neobank/tokens"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// Server serves gRPC requests for the neobank service.
+type Server struct {
+	pb.UnimplementedNeobankServer
+	store        db.Store
+	tokenMaker   tokens.Maker
+	config       utils.Config
+	broker       events.Broker
+	metrics      *metrics.Metrics
+	limiter      ratelimit.Limiter
+	mailer       mail.Mailer
+	screener     *screening.Screener
+	fraud        *fraud.Engine
+	validate     *validator.Validate
+	grpcServer   *grpc.Server
+	healthServer *health.Server
+}
+
+func NewServer(config utils.Config, store db.Store, broker events.Broker, metrics *metrics.Metrics, limiter ratelimit.Limiter, mailer mail.Mailer, screener *screening.Screener, fraud *fraud.Engine) (*Server, error) {
+	tokenMaker, err := tokens.New(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	server := &Server{
+		store:      store,
+		tokenMaker: tokenMaker,
+		config:     config,
+		broker:     broker,
+		metrics:    metrics,
+		limiter:    limiter,
+		mailer:     mailer,
+		screener:   screener,
+		fraud:      fraud,
+		validate:   validator.New(),
+	}
+
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(server.loggingInterceptor, server.authInterceptor, server.rateLimitInterceptor),
+	)
+
+	pb.RegisterNeobankServer(grpcServer, server)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(pb.Neobank_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+
+	reflection.Register(grpcServer)
+
+	server.grpcServer = grpcServer
+	server.healthServer = healthServer
+	return server, nil
+}
+
+// lockoutPolicy locks users out after LOGIN_MAX_ATTEMPTS failed logins.
+func (server *Server) lockoutPolicy() db.LockoutPolicy {
+	return db.LockoutPolicy{
+		MaxAttempts:  server.config.LoginMaxAttempts,
+		BaseDuration: server.config.LoginLockoutBase,
+		MaxDuration:  server.config.LoginLockoutMax,
+	}
+}
+
+// Start runs the gRPC server on a specific address.
+func (server *Server) Start(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("cannot create listener: %w", err)
+	}
+
+	return server.Serve(listener)
+}
+
+// Serve accepts gRPC connections on an existing listener.
+func (server *Server) Serve(listener net.Listener) error {
+	return server.grpcServer.Serve(listener)
+}
+
+// Shutdown reports NOT_SERVING to health checks, stops accepting connections
+// and waits for in-flight RPCs. When ctx is done first, the remaining RPCs are
+// cancelled.
+func (server *Server) Shutdown(ctx context.Context) error {
+	server.healthServer.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		server.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		server.grpcServer.Stop()
+		return ctx.Err()
+	}
+}
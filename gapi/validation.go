@@ -0,0 +1,27 @@
+package gapi
+
+import (
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// fieldRule checks a single request field against a validator tag, using the
+// same rules as the REST request structs.
+type fieldRule struct {
+	field string
+	value interface{}
+	tag   string
+}
+
+func (server *Server) validateFields(rules ...fieldRule) error {
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, rule := range rules {
+		if err := server.validate.Var(rule.value, rule.tag); err != nil {
+			violations = append(violations, fieldViolation(rule.field, err))
+		}
+	}
+
+	if len(violations) > 0 {
+		return invalidArgumentError(violations)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Package interest accrues interest on savings accounts day by day and pays
+// it out at the end of every period of their product.
+package interest
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/utils"
+)
+
+// Accrual is the interest of one day.
+type Accrual struct {
+	// Base is the amount earning interest, in minor units.
+	Base int64
+	// Days is how many days the day counts for under the day-count
+	// convention.
+	Days int64
+	// Micros is the interest in millionths of a minor unit.
+	Micros int64
+}
+
+// Accrue computes the interest of day on an end-of-day balance at an annual
+// rate in basis points. With daily compounding the whole minor units of the
+// interest accrued but not paid out yet earn interest too. A negative base
+// earns nothing.
+//
+// The interest is base * rate * days / basis, rounded half to even to a
+// micro, so the same inputs always accrue the same amount.
+func Accrue(day time.Time, balance, unpaidMicros int64, rateBps int32, dayCount, compounding string) (Accrual, error) {
+	days, basis, err := DayCount(dayCount, day)
+	if err != nil {
+		return Accrual{}, err
+	}
+
+	base := balance
+	switch compounding {
+	case db.CompoundingNone:
+	case db.CompoundingDaily:
+		base += unpaidMicros / db.InterestMicros
+	default:
+		return Accrual{}, fmt.Errorf("unknown compounding %q", compounding)
+	}
+	if base < 0 {
+		base = 0
+	}
+
+	// base * rate/10000 * days/basis * 1000000
+	numerator := new(big.Int).SetInt64(base)
+	numerator.Mul(numerator, big.NewInt(int64(rateBps)))
+	numerator.Mul(numerator, big.NewInt(days))
+	numerator.Mul(numerator, big.NewInt(db.InterestMicros/10_000))
+
+	return Accrual{
+		Base:   base,
+		Days:   days,
+		Micros: utils.DivRoundHalfEven(numerator, big.NewInt(basis)).Int64(),
+	}, nil
+}
+
+// PeriodEnd returns the last payout date on or before today: the first day
+// of the month, of the quarter or of the year. Interest accrued before it is
+// due.
+func PeriodEnd(frequency string, today time.Time) (time.Time, error) {
+	year, month, _ := today.Date()
+	switch frequency {
+	case db.PayoutMonthly:
+	case db.PayoutQuarterly:
+		month -= (month - 1) % 3
+	case db.PayoutAnnually:
+		month = time.January
+	default:
+		return time.Time{}, fmt.Errorf("unknown payout frequency %q", frequency)
+	}
+	return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC), nil
+}
+
+// Date returns the UTC day of t, at midnight.
+func Date(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,195 @@
+package interest
+
+import (
+	"testing"
+	"time"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccrue(t *testing.T) {
+	testCases := []struct {
+		name         string
+		day          time.Time
+		balance      int64
+		unpaidMicros int64
+		rateBps      int32
+		dayCount     string
+		compounding  string
+		accrual      Accrual
+	}{
+		{
+			// 1_000_000 * 200 * 100 / 365 = 54_794_520.55
+			name:        "ACT/365 rounds up",
+			day:         day(2024, time.March, 5),
+			balance:     1_000_000,
+			rateBps:     200,
+			dayCount:    db.DayCountACT365,
+			compounding: db.CompoundingNone,
+			accrual:     Accrual{Base: 1_000_000, Days: 1, Micros: 54_794_521},
+		},
+		{
+			// 1_000 * 150 * 100 / 365 = 41_095.89
+			name:        "ACT/365 small balance",
+			day:         day(2024, time.March, 5),
+			balance:     1_000,
+			rateBps:     150,
+			dayCount:    db.DayCountACT365,
+			compounding: db.CompoundingNone,
+			accrual:     Accrual{Base: 1_000, Days: 1, Micros: 41_096},
+		},
+		{
+			// 1_001 * 1 * 100 / 365 = 274.25
+			name:        "ACT/365 rounds down",
+			day:         day(2024, time.March, 5),
+			balance:     1_001,
+			rateBps:     1,
+			dayCount:    db.DayCountACT365,
+			compounding: db.CompoundingNone,
+			accrual:     Accrual{Base: 1_001, Days: 1, Micros: 274},
+		},
+		{
+			// 9 * 1 * 100 / 360 = 2.5
+			name:        "half rounds to even down",
+			day:         day(2024, time.March, 5),
+			balance:     9,
+			rateBps:     1,
+			dayCount:    db.DayCount30360,
+			compounding: db.CompoundingNone,
+			accrual:     Accrual{Base: 9, Days: 1, Micros: 2},
+		},
+		{
+			// 27 * 1 * 100 / 360 = 7.5
+			name:        "half rounds to even up",
+			day:         day(2024, time.March, 5),
+			balance:     27,
+			rateBps:     1,
+			dayCount:    db.DayCount30360,
+			compounding: db.CompoundingNone,
+			accrual:     Accrual{Base: 27, Days: 1, Micros: 8},
+		},
+		{
+			// 3 days: 1_000_000 * 360 * 3 * 100 / 360 = 300_000_000
+			name:        "30/360 end of February",
+			day:         day(2023, time.February, 28),
+			balance:     1_000_000,
+			rateBps:     360,
+			dayCount:    db.DayCount30360,
+			compounding: db.CompoundingNone,
+			accrual:     Accrual{Base: 1_000_000, Days: 3, Micros: 300_000_000},
+		},
+		{
+			name:        "30/360 the 31st earns nothing",
+			day:         day(2024, time.March, 30),
+			balance:     1_000_000,
+			rateBps:     360,
+			dayCount:    db.DayCount30360,
+			compounding: db.CompoundingNone,
+			accrual:     Accrual{Base: 1_000_000, Days: 0, Micros: 0},
+		},
+		{
+			name:         "no compounding ignores unpaid interest",
+			day:          day(2024, time.March, 5),
+			balance:      1_000_000,
+			unpaidMicros: 5_500_000,
+			rateBps:      360,
+			dayCount:     db.DayCount30360,
+			compounding:  db.CompoundingNone,
+			accrual:      Accrual{Base: 1_000_000, Days: 1, Micros: 100_000_000},
+		},
+		{
+			// whole minor units of the unpaid interest only
+			name:         "daily compounding",
+			day:          day(2024, time.March, 5),
+			balance:      1_000_000,
+			unpaidMicros: 5_500_000,
+			rateBps:      360,
+			dayCount:     db.DayCount30360,
+			compounding:  db.CompoundingDaily,
+			accrual:      Accrual{Base: 1_000_005, Days: 1, Micros: 100_000_500},
+		},
+		{
+			name:        "negative balance",
+			day:         day(2024, time.March, 5),
+			balance:     -5_000,
+			rateBps:     200,
+			dayCount:    db.DayCountACT365,
+			compounding: db.CompoundingNone,
+			accrual:     Accrual{Base: 0, Days: 1, Micros: 0},
+		},
+		{
+			name:        "zero rate",
+			day:         day(2024, time.March, 5),
+			balance:     1_000_000,
+			dayCount:    db.DayCountACT365,
+			compounding: db.CompoundingNone,
+			accrual:     Accrual{Base: 1_000_000, Days: 1, Micros: 0},
+		},
+		{
+			// beyond int64 before the division
+			name:        "large balance",
+			day:         day(2024, time.March, 5),
+			balance:     90_000_000_000_000,
+			rateBps:     10_000,
+			dayCount:    db.DayCountACT365,
+			compounding: db.CompoundingNone,
+			accrual:     Accrual{Base: 90_000_000_000_000, Days: 1, Micros: 246_575_342_465_753_425},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			accrual, err := Accrue(tc.day, tc.balance, tc.unpaidMicros, tc.rateBps, tc.dayCount, tc.compounding)
+			require.NoError(t, err)
+			require.Equal(t, tc.accrual, accrual)
+		})
+	}
+}
+
+func TestAccrueYear(t *testing.T) {
+	// a year of accruals adds up to the annual rate, give or take rounding
+	for _, dayCount := range []string{db.DayCountACT365, db.DayCount30360} {
+		total := int64(0)
+		for d := day(2023, time.January, 1); d.Year() == 2023; d = d.AddDate(0, 0, 1) {
+			accrual, err := Accrue(d, 1_000_000, 0, 250, dayCount, db.CompoundingNone)
+			require.NoError(t, err)
+			total += accrual.Micros
+		}
+		require.InDelta(t, 25_000*db.InterestMicros, total, 365, dayCount)
+	}
+}
+
+func TestAccrueInvalid(t *testing.T) {
+	_, err := Accrue(day(2024, time.March, 5), 1_000, 0, 100, "act/360", db.CompoundingNone)
+	require.EqualError(t, err, `unknown day count "act/360"`)
+
+	_, err = Accrue(day(2024, time.March, 5), 1_000, 0, 100, db.DayCountACT365, "monthly")
+	require.EqualError(t, err, `unknown compounding "monthly"`)
+}
+
+func TestPeriodEnd(t *testing.T) {
+	testCases := []struct {
+		frequency string
+		today     time.Time
+		periodEnd time.Time
+	}{
+		{db.PayoutMonthly, day(2024, time.March, 1), day(2024, time.March, 1)},
+		{db.PayoutMonthly, day(2024, time.March, 31), day(2024, time.March, 1)},
+		{db.PayoutQuarterly, day(2024, time.January, 15), day(2024, time.January, 1)},
+		{db.PayoutQuarterly, day(2024, time.March, 31), day(2024, time.January, 1)},
+		{db.PayoutQuarterly, day(2024, time.April, 1), day(2024, time.April, 1)},
+		{db.PayoutQuarterly, day(2024, time.August, 20), day(2024, time.July, 1)},
+		{db.PayoutQuarterly, day(2024, time.December, 31), day(2024, time.October, 1)},
+		{db.PayoutAnnually, day(2024, time.December, 31), day(2024, time.January, 1)},
+	}
+
+	for _, tc := range testCases {
+		periodEnd, err := PeriodEnd(tc.frequency, tc.today)
+		require.NoError(t, err)
+		require.Equal(t, tc.periodEnd, periodEnd, "%s %s", tc.frequency, tc.today)
+	}
+
+	_, err := PeriodEnd("weekly", day(2024, time.March, 1))
+	require.EqualError(t, err, `unknown payout frequency "weekly"`)
+}
@@ -0,0 +1,39 @@
+package interest
+
+import (
+	"fmt"
+	"time"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+)
+
+// DayCount returns how many days accruing from day to the next counts for,
+// and the days of a year, under a day-count convention.
+//
+// ACT/365 Fixed counts every calendar day once over a 365-day year, leap
+// years included. 30/360 (bond basis) counts every month as 30 days over a
+// 360-day year: the 31st counts for nothing and the last day of February
+// for the days up to the 30th.
+func DayCount(dayCount string, day time.Time) (days, basis int64, err error) {
+	switch dayCount {
+	case db.DayCountACT365:
+		return 1, 365, nil
+	case db.DayCount30360:
+		return days30360(day, day.AddDate(0, 0, 1)), 360, nil
+	default:
+		return 0, 0, fmt.Errorf("unknown day count %q", dayCount)
+	}
+}
+
+// days30360 counts the days from start to end under the 30/360 bond basis.
+func days30360(start, end time.Time) int64 {
+	y1, m1, d1 := start.Date()
+	y2, m2, d2 := end.Date()
+	if d1 == 31 {
+		d1 = 30
+	}
+	if d2 == 31 && d1 == 30 {
+		d2 = 30
+	}
+	return int64(360*(y2-y1) + 30*(int(m2)-int(m1)) + (d2 - d1))
+}
@@ -0,0 +1,59 @@
+package interest
+
+import (
+	"testing"
+	"time"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestDayCount(t *testing.T) {
+	testCases := []struct {
+		name     string
+		dayCount string
+		day      time.Time
+		days     int64
+		basis    int64
+	}{
+		{"ACT/365", db.DayCountACT365, day(2024, time.March, 5), 1, 365},
+		{"ACT/365 leap day", db.DayCountACT365, day(2024, time.February, 29), 1, 365},
+		{"30/360", db.DayCount30360, day(2024, time.March, 5), 1, 360},
+		{"30/360 the 30th", db.DayCount30360, day(2024, time.January, 30), 0, 360},
+		{"30/360 the 31st", db.DayCount30360, day(2024, time.January, 31), 1, 360},
+		{"30/360 end of February", db.DayCount30360, day(2023, time.February, 28), 3, 360},
+		{"30/360 end of leap February", db.DayCount30360, day(2024, time.February, 29), 2, 360},
+		{"30/360 end of year", db.DayCount30360, day(2023, time.December, 31), 1, 360},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			days, basis, err := DayCount(tc.dayCount, tc.day)
+			require.NoError(t, err)
+			require.Equal(t, tc.days, days)
+			require.Equal(t, tc.basis, basis)
+		})
+	}
+
+	_, _, err := DayCount("act/360", day(2024, time.March, 5))
+	require.EqualError(t, err, `unknown day count "act/360"`)
+}
+
+func TestDayCount30360Months(t *testing.T) {
+	// every month counts for 30 days, whatever its length
+	for month := time.January; month <= time.December; month++ {
+		for _, year := range []int{2023, 2024} {
+			total := int64(0)
+			for d := day(year, month, 1); d.Month() == month; d = d.AddDate(0, 0, 1) {
+				days, _, err := DayCount(db.DayCount30360, d)
+				require.NoError(t, err)
+				total += days
+			}
+			require.Equal(t, int64(30), total, "%s %d", month, year)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package interest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/events"
+)
+
+// batchSize is how many savings accounts a run loads at a time.
+const batchSize = 100
+
+// Result counts what a run did.
+type Result struct {
+	Accounts int `json:"accounts"`
+	Accruals int `json:"accruals"`
+	Payouts  int `json:"payouts"`
+}
+
+// Job accrues and pays out the interest of every savings account. Payouts
+// are published to the subscribers of the account.
+type Job struct {
+	store  db.Store
+	broker events.Broker
+}
+
+func NewJob(store db.Store, broker events.Broker) *Job {
+	return &Job{store: store, broker: broker}
+}
+
+// Run accrues the interest of every day before today not accrued yet, on
+// the end-of-day balance, and pays out what the last period ending on or
+// before today accrued. Runs pick up where the last one stopped, so they can
+// be repeated and catch up after downtime. A failing account doesn't stop
+// the others; its error is returned with the result.
+func (job *Job) Run(ctx context.Context, today time.Time) (Result, error) {
+	today = Date(today)
+
+	var result Result
+	var errs []error
+	afterID := int64(0)
+	for {
+		accounts, err := job.store.ListSavingsAccounts(ctx, db.ListSavingsAccountsParams{
+			AfterID: afterID,
+			Limit:   batchSize,
+		})
+		if err != nil {
+			return result, errors.Join(append(errs, err)...)
+		}
+
+		for _, account := range accounts {
+			result.Accounts++
+			accruals, err := job.accrue(ctx, account, today)
+			result.Accruals += accruals
+			if err != nil {
+				errs = append(errs, fmt.Errorf("account %d: %w", account.AccountID, err))
+				continue
+			}
+
+			paid, err := job.pay(ctx, account, today)
+			if paid {
+				result.Payouts++
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("account %d: %w", account.AccountID, err))
+			}
+		}
+
+		if len(accounts) < batchSize {
+			return result, errors.Join(errs...)
+		}
+		afterID = accounts[len(accounts)-1].AccountID
+	}
+}
+
+// accrue records an accrual for every day from the one after the last
+// accrual, or the day the account was opened, until yesterday.
+func (job *Job) accrue(ctx context.Context, account db.ListSavingsAccountsRow, today time.Time) (int, error) {
+	day := Date(account.CreatedAt)
+	last, err := job.store.GetLastInterestAccrual(ctx, account.AccountID)
+	switch {
+	case err == nil:
+		day = Date(last.AccrualDate).AddDate(0, 0, 1)
+	case !errors.Is(err, sql.ErrNoRows):
+		return 0, err
+	}
+	if !day.Before(today) {
+		return 0, nil
+	}
+
+	// accruals are summed as they are made rather than read back every day
+	unpaidMicros := int64(0)
+	if account.Compounding == db.CompoundingDaily {
+		unpaid, err := job.store.GetUnpaidInterest(ctx, db.GetUnpaidInterestParams{
+			Before:    day,
+			AccountID: account.AccountID,
+		})
+		if err != nil {
+			return 0, err
+		}
+		unpaidMicros = unpaid.AmountMicros
+	}
+
+	accruals := 0
+	for ; day.Before(today); day = day.AddDate(0, 0, 1) {
+		balance, err := job.store.GetEndOfDayBalance(ctx, db.GetEndOfDayBalanceParams{
+			At:        day.AddDate(0, 0, 1),
+			AccountID: account.AccountID,
+		})
+		if err != nil {
+			return accruals, err
+		}
+
+		accrual, err := Accrue(day, balance, unpaidMicros, account.RateBps, account.DayCount, account.Compounding)
+		if err != nil {
+			return accruals, err
+		}
+
+		_, err = job.store.CreateInterestAccrual(ctx, db.CreateInterestAccrualParams{
+			AccountID:    account.AccountID,
+			AccrualDate:  day,
+			Balance:      balance,
+			Base:         accrual.Base,
+			RateBps:      account.RateBps,
+			DayCount:     account.DayCount,
+			Days:         int32(accrual.Days),
+			AmountMicros: accrual.Micros,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			// another run is accruing this account
+			return accruals, nil
+		}
+		if err != nil {
+			return accruals, err
+		}
+
+		accruals++
+		unpaidMicros += accrual.Micros
+	}
+	return accruals, nil
+}
+
+// pay pays out the interest of the last period that has ended, unless it
+// was paid already.
+func (job *Job) pay(ctx context.Context, account db.ListSavingsAccountsRow, today time.Time) (bool, error) {
+	periodEnd, err := PeriodEnd(account.PayoutFrequency, today)
+	if err != nil {
+		return false, err
+	}
+
+	last, err := job.store.GetLastInterestPayout(ctx, account.AccountID)
+	switch {
+	case err == nil:
+		if !Date(last.PeriodEnd).Before(periodEnd) {
+			return false, nil
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		return false, err
+	}
+
+	paid, err := job.store.PayInterestTx(ctx, db.PayInterestTxParams{
+		AccountID: account.AccountID,
+		PeriodEnd: periodEnd,
+	})
+	if errors.Is(err, db.ErrNoInterestDue) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// a payout of less than a minor unit books no entry
+	if paid.Payout.EntryID.Valid {
+		events.PublishAll(ctx, job.broker, events.AccountEvents(paid.Account, paid.Entry))
+	}
+	return true, nil
+}
+
+// Start runs the job right away and then every interval, until ctx is done.
+// Failures are logged and retried at the next tick.
+func (job *Job) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := job.Run(ctx, time.Now())
+		slog.InfoContext(ctx, "ran interest job",
+			slog.Int("accounts", result.Accounts),
+			slog.Int("accruals", result.Accruals),
+			slog.Int("payouts", result.Payouts),
+		)
+		if err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "interest job failed", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package interest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/events"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestJobRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	job := NewJob(store, events.NewMemoryBroker())
+
+	// opened the day before the end of the month, paid monthly
+	opened := db.ListSavingsAccountsRow{
+		AccountID:       1,
+		Currency:        "EUR",
+		CreatedAt:       time.Date(2024, time.February, 28, 15, 30, 0, 0, time.UTC),
+		ProductID:       1,
+		RateBps:         200,
+		DayCount:        db.DayCountACT365,
+		Compounding:     db.CompoundingNone,
+		PayoutFrequency: db.PayoutMonthly,
+	}
+	// accrued until yesterday and paid this quarter, with daily compounding
+	current := db.ListSavingsAccountsRow{
+		AccountID:       2,
+		Currency:        "EUR",
+		CreatedAt:       time.Date(2023, time.June, 1, 9, 0, 0, 0, time.UTC),
+		ProductID:       2,
+		RateBps:         360,
+		DayCount:        db.DayCount30360,
+		Compounding:     db.CompoundingDaily,
+		PayoutFrequency: db.PayoutQuarterly,
+	}
+	// failing accounts don't stop the others
+	failing := db.ListSavingsAccountsRow{
+		AccountID:       3,
+		CreatedAt:       time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+		DayCount:        db.DayCountACT365,
+		Compounding:     db.CompoundingNone,
+		PayoutFrequency: db.PayoutMonthly,
+	}
+
+	store.EXPECT().
+		ListSavingsAccounts(gomock.Any(), gomock.Eq(db.ListSavingsAccountsParams{AfterID: 0, Limit: batchSize})).
+		Times(1).
+		Return([]db.ListSavingsAccountsRow{opened, current, failing}, nil)
+
+	// opened: Feb 28 until Mar 1 accrue, then February is paid
+	store.EXPECT().
+		GetLastInterestAccrual(gomock.Any(), gomock.Eq(int64(1))).
+		Times(1).
+		Return(db.InterestAccrual{}, sql.ErrNoRows)
+	for _, d := range []time.Time{day(2024, time.February, 28), day(2024, time.February, 29), day(2024, time.March, 1)} {
+		store.EXPECT().
+			GetEndOfDayBalance(gomock.Any(), gomock.Eq(db.GetEndOfDayBalanceParams{At: d.AddDate(0, 0, 1), AccountID: 1})).
+			Times(1).
+			Return(int64(1_000_000), nil)
+		store.EXPECT().
+			CreateInterestAccrual(gomock.Any(), gomock.Eq(db.CreateInterestAccrualParams{
+				AccountID:    1,
+				AccrualDate:  d,
+				Balance:      1_000_000,
+				Base:         1_000_000,
+				RateBps:      200,
+				DayCount:     db.DayCountACT365,
+				Days:         1,
+				AmountMicros: 54_794_521,
+			})).
+			Times(1).
+			Return(db.InterestAccrual{ID: 1}, nil)
+	}
+	store.EXPECT().
+		GetLastInterestPayout(gomock.Any(), gomock.Eq(int64(1))).
+		Times(1).
+		Return(db.InterestPayout{}, sql.ErrNoRows)
+	store.EXPECT().
+		PayInterestTx(gomock.Any(), gomock.Eq(db.PayInterestTxParams{AccountID: 1, PeriodEnd: day(2024, time.March, 1)})).
+		Times(1).
+		Return(db.PayInterestTxResult{Payout: db.InterestPayout{ID: 1, Amount: 109}}, nil)
+
+	// current: yesterday accrues on the balance and the unpaid interest
+	store.EXPECT().
+		GetLastInterestAccrual(gomock.Any(), gomock.Eq(int64(2))).
+		Times(1).
+		Return(db.InterestAccrual{AccountID: 2, AccrualDate: day(2024, time.February, 29)}, nil)
+	store.EXPECT().
+		GetUnpaidInterest(gomock.Any(), gomock.Eq(db.GetUnpaidInterestParams{Before: day(2024, time.March, 1), AccountID: 2})).
+		Times(1).
+		Return(db.GetUnpaidInterestRow{Accruals: 60, AmountMicros: 6_000_000_000}, nil)
+	store.EXPECT().
+		GetEndOfDayBalance(gomock.Any(), gomock.Eq(db.GetEndOfDayBalanceParams{At: day(2024, time.March, 2), AccountID: 2})).
+		Times(1).
+		Return(int64(1_000_000), nil)
+	store.EXPECT().
+		CreateInterestAccrual(gomock.Any(), gomock.Eq(db.CreateInterestAccrualParams{
+			AccountID:    2,
+			AccrualDate:  day(2024, time.March, 1),
+			Balance:      1_000_000,
+			Base:         1_006_000,
+			RateBps:      360,
+			DayCount:     db.DayCount30360,
+			Days:         1,
+			AmountMicros: 100_600_000,
+		})).
+		Times(1).
+		Return(db.InterestAccrual{ID: 3}, nil)
+	store.EXPECT().
+		GetLastInterestPayout(gomock.Any(), gomock.Eq(int64(2))).
+		Times(1).
+		Return(db.InterestPayout{PeriodEnd: day(2024, time.January, 1)}, nil)
+
+	// failing
+	store.EXPECT().
+		GetLastInterestAccrual(gomock.Any(), gomock.Eq(int64(3))).
+		Times(1).
+		Return(db.InterestAccrual{AccountID: 3, AccrualDate: day(2024, time.February, 29)}, nil)
+	store.EXPECT().
+		GetEndOfDayBalance(gomock.Any(), gomock.Eq(db.GetEndOfDayBalanceParams{At: day(2024, time.March, 2), AccountID: 3})).
+		Times(1).
+		Return(int64(0), errors.New("connection reset"))
+
+	result, err := job.Run(context.Background(), time.Date(2024, time.March, 2, 10, 0, 0, 0, time.UTC))
+	require.EqualError(t, err, "account 3: connection reset")
+	require.Equal(t, Result{Accounts: 3, Accruals: 4, Payouts: 1}, result)
+}
+
+func TestJobRunPages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	job := NewJob(store, events.NewMemoryBroker())
+	today := day(2024, time.March, 2)
+
+	// accounts accrued until yesterday, with nothing due
+	page := make([]db.ListSavingsAccountsRow, batchSize)
+	for i := range page {
+		page[i] = db.ListSavingsAccountsRow{
+			AccountID:       int64(i + 1),
+			DayCount:        db.DayCountACT365,
+			Compounding:     db.CompoundingNone,
+			PayoutFrequency: db.PayoutAnnually,
+		}
+	}
+	store.EXPECT().
+		ListSavingsAccounts(gomock.Any(), gomock.Eq(db.ListSavingsAccountsParams{AfterID: 0, Limit: batchSize})).
+		Times(1).
+		Return(page, nil)
+	store.EXPECT().
+		ListSavingsAccounts(gomock.Any(), gomock.Eq(db.ListSavingsAccountsParams{AfterID: batchSize, Limit: batchSize})).
+		Times(1).
+		Return(page[:1], nil)
+	store.EXPECT().
+		GetLastInterestAccrual(gomock.Any(), gomock.Any()).
+		Times(batchSize+1).
+		Return(db.InterestAccrual{AccrualDate: day(2024, time.March, 1)}, nil)
+	store.EXPECT().
+		GetLastInterestPayout(gomock.Any(), gomock.Any()).
+		Times(batchSize).
+		Return(db.InterestPayout{PeriodEnd: day(2024, time.January, 1)}, nil)
+	// the payout was made by another run in the meantime
+	store.EXPECT().
+		GetLastInterestPayout(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.InterestPayout{}, sql.ErrNoRows)
+	store.EXPECT().
+		PayInterestTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.PayInterestTxResult{}, db.ErrNoInterestDue)
+
+	result, err := job.Run(context.Background(), today)
+	require.NoError(t, err)
+	require.Equal(t, Result{Accounts: batchSize + 1}, result)
+}
+
+func TestJobStart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	job := NewJob(store, events.NewMemoryBroker())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	store.EXPECT().
+		ListSavingsAccounts(gomock.Any(), gomock.Any()).
+		MinTimes(2).
+		DoAndReturn(func(context.Context, db.ListSavingsAccountsParams) ([]db.ListSavingsAccountsRow, error) {
+			calls++
+			if calls == 2 {
+				cancel()
+			}
+			return nil, nil
+		})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		job.Start(ctx, time.Millisecond)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job did not stop")
+	}
+}
@@ -0,0 +1,115 @@
+package loans
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/events"
+)
+
+// batchSize is how many due installments a run loads at a time.
+const batchSize = 100
+
+// Result counts what a run did.
+type Result struct {
+	Installments int   `json:"installments"`
+	Paid         int   `json:"paid"`
+	Overdue      int   `json:"overdue"`
+	Collected    int64 `json:"collected"`
+}
+
+// Job collects the loan installments that fall due from the borrowers'
+// accounts. Collections are published to the subscribers of the account.
+type Job struct {
+	store  db.Store
+	broker events.Broker
+}
+
+func NewJob(store db.Store, broker events.Broker) *Job {
+	return &Job{store: store, broker: broker}
+}
+
+// Run collects every installment due on or before today that isn't paid
+// yet, overdue ones included, so runs can be repeated and catch up after
+// downtime. A failing installment doesn't stop the others; its error is
+// returned with the result.
+func (job *Job) Run(ctx context.Context, today time.Time) (Result, error) {
+	year, month, day := today.UTC().Date()
+	today = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	var result Result
+	var errs []error
+	afterID := int64(0)
+	for {
+		installments, err := job.store.ListDueLoanInstallments(ctx, db.ListDueLoanInstallmentsParams{
+			DueOn:   today,
+			AfterID: afterID,
+			Limit:   batchSize,
+		})
+		if err != nil {
+			return result, errors.Join(append(errs, err)...)
+		}
+
+		for _, installment := range installments {
+			result.Installments++
+			collected, err := job.store.CollectInstallmentTx(ctx, db.CollectInstallmentTxParams{
+				InstallmentID: installment.ID,
+				Today:         today,
+			})
+			if errors.Is(err, db.ErrInstallmentSettled) {
+				// an early repayment or another run got there first
+				continue
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("installment %d: %w", installment.ID, err))
+				continue
+			}
+
+			result.Collected += collected.Repayment.Amount
+			if collected.Repayment.ID != 0 {
+				events.PublishAll(ctx, job.broker, events.AccountEvents(collected.Account, collected.Entry))
+			}
+			switch {
+			case collected.Installment.Status == db.InstallmentPaid:
+				result.Paid++
+			case collected.Installment.Status == db.InstallmentOverdue && installment.Status != db.InstallmentOverdue:
+				result.Overdue++
+			}
+		}
+
+		if len(installments) < batchSize {
+			return result, errors.Join(errs...)
+		}
+		afterID = installments[len(installments)-1].ID
+	}
+}
+
+// Start runs the job right away and then every interval, until ctx is done.
+// Failures are logged and retried at the next tick.
+func (job *Job) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := job.Run(ctx, time.Now())
+		slog.InfoContext(ctx, "ran loan collection job",
+			slog.Int("installments", result.Installments),
+			slog.Int("paid", result.Paid),
+			slog.Int("overdue", result.Overdue),
+			slog.Int64("collected", result.Collected),
+		)
+		if err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "loan collection job failed", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
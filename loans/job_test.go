@@ -0,0 +1,119 @@
+package loans
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/events"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestJobRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	broker := events.NewMemoryBroker()
+	job := NewJob(store, broker)
+	balances, unsubscribe := broker.Subscribe(7)
+	defer unsubscribe()
+
+	today := date(2024, time.March, 15)
+	paid := db.LoanInstallment{ID: 1, LoanID: 1, Period: 2, DueDate: today, Principal: 80_000, Interest: 9_000, Status: db.InstallmentScheduled}
+	late := db.LoanInstallment{ID: 2, LoanID: 2, Period: 1, DueDate: date(2024, time.March, 1), Principal: 40_000, Interest: 2_000, Status: db.InstallmentScheduled}
+	overdue := db.LoanInstallment{ID: 3, LoanID: 3, Period: 4, DueDate: date(2024, time.January, 10), Principal: 10_000, Interest: 500, Fee: 1_500, Status: db.InstallmentOverdue}
+	settled := db.LoanInstallment{ID: 4, LoanID: 4, Period: 1, DueDate: today, Status: db.InstallmentScheduled}
+	failing := db.LoanInstallment{ID: 5, LoanID: 5, Period: 1, DueDate: today, Status: db.InstallmentScheduled}
+
+	store.EXPECT().
+		ListDueLoanInstallments(gomock.Any(), gomock.Eq(db.ListDueLoanInstallmentsParams{DueOn: today, AfterID: 0, Limit: batchSize})).
+		Times(1).
+		Return([]db.LoanInstallment{paid, late, overdue, settled, failing}, nil)
+
+	expectCollect := func(installment db.LoanInstallment, result db.CollectInstallmentTxResult, err error) {
+		store.EXPECT().
+			CollectInstallmentTx(gomock.Any(), gomock.Eq(db.CollectInstallmentTxParams{InstallmentID: installment.ID, Today: today})).
+			Times(1).
+			Return(result, err)
+	}
+
+	paid.Status = db.InstallmentPaid
+	expectCollect(paid, db.CollectInstallmentTxResult{
+		Installment: paid,
+		Repayment:   db.LoanRepayment{ID: 1, Amount: 89_000},
+		Account:     db.Account{ID: 7, Balance: 11_000},
+		Entry:       db.Entry{ID: 1, AccountID: 7, Amount: -89_000},
+	}, nil)
+	// part of it collected, then charged the late fee
+	late.Status = db.InstallmentOverdue
+	expectCollect(late, db.CollectInstallmentTxResult{Installment: late, Repayment: db.LoanRepayment{Amount: 30_000}, FeeCharged: true}, nil)
+	// still overdue, nothing collected
+	expectCollect(overdue, db.CollectInstallmentTxResult{Installment: overdue}, nil)
+	expectCollect(settled, db.CollectInstallmentTxResult{}, db.ErrInstallmentSettled)
+	expectCollect(failing, db.CollectInstallmentTxResult{}, errors.New("boom"))
+
+	result, err := job.Run(context.Background(), today.Add(13*time.Hour))
+	require.ErrorContains(t, err, "installment 5: boom")
+	require.Equal(t, Result{Installments: 5, Paid: 1, Overdue: 1, Collected: 119_000}, result)
+
+	// the borrower of the paid installment sees the collection
+	require.Equal(t, events.EventTypeEntry, (<-balances).Type)
+	balance := <-balances
+	require.Equal(t, events.EventTypeBalance, balance.Type)
+	require.Equal(t, int64(11_000), balance.Account.Balance)
+}
+
+func TestJobRunPages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	job := NewJob(store, events.NewMemoryBroker())
+
+	today := date(2024, time.March, 15)
+	page := make([]db.LoanInstallment, batchSize)
+	for i := range page {
+		page[i] = db.LoanInstallment{ID: int64(i + 1), DueDate: today, Status: db.InstallmentScheduled}
+	}
+
+	store.EXPECT().
+		ListDueLoanInstallments(gomock.Any(), gomock.Eq(db.ListDueLoanInstallmentsParams{DueOn: today, AfterID: 0, Limit: batchSize})).
+		Times(1).
+		Return(page, nil)
+	store.EXPECT().
+		ListDueLoanInstallments(gomock.Any(), gomock.Eq(db.ListDueLoanInstallmentsParams{DueOn: today, AfterID: batchSize, Limit: batchSize})).
+		Times(1).
+		Return([]db.LoanInstallment{}, nil)
+	store.EXPECT().
+		CollectInstallmentTx(gomock.Any(), gomock.Any()).
+		Times(batchSize).
+		Return(db.CollectInstallmentTxResult{Installment: db.LoanInstallment{Status: db.InstallmentPaid}, Repayment: db.LoanRepayment{Amount: 10}}, nil)
+
+	result, err := job.Run(context.Background(), today)
+	require.NoError(t, err)
+	require.Equal(t, Result{Installments: batchSize, Paid: batchSize, Collected: 10 * batchSize}, result)
+}
+
+func TestJobRunListFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	job := NewJob(store, events.NewMemoryBroker())
+
+	store.EXPECT().
+		ListDueLoanInstallments(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(nil, errors.New("boom"))
+	store.EXPECT().
+		CollectInstallmentTx(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	_, err := job.Run(context.Background(), time.Now())
+	require.ErrorContains(t, err, "boom")
+}
@@ -0,0 +1,140 @@
+// Package loans amortizes consumer loans into monthly installments and
+// collects the installments that fall due from the borrowers' accounts.
+package loans
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/utils"
+)
+
+// MaxTerm is the longest term, in months, a schedule is built for.
+const MaxTerm = 600
+
+// monthsPerYear turns the annual rate in basis points into the rate of a
+// month: every month is a twelfth of the year, whatever its length.
+const monthsPerYear = 12 * 10_000
+
+var (
+	ErrInvalidPrincipal = errors.New("principal must be positive")
+	ErrInvalidTerm      = fmt.Errorf("term must be between 1 and %d months", MaxTerm)
+	ErrInvalidRate      = errors.New("rate must not be negative")
+)
+
+// Schedule amortizes principal at an annual rate over count monthly
+// installments, the first of which is due in period first: period n falls n
+// months after disbursedOn, on the same day or the last day of shorter
+// months.
+//
+// Every installment pays the interest of the month on the principal still
+// outstanding, rounded half to even to a minor unit. Annuity installments
+// are equal, the principal making up what the interest doesn't; linear
+// installments repay the same principal every month, the remainder of the
+// division going to the first ones. Either way the last installment repays
+// whatever principal is left, so the principal of the schedule always adds
+// up to the principal of the loan.
+func Schedule(method string, principal int64, rateBps int32, disbursedOn time.Time, first, count int32) ([]db.ScheduledInstallment, error) {
+	if principal <= 0 {
+		return nil, ErrInvalidPrincipal
+	}
+	if count <= 0 || count > MaxTerm {
+		return nil, ErrInvalidTerm
+	}
+	if rateBps < 0 {
+		return nil, ErrInvalidRate
+	}
+
+	var principalOf func(k int32, interest int64) int64
+	switch method {
+	case db.LoanAnnuity:
+		payment := AnnuityPayment(principal, rateBps, count)
+		principalOf = func(k int32, interest int64) int64 {
+			return payment - interest
+		}
+	case db.LoanLinear:
+		share, remainder := principal/int64(count), principal%int64(count)
+		principalOf = func(k int32, interest int64) int64 {
+			if int64(k) < remainder {
+				return share + 1
+			}
+			return share
+		}
+	default:
+		return nil, fmt.Errorf("unknown amortization method %q", method)
+	}
+
+	installments := make([]db.ScheduledInstallment, count)
+	balance := principal
+	for k := int32(0); k < count; k++ {
+		interest := MonthlyInterest(balance, rateBps)
+		repaid := principalOf(k, interest)
+		if k == count-1 || repaid > balance {
+			repaid = balance
+		}
+		if repaid < 0 {
+			repaid = 0
+		}
+
+		installments[k] = db.ScheduledInstallment{
+			Period:    first + k,
+			DueDate:   DueDate(disbursedOn, first+k),
+			Principal: repaid,
+			Interest:  interest,
+		}
+		balance -= repaid
+	}
+	return installments, nil
+}
+
+// LoanSchedule amortizes what is left of a loan over count installments
+// from period first, with the terms of the loan. It is the db.LoanScheduler
+// of originations and early repayments.
+func LoanSchedule(loan db.Loan, first, count int32) ([]db.ScheduledInstallment, error) {
+	return Schedule(loan.Method, loan.OutstandingPrincipal, loan.RateBps, loan.DisbursedOn, first, count)
+}
+
+// AnnuityPayment is the installment that repays principal with its interest
+// in count equal monthly installments:
+//
+//	principal * r / (1 - (1 + r)^-count)
+//
+// where r is the monthly rate, computed exactly and rounded half to even to
+// a minor unit. Without interest it is the principal divided by count.
+func AnnuityPayment(principal int64, rateBps int32, count int32) int64 {
+	if rateBps == 0 {
+		return utils.DivRoundHalfEven(big.NewInt(principal), big.NewInt(int64(count))).Int64()
+	}
+
+	// with r = bps / b, this is principal * bps * g^n / (b * (g^n - b^n)),
+	// where g = b + bps: integers all the way
+	b := big.NewInt(monthsPerYear)
+	g := big.NewInt(monthsPerYear + int64(rateBps))
+	n := big.NewInt(int64(count))
+	gn := new(big.Int).Exp(g, n, nil)
+	bn := new(big.Int).Exp(b, n, nil)
+
+	payment := new(big.Int).Mul(big.NewInt(principal), big.NewInt(int64(rateBps)))
+	payment.Mul(payment, gn)
+	divisor := new(big.Int).Mul(b, gn.Sub(gn, bn))
+	return utils.DivRoundHalfEven(payment, divisor).Int64()
+}
+
+// MonthlyInterest is the interest of a month on balance, rounded half to
+// even to a minor unit.
+func MonthlyInterest(balance int64, rateBps int32) int64 {
+	interest := new(big.Int).Mul(big.NewInt(balance), big.NewInt(int64(rateBps)))
+	return utils.DivRoundHalfEven(interest, big.NewInt(monthsPerYear)).Int64()
+}
+
+// DueDate is the day of period months after disbursedOn. Installments of
+// loans disbursed late in the month fall on the last day of shorter months.
+func DueDate(disbursedOn time.Time, period int32) time.Time {
+	year, month, day := disbursedOn.Date()
+	first := time.Date(year, month+time.Month(period), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(day, last)-1)
+}
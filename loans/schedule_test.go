@@ -0,0 +1,324 @@
+package loans
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnuityPayment(t *testing.T) {
+	testCases := []struct {
+		name      string
+		principal int64
+		rateBps   int32
+		count     int32
+		payment   int64
+	}{
+		{name: "one year", principal: 1_000_000, rateBps: 1200, count: 12, payment: 88_849},
+		{name: "mortgage", principal: 25_000_000, rateBps: 499, count: 360, payment: 134_053},
+		{name: "short", principal: 100_000, rateBps: 600, count: 3, payment: 33_667},
+		{name: "single installment", principal: 1_000_000, rateBps: 1200, count: 1, payment: 1_010_000},
+		{name: "tiny principal", principal: 1, rateBps: 1200, count: 1, payment: 1},
+		{name: "tiny rate", principal: 10_000, rateBps: 1, count: 2, payment: 5_000},
+		// 100 / 3 = 33.33
+		{name: "no interest", principal: 100, rateBps: 0, count: 3, payment: 33},
+		// 5 / 2 = 2.5
+		{name: "no interest half to even down", principal: 5, rateBps: 0, count: 2, payment: 2},
+		// 7 / 2 = 3.5
+		{name: "no interest half to even up", principal: 7, rateBps: 0, count: 2, payment: 4},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.payment, AnnuityPayment(tc.principal, tc.rateBps, tc.count))
+		})
+	}
+}
+
+func TestAnnuityPaymentMatchesFormula(t *testing.T) {
+	for _, principal := range []int64{100, 12_345, 1_000_000, 987_654_321, 50_000_000_000} {
+		for _, rateBps := range []int32{1, 350, 1200, 2999, 10_000} {
+			for _, count := range []int32{1, 2, 12, 60, 360, 600} {
+				r := float64(rateBps) / monthsPerYear
+				want := float64(principal) * r / (1 - math.Pow(1+r, -float64(count)))
+
+				payment := AnnuityPayment(principal, rateBps, count)
+				require.InDelta(t, want, float64(payment), 1, "%d at %d bps over %d", principal, rateBps, count)
+			}
+		}
+	}
+}
+
+func TestMonthlyInterest(t *testing.T) {
+	testCases := []struct {
+		name     string
+		balance  int64
+		rateBps  int32
+		interest int64
+	}{
+		{name: "whole", balance: 1_000_000, rateBps: 1200, interest: 10_000},
+		// 1_000_001 * 1200 / 120_000 = 10_000.01
+		{name: "rounds down", balance: 1_000_001, rateBps: 1200, interest: 10_000},
+		// 999_999 * 1200 / 120_000 = 9_999.99
+		{name: "rounds up", balance: 999_999, rateBps: 1200, interest: 10_000},
+		// 5 * 12_000 / 120_000 = 0.5
+		{name: "half to even down", balance: 5, rateBps: 12_000, interest: 0},
+		// 15 * 12_000 / 120_000 = 1.5
+		{name: "half to even up", balance: 15, rateBps: 12_000, interest: 2},
+		{name: "no interest", balance: 1_000_000, rateBps: 0, interest: 0},
+		{name: "no balance", balance: 0, rateBps: 1200, interest: 0},
+		// would overflow an int64 multiplied out
+		{name: "large balance", balance: math.MaxInt64 / 100, rateBps: 1200, interest: 922_337_203_685_478},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.interest, MonthlyInterest(tc.balance, tc.rateBps))
+		})
+	}
+}
+
+func TestScheduleFixtures(t *testing.T) {
+	type installment struct{ principal, interest int64 }
+
+	testCases := []struct {
+		name         string
+		method       string
+		principal    int64
+		rateBps      int32
+		count        int32
+		installments []installment
+	}{
+		{
+			// the last installment is 2 short of the 88_849 payment
+			name:      "annuity",
+			method:    db.LoanAnnuity,
+			principal: 1_000_000,
+			rateBps:   1200,
+			count:     12,
+			installments: []installment{
+				{78_849, 10_000}, {79_637, 9_212}, {80_434, 8_415}, {81_238, 7_611},
+				{82_051, 6_798}, {82_871, 5_978}, {83_700, 5_149}, {84_537, 4_312},
+				{85_382, 3_467}, {86_236, 2_613}, {87_098, 1_751}, {87_967, 880},
+			},
+		},
+		{
+			// 1_000_000 / 12 = 83_333 remainder 4
+			name:      "linear",
+			method:    db.LoanLinear,
+			principal: 1_000_000,
+			rateBps:   1200,
+			count:     12,
+			installments: []installment{
+				{83_334, 10_000}, {83_334, 9_167}, {83_334, 8_333}, {83_334, 7_500},
+				{83_333, 6_667}, {83_333, 5_833}, {83_333, 5_000}, {83_333, 4_167},
+				{83_333, 3_333}, {83_333, 2_500}, {83_333, 1_667}, {83_333, 833},
+			},
+		},
+		{
+			name:         "annuity short",
+			method:       db.LoanAnnuity,
+			principal:    100_000,
+			rateBps:      600,
+			count:        3,
+			installments: []installment{{33_167, 500}, {33_333, 334}, {33_500, 168}},
+		},
+		{
+			name:         "linear small",
+			method:       db.LoanLinear,
+			principal:    100,
+			rateBps:      1200,
+			count:        3,
+			installments: []installment{{34, 1}, {33, 1}, {33, 0}},
+		},
+		{
+			name:         "annuity without interest",
+			method:       db.LoanAnnuity,
+			principal:    100,
+			rateBps:      0,
+			count:        3,
+			installments: []installment{{33, 0}, {33, 0}, {34, 0}},
+		},
+		{
+			name:         "single installment",
+			method:       db.LoanAnnuity,
+			principal:    1_000_000,
+			rateBps:      1200,
+			count:        1,
+			installments: []installment{{1_000_000, 10_000}},
+		},
+		{
+			// the payment of 0.34 rounds to nothing
+			name:         "payment rounds to nothing",
+			method:       db.LoanAnnuity,
+			principal:    1,
+			rateBps:      1200,
+			count:        3,
+			installments: []installment{{0, 0}, {0, 0}, {1, 0}},
+		},
+	}
+
+	disbursedOn := time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			schedule, err := Schedule(tc.method, tc.principal, tc.rateBps, disbursedOn, 1, tc.count)
+			require.NoError(t, err)
+			require.Len(t, schedule, len(tc.installments))
+
+			for i, want := range tc.installments {
+				require.Equal(t, int32(i+1), schedule[i].Period)
+				require.Equal(t, DueDate(disbursedOn, int32(i+1)), schedule[i].DueDate)
+				require.Equal(t, want.principal, schedule[i].Principal, "principal of period %d", i+1)
+				require.Equal(t, want.interest, schedule[i].Interest, "interest of period %d", i+1)
+			}
+		})
+	}
+}
+
+func TestScheduleInvariants(t *testing.T) {
+	disbursedOn := time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC)
+
+	for _, method := range []string{db.LoanAnnuity, db.LoanLinear} {
+		for _, principal := range []int64{1, 7, 99, 100, 12_345, 1_000_000, 987_654_321, 50_000_000_000} {
+			for _, rateBps := range []int32{0, 1, 350, 1200, 2999, 10_000} {
+				for _, count := range []int32{1, 2, 3, 7, 12, 13, 60, 360, MaxTerm} {
+					name := fmt.Sprintf("%s %d at %d bps over %d", method, principal, rateBps, count)
+					schedule, err := Schedule(method, principal, rateBps, disbursedOn, 1, count)
+					require.NoError(t, err, name)
+					require.Len(t, schedule, int(count), name)
+					requireScheduleInvariants(t, name, method, principal, rateBps, schedule)
+				}
+			}
+		}
+	}
+}
+
+func requireScheduleInvariants(t *testing.T, name, method string, principal int64, rateBps int32, schedule []db.ScheduledInstallment) {
+	count := int32(len(schedule))
+	payment := AnnuityPayment(principal, rateBps, count)
+	share := principal / int64(count)
+
+	balance := principal
+	for k, installment := range schedule {
+		last := k == len(schedule)-1
+		require.Equal(t, schedule[0].Period+int32(k), installment.Period, name)
+		require.GreaterOrEqual(t, installment.Principal, int64(0), name)
+		require.LessOrEqual(t, installment.Principal, balance, name)
+		require.Equal(t, MonthlyInterest(balance, rateBps), installment.Interest, "%s: interest of period %d", name, installment.Period)
+
+		if !last && installment.Principal < balance {
+			switch method {
+			case db.LoanAnnuity:
+				require.Equal(t, payment, installment.Principal+installment.Interest, "%s: payment of period %d", name, installment.Period)
+			case db.LoanLinear:
+				require.Contains(t, []int64{share, share + 1}, installment.Principal, "%s: principal of period %d", name, installment.Period)
+			}
+		}
+		if k > 0 && method == db.LoanLinear && !last {
+			require.LessOrEqual(t, installment.Principal, schedule[k-1].Principal, name)
+		}
+
+		balance -= installment.Principal
+	}
+	require.Zero(t, balance, "%s: principal left", name)
+}
+
+func TestScheduleFromLaterPeriod(t *testing.T) {
+	disbursedOn := time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC)
+
+	schedule, err := Schedule(db.LoanAnnuity, 500_000, 1200, disbursedOn, 5, 3)
+	require.NoError(t, err)
+	require.Len(t, schedule, 3)
+
+	require.Equal(t, int32(5), schedule[0].Period)
+	require.Equal(t, time.Date(2024, time.June, 30, 0, 0, 0, 0, time.UTC), schedule[0].DueDate)
+	require.Equal(t, int32(7), schedule[2].Period)
+	require.Equal(t, time.Date(2024, time.August, 31, 0, 0, 0, 0, time.UTC), schedule[2].DueDate)
+
+	// the same amounts as a new schedule of 3 installments
+	fresh, err := Schedule(db.LoanAnnuity, 500_000, 1200, disbursedOn, 1, 3)
+	require.NoError(t, err)
+	for i := range schedule {
+		require.Equal(t, fresh[i].Principal, schedule[i].Principal)
+		require.Equal(t, fresh[i].Interest, schedule[i].Interest)
+	}
+}
+
+func TestScheduleInvalid(t *testing.T) {
+	disbursedOn := time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name      string
+		method    string
+		principal int64
+		rateBps   int32
+		count     int32
+		err       error
+	}{
+		{name: "zero principal", method: db.LoanAnnuity, principal: 0, rateBps: 1200, count: 12, err: ErrInvalidPrincipal},
+		{name: "negative principal", method: db.LoanLinear, principal: -1, rateBps: 1200, count: 12, err: ErrInvalidPrincipal},
+		{name: "zero term", method: db.LoanAnnuity, principal: 1_000, rateBps: 1200, count: 0, err: ErrInvalidTerm},
+		{name: "term too long", method: db.LoanLinear, principal: 1_000, rateBps: 1200, count: MaxTerm + 1, err: ErrInvalidTerm},
+		{name: "negative rate", method: db.LoanAnnuity, principal: 1_000, rateBps: -1, count: 12, err: ErrInvalidRate},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Schedule(tc.method, tc.principal, tc.rateBps, disbursedOn, 1, tc.count)
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	_, err := Schedule("balloon", 1_000, 1200, disbursedOn, 1, 12)
+	require.ErrorContains(t, err, "balloon")
+}
+
+func TestLoanSchedule(t *testing.T) {
+	loan := db.Loan{
+		Principal:            1_000_000,
+		OutstandingPrincipal: 400_000,
+		RateBps:              1200,
+		Method:               db.LoanLinear,
+		DisbursedOn:          time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC),
+	}
+
+	schedule, err := LoanSchedule(loan, 4, 9)
+	require.NoError(t, err)
+
+	want, err := Schedule(db.LoanLinear, 400_000, 1200, loan.DisbursedOn, 4, 9)
+	require.NoError(t, err)
+	require.Equal(t, want, schedule)
+}
+
+func TestDueDate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		disbursedOn time.Time
+		period      int32
+		due         time.Time
+	}{
+		{name: "same day", disbursedOn: date(2024, time.January, 15), period: 1, due: date(2024, time.February, 15)},
+		{name: "disbursement day", disbursedOn: date(2024, time.January, 15), period: 0, due: date(2024, time.January, 15)},
+		{name: "leap February", disbursedOn: date(2024, time.January, 31), period: 1, due: date(2024, time.February, 29)},
+		{name: "February", disbursedOn: date(2023, time.January, 31), period: 1, due: date(2023, time.February, 28)},
+		{name: "back to the 31st", disbursedOn: date(2024, time.January, 31), period: 2, due: date(2024, time.March, 31)},
+		{name: "30-day month", disbursedOn: date(2024, time.January, 31), period: 3, due: date(2024, time.April, 30)},
+		{name: "next year", disbursedOn: date(2024, time.December, 15), period: 1, due: date(2025, time.January, 15)},
+		{name: "years later", disbursedOn: date(2024, time.August, 31), period: 30, due: date(2027, time.February, 28)},
+		{name: "ignores the time of day", disbursedOn: time.Date(2024, time.January, 15, 23, 30, 0, 0, time.UTC), period: 1, due: date(2024, time.February, 15)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.due, DueDate(tc.disbursedOn, tc.period))
+		})
+	}
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
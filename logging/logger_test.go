@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggerRedactsCredentials(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf, "info")
+
+	logger.Info("login",
+		slog.String("username", "alice"),
+		slog.String("password", "secret123"),
+		slog.Group("request", slog.String("Authorization", "bearer v2.local.abc")),
+		slog.String("header", "Bearer v2.local.abc"),
+	)
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	require.Equal(t, "alice", record["username"])
+	require.Equal(t, redacted, record["password"])
+	require.Equal(t, redacted, record["request"].(map[string]interface{})["Authorization"])
+	require.Equal(t, redacted, record["header"])
+	require.NotContains(t, buf.String(), "secret123")
+	require.NotContains(t, buf.String(), "v2.local.abc")
+}
+
+func TestLoggerAddsContextValues(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf, "info").With(slog.String("component", "test"))
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	ctx = WithUsername(ctx, "alice")
+	logger.InfoContext(ctx, "hello")
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	require.Equal(t, "req-1", record["request_id"])
+	require.Equal(t, "alice", record["user"])
+	require.Equal(t, "test", record["component"])
+}
+
+func TestLoggerLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf, "warn")
+
+	logger.Info("hidden")
+	require.Empty(t, buf.String())
+
+	logger.Warn("shown")
+	require.NotEmpty(t, buf.String())
+}
@@ -0,0 +1,38 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileMailer writes every email to its own .eml file in a directory instead
+// of sending it.
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func NewFileMailer(dir string, from string) *FileMailer {
+	if dir == "" {
+		dir = "mail"
+	}
+	return &FileMailer{dir: dir, from: from}
+}
+
+func (mailer *FileMailer) Send(_ context.Context, msg Message) error {
+	if msg.From == "" {
+		msg.From = mailer.from
+	}
+
+	if err := os.MkdirAll(mailer.dir, 0o700); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	name := fmt.Sprintf("%d-%s.eml", now.UnixNano(), strings.NewReplacer("/", "_", "\\", "_").Replace(msg.To))
+	return os.WriteFile(filepath.Join(mailer.dir, name), format(msg, now), 0o600)
+}
@@ -0,0 +1,99 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/danielmoisa/neobank/utils"
+)
+
+// Message is a plain text email.
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers emails to users.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New creates the mailer selected by MAILER, defaulting to writing the
+// emails to files in MAIL_DIR, which is enough for development.
+func New(config utils.Config) (Mailer, error) {
+	switch config.Mailer {
+	case "", "file":
+		return NewFileMailer(config.MailDir, config.MailFrom), nil
+	case "smtp":
+		return NewSMTPMailer(config.SMTPAddress, config.SMTPUsername, config.SMTPPassword, config.MailFrom), nil
+	case "memory":
+		return NewMemoryMailer(), nil
+	default:
+		return nil, fmt.Errorf("unsupported mailer %q", config.Mailer)
+	}
+}
+
+// Link builds the URL of a page of the app that acts on token.
+func Link(baseURL string, path string, token string) string {
+	return strings.TrimRight(baseURL, "/") + path + "?" + url.Values{"token": {token}}.Encode()
+}
+
+func VerificationEmail(to string, link string) Message {
+	return Message{
+		To:      to,
+		Subject: "Verify your email address",
+		Body: "Please confirm that this is your email address by opening the link below.\n\n" +
+			link + "\n\nIf you didn't sign up for Neobank you can ignore this email.\n",
+	}
+}
+
+func PasswordResetEmail(to string, link string) Message {
+	return Message{
+		To:      to,
+		Subject: "Reset your password",
+		Body: "Someone asked to reset the password of your Neobank account. Open the link below to choose a new one.\n\n" +
+			link + "\n\nIf it wasn't you, ignore this email; your password stays the same.\n",
+	}
+}
+
+// ConfirmOldEmail asks the current address of a user to confirm the change
+// to newEmail.
+func ConfirmOldEmail(to string, newEmail string, link string) Message {
+	return Message{
+		To:      to,
+		Subject: "Confirm your new email address",
+		Body: "Someone asked to change the email address of your Neobank account to " + newEmail + ". Open the link below to confirm.\n\n" +
+			link + "\n\nIf it wasn't you, ignore this email and change your password; your email stays the same until this address confirms.\n",
+	}
+}
+
+// ConfirmNewEmail asks the new address of a user to confirm the change.
+func ConfirmNewEmail(to string, link string) Message {
+	return Message{
+		To:      to,
+		Subject: "Confirm your new email address",
+		Body: "Please confirm that this is the new email address of your Neobank account by opening the link below.\n\n" +
+			link + "\n\nIf you don't have a Neobank account you can ignore this email.\n",
+	}
+}
+
+// format renders msg as an RFC 5322 message.
+func format(msg Message, date time.Time) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", msg.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return buf.Bytes()
+}
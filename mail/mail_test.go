@@ -0,0 +1,55 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLink(t *testing.T) {
+	require.Equal(t, "https://app.neobank.dev/verify-email?token=a%2Bb", Link("https://app.neobank.dev/", "/verify-email", "a+b"))
+}
+
+func TestFormat(t *testing.T) {
+	date := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	msg := VerificationEmail("alice@email.com", "https://app.neobank.dev/verify-email?token=abc")
+	msg.From = "Neobank <no-reply@neobank.dev>"
+
+	raw := string(format(msg, date))
+	require.True(t, strings.HasPrefix(raw, "From: Neobank <no-reply@neobank.dev>\r\nTo: alice@email.com\r\nSubject: Verify your email address\r\n"))
+	require.Contains(t, raw, "Date: Tue, 02 Jan 2024 03:04:05 +0000\r\n")
+	require.Contains(t, raw, "\r\n\r\nPlease confirm")
+	require.Contains(t, raw, "https://app.neobank.dev/verify-email?token=abc\r\n")
+	require.NotContains(t, strings.ReplaceAll(raw, "\r\n", ""), "\n")
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	mailer := NewFileMailer(dir, "no-reply@neobank.dev")
+
+	err := mailer.Send(context.Background(), PasswordResetEmail("bob@email.com", "https://app.neobank.dev/reset-password?token=xyz"))
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*-bob@email.com.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	raw, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	require.Contains(t, string(raw), "From: no-reply@neobank.dev\r\n")
+	require.Contains(t, string(raw), "reset-password?token=xyz")
+}
+
+func TestMemoryMailer(t *testing.T) {
+	mailer := NewMemoryMailer()
+	require.Empty(t, mailer.Messages())
+
+	msg := VerificationEmail("alice@email.com", "link")
+	require.NoError(t, mailer.Send(context.Background(), msg))
+	require.Equal(t, []Message{msg}, mailer.Messages())
+}
@@ -0,0 +1,38 @@
+package mail
+
+import (
+	"context"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+// SMTPMailer sends emails through an SMTP server, authenticating with PLAIN
+// when a username is set.
+type SMTPMailer struct {
+	address string
+	auth    smtp.Auth
+	from    string
+}
+
+func NewSMTPMailer(address string, username string, password string, from string) *SMTPMailer {
+	mailer := &SMTPMailer{address: address, from: from}
+	if username != "" {
+		host, _, _ := net.SplitHostPort(address)
+		mailer.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return mailer
+}
+
+// Send ignores ctx once the connection is made, since net/smtp can't be
+// cancelled.
+func (mailer *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if msg.From == "" {
+		msg.From = mailer.from
+	}
+	return smtp.SendMail(mailer.address, mailer.auth, msg.From, []string{msg.To}, format(msg, time.Now()))
+}
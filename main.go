@@ -1,15 +1,6 @@
 package main
 
-import (
-	"database/sql"
-	"fmt"
-	"log"
-
-	"github.com/danielmoisa/neobank/api"
-	db "github.com/danielmoisa/neobank/db/sqlc"
-	"github.com/danielmoisa/neobank/utils"
-	_ "github.com/lib/pq"
-)
+import "github.com/danielmoisa/neobank/cmd"
 
 // @title Swagger Neobank API
 // @version 1.0
@@ -25,35 +16,5 @@ import (
 
 // @host neobank.swagger.io
 func main() {
-	config, err := utils.LoadConfig(".")
-	if err != nil {
-		log.Fatal("cannot load config:", err)
-	}
-
-	dbSource := fmt.Sprintf(
-		"postgresql://%s:%s@%s:%s/%s?sslmode=%s",
-		config.DBUser,
-		config.DBPassword,
-		config.DBHost,
-		config.DBPort,
-		config.DBName,
-		config.DBSSLMode,
-	)
-
-	conn, err := sql.Open(config.DBDriver, dbSource)
-	if err != nil {
-		log.Fatal("cannot connect to db:", err)
-	}
-
-	store := db.NewStore(conn)
-	server, err := api.NewServer(config, store)
-	if err != nil {
-		log.Fatal("cannot create server:", err)
-	}
-
-	err = server.Start(config.ServerAddress)
-	if err != nil {
-		log.Fatal("cannot start server:", err)
-	}
-
+	cmd.Execute()
 }
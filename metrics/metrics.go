@@ -0,0 +1,156 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "neobank"
+
+const (
+	OutcomeSuccess  = "success"
+	OutcomeError    = "error"
+	OutcomeCanceled = "canceled"
+)
+
+// Metrics owns every collector of the service. Each instance registers on its
+// own registry, so tests can create as many as they need.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequestDuration *prometheus.HistogramVec
+	paymentTxDuration   *prometheus.HistogramVec
+	paymentTxTotal      *prometheus.CounterVec
+	loginFailures       *prometheus.CounterVec
+	rateLimited         *prometheus.CounterVec
+	paymentsVolume      *prometheus.CounterVec
+	paymentsCount       *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of HTTP requests by route, method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		paymentTxDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "store",
+			Name:      "payment_tx_duration_seconds",
+			Help:      "Duration of PaymentTx database transactions by outcome.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"outcome"}),
+		paymentTxTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "store",
+			Name:      "payment_tx_total",
+			Help:      "Number of PaymentTx database transactions by outcome.",
+		}, []string{"outcome"}),
+		loginFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "auth",
+			Name:      "login_failures_total",
+			Help:      "Number of failed logins by reason.",
+		}, []string{"reason"}),
+		rateLimited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "rate_limited_total",
+			Help:      "Number of requests refused by the rate limiter by route.",
+		}, []string{"route"}),
+		paymentsVolume: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "payments",
+			Name:      "volume_total",
+			Help:      "Sum of the amounts of completed payments, in minor units, by currency.",
+		}, []string{"currency"}),
+		paymentsCount: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "payments",
+			Name:      "completed_total",
+			Help:      "Number of completed payments by currency.",
+		}, []string{"currency"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequestDuration,
+		m.paymentTxDuration,
+		m.paymentTxTotal,
+		m.loginFailures,
+		m.rateLimited,
+		m.paymentsVolume,
+		m.paymentsCount,
+	)
+
+	return m
+}
+
+// RegisterDBStats exports the connection pool statistics of conn.
+func (m *Metrics) RegisterDBStats(conn *sql.DB) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(conn, namespace))
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Registry exposes the underlying registry, mainly for tests.
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+func (m *Metrics) ObserveHTTPRequest(method string, route string, status int, duration time.Duration) {
+	m.httpRequestDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+// ObservePaymentTx implements db.PaymentObserver.
+func (m *Metrics) ObservePaymentTx(duration time.Duration, err error) {
+	outcome := outcome(err)
+	m.paymentTxDuration.WithLabelValues(outcome).Observe(duration.Seconds())
+	m.paymentTxTotal.WithLabelValues(outcome).Inc()
+}
+
+func (m *Metrics) IncLoginFailure(reason string) {
+	m.loginFailures.WithLabelValues(reason).Inc()
+}
+
+func (m *Metrics) IncRateLimited(route string) {
+	m.rateLimited.WithLabelValues(route).Inc()
+}
+
+// ObservePayment counts a payment that went through. Payments are positive;
+// anything else is skipped rather than handed to the volume counter, which
+// panics when it would go down.
+func (m *Metrics) ObservePayment(currency string, amount int64) {
+	if amount <= 0 {
+		return
+	}
+	m.paymentsVolume.WithLabelValues(currency).Add(float64(amount))
+	m.paymentsCount.WithLabelValues(currency).Inc()
+}
+
+func outcome(err error) string {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return OutcomeCanceled
+	default:
+		return OutcomeError
+	}
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObservePaymentTx(t *testing.T) {
+	m := New()
+
+	m.ObservePaymentTx(time.Millisecond, nil)
+	m.ObservePaymentTx(time.Millisecond, nil)
+	m.ObservePaymentTx(time.Millisecond, errors.New("deadlock detected"))
+	m.ObservePaymentTx(time.Millisecond, context.Canceled)
+
+	require.Equal(t, float64(2), testutil.ToFloat64(m.paymentTxTotal.WithLabelValues(OutcomeSuccess)))
+	require.Equal(t, float64(1), testutil.ToFloat64(m.paymentTxTotal.WithLabelValues(OutcomeError)))
+	require.Equal(t, float64(1), testutil.ToFloat64(m.paymentTxTotal.WithLabelValues(OutcomeCanceled)))
+	require.Equal(t, 3, testutil.CollectAndCount(m.paymentTxDuration))
+}
+
+func TestObservePayment(t *testing.T) {
+	m := New()
+
+	m.ObservePayment("EUR", 100)
+	m.ObservePayment("EUR", 50)
+	m.ObservePayment("USD", 10)
+	require.NotPanics(t, func() {
+		m.ObservePayment("USD", -10)
+		m.ObservePayment("USD", 0)
+	})
+
+	require.Equal(t, float64(150), testutil.ToFloat64(m.paymentsVolume.WithLabelValues("EUR")))
+	require.Equal(t, float64(2), testutil.ToFloat64(m.paymentsCount.WithLabelValues("EUR")))
+	require.Equal(t, float64(10), testutil.ToFloat64(m.paymentsVolume.WithLabelValues("USD")))
+	require.Equal(t, float64(1), testutil.ToFloat64(m.paymentsCount.WithLabelValues("USD")))
+}
+
+func TestHandler(t *testing.T) {
+	m := New()
+	m.ObserveHTTPRequest(http.MethodGet, "/accounts/:id", http.StatusOK, time.Millisecond)
+	m.IncLoginFailure("wrong_password")
+
+	recorder := httptest.NewRecorder()
+	m.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	body := recorder.Body.String()
+	require.True(t, strings.Contains(body, `neobank_http_request_duration_seconds_count{method="GET",route="/accounts/:id",status="200"} 1`))
+	require.True(t, strings.Contains(body, `neobank_auth_login_failures_total{reason="wrong_password"} 1`))
+}
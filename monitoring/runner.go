@@ -0,0 +1,179 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/utils"
+)
+
+// Defaults of the scenarios when AML_STRUCTURING_THRESHOLD and
+// AML_DORMANT_PERIOD aren't set. Amounts are in minor units.
+const (
+	DefaultStructuringThreshold = 1_000_000
+	DefaultDormantPeriod        = 180 * 24 * time.Hour
+)
+
+// DefaultScenarios are the scenarios the scheduled job runs, tuned by config.
+func DefaultScenarios(config utils.Config) []Scenario {
+	threshold := config.AMLThreshold
+	if threshold <= 0 {
+		threshold = DefaultStructuringThreshold
+	}
+	dormantPeriod := config.AMLDormantPeriod
+	if dormantPeriod <= 0 {
+		dormantPeriod = DefaultDormantPeriod
+	}
+
+	return []Scenario{
+		Structuring{Threshold: threshold, MarginPercent: 10, MinPayments: 3, Period: 7 * 24 * time.Hour},
+		PassThrough{MinAmount: threshold / 2, MinOutPercent: 90, Period: 24 * time.Hour},
+		RoundTrip{MinAmount: threshold / 10, TolerancePercent: 10, MaxDelay: 72 * time.Hour, Period: 30 * 24 * time.Hour},
+		Dormant{DormantPeriod: dormantPeriod, MinAmount: threshold / 10, Period: 7 * 24 * time.Hour},
+	}
+}
+
+// Runner runs scenarios and records their alerts. Every run is recorded as a
+// job run, with its window and error.
+type Runner struct {
+	store     db.Store
+	scenarios []Scenario
+	now       func() time.Time
+}
+
+func NewRunner(store db.Store, scenarios ...Scenario) *Runner {
+	return &Runner{
+		store:     store,
+		scenarios: scenarios,
+		now:       time.Now,
+	}
+}
+
+// New builds a runner of the default scenarios.
+func New(config utils.Config, store db.Store) *Runner {
+	return NewRunner(store, DefaultScenarios(config)...)
+}
+
+// Names returns the names of the scenarios, in the order they run.
+func (runner *Runner) Names() []string {
+	names := make([]string, len(runner.scenarios))
+	for i, scenario := range runner.scenarios {
+		names[i] = scenario.Name()
+	}
+	return names
+}
+
+// Run runs the named scenarios once, or all of them when no names are
+// given. A failing scenario doesn't stop the others; its error is returned
+// with the job runs.
+func (runner *Runner) Run(ctx context.Context, names ...string) ([]db.AMLJobRun, error) {
+	scenarios := runner.scenarios
+	if len(names) > 0 {
+		scenarios = nil
+		for _, name := range names {
+			scenario, err := runner.lookup(name)
+			if err != nil {
+				return nil, err
+			}
+			scenarios = append(scenarios, scenario)
+		}
+	}
+
+	var runs []db.AMLJobRun
+	var errs []error
+	for _, scenario := range scenarios {
+		run, err := runner.run(ctx, scenario)
+		if run.ID != 0 {
+			runs = append(runs, run)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", scenario.Name(), err))
+		}
+	}
+	return runs, errors.Join(errs...)
+}
+
+func (runner *Runner) lookup(name string) (Scenario, error) {
+	for _, scenario := range runner.scenarios {
+		if scenario.Name() == name {
+			return scenario, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown scenario %s", name)
+}
+
+func (runner *Runner) run(ctx context.Context, scenario Scenario) (db.AMLJobRun, error) {
+	now := runner.now()
+	window := Window{Start: now.Add(-scenario.Lookback()), End: now}
+
+	run, err := runner.store.CreateAMLJobRun(ctx, db.CreateAMLJobRunParams{
+		Scenario:    scenario.Name(),
+		WindowStart: window.Start,
+		WindowEnd:   window.End,
+	})
+	if err != nil {
+		return run, err
+	}
+
+	alerts, err := runner.detect(ctx, scenario, window)
+	message := ""
+	if err != nil {
+		message = err.Error()
+	}
+
+	finished, finishErr := runner.store.FinishAMLJobRun(ctx, db.FinishAMLJobRunParams{
+		ID:     run.ID,
+		Alerts: int32(len(alerts)),
+		Error:  message,
+	})
+	if finishErr != nil {
+		return run, errors.Join(err, finishErr)
+	}
+	return finished, err
+}
+
+func (runner *Runner) detect(ctx context.Context, scenario Scenario, window Window) ([]db.AMLAlert, error) {
+	findings, err := scenario.Detect(ctx, runner.store, window)
+	if err != nil || len(findings) == 0 {
+		return nil, err
+	}
+
+	alerts := make([]db.UpsertAMLAlertParams, len(findings))
+	for i, finding := range findings {
+		alerts[i], err = finding.alert(scenario.Name())
+		if err != nil {
+			return nil, err
+		}
+	}
+	return runner.store.RecordAMLAlertsTx(ctx, alerts)
+}
+
+// Start runs every scenario right away and then every interval, until ctx
+// is done. Failures are logged and the scenario runs again at the next tick.
+func (runner *Runner) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		runs, err := runner.Run(ctx)
+		for _, run := range runs {
+			slog.InfoContext(ctx, "ran monitoring scenario",
+				slog.String("scenario", run.Scenario),
+				slog.Int("alerts", int(run.Alerts)),
+			)
+		}
+		if err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "monitoring scenario failed", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// stubScenario finds what it is given.
+type stubScenario struct {
+	name     string
+	findings []Finding
+	err      error
+}
+
+func (scenario stubScenario) Name() string            { return scenario.name }
+func (scenario stubScenario) Lookback() time.Duration { return time.Hour }
+
+func (scenario stubScenario) Detect(ctx context.Context, q db.Querier, window Window) ([]Finding, error) {
+	return scenario.findings, scenario.err
+}
+
+func TestDefaultScenarios(t *testing.T) {
+	runner := New(utils.Config{}, nil)
+	require.Equal(t, []string{ScenarioStructuring, ScenarioPassThrough, ScenarioRoundTrip, ScenarioDormant}, runner.Names())
+
+	scenarios := DefaultScenarios(utils.Config{})
+	require.Equal(t, int64(DefaultStructuringThreshold), scenarios[0].(Structuring).Threshold)
+	require.Equal(t, DefaultDormantPeriod, scenarios[3].(Dormant).DormantPeriod)
+
+	scenarios = DefaultScenarios(utils.Config{AMLThreshold: 500_000, AMLDormantPeriod: 24 * time.Hour})
+	require.Equal(t, int64(500_000), scenarios[0].(Structuring).Threshold)
+	require.Equal(t, int64(250_000), scenarios[1].(PassThrough).MinAmount)
+	require.Equal(t, 24*time.Hour, scenarios[3].(Dormant).DormantPeriod)
+}
+
+func TestRunnerRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	found := stubScenario{
+		name: "found",
+		findings: []Finding{
+			{Username: "alice", AccountID: 1, Summary: "one", Evidence: Evidence{PaymentIDs: []int64{1}}},
+			{Username: "bob", AccountID: 2, Summary: "two", Evidence: Evidence{PaymentIDs: []int64{2}}},
+		},
+	}
+	failed := stubScenario{name: "failed", err: errors.New("query timed out")}
+	quiet := stubScenario{name: "quiet"}
+
+	store := mockdb.NewMockStore(ctrl)
+	runner := NewRunner(store, found, failed, quiet)
+	runner.now = func() time.Time { return now }
+
+	expectRun := func(id int64, name string, alerts int32, message string) {
+		store.EXPECT().
+			CreateAMLJobRun(gomock.Any(), gomock.Eq(db.CreateAMLJobRunParams{
+				Scenario:    name,
+				WindowStart: now.Add(-time.Hour),
+				WindowEnd:   now,
+			})).
+			Times(1).
+			Return(db.AMLJobRun{ID: id, Scenario: name}, nil)
+		store.EXPECT().
+			FinishAMLJobRun(gomock.Any(), gomock.Eq(db.FinishAMLJobRunParams{ID: id, Alerts: alerts, Error: message})).
+			Times(1).
+			Return(db.AMLJobRun{ID: id, Scenario: name, Alerts: alerts, Error: message}, nil)
+	}
+	expectRun(1, "found", 1, "")
+	expectRun(2, "failed", 0, "query timed out")
+	expectRun(3, "quiet", 0, "")
+
+	// the second finding was already covered, so only one alert is recorded
+	store.EXPECT().
+		RecordAMLAlertsTx(gomock.Any(), gomock.Cond(func(x any) bool {
+			alerts, ok := x.([]db.UpsertAMLAlertParams)
+			return ok && len(alerts) == 2 && alerts[0].Scenario == "found" && alerts[1].Username == "bob"
+		})).
+		Times(1).
+		Return([]db.AMLAlert{{ID: 10}}, nil)
+
+	runs, err := runner.Run(context.Background())
+	require.EqualError(t, err, "failed: query timed out")
+	require.Len(t, runs, 3)
+	require.Equal(t, int32(1), runs[0].Alerts)
+	require.Equal(t, "query timed out", runs[1].Error)
+
+	_, err = runner.Run(context.Background(), "found", "missing")
+	require.EqualError(t, err, "unknown scenario missing")
+}
+
+func TestRunnerRunNamed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	runner := NewRunner(store, stubScenario{name: "first"}, stubScenario{name: "second"})
+
+	store.EXPECT().
+		CreateAMLJobRun(gomock.Any(), gomock.Cond(func(x any) bool {
+			arg, ok := x.(db.CreateAMLJobRunParams)
+			return ok && arg.Scenario == "second"
+		})).
+		Times(1).
+		Return(db.AMLJobRun{ID: 1, Scenario: "second"}, nil)
+	store.EXPECT().
+		FinishAMLJobRun(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.AMLJobRun{ID: 1, Scenario: "second"}, nil)
+
+	runs, err := runner.Run(context.Background(), "second")
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, "second", runs[0].Scenario)
+}
+
+func TestRunnerStart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	runner := NewRunner(store, stubScenario{name: "quiet"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	store.EXPECT().
+		CreateAMLJobRun(gomock.Any(), gomock.Any()).
+		MinTimes(2).
+		DoAndReturn(func(_ context.Context, arg db.CreateAMLJobRunParams) (db.AMLJobRun, error) {
+			return db.AMLJobRun{ID: 1, Scenario: arg.Scenario}, nil
+		})
+	calls := 0
+	store.EXPECT().
+		FinishAMLJobRun(gomock.Any(), gomock.Any()).
+		MinTimes(2).
+		DoAndReturn(func(_ context.Context, arg db.FinishAMLJobRunParams) (db.AMLJobRun, error) {
+			calls++
+			if calls == 2 {
+				cancel()
+			}
+			return db.AMLJobRun{ID: arg.ID}, nil
+		})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runner.Start(ctx, time.Millisecond)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("runner did not stop")
+	}
+}
@@ -0,0 +1,189 @@
+package monitoring
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+)
+
+var ErrAlertNotEscalated = errors.New("only escalated alerts are reported")
+
+// SAR is a suspicious activity report on an escalated alert: who the subject
+// is, the activity with every payment and entry behind it, and what the
+// analysts found.
+type SAR struct {
+	ReportID    string      `json:"report_id"`
+	GeneratedAt time.Time   `json:"generated_at"`
+	GeneratedBy string      `json:"generated_by"`
+	Subject     SARSubject  `json:"subject"`
+	Account     SARAccount  `json:"account"`
+	Activity    SARActivity `json:"activity"`
+	Narrative   string      `json:"narrative"`
+	Notes       []SARNote   `json:"notes"`
+}
+
+type SARSubject struct {
+	Username    string `json:"username"`
+	FullName    string `json:"full_name"`
+	Email       string `json:"email"`
+	LegalName   string `json:"legal_name,omitempty"`
+	DateOfBirth string `json:"date_of_birth,omitempty"`
+	Nationality string `json:"nationality,omitempty"`
+	Address     string `json:"address,omitempty"`
+	KYCStatus   string `json:"kyc_status"`
+}
+
+type SARAccount struct {
+	ID       int64     `json:"id"`
+	Currency string    `json:"currency"`
+	Balance  int64     `json:"balance"`
+	OpenedAt time.Time `json:"opened_at"`
+}
+
+type SARActivity struct {
+	AlertID        int64            `json:"alert_id"`
+	Scenario       string           `json:"scenario"`
+	Summary        string           `json:"summary"`
+	Start          time.Time        `json:"start"`
+	End            time.Time        `json:"end"`
+	DetectedAt     time.Time        `json:"detected_at"`
+	EscalatedBy    string           `json:"escalated_by"`
+	EscalatedAt    time.Time        `json:"escalated_at"`
+	Counterparties []string         `json:"counterparties,omitempty"`
+	Facts          map[string]int64 `json:"facts"`
+	Transactions   []SARTransaction `json:"transactions"`
+}
+
+// SARTransaction is a payment, with both accounts, or an entry of the
+// account.
+type SARTransaction struct {
+	Type          string    `json:"type"`
+	ID            int64     `json:"id"`
+	Time          time.Time `json:"time"`
+	Amount        int64     `json:"amount"`
+	FromAccountID int64     `json:"from_account_id,omitempty"`
+	ToAccountID   int64     `json:"to_account_id,omitempty"`
+	AccountID     int64     `json:"account_id,omitempty"`
+}
+
+type SARNote struct {
+	Author string    `json:"author"`
+	Body   string    `json:"body"`
+	Time   time.Time `json:"time"`
+}
+
+// BuildSAR gathers the suspicious activity report of an escalated alert.
+func BuildSAR(ctx context.Context, q db.Querier, alert db.AMLAlert, generatedBy string, now time.Time) (SAR, error) {
+	if alert.Status != db.AMLAlertEscalated {
+		return SAR{}, ErrAlertNotEscalated
+	}
+
+	var evidence Evidence
+	if err := json.Unmarshal(alert.Evidence, &evidence); err != nil {
+		return SAR{}, fmt.Errorf("invalid evidence: %w", err)
+	}
+
+	user, err := q.GetUser(ctx, alert.Username)
+	if err != nil {
+		return SAR{}, err
+	}
+	account, err := q.GetAccount(ctx, alert.AccountID)
+	if err != nil {
+		return SAR{}, err
+	}
+
+	report := SAR{
+		ReportID:    fmt.Sprintf("SAR-%d", alert.ID),
+		GeneratedAt: now,
+		GeneratedBy: generatedBy,
+		Subject: SARSubject{
+			Username:  user.Username,
+			FullName:  user.FullName,
+			Email:     user.Email,
+			KYCStatus: user.KYCStatus,
+		},
+		Account: SARAccount{
+			ID:       account.ID,
+			Currency: account.Currency,
+			Balance:  account.Balance,
+			OpenedAt: account.CreatedAt,
+		},
+		Activity: SARActivity{
+			AlertID:        alert.ID,
+			Scenario:       alert.Scenario,
+			Summary:        alert.Summary,
+			Start:          evidence.Window.Start,
+			End:            evidence.Window.End,
+			DetectedAt:     alert.CreatedAt,
+			EscalatedBy:    alert.ResolvedBy.String,
+			EscalatedAt:    alert.ResolvedAt.Time,
+			Counterparties: evidence.Counterparties,
+			Facts:          evidence.Facts,
+			Transactions:   []SARTransaction{},
+		},
+		Narrative: alert.Resolution,
+		Notes:     []SARNote{},
+	}
+
+	profile, err := q.GetKYCProfile(ctx, user.Username)
+	switch {
+	case err == nil:
+		report.Subject.LegalName = profile.LegalName
+		report.Subject.DateOfBirth = profile.DateOfBirth.Format(time.DateOnly)
+		report.Subject.Nationality = profile.Nationality
+		report.Subject.Address = strings.Join([]string{profile.AddressLine, profile.PostalCode + " " + profile.City, profile.Country}, ", ")
+	case !errors.Is(err, sql.ErrNoRows):
+		return SAR{}, err
+	}
+
+	if len(evidence.PaymentIDs) > 0 {
+		payments, err := q.ListPaymentsByID(ctx, evidence.PaymentIDs)
+		if err != nil {
+			return SAR{}, err
+		}
+		for _, payment := range payments {
+			report.Activity.Transactions = append(report.Activity.Transactions, SARTransaction{
+				Type:          "payment",
+				ID:            payment.ID,
+				Time:          payment.CreatedAt,
+				Amount:        payment.Amount,
+				FromAccountID: payment.FromAccountID,
+				ToAccountID:   payment.ToAccountID,
+			})
+		}
+	}
+	if len(evidence.EntryIDs) > 0 {
+		entries, err := q.ListEntriesByID(ctx, evidence.EntryIDs)
+		if err != nil {
+			return SAR{}, err
+		}
+		for _, entry := range entries {
+			report.Activity.Transactions = append(report.Activity.Transactions, SARTransaction{
+				Type:      "entry",
+				ID:        entry.ID,
+				Time:      entry.CreatedAt,
+				Amount:    entry.Amount,
+				AccountID: entry.AccountID,
+			})
+		}
+	}
+
+	comments, err := q.ListAMLAlertComments(ctx, alert.ID)
+	if err != nil {
+		return SAR{}, err
+	}
+	for _, comment := range comments {
+		report.Notes = append(report.Notes, SARNote{
+			Author: comment.Author,
+			Body:   comment.Body,
+			Time:   comment.CreatedAt,
+		})
+	}
+	return report, nil
+}
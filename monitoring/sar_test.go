@@ -0,0 +1,140 @@
+package monitoring
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestBuildSAR(t *testing.T) {
+	now := time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC)
+	window := testWindow(24 * time.Hour)
+	evidence, err := json.Marshal(Evidence{
+		Window:     window,
+		PaymentIDs: []int64{1},
+		EntryIDs:   []int64{2},
+		Facts:      map[string]int64{"total": 500},
+	})
+	require.NoError(t, err)
+
+	alert := db.AMLAlert{
+		ID:         42,
+		Scenario:   ScenarioPassThrough,
+		Username:   "alice",
+		AccountID:  7,
+		Summary:    "summary",
+		Evidence:   evidence,
+		Status:     db.AMLAlertEscalated,
+		Resolution: "money mule",
+		ResolvedBy: sql.NullString{String: "analyst", Valid: true},
+		ResolvedAt: sql.NullTime{Time: now.Add(-time.Hour), Valid: true},
+		CreatedAt:  window.End,
+	}
+
+	testCases := []struct {
+		name       string
+		alert      db.AMLAlert
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, report SAR, err error)
+	}{
+		{
+			name:  "OK",
+			alert: alert,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq("alice")).Times(1).
+					Return(db.User{Username: "alice", FullName: "Alice Doe", Email: "alice@example.com", KYCStatus: "verified"}, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(int64(7))).Times(1).
+					Return(db.Account{ID: 7, Owner: "alice", Currency: "EUR", Balance: 100}, nil)
+				store.EXPECT().GetKYCProfile(gomock.Any(), gomock.Eq("alice")).Times(1).
+					Return(db.KYCProfile{
+						LegalName:   "Alice Jane Doe",
+						DateOfBirth: time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC),
+						Nationality: "FR",
+						AddressLine: "1 Rue de Rivoli",
+						City:        "Paris",
+						PostalCode:  "75001",
+						Country:     "FR",
+					}, nil)
+				store.EXPECT().ListPaymentsByID(gomock.Any(), gomock.Eq([]int64{1})).Times(1).
+					Return([]db.Payment{{ID: 1, FromAccountID: 3, ToAccountID: 7, Amount: 500}}, nil)
+				store.EXPECT().ListEntriesByID(gomock.Any(), gomock.Eq([]int64{2})).Times(1).
+					Return([]db.Entry{{ID: 2, AccountID: 7, Amount: -480}}, nil)
+				store.EXPECT().ListAMLAlertComments(gomock.Any(), gomock.Eq(int64(42))).Times(1).
+					Return([]db.AMLAlertComment{{ID: 1, AlertID: 42, Author: "analyst", Body: "no business reason"}}, nil)
+			},
+			check: func(t *testing.T, report SAR, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "SAR-42", report.ReportID)
+				require.Equal(t, "officer", report.GeneratedBy)
+				require.Equal(t, "Alice Jane Doe", report.Subject.LegalName)
+				require.Equal(t, "1990-05-17", report.Subject.DateOfBirth)
+				require.Equal(t, "1 Rue de Rivoli, 75001 Paris, FR", report.Subject.Address)
+				require.Equal(t, "EUR", report.Account.Currency)
+				require.Equal(t, "analyst", report.Activity.EscalatedBy)
+				require.True(t, report.Activity.Start.Equal(window.Start))
+				require.True(t, report.Activity.End.Equal(window.End))
+				require.Equal(t, []SARTransaction{
+					{Type: "payment", ID: 1, Amount: 500, FromAccountID: 3, ToAccountID: 7},
+					{Type: "entry", ID: 2, Amount: -480, AccountID: 7},
+				}, report.Activity.Transactions)
+				require.Equal(t, "money mule", report.Narrative)
+				require.Equal(t, []SARNote{{Author: "analyst", Body: "no business reason"}}, report.Notes)
+			},
+		},
+		{
+			name:  "NoKYCProfile",
+			alert: alert,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(db.User{Username: "alice"}, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{ID: 7}, nil)
+				store.EXPECT().GetKYCProfile(gomock.Any(), gomock.Any()).Times(1).Return(db.KYCProfile{}, sql.ErrNoRows)
+				store.EXPECT().ListPaymentsByID(gomock.Any(), gomock.Any()).Times(1).Return([]db.Payment{}, nil)
+				store.EXPECT().ListEntriesByID(gomock.Any(), gomock.Any()).Times(1).Return([]db.Entry{}, nil)
+				store.EXPECT().ListAMLAlertComments(gomock.Any(), gomock.Any()).Times(1).Return([]db.AMLAlertComment{}, nil)
+			},
+			check: func(t *testing.T, report SAR, err error) {
+				require.NoError(t, err)
+				require.Empty(t, report.Subject.LegalName)
+				require.Empty(t, report.Subject.Address)
+				require.Empty(t, report.Activity.Transactions)
+				require.Empty(t, report.Notes)
+			},
+		},
+		{
+			name: "NotEscalated",
+			alert: func() db.AMLAlert {
+				open := alert
+				open.Status = db.AMLAlertOpen
+				return open
+			}(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, report SAR, err error) {
+				require.ErrorIs(t, err, ErrAlertNotEscalated)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			report, err := BuildSAR(context.Background(), store, tc.alert, "officer", now)
+			tc.check(t, report, err)
+		})
+	}
+}
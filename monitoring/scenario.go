@@ -0,0 +1,291 @@
+// Package monitoring runs transaction monitoring scenarios over payments and
+// entries in batches and records what they find as alerts for compliance
+// analysts to work.
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+)
+
+// Names of the scenarios, as recorded in alerts and job runs.
+const (
+	ScenarioStructuring = "structuring"
+	ScenarioPassThrough = "pass_through"
+	ScenarioRoundTrip   = "round_trip"
+	ScenarioDormant     = "dormant_reactivation"
+)
+
+// Window is the activity a scenario looks at, from Start until End.
+type Window struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Evidence is what an alert is based on. A finding isn't raised again while
+// an alert of the account lists all its payments and entries.
+type Evidence struct {
+	Window         Window           `json:"window"`
+	PaymentIDs     []int64          `json:"payment_ids"`
+	EntryIDs       []int64          `json:"entry_ids"`
+	Counterparties []string         `json:"counterparties,omitempty"`
+	Facts          map[string]int64 `json:"facts"`
+}
+
+// Finding is suspicious activity of one account.
+type Finding struct {
+	Username  string
+	AccountID int64
+	Summary   string
+	Evidence  Evidence
+}
+
+// alert turns the finding into an alert of scenario.
+func (finding Finding) alert(scenario string) (db.UpsertAMLAlertParams, error) {
+	// null isn't contained in an array, so empty lists must be []
+	if finding.Evidence.PaymentIDs == nil {
+		finding.Evidence.PaymentIDs = []int64{}
+	}
+	if finding.Evidence.EntryIDs == nil {
+		finding.Evidence.EntryIDs = []int64{}
+	}
+
+	evidence, err := json.Marshal(finding.Evidence)
+	if err != nil {
+		return db.UpsertAMLAlertParams{}, err
+	}
+	coverage, err := json.Marshal(map[string][]int64{
+		"payment_ids": finding.Evidence.PaymentIDs,
+		"entry_ids":   finding.Evidence.EntryIDs,
+	})
+	if err != nil {
+		return db.UpsertAMLAlertParams{}, err
+	}
+
+	return db.UpsertAMLAlertParams{
+		Scenario:  scenario,
+		Username:  finding.Username,
+		AccountID: finding.AccountID,
+		Summary:   finding.Summary,
+		Evidence:  evidence,
+		Coverage:  coverage,
+	}, nil
+}
+
+// Scenario detects one kind of suspicious activity.
+type Scenario interface {
+	// Name identifies the scenario in alerts and job runs.
+	Name() string
+	// Lookback is how far back from the time of a run the scenario looks.
+	Lookback() time.Duration
+	// Detect finds the suspicious activity in window.
+	Detect(ctx context.Context, q db.Querier, window Window) ([]Finding, error)
+}
+
+// Structuring finds accounts that split payments to stay under a reporting
+// threshold: at least MinPayments payments over Period, each within
+// MarginPercent under Threshold, which together reach it.
+type Structuring struct {
+	Threshold     int64
+	MarginPercent int64
+	MinPayments   int64
+	Period        time.Duration
+}
+
+func (scenario Structuring) Name() string            { return ScenarioStructuring }
+func (scenario Structuring) Lookback() time.Duration { return scenario.Period }
+
+func (scenario Structuring) Detect(ctx context.Context, q db.Querier, window Window) ([]Finding, error) {
+	rows, err := q.FindStructuring(ctx, db.FindStructuringParams{
+		Since:       window.Start,
+		Until:       window.End,
+		MinAmount:   scenario.Threshold - scenario.Threshold*scenario.MarginPercent/100,
+		Threshold:   scenario.Threshold,
+		MinPayments: scenario.MinPayments,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	findings := make([]Finding, len(rows))
+	for i, row := range rows {
+		findings[i] = Finding{
+			Username:  row.Owner,
+			AccountID: row.AccountID,
+			Summary: fmt.Sprintf("%d payments just under %d %s, %d in total",
+				row.PaymentCount, scenario.Threshold, row.Currency, row.Total),
+			Evidence: Evidence{
+				Window:     window,
+				PaymentIDs: row.PaymentIDs,
+				Facts: map[string]int64{
+					"payments":  row.PaymentCount,
+					"total":     row.Total,
+					"threshold": scenario.Threshold,
+				},
+			},
+		}
+	}
+	return findings, nil
+}
+
+// PassThrough finds accounts used to move money on: over Period they
+// received at least MinAmount and sent on at least MinOutPercent of it,
+// after receiving it.
+type PassThrough struct {
+	MinAmount     int64
+	MinOutPercent int64
+	Period        time.Duration
+}
+
+func (scenario PassThrough) Name() string            { return ScenarioPassThrough }
+func (scenario PassThrough) Lookback() time.Duration { return scenario.Period }
+
+func (scenario PassThrough) Detect(ctx context.Context, q db.Querier, window Window) ([]Finding, error) {
+	rows, err := q.FindPassThrough(ctx, db.FindPassThroughParams{
+		Since:         window.Start,
+		Until:         window.End,
+		MinAmount:     scenario.MinAmount,
+		MinOutPercent: scenario.MinOutPercent,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	findings := make([]Finding, len(rows))
+	for i, row := range rows {
+		findings[i] = Finding{
+			Username:  row.Owner,
+			AccountID: row.AccountID,
+			Summary: fmt.Sprintf("received %d %s and sent on %d within %s",
+				row.TotalIn, row.Currency, row.TotalOut, scenario.Period),
+			Evidence: Evidence{
+				Window:   window,
+				EntryIDs: row.EntryIDs,
+				Facts: map[string]int64{
+					"total_in":  row.TotalIn,
+					"total_out": row.TotalOut,
+				},
+			},
+		}
+	}
+	return findings, nil
+}
+
+// RoundTrip finds money that went back to where it came from: payments of
+// at least MinAmount to another user who paid back about the same amount,
+// within TolerancePercent, at most MaxDelay later.
+type RoundTrip struct {
+	MinAmount        int64
+	TolerancePercent int64
+	MaxDelay         time.Duration
+	Period           time.Duration
+}
+
+func (scenario RoundTrip) Name() string            { return ScenarioRoundTrip }
+func (scenario RoundTrip) Lookback() time.Duration { return scenario.Period }
+
+func (scenario RoundTrip) Detect(ctx context.Context, q db.Querier, window Window) ([]Finding, error) {
+	rows, err := q.FindRoundTrips(ctx, db.FindRoundTripsParams{
+		MaxDelayHours:    int32(scenario.MaxDelay / time.Hour),
+		Since:            window.Start,
+		Until:            window.End,
+		MinAmount:        scenario.MinAmount,
+		TolerancePercent: scenario.TolerancePercent,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// a payment can come back in several parts, and pay back several
+	// payments, so the pairs are gathered by account
+	var findings []Finding
+	var currency string
+	var counted map[int64]bool
+	for _, row := range rows {
+		if len(findings) == 0 || findings[len(findings)-1].AccountID != row.AccountID {
+			findings = append(findings, Finding{
+				Username:  row.Owner,
+				AccountID: row.AccountID,
+				Evidence: Evidence{
+					Window:     window,
+					PaymentIDs: []int64{},
+					Facts:      map[string]int64{"sent": 0, "returned": 0},
+				},
+			})
+			currency = row.Currency
+			counted = make(map[int64]bool)
+		}
+		finding := &findings[len(findings)-1]
+
+		if !counted[row.OutgoingPaymentID] {
+			counted[row.OutgoingPaymentID] = true
+			finding.Evidence.PaymentIDs = append(finding.Evidence.PaymentIDs, row.OutgoingPaymentID)
+			finding.Evidence.Facts["sent"] += row.OutgoingAmount
+		}
+		if !counted[row.BackPaymentID] {
+			counted[row.BackPaymentID] = true
+			finding.Evidence.PaymentIDs = append(finding.Evidence.PaymentIDs, row.BackPaymentID)
+			finding.Evidence.Facts["returned"] += row.BackAmount
+		}
+		if !slices.Contains(finding.Evidence.Counterparties, row.Counterparty) {
+			finding.Evidence.Counterparties = append(finding.Evidence.Counterparties, row.Counterparty)
+		}
+		finding.Summary = fmt.Sprintf("sent %d %s to %d users and got %d back within %s",
+			finding.Evidence.Facts["sent"], currency, len(finding.Evidence.Counterparties),
+			finding.Evidence.Facts["returned"], scenario.MaxDelay)
+	}
+
+	for i := range findings {
+		slices.Sort(findings[i].Evidence.PaymentIDs)
+		slices.Sort(findings[i].Evidence.Counterparties)
+	}
+	return findings, nil
+}
+
+// Dormant finds accounts that had no activity for DormantPeriod and then
+// moved at least MinAmount in and out over Period.
+type Dormant struct {
+	DormantPeriod time.Duration
+	MinAmount     int64
+	Period        time.Duration
+}
+
+func (scenario Dormant) Name() string            { return ScenarioDormant }
+func (scenario Dormant) Lookback() time.Duration { return scenario.Period }
+
+func (scenario Dormant) Detect(ctx context.Context, q db.Querier, window Window) ([]Finding, error) {
+	rows, err := q.FindDormantReactivations(ctx, db.FindDormantReactivationsParams{
+		Since:        window.Start,
+		Until:        window.End,
+		DormantSince: window.Start.Add(-scenario.DormantPeriod),
+		MinAmount:    scenario.MinAmount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	findings := make([]Finding, len(rows))
+	for i, row := range rows {
+		dormantDays := int64(window.Start.Sub(row.LastActiveAt) / (24 * time.Hour))
+		findings[i] = Finding{
+			Username:  row.Owner,
+			AccountID: row.AccountID,
+			Summary: fmt.Sprintf("moved %d %s after %d days without activity",
+				row.Turnover, row.Currency, dormantDays),
+			Evidence: Evidence{
+				Window:   window,
+				EntryIDs: row.EntryIDs,
+				Facts: map[string]int64{
+					"turnover":     row.Turnover,
+					"dormant_days": dormantDays,
+				},
+			},
+		}
+	}
+	return findings, nil
+}
@@ -0,0 +1,207 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func testWindow(period time.Duration) Window {
+	end := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	return Window{Start: end.Add(-period), End: end}
+}
+
+func TestStructuring(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	scenario := Structuring{Threshold: 1_000_000, MarginPercent: 10, MinPayments: 3, Period: 7 * 24 * time.Hour}
+	window := testWindow(scenario.Lookback())
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		FindStructuring(gomock.Any(), gomock.Eq(db.FindStructuringParams{
+			Since:       window.Start,
+			Until:       window.End,
+			MinAmount:   900_000,
+			Threshold:   1_000_000,
+			MinPayments: 3,
+		})).
+		Times(1).
+		Return([]db.FindStructuringRow{{
+			AccountID:    7,
+			Owner:        "alice",
+			Currency:     "EUR",
+			PaymentCount: 3,
+			Total:        2_900_000,
+			PaymentIDs:   []int64{11, 12, 13},
+		}}, nil)
+
+	findings, err := scenario.Detect(context.Background(), store, window)
+	require.NoError(t, err)
+	require.Equal(t, []Finding{{
+		Username:  "alice",
+		AccountID: 7,
+		Summary:   "3 payments just under 1000000 EUR, 2900000 in total",
+		Evidence: Evidence{
+			Window:     window,
+			PaymentIDs: []int64{11, 12, 13},
+			Facts:      map[string]int64{"payments": 3, "total": 2_900_000, "threshold": 1_000_000},
+		},
+	}}, findings)
+}
+
+func TestPassThrough(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	scenario := PassThrough{MinAmount: 500_000, MinOutPercent: 90, Period: 24 * time.Hour}
+	window := testWindow(scenario.Lookback())
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		FindPassThrough(gomock.Any(), gomock.Eq(db.FindPassThroughParams{
+			Since:         window.Start,
+			Until:         window.End,
+			MinAmount:     500_000,
+			MinOutPercent: 90,
+		})).
+		Times(1).
+		Return([]db.FindPassThroughRow{{
+			AccountID: 7,
+			Owner:     "alice",
+			Currency:  "EUR",
+			TotalIn:   600_000,
+			TotalOut:  580_000,
+			EntryIDs:  []int64{21, 22, 23},
+		}}, nil)
+
+	findings, err := scenario.Detect(context.Background(), store, window)
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	require.Equal(t, "received 600000 EUR and sent on 580000 within 24h0m0s", findings[0].Summary)
+	require.Equal(t, []int64{21, 22, 23}, findings[0].Evidence.EntryIDs)
+	require.Empty(t, findings[0].Evidence.PaymentIDs)
+	require.Equal(t, map[string]int64{"total_in": 600_000, "total_out": 580_000}, findings[0].Evidence.Facts)
+}
+
+func TestRoundTrip(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	scenario := RoundTrip{MinAmount: 100_000, TolerancePercent: 10, MaxDelay: 72 * time.Hour, Period: 30 * 24 * time.Hour}
+	window := testWindow(scenario.Lookback())
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		FindRoundTrips(gomock.Any(), gomock.Eq(db.FindRoundTripsParams{
+			MaxDelayHours:    72,
+			Since:            window.Start,
+			Until:            window.End,
+			MinAmount:        100_000,
+			TolerancePercent: 10,
+		})).
+		Times(1).
+		Return([]db.FindRoundTripsRow{
+			// payment 1 came back in two parts, the second of which also
+			// pays back payment 3
+			{AccountID: 7, Owner: "alice", Currency: "EUR", Counterparty: "bob", OutgoingPaymentID: 1, BackPaymentID: 2, OutgoingAmount: 200_000, BackAmount: 100_000},
+			{AccountID: 7, Owner: "alice", Currency: "EUR", Counterparty: "bob", OutgoingPaymentID: 1, BackPaymentID: 4, OutgoingAmount: 200_000, BackAmount: 190_000},
+			{AccountID: 7, Owner: "alice", Currency: "EUR", Counterparty: "bob", OutgoingPaymentID: 3, BackPaymentID: 4, OutgoingAmount: 180_000, BackAmount: 190_000},
+			{AccountID: 7, Owner: "alice", Currency: "EUR", Counterparty: "ann", OutgoingPaymentID: 5, BackPaymentID: 6, OutgoingAmount: 150_000, BackAmount: 150_000},
+			{AccountID: 9, Owner: "carl", Currency: "USD", Counterparty: "dave", OutgoingPaymentID: 7, BackPaymentID: 8, OutgoingAmount: 300_000, BackAmount: 290_000},
+		}, nil)
+
+	findings, err := scenario.Detect(context.Background(), store, window)
+	require.NoError(t, err)
+	require.Equal(t, []Finding{
+		{
+			Username:  "alice",
+			AccountID: 7,
+			Summary:   "sent 530000 EUR to 2 users and got 440000 back within 72h0m0s",
+			Evidence: Evidence{
+				Window:         window,
+				PaymentIDs:     []int64{1, 2, 3, 4, 5, 6},
+				Counterparties: []string{"ann", "bob"},
+				Facts:          map[string]int64{"sent": 530_000, "returned": 440_000},
+			},
+		},
+		{
+			Username:  "carl",
+			AccountID: 9,
+			Summary:   "sent 300000 USD to 1 users and got 290000 back within 72h0m0s",
+			Evidence: Evidence{
+				Window:         window,
+				PaymentIDs:     []int64{7, 8},
+				Counterparties: []string{"dave"},
+				Facts:          map[string]int64{"sent": 300_000, "returned": 290_000},
+			},
+		},
+	}, findings)
+}
+
+func TestDormant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	scenario := Dormant{DormantPeriod: 180 * 24 * time.Hour, MinAmount: 100_000, Period: 7 * 24 * time.Hour}
+	window := testWindow(scenario.Lookback())
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		FindDormantReactivations(gomock.Any(), gomock.Eq(db.FindDormantReactivationsParams{
+			Since:        window.Start,
+			Until:        window.End,
+			DormantSince: window.Start.Add(-180 * 24 * time.Hour),
+			MinAmount:    100_000,
+		})).
+		Times(1).
+		Return([]db.FindDormantReactivationsRow{{
+			AccountID:    7,
+			Owner:        "alice",
+			Currency:     "EUR",
+			LastActiveAt: window.Start.Add(-200 * 24 * time.Hour),
+			Turnover:     400_000,
+			EntryIDs:     []int64{31, 32},
+		}}, nil)
+
+	findings, err := scenario.Detect(context.Background(), store, window)
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	require.Equal(t, "moved 400000 EUR after 200 days without activity", findings[0].Summary)
+	require.Equal(t, map[string]int64{"turnover": 400_000, "dormant_days": 200}, findings[0].Evidence.Facts)
+}
+
+func TestFindingAlert(t *testing.T) {
+	window := testWindow(time.Hour)
+	finding := Finding{
+		Username:  "alice",
+		AccountID: 7,
+		Summary:   "summary",
+		Evidence: Evidence{
+			Window:   window,
+			EntryIDs: []int64{1, 2},
+			Facts:    map[string]int64{"total": 10},
+		},
+	}
+
+	alert, err := finding.alert(ScenarioPassThrough)
+	require.NoError(t, err)
+	require.Equal(t, ScenarioPassThrough, alert.Scenario)
+	require.Equal(t, "alice", alert.Username)
+	require.Equal(t, int64(7), alert.AccountID)
+	require.JSONEq(t, `{"payment_ids":[],"entry_ids":[1,2]}`, string(alert.Coverage))
+
+	var evidence Evidence
+	require.NoError(t, json.Unmarshal(alert.Evidence, &evidence))
+	require.Equal(t, []int64{}, evidence.PaymentIDs)
+	require.Equal(t, []int64{1, 2}, evidence.EntryIDs)
+	require.True(t, window.Start.Equal(evidence.Window.Start))
+	require.Equal(t, map[string]int64{"total": 10}, evidence.Facts)
+}
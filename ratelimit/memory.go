@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// sweepThreshold is the number of buckets above which full ones are
+	// dropped, since a full bucket behaves exactly like a missing one. After
+	// a sweep the next one waits until the map has doubled, so sweeping
+	// stays cheap when few buckets are full.
+	sweepThreshold = 10_000
+	// maxBuckets caps the map. Once it is reached, new keys are refused
+	// rather than pushing out the buckets of others, and the map is swept
+	// at most every sweepInterval.
+	maxBuckets    = 100_000
+	sweepInterval = time.Second
+)
+
+// bucket remembers its policy, so a sweep judges it by its own refill rate.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	policy  Policy
+}
+
+// MemoryLimiter keeps the buckets in the memory of a single process.
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	sweepAt int
+	swept   time.Time
+	now     func() time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets: make(map[string]*bucket),
+		sweepAt: sweepThreshold,
+		now:     time.Now,
+	}
+}
+
+func (limiter *MemoryLimiter) Allow(_ context.Context, key string, policy Policy) (Decision, error) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	now := limiter.now()
+	b, ok := limiter.buckets[key]
+	if !ok {
+		if len(limiter.buckets) >= limiter.sweepAt && now.Sub(limiter.swept) >= sweepInterval {
+			limiter.sweep(now)
+		}
+		if len(limiter.buckets) >= maxBuckets {
+			return decide(false, 0, policy), nil
+		}
+		b = &bucket{tokens: float64(policy.Burst), updated: now}
+		limiter.buckets[key] = b
+	}
+
+	b.tokens = refill(b, now, policy)
+	b.updated = now
+	b.policy = policy
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	return decide(allowed, b.tokens, policy), nil
+}
+
+// sweep drops the buckets that have refilled completely under their own
+// policy.
+func (limiter *MemoryLimiter) sweep(now time.Time) {
+	for key, b := range limiter.buckets {
+		if refill(b, now, b.policy) >= float64(b.policy.Burst) {
+			delete(limiter.buckets, key)
+		}
+	}
+	limiter.swept = now
+	limiter.sweepAt = min(max(sweepThreshold, 2*len(limiter.buckets)), maxBuckets)
+}
+
+func refill(b *bucket, now time.Time, policy Policy) float64 {
+	elapsed := now.Sub(b.updated).Seconds()
+	return math.Min(float64(policy.Burst), b.tokens+elapsed*policy.Rate)
+}
@@ -0,0 +1,131 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMemoryLimiter() (*MemoryLimiter, *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewMemoryLimiter()
+	limiter.now = func() time.Time { return now }
+	return limiter, &now
+}
+
+func TestMemoryLimiterBurst(t *testing.T) {
+	limiter, _ := newTestMemoryLimiter()
+	policy := Policy{Rate: 1, Burst: 3}
+
+	for i := 2; i >= 0; i-- {
+		decision, err := limiter.Allow(context.Background(), "ip:1.2.3.4", policy)
+		require.NoError(t, err)
+		require.True(t, decision.Allowed)
+		require.Equal(t, i, decision.Remaining)
+	}
+
+	decision, err := limiter.Allow(context.Background(), "ip:1.2.3.4", policy)
+	require.NoError(t, err)
+	require.False(t, decision.Allowed)
+	require.Equal(t, time.Second, decision.RetryAfter)
+
+	// other keys have their own bucket
+	decision, err = limiter.Allow(context.Background(), "ip:5.6.7.8", policy)
+	require.NoError(t, err)
+	require.True(t, decision.Allowed)
+}
+
+func TestMemoryLimiterRefill(t *testing.T) {
+	limiter, now := newTestMemoryLimiter()
+	policy := PerMinute(30, 1)
+
+	decision, err := limiter.Allow(context.Background(), "user:alice", policy)
+	require.NoError(t, err)
+	require.True(t, decision.Allowed)
+
+	*now = now.Add(time.Second)
+	decision, err = limiter.Allow(context.Background(), "user:alice", policy)
+	require.NoError(t, err)
+	require.False(t, decision.Allowed)
+	require.Equal(t, time.Second, decision.RetryAfter)
+
+	*now = now.Add(2 * time.Second)
+	decision, err = limiter.Allow(context.Background(), "user:alice", policy)
+	require.NoError(t, err)
+	require.True(t, decision.Allowed)
+
+	// refilling never exceeds the burst
+	*now = now.Add(time.Hour)
+	decision, err = limiter.Allow(context.Background(), "user:alice", policy)
+	require.NoError(t, err)
+	require.True(t, decision.Allowed)
+	require.Zero(t, decision.Remaining)
+}
+
+func TestMemoryLimiterSweep(t *testing.T) {
+	limiter, now := newTestMemoryLimiter()
+	policy := Policy{Rate: 1, Burst: 1}
+
+	for i := 0; i < sweepThreshold; i++ {
+		_, err := limiter.Allow(context.Background(), time.Duration(i).String(), policy)
+		require.NoError(t, err)
+	}
+	require.Len(t, limiter.buckets, sweepThreshold)
+
+	*now = now.Add(time.Second)
+	_, err := limiter.Allow(context.Background(), "new", policy)
+	require.NoError(t, err)
+	require.Len(t, limiter.buckets, 1)
+}
+
+func TestMemoryLimiterSweepByOwnPolicy(t *testing.T) {
+	limiter, now := newTestMemoryLimiter()
+	strict := PerMinute(1, 5)
+	loose := Policy{Rate: 100, Burst: 1}
+
+	for i := 0; i < 4; i++ {
+		_, err := limiter.Allow(context.Background(), "login:alice", strict)
+		require.NoError(t, err)
+	}
+	for i := 0; len(limiter.buckets) < sweepThreshold; i++ {
+		_, err := limiter.Allow(context.Background(), time.Duration(i).String(), loose)
+		require.NoError(t, err)
+	}
+
+	// the loose buckets are full again, the strict one isn't
+	*now = now.Add(time.Second)
+	_, err := limiter.Allow(context.Background(), "new", loose)
+	require.NoError(t, err)
+	require.Len(t, limiter.buckets, 2)
+
+	decision, err := limiter.Allow(context.Background(), "login:alice", strict)
+	require.NoError(t, err)
+	require.True(t, decision.Allowed)
+	require.Zero(t, decision.Remaining)
+}
+
+func TestMemoryLimiterCap(t *testing.T) {
+	limiter, now := newTestMemoryLimiter()
+	policy := Policy{Rate: 1, Burst: 2}
+
+	for i := 0; i < maxBuckets; i++ {
+		_, err := limiter.Allow(context.Background(), time.Duration(i).String(), policy)
+		require.NoError(t, err)
+	}
+	require.Len(t, limiter.buckets, maxBuckets)
+
+	// no bucket has refilled, so a new key is refused instead of pushing
+	// one out
+	decision, err := limiter.Allow(context.Background(), "new", policy)
+	require.NoError(t, err)
+	require.False(t, decision.Allowed)
+	require.Len(t, limiter.buckets, maxBuckets)
+
+	*now = now.Add(time.Second)
+	decision, err = limiter.Allow(context.Background(), "new", policy)
+	require.NoError(t, err)
+	require.True(t, decision.Allowed)
+	require.Len(t, limiter.buckets, 1)
+}
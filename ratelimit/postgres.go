@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+)
+
+// staleAfter is how long a bucket goes unused before it is pruned. Every
+// policy refills far sooner, so pruning never resets a limit early.
+const staleAfter = time.Hour
+
+// bucketStore is the part of db.Querier the PGLimiter needs.
+type bucketStore interface {
+	TakeRateLimitToken(ctx context.Context, arg db.TakeRateLimitTokenParams) (db.TakeRateLimitTokenRow, error)
+	DeleteStaleRateLimitBuckets(ctx context.Context, updatedBefore time.Time) (int64, error)
+}
+
+// PGLimiter keeps the buckets in the rate_limit_buckets table, so every
+// replica draws from the same ones. Each Allow is a single upsert.
+type PGLimiter struct {
+	store bucketStore
+}
+
+func NewPGLimiter(store bucketStore) *PGLimiter {
+	return &PGLimiter{store: store}
+}
+
+func (limiter *PGLimiter) Allow(ctx context.Context, key string, policy Policy) (Decision, error) {
+	row, err := limiter.store.TakeRateLimitToken(ctx, db.TakeRateLimitTokenParams{
+		Key:   key,
+		Burst: float64(policy.Burst),
+		Rate:  policy.Rate,
+	})
+	if err != nil {
+		return Decision{}, err
+	}
+
+	return decide(row.Allowed, row.Tokens, policy), nil
+}
+
+// Prune deletes the buckets nobody used for staleAfter, so keys seen once
+// don't stay in the table for good.
+func (limiter *PGLimiter) Prune(ctx context.Context, now time.Time) (int64, error) {
+	return limiter.store.DeleteStaleRateLimitBuckets(ctx, now.Add(-staleAfter))
+}
+
+// Start prunes right away and then every interval, until ctx is done.
+// Failures are logged and retried at the next tick.
+func (limiter *PGLimiter) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		pruned, err := limiter.Prune(ctx, time.Now())
+		if err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "cannot prune rate limit buckets", slog.Any("error", err))
+		} else if pruned > 0 {
+			slog.InfoContext(ctx, "pruned rate limit buckets", slog.Int64("buckets", pruned))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/utils"
+)
+
+// Policy is a token bucket: it holds up to Burst tokens and refills at Rate
+// tokens per second. Every request takes one token.
+type Policy struct {
+	Rate  float64
+	Burst int
+}
+
+// PerMinute allows n requests a minute with bursts of up to burst.
+func PerMinute(n int, burst int) Policy {
+	return Policy{Rate: float64(n) / 60, Burst: burst}
+}
+
+// Policies of the API, shared by REST and gRPC so that both draw from the
+// same buckets. Sign up and login are limited per client IP since the caller
+// is not known yet; everything else per user.
+var (
+	AuthPolicy    = PerMinute(10, 5)
+	UserPolicy    = PerMinute(120, 30)
+	PaymentPolicy = PerMinute(30, 10)
+)
+
+// Decision is the outcome of a single Allow.
+type Decision struct {
+	Allowed bool
+	// Remaining is the number of whole tokens left in the bucket.
+	Remaining int
+	// RetryAfter is how long until the next token when the request was
+	// refused.
+	RetryAfter time.Duration
+}
+
+// Limiter takes a token from the bucket of key under policy. Buckets are
+// created full on first use.
+type Limiter interface {
+	Allow(ctx context.Context, key string, policy Policy) (Decision, error)
+}
+
+// New creates the limiter selected by RATE_LIMITER, defaulting to in-memory.
+// The in-memory limiter counts per replica; the postgres one shares the
+// buckets between all replicas.
+func New(config utils.Config, store db.Querier) (Limiter, error) {
+	switch config.RateLimiter {
+	case "", "memory":
+		return NewMemoryLimiter(), nil
+	case "postgres":
+		return NewPGLimiter(store), nil
+	default:
+		return nil, fmt.Errorf("unsupported rate limiter %q", config.RateLimiter)
+	}
+}
+
+// decide turns the tokens left after a request into a Decision.
+func decide(allowed bool, tokens float64, policy Policy) Decision {
+	decision := Decision{
+		Allowed:   allowed,
+		Remaining: int(math.Max(0, math.Floor(tokens))),
+	}
+	if !allowed {
+		decision.RetryAfter = retryAfter(tokens, policy)
+	}
+	return decision
+}
+
+func retryAfter(tokens float64, policy Policy) time.Duration {
+	if policy.Rate <= 0 {
+		return 0
+	}
+	seconds := (1 - tokens) / policy.Rate
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
@@ -0,0 +1,74 @@
+package screening
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// LoadEU reads the EU consolidated financial sanctions list in its CSV
+// format: semicolon separated, with a header, and one row per name of an
+// entry. Rows are grouped into entries by Entity_LogicalId and named by
+// NameAlias_WholeName.
+func LoadEU(r io.Reader) ([]Entry, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = ';'
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read header: %w", err)
+	}
+	column := func(name string) int {
+		return slices.IndexFunc(header, func(field string) bool {
+			// the first column name may start with a byte order mark
+			return strings.TrimPrefix(strings.TrimSpace(field), "\ufeff") == name
+		})
+	}
+
+	idColumn := column("Entity_LogicalId")
+	nameColumn := column("NameAlias_WholeName")
+	typeColumn := column("Entity_SubjectType")
+	if idColumn < 0 || nameColumn < 0 {
+		return nil, errors.New("missing Entity_LogicalId or NameAlias_WholeName column")
+	}
+
+	var entries []Entry
+	index := make(map[string]int)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) <= max(idColumn, nameColumn) {
+			continue
+		}
+
+		id := strings.TrimSpace(record[idColumn])
+		name := strings.TrimSpace(record[nameColumn])
+		if id == "" || name == "" {
+			continue
+		}
+
+		i, ok := index[id]
+		if !ok {
+			entry := Entry{List: ListEU, ID: id}
+			if typeColumn >= 0 && typeColumn < len(record) {
+				entry.Type = strings.TrimSpace(record[typeColumn])
+			}
+			i = len(entries)
+			index[id] = i
+			entries = append(entries, entry)
+		}
+		if !slices.Contains(entries[i].Names, name) {
+			entries[i].Names = append(entries[i].Names, name)
+		}
+	}
+}
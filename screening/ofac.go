@@ -0,0 +1,76 @@
+package screening
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ofacNull is how the OFAC files spell an empty field.
+const ofacNull = "-0-"
+
+// LoadOFAC reads the OFAC SDN list in its legacy CSV format: sdn.csv, which
+// has one row per entry with the number in the first column and the name in
+// the second, and optionally alt.csv, with the aliases of the entries (entry
+// number first, alias in the fourth column). Neither file has a header.
+func LoadOFAC(sdn io.Reader, alt io.Reader) ([]Entry, error) {
+	var entries []Entry
+	index := make(map[string]int)
+
+	err := readOFAC(sdn, 3, func(record []string) {
+		id := strings.TrimSpace(record[0])
+		index[id] = len(entries)
+		entries = append(entries, Entry{
+			List:  ListOFAC,
+			ID:    id,
+			Type:  ofacField(record[2]),
+			Names: []string{ofacField(record[1])},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if alt == nil {
+		return entries, nil
+	}
+
+	err = readOFAC(alt, 4, func(record []string) {
+		i, ok := index[strings.TrimSpace(record[0])]
+		if name := ofacField(record[3]); ok && name != "" {
+			entries[i].Names = append(entries[i].Names, name)
+		}
+	})
+	return entries, err
+}
+
+func readOFAC(r io.Reader, fields int, row func(record []string)) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// the files end with an EOF marker on a line of its own
+		if len(record) < fields {
+			continue
+		}
+		row(record)
+	}
+}
+
+func ofacField(value string) string {
+	value = strings.TrimSpace(value)
+	if value == ofacNull {
+		return ""
+	}
+	return value
+}
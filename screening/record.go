@@ -0,0 +1,31 @@
+package screening
+
+import (
+	"context"
+
+	db "github.com/danielmoisa/neobank/db/sqlc"
+)
+
+// Record screens the full names of users and records what they match for
+// analysts to review. The store is only used when there are matches.
+func (screener *Screener) Record(ctx context.Context, store db.Store, source string, users ...db.User) ([]db.ScreeningHit, error) {
+	var hits []db.UpsertScreeningHitParams
+	for _, user := range users {
+		for _, match := range screener.Screen(user.FullName) {
+			hits = append(hits, db.UpsertScreeningHitParams{
+				Username:     user.Username,
+				Source:       source,
+				ScreenedName: user.FullName,
+				List:         match.List,
+				EntryID:      match.EntryID,
+				EntryName:    match.EntryName,
+				Score:        match.Score,
+			})
+		}
+	}
+	if len(hits) == 0 {
+		return nil, nil
+	}
+
+	return store.RecordScreeningHitsTx(ctx, hits)
+}
@@ -0,0 +1,225 @@
+// Package screening matches the names of customers and counterparties
+// against sanctions lists.
+package screening
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/danielmoisa/neobank/utils"
+)
+
+// Lists the entries can come from.
+const (
+	ListOFAC = "ofac_sdn"
+	ListEU   = "eu_consolidated"
+)
+
+// DefaultThreshold is the score from which a name is reported as a match
+// when SCREENING_THRESHOLD isn't set. It lets through typos and
+// transliteration differences of a listed name, such as "Vladimr Poutine"
+// for "Vladimir Putin", while different names with a common first name stay
+// well below it.
+const DefaultThreshold = 0.9
+
+// singleTokenPenalty scales the score of a one word name against a longer
+// one: "Ali" alone says little about "Ali Hassan Mohammed".
+const singleTokenPenalty = 0.85
+
+// Entry is a listed person or organisation with all the names it is known by.
+type Entry struct {
+	List  string
+	ID    string
+	Type  string
+	Names []string
+}
+
+// Match is an entry a screened name is similar to.
+type Match struct {
+	List      string  `json:"list"`
+	EntryID   string  `json:"entry_id"`
+	EntryName string  `json:"entry_name"`
+	Score     float64 `json:"score"`
+}
+
+type indexedName struct {
+	name       string
+	normalized string
+	tokens     []string
+}
+
+type indexedEntry struct {
+	entry Entry
+	names []indexedName
+}
+
+// Screener matches names against a fixed set of entries. It is safe for
+// concurrent use.
+type Screener struct {
+	threshold float64
+	entries   []indexedEntry
+}
+
+// NewScreener indexes entries. A threshold of zero uses DefaultThreshold.
+func NewScreener(threshold float64, entries []Entry) *Screener {
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+
+	screener := &Screener{threshold: threshold}
+	for _, entry := range entries {
+		indexed := indexedEntry{entry: entry}
+		for _, name := range entry.Names {
+			normalized := Normalize(name)
+			if normalized == "" {
+				continue
+			}
+			indexed.names = append(indexed.names, indexedName{
+				name:       name,
+				normalized: normalized,
+				tokens:     sortedTokens(normalized),
+			})
+		}
+		if len(indexed.names) > 0 {
+			screener.entries = append(screener.entries, indexed)
+		}
+	}
+	return screener
+}
+
+// New loads the lists configured with SCREENING_OFAC_SDN_FILE (and its
+// aliases in SCREENING_OFAC_ALT_FILE) and SCREENING_EU_FILE. Lists that
+// aren't configured are skipped, so without any a screener matches nothing.
+func New(config utils.Config) (*Screener, error) {
+	var entries []Entry
+
+	if config.ScreeningOFACFile != "" {
+		sdn, err := os.Open(config.ScreeningOFACFile)
+		if err != nil {
+			return nil, err
+		}
+		defer sdn.Close()
+
+		// a nil *os.File would make a non-nil io.Reader
+		var alt io.Reader
+		if config.ScreeningOFACAltFile != "" {
+			file, err := os.Open(config.ScreeningOFACAltFile)
+			if err != nil {
+				return nil, err
+			}
+			defer file.Close()
+			alt = file
+		}
+
+		ofac, err := LoadOFAC(sdn, alt)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load %s: %w", config.ScreeningOFACFile, err)
+		}
+		entries = append(entries, ofac...)
+	}
+
+	if config.ScreeningEUFile != "" {
+		file, err := os.Open(config.ScreeningEUFile)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		eu, err := LoadEU(file)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load %s: %w", config.ScreeningEUFile, err)
+		}
+		entries = append(entries, eu...)
+	}
+
+	return NewScreener(config.ScreeningThreshold, entries), nil
+}
+
+// Len returns the number of entries screened against.
+func (screener *Screener) Len() int {
+	return len(screener.entries)
+}
+
+// Threshold returns the score from which names match.
+func (screener *Screener) Threshold() float64 {
+	return screener.threshold
+}
+
+// Screen returns the entries name matches, the best match first. An entry
+// is reported once, under the name of it that is closest.
+func (screener *Screener) Screen(name string) []Match {
+	normalized := Normalize(name)
+	if normalized == "" {
+		return nil
+	}
+	tokens := sortedTokens(normalized)
+
+	var matches []Match
+	for _, entry := range screener.entries {
+		best := Match{List: entry.entry.List, EntryID: entry.entry.ID}
+		for _, candidate := range entry.names {
+			if score := similarity(normalized, tokens, candidate); score > best.Score {
+				best.Score = score
+				best.EntryName = candidate.name
+			}
+		}
+		if best.Score >= screener.threshold {
+			matches = append(matches, best)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	return matches
+}
+
+// Score returns how similar two names are, from 0 to 1.
+func Score(a, b string) float64 {
+	normalized := Normalize(b)
+	return similarity(Normalize(a), sortedTokens(Normalize(a)), indexedName{
+		normalized: normalized,
+		tokens:     sortedTokens(normalized),
+	})
+}
+
+// similarity compares the whole names, which catches names written without
+// spaces, and word by word, which doesn't depend on the order of the words:
+// lists write "PUTIN, Vladimir" for Vladimir Putin. Every word of the shorter
+// name is paired with the most similar word of the longer one.
+func similarity(normalized string, tokens []string, candidate indexedName) float64 {
+	if normalized == "" || candidate.normalized == "" {
+		return 0
+	}
+	whole := JaroWinkler(normalized, candidate.normalized)
+
+	shorter, longer := tokens, candidate.tokens
+	if len(shorter) > len(longer) {
+		shorter, longer = longer, shorter
+	}
+
+	total := 0.0
+	for _, token := range shorter {
+		best := 0.0
+		for _, other := range longer {
+			best = max(best, JaroWinkler(token, other))
+		}
+		total += best
+	}
+	words := total / float64(len(shorter))
+	if len(shorter) == 1 && len(longer) > 1 {
+		words *= singleTokenPenalty
+	}
+
+	return max(whole, words)
+}
+
+func sortedTokens(normalized string) []string {
+	tokens := strings.Fields(normalized)
+	slices.Sort(tokens)
+	return tokens
+}
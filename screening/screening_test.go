@@ -0,0 +1,194 @@
+package screening
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mockdb "github.com/danielmoisa/neobank/db/mocks"
+	db "github.com/danielmoisa/neobank/db/sqlc"
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const testSDN = `36,"AEROCARIBBEAN AIRLINES",-0- ,"CUBA",-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0-
+173,"ANGLO-CARIBBEAN CO., LTD.",-0- ,"CUBA",-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0-
+9874,"PUTIN, Vladimir Vladimirovich","individual","RUSSIA-EO14024",-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,"DOB 07 Oct 1952"
+`
+
+const testAlt = `36,12,"aka","AERO-CARIBBEAN",-0-
+9874,27,"aka","PUTIN, Vladimir",-0-
+`
+
+const testEU = "\ufeffFileGenerationDate;Entity_LogicalId;Entity_SubjectType;NameAlias_WholeName\n" +
+	"2024-01-01;13;person;Saddam Hussein Al-Tikriti\n" +
+	"2024-01-01;13;person;Abu Ali\n" +
+	"2024-01-01;20;enterprise;Окно Банк\n"
+
+func TestNormalize(t *testing.T) {
+	testCases := map[string]string{
+		"Müller-Lüdenscheidt, José": "muller ludenscheidt jose",
+		"  PUTIN,  Vladimir ":       "putin vladimir",
+		"Владимир Путин":            "vladimir putin",
+		"Straße Øresund":            "strasse oresund",
+		"O'Brien":                   "obrien",
+		"Σωκράτης":                  "sokratis",
+		"!!!":                       "",
+	}
+	for name, normalized := range testCases {
+		require.Equal(t, normalized, Normalize(name), name)
+	}
+}
+
+func TestJaroWinkler(t *testing.T) {
+	require.Equal(t, 1.0, JaroWinkler("martha", "martha"))
+	require.InDelta(t, 0.961, JaroWinkler("martha", "marhta"), 0.001)
+	require.InDelta(t, 0.840, JaroWinkler("dwayne", "duane"), 0.001)
+	require.InDelta(t, 0.813, JaroWinkler("dixon", "dicksonx"), 0.001)
+	require.Equal(t, 0.0, JaroWinkler("abc", "xyz"))
+	require.Equal(t, 0.0, JaroWinkler("", "abc"))
+	require.Equal(t, 1.0, JaroWinkler("", ""))
+}
+
+func TestScore(t *testing.T) {
+	require.Equal(t, 1.0, Score("Vladimir Putin", "PUTIN, Vladimir"))
+	require.Equal(t, 1.0, Score("Владимир Путин", "Vladimir Putin"))
+	require.GreaterOrEqual(t, Score("Vladimr Poutine", "Vladimir Putin"), DefaultThreshold)
+	require.Less(t, Score("Vladimir Nabokov", "Vladimir Putin"), DefaultThreshold)
+	require.Less(t, Score("Jane Doe", "Vladimir Putin"), DefaultThreshold)
+	require.Less(t, Score("Ali", "Abu Ali"), DefaultThreshold)
+	require.Equal(t, 0.0, Score("", "Abu Ali"))
+}
+
+func testEntries(t *testing.T) []Entry {
+	ofac, err := LoadOFAC(strings.NewReader(testSDN), strings.NewReader(testAlt))
+	require.NoError(t, err)
+	eu, err := LoadEU(strings.NewReader(testEU))
+	require.NoError(t, err)
+	return append(ofac, eu...)
+}
+
+func TestLoadOFAC(t *testing.T) {
+	entries, err := LoadOFAC(strings.NewReader(testSDN), strings.NewReader(testAlt))
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	require.Equal(t, Entry{
+		List:  ListOFAC,
+		ID:    "9874",
+		Type:  "individual",
+		Names: []string{"PUTIN, Vladimir Vladimirovich", "PUTIN, Vladimir"},
+	}, entries[2])
+	require.Equal(t, "", entries[0].Type)
+
+	entries, err = LoadOFAC(strings.NewReader(testSDN+"\x1a\n"), nil)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Len(t, entries[0].Names, 1)
+}
+
+func TestLoadEU(t *testing.T) {
+	entries, err := LoadEU(strings.NewReader(testEU))
+	require.NoError(t, err)
+	require.Equal(t, []Entry{
+		{List: ListEU, ID: "13", Type: "person", Names: []string{"Saddam Hussein Al-Tikriti", "Abu Ali"}},
+		{List: ListEU, ID: "20", Type: "enterprise", Names: []string{"Окно Банк"}},
+	}, entries)
+
+	_, err = LoadEU(strings.NewReader("a;b\n1;2\n"))
+	require.Error(t, err)
+}
+
+func TestScreen(t *testing.T) {
+	screener := NewScreener(0, testEntries(t))
+	require.Equal(t, 5, screener.Len())
+	require.Equal(t, DefaultThreshold, screener.Threshold())
+
+	matches := screener.Screen("Vladimir Putin")
+	require.Len(t, matches, 1)
+	// the middle name is left out of the comparison
+	require.Equal(t, Match{List: ListOFAC, EntryID: "9874", EntryName: "PUTIN, Vladimir Vladimirovich", Score: 1}, matches[0])
+
+	matches = screener.Screen("Saddam Husein al Tikriti")
+	require.Len(t, matches, 1)
+	require.Equal(t, ListEU, matches[0].List)
+	require.Equal(t, "13", matches[0].EntryID)
+
+	matches = screener.Screen("okno bank")
+	require.Len(t, matches, 1)
+	require.Equal(t, "20", matches[0].EntryID)
+
+	require.Empty(t, screener.Screen("Jane Doe"))
+	require.Empty(t, screener.Screen(""))
+
+	// a low threshold lets more names through, best first
+	matches = NewScreener(0.6, testEntries(t)).Screen("Vladimir Putin")
+	require.NotEmpty(t, matches)
+	require.Equal(t, "9874", matches[0].EntryID)
+}
+
+func TestNew(t *testing.T) {
+	screener, err := New(utils.Config{})
+	require.NoError(t, err)
+	require.Zero(t, screener.Len())
+	require.Empty(t, screener.Screen("Vladimir Putin"))
+
+	dir := t.TempDir()
+	write := func(name string, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	screener, err = New(utils.Config{
+		ScreeningOFACFile:    write("sdn.csv", testSDN),
+		ScreeningOFACAltFile: write("alt.csv", testAlt),
+		ScreeningEUFile:      write("eu.csv", testEU),
+		ScreeningThreshold:   0.95,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 5, screener.Len())
+	require.Equal(t, 0.95, screener.Threshold())
+
+	// the aliases are optional
+	screener, err = New(utils.Config{ScreeningOFACFile: write("sdn.csv", testSDN)})
+	require.NoError(t, err)
+	require.NotZero(t, screener.Len())
+
+	_, err = New(utils.Config{ScreeningEUFile: filepath.Join(dir, "missing.csv")})
+	require.Error(t, err)
+}
+
+func TestRecord(t *testing.T) {
+	screener := NewScreener(0, testEntries(t))
+	user := db.User{Username: utils.RandomOwner(), FullName: "Vladimir Putin"}
+	other := db.User{Username: utils.RandomOwner(), FullName: "Jane Doe"}
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	hit := db.ScreeningHit{ID: 1, Username: user.Username, Status: db.ScreeningOpen}
+	store.EXPECT().
+		RecordScreeningHitsTx(gomock.Any(), gomock.Eq([]db.UpsertScreeningHitParams{{
+			Username:     user.Username,
+			Source:       db.ScreeningSourcePayment,
+			ScreenedName: user.FullName,
+			List:         ListOFAC,
+			EntryID:      "9874",
+			EntryName:    "PUTIN, Vladimir Vladimirovich",
+			Score:        1,
+		}})).
+		Times(1).
+		Return([]db.ScreeningHit{hit}, nil)
+
+	hits, err := screener.Record(context.Background(), store, db.ScreeningSourcePayment, other, user)
+	require.NoError(t, err)
+	require.Equal(t, []db.ScreeningHit{hit}, hits)
+
+	// names that match nothing don't touch the store
+	hits, err = screener.Record(context.Background(), store, db.ScreeningSourceOnboarding, other)
+	require.NoError(t, err)
+	require.Empty(t, hits)
+}
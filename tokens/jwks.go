@@ -0,0 +1,104 @@
+package tokens
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// JWK is a public key as a JSON Web Key (RFC 7517), Ed25519 keys as in
+// RFC 8037.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Modulus   string `json:"n,omitempty"`
+	Exponent  string `json:"e,omitempty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg,omitempty"`
+	Use       string `json:"use"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// KeySet is implemented by the makers that sign tokens with a private key.
+// Their public keys are safe to publish so that other services can verify
+// tokens without holding a secret.
+type KeySet interface {
+	JWKS() JWKS
+}
+
+func newJWK(key crypto.PublicKey) (JWK, error) {
+	switch key := key.(type) {
+	case ed25519.PublicKey:
+		return JWK{
+			KeyType: "OKP",
+			Curve:   "Ed25519",
+			X:       base64.RawURLEncoding.EncodeToString(key),
+		}, nil
+	case *rsa.PublicKey:
+		return JWK{
+			KeyType:  "RSA",
+			Modulus:  base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			Exponent: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	default:
+		return JWK{}, fmt.Errorf("unsupported key type %T", key)
+	}
+}
+
+// KeyID identifies key by its JWK thumbprint (RFC 7638), so the same key
+// gets the same ID everywhere without configuring one.
+func KeyID(key crypto.PublicKey) (string, error) {
+	jwk, err := newJWK(key)
+	if err != nil {
+		return "", err
+	}
+
+	// the required members only, in lexicographic order
+	var members interface{}
+	switch jwk.KeyType {
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Curve, jwk.KeyType, jwk.X}
+	default:
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.Exponent, jwk.KeyType, jwk.Modulus}
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// JWKS returns the verification keys of the keyring for tokens signed with
+// algorithm.
+func (keyring *Keyring) JWKS(algorithm string) JWKS {
+	set := JWKS{Keys: make([]JWK, 0, len(keyring.ids))}
+	for _, id := range keyring.ids {
+		// the keys were checked when they were added
+		jwk, _ := newJWK(keyring.keys[id])
+		jwk.KeyID = id
+		jwk.Algorithm = algorithm
+		jwk.Use = "sig"
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
@@ -0,0 +1,126 @@
+package tokens
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// KeyringJWTMaker signs JWTs with the private key of a keyring and puts the
+// ID of the key into the kid header.
+type KeyringJWTMaker struct {
+	method  jwt.SigningMethod
+	keyring *Keyring
+}
+
+// NewJWTEdDSAMaker creates a maker signing JWTs with Ed25519 keys.
+func NewJWTEdDSAMaker(keyring *Keyring) (Maker, error) {
+	if err := keyring.checkKeys(isEd25519, "an Ed25519 key"); err != nil {
+		return nil, err
+	}
+	return &KeyringJWTMaker{method: signingMethodEdDSA, keyring: keyring}, nil
+}
+
+// NewJWTRS256Maker creates a maker signing JWTs with RSA keys.
+func NewJWTRS256Maker(keyring *Keyring) (Maker, error) {
+	if err := keyring.checkKeys(isRSA, "an RSA key"); err != nil {
+		return nil, err
+	}
+	return &KeyringJWTMaker{method: jwt.SigningMethodRS256, keyring: keyring}, nil
+}
+
+func (maker *KeyringJWTMaker) CreateToken(username string, duration time.Duration) (string, error) {
+	payload, err := NewPayload(username, duration)
+	if err != nil {
+		return "", err
+	}
+
+	return maker.CreatePayloadToken(payload)
+}
+
+func (maker *KeyringJWTMaker) CreatePayloadToken(payload *Payload) (string, error) {
+	keyID, signer, err := maker.keyring.SigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	jwtToken := jwt.NewWithClaims(maker.method, newJWTClaims(payload))
+	jwtToken.Header["kid"] = keyID
+	return jwtToken.SignedString(signer)
+}
+
+func (maker *KeyringJWTMaker) VerifyToken(token string) (*Payload, error) {
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		if token.Method.Alg() != maker.method.Alg() {
+			return nil, ErrInvalidToken
+		}
+		keyID, _ := token.Header["kid"].(string)
+		key, ok := maker.keyring.PublicKey(keyID)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+		return key, nil
+	}
+
+	return parseJWT(token, keyFunc)
+}
+
+// JWKS returns the keys tokens are verified with.
+func (maker *KeyringJWTMaker) JWKS() JWKS {
+	return maker.keyring.JWKS(maker.method.Alg())
+}
+
+// signingMethodEdDSA signs JWTs with Ed25519 as in RFC 8037, which jwt-go v3
+// doesn't ship.
+var signingMethodEdDSA = &signingMethodEd25519{}
+
+type signingMethodEd25519 struct{}
+
+func init() {
+	jwt.RegisterSigningMethod(signingMethodEdDSA.Alg(), func() jwt.SigningMethod {
+		return signingMethodEdDSA
+	})
+}
+
+func (method *signingMethodEd25519) Alg() string {
+	return "EdDSA"
+}
+
+func (method *signingMethodEd25519) Sign(signingString string, key interface{}) (string, error) {
+	var signer crypto.Signer
+	switch key := key.(type) {
+	case ed25519.PrivateKey:
+		signer = key
+	case crypto.Signer:
+		if _, ok := key.Public().(ed25519.PublicKey); !ok {
+			return "", jwt.ErrInvalidKeyType
+		}
+		signer = key
+	default:
+		return "", jwt.ErrInvalidKeyType
+	}
+
+	signature, err := signer.Sign(nil, []byte(signingString), crypto.Hash(0))
+	if err != nil {
+		return "", err
+	}
+	return jwt.EncodeSegment(signature), nil
+}
+
+func (method *signingMethodEd25519) Verify(signingString, signature string, key interface{}) error {
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return jwt.ErrInvalidKeyType
+	}
+
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(publicKey, []byte(signingString), sig) {
+		return jwt.ErrSignatureInvalid
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package tokens
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/require"
+)
+
+func newRSAKeyring(t *testing.T) *Keyring {
+	// a small key keeps the test fast; GenerateKey uses a safe size
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	keyring, err := NewKeyring(key)
+	require.NoError(t, err)
+	return keyring
+}
+
+func TestKeyringJWTMaker(t *testing.T) {
+	testCases := []struct {
+		name    string
+		alg     string
+		newMake func(t *testing.T) (Maker, *Keyring)
+	}{
+		{
+			name: "EdDSA",
+			alg:  "EdDSA",
+			newMake: func(t *testing.T) (Maker, *Keyring) {
+				keyring := newEd25519Keyring(t)
+				maker, err := NewJWTEdDSAMaker(keyring)
+				require.NoError(t, err)
+				return maker, keyring
+			},
+		},
+		{
+			name: "RS256",
+			alg:  "RS256",
+			newMake: func(t *testing.T) (Maker, *Keyring) {
+				keyring := newRSAKeyring(t)
+				maker, err := NewJWTRS256Maker(keyring)
+				require.NoError(t, err)
+				return maker, keyring
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			maker, keyring := tc.newMake(t)
+
+			username := utils.RandomOwner()
+			issuedAt := time.Now()
+
+			token, err := maker.CreateToken(username, time.Minute)
+			require.NoError(t, err)
+
+			parsed, _, err := new(jwt.Parser).ParseUnverified(token, &Payload{})
+			require.NoError(t, err)
+			keyID, _, err := keyring.SigningKey()
+			require.NoError(t, err)
+			require.Equal(t, tc.alg, parsed.Header["alg"])
+			require.Equal(t, keyID, parsed.Header["kid"])
+
+			payload, err := maker.VerifyToken(token)
+			require.NoError(t, err)
+			require.Equal(t, username, payload.Username)
+			require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+
+			keys := maker.(KeySet).JWKS()
+			require.Len(t, keys.Keys, 1)
+			require.Equal(t, keyID, keys.Keys[0].KeyID)
+			require.Equal(t, tc.alg, keys.Keys[0].Algorithm)
+
+			expired, err := maker.CreateToken(username, -time.Minute)
+			require.NoError(t, err)
+			payload, err = maker.VerifyToken(expired)
+			require.EqualError(t, err, ErrExpiredToken.Error())
+			require.Nil(t, payload)
+		})
+	}
+}
+
+func TestKeyringJWTMakerRejectsOtherKeys(t *testing.T) {
+	keyring := newEd25519Keyring(t)
+	maker, err := NewJWTEdDSAMaker(keyring)
+	require.NoError(t, err)
+
+	keyID, signer, err := keyring.SigningKey()
+	require.NoError(t, err)
+	publicKey, err := x509.MarshalPKIXPublicKey(signer.Public())
+	require.NoError(t, err)
+
+	other := newEd25519Keyring(t)
+	otherID, otherSigner, err := other.SigningKey()
+	require.NoError(t, err)
+
+	sign := func(method jwt.SigningMethod, kid string, key interface{}) string {
+		payload, err := NewPayload(utils.RandomOwner(), time.Minute)
+		require.NoError(t, err)
+
+		jwtToken := jwt.NewWithClaims(method, payload)
+		if kid != "" {
+			jwtToken.Header["kid"] = kid
+		}
+		token, err := jwtToken.SignedString(key)
+		require.NoError(t, err)
+		return token
+	}
+
+	testCases := []struct {
+		name  string
+		token string
+	}{
+		{name: "UnknownKey", token: sign(signingMethodEdDSA, otherID, otherSigner)},
+		{name: "WrongKeyForID", token: sign(signingMethodEdDSA, keyID, otherSigner)},
+		{name: "NoKeyID", token: sign(signingMethodEdDSA, "", signer)},
+		// the public key must not be usable as an HMAC secret
+		{name: "AlgorithmConfusion", token: sign(jwt.SigningMethodHS256, keyID, publicKey)},
+		{name: "AlgNone", token: sign(jwt.SigningMethodNone, keyID, jwt.UnsafeAllowNoneSignatureType)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			payload, err := maker.VerifyToken(tc.token)
+			require.EqualError(t, err, ErrInvalidToken.Error())
+			require.Nil(t, payload)
+		})
+	}
+}
+
+func TestKeyringJWTMakerKeyType(t *testing.T) {
+	_, err := NewJWTRS256Maker(newEd25519Keyring(t))
+	require.Error(t, err)
+
+	_, err = NewJWTEdDSAMaker(newRSAKeyring(t))
+	require.Error(t, err)
+
+	_, err = NewPasetoV4Maker(newRSAKeyring(t))
+	require.Error(t, err)
+}
@@ -32,7 +32,11 @@ func (maker *JWTMaker) CreateToken(username string, duration time.Duration) (str
 		return "", err
 	}
 
-	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
+	return maker.CreatePayloadToken(payload)
+}
+
+func (maker *JWTMaker) CreatePayloadToken(payload *Payload) (string, error) {
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, newJWTClaims(payload))
 	return jwtToken.SignedString([]byte(maker.secretKey))
 }
 
@@ -45,8 +49,48 @@ func (maker *JWTMaker) VerifyToken(token string) (*Payload, error) {
 		return []byte(maker.secretKey), nil
 	}
 
-	jwtToken, err := jwt.ParseWithClaims(token, &Payload{}, keyFunc)
-	if err != nil {
+	return parseJWT(token, keyFunc)
+}
+
+// jwtClaims are the claims of the JWTs the makers issue: the payload along
+// with the registered claims of RFC 7519, so that other JWT libraries can
+// check the token too.
+type jwtClaims struct {
+	*Payload
+	jwt.StandardClaims
+}
+
+func newJWTClaims(payload *Payload) *jwtClaims {
+	return &jwtClaims{
+		Payload: payload,
+		StandardClaims: jwt.StandardClaims{
+			Id:        payload.ID.String(),
+			Subject:   payload.Username,
+			IssuedAt:  payload.IssuedAt.Unix(),
+			ExpiresAt: payload.ExpiredAt.Unix(),
+		},
+	}
+}
+
+// Valid checks the registered claims against the payload. Tokens issued
+// before they were added have none, and are checked by the payload alone.
+func (claims *jwtClaims) Valid() error {
+	if err := claims.Payload.Valid(); err != nil {
+		return err
+	}
+	now := time.Now().Unix()
+	if !claims.VerifyExpiresAt(now, false) {
+		return ErrExpiredToken
+	}
+	if claims.Subject != "" && claims.Subject != claims.Username {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+func parseJWT(token string, keyFunc jwt.Keyfunc) (*Payload, error) {
+	claims := &jwtClaims{Payload: &Payload{}}
+	if _, err := jwt.ParseWithClaims(token, claims, keyFunc); err != nil {
 		verr, ok := err.(*jwt.ValidationError)
 		if ok && errors.Is(verr.Inner, ErrExpiredToken) {
 			return nil, ErrExpiredToken
@@ -54,12 +98,7 @@ func (maker *JWTMaker) VerifyToken(token string) (*Payload, error) {
 		return nil, ErrInvalidToken
 	}
 
-	payload, ok := jwtToken.Claims.(*Payload)
-	if !ok {
-		return nil, ErrInvalidToken
-	}
-
-	return payload, nil
+	return claims.Payload, nil
 }
 
 func (payload *Payload) Valid() error {
@@ -63,3 +63,35 @@ func TestInvalidJWTTokenAlgNone(t *testing.T) {
 	require.EqualError(t, err, ErrInvalidToken.Error())
 	require.Nil(t, payload)
 }
+
+func TestJWTRegisteredClaims(t *testing.T) {
+	maker, err := NewJWTMaker(utils.RandomString(32))
+	require.NoError(t, err)
+
+	payload, err := NewPayload(utils.RandomOwner(), time.Minute)
+	require.NoError(t, err)
+	token, err := maker.CreatePayloadToken(payload)
+	require.NoError(t, err)
+
+	claims := jwt.MapClaims{}
+	_, _, err = new(jwt.Parser).ParseUnverified(token, claims)
+	require.NoError(t, err)
+	require.Equal(t, payload.Username, claims["sub"])
+	require.Equal(t, payload.ID.String(), claims["jti"])
+	require.EqualValues(t, payload.IssuedAt.Unix(), claims["iat"])
+	require.EqualValues(t, payload.ExpiredAt.Unix(), claims["exp"])
+
+	// the registered claims are checked against the payload
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"id":         payload.ID.String(),
+		"username":   payload.Username,
+		"issued_at":  payload.IssuedAt,
+		"expired_at": payload.ExpiredAt,
+		"sub":        utils.RandomOwner(),
+		"exp":        payload.ExpiredAt.Unix(),
+	})
+	token, err = forged.SignedString([]byte(maker.(*JWTMaker).secretKey))
+	require.NoError(t, err)
+	_, err = maker.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+}
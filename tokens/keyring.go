@@ -0,0 +1,192 @@
+package tokens
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrNoSigningKey is returned by CreateToken of a maker whose keyring only
+// holds verification keys.
+var ErrNoSigningKey = errors.New("no signing key")
+
+// Keyring holds the private key that signs new tokens and the public keys
+// tokens are verified with. Each key is known by an ID that goes into the
+// tokens it signs.
+//
+// Keys rotate by generating a new key pair, adding the new public key to
+// every verifier, switching the signing key and keeping the old public key
+// around until the tokens it signed have expired.
+type Keyring struct {
+	signer   crypto.Signer
+	signerID string
+	keys     map[string]crypto.PublicKey
+	// ids keeps the keys in the order they were given, the signing key first.
+	ids []string
+}
+
+// NewKeyring creates a keyring that signs with signer, which may be nil for
+// a verifier, and verifies with its public key and the verification keys.
+func NewKeyring(signer crypto.Signer, verification ...crypto.PublicKey) (*Keyring, error) {
+	keyring := &Keyring{keys: make(map[string]crypto.PublicKey)}
+
+	if signer != nil {
+		id, err := keyring.add(signer.Public())
+		if err != nil {
+			return nil, err
+		}
+		keyring.signer = signer
+		keyring.signerID = id
+	}
+
+	for _, key := range verification {
+		if _, err := keyring.add(key); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(keyring.ids) == 0 {
+		return nil, errors.New("keyring needs at least one key")
+	}
+	return keyring, nil
+}
+
+func (keyring *Keyring) add(key crypto.PublicKey) (string, error) {
+	id, err := KeyID(key)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := keyring.keys[id]; !ok {
+		keyring.keys[id] = key
+		keyring.ids = append(keyring.ids, id)
+	}
+	return id, nil
+}
+
+// SigningKey returns the key new tokens are signed with and its ID.
+func (keyring *Keyring) SigningKey() (string, crypto.Signer, error) {
+	if keyring.signer == nil {
+		return "", nil, ErrNoSigningKey
+	}
+	return keyring.signerID, keyring.signer, nil
+}
+
+// PublicKey returns the verification key with id.
+func (keyring *Keyring) PublicKey(id string) (crypto.PublicKey, bool) {
+	key, ok := keyring.keys[id]
+	return key, ok
+}
+
+// checkKeys makes sure every key of the keyring is usable by a maker.
+func (keyring *Keyring) checkKeys(usable func(key crypto.PublicKey) bool, want string) error {
+	for _, id := range keyring.ids {
+		if !usable(keyring.keys[id]) {
+			return fmt.Errorf("key %s is a %T, want %s", id, keyring.keys[id], want)
+		}
+	}
+	return nil
+}
+
+func isEd25519(key crypto.PublicKey) bool {
+	_, ok := key.(ed25519.PublicKey)
+	return ok
+}
+
+func isRSA(key crypto.PublicKey) bool {
+	_, ok := key.(*rsa.PublicKey)
+	return ok
+}
+
+// LoadKeyring reads a PKCS #8 private key and PKIX public keys from PEM files.
+// privateKeyFile may be empty for a keyring that only verifies.
+func LoadKeyring(privateKeyFile string, publicKeyFiles []string) (*Keyring, error) {
+	var signer crypto.Signer
+	if privateKeyFile != "" {
+		block, err := readPEM(privateKeyFile, "PRIVATE KEY")
+		if err != nil {
+			return nil, err
+		}
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse private key %s: %w", privateKeyFile, err)
+		}
+		var ok bool
+		if signer, ok = key.(crypto.Signer); !ok {
+			return nil, fmt.Errorf("private key %s is a %T", privateKeyFile, key)
+		}
+	}
+
+	var verification []crypto.PublicKey
+	for _, file := range publicKeyFiles {
+		block, err := readPEM(file, "PUBLIC KEY")
+		if err != nil {
+			return nil, err
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse public key %s: %w", file, err)
+		}
+		verification = append(verification, key)
+	}
+
+	return NewKeyring(signer, verification...)
+}
+
+func readPEM(file string, blockType string) (*pem.Block, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != blockType {
+		return nil, fmt.Errorf("%s holds no %s PEM block", file, blockType)
+	}
+	return block, nil
+}
+
+// Key types GenerateKey knows about.
+const (
+	KeyTypeEd25519 = "ed25519"
+	KeyTypeRSA     = "rsa"
+)
+
+const rsaKeyBits = 3072
+
+// GenerateKey creates a new signing key of keyType.
+func GenerateKey(keyType string) (crypto.Signer, error) {
+	switch keyType {
+	case KeyTypeEd25519:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	case KeyTypeRSA:
+		return rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", keyType)
+	}
+}
+
+// EncodePrivateKey returns the PKCS #8 PEM encoding of key, as read by
+// LoadKeyring.
+func EncodePrivateKey(key crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// EncodePublicKey returns the PKIX PEM encoding of key, as read by
+// LoadKeyring.
+func EncodePublicKey(key crypto.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
@@ -0,0 +1,155 @@
+package tokens
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// writeKeyPair generates a key and writes it like `neobank token keygen`.
+func writeKeyPair(t *testing.T, keyType string) (privateFile string, publicFile string) {
+	key, err := GenerateKey(keyType)
+	require.NoError(t, err)
+
+	private, err := EncodePrivateKey(key)
+	require.NoError(t, err)
+	public, err := EncodePublicKey(key.Public())
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	privateFile = filepath.Join(dir, "token_key")
+	publicFile = privateFile + ".pub"
+	require.NoError(t, os.WriteFile(privateFile, private, 0o600))
+	require.NoError(t, os.WriteFile(publicFile, public, 0o644))
+	return privateFile, publicFile
+}
+
+func TestKeyID(t *testing.T) {
+	// the thumbprint example of RFC 8037
+	x, err := base64.RawURLEncoding.DecodeString("11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo")
+	require.NoError(t, err)
+
+	keyID, err := KeyID(ed25519.PublicKey(x))
+	require.NoError(t, err)
+	require.Equal(t, "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k", keyID)
+}
+
+func TestLoadKeyring(t *testing.T) {
+	privateFile, publicFile := writeKeyPair(t, KeyTypeEd25519)
+	_, oldPublicFile := writeKeyPair(t, KeyTypeEd25519)
+
+	keyring, err := LoadKeyring(privateFile, []string{oldPublicFile, publicFile})
+	require.NoError(t, err)
+
+	keys := keyring.JWKS("EdDSA").Keys
+	// the signing key comes first and isn't listed twice
+	require.Len(t, keys, 2)
+	keyID, _, err := keyring.SigningKey()
+	require.NoError(t, err)
+	require.Equal(t, keyID, keys[0].KeyID)
+	for _, key := range keys {
+		require.Equal(t, "OKP", key.KeyType)
+		require.Equal(t, "Ed25519", key.Curve)
+		require.Equal(t, "sig", key.Use)
+	}
+
+	verifier, err := LoadKeyring("", []string{publicFile})
+	require.NoError(t, err)
+	_, _, err = verifier.SigningKey()
+	require.ErrorIs(t, err, ErrNoSigningKey)
+
+	_, err = LoadKeyring("", nil)
+	require.Error(t, err)
+
+	// the files are not interchangeable
+	_, err = LoadKeyring(publicFile, nil)
+	require.Error(t, err)
+	_, err = LoadKeyring("", []string{privateFile})
+	require.Error(t, err)
+}
+
+func TestNew(t *testing.T) {
+	edPrivate, edPublic := writeKeyPair(t, KeyTypeEd25519)
+	rsaPrivate, _ := writeKeyPair(t, KeyTypeRSA)
+
+	testCases := []struct {
+		name   string
+		config utils.Config
+		prefix string
+		keySet bool
+	}{
+		{
+			name:   "Default",
+			config: utils.Config{TokenSymmetricKey: utils.RandomString(32)},
+			prefix: "v2.local.",
+		},
+		{
+			name:   "JWT",
+			config: utils.Config{TokenMaker: MakerJWT, TokenSymmetricKey: utils.RandomString(32)},
+			prefix: "eyJ",
+		},
+		{
+			name:   "PasetoV4Public",
+			config: utils.Config{TokenMaker: MakerPasetoV4Public, TokenPrivateKeyFile: edPrivate, TokenPublicKeyFiles: []string{edPublic}},
+			prefix: "v4.public.",
+			keySet: true,
+		},
+		{
+			name:   "JWTEdDSA",
+			config: utils.Config{TokenMaker: MakerJWTEdDSA, TokenPrivateKeyFile: edPrivate},
+			prefix: "eyJ",
+			keySet: true,
+		},
+		{
+			name:   "JWTRS256",
+			config: utils.Config{TokenMaker: MakerJWTRS256, TokenPrivateKeyFile: rsaPrivate},
+			prefix: "eyJ",
+			keySet: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			maker, err := New(tc.config)
+			require.NoError(t, err)
+
+			token, err := maker.CreateToken(utils.RandomOwner(), time.Minute)
+			require.NoError(t, err)
+			require.True(t, strings.HasPrefix(token, tc.prefix))
+
+			_, err = maker.VerifyToken(token)
+			require.NoError(t, err)
+
+			_, ok := maker.(KeySet)
+			require.Equal(t, tc.keySet, ok)
+
+			// the OAuth claims survive every token format
+			scoped, err := NewPayload(utils.RandomOwner(), time.Minute)
+			require.NoError(t, err)
+			scoped.ClientID = "client"
+			scoped.ConsentID = 7
+			scoped.Scopes = []string{"accounts:read"}
+
+			token, err = maker.CreatePayloadToken(scoped)
+			require.NoError(t, err)
+			payload, err := maker.VerifyToken(token)
+			require.NoError(t, err)
+			require.Equal(t, scoped.ClientID, payload.ClientID)
+			require.Equal(t, scoped.ConsentID, payload.ConsentID)
+			require.Equal(t, scoped.Scopes, payload.Scopes)
+		})
+	}
+
+	_, err := New(utils.Config{TokenMaker: "rot13"})
+	require.EqualError(t, err, `unsupported token maker "rot13"`)
+
+	_, err = New(utils.Config{TokenMaker: MakerJWTRS256, TokenPrivateKeyFile: edPrivate})
+	require.Error(t, err)
+}
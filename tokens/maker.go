@@ -1,8 +1,10 @@
 package tokens
 
 import (
+	"fmt"
 	"time"
 
+	"github.com/danielmoisa/neobank/utils"
 	"github.com/google/uuid"
 )
 
@@ -11,10 +13,18 @@ type Payload struct {
 	Username  string    `json:"username"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
+	// ClientID is set on tokens issued to an OAuth client, which may only
+	// use Scopes while ConsentID is in force.
+	ClientID  string   `json:"client_id,omitempty"`
+	ConsentID int64    `json:"consent_id,omitempty"`
+	Scopes    []string `json:"scopes,omitempty"`
 }
 
 type Maker interface {
 	CreateToken(username string, duration time.Duration) (string, error)
+	// CreatePayloadToken creates a token for a payload the caller filled in,
+	// such as the scoped tokens of OAuth clients.
+	CreatePayloadToken(payload *Payload) (string, error)
 	VerifyToken(token string) (*Payload, error)
 }
 
@@ -32,3 +42,40 @@ func NewPayload(username string, duration time.Duration) (*Payload, error) {
 	}
 	return payload, nil
 }
+
+// Makers New knows about.
+const (
+	MakerPaseto         = "paseto"
+	MakerJWT            = "jwt"
+	MakerPasetoV4Public = "paseto_v4_public"
+	MakerJWTEdDSA       = "jwt_eddsa"
+	MakerJWTRS256       = "jwt_rs256"
+)
+
+// New creates the maker selected by TOKEN_MAKER. The default encrypts tokens
+// with TOKEN_SYMMETRIC_KEY, so every service verifying them holds the secret.
+// The public key makers sign with TOKEN_PRIVATE_KEY_FILE and verify with its
+// public key and the ones in TOKEN_PUBLIC_KEY_FILES.
+func New(config utils.Config) (Maker, error) {
+	switch config.TokenMaker {
+	case "", MakerPaseto:
+		return NewPasetoMaker(config.TokenSymmetricKey)
+	case MakerJWT:
+		return NewJWTMaker(config.TokenSymmetricKey)
+	case MakerPasetoV4Public, MakerJWTEdDSA, MakerJWTRS256:
+		keyring, err := LoadKeyring(config.TokenPrivateKeyFile, config.TokenPublicKeyFiles)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load token keys: %w", err)
+		}
+		switch config.TokenMaker {
+		case MakerPasetoV4Public:
+			return NewPasetoV4Maker(keyring)
+		case MakerJWTEdDSA:
+			return NewJWTEdDSAMaker(keyring)
+		default:
+			return NewJWTRS256Maker(keyring)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported token maker %q", config.TokenMaker)
+	}
+}
@@ -32,6 +32,10 @@ func (maker *PasetoMaker) CreateToken(username string, duration time.Duration) (
 		return "", err
 	}
 
+	return maker.CreatePayloadToken(payload)
+}
+
+func (maker *PasetoMaker) CreatePayloadToken(payload *Payload) (string, error) {
 	return maker.paseto.Encrypt(maker.symmetricKey, payload, nil)
 }
 
@@ -0,0 +1,152 @@
+package tokens
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+const pasetoV4PublicHeader = "v4.public."
+
+// PasetoV4Maker signs PASETO v4.public tokens with Ed25519. The ID of the
+// signing key goes into the footer so that tokens verify after a rotation.
+type PasetoV4Maker struct {
+	keyring *Keyring
+}
+
+type pasetoFooter struct {
+	KeyID string `json:"kid"`
+}
+
+func NewPasetoV4Maker(keyring *Keyring) (Maker, error) {
+	if err := keyring.checkKeys(isEd25519, "an Ed25519 key"); err != nil {
+		return nil, err
+	}
+	return &PasetoV4Maker{keyring}, nil
+}
+
+func (maker *PasetoV4Maker) CreateToken(username string, duration time.Duration) (string, error) {
+	payload, err := NewPayload(username, duration)
+	if err != nil {
+		return "", err
+	}
+
+	return maker.CreatePayloadToken(payload)
+}
+
+func (maker *PasetoV4Maker) CreatePayloadToken(payload *Payload) (string, error) {
+	keyID, signer, err := maker.keyring.SigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	message, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	footer, err := json.Marshal(pasetoFooter{KeyID: keyID})
+	if err != nil {
+		return "", err
+	}
+
+	signature, err := signer.Sign(nil, preAuthEncode([]byte(pasetoV4PublicHeader), message, footer, nil), crypto.Hash(0))
+	if err != nil {
+		return "", err
+	}
+
+	body := append(message, signature...)
+	return pasetoV4PublicHeader +
+		base64.RawURLEncoding.EncodeToString(body) + "." +
+		base64.RawURLEncoding.EncodeToString(footer), nil
+}
+
+func (maker *PasetoV4Maker) VerifyToken(token string) (*Payload, error) {
+	message, err := maker.verify(token)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	payload := &Payload{}
+	if err := json.Unmarshal(message, payload); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	err = payload.Valid()
+	if err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// verify checks the signature of token and returns its message.
+func (maker *PasetoV4Maker) verify(token string) ([]byte, error) {
+	if !strings.HasPrefix(token, pasetoV4PublicHeader) {
+		return nil, errors.New("not a v4.public token")
+	}
+
+	parts := strings.Split(strings.TrimPrefix(token, pasetoV4PublicHeader), ".")
+	if len(parts) != 2 {
+		return nil, errors.New("token has no footer")
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(body) < ed25519.SignatureSize {
+		return nil, errors.New("token is too short")
+	}
+
+	footer, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	var f pasetoFooter
+	if err := json.Unmarshal(footer, &f); err != nil {
+		return nil, err
+	}
+	key, ok := maker.keyring.PublicKey(f.KeyID)
+	if !ok {
+		return nil, errors.New("unknown key")
+	}
+
+	message := body[:len(body)-ed25519.SignatureSize]
+	signature := body[len(body)-ed25519.SignatureSize:]
+	if !ed25519.Verify(key.(ed25519.PublicKey), preAuthEncode([]byte(pasetoV4PublicHeader), message, footer, nil), signature) {
+		return nil, errors.New("invalid signature")
+	}
+	return message, nil
+}
+
+// JWKS returns the keys tokens are verified with.
+func (maker *PasetoV4Maker) JWKS() JWKS {
+	return maker.keyring.JWKS("")
+}
+
+// preAuthEncode is the PAE function of the PASETO specification. Each piece
+// is prefixed with its length so that no two lists encode the same way.
+func preAuthEncode(pieces ...[]byte) []byte {
+	var buf bytes.Buffer
+	writeLE64(&buf, len(pieces))
+	for _, piece := range pieces {
+		writeLE64(&buf, len(piece))
+		buf.Write(piece)
+	}
+	return buf.Bytes()
+}
+
+func writeLE64(buf *bytes.Buffer, n int) {
+	var b [8]byte
+	// the most significant bit is cleared for interoperability
+	binary.LittleEndian.PutUint64(b[:], uint64(n)&(1<<63-1))
+	buf.Write(b[:])
+}
@@ -0,0 +1,171 @@
+package tokens
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func newEd25519Keyring(t *testing.T, verification ...crypto.PublicKey) *Keyring {
+	key, err := GenerateKey(KeyTypeEd25519)
+	require.NoError(t, err)
+
+	keyring, err := NewKeyring(key, verification...)
+	require.NoError(t, err)
+	return keyring
+}
+
+func TestPasetoV4Maker(t *testing.T) {
+	maker, err := NewPasetoV4Maker(newEd25519Keyring(t))
+	require.NoError(t, err)
+
+	username := utils.RandomOwner()
+	duration := time.Minute
+
+	issuedAt := time.Now()
+	expiredAt := issuedAt.Add(duration)
+
+	token, err := maker.CreateToken(username, duration)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(token, "v4.public."))
+
+	payload, err := maker.VerifyToken(token)
+	require.NoError(t, err)
+
+	require.NotZero(t, payload.ID)
+	require.Equal(t, username, payload.Username)
+	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
+}
+
+func TestExpiredPasetoV4Token(t *testing.T) {
+	maker, err := NewPasetoV4Maker(newEd25519Keyring(t))
+	require.NoError(t, err)
+
+	token, err := maker.CreateToken(utils.RandomOwner(), -time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestPasetoV4FooterNamesKey(t *testing.T) {
+	keyring := newEd25519Keyring(t)
+	maker, err := NewPasetoV4Maker(keyring)
+	require.NoError(t, err)
+
+	token, err := maker.CreateToken(utils.RandomOwner(), time.Minute)
+	require.NoError(t, err)
+
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 4)
+	footer, err := base64.RawURLEncoding.DecodeString(parts[3])
+	require.NoError(t, err)
+
+	keyID, _, err := keyring.SigningKey()
+	require.NoError(t, err)
+	require.JSONEq(t, `{"kid":"`+keyID+`"}`, string(footer))
+}
+
+func TestPasetoV4KeyRotation(t *testing.T) {
+	oldMaker, err := NewPasetoV4Maker(newEd25519Keyring(t))
+	require.NoError(t, err)
+	oldToken, err := oldMaker.CreateToken(utils.RandomOwner(), time.Minute)
+	require.NoError(t, err)
+
+	oldKeys := oldMaker.(KeySet).JWKS()
+	require.Len(t, oldKeys.Keys, 1)
+	oldKey, err := base64.RawURLEncoding.DecodeString(oldKeys.Keys[0].X)
+	require.NoError(t, err)
+
+	// the new signing key still accepts tokens signed with the old one
+	newMaker, err := NewPasetoV4Maker(newEd25519Keyring(t, ed25519.PublicKey(oldKey)))
+	require.NoError(t, err)
+	require.Len(t, newMaker.(KeySet).JWKS().Keys, 2)
+
+	_, err = newMaker.VerifyToken(oldToken)
+	require.NoError(t, err)
+
+	// but a verifier that dropped the old key does not
+	unrelated, err := NewPasetoV4Maker(newEd25519Keyring(t))
+	require.NoError(t, err)
+
+	payload, err := unrelated.VerifyToken(oldToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestPasetoV4VerifierWithoutSigningKey(t *testing.T) {
+	signer, err := NewPasetoV4Maker(newEd25519Keyring(t))
+	require.NoError(t, err)
+	token, err := signer.CreateToken(utils.RandomOwner(), time.Minute)
+	require.NoError(t, err)
+
+	publicKey, err := base64.RawURLEncoding.DecodeString(signer.(KeySet).JWKS().Keys[0].X)
+	require.NoError(t, err)
+
+	keyring, err := NewKeyring(nil, ed25519.PublicKey(publicKey))
+	require.NoError(t, err)
+	verifier, err := NewPasetoV4Maker(keyring)
+	require.NoError(t, err)
+
+	_, err = verifier.VerifyToken(token)
+	require.NoError(t, err)
+
+	_, err = verifier.CreateToken(utils.RandomOwner(), time.Minute)
+	require.ErrorIs(t, err, ErrNoSigningKey)
+}
+
+func TestInvalidPasetoV4Token(t *testing.T) {
+	maker, err := NewPasetoV4Maker(newEd25519Keyring(t))
+	require.NoError(t, err)
+
+	token, err := maker.CreateToken(utils.RandomOwner(), time.Minute)
+	require.NoError(t, err)
+	parts := strings.Split(token, ".")
+
+	body, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	body[0] ^= 1
+	tampered := strings.Join([]string{parts[0], parts[1], base64.RawURLEncoding.EncodeToString(body), parts[3]}, ".")
+
+	testCases := []struct {
+		name  string
+		token string
+	}{
+		{name: "TamperedMessage", token: tampered},
+		{name: "NoFooter", token: strings.Join(parts[:3], ".")},
+		{name: "UnknownKey", token: strings.Join(parts[:3], ".") + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"kid":"nope"}`))},
+		{name: "WrongHeader", token: "v2.public." + parts[2] + "." + parts[3]},
+		{name: "Empty", token: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			payload, err := maker.VerifyToken(tc.token)
+			require.EqualError(t, err, ErrInvalidToken.Error())
+			require.Nil(t, payload)
+		})
+	}
+}
+
+func TestPasetoV4TestVector(t *testing.T) {
+	// test vector 4-S-1 of the PASETO specification
+	seed, err := hex.DecodeString("b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a3774")
+	require.NoError(t, err)
+	key := ed25519.NewKeyFromSeed(seed)
+
+	message := []byte(`{"data":"this is a signed message","exp":"2022-01-01T00:00:00+00:00"}`)
+	signature := ed25519.Sign(key, preAuthEncode([]byte(pasetoV4PublicHeader), message, nil, nil))
+
+	token := pasetoV4PublicHeader + base64.RawURLEncoding.EncodeToString(append(message, signature...))
+	require.Equal(t, "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9bg_XBBzds8lTZShVlwwKSgeKpLT3yukTw6JUz3W4h_ExsQV-P0V54zemZDcAxFaSeef1QlXEFtkqxT1ciiQEDA", token)
+}
@@ -0,0 +1,103 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type fakeDB struct {
+	err error
+}
+
+func (db *fakeDB) ExecContext(context.Context, string, ...interface{}) (sql.Result, error) {
+	return nil, db.err
+}
+
+func (db *fakeDB) PrepareContext(context.Context, string) (*sql.Stmt, error) {
+	return nil, db.err
+}
+
+func (db *fakeDB) QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error) {
+	return nil, db.err
+}
+
+func (db *fakeDB) QueryRowContext(context.Context, string, ...interface{}) *sql.Row {
+	return nil
+}
+
+func newRecorder() (*tracetest.SpanRecorder, trace.Tracer) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	return recorder, provider.Tracer("test")
+}
+
+func TestWrapDB(t *testing.T) {
+	recorder, tracer := newRecorder()
+	db := WrapDB(&fakeDB{}, tracer)
+
+	query := "-- name: GetAccount :one\nSELECT id FROM accounts WHERE id = $1"
+	_, err := db.QueryContext(context.Background(), query, 1)
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, "GetAccount", spans[0].Name())
+	require.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	require.Equal(t, codes.Unset, spans[0].Status().Code)
+	require.Contains(t, spans[0].Attributes(), attribute.String("db.system", "postgresql"))
+	require.Contains(t, spans[0].Attributes(), attribute.String("db.statement", query))
+}
+
+func TestWrapDBError(t *testing.T) {
+	recorder, tracer := newRecorder()
+	db := WrapDB(&fakeDB{err: errors.New("connection reset")}, tracer)
+
+	_, err := db.ExecContext(context.Background(), "-- name: DeleteAccount :exec\nDELETE FROM accounts WHERE id = $1", 1)
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, "DeleteAccount", spans[0].Name())
+	require.Equal(t, codes.Error, spans[0].Status().Code)
+	require.Equal(t, "connection reset", spans[0].Status().Description)
+}
+
+func TestWrapDBNestsUnderParent(t *testing.T) {
+	recorder, tracer := newRecorder()
+	db := WrapDB(&fakeDB{}, tracer)
+
+	ctx, parent := tracer.Start(context.Background(), "PaymentTx")
+	db.QueryRowContext(ctx, "-- name: CreatePayment :one\nINSERT INTO payments DEFAULT VALUES")
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	require.Equal(t, "CreatePayment", spans[0].Name())
+	require.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	require.Equal(t, parent.SpanContext().TraceID(), spans[0].SpanContext().TraceID())
+}
+
+func TestQueryName(t *testing.T) {
+	testCases := []struct {
+		query string
+		name  string
+	}{
+		{query: "-- name: ListAccounts :many\nSELECT 1", name: "ListAccounts"},
+		{query: "\n  -- name: AddAccountBalance :one\nUPDATE accounts", name: "AddAccountBalance"},
+		{query: "SELECT 1", name: "sql"},
+		{query: "-- name:", name: "sql"},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.name, queryName(tc.query), tc.query)
+	}
+}
@@ -0,0 +1,27 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danielmoisa/neobank/utils"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetup(t *testing.T) {
+	for _, exporter := range []string{"", ExporterNone, ExporterStdout} {
+		shutdown, err := Setup(context.Background(), utils.Config{TraceExporter: exporter})
+		require.NoError(t, err, exporter)
+		require.NoError(t, shutdown(context.Background()))
+	}
+
+	fields := otel.GetTextMapPropagator().Fields()
+	require.Contains(t, fields, "traceparent")
+	require.Contains(t, fields, "baggage")
+}
+
+func TestSetupUnknownExporter(t *testing.T) {
+	_, err := Setup(context.Background(), utils.Config{TraceExporter: "zipkin"})
+	require.EqualError(t, err, `unknown trace exporter "zipkin"`)
+}
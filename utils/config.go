@@ -1,22 +1,82 @@
 package utils
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	DBDriver            string        `mapstructure:"DB_DRIVER"`
-	DBUser              string        `mapstructure:"DB_USER"`
-	DBPassword          string        `mapstructure:"DB_PASSWORD"`
-	DBHost              string        `mapstructure:"DB_HOST"`
-	DBPort              string        `mapstructure:"DB_PORT"`
-	DBName              string        `mapstructure:"DB_NAME"`
-	DBSSLMode           string        `mapstructure:"DB_SSLMODE"`
-	ServerAddress       string        `mapstructure:"SERVER_ADDRESS"`
-	TokenSymmetricKey   string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	AccessTokenDuration time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	DBDriver             string        `mapstructure:"DB_DRIVER"`
+	DBUser               string        `mapstructure:"DB_USER"`
+	DBPassword           string        `mapstructure:"DB_PASSWORD"`
+	DBHost               string        `mapstructure:"DB_HOST"`
+	DBPort               string        `mapstructure:"DB_PORT"`
+	DBName               string        `mapstructure:"DB_NAME"`
+	DBSSLMode            string        `mapstructure:"DB_SSLMODE"`
+	ServerAddress        string        `mapstructure:"SERVER_ADDRESS"`
+	TrustedProxies       []string      `mapstructure:"TRUSTED_PROXIES"`
+	GRPCServerAddress    string        `mapstructure:"GRPC_SERVER_ADDRESS"`
+	TokenMaker           string        `mapstructure:"TOKEN_MAKER"`
+	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	TokenPrivateKeyFile  string        `mapstructure:"TOKEN_PRIVATE_KEY_FILE"`
+	TokenPublicKeyFiles  []string      `mapstructure:"TOKEN_PUBLIC_KEY_FILES"`
+	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	EventBroker          string        `mapstructure:"EVENT_BROKER"`
+	LogLevel             string        `mapstructure:"LOG_LEVEL"`
+	TraceExporter        string        `mapstructure:"TRACE_EXPORTER"`
+	OTLPEndpoint         string        `mapstructure:"OTLP_ENDPOINT"`
+	ShutdownTimeout      time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+	MigrateOnStart       bool          `mapstructure:"MIGRATE_ON_START"`
+	AdjustmentTTL        time.Duration `mapstructure:"ADJUSTMENT_TTL"`
+	RateLimiter          string        `mapstructure:"RATE_LIMITER"`
+	LoginMaxAttempts     int32         `mapstructure:"LOGIN_MAX_ATTEMPTS"`
+	LoginLockoutBase     time.Duration `mapstructure:"LOGIN_LOCKOUT_BASE"`
+	LoginLockoutMax      time.Duration `mapstructure:"LOGIN_LOCKOUT_MAX"`
+	Mailer               string        `mapstructure:"MAILER"`
+	MailFrom             string        `mapstructure:"MAIL_FROM"`
+	MailDir              string        `mapstructure:"MAIL_DIR"`
+	SMTPAddress          string        `mapstructure:"SMTP_ADDRESS"`
+	SMTPUsername         string        `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword         string        `mapstructure:"SMTP_PASSWORD"`
+	AppBaseURL           string        `mapstructure:"APP_BASE_URL"`
+	EmailVerifyTTL       time.Duration `mapstructure:"EMAIL_VERIFICATION_TTL"`
+	PasswordResetTTL     time.Duration `mapstructure:"PASSWORD_RESET_TTL"`
+	UnverifiedLimit      int64         `mapstructure:"UNVERIFIED_PAYMENT_LIMIT"`
+	OAuthConsentTTL      time.Duration `mapstructure:"OAUTH_CONSENT_TTL"`
+	OAuthRefreshTTL      time.Duration `mapstructure:"OAUTH_REFRESH_TOKEN_TTL"`
+	BlobStorage          string        `mapstructure:"BLOB_STORAGE"`
+	BlobDir              string        `mapstructure:"BLOB_DIR"`
+	ScreeningOFACFile    string        `mapstructure:"SCREENING_OFAC_SDN_FILE"`
+	ScreeningOFACAltFile string        `mapstructure:"SCREENING_OFAC_ALT_FILE"`
+	ScreeningEUFile      string        `mapstructure:"SCREENING_EU_FILE"`
+	ScreeningThreshold   float64       `mapstructure:"SCREENING_THRESHOLD"`
+	FraudRulesFile       string        `mapstructure:"FRAUD_RULES_FILE"`
+	FraudChallengeScore  int           `mapstructure:"FRAUD_CHALLENGE_SCORE"`
+	FraudBlockScore      int           `mapstructure:"FRAUD_BLOCK_SCORE"`
+	FraudTimeZone        string        `mapstructure:"FRAUD_TIME_ZONE"`
+	AMLJobInterval       time.Duration `mapstructure:"AML_JOB_INTERVAL"`
+	AMLThreshold         int64         `mapstructure:"AML_STRUCTURING_THRESHOLD"`
+	AMLDormantPeriod     time.Duration `mapstructure:"AML_DORMANT_PERIOD"`
+	InterestJobInterval  time.Duration `mapstructure:"INTEREST_JOB_INTERVAL"`
+	LoanJobInterval      time.Duration `mapstructure:"LOAN_JOB_INTERVAL"`
+	CardBIN              string        `mapstructure:"CARD_BIN"`
+	CardHoldTTL          time.Duration `mapstructure:"CARD_HOLD_TTL"`
+}
+
+// DefaultUnverifiedPaymentLimit is the largest payment, in minor units, a
+// user who hasn't verified their email may make when
+// UNVERIFIED_PAYMENT_LIMIT is not set.
+const DefaultUnverifiedPaymentLimit = 10_000
+
+// UnverifiedPaymentLimit is the largest payment a user who hasn't verified
+// their email may make.
+func (config Config) UnverifiedPaymentLimit() int64 {
+	if config.UnverifiedLimit <= 0 {
+		return DefaultUnverifiedPaymentLimit
+	}
+	return config.UnverifiedLimit
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -34,3 +94,16 @@ func LoadConfig(path string) (config Config, err error) {
 	err = viper.Unmarshal(&config)
 	return
 }
+
+// DBSource builds the postgres connection string from the DB_* settings.
+func (config Config) DBSource() string {
+	return fmt.Sprintf(
+		"postgresql://%s:%s@%s:%s/%s?sslmode=%s",
+		config.DBUser,
+		config.DBPassword,
+		config.DBHost,
+		config.DBPort,
+		config.DBName,
+		config.DBSSLMode,
+	)
+}
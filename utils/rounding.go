@@ -0,0 +1,18 @@
+package utils
+
+import "math/big"
+
+// DivRoundHalfEven divides a non-negative x by a positive y, rounding half
+// to even, the rounding of amounts of money across the bank.
+func DivRoundHalfEven(x, y *big.Int) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(x, y, new(big.Int))
+	switch new(big.Int).Lsh(remainder, 1).Cmp(y) {
+	case 1:
+		quotient.Add(quotient, big.NewInt(1))
+	case 0:
+		if quotient.Bit(0) == 1 {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return quotient
+}
@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDivRoundHalfEven(t *testing.T) {
+	for _, tc := range []struct {
+		x, y, want int64
+	}{
+		{x: 10, y: 4, want: 2},
+		{x: 14, y: 4, want: 4},
+		{x: 11, y: 4, want: 3},
+		{x: 9, y: 4, want: 2},
+		{x: 12, y: 4, want: 3},
+		{x: 0, y: 7, want: 0},
+	} {
+		got := DivRoundHalfEven(big.NewInt(tc.x), big.NewInt(tc.y))
+		require.Equal(t, tc.want, got.Int64(), "%d / %d", tc.x, tc.y)
+	}
+}